@@ -137,6 +137,8 @@ func CreateCloneArgParser() *argparser.ArgParser {
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use.")
 	ap.SupportsString(dbfactory.OSSCredsFileParam, "", "file", "OSS credentials file.")
 	ap.SupportsString(dbfactory.OSSCredsProfile, "", "profile", "OSS profile to use.")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of the S3 compatible store.")
+	ap.SupportsFlag(dbfactory.S3ForcePathStyleParam, "", "Address the S3 bucket in the path of requests rather than in the host name.")
 	ap.SupportsString(UserFlag, "u", "user", "User name to use when authenticating with the remote. Gets password from the environment variable {{.EmphasisLeft}}DOLT_REMOTE_PASSWORD{{.EmphasisRight}}.")
 	ap.SupportsFlag(SingleBranchFlag, "", "Clone only the history leading to the tip of a single branch, either specified by --branch or the remote's HEAD (default).")
 	return ap
@@ -256,6 +258,8 @@ func CreateBackupArgParser() *argparser.ArgParser {
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, dbfactory.AWSCredTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of the S3 compatible store")
+	ap.SupportsFlag(dbfactory.S3ForcePathStyleParam, "", "Address the S3 bucket in the path of requests rather than in the host name")
 	return ap
 }
 
@@ -303,6 +307,40 @@ func CreateReflogArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateMigrateAddArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("migrate_add", 3)
+	ap.SupportsInt(SequenceParam, "", "sequence", "The sequence number of the migration. Defaults to one more than the highest sequence number of the existing migrations.")
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"name", "The name of the migration."})
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"up", "The script which applies the migration."})
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"down", "The script which reverts the migration."})
+	return ap
+}
+
+func CreateMigrateArgParser(name string) *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(name, 0)
+	ap.SupportsInt(ToParam, "", "sequence", "The sequence number of the last migration to apply, or of the last migration to keep when rolling back.")
+	return ap
+}
+
+func CreateSequenceArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("create_sequence", 1)
+	ap.SupportsString(StartParam, "", "value", "The first value of the sequence. Defaults to the minimum value of ascending and the maximum value of descending sequences.")
+	ap.SupportsString(IncrementParam, "", "value", "The difference between consecutive values of the sequence. Defaults to 1.")
+	ap.SupportsString(MinValueParam, "", "value", "The smallest value of the sequence. Defaults to 1 for ascending sequences.")
+	ap.SupportsString(MaxValueParam, "", "value", "The largest value of the sequence. Defaults to -1 for descending sequences.")
+	ap.SupportsString(BranchRangeParam, "", "count", "Reserve values for each branch in ranges of this many values, so that different branches never use the same value.")
+	ap.SupportsFlag(IfNotExistsFlag, "", "Do nothing if the sequence already exists.")
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"name", "The name of the sequence."})
+	return ap
+}
+
+func CreateDropSequenceArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("drop_sequence", 1)
+	ap.SupportsFlag(IfExistsFlag, "", "Do nothing if the sequence does not exist.")
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"name", "The name of the sequence."})
+	return ap
+}
+
 func CreateGlobalArgParser(name string) *argparser.ArgParser {
 	ap := argparser.NewArgParserWithVariableArgs(name)
 	if name == "dolt" {
@@ -324,6 +362,7 @@ func CreateGlobalArgParser(name string) *argparser.ArgParser {
 
 var awsParams = []string{dbfactory.AWSRegionParam, dbfactory.AWSCredsTypeParam, dbfactory.AWSCredsFileParam, dbfactory.AWSCredsProfile}
 var ossParams = []string{dbfactory.OSSCredsFileParam, dbfactory.OSSCredsProfile}
+var s3Params = []string{dbfactory.S3EndpointParam, dbfactory.S3ForcePathStyleParam}
 
 func ProcessBackupArgs(apr *argparser.ArgParseResults, scheme, backupUrl string) (map[string]string, error) {
 	params := map[string]string{}
@@ -334,9 +373,14 @@ func ProcessBackupArgs(apr *argparser.ArgParseResults, scheme, backupUrl string)
 		err = AddAWSParams(backupUrl, apr, params)
 	case dbfactory.OSSScheme:
 		err = AddOSSParams(backupUrl, apr, params)
+	case dbfactory.S3Scheme:
+		err = AddS3Params(backupUrl, apr, params)
 	default:
 		err = VerifyNoAwsParams(apr)
 	}
+	if err == nil && scheme != dbfactory.S3Scheme {
+		err = VerifyNoS3Params(apr)
+	}
 	return params, err
 }
 
@@ -380,6 +424,38 @@ func AddOSSParams(remoteUrl string, apr *argparser.ArgParseResults, params map[s
 	return nil
 }
 
+// AddS3Params adds the params of s3 remotes, which are the aws credential and region params along with the endpoint
+// and addressing of the store.
+func AddS3Params(remoteUrl string, apr *argparser.ArgParseResults, params map[string]string) error {
+	if !strings.HasPrefix(remoteUrl, dbfactory.S3Scheme+":") {
+		return VerifyNoS3Params(apr)
+	}
+
+	for _, p := range awsParams {
+		if val, ok := apr.GetValue(p); ok {
+			params[p] = val
+		}
+	}
+	if val, ok := apr.GetValue(dbfactory.S3EndpointParam); ok {
+		params[dbfactory.S3EndpointParam] = val
+	}
+	if apr.Contains(dbfactory.S3ForcePathStyleParam) {
+		params[dbfactory.S3ForcePathStyleParam] = "true"
+	}
+
+	return nil
+}
+
+// VerifyNoS3Params returns an error if any of the params which only apply to s3 remotes were given.
+func VerifyNoS3Params(apr *argparser.ArgParseResults) error {
+	for _, p := range s3Params {
+		if apr.Contains(p) {
+			return fmt.Errorf("%s param is only valid for s3 remotes in the format s3://s3-bucket/database", p)
+		}
+	}
+	return nil
+}
+
 func VerifyNoAwsParams(apr *argparser.ArgParseResults) error {
 	if awsParams := apr.GetValues(awsParams...); len(awsParams) > 0 {
 		awsParamKeys := make([]string, 0, len(awsParams))
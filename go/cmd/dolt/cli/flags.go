@@ -23,6 +23,7 @@ const (
 	AmendFlag            = "amend"
 	AuthorParam          = "author"
 	BranchParam          = "branch"
+	BranchRangeParam     = "branch-range"
 	CachedFlag           = "cached"
 	CheckoutCreateBranch = "b"
 	CreateResetBranch    = "B"
@@ -38,10 +39,16 @@ const (
 	ForceFlag            = "force"
 	HardResetParam       = "hard"
 	HostFlag             = "host"
+	IfExistsFlag         = "if-exists"
+	IfNotExistsFlag      = "if-not-exists"
+	IncrementParam       = "increment"
 	InteractiveFlag      = "interactive"
 	ListFlag             = "list"
+	MaskedFlag           = "masked"
+	MaxValueParam        = "max-value"
 	MergesFlag           = "merges"
 	MessageArg           = "message"
+	MinValueParam        = "min-value"
 	MinParentsFlag       = "min-parents"
 	MoveFlag             = "move"
 	NoCommitFlag         = "no-commit"
@@ -60,6 +67,7 @@ const (
 	PortFlag             = "port"
 	PruneFlag            = "prune"
 	RemoteParam          = "remote"
+	SequenceParam        = "sequence"
 	SetUpstreamFlag      = "set-upstream"
 	ShallowFlag          = "shallow"
 	ShowIgnoredFlag      = "ignored"
@@ -68,10 +76,12 @@ const (
 	SkipEmptyFlag        = "skip-empty"
 	SoftResetParam       = "soft"
 	SquashParam          = "squash"
+	StartParam           = "start"
 	StatFlag             = "stat"
 	SystemFlag           = "system"
 	TablesFlag           = "tables"
 	TheirsFlag           = "theirs"
+	ToParam              = "to"
 	TrackFlag            = "track"
 	UpperCaseAllFlag     = "ALL"
 	UserFlag             = "user"
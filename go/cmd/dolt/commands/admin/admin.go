@@ -22,6 +22,7 @@ var Commands = cli.NewHiddenSubCommandHandler("admin", "Commands for directly wo
 	SetRefCmd{},
 	ShowRootCmd{},
 	ArchiveCmd{},
+	StorageReportCmd{},
 
 	ZstdCmd{},
 })
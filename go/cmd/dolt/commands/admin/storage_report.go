@@ -0,0 +1,182 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dustin/go-humanize"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/tabular"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+)
+
+var storageReportDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Reports the storage used by each table, index and ref of the database.",
+	LongDesc: `Walks every chunk reachable from the branches, tags, working sets and other refs of the database, and reports:
+
+Per table and index, the size of the row data or index at the roots of every branch, tag and working set. Unique bytes are not referenced by any other table or index, and shared bytes are.
+
+Per ref, the size of everything reachable from it, including its history. Unique bytes are not reachable from any other ref, and would be reclaimed by {{.EmphasisLeft}}dolt gc{{.EmphasisRight}} after deleting the ref.
+
+The total size of the reachable chunks, the size of the table files of the database, and an upper bound of how much {{.EmphasisLeft}}dolt gc{{.EmphasisRight}} would reclaim, since the indexes of table files and the journal are counted as reclaimable.
+
+Sizes are the compressed sizes of chunks in table files. This command reads the entire database, and may take a long time on large databases.`,
+
+	Synopsis: []string{
+		`[-r {{.LessThan}}result format{{.GreaterThan}}]`,
+	},
+}
+
+type StorageReportCmd struct {
+}
+
+func (cmd StorageReportCmd) Name() string {
+	return "storage-report"
+}
+
+// Description returns a description of the command
+func (cmd StorageReportCmd) Description() string {
+	return "Reports the storage used by each table, index and ref of the database."
+}
+
+func (cmd StorageReportCmd) RequiresRepo() bool {
+	return true
+}
+
+func (cmd StorageReportCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(storageReportDocs, ap)
+}
+
+func (cmd StorageReportCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 0)
+	ap.SupportsString(commands.FormatFlag, "r", "result output format", "How to format the report. Valid values are tabular and json. Defaults to tabular.")
+	return ap
+}
+
+func (cmd StorageReportCmd) Hidden() bool {
+	return true
+}
+
+func (cmd StorageReportCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	usage, _ := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, storageReportDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, usage)
+
+	format := apr.GetValueOrDefault(commands.FormatFlag, "tabular")
+	if format != "tabular" && format != "json" {
+		verr := errhand.BuildDError("invalid result format: %s", format).SetPrintUsage().Build()
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	report, err := dEnv.DoltDB.GetStorageReport(ctx)
+	if err != nil {
+		verr := errhand.BuildDError("failed to build storage report").AddCause(err).Build()
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	if format == "json" {
+		err = printStorageReportJSON(report)
+	} else {
+		err = printStorageReport(ctx, report)
+	}
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	return 0
+}
+
+func printStorageReportJSON(report *doltdb.StorageReport) error {
+	if report.Tables == nil {
+		report.Tables = []doltdb.TableStorage{}
+	}
+	if report.Refs == nil {
+		report.Refs = []doltdb.RefStorage{}
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	cli.Println(string(b))
+	return nil
+}
+
+var tableStorageSchema = sql.Schema{
+	&sql.Column{Name: "table", Type: types.Text},
+	&sql.Column{Name: "index", Type: types.Text},
+	&sql.Column{Name: "chunks", Type: types.Uint64},
+	&sql.Column{Name: "bytes", Type: types.Text},
+	&sql.Column{Name: "unique bytes", Type: types.Text},
+	&sql.Column{Name: "shared bytes", Type: types.Text},
+	&sql.Column{Name: "avg chunk size", Type: types.Text},
+}
+
+var refStorageSchema = sql.Schema{
+	&sql.Column{Name: "ref", Type: types.Text},
+	&sql.Column{Name: "chunks", Type: types.Uint64},
+	&sql.Column{Name: "bytes", Type: types.Text},
+	&sql.Column{Name: "unique bytes", Type: types.Text},
+	&sql.Column{Name: "avg chunk size", Type: types.Text},
+}
+
+func printStorageReport(ctx context.Context, report *doltdb.StorageReport) error {
+	var rows []sql.Row
+	for _, t := range report.Tables {
+		rows = append(rows, sql.Row{t.Table, t.Index, t.Chunks, humanize.Bytes(t.Bytes), humanize.Bytes(t.UniqueBytes),
+			humanize.Bytes(t.SharedBytes), humanize.Bytes(t.AvgChunkSize)})
+	}
+	if err := writeStorageReportRows(ctx, tableStorageSchema, rows); err != nil {
+		return err
+	}
+	cli.Println()
+
+	rows = nil
+	for _, r := range report.Refs {
+		rows = append(rows, sql.Row{r.Ref, r.Chunks, humanize.Bytes(r.Bytes), humanize.Bytes(r.UniqueBytes),
+			humanize.Bytes(r.AvgChunkSize)})
+	}
+	if err := writeStorageReportRows(ctx, refStorageSchema, rows); err != nil {
+		return err
+	}
+	cli.Println()
+
+	cli.Printf("reachable chunks:       %d\n", report.Chunks)
+	cli.Printf("reachable bytes:        %s\n", humanize.Bytes(report.Bytes))
+	cli.Printf("stored bytes:           %s\n", humanize.Bytes(report.StoredBytes))
+	cli.Printf("reclaimable by dolt gc: at most %s\n", humanize.Bytes(report.ReclaimableBytes))
+	return nil
+}
+
+func writeStorageReportRows(ctx context.Context, sch sql.Schema, rows []sql.Row) error {
+	wr := tabular.NewFixedWidthTableWriter(sch, iohelp.NopWrCloser(cli.OutStream), 100)
+	for _, row := range rows {
+		if err := wr.WriteSqlRow(ctx, row); err != nil {
+			_ = wr.Close(ctx)
+			return fmt.Errorf("could not write storage report: %w", err)
+		}
+	}
+	return wr.Close(ctx)
+}
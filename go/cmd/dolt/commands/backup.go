@@ -51,6 +51,8 @@ aws-creds-type specifies the means by which credentials should be retrieved in o
 	
 GCP backup urls should be of the form gs://gcs-bucket/database and will use the credentials setup using the gcloud command line available from Google.
 
+S3 backup urls should be of the form {{.EmphasisLeft}}s3://s3-bucket/database{{.EmphasisRight}}, and can be used with S3 compatible stores such as MinIO and Ceph as well as with AWS. Unlike aws backups they do not need a DynamoDB table. The endpoint of the store is set with {{.EmphasisLeft}}--s3-endpoint{{.EmphasisRight}}, and {{.EmphasisLeft}}--s3-force-path-style{{.EmphasisRight}} addresses the bucket in the path of requests rather than in the host name, as most S3 compatible stores require. The aws-region and aws-creds parameters above apply to them too.
+
 The local filesystem can be used as a backup by providing a repository url in the format file://absolute path. See https://en.wikipedia.org/wiki/File_URI_scheme
 
 {{.EmphasisLeft}}remove{{.EmphasisRight}}, {{.EmphasisLeft}}rm{{.EmphasisRight}}
@@ -68,7 +70,7 @@ Snapshot the database and upload the backup to {{.LessThan}}url{{.GreaterThan}}.
 
 	Synopsis: []string{
 		"[-v | --verbose]",
-		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
+		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--s3-endpoint {{.LessThan}}url{{.GreaterThan}}] [--s3-force-path-style] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
 		"remove {{.LessThan}}name{{.GreaterThan}}",
 		"restore [--force] {{.LessThan}}url{{.GreaterThan}} {{.LessThan}}name{{.GreaterThan}}",
 		"sync {{.LessThan}}name{{.GreaterThan}}",
@@ -43,9 +43,11 @@ var cloneDocs = cli.CommandDocumentationContent{
 After the clone, a plain {{.EmphasisLeft}}dolt fetch{{.EmphasisRight}} without arguments will update all the remote-tracking branches, and a {{.EmphasisLeft}}dolt pull{{.EmphasisRight}} without arguments will in addition merge the remote branch into the current branch.
 
 This default configuration is achieved by creating references to the remote branch heads under {{.LessThan}}refs/remotes/origin{{.GreaterThan}}  and by creating a remote named 'origin'.
+
+If the {{.EmphasisLeft}}--masked{{.EmphasisRight}} flag is provided, a local branch is created for every cloned remote branch, and the history of every branch and tag is rewritten to apply the masking rules in the {{.EmphasisLeft}}dolt_masking{{.EmphasisRight}} table of the cloned HEAD, so masked values are consistent across commits. The remote and its remote-tracking branches are then removed and the clone is garbage collected, so that no unmasked data is left in it.
 `,
 	Synopsis: []string{
-		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}]  [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
+		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}]  [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--masked] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
 	},
 }
 
@@ -73,7 +75,9 @@ func (cmd CloneCmd) Docs() *cli.CommandDocumentation {
 }
 
 func (cmd CloneCmd) ArgParser() *argparser.ArgParser {
-	return cli.CreateCloneArgParser()
+	ap := cli.CreateCloneArgParser()
+	ap.SupportsFlag(cli.MaskedFlag, "", "Rewrite the cloned history to apply the masking rules in the `dolt_masking` table, and remove the remote.")
+	return ap
 }
 
 // EventType returns the type of the event to log
@@ -169,6 +173,15 @@ func clone(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEn
 		}
 	}
 
+	// A masked clone has no remote to track, since the remote's history is not masked
+	if apr.Contains(cli.MaskedFlag) {
+		err = maskClonedHistory(ctx, clonedEnv, remoteName)
+		if err != nil {
+			return errhand.BuildDError("error: failed to mask cloned data").AddCause(err).Build()
+		}
+		return nil
+	}
+
 	err = clonedEnv.RepoStateWriter().UpdateBranch(clonedEnv.RepoState.CWBHeadRef().GetPath(), env.BranchConfig{
 		Merge:  clonedEnv.RepoState.Head,
 		Remote: remoteName,
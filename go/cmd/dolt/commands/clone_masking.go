@@ -0,0 +1,217 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/masking"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rebase"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+	"github.com/dolthub/dolt/go/store/chunks"
+)
+
+// maskClonedHistory rewrites every branch and tag of a freshly cloned database so that the masking rules in the
+// dolt_masking table of its HEAD are applied to every commit. The dolt_masking table itself is removed from every commit,
+// since the salts of its rules would allow the masked values to be reversed. The remote named |remoteName| is removed
+// afterwards and the database is garbage collected, so the clone does not retain any of the unmasked data.
+func maskClonedHistory(ctx context.Context, dEnv *env.DoltEnv, remoteName string) error {
+	root, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules, err := masking.LoadRules(ctx, root)
+	if err != nil {
+		return err
+	}
+	if rules.Empty() {
+		cli.PrintErrln(fmt.Sprintf("warning: no rules found in %s, the clone is not masked", doltdb.MaskingTableName))
+		return nil
+	}
+
+	// Every cloned branch is kept as a local branch, since remote-tracking branches can't be rewritten
+	err = createBranchesForRemoteRefs(ctx, dEnv, remoteName)
+	if err != nil {
+		return err
+	}
+
+	replayer := &maskingReplayer{dEnv: dEnv, rules: rules}
+	err = rebase.AllBranchesAndTags(ctx, dEnv, false, replayer, replayer, rebase.EntireHistory())
+	if err != nil {
+		return err
+	}
+
+	// Remote-tracking branches still reference the unmasked history, so the remote must go
+	err = dEnv.RemoveRemote(ctx, remoteName)
+	if err != nil {
+		return err
+	}
+
+	err = dEnv.DoltDB.GC(ctx, nil)
+	if err != nil && err != chunks.ErrNothingToCollect {
+		return err
+	}
+
+	return nil
+}
+
+// createBranchesForRemoteRefs creates a local branch for each remote-tracking branch of |remoteName| that doesn't
+// already have one.
+func createBranchesForRemoteRefs(ctx context.Context, dEnv *env.DoltEnv, remoteName string) error {
+	remoteRefs, err := dEnv.DoltDB.GetRemoteRefs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range remoteRefs {
+		rr := r.(ref.RemoteRef)
+		if rr.GetRemote() != remoteName {
+			continue
+		}
+
+		branchRef := ref.NewBranchRef(rr.GetBranch())
+		exists, err := dEnv.DoltDB.HasRef(ctx, branchRef)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		cm, err := dEnv.DoltDB.ResolveCommitRef(ctx, rr)
+		if err != nil {
+			return err
+		}
+		err = dEnv.DoltDB.NewBranchAtCommit(ctx, branchRef, cm, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maskingReplayer replays commits and working set roots, applying masking rules to their tables
+type maskingReplayer struct {
+	dEnv  *env.DoltEnv
+	rules masking.Rules
+}
+
+var _ rebase.CommitReplayer = &maskingReplayer{}
+var _ rebase.RootReplayer = &maskingReplayer{}
+
+// ReplayCommit implements the CommitReplayer interface
+func (m *maskingReplayer) ReplayCommit(ctx context.Context, commit, _, _ *doltdb.Commit) (doltdb.RootValue, error) {
+	root, err := commit.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmHash, err := commit.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	return m.maskRoot(ctx, root, cmHash.String())
+}
+
+// ReplayRoot implements the RootReplayer interface
+func (m *maskingReplayer) ReplayRoot(ctx context.Context, root, _, _ doltdb.RootValue) (doltdb.RootValue, error) {
+	rootHash, err := root.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	return m.maskRoot(ctx, root, rootHash.String())
+}
+
+func (m *maskingReplayer) maskRoot(ctx context.Context, root doltdb.RootValue, hashStr string) (doltdb.RootValue, error) {
+	query, err := maskingQuery(ctx, root, m.rules)
+	if err != nil {
+		return nil, err
+	}
+	if query != "" {
+		root, err = processFilterQuery(ctx, m.dEnv, root, hashStr, query, false, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return removeMaskingTable(ctx, root)
+}
+
+// removeMaskingTable returns |root| without the dolt_masking table, if it has one.
+func removeMaskingTable(ctx context.Context, root doltdb.RootValue) (doltdb.RootValue, error) {
+	tblName := doltdb.TableName{Name: doltdb.MaskingTableName}
+	has, err := root.HasTable(ctx, tblName)
+	if err != nil || !has {
+		return root, err
+	}
+	return root.RemoveTables(ctx, true, false, tblName)
+}
+
+// maskingQuery returns the UPDATE statements that apply |rules| to the tables of |root|. Rules for tables or columns
+// that do not exist in |root| are skipped, since the schema may have changed over the history being rewritten.
+func maskingQuery(ctx context.Context, root doltdb.RootValue, rules masking.Rules) (string, error) {
+	tableNames, err := doltdb.GetNonSystemTableNames(ctx, root)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(tableNames)
+
+	var stmts []string
+	for _, tableName := range tableNames {
+		tblRules := rules.ForTable(tableName)
+		if len(tblRules) == 0 {
+			continue
+		}
+
+		tbl, _, err := root.GetTable(ctx, doltdb.TableName{Name: tableName})
+		if err != nil {
+			return "", err
+		}
+		sch, err := tbl.GetSchema(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		var assignments []string
+		for _, col := range sch.GetAllCols().GetColumns() {
+			rule, ok := tblRules[strings.ToLower(col.Name)]
+			if !ok {
+				continue
+			}
+			quotedCol := sqlfmt.QuoteIdentifier(col.Name)
+			assignments = append(assignments, fmt.Sprintf("%s = %s(%s, %s, %s)", quotedCol, dfunctions.MaskFuncName, quotedCol,
+				quoteMaskingString(string(rule.Transform)), quoteMaskingString(rule.Argument)))
+		}
+		if len(assignments) > 0 {
+			stmts = append(stmts, fmt.Sprintf("UPDATE %s SET %s;", sqlfmt.QuoteIdentifier(tableName), strings.Join(assignments, ", ")))
+		}
+	}
+
+	return strings.Join(stmts, "\n"), nil
+}
+
+// quoteMaskingString returns |s| as a single-quoted SQL string literal
+func quoteMaskingString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
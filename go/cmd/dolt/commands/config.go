@@ -49,6 +49,8 @@ Valid configuration variables:
 
 	- core.editor - lets you edit 'commit' or 'tag' messages by launching the set editor.
 
+	- core.pager - the command the SQL shell pages results with. When unset, results are paged only after the \P meta command, with $PAGER or less.
+
 	- creds.add_url - sets the endpoint used to authenticate a client for 'dolt login'.
 
 	- doltlab.insecure - boolean flag used to authenticate a client against DoltLab.
@@ -33,6 +33,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/markdown"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/sqlexport"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/tabular"
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
@@ -68,6 +69,8 @@ func newDiffWriter(diffOutput diffOutput) (diffWriter, error) {
 		return sqlDiffWriter{}, nil
 	case JsonDiffOutput:
 		return newJsonDiffWriter(iohelp.NopWrCloser(cli.CliOut))
+	case MarkdownDiffOutput:
+		return markdownDiffWriter{}, nil
 	default:
 		panic(fmt.Sprintf("unexpected diff output: %v", diffOutput))
 	}
@@ -650,3 +653,110 @@ func (j *jsonDiffWriter) Close(ctx context.Context) error {
 	// Writer has already been closed here during row iteration, no need to close it here
 	return nil
 }
+
+// markdownDiffWriter writes diffs as markdown, suitable for pull request descriptions and review comments. Each table
+// has a heading, schema and definition changes are written as diff code blocks, and row changes are written as a
+// table whose first column marks each row as added, removed or modified.
+type markdownDiffWriter struct{}
+
+var _ diffWriter = markdownDiffWriter{}
+
+func (m markdownDiffWriter) Close(ctx context.Context) error {
+	return nil
+}
+
+func (m markdownDiffWriter) BeginTable(fromTableName, toTableName string, isAdd, isDrop bool) error {
+	if isDrop {
+		cli.Printf("## `%s`\n\nDeleted table\n\n", fromTableName)
+	} else if isAdd {
+		cli.Printf("## `%s`\n\nAdded table\n\n", toTableName)
+	} else if fromTableName != toTableName {
+		cli.Printf("## `%s`\n\nRenamed from `%s`\n\n", toTableName, fromTableName)
+	} else {
+		cli.Printf("## `%s`\n\n", toTableName)
+	}
+	return nil
+}
+
+func (m markdownDiffWriter) WriteTableSchemaDiff(fromTableInfo, toTableInfo *diff.TableInfo, tds diff.TableDeltaSummary) error {
+	var fromCreateStmt = ""
+	if fromTableInfo != nil {
+		fromCreateStmt = fromTableInfo.CreateStmt
+	}
+
+	var toCreateStmt = ""
+	if toTableInfo != nil {
+		toCreateStmt = toTableInfo.CreateStmt
+	}
+
+	if fromCreateStmt != toCreateStmt {
+		cli.Println("### Schema")
+		cli.Println()
+		m.printLineDiff(fromCreateStmt, toCreateStmt)
+	}
+
+	return nil
+}
+
+func (m markdownDiffWriter) WriteEventDiff(ctx context.Context, eventName, oldDefn, newDefn string) error {
+	cli.Printf("## Event `%s`\n\n", eventName)
+	m.printLineDiff(oldDefn, newDefn)
+	return nil
+}
+
+func (m markdownDiffWriter) WriteTriggerDiff(ctx context.Context, triggerName, oldDefn, newDefn string) error {
+	cli.Printf("## Trigger `%s`\n\n", triggerName)
+	m.printLineDiff(oldDefn, newDefn)
+	return nil
+}
+
+func (m markdownDiffWriter) WriteViewDiff(ctx context.Context, viewName, oldDefn, newDefn string) error {
+	cli.Printf("## View `%s`\n\n", viewName)
+	m.printLineDiff(oldDefn, newDefn)
+	return nil
+}
+
+func (m markdownDiffWriter) printLineDiff(oldText, newText string) {
+	cli.Println("```diff")
+	cli.Println(textdiff.LineDiff(oldText, newText))
+	cli.Println("```")
+	cli.Println()
+}
+
+func (m markdownDiffWriter) WriteTableDiffStats(diffStats []diffStatistics, oldColLen, newColLen int, areTablesKeyless bool) error {
+	acc := diff.DiffStatProgress{}
+	for _, diffStat := range diffStats {
+		acc.Adds += diffStat.RowsAdded
+		acc.Removes += diffStat.RowsDeleted
+		acc.Changes += diffStat.RowsModified
+		acc.CellChanges += diffStat.CellsModified
+		acc.NewRowSize += diffStat.NewRowCount
+		acc.OldRowSize += diffStat.OldRowCount
+		acc.NewCellSize += diffStat.NewCellCount
+		acc.OldCellSize += diffStat.OldCellCount
+	}
+
+	if (acc.Adds+acc.Removes+acc.Changes) == 0 && (acc.OldCellSize-acc.NewCellSize) == 0 {
+		cli.Println("No data changes.")
+		cli.Println()
+		return nil
+	}
+
+	cli.Printf("- %s\n", pluralize("Row Added", "Rows Added", acc.Adds))
+	cli.Printf("- %s\n", pluralize("Row Deleted", "Rows Deleted", acc.Removes))
+	if !areTablesKeyless {
+		numCellInserts, numCellDeletes := sqle.GetCellsAddedAndDeleted(acc, newColLen)
+		cli.Printf("- %s\n", pluralize("Row Modified", "Rows Modified", acc.Changes))
+		cli.Printf("- %s\n", pluralize("Row Unmodified", "Rows Unmodified", acc.OldRowSize-acc.Changes-acc.Removes))
+		cli.Printf("- %s\n", pluralize("Cell Added", "Cells Added", numCellInserts))
+		cli.Printf("- %s\n", pluralize("Cell Deleted", "Cells Deleted", numCellDeletes))
+		cli.Printf("- %s\n", pluralize("Cell Modified", "Cells Modified", acc.CellChanges))
+	}
+	cli.Println()
+
+	return nil
+}
+
+func (m markdownDiffWriter) RowWriter(fromTableInfo, toTableInfo *diff.TableInfo, tds diff.TableDeltaSummary, unionSch sql.Schema) (diff.SqlRowDiffWriter, error) {
+	return markdown.NewMarkdownDiffWriter(iohelp.NopWrCloser(cli.CliOut), unionSch), nil
+}
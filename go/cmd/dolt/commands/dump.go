@@ -33,6 +33,7 @@ import (
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/masking"
 	"github.com/dolthub/dolt/go/libraries/doltcore/mvdata"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table"
@@ -67,11 +68,12 @@ var dumpDocs = cli.CommandDocumentationContent{
 If a dump file already exists then the operation will fail, unless the {{.EmphasisLeft}}--force | -f{{.EmphasisRight}} flag 
 is provided. The force flag forces the existing dump file to be overwritten. The {{.EmphasisLeft}}-r{{.EmphasisRight}} flag 
 is used to support different file formats of the dump. In the case of non .sql files each table is written to a separate
-csv,json or parquet file. 
+csv,json or parquet file. If the {{.EmphasisLeft}}--masked{{.EmphasisRight}} flag is provided, the masking rules in the 
+{{.EmphasisLeft}}dolt_masking{{.EmphasisRight}} table are applied to the dumped rows.
 `,
 
 	Synopsis: []string{
-		"[-f] [-r {{.LessThan}}result-format{{.GreaterThan}}] [-fn {{.LessThan}}file_name{{.GreaterThan}}]  [-d {{.LessThan}}directory{{.GreaterThan}}] [--batch] [--no-batch] [--no-autocommit] [--no-create-db] ",
+		"[-f] [-r {{.LessThan}}result-format{{.GreaterThan}}] [-fn {{.LessThan}}file_name{{.GreaterThan}}]  [-d {{.LessThan}}directory{{.GreaterThan}}] [--batch] [--no-batch] [--no-autocommit] [--no-create-db] [--masked] ",
 	},
 }
 
@@ -104,6 +106,7 @@ func (cmd DumpCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(noAutocommitFlag, "na", "Turn off autocommit for each dumped table. Useful for speeding up loading of output SQL file.")
 	ap.SupportsFlag(schemaOnlyFlag, "", "Dump a table's schema, without including any data, to the output SQL file.")
 	ap.SupportsFlag(noCreateDbFlag, "", "Do not write `CREATE DATABASE` statements in SQL files.")
+	ap.SupportsFlag(cli.MaskedFlag, "", "Apply the masking rules in the `dolt_masking` table to the dumped rows.")
 	return ap
 }
 
@@ -142,6 +145,14 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 		return HandleVErrAndExitCode(vErr, usage)
 	}
 
+	var rules masking.Rules
+	if apr.Contains(cli.MaskedFlag) {
+		rules, err = masking.LoadRules(ctx, root)
+		if err != nil {
+			return HandleVErrAndExitCode(errhand.BuildDError("error: failed to read masking rules").AddCause(err).Build(), usage)
+		}
+	}
+
 	switch resFormat {
 	case emptyFileExt, sqlFileExt:
 		var defaultName string
@@ -183,6 +194,7 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 
 		for _, tbl := range tblNames {
 			tblOpts := newTableArgs(tbl, dumpOpts.dest, !apr.Contains(noBatchFlag), apr.Contains(noAutocommitFlag), schemaOnly)
+			tblOpts.masking = rules
 			err = dumpTable(ctx, dEnv, tblOpts, fPath)
 			if err != nil {
 				return HandleVErrAndExitCode(err, usage)
@@ -194,7 +206,7 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 			return HandleVErrAndExitCode(err, usage)
 		}
 	case csvFileExt, jsonFileExt, parquetFileExt:
-		err = dumpNonSqlTables(ctx, root, dEnv, force, tblNames, resFormat, outputFileOrDirName, false)
+		err = dumpNonSqlTables(ctx, root, dEnv, force, tblNames, resFormat, outputFileOrDirName, false, rules)
 		if err != nil {
 			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
 		}
@@ -527,6 +539,8 @@ type tableOptions struct {
 	dest          mvdata.DataLocation
 	batched       bool
 	autocommitOff bool
+	// masking holds the masking rules applied to the dumped rows, or nil if rows are dumped unmasked
+	masking masking.Rules
 }
 
 func (m tableOptions) IsBatched() bool {
@@ -561,10 +575,14 @@ func (m dumpOptions) DumpDestName() string {
 
 // dumpTable dumps table in file given specific table and file location info
 func dumpTable(ctx context.Context, dEnv *env.DoltEnv, tblOpts *tableOptions, filePath string) errhand.VerboseError {
+	var rd table.SqlRowReader
 	rd, err := mvdata.NewSqlEngineReader(ctx, dEnv, tblOpts.tableName)
 	if err != nil {
 		return errhand.BuildDError("Error creating reader for %s.", tblOpts.SrcName()).AddCause(err).Build()
 	}
+	if tblOpts.masking != nil {
+		rd = masking.NewReader(rd, tblOpts.tableName, tblOpts.masking)
+	}
 
 	wr, err := getTableWriter(ctx, dEnv, tblOpts, rd.GetSchema(), filePath)
 	if err != nil {
@@ -737,8 +755,8 @@ func newTableArgs(tblName string, destination mvdata.DataLocation, batched, auto
 }
 
 // dumpNonSqlTables returns nil if all tables is dumped successfully, and it returns err if there is one.
-// It handles only csv and json file types(rf).
-func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.DoltEnv, force bool, tblNames []string, rf string, dirName string, batched bool) errhand.VerboseError {
+// It handles only csv and json file types(rf). If |rules| is non-nil, the masking rules are applied to the dumped rows.
+func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.DoltEnv, force bool, tblNames []string, rf string, dirName string, batched bool, rules masking.Rules) errhand.VerboseError {
 	var fName string
 	if dirName == emptyStr {
 		dirName = "doltdump/"
@@ -758,6 +776,7 @@ func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.Dolt
 		}
 
 		tblOpts := newTableArgs(tbl, dumpOpts.dest, batched, false, false)
+		tblOpts.masking = rules
 
 		err = dumpTable(ctx, dEnv, tblOpts, fPath)
 		if err != nil {
@@ -31,7 +31,10 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/parquet"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/csv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/html"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/markdown"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/tabular"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/xml"
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
 )
 
@@ -44,6 +47,10 @@ const (
 	FormatNull // used for profiling
 	FormatVertical
 	FormatParquet
+	FormatMarkdown
+	FormatHTML
+	FormatXML
+	FormatNDJson
 )
 
 type PrintSummaryBehavior byte
@@ -105,6 +112,18 @@ func prettyPrintResultsWithSummary(ctx *sql.Context, resultFormat PrintResultFor
 		if err != nil {
 			return err
 		}
+	case FormatMarkdown:
+		wr = markdown.NewMarkdownSqlWriter(iohelp.NopWrCloser(cli.CliOut), sqlSch)
+	case FormatHTML:
+		wr = html.NewHTMLSqlWriter(iohelp.NopWrCloser(cli.CliOut), sqlSch)
+	case FormatXML:
+		wr = xml.NewXMLSqlWriter(iohelp.NopWrCloser(cli.CliOut), sqlSch)
+	case FormatNDJson:
+		var err error
+		wr, err = json.NewNDJSONSqlWriter(iohelp.NopWrCloser(cli.CliOut), sqlSch)
+		if err != nil {
+			return err
+		}
 	}
 
 	numRows, err := writeResultSet(ctx, rowIter, wr)
@@ -126,7 +145,7 @@ func prettyPrintResultsWithSummary(ctx *sql.Context, resultFormat PrintResultFor
 
 	// Some output formats need a final newline printed, others do not
 	switch resultFormat {
-	case FormatJson, FormatTabular, FormatVertical:
+	case FormatJson, FormatTabular, FormatVertical, FormatMarkdown:
 		return iohelp.WriteLine(cli.CliOut, "")
 	default:
 		return nil
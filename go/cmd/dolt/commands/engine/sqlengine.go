@@ -135,11 +135,18 @@ func NewSqlEngine(
 	}
 	pro = pro.WithRemoteDialer(mrEnv.RemoteDialProvider())
 
+	// A transaction which was committing to more than one database when the server last stopped is rolled back
+	// before any queries can see its partial writes.
+	if err := dsess.RecoverTransactionIntents(ctx, pro.FileSystem(), pro.DoltDatabases()); err != nil {
+		return nil, err
+	}
+
 	config.ClusterController.RegisterStoredProcedures(pro)
 	if config.ClusterController != nil {
 		pro.InitDatabaseHooks = append(pro.InitDatabaseHooks, cluster.NewInitDatabaseHook(config.ClusterController, bThreads))
 		pro.DropDatabaseHooks = append(pro.DropDatabaseHooks, config.ClusterController.DropDatabaseHook())
 		config.ClusterController.SetDropDatabase(pro.DropDatabase)
+		pro.SetReplicaLagFunc(config.ClusterController.ReplicaLag)
 	}
 
 	sqlEngine := &SqlEngine{}
@@ -195,6 +202,7 @@ func NewSqlEngine(
 	engine.Analyzer.Catalog.StatsProvider = statsPro
 
 	engine.Analyzer.ExecBuilder = rowexec.DefaultBuilder
+	engine.Parser = dsqle.NewDoltParser(engine.Parser)
 	sessFactory := doltSessionFactory(pro, statsPro, mrEnv.Config(), bcController, config.Autocommit)
 	sqlEngine.provider = pro
 	sqlEngine.contextFactory = sqlContextFactory()
@@ -23,10 +23,12 @@ import (
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
 	"github.com/gocraft/dbr/v2"
 	"github.com/gocraft/dbr/v2/dialect"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
@@ -85,7 +87,9 @@ func (cmd LogCmd) Docs() *cli.CommandDocumentation {
 }
 
 func (cmd LogCmd) ArgParser() *argparser.ArgParser {
-	return cli.CreateLogArgParser(false)
+	ap := cli.CreateLogArgParser(false)
+	ap.SupportsString(FormatFlag, "r", "result output format", "Prints the log as a result set in the given format. Valid values are the same as for dolt sql. Cannot be combined with --oneline or --stat.")
+	return ap
 }
 
 func (cmd LogCmd) RequiresRepo() bool {
@@ -102,6 +106,15 @@ func (cmd LogCmd) logWithLoggerFunc(ctx context.Context, commandStr string, args
 	help, _ := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, logDocs, ap))
 	apr := cli.ParseArgsOrDie(ap, args, help)
 
+	if apr.Contains(FormatFlag) {
+		if apr.Contains(cli.OneLineFlag) || apr.Contains(cli.StatFlag) {
+			return handleErrAndExit(fmt.Errorf("--%s cannot be combined with --%s or --%s", FormatFlag, cli.OneLineFlag, cli.StatFlag))
+		}
+		if _, verr := GetResultFormat(apr.MustGetValue(FormatFlag)); verr != nil {
+			return HandleVErrAndExitCode(verr, nil)
+		}
+	}
+
 	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
 	if err != nil {
 		return handleErrAndExit(err)
@@ -287,9 +300,44 @@ func logCommits(apr *argparser.ArgParseResults, commitHashes []sql.Row, queryist
 		commitsInfo = append(commitsInfo, *commit)
 	}
 
+	if format, ok := apr.GetValue(FormatFlag); ok {
+		return logResultSet(sqlCtx, apr, format, commitsInfo)
+	}
+
 	return logToStdOut(apr, commitsInfo, sqlCtx, queryist)
 }
 
+// logResultSet prints the commits given as a result set in the format given, with a row for each commit.
+func logResultSet(sqlCtx *sql.Context, apr *argparser.ArgParseResults, format string, commits []CommitInfo) error {
+	resultFormat, verr := GetResultFormat(format)
+	if verr != nil {
+		return verr
+	}
+
+	sch := sql.Schema{
+		{Name: "commit_hash", Type: types.Text},
+		{Name: "author", Type: types.Text},
+		{Name: "email", Type: types.Text},
+		{Name: "date", Type: types.Datetime},
+		{Name: "message", Type: types.Text},
+	}
+	showParents := apr.Contains(cli.ParentsFlag)
+	if showParents {
+		sch = append(sch, &sql.Column{Name: "parents", Type: types.Text})
+	}
+
+	rows := make([]sql.Row, 0, len(commits))
+	for _, comm := range commits {
+		row := sql.Row{comm.commitHash, comm.commitMeta.Name, comm.commitMeta.Email, comm.commitMeta.Time().UTC(), comm.commitMeta.Description}
+		if showParents {
+			row = append(row, strings.Join(comm.parentHashes, ", "))
+		}
+		rows = append(rows, row)
+	}
+
+	return engine.PrettyPrintResults(sqlCtx, resultFormat, sch, sql.RowsToRowIter(rows...))
+}
+
 func logCompact(pager *outputpager.Pager, apr *argparser.ArgParseResults, commits []CommitInfo, sqlCtx *sql.Context, queryist cli.Queryist) error {
 	for _, comm := range commits {
 		if len(comm.parentHashes) < apr.GetIntOrDefault(cli.MinParentsFlag, 0) {
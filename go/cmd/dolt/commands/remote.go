@@ -55,6 +55,8 @@ aws-creds-type specifies the means by which credentials should be retrieved in o
 	
 GCP remote urls should be of the form gs://gcs-bucket/database and will use the credentials setup using the gcloud command line available from Google.
 
+S3 remote urls should be of the form {{.EmphasisLeft}}s3://s3-bucket/database{{.EmphasisRight}}, and can be used with S3 compatible stores such as MinIO and Ceph as well as with AWS. Unlike aws remotes they do not need a DynamoDB table. The endpoint of the store is set with {{.EmphasisLeft}}--s3-endpoint{{.EmphasisRight}}, and {{.EmphasisLeft}}--s3-force-path-style{{.EmphasisRight}} addresses the bucket in the path of requests rather than in the host name, as most S3 compatible stores require. The aws-region and aws-creds parameters above apply to them too.
+
 The local filesystem can be used as a remote by providing a repository url in the format file://absolute path. See https://en.wikipedia.org/wiki/File_URI_scheme
 
 {{.EmphasisLeft}}remove{{.EmphasisRight}}, {{.EmphasisLeft}}rm{{.EmphasisRight}}
@@ -62,7 +64,7 @@ Remove the remote named {{.LessThan}}name{{.GreaterThan}}. All remote-tracking b
 
 	Synopsis: []string{
 		"[-v | --verbose]",
-		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
+		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--s3-endpoint {{.LessThan}}url{{.GreaterThan}}] [--s3-force-path-style] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
 		"remove {{.LessThan}}name{{.GreaterThan}}",
 	},
 }
@@ -101,6 +103,9 @@ func (cmd RemoteCmd) ArgParser() *argparser.ArgParser {
 
 	ap.SupportsString(dbfactory.OSSCredsFileParam, "", "file", "OSS credentials file")
 	ap.SupportsString(dbfactory.OSSCredsProfile, "", "profile", "OSS profile to use")
+
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of the S3 compatible store")
+	ap.SupportsFlag(dbfactory.S3ForcePathStyleParam, "", "Address the S3 bucket in the path of requests rather than in the host name")
 	return ap
 }
 
@@ -215,9 +220,14 @@ func parseRemoteArgs(apr *argparser.ArgParseResults, scheme, remoteUrl string) (
 		err = cli.AddAWSParams(remoteUrl, apr, params)
 	case dbfactory.OSSScheme:
 		err = cli.AddOSSParams(remoteUrl, apr, params)
+	case dbfactory.S3Scheme:
+		err = cli.AddS3Params(remoteUrl, apr, params)
 	default:
 		err = cli.VerifyNoAwsParams(apr)
 	}
+	if err == nil && scheme != dbfactory.S3Scheme {
+		err = cli.VerifyNoS3Params(apr)
+	}
 	if err != nil {
 		return nil, errhand.VerboseErrorFromError(err)
 	}
@@ -16,6 +16,7 @@ package schcmds
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -55,6 +56,14 @@ const (
 	floatThresholdParam = "float-threshold"
 	keepTypesParam      = "keep-types"
 	delimParam          = "delim"
+	reportFlag          = "report"
+	inferDecimalFlag    = "infer-decimal"
+	inferEnumFlag       = "infer-enum"
+	dateFormatParam     = "date-format"
+	sampleRowsParam     = "sample-rows"
+
+	// autoPks is the value of --pks which proposes a primary key from the data
+	autoPks = "auto"
 )
 
 var MappingFileHelp = "A mapping file is json in the format:" + `
@@ -69,7 +78,7 @@ where source_field_name is the name of a field in the file being imported and de
 
 var schImportDocs = cli.CommandDocumentationContent{
 	ShortDesc: "Creates or updates a table by inferring a schema from a file containing sample data.",
-	LongDesc: `If {{.EmphasisLeft}}--create | -c{{.EmphasisRight}} is given the operation will create {{.LessThan}}table{{.GreaterThan}} with a schema that it infers from the supplied file. One or more primary key columns must be specified using the {{.EmphasisLeft}}--pks{{.EmphasisRight}} parameter.
+	LongDesc: `If {{.EmphasisLeft}}--create | -c{{.EmphasisRight}} is given the operation will create {{.LessThan}}table{{.GreaterThan}} with a schema that it infers from the supplied file. One or more primary key columns must be specified using the {{.EmphasisLeft}}--pks{{.EmphasisRight}} parameter. If {{.EmphasisLeft}}--pks auto{{.EmphasisRight}} is given, the first column, or combination of up to three columns, whose values are never null and are unique in the file, or in the rows read with {{.EmphasisLeft}}--sample-rows{{.EmphasisRight}}, is used as the primary key.
 
 If {{.EmphasisLeft}}--update | -u{{.EmphasisRight}} is given the operation will update {{.LessThan}}table{{.GreaterThan}} any additional columns, or change the types of columns based on the file supplied.  If the {{.EmphasisLeft}}--keep-types{{.EmphasisRight}} parameter is supplied then the types for existing columns will not be modified, even if they differ from what is in the supplied file.
 
@@ -81,13 +90,15 @@ A mapping file can be used to map fields between the file being imported and the
 
 In create, update, and replace scenarios the file's extension is used to infer the type of the file.  If a file does not have the expected extension then the {{.EmphasisLeft}}--file-type{{.EmphasisRight}} parameter should be used to explicitly define the format of the file in one of the supported formats (Currently only csv is supported).  For files separated by a delimiter other than a ',', the --delim parameter can be used to specify a delimiter.
 
-If the parameter {{.EmphasisLeft}}--dry-run{{.EmphasisRight}} is supplied a sql statement will be generated showing what would be executed if this were run without the --dry-run flag
+If the parameter {{.EmphasisLeft}}--dry-run{{.EmphasisRight}} is supplied a sql statement will be generated showing what would be executed if this were run without the --dry-run flag. If {{.EmphasisLeft}}--report{{.EmphasisRight}} is also supplied, a JSON report is printed instead, with the statement, the number of rows sampled, the primary key, and for each column the type inferred, the types of its values, its number of nulls, and its number of distinct values.
+
+{{.EmphasisLeft}}--infer-decimal{{.EmphasisRight}} infers numbers with a fractional component as DECIMAL columns with the precision and scale of the data, rather than as floats. {{.EmphasisLeft}}--infer-enum{{.EmphasisRight}} infers string columns with a few repeated values as ENUM columns of those values. {{.EmphasisLeft}}--date-format{{.EmphasisRight}} gives the format, in the syntax of STR_TO_DATE, of dates and times which are not in a format MySQL recognizes, such as {{.EmphasisLeft}}%d/%m/%Y{{.EmphasisRight}}. {{.EmphasisLeft}}--sample-rows{{.EmphasisRight}} infers the schema from the first rows of the file, each of which is read, rather than from rows sampled throughout it.
 
 {{.EmphasisLeft}}--float-threshold{{.EmphasisRight}} is the threshold at which a string representing a floating point number should be interpreted as a float versus an int.  If FloatThreshold is 0.0 then any number with a decimal point will be interpreted as a float (such as 0.0, 1.0, etc).  If FloatThreshold is 1.0 then any number with a decimal point will be converted to an int (0.5 will be the int 0, 1.99 will be the int 1, etc.  If the FloatThreshold is 0.001 then numbers with a fractional component greater than or equal to 0.001 will be treated as a float (1.0 would be an int, 1.0009 would be an int, 1.001 would be a float, 1.1 would be a float, etc)
 `,
 
 	Synopsis: []string{
-		`[--create|--replace] [--force] [--dry-run] [--lower|--upper] [--keep-types] [--file-type <type>] [--float-threshold] [--map {{.LessThan}}mapping-file{{.GreaterThan}}] [--delim {{.LessThan}}delimiter{{.GreaterThan}}]--pks {{.LessThan}}field{{.GreaterThan}},... {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}`,
+		`[--create|--replace] [--force] [--dry-run] [--lower|--upper] [--keep-types] [--file-type <type>] [--float-threshold|--infer-decimal] [--infer-enum] [--date-format {{.LessThan}}format{{.GreaterThan}}] [--sample-rows {{.LessThan}}n{{.GreaterThan}}] [--report] [--map {{.LessThan}}mapping-file{{.GreaterThan}}] [--delim {{.LessThan}}delimiter{{.GreaterThan}}]--pks {{.LessThan}}field{{.GreaterThan}},... {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}`,
 	},
 }
 
@@ -110,6 +121,7 @@ type importOptions struct {
 	keepTypes      bool
 	colMapper      rowconv.NameMapper
 	floatThreshold float64
+	inferOpts      actions.InferenceOptions
 }
 
 func (im *importOptions) ColNameMapper() rowconv.NameMapper {
@@ -118,6 +130,9 @@ func (im *importOptions) ColNameMapper() rowconv.NameMapper {
 func (im *importOptions) FloatThreshold() float64 {
 	return im.floatThreshold
 }
+func (im *importOptions) InferenceOptions() actions.InferenceOptions {
+	return im.inferOpts
+}
 
 type ImportCmd struct{}
 
@@ -155,6 +170,11 @@ func (cmd ImportCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsString(mappingParam, "m", "mapping-file", "A file that can map a column name in {{.LessThan}}file{{.GreaterThan}} to a new value.")
 	ap.SupportsString(floatThresholdParam, "", "float", "Minimum value at which the fractional component of a value must exceed in order to be considered a float.")
 	ap.SupportsString(delimParam, "", "delimiter", "Specify a delimiter for a csv style file with a non-comma delimiter.")
+	ap.SupportsFlag(reportFlag, "", "With --dry-run, print a JSON report of the columns inferred instead of the sql statement.")
+	ap.SupportsFlag(inferDecimalFlag, "", "Infer numbers with a fractional component as DECIMAL columns rather than floats.")
+	ap.SupportsFlag(inferEnumFlag, "", "Infer string columns with a few repeated values as ENUM columns.")
+	ap.SupportsString(dateFormatParam, "", "format", "The STR_TO_DATE format of dates and times which aren't in a format MySQL recognizes, such as %d/%m/%Y.")
+	ap.SupportsInt(sampleRowsParam, "", "rows", "Infer the schema from the first rows of the file rather than from rows sampled throughout it.")
 	return ap
 }
 
@@ -253,6 +273,35 @@ func getSchemaImportArgs(ctx context.Context, apr *argparser.ArgParseResults, dE
 		return nil, errhand.BuildDError("error: no valid columns provided in --pks argument").Build()
 	}
 
+	inferOpts := actions.InferenceOptions{
+		Decimals:   apr.Contains(inferDecimalFlag),
+		DateFormat: apr.GetValueOrDefault(dateFormatParam, ""),
+	}
+	if apr.Contains(inferEnumFlag) {
+		inferOpts.EnumMaxValues = actions.DefaultEnumMaxValues
+	}
+	if apr.Contains(sampleRowsParam) {
+		n, ok := apr.GetInt(sampleRowsParam)
+		if !ok || n <= 0 {
+			return nil, errhand.BuildDError("error: --%s must be a positive number of rows", sampleRowsParam).Build()
+		}
+		inferOpts.SampleRows = n
+	}
+	if len(pks) == 1 && strings.EqualFold(pks[0], autoPks) {
+		if op != CreateOp {
+			return nil, errhand.BuildDError("error: --%s %s is only supported for create operations", pksParam, autoPks).Build()
+		}
+		pks = nil
+		inferOpts.PrimaryKey = true
+	}
+
+	if apr.Contains(reportFlag) && !apr.Contains(dryRunFlag) {
+		return nil, errhand.BuildDError("error: --%s is only supported with --%s", reportFlag, dryRunFlag).Build()
+	}
+	if apr.ContainsAll(inferDecimalFlag, floatThresholdParam) {
+		return nil, errhand.BuildDError("parameters %s and %s are mutually exclusive", inferDecimalFlag, floatThresholdParam).Build()
+	}
+
 	mappingFile := apr.GetValueOrDefault(mappingParam, "")
 	colMapper, err := rowconv.NameMapperFromFile(mappingFile, dEnv.FS)
 
@@ -278,6 +327,7 @@ func getSchemaImportArgs(ctx context.Context, apr *argparser.ArgParseResults, dE
 		keepTypes:      apr.Contains(keepTypesParam),
 		colMapper:      colMapper,
 		floatThreshold: floatThreshold,
+		inferOpts:      inferOpts,
 	}, nil
 }
 
@@ -292,7 +342,7 @@ func importSchema(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPars
 		return verr
 	}
 
-	sch, verr := inferSchemaFromFile(ctx, dEnv.DoltDB.ValueReadWriter().Format(), impArgs, root)
+	sch, report, verr := inferSchemaFromFile(ctx, dEnv.DoltDB.ValueReadWriter().Format(), impArgs, root)
 	if verr != nil {
 		return verr
 	}
@@ -310,6 +360,10 @@ func importSchema(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPars
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
+
+	if apr.Contains(reportFlag) {
+		return printInferenceReport(tblName, stmt, report)
+	}
 	cli.Println(stmt)
 
 	if !apr.Contains(dryRunFlag) {
@@ -324,6 +378,27 @@ func importSchema(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPars
 	return nil
 }
 
+// inferenceReportJSON is the report printed by --report, which adds the statement creating the table to the report of
+// the inference.
+type inferenceReportJSON struct {
+	Table           string `json:"table"`
+	CreateStatement string `json:"create_statement"`
+	*actions.InferenceReport
+}
+
+func printInferenceReport(tblName, stmt string, report *actions.InferenceReport) errhand.VerboseError {
+	out, err := json.MarshalIndent(inferenceReportJSON{
+		Table:           tblName,
+		CreateStatement: stmt,
+		InferenceReport: report,
+	}, "", "  ")
+	if err != nil {
+		return errhand.BuildDError("error: failed to write the report").AddCause(err).Build()
+	}
+	cli.Println(string(out))
+	return nil
+}
+
 func putEmptyTableWithSchema(ctx context.Context, tblName string, root doltdb.RootValue, sch schema.Schema) (doltdb.RootValue, errhand.VerboseError) {
 	tbl, tblExists, err := root.GetTable(ctx, doltdb.TableName{Name: tblName})
 	if err != nil {
@@ -361,7 +436,7 @@ func putEmptyTableWithSchema(ctx context.Context, tblName string, root doltdb.Ro
 	return root, nil
 }
 
-func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts *importOptions, root doltdb.RootValue) (schema.Schema, errhand.VerboseError) {
+func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts *importOptions, root doltdb.RootValue) (schema.Schema, *actions.InferenceReport, errhand.VerboseError) {
 	if impOpts.fileType[0] == '.' {
 		impOpts.fileType = impOpts.fileType[1:]
 	}
@@ -377,13 +452,13 @@ func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts
 	case "psv":
 		csvInfo.SetDelim("|")
 	default:
-		return nil, errhand.BuildDError("error: unsupported file type '%s'", impOpts.fileType).Build()
+		return nil, nil, errhand.BuildDError("error: unsupported file type '%s'", impOpts.fileType).Build()
 	}
 
 	f, err := os.Open(impOpts.fileName)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to open '%s'", impOpts.fileName).Build()
+		return nil, nil, errhand.BuildDError("error: failed to open '%s'", impOpts.fileName).Build()
 	}
 
 	defer f.Close()
@@ -391,18 +466,23 @@ func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts
 	rd, err = csv.NewCSVReader(nbf, f, csvInfo)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to create a CSVReader.").AddCause(err).Build()
+		return nil, nil, errhand.BuildDError("error: failed to create a CSVReader.").AddCause(err).Build()
 	}
 
 	defer rd.Close(ctx)
 
-	infCols, err := actions.InferColumnTypesFromTableReader(ctx, rd, impOpts)
+	infCols, report, err := actions.InferColumnTypesAndReportFromTableReader(ctx, rd, impOpts)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to infer schema").AddCause(err).Build()
+		return nil, nil, errhand.BuildDError("error: failed to infer schema").AddCause(err).Build()
+	}
+
+	if impOpts.inferOpts.PrimaryKey {
+		impOpts.PkCols = report.PrimaryKey
 	}
 
-	return CombineColCollections(ctx, root, infCols, impOpts)
+	sch, verr := CombineColCollections(ctx, root, infCols, impOpts)
+	return sch, report, verr
 }
 
 func CombineColCollections(ctx context.Context, root doltdb.RootValue, inferredCols *schema.ColCollection, impOpts *importOptions) (schema.Schema, errhand.VerboseError) {
@@ -0,0 +1,339 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schcmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schmigrations"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	migrateDirParam = "dir"
+
+	migrateUp     = "up"
+	migrateDown   = "down"
+	migrateStatus = "status"
+	migrateDrift  = "drift"
+)
+
+var migrateDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Applies, rolls back and compares versioned schema migrations.",
+	LongDesc: `{{.EmphasisLeft}}dolt schema migrate{{.EmphasisRight}} manages the schema migrations of the current branch. Migrations are stored in the {{.EmphasisLeft}}dolt_schemas{{.EmphasisRight}} table and listed, with the commit which applied them, by the {{.EmphasisLeft}}dolt_migrations{{.EmphasisRight}} system table.
+
+{{.EmphasisLeft}}dolt schema migrate up{{.EmphasisRight}} applies the pending migrations in the order of their sequence numbers, up to the one given with {{.EmphasisLeft}}--to{{.EmphasisRight}}. With {{.EmphasisLeft}}--dir{{.EmphasisRight}}, the scripts of a directory are added as migrations before they are applied. Scripts are named {{.EmphasisLeft}}{{.LessThan}}sequence{{.GreaterThan}}_{{.LessThan}}name{{.GreaterThan}}.up.sql{{.EmphasisRight}} and {{.EmphasisLeft}}{{.LessThan}}sequence{{.GreaterThan}}_{{.LessThan}}name{{.GreaterThan}}.down.sql{{.EmphasisRight}}.
+
+{{.EmphasisLeft}}dolt schema migrate down{{.EmphasisRight}} rolls back the last applied migration, or all applied migrations after the one given with {{.EmphasisLeft}}--to{{.EmphasisRight}}.
+
+{{.EmphasisLeft}}dolt schema migrate status{{.EmphasisRight}} lists the migrations of the current branch.
+
+{{.EmphasisLeft}}dolt schema migrate drift{{.EmphasisRight}} lists the migrations which differ between the current branch and {{.LessThan}}branch{{.GreaterThan}}, and exits with a non-zero status if there are any.
+
+When both sides of a merge added a migration with the same sequence number, the merge gives the migration which has not been applied yet a new sequence number. If both were applied, the merge fails.`,
+	Synopsis: []string{
+		"up [--dir {{.LessThan}}directory{{.GreaterThan}}] [--to {{.LessThan}}sequence{{.GreaterThan}}]",
+		"down [--to {{.LessThan}}sequence{{.GreaterThan}}]",
+		"status",
+		"drift {{.LessThan}}branch{{.GreaterThan}}",
+	},
+}
+
+type MigrateCmd struct{}
+
+var _ cli.Command = MigrateCmd{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd MigrateCmd) Name() string {
+	return "migrate"
+}
+
+// Description returns a description of the command
+func (cmd MigrateCmd) Description() string {
+	return "Applies, rolls back and compares versioned schema migrations."
+}
+
+func (cmd MigrateCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(migrateDocs, ap)
+}
+
+func (cmd MigrateCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 2)
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"branch", "The branch to compare the migrations of the current branch to."})
+	ap.SupportsString(migrateDirParam, "", "directory", "A directory of migration scripts to add before applying migrations.")
+	ap.SupportsInt(cli.ToParam, "", "sequence", "The sequence number of the last migration to apply, or of the last migration to keep when rolling back.")
+	return ap
+}
+
+// EventType returns the type of the event to log
+func (cmd MigrateCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_SCHEMA
+}
+
+// Exec executes the command
+func (cmd MigrateCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, migrateDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	action := migrateStatus
+	if apr.NArg() > 0 {
+		action = apr.Arg(0)
+	}
+	if (action == migrateDrift) != (apr.NArg() == 2) {
+		usage()
+		return 1
+	}
+	if apr.Contains(migrateDirParam) && action != migrateUp {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("--%s is only supported by %s", migrateDirParam, migrateUp).Build(), usage)
+	}
+
+	eng, dbName, err := engine.NewSqlEngineForEnv(ctx, dEnv)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("Failed to build sql engine.").AddCause(err).Build(), usage)
+	}
+	defer eng.Close()
+	sqlCtx, err := eng.NewLocalContext(ctx)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	sqlCtx.SetCurrentDatabase(dbName)
+	// Like `dolt sql`, the session autocommits so that the migrations persist to the working set
+	err = sqlCtx.SetSessionVariable(sqlCtx, sql.AutoCommitSessionVar, true)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	queryist := cli.Queryist(eng)
+
+	switch action {
+	case migrateUp:
+		err = migrateUpAction(queryist, sqlCtx, apr)
+	case migrateDown:
+		err = migrateDownAction(queryist, sqlCtx, apr)
+	case migrateStatus:
+		err = migrateStatusAction(queryist, sqlCtx)
+	case migrateDrift:
+		var drift bool
+		drift, err = migrateDriftAction(queryist, sqlCtx, apr.Arg(1))
+		if err == nil && drift {
+			return 1
+		}
+	default:
+		err = fmt.Errorf("unknown action '%s', expected one of %s, %s, %s or %s", action, migrateUp, migrateDown, migrateStatus, migrateDrift)
+	}
+	return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+}
+
+func migrateUpAction(queryist cli.Queryist, sqlCtx *sql.Context, apr *argparser.ArgParseResults) error {
+	if dir, ok := apr.GetValue(migrateDirParam); ok {
+		if err := addMigrationScripts(queryist, sqlCtx, dir); err != nil {
+			return err
+		}
+	}
+
+	query := "CALL DOLT_MIGRATE_APPLY()"
+	if to, ok := apr.GetInt(cli.ToParam); ok {
+		query = fmt.Sprintf("CALL DOLT_MIGRATE_APPLY('--%s', '%d')", cli.ToParam, to)
+	}
+	rows, err := commands.GetRowsForSql(queryist, sqlCtx, query)
+	if err != nil {
+		return err
+	}
+	cli.Printf("Applied %v schema migration(s)\n", rows[0][0])
+	return nil
+}
+
+func migrateDownAction(queryist cli.Queryist, sqlCtx *sql.Context, apr *argparser.ArgParseResults) error {
+	query := "CALL DOLT_MIGRATE_ROLLBACK()"
+	if to, ok := apr.GetInt(cli.ToParam); ok {
+		query = fmt.Sprintf("CALL DOLT_MIGRATE_ROLLBACK('--%s', '%d')", cli.ToParam, to)
+	}
+	rows, err := commands.GetRowsForSql(queryist, sqlCtx, query)
+	if err != nil {
+		return err
+	}
+	cli.Printf("Rolled back %v schema migration(s)\n", rows[0][0])
+	return nil
+}
+
+func migrateStatusAction(queryist cli.Queryist, sqlCtx *sql.Context) error {
+	sch, rowIter, err := queryist.Query(sqlCtx, "SELECT sequence, name, status, applied_commit, applied_at FROM dolt_migrations")
+	if err != nil {
+		return err
+	}
+	return engine.PrettyPrintResults(sqlCtx, engine.FormatTabular, sch, rowIter)
+}
+
+// migrateDriftAction prints the migrations which differ between the current branch and |branch|, and returns whether
+// there were any.
+func migrateDriftAction(queryist cli.Queryist, sqlCtx *sql.Context, branch string) (bool, error) {
+	rows, err := commands.GetRowsForSql(queryist, sqlCtx, "SELECT DATABASE()")
+	if err != nil {
+		return false, err
+	}
+	if len(rows) == 0 || rows[0][0] == nil {
+		return false, fmt.Errorf("no database selected")
+	}
+	dbName := fmt.Sprint(rows[0][0])
+
+	ours, err := loadMigrations(queryist, sqlCtx, "dolt_migrations")
+	if err != nil {
+		return false, err
+	}
+	theirs, err := loadMigrations(queryist, sqlCtx, fmt.Sprintf("`%s/%s`.dolt_migrations", dbName, branch))
+	if err != nil {
+		return false, err
+	}
+
+	drift := schmigrations.Diff(ours, theirs)
+	if len(drift) == 0 {
+		cli.Printf("No schema migration drift between the current branch and %s\n", branch)
+		return false, nil
+	}
+
+	driftSch := sql.Schema{
+		{Name: "name", Type: types.Text},
+		{Name: "drift", Type: types.Text},
+		{Name: "sequence", Type: types.Int64, Nullable: true},
+		{Name: branch + " sequence", Type: types.Int64, Nullable: true},
+	}
+	driftRows := make([]sql.Row, len(drift))
+	for i, d := range drift {
+		row := sql.Row{d.Name, string(d.Kind), nil, nil}
+		if d.From != nil {
+			row[2] = d.From.Sequence
+		}
+		if d.To != nil {
+			row[3] = d.To.Sequence
+		}
+		driftRows[i] = row
+	}
+	return true, engine.PrettyPrintResults(sqlCtx, engine.FormatTabular, driftSch, sql.RowsToRowIter(driftRows...))
+}
+
+// loadMigrations reads the migrations listed by |table|, a dolt_migrations table.
+func loadMigrations(queryist cli.Queryist, sqlCtx *sql.Context, table string) ([]schmigrations.Migration, error) {
+	rows, err := commands.GetRowsForSql(queryist, sqlCtx, "SELECT sequence, name, status, up, down FROM "+table)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]schmigrations.Migration, len(rows))
+	for i, row := range rows {
+		seq, err := strconv.ParseInt(fmt.Sprint(row[0]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		migrations[i] = schmigrations.Migration{
+			Sequence: seq,
+			Name:     fmt.Sprint(row[1]),
+			Applied:  fmt.Sprint(row[2]) == "applied",
+			Up:       fmt.Sprint(row[3]),
+		}
+		if row[4] != nil {
+			migrations[i].Down = fmt.Sprint(row[4])
+		}
+	}
+	return migrations, nil
+}
+
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// addMigrationScripts adds the migrations in the scripts of |dir| which are not yet stored on the current branch. It
+// fails if the scripts of a stored migration were changed.
+func addMigrationScripts(queryist cli.Queryist, sqlCtx *sql.Context, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	scripts := make(map[string]*schmigrations.Migration)
+	for _, entry := range entries {
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		seq, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid sequence number in %s: %w", entry.Name(), err)
+		}
+		contents, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		m, ok := scripts[match[2]]
+		if !ok {
+			m = &schmigrations.Migration{Sequence: seq, Name: match[2]}
+			scripts[match[2]] = m
+		} else if m.Sequence != seq {
+			return fmt.Errorf("the scripts of schema migration '%s' have different sequence numbers", m.Name)
+		}
+		if match[3] == migrateUp {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	existing, err := loadMigrations(queryist, sqlCtx, "dolt_migrations")
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		m, ok := scripts[e.Name]
+		if !ok {
+			continue
+		}
+		if m.Sequence != e.Sequence || m.Up != e.Up || m.Down != e.Down {
+			return fmt.Errorf("the scripts of schema migration '%s' were changed after it was added", e.Name)
+		}
+		delete(scripts, e.Name)
+	}
+
+	var added []schmigrations.Migration
+	for _, m := range scripts {
+		if len(m.Up) == 0 {
+			return fmt.Errorf("schema migration '%s' has no up script", m.Name)
+		}
+		added = append(added, *m)
+	}
+	schmigrations.Sort(added)
+	for _, m := range added {
+		_, err = commands.InterpolateAndRunQuery(queryist, sqlCtx, "CALL DOLT_MIGRATE_ADD('--sequence', ?, ?, ?, ?)", strconv.FormatInt(m.Sequence, 10), m.Name, m.Up, m.Down)
+		if err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		cli.Printf("Added %d schema migration(s)\n", len(added))
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 var Commands = cli.NewSubCommandHandler("schema", "Commands for showing and importing table schemas.", []cli.Command{
 	ExportCmd{},
 	ImportCmd{},
+	MigrateCmd{},
 	ShowCmd{},
 	TagsCmd{},
 	UpdateTagCmd{},
@@ -20,8 +20,8 @@ import (
 	"io"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -31,7 +31,6 @@ import (
 	"github.com/dolthub/vitess/go/vt/sqlparser"
 	"github.com/dolthub/vitess/go/vt/vterrors"
 	"github.com/fatih/color"
-	"github.com/flynn-archive/go-shlex"
 	textunicode "golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 	"gopkg.in/src-d/go-errors.v1"
@@ -43,8 +42,10 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
 	"github.com/dolthub/dolt/go/libraries/utils/osutil"
 )
@@ -95,7 +96,7 @@ const (
 	ProfileFlag           = "profile"
 
 	welcomeMsg = `# Welcome to the DoltSQL shell.
-# Statements must be terminated with ';'.
+# Statements must be terminated with ';'. Type \? for the shell's meta commands.
 # "exit" or "quit" (or Ctrl-D) to exit.`
 )
 
@@ -134,7 +135,7 @@ func (cmd SqlCmd) Docs() *cli.CommandDocumentation {
 func (cmd SqlCmd) ArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 0)
 	ap.SupportsString(QueryFlag, "q", "SQL query to run", "Runs a single query and exits.")
-	ap.SupportsString(FormatFlag, "r", "result output format", "How to format result output. Valid values are tabular, csv, json, vertical, and parquet. Defaults to tabular.")
+	ap.SupportsString(FormatFlag, "r", "result output format", "How to format result output. Valid values are tabular, csv, json, ndjson, vertical, parquet, markdown, html, and xml. Defaults to tabular.")
 	ap.SupportsString(saveFlag, "s", "saved query name", "Used with --query, save the query to the query catalog with the name provided. Saved queries can be examined in the dolt_query_catalog system table.")
 	ap.SupportsString(executeFlag, "x", "saved query name", "Executes a saved query with the given name.")
 	ap.SupportsFlag(listSavedFlag, "l", "List all saved queries.")
@@ -260,7 +261,7 @@ func (cmd SqlCmd) Exec(ctx context.Context, commandStr string, args []string, dE
 		}
 
 		if isTty {
-			err := execShell(sqlCtx, queryist, format)
+			err := execShell(sqlCtx, queryist, format, cliCtx, dEnv)
 			if err != nil {
 				return sqlHandleVErrAndExitCode(queryist, errhand.VerboseErrorFromError(err), usage)
 			}
@@ -303,7 +304,7 @@ func (cmd SqlCmd) handleLegacyArguments(ap *argparser.ArgParser, commandStr stri
 	if err != nil {
 		legacyParser := argparser.NewArgParserWithMaxArgs(cmd.Name(), 0)
 		legacyParser.SupportsString(QueryFlag, "q", "SQL query to run", "Runs a single query and exits.")
-		legacyParser.SupportsString(FormatFlag, "r", "result output format", "How to format result output. Valid values are tabular, csv, json, vertical, and parquet. Defaults to tabular.")
+		legacyParser.SupportsString(FormatFlag, "r", "result output format", "How to format result output. Valid values are tabular, csv, json, ndjson, vertical, parquet, markdown, html, and xml. Defaults to tabular.")
 		legacyParser.SupportsString(saveFlag, "s", "saved query name", "Used with --query, save the query to the query catalog with the name provided. Saved queries can be examined in the dolt_query_catalog system table.")
 		legacyParser.SupportsString(executeFlag, "x", "saved query name", "Executes a saved query with the given name.")
 		legacyParser.SupportsFlag(listSavedFlag, "l", "List all saved queries.")
@@ -526,8 +527,16 @@ func GetResultFormat(format string) (engine.PrintResultFormat, errhand.VerboseEr
 		return engine.FormatVertical, nil
 	case "parquet":
 		return engine.FormatParquet, nil
+	case "markdown":
+		return engine.FormatMarkdown, nil
+	case "html":
+		return engine.FormatHTML, nil
+	case "xml":
+		return engine.FormatXML, nil
+	case "ndjson":
+		return engine.FormatNDJson, nil
 	default:
-		return engine.FormatTabular, errhand.BuildDError("Invalid argument for --result-format. Valid values are tabular, csv, json").Build()
+		return engine.FormatTabular, errhand.BuildDError("Invalid argument for --result-format. Valid values are tabular, csv, json, ndjson, vertical, parquet, markdown, html and xml").Build()
 	}
 }
 
@@ -626,7 +635,7 @@ func execBatchMode(ctx *sql.Context, qryist cli.Queryist, input io.Reader, conti
 
 		sqlMode := sql.LoadSqlMode(ctx)
 
-		sqlStatement, err := sqlparser.ParseWithOptions(query, sqlMode.ParserOptions())
+		sqlStatement, _, _, err := cliParser.ParseWithOptions(query, ';', false, sqlMode.ParserOptions())
 		if err == sqlparser.ErrEmpty {
 			continue
 		} else if err != nil {
@@ -685,9 +694,8 @@ func buildBatchSqlErr(stmtStartLine int, query string, err error) error {
 
 // execShell starts a SQL shell. Returns when the user exits the shell. The Root of the sqlEngine may
 // be updated by any queries which were processed.
-func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResultFormat) error {
+func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResultFormat, cliCtx cli.CliContext, dEnv *env.DoltEnv) error {
 	_ = iohelp.WriteLine(cli.CliOut, welcomeMsg)
-	historyFile := filepath.Join(".sqlhistory") // history file written to working dir
 
 	db, branch, _ := getDBBranchFromSession(sqlCtx, qryist)
 	dirty := false
@@ -696,15 +704,36 @@ func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResu
 	}
 
 	initialPrompt, initialMultilinePrompt := formattedPrompts(db, branch, dirty)
+	repo, err := dEnv.FS.Abs("")
+	if err != nil {
+		return err
+	}
+
+	s := &sqlShell{
+		cliCtx:          cliCtx,
+		qryist:          qryist,
+		format:          format,
+		timing:          true,
+		stdout:          cli.CliOut,
+		history:         newShellHistory(repo, db),
+		savedTerminator: ";",
+		db:              db,
+		branch:          branch,
+		dirty:           dirty,
+	}
+	if cliCtx != nil && cliCtx.Config() != nil {
+		s.pager = cliCtx.Config().GetStringOrDefault(config.DoltPager, "")
+	}
 
 	rlConf := readline.Config{
 		Prompt:                 initialPrompt,
 		Stdout:                 cli.CliOut,
 		Stderr:                 cli.CliOut,
-		HistoryFile:            historyFile,
-		HistoryLimit:           500,
+		HistoryFile:            s.history.path(),
+		HistoryLimit:           shellHistoryLimit,
 		HistorySearchFold:      true,
 		DisableAutoSaveHistory: true,
+		Listener:               readline.FuncListener(s.onLineChange),
 	}
 
 	verticalOutputLineTerminators := []string{"\\g", "\\G"}
@@ -720,11 +749,13 @@ func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResu
 
 	shell := ishell.NewUninterpreted(&shellConf)
 	shell.SetMultiPrompt(initialMultilinePrompt)
-	// TODO: update completer on create / drop / alter statements
+	s.shell = shell
+
 	completer, err := newCompleter(sqlCtx, qryist)
 	if err != nil {
 		return err
 	}
+	s.completer = completer
 
 	shell.CustomCompleter(completer)
 
@@ -750,7 +781,7 @@ func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResu
 			return
 		}
 
-		closureFormat := format
+		closureFormat := s.format
 
 		// TODO: there's a bug in the readline library when editing multi-line history entries.
 		// Longer term we need to switch to a new readline library, like in this bug:
@@ -762,6 +793,33 @@ func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResu
 			// TODO: handle better, like by turning off history writing for the rest of the session
 			shell.Println(color.RedString(err.Error()))
 		}
+		if s.tee != nil {
+			_, _ = fmt.Fprintln(s.tee, query)
+		}
+
+		// Meta commands are run when their line is entered, discarding any statement entered on the lines before
+		lines := strings.Split(strings.TrimSpace(query), "\n")
+		if name, args, ok := parseMetaCommand(lines[len(lines)-1]); ok {
+			s.restoreLineTerminator()
+			if len(lines) > 1 && name != `\c` {
+				cli.Println("Discarded the statement being entered.")
+			}
+
+			cmd, ok := findShellMetaCommand(name)
+			if !ok {
+				shell.Println(color.RedString(`Unknown command '%s'. Use \? for help.`, name))
+				return
+			}
+			s.withSession(initialCtx, sqlCtx, false, func(sqlCtx *sql.Context) {
+				if err := cmd.exec(s, sqlCtx, args); err != nil {
+					shell.Println(formatQueryError("", err).Verbose())
+				}
+			})
+			if s.quit {
+				c.Stop()
+			}
+			return
+		}
 
 		query = strings.TrimSuffix(query, shell.LineTerminator())
 
@@ -772,54 +830,177 @@ func execShell(sqlCtx *sql.Context, qryist cli.Queryist, format engine.PrintResu
 			}
 			query = strings.TrimSuffix(query, terminator)
 		}
+		s.lastQuery = query
 
-		var nextPrompt string
-		var multiPrompt string
-		var sqlSch sql.Schema
-		var rowIter sql.RowIter
-
-		func() {
-			subCtx, stop := signal.NotifyContext(initialCtx, os.Interrupt, syscall.SIGTERM)
-			defer stop()
-
-			sqlCtx := sql.NewContext(subCtx, sql.WithSession(sqlCtx.Session))
-
-			if sqlSch, rowIter, err = processQuery(sqlCtx, query, qryist); err != nil {
-				verr := formatQueryError("", err)
-				shell.Println(verr.Verbose())
-			} else if rowIter != nil {
-				switch closureFormat {
-				case engine.FormatTabular, engine.FormatVertical:
-					err = engine.PrettyPrintResultsExtended(sqlCtx, closureFormat, sqlSch, rowIter)
-				default:
-					err = engine.PrettyPrintResults(sqlCtx, closureFormat, sqlSch, rowIter)
-				}
-
-				if err != nil {
-					shell.Println(color.RedString(err.Error()))
-				}
-			}
-
-			db, branch, ok := getDBBranchFromSession(sqlCtx, qryist)
-			if ok {
-				sqlCtx.SetCurrentDatabase(db)
-			}
-			if branch != "" {
-				dirty, _ = isDirty(sqlCtx, qryist)
+		s.withSession(initialCtx, sqlCtx, isSchemaChange(query), func(sqlCtx *sql.Context) {
+			if err := s.runQuery(sqlCtx, query, closureFormat); err != nil {
+				shell.Println(formatQueryError("", err).Verbose())
 			}
-			nextPrompt, multiPrompt = formattedPrompts(db, branch, dirty)
-		}()
-
-		shell.SetPrompt(nextPrompt)
-		shell.SetMultiPrompt(multiPrompt)
+		})
 	})
 
 	shell.Run()
+	s.setTee(nil)
 	_ = iohelp.WriteLine(cli.CliOut, "Bye")
 
 	return nil
 }
 
+// sqlShell is the state of an interactive SQL shell session.
+type sqlShell struct {
+	shell     *ishell.Shell
+	cliCtx    cli.CliContext
+	qryist    cli.Queryist
+	completer *sqlCompleter
+	history   *shellHistory
+
+	format    engine.PrintResultFormat
+	timing    bool
+	pager     string
+	tee       *os.File
+	stdout    io.Writer
+	lastQuery string
+	quit      bool
+
+	// savedTerminator is the line terminator in effect while a meta command is being entered, which doesn't need one
+	savedTerminator string
+
+	db     string
+	branch string
+	dirty  bool
+}
+
+// onLineChange is called by readline after each key press. When the line being entered is a meta command, the line
+// terminator is cleared so that the command runs when the line is entered.
+func (s *sqlShell) onLineChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	// Enter is reported after the line has been sent, with an empty buffer
+	if key == readline.CharEnter || key == readline.CharCtrlJ || s.shell == nil {
+		return nil, 0, false
+	}
+	if _, _, ok := parseMetaCommand(string(line)); ok {
+		if terminator := s.shell.LineTerminator(); terminator != "" {
+			s.savedTerminator = terminator
+			s.shell.SetLineTerminator("")
+		}
+	} else {
+		s.restoreLineTerminator()
+	}
+	return nil, 0, false
+}
+
+// restoreLineTerminator restores the line terminator cleared while a meta command was being entered.
+func (s *sqlShell) restoreLineTerminator() {
+	if s.shell.LineTerminator() == "" {
+		s.shell.SetLineTerminator(s.savedTerminator)
+	}
+}
+
+// withSession calls the function given with a context for the shell's session which is canceled on an interrupt, and
+// then updates the prompt, history and completions for any change to the session's database, branch or schema.
+func (s *sqlShell) withSession(initialCtx context.Context, sqlCtx *sql.Context, schemaChanged bool, f func(sqlCtx *sql.Context)) {
+	subCtx, stop := signal.NotifyContext(initialCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sqlCtx = sql.NewContext(subCtx, sql.WithSession(sqlCtx.Session))
+	f(sqlCtx)
+
+	db, branch, ok := getDBBranchFromSession(sqlCtx, s.qryist)
+	if ok {
+		sqlCtx.SetCurrentDatabase(db)
+	}
+	dirty := false
+	if branch != "" {
+		dirty, _ = isDirty(sqlCtx, s.qryist)
+	}
+
+	if db != s.db {
+		s.history.setDatabase(db)
+		s.shell.SetHistoryPath(s.history.path())
+	}
+	if db != s.db || branch != s.branch || schemaChanged {
+		s.completer.refresh(sqlCtx, s.qryist)
+	}
+	s.db, s.branch, s.dirty = db, branch, dirty
+
+	prompt, multiPrompt := formattedPrompts(db, branch, dirty)
+	s.shell.SetPrompt(prompt)
+	s.shell.SetMultiPrompt(multiPrompt)
+}
+
+// runQuery runs the query given and prints its results in the format given, through the pager if one is set.
+func (s *sqlShell) runQuery(sqlCtx *sql.Context, query string, format engine.PrintResultFormat) error {
+	sqlSch, rowIter, err := processQuery(sqlCtx, query, s.qryist)
+	if err != nil || rowIter == nil {
+		return err
+	}
+
+	if s.pager != "" {
+		out, closePager, err := startShellPager(s.pager, s.pagerStdout())
+		if err != nil {
+			_ = rowIter.Close(sqlCtx)
+			return fmt.Errorf("failed to start pager '%s': %w", s.pager, err)
+		}
+		if s.tee != nil {
+			out = io.MultiWriter(out, s.tee)
+		}
+		prevOut := cli.CliOut
+		cli.CliOut = out
+		defer func() {
+			cli.CliOut = prevOut
+			closePager()
+		}()
+	}
+
+	switch {
+	case s.timing && (format == engine.FormatTabular || format == engine.FormatVertical):
+		return engine.PrettyPrintResultsExtended(sqlCtx, format, sqlSch, rowIter)
+	default:
+		return engine.PrettyPrintResults(sqlCtx, format, sqlSch, rowIter)
+	}
+}
+
+// pagerStdout returns the terminal the pager should write to.
+func (s *sqlShell) pagerStdout() io.Writer {
+	out := io.Writer(os.Stdout)
+	if cli.ExecuteWithStdioRestored != nil {
+		cli.ExecuteWithStdioRestored(func() {
+			out = os.Stdout
+		})
+	}
+	return out
+}
+
+// setTee sets the file which all output is copied to, closing the previous one. A nil file stops copying output.
+func (s *sqlShell) setTee(f *os.File) {
+	if s.tee != nil {
+		_ = s.tee.Close()
+	}
+	s.tee = f
+
+	out := s.stdout
+	if f != nil {
+		out = io.MultiWriter(s.stdout, f)
+	}
+	cli.CliOut = out
+	if s.shell != nil {
+		s.shell.SetOut(out)
+	}
+}
+
+// isSchemaChange returns whether the query given may change the tables, columns or procedures of the session, which
+// are offered as completions.
+func isSchemaChange(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToLower(fields[0]) {
+	case "create", "drop", "alter", "rename", "truncate", "call":
+		return true
+	}
+	return false
+}
+
 // formattedPrompts returns the prompt and multiline prompt for the current session. If the db is empty, the prompt will
 // be "> ", otherwise it will be "db> ". If the branch is empty, the multiline prompt will be "-> ", left padded for
 // alignment with the prompt.
@@ -912,39 +1093,44 @@ func isDirty(sqlCtx *sql.Context, qryist cli.Queryist) (bool, error) {
 	return getStrBoolColAsBool(row[0])
 }
 
-// Returns a new auto completer with table names, column names, and SQL keywords.
-// TODO: update the completer on DDL, branch change, etc.
+// Returns a new auto completer with the table, column, branch, database and procedure names of the session, and SQL
+// keywords.
 func newCompleter(
 	ctx *sql.Context,
 	qryist cli.Queryist,
 ) (completer *sqlCompleter, rerr error) {
+	completer = &sqlCompleter{}
+	if err := completer.refresh(ctx, qryist); err != nil {
+		return nil, err
+	}
+	return completer, nil
+}
+
+type sqlCompleter struct {
+	mu          sync.RWMutex
+	allWords    []string
+	columnNames []string
+	branches    []string
+	databases   []string
+	procedures  []string
+}
+
+// refresh reloads the names offered as completions from the session given. Only a failure to load the columns of the
+// session is returned, since branches and procedures aren't available in every database.
+func (c *sqlCompleter) refresh(ctx *sql.Context, qryist cli.Queryist) error {
 	subCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	sqlCtx := sql.NewContext(subCtx, sql.WithSession(ctx.Session))
 
-	_, iter, err := qryist.Query(sqlCtx, "select table_schema, table_name, column_name from information_schema.columns;")
+	rows, err := GetRowsForSql(qryist, sqlCtx, "select table_schema, table_name, column_name from information_schema.columns;")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	defer func(iter sql.RowIter, context *sql.Context) {
-		err := iter.Close(context)
-		if err != nil && rerr == nil {
-			rerr = err
-		}
-	}(iter, sqlCtx)
-
 	identifiers := make(map[string]struct{})
 	var columnNames []string
-	for {
-		r, err := iter.Next(sqlCtx)
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
-		}
-
+	for _, r := range rows {
 		identifiers[r[0].(string)] = struct{}{}
 		identifiers[r[1].(string)] = struct{}{}
 		identifiers[r[2].(string)] = struct{}{}
@@ -958,63 +1144,119 @@ func newCompleter(
 
 	completionWords = append(completionWords, dsqle.CommonKeywords...)
 
-	return &sqlCompleter{
-		allWords:    completionWords,
-		columnNames: columnNames,
-	}, nil
+	branches := stringColumn(GetRowsForSql(qryist, sqlCtx, "select name from dolt_branches"))
+	databases := stringColumn(GetRowsForSql(qryist, sqlCtx, "show databases"))
+	procedures := stringColumn(GetRowsForSql(qryist, sqlCtx, "select routine_name from information_schema.routines where routine_schema = database()"))
+	for _, proc := range dprocedures.DoltProcedures {
+		procedures = append(procedures, proc.Name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.allWords = completionWords
+	c.columnNames = columnNames
+	c.branches = branches
+	c.databases = databases
+	c.procedures = procedures
+	return nil
 }
 
-type sqlCompleter struct {
-	allWords    []string
-	columnNames []string
+// stringColumn returns the values of the first column of the rows given, or nil if there was an error getting them.
+func stringColumn(rows []sql.Row, err error) []string {
+	if err != nil {
+		return nil
+	}
+	var vals []string
+	for _, r := range rows {
+		if len(r) > 0 {
+			if s, ok := r[0].(string); ok {
+				vals = append(vals, s)
+			}
+		}
+	}
+	return vals
 }
 
+// completionSeparators are the characters which separate the words of a line for completion.
+const completionSeparators = " \t\n(),'\"`=;"
+
 // Do function for autocompletion, defined by the Readline library. Mostly stolen from ishell.
 func (c *sqlCompleter) Do(line []rune, pos int) (newLine [][]rune, length int) {
-	var words []string
-	if w, err := shlex.Split(string(line)); err == nil {
-		words = w
-	} else {
-		// fall back
-		words = strings.Fields(string(line))
-	}
+	head := string(line[:pos])
+	words := strings.FieldsFunc(head, func(r rune) bool {
+		return strings.ContainsRune(completionSeparators, r)
+	})
 
-	var cWords []string
-	prefix := ""
 	lastWord := ""
-	if len(words) > 0 && pos > 0 && line[pos-1] != ' ' {
-		lastWord = words[len(words)-1]
-		prefix = strings.ToLower(lastWord)
-	} else if len(words) > 0 {
+	prevWord := ""
+	if len(words) > 0 && pos > 0 && !strings.ContainsRune(completionSeparators, line[pos-1]) {
 		lastWord = words[len(words)-1]
+		words = words[:len(words)-1]
 	}
+	if len(words) > 0 {
+		prevWord = words[len(words)-1]
+	}
+
+	cWords := c.getWords(prevWord, lastWord, len(words) == 0)
 
-	cWords = c.getWords(lastWord)
+	// Meta commands are case-sensitive, everything else is completed in lower case
+	caseSensitive := strings.HasPrefix(lastWord, `\`)
+	prefix := lastWord
+	if !caseSensitive {
+		prefix = strings.ToLower(lastWord)
+	}
 
 	var suggestions [][]rune
+	seen := make(map[string]struct{})
 	for _, w := range cWords {
-		lowered := strings.ToLower(w)
-		if strings.HasPrefix(lowered, prefix) {
-			suggestions = append(suggestions, []rune(strings.TrimPrefix(lowered, prefix)))
+		if !caseSensitive {
+			w = strings.ToLower(w)
+		}
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		if strings.HasPrefix(w, prefix) {
+			seen[w] = struct{}{}
+			suggestions = append(suggestions, []rune(strings.TrimPrefix(w, prefix)))
 		}
 	}
 	if len(suggestions) == 1 && prefix != "" && string(suggestions[0]) == "" {
 		suggestions = [][]rune{[]rune(" ")}
 	}
 
-	return suggestions, len(prefix)
+	return suggestions, len([]rune(prefix))
 }
 
 // Simple suggestion function. Returns column name suggestions if the last word in the input has exactly one '.' in it,
-// otherwise returns all tables, columns, and reserved words.
-func (c *sqlCompleter) getWords(lastWord string) (s []string) {
+// names of the kind the previous word expects after it, such as procedures after CALL or branches after \checkout, and
+// otherwise all tables, columns, branches, procedures and reserved words.
+func (c *sqlCompleter) getWords(prevWord, lastWord string, firstWord bool) (s []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if firstWord && strings.HasPrefix(lastWord, `\`) {
+		return metaCommandNames()
+	}
+
 	lastDot := strings.LastIndex(lastWord, ".")
 	if lastDot > 0 && strings.Count(lastWord, ".") == 1 {
 		alias := lastWord[:lastDot]
 		return prepend(alias+".", c.columnNames)
 	}
 
-	return c.allWords
+	switch strings.ToLower(prevWord) {
+	case "call":
+		return c.procedures
+	case "use", `\u`:
+		return c.databases
+	case `\checkout`, "dolt_checkout", "dolt_merge", "dolt_branch":
+		return c.branches
+	}
+
+	words := make([]string, 0, len(c.allWords)+len(c.branches)+len(c.procedures))
+	words = append(words, c.allWords...)
+	words = append(words, c.branches...)
+	return append(words, c.procedures...)
 }
 
 func prepend(s string, ss []string) []string {
@@ -1025,10 +1267,14 @@ func prepend(s string, ss []string) []string {
 	return newSs
 }
 
+// cliParser parses the statements run by the sql command before they're sent to the engine, accepting the same
+// locking read and FOR SYSTEM_TIME clauses and sequence statements as the engine's parser.
+var cliParser = dsqle.NewDoltParser(sql.NewMysqlParser())
+
 // processQuery processes a single query. The Root of the sqlEngine will be updated if necessary.
 // Returns the schema and the row iterator for the results, which may be nil, and an error if one occurs.
 func processQuery(ctx *sql.Context, query string, qryist cli.Queryist) (sql.Schema, sql.RowIter, error) {
-	sqlStatement, err := sqlparser.Parse(query)
+	sqlStatement, err := cliParser.ParseSimple(query)
 	if err == sqlparser.ErrEmpty {
 		// silently skip empty statements
 		return nil, nil, nil
@@ -0,0 +1,552 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/flynn-archive/go-shlex"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/config"
+	"github.com/dolthub/dolt/go/libraries/utils/editor"
+)
+
+// shellMetaCommand is a command of the SQL shell which starts with a backslash, in the style of the meta commands of
+// the mysql client. Unlike statements, meta commands don't need a terminator, and are run when their line is entered.
+type shellMetaCommand struct {
+	name string
+	args string
+	desc string
+	exec func(s *sqlShell, sqlCtx *sql.Context, args string) error
+}
+
+// doltShellCommands are the dolt commands which can be run as meta commands of the SQL shell, such as
+// \commit -m "message". They run in the session of the shell.
+var doltShellCommands = []cli.Command{
+	AddCmd{},
+	CommitCmd{},
+	DiffCmd{},
+	LogCmd{},
+	ResetCmd{},
+}
+
+var shellMetaCommands []shellMetaCommand
+
+func init() {
+	shellMetaCommands = []shellMetaCommand{
+		{name: `\?`, desc: "Prints this help.", exec: metaHelp},
+		{name: `\c`, desc: "Clears the statement being entered.", exec: func(*sqlShell, *sql.Context, string) error { return nil }},
+		{name: `\e`, desc: "Edits the last statement with the editor of core.editor or $EDITOR, and runs the result.", exec: metaEdit},
+		{name: `\history`, args: "[pattern]", desc: "Lists the history of the current database, or the entries containing pattern. Ctrl-R searches it interactively.", exec: metaHistory},
+		{name: `\n`, desc: "Disables the pager.", exec: metaNoPager},
+		{name: `\P`, args: "[command]", desc: "Pages results with command, or with core.pager, $PAGER or less.", exec: metaPager},
+		{name: `\q`, desc: "Exits the shell.", exec: metaQuit},
+		{name: `\s`, desc: "Prints the status of the session.", exec: metaStatus},
+		{name: `\t`, desc: "Stops writing output to a file.", exec: metaNoTee},
+		{name: `\T`, args: "<file>", desc: "Appends all output to file, in addition to printing it.", exec: metaTee},
+		{name: `\timing`, desc: "Toggles printing the row count and time of each result.", exec: metaTiming},
+		{name: `\u`, args: "<database>", desc: "Uses another database.", exec: metaUse},
+		{name: `\checkout`, args: "<branch>", desc: "Checks out a branch in the session.", exec: metaCheckout},
+	}
+	for _, cmd := range doltShellCommands {
+		cmd := cmd
+		shellMetaCommands = append(shellMetaCommands, shellMetaCommand{
+			name: `\` + cmd.Name(),
+			args: "[args...]",
+			desc: cmd.Description(),
+			exec: func(s *sqlShell, sqlCtx *sql.Context, args string) error {
+				return s.runDoltCommand(sqlCtx, cmd, args)
+			},
+		})
+	}
+}
+
+// findShellMetaCommand returns the meta command with the name given.
+func findShellMetaCommand(name string) (shellMetaCommand, bool) {
+	for _, cmd := range shellMetaCommands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return shellMetaCommand{}, false
+}
+
+// parseMetaCommand returns the name and arguments of the meta command on the line given, if the line is one. The
+// vertical output terminators \g and \G end statements, and are not meta commands.
+func parseMetaCommand(line string) (name, args string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, `\`) || line == `\g` || line == `\G` {
+		return "", "", false
+	}
+	line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		return line[:i], strings.TrimSpace(line[i+1:]), true
+	}
+	return line, "", true
+}
+
+func metaHelp(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	cli.Println("Meta commands run when their line is entered, and don't need a terminator.")
+	cli.Println()
+	for _, cmd := range shellMetaCommands {
+		usage := cmd.name
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		cli.Printf("  %-24s %s\n", usage, cmd.desc)
+	}
+	cli.Println()
+	cli.Println(`Statements ending with \G instead of ; print their results vertically.`)
+	return nil
+}
+
+func metaQuit(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	s.quit = true
+	return nil
+}
+
+func metaUse(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	db := strings.Trim(args, "`")
+	if db == "" {
+		return errors.New(`usage: \u <database>`)
+	}
+	return s.runQuery(sqlCtx, fmt.Sprintf("USE `%s`", strings.ReplaceAll(db, "`", "``")), s.format)
+}
+
+func metaCheckout(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	argv, err := shlex.Split(args)
+	if err != nil {
+		return err
+	}
+	if len(argv) == 0 {
+		return errors.New(`usage: \checkout [-b] <branch>`)
+	}
+
+	var buffer bytes.Buffer
+	queryValues := make([]interface{}, 0, len(argv))
+	buffer.WriteString("CALL DOLT_CHECKOUT(")
+	for i, arg := range argv {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString("?")
+		queryValues = append(queryValues, arg)
+	}
+	buffer.WriteString(")")
+	query, err := dbr.InterpolateForDialect(buffer.String(), queryValues, dialect.MySQL)
+	if err != nil {
+		return err
+	}
+
+	rows, err := GetRowsForSql(s.qryist, sqlCtx, query)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 1 && len(rows[0]) > 1 {
+		if message, ok := rows[0][1].(string); ok && message != "" {
+			cli.Println(message)
+		}
+	}
+	return nil
+}
+
+func metaTiming(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	s.timing = !s.timing
+	if s.timing {
+		cli.Println("Timing is on.")
+	} else {
+		cli.Println("Timing is off.")
+	}
+	return nil
+}
+
+func metaPager(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	pager := args
+	if pager == "" {
+		pager = defaultShellPager(s.cliCtx)
+	}
+	if _, err := shlex.Split(pager); err != nil {
+		return err
+	}
+	s.pager = pager
+	cli.Printf("PAGER set to '%s'\n", pager)
+	return nil
+}
+
+func metaNoPager(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	s.pager = ""
+	cli.Println("PAGER set to stdout")
+	return nil
+}
+
+// defaultShellPager returns the pager of the core.pager config, or of the PAGER environment variable, or less.
+func defaultShellPager(cliCtx cli.CliContext) string {
+	backup := "less -FRSX"
+	if pager, ok := os.LookupEnv(dconfig.EnvPager); ok && pager != "" {
+		backup = pager
+	}
+	if cliCtx == nil || cliCtx.Config() == nil {
+		return backup
+	}
+	return cliCtx.Config().GetStringOrDefault(config.DoltPager, backup)
+}
+
+func metaTee(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	if args == "" {
+		return errors.New(`usage: \T <file>`)
+	}
+	f, err := os.OpenFile(args, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.setTee(f)
+	cli.Printf("Logging to file '%s'\n", args)
+	return nil
+}
+
+func metaNoTee(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	if s.tee != nil {
+		s.setTee(nil)
+		cli.Println("Outfile disabled.")
+	}
+	return nil
+}
+
+func metaHistory(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	entries, err := s.history.search(args)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		cli.Printf("%5d  %s\n", i+1, entry)
+	}
+	return nil
+}
+
+func metaEdit(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	if cli.ExecuteWithStdioRestored == nil {
+		return errors.New("no terminal to run an editor in")
+	}
+
+	initial := ""
+	if s.lastQuery != "" {
+		initial = s.lastQuery + ";\n"
+	}
+
+	editorStr := "vim"
+	if ed, ok := os.LookupEnv(dconfig.EnvEditor); ok {
+		editorStr = ed
+	}
+	if s.cliCtx != nil && s.cliCtx.Config() != nil {
+		editorStr = s.cliCtx.Config().GetStringOrDefault(config.DoltEditor, editorStr)
+	}
+
+	var edited string
+	var err error
+	cli.ExecuteWithStdioRestored(func() {
+		edited, err = editor.OpenTempEditor(editorStr, initial)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	edited = strings.TrimSpace(edited)
+	if edited == "" {
+		return nil
+	}
+	cli.Println(edited)
+	_ = s.shell.AddHistory(strings.ReplaceAll(edited, "\n", " "))
+
+	scanner := NewSqlStatementScanner(strings.NewReader(edited))
+	for scanner.Scan() {
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" {
+			continue
+		}
+		s.lastQuery = query
+		if err := s.runQuery(sqlCtx, query, s.format); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func metaStatus(s *sqlShell, sqlCtx *sql.Context, args string) error {
+	rows, err := GetRowsForSql(s.qryist, sqlCtx, "select current_user(), version(), @@autocommit")
+	if err != nil {
+		return err
+	}
+	user, version, autocommit := "", "", ""
+	if len(rows) == 1 && len(rows[0]) == 3 {
+		user, version, autocommit = fmt.Sprint(rows[0][0]), fmt.Sprint(rows[0][1]), fmt.Sprint(rows[0][2])
+	}
+
+	connection := "remote server"
+	if _, ok := s.qryist.(*engine.SqlEngine); ok {
+		connection = "local"
+	}
+	workingSet := "clean"
+	if s.dirty {
+		workingSet = "dirty"
+	}
+	pager := s.pager
+	if pager == "" {
+		pager = "stdout"
+	}
+	tee := "off"
+	if s.tee != nil {
+		tee = s.tee.Name()
+	}
+	timing := "off"
+	if s.timing {
+		timing = "on"
+	}
+
+	cli.Println("--------------")
+	for _, line := range [][2]string{
+		{"Current database:", s.db},
+		{"Current branch:", s.branch},
+		{"Working set:", workingSet},
+		{"Current user:", user},
+		{"Server version:", version},
+		{"Connection:", connection},
+		{"Autocommit:", autocommit},
+		{"Pager:", pager},
+		{"Outfile:", tee},
+		{"Timing:", timing},
+		{"History file:", s.history.path()},
+	} {
+		cli.Printf("%-20s%s\n", line[0], line[1])
+	}
+	cli.Println("--------------")
+	return nil
+}
+
+// runDoltCommand runs the dolt command given with the arguments given, in the session of the shell.
+func (s *sqlShell) runDoltCommand(sqlCtx *sql.Context, cmd cli.Command, args string) error {
+	argv, err := shlex.Split(args)
+	if err != nil {
+		return err
+	}
+
+	// Commands exit the process when their arguments are invalid, so they are checked first.
+	commandStr := "dolt " + cmd.Name()
+	if _, err := cmd.ArgParser().Parse(argv); err != nil {
+		if err == argparser.ErrHelp {
+			docs := cmd.Docs()
+			docs.CommandStr = commandStr
+			help, _ := cli.HelpAndUsagePrinters(docs)
+			help()
+			return nil
+		}
+		return err
+	}
+
+	cliCtx := shellCliContext{CliContext: s.cliCtx, qryist: s.qryist, sqlCtx: sqlCtx}
+	// Errors are printed by the command
+	_ = cmd.Exec(sqlCtx, commandStr, argv, nil, cliCtx)
+	return nil
+}
+
+// shellCliContext is a cli.CliContext which runs the queries of a command in the session of the SQL shell.
+type shellCliContext struct {
+	cli.CliContext
+	qryist cli.Queryist
+	sqlCtx *sql.Context
+}
+
+var _ cli.CliContext = shellCliContext{}
+
+func (c shellCliContext) QueryEngine(ctx context.Context) (cli.Queryist, *sql.Context, func(), error) {
+	return c.qryist, c.sqlCtx, nil, nil
+}
+
+// startShellPager starts the pager command given, and returns a writer to its input. The returned function closes the
+// input and waits for the pager to exit.
+func startShellPager(command string, stdout io.Writer) (io.Writer, func(), error) {
+	argv, err := shlex.Split(command)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(argv) == 0 {
+		return nil, nil, errors.New("empty pager command")
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	return pagerWriter{in}, func() {
+		_ = in.Close()
+		_ = cmd.Wait()
+	}, nil
+}
+
+// pagerWriter discards writes after the pager exits, so that quitting the pager does not fail the statement whose
+// output it was showing.
+type pagerWriter struct {
+	io.Writer
+}
+
+func (w pagerWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed) {
+		return len(p), nil
+	}
+	return n, err
+}
+
+const (
+	shellHistoryDir   = "sql_history"
+	shellHistoryLimit = 500
+	// legacyShellHistoryFile is the history file older versions of the shell wrote to the working directory.
+	legacyShellHistoryFile = ".sqlhistory"
+)
+
+// shellHistory locates the history of the SQL shell, which is kept in a file per repository and database in the dolt
+// directory of the user's home directory.
+type shellHistory struct {
+	dir string
+	db  string
+	// legacy is the path of the history file of older versions of the shell, which was shared by every database.
+	legacy string
+}
+
+// newShellHistory returns the history of the database |db| of the repository in the directory |repo|.
+func newShellHistory(repo, db string) *shellHistory {
+	h := &shellHistory{legacy: legacyShellHistoryFile}
+	if home, err := env.GetCurrentUserHomeDir(); err == nil {
+		dir := filepath.Join(home, dbfactory.DoltDir, shellHistoryDir, shellHistoryRepoDir(repo))
+		if err := os.MkdirAll(dir, 0700); err == nil {
+			h.dir = dir
+		}
+	}
+	h.setDatabase(db)
+	return h
+}
+
+// shellHistoryRepoDir returns the name of the directory of the histories of the repository in the directory |repo|.
+// The name of the repository's directory keeps it readable, and a hash of its path tells apart repositories with the
+// same name.
+func shellHistoryRepoDir(repo string) string {
+	sum := sha256.Sum256([]byte(repo))
+	return url.PathEscape(filepath.Base(repo)) + "-" + hex.EncodeToString(sum[:8])
+}
+
+// setDatabase makes |db| the current database of the history.
+func (h *shellHistory) setDatabase(db string) {
+	h.db = db
+	h.importLegacy()
+}
+
+// importLegacy copies the legacy history file to the history of the current database, if the database has no history
+// yet. Every database of the repository shared the legacy history, so each gets its own copy. The legacy file is left
+// in place.
+func (h *shellHistory) importLegacy() {
+	if h.dir == "" || h.legacy == "" {
+		return
+	}
+	if _, err := os.Stat(h.path()); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	contents, err := os.ReadFile(h.legacy)
+	if err != nil || len(contents) == 0 {
+		return
+	}
+	_ = os.WriteFile(h.path(), contents, 0600)
+}
+
+// path returns the path of the history file of the current database, or the empty string if history isn't persisted.
+func (h *shellHistory) path() string {
+	if h.dir == "" {
+		return ""
+	}
+	return filepath.Join(h.dir, url.PathEscape(h.db)+".history")
+}
+
+// search returns the entries of the history of the current database which contain the pattern given, ignoring case.
+func (h *shellHistory) search(pattern string) ([]string, error) {
+	if h.dir == "" {
+		return nil, nil
+	}
+	f, err := os.Open(h.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if scanner.Text() != "" {
+			entries = append(entries, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil || pattern == "" {
+		return entries, err
+	}
+
+	pattern = strings.ToLower(pattern)
+	var matches []string
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry), pattern) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches, nil
+}
+
+// metaCommandNames returns the sorted names of the meta commands, for completion.
+func metaCommandNames() []string {
+	names := make([]string, len(shellMetaCommands))
+	for i, cmd := range shellMetaCommands {
+		names[i] = cmd.name
+	}
+	sort.Strings(names)
+	return names
+}
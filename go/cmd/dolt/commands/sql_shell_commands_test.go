@@ -0,0 +1,168 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetaCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		name string
+		args string
+		ok   bool
+	}{
+		{line: `\s`, name: `\s`, ok: true},
+		{line: `  \u mydb;`, name: `\u`, args: "mydb", ok: true},
+		{line: `\commit -m "a message"`, name: `\commit`, args: `-m "a message"`, ok: true},
+		{line: `\T	/tmp/out.txt`, name: `\T`, args: "/tmp/out.txt", ok: true},
+		{line: `\g`},
+		{line: `\G`},
+		{line: `select 1`},
+		{line: `select '\s'`},
+		{line: ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			name, args, ok := parseMetaCommand(test.line)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.name, name)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestShellMetaCommandsAreUnique(t *testing.T) {
+	seen := make(map[string]struct{})
+	for _, cmd := range shellMetaCommands {
+		_, ok := seen[cmd.name]
+		assert.False(t, ok, "duplicate meta command %s", cmd.name)
+		seen[cmd.name] = struct{}{}
+		assert.NotNil(t, cmd.exec)
+	}
+	for _, name := range []string{`\u`, `\s`, `\e`, `\T`, `\timing`, `\checkout`, `\diff`, `\commit`} {
+		_, ok := findShellMetaCommand(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	dir := t.TempDir()
+	h := &shellHistory{dir: dir, db: "my/db"}
+	assert.Equal(t, filepath.Join(dir, "my%2Fdb.history"), h.path())
+
+	entries, err := h.search("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, os.WriteFile(h.path(), []byte("select * from t1;\nSHOW TABLES;\n\nselect count(*) from t2;\n"), 0600))
+
+	entries, err = h.search("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select * from t1;", "SHOW TABLES;", "select count(*) from t2;"}, entries)
+
+	entries, err = h.search("SELECT")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select * from t1;", "select count(*) from t2;"}, entries)
+
+	h.db = "other"
+	entries, err = h.search("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestShellHistoryImportsLegacyHistory(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(t.TempDir(), legacyShellHistoryFile)
+	require.NoError(t, os.WriteFile(legacy, []byte("select 1;\nselect 2;\n"), 0600))
+
+	h := &shellHistory{dir: dir, legacy: legacy}
+	h.setDatabase("mydb")
+	entries, err := h.search("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 1;", "select 2;"}, entries)
+
+	// the history of a database is only imported into once
+	require.NoError(t, os.WriteFile(legacy, []byte("select 3;\n"), 0600))
+	h.setDatabase("mydb")
+	entries, err = h.search("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 1;", "select 2;"}, entries)
+	_, err = os.Stat(legacy)
+	assert.NoError(t, err)
+
+	// every database of the repository shared the legacy history
+	h.setDatabase("other")
+	entries, err = h.search("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 3;"}, entries)
+}
+
+func TestShellHistoryRepoDir(t *testing.T) {
+	a := shellHistoryRepoDir(filepath.Join("home", "a", "repo"))
+	b := shellHistoryRepoDir(filepath.Join("home", "b", "repo"))
+	assert.NotEqual(t, a, b)
+	assert.True(t, strings.HasPrefix(a, "repo-"), a)
+	assert.Equal(t, a, shellHistoryRepoDir(filepath.Join("home", "a", "repo")))
+}
+
+func TestSqlCompleter(t *testing.T) {
+	c := &sqlCompleter{
+		allWords:    []string{"SELECT", "SET", "mytable", "id", "name"},
+		columnNames: []string{"id", "name"},
+		branches:    []string{"main", "feature"},
+		databases:   []string{"mydb", "otherdb"},
+		procedures:  []string{"myproc", "dolt_checkout"},
+	}
+
+	complete := func(line string) []string {
+		suggestions, _ := c.Do([]rune(line), len([]rune(line)))
+		var words []string
+		for _, s := range suggestions {
+			words = append(words, string(s))
+		}
+		sort.Strings(words)
+		return words
+	}
+
+	assert.Equal(t, []string{"lect", "t"}, complete("se"))
+	assert.Equal(t, []string{"proc", "table"}, complete("select * from my"))
+	assert.Equal(t, []string{"id", "name"}, complete("select t."))
+	assert.Equal(t, []string{"proc"}, complete("call my"))
+	assert.Equal(t, []string{"db"}, complete("use my"))
+	assert.Equal(t, []string{"mydb", "otherdb"}, complete(`\u `))
+	assert.Equal(t, []string{"eature"}, complete(`\checkout f`))
+	assert.Equal(t, []string{"eature"}, complete("call dolt_checkout('f"))
+	assert.Equal(t, []string{"ming"}, complete(`\ti`))
+	assert.Equal(t, []string{"eature"}, complete("select * from f"))
+	assert.Empty(t, complete(`\x`))
+}
+
+func TestIsSchemaChange(t *testing.T) {
+	assert.True(t, isSchemaChange("CREATE TABLE t (id int)"))
+	assert.True(t, isSchemaChange("  drop table t"))
+	assert.True(t, isSchemaChange("call dolt_checkout('main')"))
+	assert.False(t, isSchemaChange("select * from t"))
+	assert.False(t, isSchemaChange(""))
+}
@@ -54,6 +54,8 @@ type commandLineServerConfig struct {
 	socket                  string
 	remotesapiPort          *int
 	remotesapiReadOnly      *bool
+	postgresPort            *int
+	httpAPIPort             *int
 	goldenMysqlConn         string
 	eventSchedulerStatus    string
 	valuesSet               map[string]struct{}
@@ -124,6 +126,14 @@ func NewCommandLineConfig(creds *cli.UserPassword, apr *argparser.ArgParseResult
 		config.WithRemotesapiReadOnly(&val)
 	}
 
+	if port, ok := apr.GetInt(postgresPortFlag); ok {
+		config.WithPostgresListenerPort(&port)
+	}
+
+	if port, ok := apr.GetInt(httpPortFlag); ok {
+		config.WithHTTPAPIPort(&port)
+	}
+
 	if persistenceBehavior, ok := apr.GetValue(persistenceBehaviorFlag); ok {
 		config.withPersistenceBehavior(persistenceBehavior)
 	}
@@ -303,10 +313,47 @@ func (cfg *commandLineServerConfig) RemotesapiReadOnly() *bool {
 	return cfg.remotesapiReadOnly
 }
 
+// RemotesapiProtectedBranches returns nil, as protected branches can only be configured in a config file.
+func (cfg *commandLineServerConfig) RemotesapiProtectedBranches() []servercfg.ProtectedBranchConfig {
+	return nil
+}
+
 func (cfg *commandLineServerConfig) ClusterConfig() servercfg.ClusterConfig {
 	return nil
 }
 
+// PostgresListenerHost returns the host of the Postgres wire protocol listener, which is always the server host when
+// configured from the command line.
+func (cfg *commandLineServerConfig) PostgresListenerHost() string {
+	return cfg.host
+}
+
+func (cfg *commandLineServerConfig) PostgresListenerPort() *int {
+	return cfg.postgresPort
+}
+
+// HTTPAPIHost returns the host of the HTTP query API, which is always the server host when configured from the command
+// line.
+func (cfg *commandLineServerConfig) HTTPAPIHost() string {
+	return cfg.host
+}
+
+func (cfg *commandLineServerConfig) HTTPAPIPort() *int {
+	return cfg.httpAPIPort
+}
+
+func (cfg *commandLineServerConfig) HTTPAPIReadOnly() bool {
+	return false
+}
+
+func (cfg *commandLineServerConfig) HTTPAPIQueryTimeoutMillis() uint64 {
+	return 0
+}
+
+func (cfg *commandLineServerConfig) HTTPAPIMaxRows() uint64 {
+	return servercfg.DefaultHTTPAPIMaxRows
+}
+
 // PrivilegeFilePath returns the path to the file which contains all needed privilege information in the form of a
 // JSON string.
 func (cfg *commandLineServerConfig) PrivilegeFilePath() string {
@@ -460,6 +507,18 @@ func (cfg *commandLineServerConfig) WithRemotesapiReadOnly(readonly *bool) *comm
 	return cfg
 }
 
+// WithPostgresListenerPort sets the port of the Postgres wire protocol listener.
+func (cfg *commandLineServerConfig) WithPostgresListenerPort(port *int) *commandLineServerConfig {
+	cfg.postgresPort = port
+	return cfg
+}
+
+// WithHTTPAPIPort sets the port of the HTTP query API.
+func (cfg *commandLineServerConfig) WithHTTPAPIPort(port *int) *commandLineServerConfig {
+	cfg.httpAPIPort = port
+	return cfg
+}
+
 func (cfg *commandLineServerConfig) GoldenMysqlConnectionString() string {
 	return cfg.goldenMysqlConn
 }
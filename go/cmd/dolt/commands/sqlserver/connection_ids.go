@@ -0,0 +1,97 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// connectionIDs allocates the connection IDs of the sessions of the MySQL, Postgres and HTTP API listeners, so that
+// sessions have distinct IDs across all listeners.
+type connectionIDs struct {
+	last atomic.Uint32
+}
+
+// next returns an unused connection ID. IDs are never 0, which the engine uses for internal sessions.
+func (ids *connectionIDs) next() uint32 {
+	for {
+		if id := ids.last.Add(1); id != 0 {
+			return id
+		}
+	}
+}
+
+// connectionIDHandler replaces the connection IDs the MySQL listener assigns to new connections with IDs from
+// connectionIDs, and closes the session of a connection when the connection closes, as the Postgres and HTTP API
+// listeners do.
+type connectionIDHandler struct {
+	mysql.Handler
+	ids *connectionIDs
+	// newContext returns a context for the session of a connection.
+	newContext func(c *mysql.Conn) (*sql.Context, error)
+}
+
+var _ mysql.BinlogReplicaHandler = connectionIDHandler{}
+
+func newConnectionIDHandler(h mysql.Handler, ids *connectionIDs, newContext func(c *mysql.Conn) (*sql.Context, error)) mysql.Handler {
+	return connectionIDHandler{Handler: h, ids: ids, newContext: newContext}
+}
+
+// NewConnection implements mysql.Handler. It is called before the connection ID is sent to the client in the
+// handshake, and before anything else sees it.
+func (h connectionIDHandler) NewConnection(c *mysql.Conn) {
+	c.ConnectionID = h.ids.next()
+	h.Handler.NewConnection(c)
+}
+
+// ConnectionClosed implements mysql.Handler.
+func (h connectionIDHandler) ConnectionClosed(c *mysql.Conn) {
+	defer h.Handler.ConnectionClosed(c)
+	ctx, err := h.newContext(c)
+	if err != nil {
+		logrus.Errorf("unable to close session: %s", err)
+		return
+	}
+	if dSess, ok := ctx.Session.(*dsess.DoltSession); ok {
+		if err := dSess.Close(ctx); err != nil {
+			logrus.Errorf("unable to close session: %s", err)
+		}
+	}
+}
+
+var errNoBinlogReplicaHandler = errors.New("binlog replication is not supported by this server")
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler.
+func (h connectionIDHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	if rh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return rh.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+	}
+	return errNoBinlogReplicaHandler
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h connectionIDHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	if rh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return rh.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+	}
+	return errNoBinlogReplicaHandler
+}
@@ -0,0 +1,435 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/mysql"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// maxRequestSize is the largest request body that is accepted.
+const maxRequestSize = 16 * 1024 * 1024
+
+const ndjsonContentType = "application/x-ndjson"
+
+var errReadOnly = errors.New("the HTTP API is read only")
+
+var errInsecureTransport = errors.New("requests using insecure transport are prohibited while --require_secure_transport=ON")
+
+// queryRequest is the body of a POST /query request.
+type queryRequest struct {
+	// Query is the single SQL statement to run.
+	Query string `json:"query"`
+	// Params are the values of the parameters of the query. An array binds the ? placeholders of the query in order, an
+	// object binds :name placeholders by name.
+	Params json.RawMessage `json:"params"`
+	// Database is the database the query runs against. It may include a revision, as in `db/branch`.
+	Database string `json:"database"`
+	// TimeoutMillis shortens the query timeout of the server for this request.
+	TimeoutMillis uint64 `json:"timeout_ms"`
+}
+
+// column describes a column of a result set.
+type column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// rowsResponse is the response to a query which returns rows.
+type rowsResponse struct {
+	Columns []column            `json:"columns"`
+	Rows    [][]json.RawMessage `json:"rows"`
+	// Truncated is true if the result had more rows than the server returns in a JSON document.
+	Truncated bool `json:"truncated"`
+}
+
+// okResponse is the response to a query which does not return rows.
+type okResponse struct {
+	RowsAffected uint64 `json:"rows_affected"`
+	LastInsertID uint64 `json:"last_insert_id"`
+}
+
+// errorResponse is the response to a request which failed.
+type errorResponse struct {
+	Error    string `json:"error"`
+	Code     int    `json:"code,omitempty"`
+	SQLState string `json:"sql_state,omitempty"`
+}
+
+// handleQuery serves POST /query requests.
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	if s.cfg.RequireSecureTransport && r.TLS == nil {
+		writeError(w, http.StatusForbidden, errInsecureTransport)
+		return
+	}
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="dolt"`)
+		writeError(w, http.StatusUnauthorized, errors.New("authentication required"))
+		return
+	}
+	clientAddr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = s.cfg.Authenticate(user, password, clientAddr); err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="dolt"`)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var req queryRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request body: query is required"))
+		return
+	}
+	bindings, err := bindVariables(req.Params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	database := req.Database
+	if ref := r.URL.Query().Get("ref"); ref != "" {
+		if database == "" {
+			writeError(w, http.StatusBadRequest, errors.New("a database is required to query a ref"))
+			return
+		}
+		database = database + "/" + ref
+	}
+
+	ctx := r.Context()
+	if timeout := s.timeout(req.TimeoutMillis); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	id := s.cfg.NextConnectionID()
+	sess, err := s.cfg.NewSession(ctx, id, user, clientAddr.IP.String())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.closeSession(id, sess)
+
+	if database != "" {
+		use := "USE " + quoteIdentifier(database)
+		if err = s.runAndDiscard(sql.NewContext(ctx, sql.WithSession(sess), sql.WithQuery(use)), use); err != nil {
+			writeQueryError(w, ctx, err)
+			return
+		}
+	}
+
+	sqlCtx := sql.NewContext(ctx, sql.WithSession(sess), sql.WithQuery(req.Query))
+	sch, iter, err := s.query(sqlCtx, req.Query, bindings)
+	if err != nil {
+		writeQueryError(w, ctx, err)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		s.streamRows(w, sqlCtx, sch, iter)
+	} else {
+		s.writeRows(w, sqlCtx, sch, iter)
+	}
+}
+
+// timeout returns the timeout of a request which asked for |requested| milliseconds.
+func (s *Server) timeout(requested uint64) time.Duration {
+	timeout := s.cfg.QueryTimeout
+	if requested > 0 {
+		if r := time.Duration(requested) * time.Millisecond; timeout == 0 || r < timeout {
+			timeout = r
+		}
+	}
+	return timeout
+}
+
+// query analyzes |query| and returns the schema and the rows of its result. Queries which write are rejected if the
+// server is read only.
+func (s *Server) query(ctx *sql.Context, query string, bindings map[string]*querypb.BindVariable) (sql.Schema, sql.RowIter, error) {
+	e := s.cfg.Engine
+	parsed, _, _, err := e.Parser.Parse(ctx, query, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	node, err := e.BoundQueryPlan(ctx, query, parsed, bindings)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.cfg.ReadOnly && !plan.IsReadOnly(node) {
+		return nil, nil, errReadOnly
+	}
+
+	return e.PrepQueryPlanForExecution(ctx, query, node)
+}
+
+func (s *Server) runAndDiscard(ctx *sql.Context, query string) error {
+	_, iter, err := s.cfg.Engine.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	_, err = sql.RowIterToRows(ctx, iter)
+	return err
+}
+
+// closeSession releases the resources of the session of a request, like the MySQL handler does for closed
+// connections.
+func (s *Server) closeSession(id uint32, sess sql.Session) {
+	e := s.cfg.Engine
+	defer e.CloseSession(id)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sess))
+	if dSess, ok := sess.(*dsess.DoltSession); ok {
+		if err := dSess.Close(ctx); err != nil {
+			s.logError(id, "unable to close session", err)
+		}
+	}
+	if _, err := e.LS.ReleaseAll(ctx); err != nil {
+		s.logError(id, "unable to release all locks on session close", err)
+	}
+	if err := e.Analyzer.Catalog.UnlockTables(ctx, id); err != nil {
+		s.logError(id, "unable to unlock tables on session close", err)
+	}
+}
+
+// writeRows writes the result of a query as a single JSON document. The document holds at most MaxRows rows, and the
+// query is stopped once more rows are read.
+func (s *Server) writeRows(w http.ResponseWriter, ctx *sql.Context, sch sql.Schema, iter sql.RowIter) {
+	if types.IsOkResultSchema(sch) {
+		rows, err := sql.RowIterToRows(ctx, iter)
+		if err != nil {
+			writeQueryError(w, ctx, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, okResult(rows))
+		return
+	}
+
+	resp := rowsResponse{Columns: columns(sch), Rows: [][]json.RawMessage{}}
+	err := func() (err error) {
+		defer func() {
+			if cerr := iter.Close(ctx); err == nil {
+				err = cerr
+			}
+		}()
+
+		for {
+			row, err := iter.Next(ctx)
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if s.cfg.MaxRows > 0 && uint64(len(resp.Rows)) == s.cfg.MaxRows {
+				resp.Truncated = true
+				return nil
+			}
+
+			vals, err := encodeRow(ctx, sch, row)
+			if err != nil {
+				return err
+			}
+			resp.Rows = append(resp.Rows, vals)
+		}
+	}()
+	if err != nil {
+		writeQueryError(w, ctx, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// streamRows writes the result of a query as newline delimited JSON. The first line is an object with the columns of
+// the result, which is followed by a JSON array per row. Errors after the response started are written as a last line
+// with an error object.
+func (s *Server) streamRows(w http.ResponseWriter, ctx *sql.Context, sch sql.Schema, iter sql.RowIter) {
+	if types.IsOkResultSchema(sch) {
+		rows, err := sql.RowIterToRows(ctx, iter)
+		if err != nil {
+			writeQueryError(w, ctx, err)
+			return
+		}
+		w.Header().Set("Content-Type", ndjsonContentType)
+		json.NewEncoder(w).Encode(okResult(rows))
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	err := func() (err error) {
+		defer func() {
+			if cerr := iter.Close(ctx); err == nil {
+				err = cerr
+			}
+		}()
+
+		if err = enc.Encode(struct {
+			Columns []column `json:"columns"`
+		}{columns(sch)}); err != nil {
+			return err
+		}
+		for {
+			row, err := iter.Next(ctx)
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			vals, err := encodeRow(ctx, sch, row)
+			if err != nil {
+				return err
+			}
+			if err = enc.Encode(vals); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}()
+	if err != nil {
+		_, resp := queryError(ctx, err)
+		enc.Encode(resp)
+	}
+}
+
+func (s *Server) logError(id uint32, msg string, err error) {
+	if s.cfg.Logger != nil {
+		s.cfg.Logger.WithField("connectionID", id).Warnf("%s: %v", msg, err)
+	}
+}
+
+// okResult returns the response to a query which returned the OkResult |rows|.
+func okResult(rows []sql.Row) okResponse {
+	var resp okResponse
+	if len(rows) == 1 && types.IsOkResult(rows[0]) {
+		res := types.GetOkResult(rows[0])
+		resp.RowsAffected = res.RowsAffected
+		resp.LastInsertID = res.InsertID
+	}
+	return resp
+}
+
+func columns(sch sql.Schema) []column {
+	cols := make([]column, len(sch))
+	for i, col := range sch {
+		cols[i] = column{Name: col.Name, Type: col.Type.String()}
+	}
+	return cols
+}
+
+// wantsNDJSON returns whether the response to |r| should be streamed as newline delimited JSON.
+func wantsNDJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "ndjson"
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// queryError returns the error response for |err|, which was returned while running a query with |ctx|.
+func queryError(ctx context.Context, err error) (int, errorResponse) {
+	if errors.Is(err, errReadOnly) {
+		return http.StatusForbidden, errorResponse{Error: err.Error()}
+	}
+	if ctxErr := ctx.Err(); errors.Is(ctxErr, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, errorResponse{Error: "query timed out"}
+	}
+
+	resp := errorResponse{Error: err.Error()}
+	var sqlErr *mysql.SQLError
+	if errors.As(sql.CastSQLError(err), &sqlErr) {
+		resp.Code = sqlErr.Num
+		resp.SQLState = sqlErr.State
+		switch sqlErr.Num {
+		case mysql.ERAccessDeniedError, mysql.ERDBAccessDenied, mysql.ERSpecifiedAccessDenied:
+			return http.StatusForbidden, resp
+		}
+	}
+	if isAccessDenied(err) {
+		return http.StatusForbidden, resp
+	}
+	return http.StatusBadRequest, resp
+}
+
+// isAccessDenied returns whether |err| was returned because the grants or the branch_control permissions of the user
+// do not allow the query.
+func isAccessDenied(err error) bool {
+	return sql.ErrPrivilegeCheckFailed.Is(err) ||
+		sql.ErrDatabaseAccessDeniedForUser.Is(err) ||
+		sql.ErrTableAccessDeniedForUser.Is(err) ||
+		branch_control.ErrIncorrectPermissions.Is(err)
+}
+
+func writeQueryError(w http.ResponseWriter, ctx context.Context, err error) {
+	status, resp := queryError(ctx, err)
+	writeJSON(w, status, resp)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpapi implements an HTTP API for running queries against the go-mysql-server engine of a sql-server.
+// Clients authenticate each request with HTTP basic authentication as a SQL user and POST a JSON document with the
+// query and its parameters to /query. Results are returned as a single JSON document, or streamed as newline
+// delimited JSON.
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	gms "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionBuilder creates the SQL session of a request for |user|, connecting from |addr|.
+type SessionBuilder func(ctx context.Context, connID uint32, user, addr string) (sql.Session, error)
+
+// Authenticator returns an error if |password| is not valid for |user| connecting from |addr|.
+type Authenticator func(user, password string, addr net.Addr) error
+
+// Config is the configuration of a Server.
+type Config struct {
+	// Address is the host:port to listen on.
+	Address string
+	// Engine runs the queries of requests.
+	Engine *gms.Engine
+	// NextConnectionID allocates the connection IDs of the sessions of requests. IDs must be distinct from the IDs of
+	// the other listeners of the server.
+	NextConnectionID func() uint32
+	// NewSession creates the sessions of requests.
+	NewSession SessionBuilder
+	// Authenticate checks the basic authentication credentials of requests.
+	Authenticate Authenticator
+	// ReadOnly rejects queries which write.
+	ReadOnly bool
+	// QueryTimeout is the longest time a query may run. 0 means no limit.
+	QueryTimeout time.Duration
+	// MaxRows is the largest number of rows returned in a JSON document. Results with more rows are truncated, and
+	// clients must stream them as newline delimited JSON to get every row. 0 means no limit.
+	MaxRows uint64
+	// TLSConfig, if set, makes the server serve HTTPS instead of HTTP.
+	TLSConfig *tls.Config
+	// RequireSecureTransport rejects requests which were not made over TLS.
+	RequireSecureTransport bool
+	Logger                 *logrus.Entry
+}
+
+// Server serves the HTTP query API.
+type Server struct {
+	cfg  Config
+	lis  net.Listener
+	srv  *http.Server
+	done atomic.Bool
+}
+
+// NewServer returns a Server listening on the address in |cfg|. Requests are not served until Serve is called.
+func NewServer(cfg Config) (*Server, error) {
+	lis, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{cfg: cfg, lis: lis}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/query", s.handleQuery)
+	s.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         cfg.TLSConfig,
+	}
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Serve serves requests until the server is closed.
+func (s *Server) Serve() error {
+	var err error
+	if s.cfg.TLSConfig != nil {
+		// The certificates are in the TLSConfig of the http.Server
+		err = s.srv.ServeTLS(s.lis, "", "")
+	} else {
+		err = s.srv.Serve(s.lis)
+	}
+	if errors.Is(err, http.ErrServerClosed) || s.done.Load() {
+		return nil
+	}
+	return err
+}
+
+// Close stops accepting requests and cancels the requests in flight.
+func (s *Server) Close() error {
+	s.done.Store(true)
+	err := s.srv.Close()
+	// The listener is only closed by the http.Server once Serve was called
+	if lisErr := s.lis.Close(); err == nil && !errors.Is(lisErr, net.ErrClosed) {
+		err = lisErr
+	}
+	return err
+}
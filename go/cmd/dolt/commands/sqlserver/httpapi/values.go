@@ -0,0 +1,151 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+)
+
+// bindVariables returns the bind variables for the JSON |params| of a request. The values of an array bind the ?
+// placeholders of the query, which the parser names v1, v2, ..., and the values of an object bind placeholders by
+// name.
+func bindVariables(params json.RawMessage) (map[string]*querypb.BindVariable, error) {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil, nil
+	}
+
+	var values map[string]json.RawMessage
+	switch params[0] {
+	case '[':
+		var arr []json.RawMessage
+		if err := json.Unmarshal(params, &arr); err != nil {
+			return nil, err
+		}
+		values = make(map[string]json.RawMessage, len(arr))
+		for i, v := range arr {
+			values["v"+strconv.Itoa(i+1)] = v
+		}
+	case '{':
+		if err := json.Unmarshal(params, &values); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("params must be an array or an object")
+	}
+
+	bindings := make(map[string]*querypb.BindVariable, len(values))
+	for name, v := range values {
+		bv, err := bindVariable(v)
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %w", name, err)
+		}
+		bindings[name] = bv
+	}
+	return bindings, nil
+}
+
+// bindVariable converts the JSON value |v| to a bind variable. Numbers keep their textual representation, so that
+// decimals are not rounded, and arrays and objects are bound as JSON strings.
+func bindVariable(v json.RawMessage) (*querypb.BindVariable, error) {
+	dec := json.NewDecoder(bytes.NewReader(v))
+	dec.UseNumber()
+	var val interface{}
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	switch val := val.(type) {
+	case nil:
+		return &querypb.BindVariable{Type: sqltypes.Null}, nil
+	case bool:
+		return sqltypes.BuildBindVariable(val)
+	case string:
+		return sqltypes.StringBindVariable(val), nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return sqltypes.Int64BindVariable(i), nil
+		}
+		if u, err := strconv.ParseUint(val.String(), 10, 64); err == nil {
+			return sqltypes.Uint64BindVariable(u), nil
+		}
+		return &querypb.BindVariable{Type: sqltypes.Float64, Value: []byte(val.String())}, nil
+	default:
+		return sqltypes.StringBindVariable(string(bytes.TrimSpace(v))), nil
+	}
+}
+
+// encodeRow encodes the values of |row| of |sch| as JSON. Integers and floats are encoded as numbers, JSON values as
+// JSON, binary values as base64 encoded strings and all other values as strings of their SQL representation.
+func encodeRow(ctx *sql.Context, sch sql.Schema, row sql.Row) ([]json.RawMessage, error) {
+	vals := make([]json.RawMessage, len(row))
+	for i, v := range row {
+		var err error
+		if vals[i], err = encodeValue(ctx, sch[i].Type, v); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+func encodeValue(ctx *sql.Context, typ sql.Type, v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return json.RawMessage("null"), nil
+	}
+
+	switch {
+	case types.IsJSON(typ):
+		if wrapper, ok := v.(sql.JSONWrapper); ok {
+			doc, err := wrapper.ToInterface()
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(doc)
+		}
+	case types.IsInteger(typ) || types.IsFloat(typ):
+		val, err := typ.SQL(ctx, nil, v)
+		if err != nil {
+			return nil, err
+		}
+		if f, err := strconv.ParseFloat(val.ToString(), 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return json.RawMessage(val.ToString()), nil
+		}
+		return json.Marshal(val.ToString())
+	case types.IsBinaryType(typ):
+		switch v := v.(type) {
+		case []byte:
+			return json.Marshal(base64.StdEncoding.EncodeToString(v))
+		case string:
+			return json.Marshal(base64.StdEncoding.EncodeToString([]byte(v)))
+		}
+	}
+
+	val, err := typ.SQL(ctx, nil, v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(val.ToString())
+}
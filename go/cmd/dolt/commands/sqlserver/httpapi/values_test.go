@@ -0,0 +1,72 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindVariables(t *testing.T) {
+	bindings, err := bindVariables(json.RawMessage(`[1, "a", null, true, 1.50, 18446744073709551615, {"b": [1]}]`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*querypb.BindVariable{
+		"v1": sqltypes.Int64BindVariable(1),
+		"v2": sqltypes.StringBindVariable("a"),
+		"v3": {Type: sqltypes.Null},
+		"v4": sqltypes.Int8BindVariable(1),
+		"v5": {Type: sqltypes.Float64, Value: []byte("1.50")},
+		"v6": sqltypes.Uint64BindVariable(18446744073709551615),
+		"v7": sqltypes.StringBindVariable(`{"b": [1]}`),
+	}, bindings)
+
+	bindings, err = bindVariables(json.RawMessage(`{"name": "a"}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*querypb.BindVariable{"name": sqltypes.StringBindVariable("a")}, bindings)
+
+	bindings, err = bindVariables(nil)
+	require.NoError(t, err)
+	assert.Nil(t, bindings)
+
+	_, err = bindVariables(json.RawMessage(`"a"`))
+	assert.Error(t, err)
+}
+
+func TestEncodeRow(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	sch := sql.Schema{
+		{Name: "i", Type: types.Int64},
+		{Name: "f", Type: types.Float64},
+		{Name: "d", Type: types.MustCreateDecimalType(10, 2)},
+		{Name: "s", Type: types.Text},
+		{Name: "b", Type: types.Blob},
+		{Name: "j", Type: types.JSON},
+		{Name: "n", Type: types.Int64},
+	}
+	row := sql.Row{int64(-1), 1.5, "2.50", "a\"b", []byte{0, 1}, types.MustJSON(`{"a": [1, 2]}`), nil}
+
+	vals, err := encodeRow(ctx, sch, row)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(vals)
+	require.NoError(t, err)
+	assert.Equal(t, `[-1,1.5,"2.50","a\"b","AAE=",{"a":[1,2]},null]`, string(encoded))
+}
@@ -22,9 +22,11 @@ import (
 	"github.com/dolthub/go-mysql-server/server"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/cluster"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/clusterdb"
 	"github.com/dolthub/dolt/go/libraries/utils/version"
+	"github.com/dolthub/dolt/go/store/nbs"
 )
 
 const (
@@ -47,9 +49,14 @@ type metricsListener struct {
 	histQueryDur           prometheus.Histogram
 	gaugeVersion           prometheus.Gauge
 
+	// disk cache metrics of stores on object storage
+	diskCacheMetrics []prometheus.Collector
+
 	// replication metrics
 	isReplicaGauges      *prometheus.GaugeVec
 	replicationLagGauges *prometheus.GaugeVec
+	writeWaitGauges      *prometheus.GaugeVec
+	writeAckedGauges     *prometheus.GaugeVec
 
 	// used in updating cluster metrics
 	clusterStatus  clusterdb.ClusterStatusProvider
@@ -97,14 +104,25 @@ func newMetricsListener(labels prometheus.Labels, versionStr string, clusterStat
 			Help:        "The reported replication lag of this server when it is a primary to the given standby.",
 			ConstLabels: labels,
 		}, []string{dbLabel, remoteLabel}),
+		writeWaitGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "dss_replication_write_wait",
+			Help:        "How long in milliseconds the most recent write to the database waited for its standbys to reach the write quorum, when this server is a primary.",
+			ConstLabels: labels,
+		}, []string{dbLabel}),
+		writeAckedGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "dss_replication_write_acked",
+			Help:        "one if the given standby acknowledged the most recent write to the database which waited for the write quorum, zero otherwise",
+			ConstLabels: labels,
+		}, []string{dbLabel, remoteLabel}),
 		isReplicaGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name:        "dss_is_replica",
 			Help:        "one if the server is currently in this role, zero otherwise",
 			ConstLabels: labels,
 		}, []string{dbLabel}),
-		clusterStatus:  clusterStatus,
-		mu:             &sync.Mutex{},
-		clusterSeenDbs: make(map[string]struct{}),
+		diskCacheMetrics: newDiskCacheMetrics(labels),
+		clusterStatus:    clusterStatus,
+		mu:               &sync.Mutex{},
+		clusterSeenDbs:   make(map[string]struct{}),
 	}
 
 	u32Version, err := version.Encode(versionStr)
@@ -126,6 +144,11 @@ func newMetricsListener(labels prometheus.Labels, versionStr string, clusterStat
 	prometheus.MustRegister(ml.histQueryDur)
 	prometheus.MustRegister(ml.replicationLagGauges)
 	prometheus.MustRegister(ml.isReplicaGauges)
+	prometheus.MustRegister(ml.writeWaitGauges)
+	prometheus.MustRegister(ml.writeAckedGauges)
+	for _, c := range ml.diskCacheMetrics {
+		prometheus.MustRegister(c)
+	}
 
 	go func() {
 		for ml.updateReplMetrics() {
@@ -163,6 +186,17 @@ func (ml *metricsListener) updateReplMetrics() bool {
 			} else {
 				ml.replicationLagGauges.WithLabelValues(status.Database, status.Remote).Set(float64(status.ReplicationLag.Milliseconds()))
 			}
+
+			if status.LastWriteWait != nil {
+				ml.writeWaitGauges.WithLabelValues(status.Database).Set(float64(status.LastWriteWait.Milliseconds()))
+			}
+			if status.LastWriteAcked != nil {
+				if *status.LastWriteAcked {
+					ml.writeAckedGauges.WithLabelValues(status.Database, status.Remote).Set(1.0)
+				} else {
+					ml.writeAckedGauges.WithLabelValues(status.Database, status.Remote).Set(0.0)
+				}
+			}
 		} else {
 			ml.isReplicaGauges.WithLabelValues(status.Database).Set(1.0)
 			ml.replicationLagGauges.WithLabelValues(status.Database, status.Remote).Set(-1.0)
@@ -174,6 +208,8 @@ func (ml *metricsListener) updateReplMetrics() bool {
 		if _, ok := dbNames[db]; !ok {
 			ml.isReplicaGauges.DeletePartialMatch(prometheus.Labels{"database": db})
 			ml.replicationLagGauges.DeletePartialMatch(prometheus.Labels{"database": db})
+			ml.writeWaitGauges.DeletePartialMatch(prometheus.Labels{"database": db})
+			ml.writeAckedGauges.DeletePartialMatch(prometheus.Labels{"database": db})
 		}
 	}
 	ml.clusterSeenDbs = dbNames
@@ -207,16 +243,60 @@ func (ml *metricsListener) Close() {
 	prometheus.Unregister(ml.gaugeConcurrentConn)
 	prometheus.Unregister(ml.gaugeConcurrentQueries)
 	prometheus.Unregister(ml.histQueryDur)
+	for _, c := range ml.diskCacheMetrics {
+		prometheus.Unregister(c)
+	}
 
 	ml.closeReplicationMetrics()
 }
 
+// newDiskCacheMetrics returns the metrics of the disk cache of stores on object storage, which are zero when there is
+// no disk cache.
+func newDiskCacheMetrics(labels prometheus.Labels) []prometheus.Collector {
+	stat := func(f func(nbs.DiskCacheStats) float64) func() float64 {
+		return func() float64 {
+			stats, ok := dbfactory.DiskCacheStats()
+			if !ok {
+				return 0
+			}
+			return f(stats)
+		}
+	}
+	counter := func(name, help string, f func(nbs.DiskCacheStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help, ConstLabels: labels}, stat(f))
+	}
+	gauge := func(name, help string, f func(nbs.DiskCacheStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help, ConstLabels: labels}, stat(f))
+	}
+
+	return []prometheus.Collector{
+		counter("dss_disk_cache_hits", "Count of chunks read from the disk cache",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Hits) }),
+		counter("dss_disk_cache_misses", "Count of chunks which were not in the disk cache",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Misses) }),
+		counter("dss_disk_cache_inserts", "Count of chunks added to the disk cache",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Inserts) }),
+		counter("dss_disk_cache_evictions", "Count of chunks evicted from the disk cache",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Evictions) }),
+		counter("dss_disk_cache_integrity_failures", "Count of chunks in the disk cache which did not match their hash",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.IntegrityFailures) }),
+		gauge("dss_disk_cache_chunks", "Number of chunks in the disk cache",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Chunks) }),
+		gauge("dss_disk_cache_size_bytes", "Size of the disk cache in bytes",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.Size) }),
+		gauge("dss_disk_cache_max_size_bytes", "Maximum size of the disk cache in bytes",
+			func(s nbs.DiskCacheStats) float64 { return float64(s.MaxSize) }),
+	}
+}
+
 func (ml *metricsListener) closeReplicationMetrics() {
 	ml.mu.Lock()
 	defer ml.mu.Unlock()
 
 	prometheus.Unregister(ml.replicationLagGauges)
 	prometheus.Unregister(ml.isReplicaGauges)
+	prometheus.Unregister(ml.writeWaitGauges)
+	prometheus.Unregister(ml.writeAckedGauges)
 
 	ml.done = true
 }
@@ -0,0 +1,660 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/mysql"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// preparedStatement is a statement created by a Parse message.
+type preparedStatement struct {
+	statement
+	paramOIDs []uint32
+	// schema is the schema of the rows returned by the statement, nil if it returns none
+	schema sql.Schema
+}
+
+// portal is a prepared statement bound to parameters by a Bind message.
+type portal struct {
+	stmt          *preparedStatement
+	bindings      map[string]*querypb.BindVariable
+	resultFormats []int16
+}
+
+// conn is a single client connection.
+type conn struct {
+	srv *Server
+	nc  net.Conn
+	be  *pgproto3.Backend
+	// secure is true once the connection has been upgraded to TLS
+	secure bool
+	id     uint32
+	// secretKey must be sent along with the ID of the connection in a CancelRequest to cancel its query
+	secretKey uint32
+	sess      sql.Session
+	// ctx is cancelled when the connection is closed, which cancels any query it is running
+	ctx    context.Context
+	cancel context.CancelFunc
+	// queryCancel cancels the query the connection is running, if any
+	queryMu     sync.Mutex
+	queryCancel context.CancelFunc
+	// params are the Postgres run-time parameters of the connection, keyed by lower-cased name
+	params   map[string]string
+	typeMap  *pgtype.Map
+	stmts    map[string]*preparedStatement
+	portals  map[string]*portal
+	skipSync bool
+}
+
+func newConn(srv *Server, nc net.Conn, id uint32) (*conn, error) {
+	var key [4]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &conn{
+		srv:       srv,
+		nc:        nc,
+		be:        pgproto3.NewBackend(nc, nc),
+		id:        id,
+		secretKey: binary.BigEndian.Uint32(key[:]),
+		ctx:       ctx,
+		cancel:    cancel,
+		typeMap:   pgtype.NewMap(),
+		stmts:     make(map[string]*preparedStatement),
+		portals:   make(map[string]*portal),
+	}, nil
+}
+
+// errCancelRequest is returned by startup for connections which were only opened to send a CancelRequest.
+var errCancelRequest = errors.New("cancel request")
+
+// serve runs the connection until the client terminates it or an I/O error occurs.
+func (c *conn) serve() {
+	defer c.nc.Close()
+	defer c.cancel()
+
+	err := c.startup()
+	if errors.Is(err, errCancelRequest) {
+		return
+	} else if err != nil {
+		c.logError("startup failed", err)
+		return
+	}
+	defer c.close()
+
+	for {
+		msg, err := c.be.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.logError("failed to receive message", err)
+			}
+			return
+		}
+
+		// After an error in the extended query protocol, messages are discarded until the next Sync
+		if _, ok := msg.(*pgproto3.Sync); c.skipSync && !ok {
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *pgproto3.Query:
+			c.simpleQuery(m.String)
+			c.sendReady()
+		case *pgproto3.Parse:
+			c.extended(c.parse(m))
+		case *pgproto3.Bind:
+			c.extended(c.bind(m))
+		case *pgproto3.Describe:
+			c.extended(c.describe(m))
+		case *pgproto3.Execute:
+			c.extended(c.execute(m))
+		case *pgproto3.Close:
+			if m.ObjectType == 'S' {
+				delete(c.stmts, m.Name)
+			} else {
+				delete(c.portals, m.Name)
+			}
+			c.be.Send(&pgproto3.CloseComplete{})
+		case *pgproto3.Sync:
+			c.skipSync = false
+			c.sendReady()
+		case *pgproto3.Flush:
+		case *pgproto3.Terminate:
+			return
+		default:
+			c.sendError(fmt.Errorf("unsupported message type %T", msg))
+		}
+
+		switch msg.(type) {
+		case *pgproto3.Query, *pgproto3.Sync, *pgproto3.Flush:
+			if err := c.be.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// startup handles the startup message and authentication of the connection, and creates its session.
+func (c *conn) startup() error {
+	var startup *pgproto3.StartupMessage
+	for startup == nil {
+		msg, err := c.be.ReceiveStartupMessage()
+		if err != nil {
+			return err
+		}
+		switch m := msg.(type) {
+		case *pgproto3.StartupMessage:
+			startup = m
+		case *pgproto3.SSLRequest:
+			if c.srv.cfg.TLSConfig == nil || c.secure {
+				// Clients may continue unencrypted
+				if _, err = c.nc.Write([]byte{'N'}); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err = c.nc.Write([]byte{'S'}); err != nil {
+				return err
+			}
+			tc := tls.Server(c.nc, c.srv.cfg.TLSConfig)
+			if err = tc.Handshake(); err != nil {
+				return err
+			}
+			c.be = pgproto3.NewBackend(tc, tc)
+			c.secure = true
+		case *pgproto3.CancelRequest:
+			// Cancel requests are sent on a new connection, which is closed without a response
+			c.srv.cancelQuery(m.ProcessID, m.SecretKey)
+			return errCancelRequest
+		case *pgproto3.GSSEncRequest:
+			// GSSAPI encryption isn't supported, clients may continue unencrypted or request TLS
+			if _, err = c.nc.Write([]byte{'N'}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported startup message %T", msg)
+		}
+	}
+
+	user := startup.Parameters["user"]
+	if c.srv.cfg.RequireSecureTransport && !c.secure {
+		// The password is sent in cleartext, so it must not be requested over an unencrypted connection
+		c.sendFatal("28000", "connections using insecure transport are prohibited while --require_secure_transport=ON")
+		return errors.New("insecure connection refused")
+	}
+	c.be.Send(&pgproto3.AuthenticationCleartextPassword{})
+	if err := c.be.Flush(); err != nil {
+		return err
+	}
+	if err := c.be.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+		return err
+	}
+	msg, err := c.be.Receive()
+	if err != nil {
+		return err
+	}
+	pw, ok := msg.(*pgproto3.PasswordMessage)
+	if !ok {
+		return fmt.Errorf("expected password message, got %T", msg)
+	}
+	if err = c.srv.cfg.Authenticate(user, pw.Password, c.nc.RemoteAddr()); err != nil {
+		c.sendFatal("28P01", fmt.Sprintf("password authentication failed for user \"%s\"", user))
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(c.nc.RemoteAddr().String())
+	if err != nil {
+		return err
+	}
+	c.sess, err = c.srv.cfg.NewSession(c.ctx, c.id, user, host)
+	if err != nil {
+		c.sendFatal("XX000", err.Error())
+		return err
+	}
+
+	if db := startup.Parameters["database"]; db != "" {
+		if err = c.runAndDiscard("USE " + quoteIdentifier(db)); err != nil {
+			c.sendFatal("3D000", fmt.Sprintf("database \"%s\" does not exist", db))
+			return err
+		}
+	}
+
+	c.params = map[string]string{
+		"server_version":              "15.0",
+		"server_encoding":             "UTF8",
+		"client_encoding":             "UTF8",
+		"datestyle":                   "ISO, MDY",
+		"intervalstyle":               "postgres",
+		"timezone":                    "UTC",
+		"integer_datetimes":           "on",
+		"standard_conforming_strings": "on",
+		"is_superuser":                "off",
+		"session_authorization":       user,
+		"application_name":            startup.Parameters["application_name"],
+		"search_path":                 "public",
+		"transaction_isolation":       "repeatable read",
+		"dolt_version":                c.srv.cfg.Version,
+	}
+
+	c.be.Send(&pgproto3.AuthenticationOk{})
+	for _, name := range reportedParams {
+		c.be.Send(&pgproto3.ParameterStatus{Name: name, Value: c.params[strings.ToLower(name)]})
+	}
+	c.be.Send(&pgproto3.BackendKeyData{ProcessID: c.id, SecretKey: c.secretKey})
+	c.sendReady()
+	return c.be.Flush()
+}
+
+// reportedParams are the run-time parameters reported to clients with ParameterStatus messages.
+var reportedParams = []string{
+	"server_version", "server_encoding", "client_encoding", "DateStyle", "IntervalStyle", "TimeZone",
+	"integer_datetimes", "standard_conforming_strings", "is_superuser", "session_authorization", "application_name",
+}
+
+// close releases the resources of the connection's session, like the MySQL handler does for closed connections.
+func (c *conn) close() {
+	e := c.srv.cfg.Engine
+	defer e.CloseSession(c.id)
+
+	ctx := c.newContext("")
+	if dSess, ok := ctx.Session.(*dsess.DoltSession); ok {
+		if err := dSess.Close(ctx); err != nil {
+			c.logError("unable to close session", err)
+		}
+	}
+	if _, err := e.LS.ReleaseAll(ctx); err != nil {
+		c.logError("unable to release all locks on session close", err)
+	}
+	if err := e.Analyzer.Catalog.UnlockTables(ctx, c.id); err != nil {
+		c.logError("unable to unlock tables on session close", err)
+	}
+}
+
+// simpleQuery runs the statements of a simple query protocol message.
+func (c *conn) simpleQuery(query string) {
+	stmts := splitStatements(query)
+	if len(stmts) == 0 {
+		c.be.Send(&pgproto3.EmptyQueryResponse{})
+		return
+	}
+
+	for _, toks := range stmts {
+		stmt, err := translate(toks, c.srv.procedures)
+		if err == nil {
+			err = c.run(stmt, nil, nil, true)
+		}
+		if err != nil {
+			c.sendError(err)
+			return
+		}
+	}
+}
+
+// extended handles the result of an extended query protocol message.
+func (c *conn) extended(err error) {
+	if err != nil {
+		c.sendError(err)
+		c.skipSync = true
+	}
+}
+
+func (c *conn) parse(m *pgproto3.Parse) error {
+	stmts := splitStatements(m.Query)
+	if len(stmts) > 1 {
+		return fmt.Errorf("cannot insert multiple commands into a prepared statement")
+	}
+
+	ps := &preparedStatement{}
+	if len(stmts) == 1 {
+		var err error
+		ps.statement, err = translate(stmts[0], c.srv.procedures)
+		if err != nil {
+			return err
+		}
+		ps.schema, err = c.resultSchema(ps.statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	ps.paramOIDs = make([]uint32, ps.numParams)
+	copy(ps.paramOIDs, m.ParameterOIDs)
+	c.stmts[m.Name] = ps
+	c.be.Send(&pgproto3.ParseComplete{})
+	return nil
+}
+
+// resultSchema returns the schema of the rows returned by |stmt|, or nil if it doesn't return rows.
+func (c *conn) resultSchema(stmt statement) (sql.Schema, error) {
+	if stmt.version {
+		return textSchema("version"), nil
+	}
+	if _, ok := c.params[stmt.showParam]; ok && stmt.showParam != "" {
+		return textSchema(stmt.showParam), nil
+	}
+	if stmt.procedure != "" {
+		// The procedure isn't resolved until the CALL is executed, so its schema is taken from its definition
+		return c.srv.procedures[stmt.procedure], nil
+	}
+	if stmt.query == "" {
+		return nil, nil
+	}
+
+	ctx := c.newContext(stmt.query)
+	analyzed, err := c.srv.cfg.Engine.PrepareQuery(ctx, stmt.query)
+	if err != nil {
+		return nil, err
+	}
+
+	// These nodes return an OK result when they are executed, see the MySQL handler's ComPrepare
+	switch analyzed.(type) {
+	case *plan.InsertInto, *plan.Update, *plan.UpdateJoin, *plan.DeleteFrom:
+		return nil, nil
+	}
+	if types.IsOkResultSchema(analyzed.Schema()) {
+		return nil, nil
+	}
+	return analyzed.Schema(), nil
+}
+
+func (c *conn) bind(m *pgproto3.Bind) error {
+	ps, ok := c.stmts[m.PreparedStatement]
+	if !ok {
+		return fmt.Errorf("prepared statement \"%s\" does not exist", m.PreparedStatement)
+	}
+	if len(m.Parameters) != len(ps.paramOIDs) {
+		return fmt.Errorf("bind message supplies %d parameters, but prepared statement \"%s\" requires %d",
+			len(m.Parameters), m.PreparedStatement, len(ps.paramOIDs))
+	}
+
+	var bindings map[string]*querypb.BindVariable
+	if len(m.Parameters) > 0 {
+		bindings = make(map[string]*querypb.BindVariable, len(m.Parameters))
+	}
+	for i, p := range m.Parameters {
+		bv, err := bindVariable(c.typeMap, ps.paramOIDs[i], formatCode(m.ParameterFormatCodes, i), p)
+		if err != nil {
+			return err
+		}
+		bindings["v"+strconv.Itoa(i+1)] = bv
+	}
+
+	c.portals[m.DestinationPortal] = &portal{stmt: ps, bindings: bindings, resultFormats: m.ResultFormatCodes}
+	c.be.Send(&pgproto3.BindComplete{})
+	return nil
+}
+
+func (c *conn) describe(m *pgproto3.Describe) error {
+	var sch sql.Schema
+	var formats []int16
+	if m.ObjectType == 'S' {
+		ps, ok := c.stmts[m.Name]
+		if !ok {
+			return fmt.Errorf("prepared statement \"%s\" does not exist", m.Name)
+		}
+		c.be.Send(&pgproto3.ParameterDescription{ParameterOIDs: ps.paramOIDs})
+		sch = ps.schema
+	} else {
+		p, ok := c.portals[m.Name]
+		if !ok {
+			return fmt.Errorf("portal \"%s\" does not exist", m.Name)
+		}
+		sch, formats = p.stmt.schema, p.resultFormats
+	}
+
+	if sch == nil {
+		c.be.Send(&pgproto3.NoData{})
+	} else {
+		c.be.Send(rowDescription(sch, formats))
+	}
+	return nil
+}
+
+func (c *conn) execute(m *pgproto3.Execute) error {
+	p, ok := c.portals[m.Portal]
+	if !ok {
+		return fmt.Errorf("portal \"%s\" does not exist", m.Portal)
+	}
+	if p.stmt.tag == "" {
+		c.be.Send(&pgproto3.EmptyQueryResponse{})
+		return nil
+	}
+	return c.run(p.stmt.statement, p.bindings, p.resultFormats, false)
+}
+
+// run runs |stmt| and sends its results. The row description is only sent if |describe| is true, since it is sent
+// in response to Describe messages in the extended query protocol.
+func (c *conn) run(stmt statement, bindings map[string]*querypb.BindVariable, formats []int16, describe bool) error {
+	switch {
+	case stmt.setParam != "":
+		c.params[stmt.setParam] = stmt.setValue
+		for _, name := range reportedParams {
+			if strings.ToLower(name) == stmt.setParam {
+				c.be.Send(&pgproto3.ParameterStatus{Name: name, Value: stmt.setValue})
+			}
+		}
+		c.be.Send(&pgproto3.CommandComplete{CommandTag: []byte(stmt.tag)})
+		return nil
+	case stmt.version:
+		version := fmt.Sprintf("PostgreSQL 15.0 (Dolt %s)", c.srv.cfg.Version)
+		return c.sendRows(c.newContext(""), textSchema("version"), sql.RowsToRowIter(sql.Row{version}), formats, describe, "SELECT")
+	case stmt.showParam != "":
+		if v, ok := c.params[stmt.showParam]; ok {
+			return c.sendRows(c.newContext(""), textSchema(stmt.showParam), sql.RowsToRowIter(sql.Row{v}), formats, describe, "SHOW")
+		}
+	case stmt.query == "":
+		c.be.Send(&pgproto3.CommandComplete{CommandTag: []byte(stmt.tag)})
+		return nil
+	}
+
+	ctx, cancel := c.newQueryContext(stmt.query)
+	defer cancel()
+	sch, iter, err := c.srv.cfg.Engine.QueryWithBindings(ctx, stmt.query, nil, bindings)
+	if err != nil {
+		return err
+	}
+
+	if types.IsOkResultSchema(sch) {
+		rows, err := sql.RowIterToRows(ctx, iter)
+		if err != nil {
+			return err
+		}
+		var affected uint64
+		if len(rows) == 1 && types.IsOkResult(rows[0]) {
+			affected = types.GetOkResult(rows[0]).RowsAffected
+		}
+		c.be.Send(&pgproto3.CommandComplete{CommandTag: []byte(commandComplete(stmt.tag, affected))})
+		return nil
+	}
+
+	return c.sendRows(ctx, sch, iter, formats, describe, "SELECT")
+}
+
+// rowsPerFlush is the number of data rows sent to the client at a time while a result is streamed.
+const rowsPerFlush = 128
+
+// sendRows streams the rows of |iter| of |sch| to the client, followed by a CommandComplete message for |tag|. Rows
+// are flushed as they are produced, so results are never held in memory, and a client that has gone away fails the
+// write, which cancels the query.
+func (c *conn) sendRows(ctx *sql.Context, sch sql.Schema, iter sql.RowIter, formats []int16, describe bool, tag string) (err error) {
+	defer func() {
+		if cerr := iter.Close(ctx); err == nil {
+			err = cerr
+		}
+	}()
+
+	if describe {
+		c.be.Send(rowDescription(sch, formats))
+	}
+
+	var n uint64
+	for {
+		row, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		values, err := encodeRow(ctx, c.typeMap, sch, row, formats)
+		if err != nil {
+			return err
+		}
+		c.be.Send(&pgproto3.DataRow{Values: values})
+		n++
+		if n%rowsPerFlush == 0 {
+			if err = c.be.Flush(); err != nil {
+				c.cancel()
+				return err
+			}
+		}
+	}
+
+	if tag == "SELECT" {
+		tag = commandComplete(tag, n)
+	}
+	c.be.Send(&pgproto3.CommandComplete{CommandTag: []byte(tag)})
+	return nil
+}
+
+// runAndDiscard runs |query| and discards its results.
+func (c *conn) runAndDiscard(query string) error {
+	ctx := c.newContext(query)
+	_, iter, err := c.srv.cfg.Engine.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	_, err = sql.RowIterToRows(ctx, iter)
+	return err
+}
+
+func (c *conn) newContext(query string) *sql.Context {
+	return sql.NewContext(c.ctx, sql.WithSession(c.sess), sql.WithQuery(query))
+}
+
+// newQueryContext returns the context of a query, which is cancelled by a CancelRequest for the connection or when the
+// connection is closed. The returned function must be called once the query is done.
+func (c *conn) newQueryContext(query string) (*sql.Context, func()) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.queryMu.Lock()
+	c.queryCancel = cancel
+	c.queryMu.Unlock()
+
+	return sql.NewContext(ctx, sql.WithSession(c.sess), sql.WithQuery(query)), func() {
+		c.queryMu.Lock()
+		c.queryCancel = nil
+		c.queryMu.Unlock()
+		cancel()
+	}
+}
+
+// cancelQuery cancels the query the connection is running, if any.
+func (c *conn) cancelQuery() {
+	c.queryMu.Lock()
+	defer c.queryMu.Unlock()
+	if c.queryCancel != nil {
+		c.queryCancel()
+	}
+}
+
+func (c *conn) sendReady() {
+	status := byte('I')
+	if c.sess != nil && c.sess.GetIgnoreAutoCommit() {
+		status = 'T'
+	}
+	c.be.Send(&pgproto3.ReadyForQuery{TxStatus: status})
+}
+
+func (c *conn) sendError(err error) {
+	c.be.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: sqlState(err), Message: err.Error()})
+}
+
+func (c *conn) sendFatal(code, msg string) {
+	c.be.Send(&pgproto3.ErrorResponse{Severity: "FATAL", Code: code, Message: msg})
+	_ = c.be.Flush()
+}
+
+func (c *conn) logError(msg string, err error) {
+	if c.srv.cfg.Logger != nil {
+		c.srv.cfg.Logger.WithField("connectionID", c.id).Warnf("%s: %v", msg, err)
+	}
+}
+
+// commandComplete returns the command tag for a statement that returned or affected |n| rows.
+func commandComplete(tag string, n uint64) string {
+	switch tag {
+	case "INSERT":
+		return fmt.Sprintf("INSERT 0 %d", n)
+	case "SELECT", "UPDATE", "DELETE":
+		return fmt.Sprintf("%s %d", tag, n)
+	default:
+		return tag
+	}
+}
+
+// sqlState returns the Postgres error code for |err|.
+func sqlState(err error) string {
+	var sqlErr *mysql.SQLError
+	if !errors.As(sql.CastSQLError(err), &sqlErr) {
+		return "XX000"
+	}
+	switch sqlErr.Num {
+	case mysql.ERDupEntry:
+		return "23505"
+	case mysql.ERNoSuchTable:
+		return "42P01"
+	case mysql.ERBadFieldError:
+		return "42703"
+	case mysql.ERParseError, mysql.ERSyntaxError:
+		return "42601"
+	case mysql.ERBadDb:
+		return "3D000"
+	case mysql.ERAccessDeniedError, mysql.ERDBAccessDenied, mysql.ERSpecifiedAccessDenied:
+		return "42501"
+	}
+	if len(sqlErr.State) == 5 && sqlErr.State != mysql.SSUnknownSQLState {
+		return sqlErr.State
+	}
+	return "XX000"
+}
+
+func textSchema(name string) sql.Schema {
+	return sql.Schema{{Name: name, Type: types.LongText}}
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgserver
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokParam
+	tokPunct
+	tokSpace
+	tokComment
+)
+
+// token is a lexical element of a Postgres query. Joining the text of all tokens of a query yields the query.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+func (t token) isWord(word string) bool {
+	return t.is(tokWord, word)
+}
+
+func (t token) significant() bool {
+	return t.kind != tokSpace && t.kind != tokComment
+}
+
+// lex splits |query| into tokens. It is only as precise as the rewrites in this package need it to be: string
+// literals, quoted identifiers and comments are kept intact so that their contents are never rewritten.
+func lex(query string) []token {
+	var toks []token
+	r := []rune(query)
+	for i := 0; i < len(r); {
+		start := i
+		c := r[i]
+		var kind tokenKind
+		switch {
+		case unicode.IsSpace(c):
+			for i < len(r) && unicode.IsSpace(r[i]) {
+				i++
+			}
+			kind = tokSpace
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+			kind = tokComment
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			i += 2
+			for i < len(r) && !(r[i] == '*' && i+1 < len(r) && r[i+1] == '/') {
+				i++
+			}
+			i = min(i+2, len(r))
+			kind = tokComment
+		case c == '\'':
+			i = scanQuoted(r, i, '\'', false)
+			kind = tokString
+		case (c == 'E' || c == 'e') && i+1 < len(r) && r[i+1] == '\'':
+			i = scanQuoted(r, i+1, '\'', true)
+			kind = tokString
+		case c == '"':
+			i = scanQuoted(r, i, '"', false)
+			kind = tokQuotedIdent
+		case c == '$' && i+1 < len(r) && unicode.IsDigit(r[i+1]):
+			i++
+			for i < len(r) && unicode.IsDigit(r[i]) {
+				i++
+			}
+			kind = tokParam
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.' || r[i] == 'e' || r[i] == 'E') {
+				i++
+			}
+			kind = tokNumber
+		case c == '_' || c == '@' || unicode.IsLetter(c):
+			for i < len(r) && (r[i] == '_' || r[i] == '@' || r[i] == '$' || unicode.IsLetter(r[i]) || unicode.IsDigit(r[i])) {
+				i++
+			}
+			kind = tokWord
+		case c == ':' && i+1 < len(r) && r[i+1] == ':':
+			i += 2
+			kind = tokPunct
+		default:
+			i++
+			kind = tokPunct
+		}
+		toks = append(toks, token{kind: kind, text: string(r[start:i])})
+	}
+	return toks
+}
+
+// scanQuoted returns the index just past the quoted section starting at |i|, treating doubled quotes as part of the
+// section. Backslash escapes are only part of the section when |escapes| is set, as for E'...' strings; Postgres
+// standard strings treat backslashes literally, so a backslash before a quote must not keep the string open.
+func scanQuoted(r []rune, i int, quote rune, escapes bool) int {
+	i++
+	for i < len(r) {
+		switch {
+		case r[i] == '\\' && escapes:
+			i += 2
+		case r[i] == quote && i+1 < len(r) && r[i+1] == quote:
+			i += 2
+		case r[i] == quote:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(r)
+}
+
+// joinTokens returns the query text of |toks|.
+func joinTokens(toks []token) string {
+	var sb strings.Builder
+	for _, t := range toks {
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+// splitStatements splits a query string into its statements, dropping empty ones.
+func splitStatements(query string) [][]token {
+	var stmts [][]token
+	var cur []token
+	flush := func() {
+		for _, t := range cur {
+			if t.significant() {
+				stmts = append(stmts, cur)
+				break
+			}
+		}
+		cur = nil
+	}
+	for _, t := range lex(query) {
+		if t.is(tokPunct, ";") {
+			flush()
+			continue
+		}
+		cur = append(cur, t)
+	}
+	flush()
+	return stmts
+}
+
+// significantTokens returns the tokens of |toks| that aren't whitespace or comments.
+func significantTokens(toks []token) []token {
+	var sig []token
+	for _, t := range toks {
+		if t.significant() {
+			sig = append(sig, t)
+		}
+	}
+	return sig
+}
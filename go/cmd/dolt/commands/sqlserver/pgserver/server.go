@@ -0,0 +1,172 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgserver implements a listener for the PostgreSQL wire protocol that runs queries against the
+// go-mysql-server engine of a sql-server. It supports the simple and extended query protocols, translates a practical
+// subset of Postgres SQL and system catalog queries, and exposes Dolt stored procedures as functions.
+package pgserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	gms "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
+)
+
+// SessionBuilder creates the SQL session of a new connection for |user|, connecting from |addr|.
+type SessionBuilder func(ctx context.Context, connID uint32, user, addr string) (sql.Session, error)
+
+// Authenticator returns an error if |password| is not valid for |user| connecting from |addr|.
+type Authenticator func(user, password string, addr net.Addr) error
+
+// Config is the configuration of a Server.
+type Config struct {
+	// Address is the host:port to listen on.
+	Address string
+	// Version is the Dolt version reported in the server_version of the listener.
+	Version string
+	// Engine runs the translated queries.
+	Engine *gms.Engine
+	// NextConnectionID allocates the connection IDs of new connections. IDs must be distinct from the IDs of the
+	// other listeners of the server.
+	NextConnectionID func() uint32
+	// NewSession creates the sessions of new connections.
+	NewSession SessionBuilder
+	// Authenticate checks the cleartext password of connecting clients.
+	Authenticate Authenticator
+	// TLSConfig, if set, lets clients upgrade their connections to TLS with an SSLRequest.
+	TLSConfig *tls.Config
+	// RequireSecureTransport refuses to authenticate clients which have not upgraded their connections to TLS.
+	RequireSecureTransport bool
+	Logger                 *logrus.Entry
+}
+
+// Server accepts PostgreSQL wire protocol connections.
+type Server struct {
+	cfg        Config
+	lis        net.Listener
+	procedures map[string]sql.Schema
+
+	mu     sync.Mutex
+	conns  map[*conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer returns a Server listening on the address in |cfg|. Connections are not accepted until Serve is called.
+func NewServer(cfg Config) (*Server, error) {
+	lis, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	procedures := make(map[string]sql.Schema, len(dprocedures.DoltProcedures))
+	for _, p := range dprocedures.DoltProcedures {
+		procedures[strings.ToLower(p.Name)] = p.Schema
+	}
+
+	return &Server{
+		cfg:        cfg,
+		lis:        lis,
+		procedures: procedures,
+		conns:      make(map[*conn]struct{}),
+	}, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Serve accepts connections until the server is closed.
+func (s *Server) Serve() error {
+	for {
+		nc, err := s.lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		c, err := newConn(s, nc, s.cfg.NextConnectionID())
+		if err != nil {
+			// only this connection is lost, so keep accepting others
+			s.logError("unable to set up connection", err)
+			nc.Close()
+			continue
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return nil
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			c.serve()
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) logError(msg string, err error) {
+	if s.cfg.Logger != nil {
+		s.cfg.Logger.Warnf("%s: %v", msg, err)
+	}
+}
+
+// cancelQuery cancels the query of the connection with the ID |processID|, if |secretKey| is its secret key.
+func (s *Server) cancelQuery(processID, secretKey uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		if c.id == processID && c.secretKey == secretKey {
+			c.cancelQuery()
+			return
+		}
+	}
+}
+
+// Close stops accepting connections and closes all open connections, cancelling their queries.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.cancel()
+		c.nc.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.lis.Close()
+	s.wg.Wait()
+	return err
+}
@@ -0,0 +1,553 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// statement is a Postgres statement translated for the SQL engine.
+type statement struct {
+	// query is the translated query to run against the engine. It is empty for statements that are answered by the
+	// listener itself.
+	query string
+	// tag is the command tag reported to the client when the statement completes, without any row count
+	tag string
+	// numParams is the number of $n parameters in the statement
+	numParams int
+	// setParam is the name of the Postgres run-time parameter set by the statement, if it is one the listener handles
+	setParam string
+	setValue string
+	// showParam is the name of the run-time parameter shown by a SHOW statement. If the listener doesn't know the
+	// parameter, |query| is run instead.
+	showParam string
+	// version is true for SELECT version()
+	version bool
+	// procedure is the lower-cased name of the stored procedure called by the statement, if it calls one as a function
+	procedure string
+}
+
+// catalogRelations are the Postgres system catalog relations supported by the listener, as queries against
+// information_schema.
+var catalogRelations = map[string]string{
+	"pg_tables": "SELECT table_schema AS schemaname, table_name AS tablename, '' AS tableowner, NULL AS tablespace, " +
+		"false AS hasindexes, false AS hasrules, false AS hastriggers, false AS rowsecurity " +
+		"FROM information_schema.tables WHERE table_type = 'BASE TABLE'",
+	"pg_views": "SELECT table_schema AS schemaname, table_name AS viewname, '' AS viewowner, view_definition AS definition " +
+		"FROM information_schema.views",
+	"pg_namespace": "SELECT schema_name AS nspname, 0 AS nspowner FROM information_schema.schemata",
+	"pg_database":  "SELECT schema_name AS datname, 'UTF8' AS encoding, false AS datistemplate, true AS datallowconn FROM information_schema.schemata",
+}
+
+// functionRenames maps Postgres functions to their equivalents in the engine.
+var functionRenames = map[string]string{
+	"current_database": "database",
+	"current_schema":   "database",
+	"current_catalog":  "database",
+	"pg_backend_pid":   "connection_id",
+}
+
+// castTypes maps Postgres type names in :: casts to the engine types of equivalent CAST expressions. Types that are
+// not listed have their casts dropped.
+var castTypes = map[string]string{
+	"int2":                        "SIGNED",
+	"smallint":                    "SIGNED",
+	"int":                         "SIGNED",
+	"int4":                        "SIGNED",
+	"integer":                     "SIGNED",
+	"int8":                        "SIGNED",
+	"bigint":                      "SIGNED",
+	"text":                        "CHAR",
+	"varchar":                     "CHAR",
+	"character varying":           "CHAR",
+	"char":                        "CHAR",
+	"character":                   "CHAR",
+	"bpchar":                      "CHAR",
+	"name":                        "CHAR",
+	"float4":                      "DOUBLE",
+	"float8":                      "DOUBLE",
+	"real":                        "DOUBLE",
+	"float":                       "DOUBLE",
+	"double precision":            "DOUBLE",
+	"date":                        "DATE",
+	"time":                        "TIME",
+	"timestamp":                   "DATETIME",
+	"timestamptz":                 "DATETIME",
+	"timestamp with time zone":    "DATETIME",
+	"timestamp without time zone": "DATETIME",
+	"json":                        "JSON",
+	"jsonb":                       "JSON",
+}
+
+// multiWordTypes are the words that may continue a multi-word Postgres type name.
+var multiWordTypes = map[string]bool{
+	"precision": true,
+	"varying":   true,
+	"with":      true,
+	"without":   true,
+	"time":      true,
+	"zone":      true,
+}
+
+// keywords are words that can precede a parenthesized expression without being a function call, and words that end
+// a table reference in a FROM clause.
+var keywords = map[string]bool{
+	"select": true, "from": true, "where": true, "and": true, "or": true, "not": true, "in": true, "on": true,
+	"as": true, "then": true, "else": true, "when": true, "case": true, "values": true, "exists": true, "any": true,
+	"all": true, "join": true, "left": true, "right": true, "inner": true, "outer": true, "cross": true, "full": true,
+	"natural": true, "using": true, "group": true, "order": true, "having": true, "limit": true, "offset": true,
+	"union": true, "intersect": true, "except": true, "window": true, "for": true, "is": true, "like": true,
+	"between": true, "set": true, "into": true, "return": true, "returns": true,
+}
+
+// translate translates a Postgres statement into one the engine can run. |procedures| holds the stored procedures
+// that may be called as functions, keyed by lower-cased name.
+func translate(toks []token, procedures map[string]sql.Schema) (statement, error) {
+	sig := significantTokens(toks)
+	if len(sig) == 0 {
+		return statement{}, fmt.Errorf("empty statement")
+	}
+
+	first := strings.ToLower(sig[0].text)
+	switch first {
+	case "begin":
+		return statement{query: "START TRANSACTION", tag: "BEGIN"}, nil
+	case "start":
+		if len(sig) > 1 && sig[1].isWord("transaction") {
+			return statement{query: "START TRANSACTION", tag: "BEGIN"}, nil
+		}
+	case "end", "commit":
+		return statement{query: "COMMIT", tag: "COMMIT"}, nil
+	case "abort":
+		return statement{query: "ROLLBACK", tag: "ROLLBACK"}, nil
+	case "rollback":
+		if len(sig) == 1 || sig[1].isWord("transaction") || sig[1].isWord("work") {
+			return statement{query: "ROLLBACK", tag: "ROLLBACK"}, nil
+		}
+	case "discard", "deallocate", "reset", "listen", "unlisten", "notify":
+		return statement{tag: strings.ToUpper(first)}, nil
+	case "set":
+		return translateSet(toks, sig)
+	case "show":
+		st, numParams := rewrite(toks)
+		stmt := statement{query: joinTokens(st), tag: "SHOW", numParams: numParams}
+		if len(sig) == 2 && sig[1].kind == tokWord {
+			stmt.showParam = strings.ToLower(sig[1].text)
+		}
+		return stmt, nil
+	case "select":
+		if len(sig) == 4 && sig[1].isWord("version") && sig[2].is(tokPunct, "(") && sig[3].is(tokPunct, ")") {
+			return statement{tag: "SELECT", version: true}, nil
+		}
+	}
+
+	out, numParams := rewrite(toks)
+	if call, name, ok := procedureCall(out, procedures); ok {
+		return statement{query: call, tag: "SELECT", numParams: numParams, procedure: name}, nil
+	}
+
+	return statement{query: joinTokens(out), tag: commandTag(sig), numParams: numParams}, nil
+}
+
+// translateSet translates a SET statement. Run-time parameters that are not system variables of the engine are
+// handled by the listener.
+func translateSet(toks, sig []token) (statement, error) {
+	i := 1
+	if i < len(sig) && (sig[i].isWord("session") || sig[i].isWord("local")) {
+		i++
+	}
+	if i >= len(sig) {
+		return statement{}, fmt.Errorf("syntax error at end of input")
+	}
+
+	switch {
+	case sig[i].isWord("transaction"):
+		out, numParams := rewrite(toks)
+		return statement{query: joinTokens(out), tag: "SET", numParams: numParams}, nil
+	case sig[i].isWord("characteristics"), sig[i].isWord("role"), sig[i].isWord("constraints"):
+		return statement{tag: "SET"}, nil
+	case sig[i].isWord("time") && i+1 < len(sig) && sig[i+1].isWord("zone"):
+		return statement{tag: "SET", setParam: "timezone", setValue: setValue(sig[i+2:])}, nil
+	case sig[i].isWord("names"):
+		return statement{tag: "SET", setParam: "client_encoding", setValue: setValue(sig[i+1:])}, nil
+	}
+
+	name := strings.ToLower(sig[i].text)
+	for i+2 < len(sig) && sig[i+1].is(tokPunct, ".") {
+		name += "." + strings.ToLower(sig[i+2].text)
+		i += 2
+	}
+	if i+1 >= len(sig) || !(sig[i+1].isWord("to") || sig[i+1].is(tokPunct, "=")) {
+		return statement{}, fmt.Errorf("syntax error at or near \"%s\"", sig[i].text)
+	}
+
+	if _, _, ok := sql.SystemVariables.GetGlobal(name); !ok && !strings.HasPrefix(name, "@") {
+		return statement{tag: "SET", setParam: name, setValue: setValue(sig[i+2:])}, nil
+	}
+
+	// Postgres allows TO in place of =, which the engine does not
+	out, numParams := rewrite(toks)
+	for j, t := range out {
+		if t.isWord("to") {
+			out[j] = token{kind: tokPunct, text: "="}
+			break
+		}
+	}
+	return statement{query: joinTokens(out), tag: "SET", numParams: numParams}, nil
+}
+
+// setValue returns the value assigned by a SET statement.
+func setValue(sig []token) string {
+	var vals []string
+	for _, t := range sig {
+		switch t.kind {
+		case tokString:
+			vals = append(vals, unquoteString(t.text))
+		case tokPunct:
+			if t.text != "," {
+				vals = append(vals, t.text)
+			}
+		default:
+			vals = append(vals, t.text)
+		}
+	}
+	return strings.Join(vals, ", ")
+}
+
+// unquoteString returns the contents of a string literal token.
+func unquoteString(s string) string {
+	if len(s) > 0 && (s[0] == 'E' || s[0] == 'e') {
+		s = s[1:]
+	}
+	if len(s) >= 2 {
+		s = s[1 : len(s)-1]
+	}
+	return strings.ReplaceAll(s, "''", "'")
+}
+
+// commandTag returns the command tag for a statement, without any row count.
+func commandTag(sig []token) string {
+	first := strings.ToUpper(sig[0].text)
+	switch first {
+	case "SELECT", "WITH", "VALUES", "TABLE", "CALL", "DESCRIBE", "EXPLAIN":
+		return "SELECT"
+	case "REPLACE":
+		return "INSERT"
+	case "CREATE", "DROP", "ALTER":
+		for _, t := range sig[1:] {
+			switch strings.ToLower(t.text) {
+			case "or", "replace", "unique", "temporary", "temp", "definer", "sql", "security", "=":
+				continue
+			}
+			return first + " " + strings.ToUpper(t.text)
+		}
+	}
+	return first
+}
+
+// rewrite applies the Postgres to MySQL rewrites that don't depend on the kind of statement. It returns the rewritten
+// tokens and the number of $n parameters used.
+func rewrite(toks []token) ([]token, int) {
+	out := make([]token, 0, len(toks))
+	numParams := 0
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.kind {
+		case tokParam:
+			n, _ := strconv.Atoi(t.text[1:])
+			numParams = max(numParams, n)
+			out = append(out, token{kind: tokParam, text: ":v" + t.text[1:]})
+		case tokQuotedIdent:
+			ident := strings.ReplaceAll(t.text[1:len(t.text)-1], `""`, `"`)
+			out = append(out, token{kind: tokQuotedIdent, text: "`" + strings.ReplaceAll(ident, "`", "``") + "`"})
+		case tokString:
+			out = append(out, token{kind: tokString, text: translateString(t.text)})
+		case tokPunct:
+			if t.text != "::" {
+				out = append(out, t)
+				continue
+			}
+			var typeName, typeArgs string
+			typeName, typeArgs, i = parseCastType(toks, i+1)
+			out = applyCast(out, typeName, typeArgs)
+		case tokWord:
+			lower := strings.ToLower(t.text)
+			next := nextSignificant(toks, i+1)
+			isCall := next >= 0 && toks[next].is(tokPunct, "(")
+
+			if lower == "pg_catalog" && next >= 0 && toks[next].is(tokPunct, ".") {
+				// The schema qualifier is dropped and the qualified name handled on the next iteration
+				i = next
+				continue
+			}
+
+			switch {
+			case lower == "ilike":
+				out = append(out, token{kind: tokWord, text: "LIKE"})
+			case isCall && functionRenames[lower] != "":
+				out = append(out, token{kind: tokWord, text: functionRenames[lower]})
+			case !isCall && (lower == "current_schema" || lower == "current_catalog"):
+				out = append(out, token{kind: tokWord, text: "database()"})
+			case catalogRelations[lower] != "" && precededByFromOrJoin(out):
+				out = append(out, token{kind: tokPunct, text: "(" + catalogRelations[lower] + ")"})
+				if !followedByAlias(toks, i+1) {
+					out = append(out, token{kind: tokWord, text: " AS " + lower})
+				}
+			default:
+				out = append(out, t)
+			}
+		default:
+			out = append(out, t)
+		}
+	}
+	return out, numParams
+}
+
+// translateString converts a Postgres string literal to a MySQL one. Postgres standard strings treat backslashes
+// literally, while MySQL interprets them as escapes, and E'...' escape strings are plain MySQL strings.
+func translateString(s string) string {
+	if s[0] == 'E' || s[0] == 'e' {
+		return s[1:]
+	}
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// parseCastType parses the type name of a :: cast starting at |i|. It returns the lower-cased type name without
+// schema qualifier, the text of any type modifier arguments, and the index of the last token of the type.
+func parseCastType(toks []token, i int) (string, string, int) {
+	j := nextSignificant(toks, i)
+	if j < 0 {
+		return "", "", len(toks) - 1
+	}
+	name := strings.ToLower(strings.Trim(toks[j].text, `"`))
+	for {
+		dot := nextSignificant(toks, j+1)
+		if dot < 0 || !toks[dot].is(tokPunct, ".") {
+			break
+		}
+		k := nextSignificant(toks, dot+1)
+		if k < 0 {
+			break
+		}
+		name, j = strings.ToLower(strings.Trim(toks[k].text, `"`)), k
+	}
+	for {
+		k := nextSignificant(toks, j+1)
+		if k < 0 || toks[k].kind != tokWord || !multiWordTypes[strings.ToLower(toks[k].text)] {
+			break
+		}
+		switch name {
+		case "double", "character", "bit", "timestamp", "time", "timestamp with", "timestamp without",
+			"timestamp with time", "timestamp without time", "time with", "time without", "time with time", "time without time":
+		default:
+			return name, "", j
+		}
+		name, j = name+" "+strings.ToLower(toks[k].text), k
+	}
+
+	var args string
+	if k := nextSignificant(toks, j+1); k >= 0 && toks[k].is(tokPunct, "(") {
+		end := matchingParen(toks, k)
+		args = joinTokens(toks[k : end+1])
+		j = end
+	}
+	if k := nextSignificant(toks, j+1); k >= 0 && toks[k].is(tokPunct, "[") {
+		if l := nextSignificant(toks, k+1); l >= 0 && toks[l].is(tokPunct, "]") {
+			j = l
+		}
+	}
+	return name, args, j
+}
+
+// applyCast turns the operand at the end of |out| into a CAST expression to the engine equivalent of |typeName|. If
+// there is no equivalent, or the operand can't be identified, the cast is dropped.
+func applyCast(out []token, typeName, typeArgs string) []token {
+	target, ok := castTypes[typeName]
+	if (typeName == "numeric" || typeName == "decimal") && typeArgs != "" {
+		target, ok = "DECIMAL"+typeArgs, true
+	}
+	if !ok {
+		return out
+	}
+
+	start := operandStart(out)
+	if start < 0 {
+		return out
+	}
+
+	operand := strings.TrimSpace(joinTokens(out[start:]))
+	out = out[:start]
+	return append(out, token{kind: tokWord, text: fmt.Sprintf("CAST(%s AS %s)", operand, target)})
+}
+
+// operandStart returns the index in |out| at which the operand ending at the end of |out| starts, or -1.
+func operandStart(out []token) int {
+	p := prevSignificant(out, len(out))
+	if p < 0 {
+		return -1
+	}
+
+	switch t := out[p]; {
+	case t.is(tokPunct, ")"):
+		q := matchingOpenParen(out, p)
+		if q < 0 {
+			return -1
+		}
+		if r := prevSignificant(out, q); r >= 0 && out[r].kind == tokWord && !keywords[strings.ToLower(out[r].text)] {
+			return qualifiedStart(out, r)
+		}
+		return q
+	case t.kind == tokWord && keywords[strings.ToLower(t.text)]:
+		return -1
+	case t.kind == tokWord, t.kind == tokQuotedIdent:
+		return qualifiedStart(out, p)
+	case t.kind == tokString, t.kind == tokNumber, t.kind == tokParam:
+		return p
+	}
+	return -1
+}
+
+// qualifiedStart returns the start of the possibly qualified name ending at |p|.
+func qualifiedStart(out []token, p int) int {
+	for {
+		dot := prevSignificant(out, p)
+		if dot < 0 || !out[dot].is(tokPunct, ".") {
+			return p
+		}
+		q := prevSignificant(out, dot)
+		if q < 0 || (out[q].kind != tokWord && out[q].kind != tokQuotedIdent) {
+			return p
+		}
+		p = q
+	}
+}
+
+// procedureCall returns a CALL statement and the procedure name if the statement in |toks| is a SELECT of a single
+// stored procedure, as in SELECT dolt_commit('-am', 'message') or SELECT * FROM dolt_commit('-am', 'message').
+func procedureCall(toks []token, procedures map[string]sql.Schema) (string, string, bool) {
+	var idx []int
+	for i, t := range toks {
+		if t.significant() {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) < 4 || !toks[idx[0]].isWord("select") {
+		return "", "", false
+	}
+
+	n := 1
+	if toks[idx[1]].is(tokPunct, "*") {
+		if !toks[idx[2]].isWord("from") {
+			return "", "", false
+		}
+		n = 3
+	}
+	if n+1 >= len(idx) {
+		return "", "", false
+	}
+
+	name := strings.ToLower(strings.Trim(toks[idx[n]].text, "`"))
+	if _, ok := procedures[name]; !ok || !toks[idx[n+1]].is(tokPunct, "(") {
+		return "", "", false
+	}
+	end := matchingParen(toks, idx[n+1])
+	if end >= len(toks) {
+		return "", "", false
+	}
+
+	// Anything after the call other than an alias means this is not a plain procedure call
+	rest := significantTokens(toks[end+1:])
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 1 && rest[0].kind != tokPunct:
+	case len(rest) == 2 && rest[0].isWord("as"):
+	default:
+		return "", "", false
+	}
+
+	return "CALL " + joinTokens(toks[idx[n]:end+1]), name, true
+}
+
+// precededByFromOrJoin returns whether the last significant token of |out| is FROM or JOIN.
+func precededByFromOrJoin(out []token) bool {
+	p := prevSignificant(out, len(out))
+	return p >= 0 && (out[p].isWord("from") || out[p].isWord("join"))
+}
+
+// followedByAlias returns whether the table reference ending before |i| is followed by an alias.
+func followedByAlias(toks []token, i int) bool {
+	n := nextSignificant(toks, i)
+	if n < 0 {
+		return false
+	}
+	t := toks[n]
+	return t.kind == tokQuotedIdent || (t.kind == tokWord && (t.isWord("as") || !keywords[strings.ToLower(t.text)]))
+}
+
+// matchingParen returns the index of the parenthesis closing the one at |open|, or len(toks) if there is none.
+func matchingParen(toks []token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch {
+		case toks[i].is(tokPunct, "("):
+			depth++
+		case toks[i].is(tokPunct, ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks)
+}
+
+// matchingOpenParen returns the index of the parenthesis opening the one at |close|, or -1 if there is none.
+func matchingOpenParen(toks []token, close int) int {
+	depth := 0
+	for i := close; i >= 0; i-- {
+		switch {
+		case toks[i].is(tokPunct, ")"):
+			depth++
+		case toks[i].is(tokPunct, "("):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func nextSignificant(toks []token, i int) int {
+	for ; i < len(toks); i++ {
+		if toks[i].significant() {
+			return i
+		}
+	}
+	return -1
+}
+
+func prevSignificant(toks []token, i int) int {
+	for i--; i >= 0; i-- {
+		if toks[i].significant() {
+			return i
+		}
+	}
+	return -1
+}
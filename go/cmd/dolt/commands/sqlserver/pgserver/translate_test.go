@@ -0,0 +1,175 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgserver
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("SELECT 1; SELECT ';' ; -- comment\n ;INSERT INTO t VALUES (1)")
+	require.Len(t, stmts, 3)
+	assert.Equal(t, "SELECT 1", joinTokens(stmts[0]))
+	assert.Equal(t, " SELECT ';' ", joinTokens(stmts[1]))
+	assert.Equal(t, "INSERT INTO t VALUES (1)", joinTokens(stmts[2]))
+
+	assert.Empty(t, splitStatements(" ; -- nothing"))
+}
+
+func TestTranslate(t *testing.T) {
+	procedures := map[string]sql.Schema{"dolt_commit": nil, "dolt_checkout": nil}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected statement
+	}{
+		{
+			name:     "plain select",
+			query:    "SELECT * FROM t WHERE a = 1",
+			expected: statement{query: "SELECT * FROM t WHERE a = 1", tag: "SELECT"},
+		},
+		{
+			name:     "parameters",
+			query:    "SELECT * FROM t WHERE a = $1 AND b = $2",
+			expected: statement{query: "SELECT * FROM t WHERE a = :v1 AND b = :v2", tag: "SELECT", numParams: 2},
+		},
+		{
+			name:     "quoted identifiers",
+			query:    `SELECT "Name", "a""b" FROM "My Table"`,
+			expected: statement{query: "SELECT `Name`, `a\"b` FROM `My Table`", tag: "SELECT"},
+		},
+		{
+			name:     "strings",
+			query:    `SELECT 'a\b', E'c\nd', 'it''s'`,
+			expected: statement{query: `SELECT 'a\\b', 'c\nd', 'it''s'`, tag: "SELECT"},
+		},
+		{
+			name:     "backslash before a quote in a standard string",
+			query:    `SELECT * FROM t WHERE a = '\' AND b = ' e''\'' OR 1=1 -- '`,
+			expected: statement{query: `SELECT * FROM t WHERE a = '\\' AND b = ' e''\\'' OR 1=1 -- '`, tag: "SELECT"},
+		},
+		{
+			name:     "escaped quote in an escape string",
+			query:    `SELECT E'a\'b', 'c'`,
+			expected: statement{query: `SELECT 'a\'b', 'c'`, tag: "SELECT"},
+		},
+		{
+			name:     "casts",
+			query:    "SELECT $1::int, 'x'::text, a::numeric(10,2), f(a)::float8, t.b::timestamp with time zone, 'c'::regclass",
+			expected: statement{query: "SELECT CAST(:v1 AS SIGNED), CAST('x' AS CHAR), CAST(a AS DECIMAL(10,2)), CAST(f(a) AS DOUBLE), CAST(t.b AS DATETIME), 'c'", tag: "SELECT", numParams: 1},
+		},
+		{
+			name:     "ilike",
+			query:    "SELECT * FROM t WHERE a ILIKE 'x%'",
+			expected: statement{query: "SELECT * FROM t WHERE a LIKE 'x%'", tag: "SELECT"},
+		},
+		{
+			name:     "functions",
+			query:    "SELECT current_database(), current_schema, pg_catalog.pg_backend_pid()",
+			expected: statement{query: "SELECT database(), database(), connection_id()", tag: "SELECT"},
+		},
+		{
+			name:  "catalog relation",
+			query: "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = 'db'",
+			expected: statement{query: "SELECT tablename FROM (" + catalogRelations["pg_tables"] + ") AS pg_tables WHERE schemaname = 'db'",
+				tag: "SELECT"},
+		},
+		{
+			name:     "catalog relation with alias",
+			query:    "SELECT n.nspname FROM pg_namespace n",
+			expected: statement{query: "SELECT n.nspname FROM (" + catalogRelations["pg_namespace"] + ") n", tag: "SELECT"},
+		},
+		{
+			name:     "procedure as function",
+			query:    "SELECT dolt_commit('-am', $1)",
+			expected: statement{query: "CALL dolt_commit('-am', :v1)", tag: "SELECT", numParams: 1, procedure: "dolt_commit"},
+		},
+		{
+			name:     "procedure as table function",
+			query:    "SELECT * FROM DOLT_CHECKOUT('main') AS c",
+			expected: statement{query: "CALL DOLT_CHECKOUT('main')", tag: "SELECT", procedure: "dolt_checkout"},
+		},
+		{
+			name:     "procedure in expression",
+			query:    "SELECT dolt_commit('-am', 'x') = 'abc'",
+			expected: statement{query: "SELECT dolt_commit('-am', 'x') = 'abc'", tag: "SELECT"},
+		},
+		{
+			name:     "begin",
+			query:    "BEGIN ISOLATION LEVEL READ COMMITTED",
+			expected: statement{query: "START TRANSACTION", tag: "BEGIN"},
+		},
+		{
+			name:     "end",
+			query:    "END",
+			expected: statement{query: "COMMIT", tag: "COMMIT"},
+		},
+		{
+			name:     "set postgres parameter",
+			query:    "SET search_path TO public, 'other'",
+			expected: statement{tag: "SET", setParam: "search_path", setValue: "public, other"},
+		},
+		{
+			name:     "set time zone",
+			query:    "SET TIME ZONE 'UTC'",
+			expected: statement{tag: "SET", setParam: "timezone", setValue: "UTC"},
+		},
+		{
+			name:     "set system variable",
+			query:    "SET SESSION autocommit TO 0",
+			expected: statement{query: "SET SESSION autocommit = 0", tag: "SET"},
+		},
+		{
+			name:     "show",
+			query:    "SHOW server_version",
+			expected: statement{query: "SHOW server_version", tag: "SHOW", showParam: "server_version"},
+		},
+		{
+			name:     "version",
+			query:    "select version()",
+			expected: statement{tag: "SELECT", version: true},
+		},
+		{
+			name:     "insert",
+			query:    "INSERT INTO t VALUES ($1)",
+			expected: statement{query: "INSERT INTO t VALUES (:v1)", tag: "INSERT", numParams: 1},
+		},
+		{
+			name:     "create",
+			query:    "CREATE UNIQUE INDEX i ON t (a)",
+			expected: statement{query: "CREATE UNIQUE INDEX i ON t (a)", tag: "CREATE INDEX"},
+		},
+		{
+			name:     "discard",
+			query:    "DISCARD ALL",
+			expected: statement{tag: "DISCARD"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stmts := splitStatements(test.query)
+			require.Len(t, stmts, 1)
+			actual, err := translate(stmts[0], procedures)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
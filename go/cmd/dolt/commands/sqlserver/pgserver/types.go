@@ -0,0 +1,199 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// typeOID returns the OID of the Postgres type that values of |typ| are sent as.
+func typeOID(typ sql.Type) uint32 {
+	switch typ.Type() {
+	case sqltypes.Int8, sqltypes.Uint8, sqltypes.Int16:
+		return pgtype.Int2OID
+	case sqltypes.Uint16, sqltypes.Int24, sqltypes.Uint24, sqltypes.Int32, sqltypes.Year:
+		return pgtype.Int4OID
+	case sqltypes.Uint32, sqltypes.Int64, sqltypes.Bit:
+		return pgtype.Int8OID
+	case sqltypes.Uint64, sqltypes.Decimal:
+		return pgtype.NumericOID
+	case sqltypes.Float32:
+		return pgtype.Float4OID
+	case sqltypes.Float64:
+		return pgtype.Float8OID
+	case sqltypes.Date:
+		return pgtype.DateOID
+	case sqltypes.Datetime, sqltypes.Timestamp:
+		return pgtype.TimestampOID
+	case sqltypes.VarChar, sqltypes.Char:
+		return pgtype.VarcharOID
+	case sqltypes.Blob, sqltypes.VarBinary, sqltypes.Binary:
+		if types.IsText(typ) {
+			return pgtype.TextOID
+		}
+		return pgtype.ByteaOID
+	case sqltypes.TypeJSON:
+		return pgtype.JSONOID
+	default:
+		return pgtype.TextOID
+	}
+}
+
+// typeSize returns the Postgres type size of |oid|, -1 for variable size types.
+func typeSize(oid uint32) int16 {
+	switch oid {
+	case pgtype.Int2OID:
+		return 2
+	case pgtype.Int4OID, pgtype.Float4OID, pgtype.DateOID:
+		return 4
+	case pgtype.Int8OID, pgtype.Float8OID, pgtype.TimestampOID:
+		return 8
+	default:
+		return -1
+	}
+}
+
+// rowDescription returns the row description of |sch| with the result format codes |formats|.
+func rowDescription(sch sql.Schema, formats []int16) *pgproto3.RowDescription {
+	fields := make([]pgproto3.FieldDescription, len(sch))
+	for i, col := range sch {
+		oid := typeOID(col.Type)
+		fields[i] = pgproto3.FieldDescription{
+			Name:         []byte(col.Name),
+			DataTypeOID:  oid,
+			DataTypeSize: typeSize(oid),
+			TypeModifier: -1,
+			Format:       formatCode(formats, i),
+		}
+	}
+	return &pgproto3.RowDescription{Fields: fields}
+}
+
+// formatCode returns the format code of the |i|th column or parameter given the format codes of a Bind message.
+func formatCode(formats []int16, i int) int16 {
+	switch len(formats) {
+	case 0:
+		return pgtype.TextFormatCode
+	case 1:
+		return formats[0]
+	default:
+		return formats[i]
+	}
+}
+
+// encodeRow encodes |row| of |sch| for a DataRow message.
+func encodeRow(ctx *sql.Context, m *pgtype.Map, sch sql.Schema, row sql.Row, formats []int16) ([][]byte, error) {
+	values := make([][]byte, len(row))
+	for i, v := range row {
+		if v == nil {
+			continue
+		}
+
+		oid := typeOID(sch[i].Type)
+		val, err := pgValue(ctx, sch[i].Type, oid, v)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i], err = m.Encode(oid, formatCode(formats, i), val, []byte{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// pgValue converts |v| of |typ| to a value that the pgtype codec of |oid| can encode.
+func pgValue(ctx *sql.Context, typ sql.Type, oid uint32, v interface{}) (interface{}, error) {
+	switch oid {
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID:
+		i, _, err := types.Int64.Convert(v)
+		return i, err
+	case pgtype.Float4OID, pgtype.Float8OID:
+		f, _, err := types.Float64.Convert(v)
+		return f, err
+	case pgtype.NumericOID:
+		d, _, err := types.InternalDecimalType.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+		var n pgtype.Numeric
+		err = n.Scan(d.(decimal.Decimal).String())
+		return n, err
+	case pgtype.DateOID, pgtype.TimestampOID:
+		if t, ok := v.(time.Time); ok {
+			return t, nil
+		}
+	case pgtype.ByteaOID:
+		switch v := v.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	}
+
+	val, err := typ.SQL(ctx, nil, v)
+	if err != nil {
+		return nil, err
+	}
+	return val.ToString(), nil
+}
+
+// bindVariable converts a parameter value of a Bind message to a bind variable of the engine. Text format values are
+// passed as strings, which the engine converts as needed.
+func bindVariable(m *pgtype.Map, oid uint32, format int16, value []byte) (*querypb.BindVariable, error) {
+	if value == nil {
+		return &querypb.BindVariable{Type: sqltypes.Null}, nil
+	}
+	if format == pgtype.TextFormatCode {
+		return &querypb.BindVariable{Type: sqltypes.VarChar, Value: value}, nil
+	}
+
+	dt, ok := m.TypeForOID(oid)
+	if !ok {
+		return nil, fmt.Errorf("binary format is not supported for parameters of type %d", oid)
+	}
+	v, err := dt.Codec.DecodeValue(m, oid, format, value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch val := v.(type) {
+	case int16:
+		v = int64(val)
+	case int32:
+		v = int64(val)
+	case float32:
+		v = float64(val)
+	case time.Time:
+		v = val.Format("2006-01-02 15:04:05.999999")
+	case driver.Valuer:
+		if v, err = val.Value(); err != nil {
+			return nil, err
+		}
+	}
+	return sqltypes.BuildBindVariable(v)
+}
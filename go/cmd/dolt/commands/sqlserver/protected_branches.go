@@ -0,0 +1,275 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// protectedBranchQueryist runs the SQL checks of protected branches. It is implemented by *engine.SqlEngine.
+type protectedBranchQueryist interface {
+	NewLocalContext(ctx context.Context) (*sql.Context, error)
+	Query(ctx *sql.Context, query string) (sql.Schema, sql.RowIter, error)
+}
+
+// protectedBranchesHook returns a remotesrv.PreReceiveHook which rejects pushes that break the rules of |branches|, or
+// nil if there are no protected branches.
+func protectedBranchesHook(queryist protectedBranchQueryist, branches []servercfg.ProtectedBranchConfig) remotesrv.PreReceiveHook {
+	if len(branches) == 0 {
+		return nil
+	}
+
+	return func(ctx context.Context, repoPath string, updates []remotesrv.RefUpdate) error {
+		var sqlCtx *sql.Context
+		var rejections []remotestorage.PushRejection
+		branchPrefix := ref.PrefixForType(ref.BranchRefType)
+		for _, update := range updates {
+			if !strings.HasPrefix(update.Ref, branchPrefix) {
+				continue
+			}
+			branch := strings.TrimPrefix(update.Ref, branchPrefix)
+			rules := matchingProtectedBranches(branches, branch)
+			if len(rules) == 0 {
+				continue
+			}
+
+			if sqlCtx == nil {
+				var err error
+				sqlCtx, err = queryist.NewLocalContext(ctx)
+				if err != nil {
+					return err
+				}
+			}
+			reasons, err := checkProtectedBranchUpdate(sqlCtx, queryist, repoPath, branch, update, rules)
+			if err != nil {
+				return err
+			}
+			for _, reason := range reasons {
+				rejections = append(rejections, remotestorage.PushRejection{Ref: update.Ref, Reason: reason})
+			}
+		}
+
+		if len(rejections) > 0 {
+			return &remotestorage.PushRejectedError{Rejections: rejections}
+		}
+		return nil
+	}
+}
+
+// matchingProtectedBranches returns the rules in |branches| which apply to |branch|.
+func matchingProtectedBranches(branches []servercfg.ProtectedBranchConfig, branch string) []servercfg.ProtectedBranchConfig {
+	var matches []servercfg.ProtectedBranchConfig
+	for _, b := range branches {
+		// patterns are checked when the config is validated
+		if ok, _ := path.Match(b.Branch(), branch); ok {
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
+
+// checkProtectedBranchUpdate returns the reasons |update| of the protected |branch| of the database |repoPath| breaks
+// |rules|, if any.
+func checkProtectedBranchUpdate(ctx *sql.Context, queryist protectedBranchQueryist, repoPath, branch string, update remotesrv.RefUpdate, rules []servercfg.ProtectedBranchConfig) ([]string, error) {
+	allowDeletion, allowForcePush, verifyConstraints := true, true, false
+	var checks []servercfg.ProtectedBranchCheckConfig
+	for _, r := range rules {
+		allowDeletion = allowDeletion && r.AllowDeletion()
+		allowForcePush = allowForcePush && r.AllowForcePush()
+		verifyConstraints = verifyConstraints || r.VerifyConstraints()
+		checks = append(checks, r.Checks()...)
+	}
+
+	if update.New.IsEmpty() {
+		if allowDeletion {
+			return nil, nil
+		}
+		return []string{fmt.Sprintf("protected branch %s cannot be deleted", branch)}, nil
+	}
+
+	ddb, err := protectedBranchDB(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var reasons []string
+	if !allowForcePush && !update.Old.IsEmpty() {
+		ff, err := isFastForward(ctx, ddb, update.Old, update.New)
+		if err != nil {
+			return nil, err
+		}
+		if !ff {
+			reasons = append(reasons, fmt.Sprintf("protected branch %s cannot be force pushed", branch))
+		}
+	}
+	if len(checks) == 0 && !verifyConstraints {
+		return reasons, nil
+	}
+
+	// Every commit the push adds to the branch must pass the checks, not just its head. The checks run against the
+	// pushed commits, which are read from the store before the push's root is committed. They stop at the first commit
+	// which fails them.
+	commits, err := pushedCommits(ctx, ddb, branch, update, rules)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range commits {
+		commitReasons := checkCommit(ctx, queryist, repoPath+dsess.DbRevisionDelimiter+h.String(), checks, verifyConstraints)
+		if len(commitReasons) > 0 {
+			for _, reason := range commitReasons {
+				reasons = append(reasons, fmt.Sprintf("%s at commit %s", reason, h.String()))
+			}
+			break
+		}
+	}
+	return reasons, nil
+}
+
+// checkCommit returns the reasons the commit at |revision| fails |checks| and, if |verifyConstraints| is set, has
+// constraint violations.
+func checkCommit(ctx *sql.Context, queryist protectedBranchQueryist, revision string, checks []servercfg.ProtectedBranchCheckConfig, verifyConstraints bool) []string {
+	var reasons []string
+	for _, check := range checks {
+		rows, err := queryRevision(ctx, queryist, revision, check.Query())
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("check %q failed: %v", check.Name(), err))
+		} else if len(rows) > 0 {
+			reasons = append(reasons, fmt.Sprintf("check %q returned %d rows", check.Name(), len(rows)))
+		}
+	}
+	if verifyConstraints {
+		rows, err := queryRevision(ctx, queryist, revision, "CALL dolt_verify_constraints('--all', '--output-only')")
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("verifying constraints failed: %v", err))
+		} else if len(rows) != 1 || rows[0][0] != int64(0) {
+			reasons = append(reasons, "commit has constraint violations")
+		}
+	}
+	return reasons
+}
+
+// protectedBranchDB returns the DoltDB of the database |repoPath|.
+func protectedBranchDB(ctx *sql.Context, repoPath string) (*doltdb.DoltDB, error) {
+	sess := dsess.DSessFromSess(ctx.Session)
+	db, err := sess.Provider().Database(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	sdb, ok := db.(dsess.SqlDatabase)
+	if !ok {
+		return nil, fmt.Errorf("database %s is not a dolt database", repoPath)
+	}
+	return sdb.DbData().Ddb, nil
+}
+
+// pushedCommits returns the commits |update| adds to the protected |branch|, oldest first. These are the commits of
+// update.New which are not reachable from update.Old. For a new branch, they are the commits which are not reachable
+// from the other branches which |rules| protect, which passed the same checks when they were pushed.
+func pushedCommits(ctx context.Context, ddb *doltdb.DoltDB, branch string, update remotesrv.RefUpdate, rules []servercfg.ProtectedBranchConfig) ([]hash.Hash, error) {
+	var excluded []hash.Hash
+	if !update.Old.IsEmpty() {
+		excluded = append(excluded, update.Old)
+	} else {
+		heads, err := ddb.GetBranchesWithHashes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, head := range heads {
+			if name := head.Ref.GetPath(); name != branch && protectedByAll(rules, name) {
+				excluded = append(excluded, head.Hash)
+			}
+		}
+	}
+
+	itr, err := commitwalk.GetDotDotRevisionsIterator(ctx, ddb, []hash.Hash{update.New}, ddb, excluded, nil)
+	if err != nil {
+		return nil, err
+	}
+	var commits []hash.Hash
+	for {
+		h, _, err := itr.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		commits = append(commits, h)
+	}
+	slices.Reverse(commits)
+	return commits, nil
+}
+
+// protectedByAll returns whether every rule in |rules| applies to |branch|.
+func protectedByAll(rules []servercfg.ProtectedBranchConfig, branch string) bool {
+	return len(matchingProtectedBranches(rules, branch)) == len(rules)
+}
+
+// isFastForward returns whether the commit |new| descends from the commit |old|.
+func isFastForward(ctx *sql.Context, ddb *doltdb.DoltDB, old, new hash.Hash) (bool, error) {
+	oldCm, err := readCommit(ctx, ddb, old)
+	if err != nil {
+		return false, err
+	}
+	newCm, err := readCommit(ctx, ddb, new)
+	if err != nil {
+		return false, err
+	}
+	ancestor, err := doltdb.GetCommitAncestor(ctx, oldCm, newCm)
+	if errors.Is(err, doltdb.ErrNoCommonAncestor) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return ancestor.Addr == old, nil
+}
+
+func readCommit(ctx context.Context, ddb *doltdb.DoltDB, h hash.Hash) (*doltdb.Commit, error) {
+	optCm, err := ddb.ReadCommit(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	cm, ok := optCm.ToCommit()
+	if !ok {
+		return nil, doltdb.ErrGhostCommitEncountered
+	}
+	return cm, nil
+}
+
+// queryRevision runs |query| with |revision| as the current database, returning its rows.
+func queryRevision(ctx *sql.Context, queryist protectedBranchQueryist, revision, query string) ([]sql.Row, error) {
+	ctx.SetCurrentDatabase(revision)
+	_, iter, err := queryist.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowIterToRows(ctx, iter)
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+type noQueries struct{}
+
+func (noQueries) NewLocalContext(ctx context.Context) (*sql.Context, error) {
+	return sql.NewContext(ctx), nil
+}
+
+func (noQueries) Query(ctx *sql.Context, query string) (sql.Schema, sql.RowIter, error) {
+	panic("unexpected query: " + query)
+}
+
+func TestMatchingProtectedBranches(t *testing.T) {
+	main := servercfg.ProtectedBranchYAMLConfig{Branch_: "main"}
+	release := servercfg.ProtectedBranchYAMLConfig{Branch_: "release/*"}
+	all := servercfg.ProtectedBranchYAMLConfig{Branch_: "*"}
+	branches := []servercfg.ProtectedBranchConfig{main, release, all}
+
+	assert.Equal(t, []servercfg.ProtectedBranchConfig{main, all}, matchingProtectedBranches(branches, "main"))
+	assert.Equal(t, []servercfg.ProtectedBranchConfig{release}, matchingProtectedBranches(branches, "release/1.0"))
+	assert.Empty(t, matchingProtectedBranches(branches, "release/1.0/hotfix"))
+	assert.Equal(t, []servercfg.ProtectedBranchConfig{all}, matchingProtectedBranches(branches, "feature"))
+}
+
+func TestProtectedBranchesHookDeletions(t *testing.T) {
+	assert.Nil(t, protectedBranchesHook(noQueries{}, nil))
+
+	allow := true
+	hook := protectedBranchesHook(noQueries{}, []servercfg.ProtectedBranchConfig{
+		servercfg.ProtectedBranchYAMLConfig{Branch_: "main"},
+		servercfg.ProtectedBranchYAMLConfig{Branch_: "scratch/*", AllowDeletion_: &allow},
+	})
+	require.NotNil(t, hook)
+
+	old := hash.Of([]byte("old"))
+	err := hook(context.Background(), "db", []remotesrv.RefUpdate{
+		{Ref: "refs/heads/feature", Old: old},
+		{Ref: "refs/heads/main", Old: old},
+		{Ref: "refs/heads/scratch/tmp", Old: old},
+		{Ref: "refs/tags/main", Old: old},
+	})
+	var rejected *remotestorage.PushRejectedError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, []remotestorage.PushRejection{
+		{Ref: "refs/heads/main", Reason: "protected branch main cannot be deleted"},
+	}, rejected.Rejections)
+
+	err = hook(context.Background(), "db", []remotesrv.RefUpdate{
+		{Ref: "refs/heads/scratch/tmp", Old: old},
+	})
+	assert.NoError(t, err)
+}
@@ -42,6 +42,8 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver/httpapi"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver/pgserver"
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
@@ -109,6 +111,9 @@ func ConfigureServices(
 	}
 	controller.Register(ValidateConfigStep)
 
+	// The MySQL, Postgres and HTTP API listeners share connection IDs
+	connIDs := &connectionIDs{}
+
 	lgr := logrus.StandardLogger()
 	lgr.SetOutput(cli.CliErr)
 	InitLogging := &svcs.AnonService{
@@ -435,6 +440,7 @@ func ConfigureServices(
 				HttpListenAddr:     listenaddr,
 				GrpcListenAddr:     listenaddr,
 				ConcurrencyControl: remotesapi.PushConcurrencyControl_PUSH_CONCURRENCY_CONTROL_ASSERT_WORKING_SET,
+				PreReceiveHook:     protectedBranchesHook(sqlEngine, serverConfig.RemotesapiProtectedBranches()),
 			}
 			var err error
 			args.FS, args.DBCache, err = sqle.RemoteSrvFSAndDBCache(sqlEngine.NewDefaultContext, sqle.DoNotCreateUnknownDatabases)
@@ -530,6 +536,118 @@ func ConfigureServices(
 	}
 	controller.Register(RunClusterRemoteSrv)
 
+	type PostgresListenerService struct {
+		state svcs.ServiceState
+		srv   *pgserver.Server
+	}
+	var pgListener PostgresListenerService
+	RunPostgresListener := &svcs.AnonService{
+		InitF: func(context.Context) (err error) {
+			if serverConfig.PostgresListenerPort() == nil {
+				return nil
+			}
+			pgListener.state.Swap(svcs.ServiceState_Init)
+
+			addr := net.JoinHostPort(serverConfig.PostgresListenerHost(), strconv.Itoa(*serverConfig.PostgresListenerPort()))
+			mysqlDb := sqlEngine.GetUnderlyingEngine().Analyzer.Catalog.MySQLDb
+			tlsConfig, err := servercfg.LoadTLSConfig(serverConfig)
+			if err != nil {
+				return err
+			}
+			pgListener.srv, err = pgserver.NewServer(pgserver.Config{
+				Address:          addr,
+				Version:          version,
+				Engine:           sqlEngine.GetUnderlyingEngine(),
+				NextConnectionID: connIDs.next,
+				NewSession:       newListenerSessionBuilder(sqlEngine, serverConfig, addr),
+				Authenticate: func(user, password string, clientAddr net.Addr) error {
+					return commands.ValidatePasswordFromAddr(mysqlDb, user, password, clientAddr)
+				},
+				TLSConfig:              tlsConfig,
+				RequireSecureTransport: serverConfig.RequireSecureTransport(),
+				Logger:                 logrus.NewEntry(lgr),
+			})
+			if err != nil {
+				lgr.Errorf("error starting postgres listener on %s: %v", addr, err)
+				return err
+			}
+			return nil
+		},
+		RunF: func(context.Context) {
+			if pgListener.state.CompareAndSwap(svcs.ServiceState_Init, svcs.ServiceState_Run) {
+				err := pgListener.srv.Serve()
+				if err != nil {
+					lgr.Errorf("error serving postgres listener: %v", err)
+				}
+			}
+		},
+		StopF: func() error {
+			state := pgListener.state.Swap(svcs.ServiceState_Stopped)
+			if state == svcs.ServiceState_Run || state == svcs.ServiceState_Init {
+				return pgListener.srv.Close()
+			}
+			return nil
+		},
+	}
+	controller.Register(RunPostgresListener)
+
+	type HTTPAPIService struct {
+		state svcs.ServiceState
+		srv   *httpapi.Server
+	}
+	var httpAPI HTTPAPIService
+	RunHTTPAPI := &svcs.AnonService{
+		InitF: func(context.Context) (err error) {
+			if serverConfig.HTTPAPIPort() == nil {
+				return nil
+			}
+			httpAPI.state.Swap(svcs.ServiceState_Init)
+
+			addr := net.JoinHostPort(serverConfig.HTTPAPIHost(), strconv.Itoa(*serverConfig.HTTPAPIPort()))
+			mysqlDb := sqlEngine.GetUnderlyingEngine().Analyzer.Catalog.MySQLDb
+			tlsConfig, err := servercfg.LoadTLSConfig(serverConfig)
+			if err != nil {
+				return err
+			}
+			httpAPI.srv, err = httpapi.NewServer(httpapi.Config{
+				Address:          addr,
+				Engine:           sqlEngine.GetUnderlyingEngine(),
+				NextConnectionID: connIDs.next,
+				NewSession:       newListenerSessionBuilder(sqlEngine, serverConfig, addr),
+				Authenticate: func(user, password string, clientAddr net.Addr) error {
+					return commands.ValidatePasswordFromAddr(mysqlDb, user, password, clientAddr)
+				},
+				ReadOnly:               serverConfig.HTTPAPIReadOnly(),
+				QueryTimeout:           time.Duration(serverConfig.HTTPAPIQueryTimeoutMillis()) * time.Millisecond,
+				MaxRows:                serverConfig.HTTPAPIMaxRows(),
+				TLSConfig:              tlsConfig,
+				RequireSecureTransport: serverConfig.RequireSecureTransport(),
+				Logger:                 logrus.NewEntry(lgr),
+			})
+			if err != nil {
+				lgr.Errorf("error starting http api on %s: %v", addr, err)
+				return err
+			}
+			return nil
+		},
+		RunF: func(context.Context) {
+			if httpAPI.state.CompareAndSwap(svcs.ServiceState_Init, svcs.ServiceState_Run) {
+				err := httpAPI.srv.Serve()
+				if err != nil {
+					lgr.Errorf("error serving http api: %v", err)
+				}
+			}
+		},
+		StopF: func() error {
+			state := httpAPI.state.Swap(svcs.ServiceState_Stopped)
+			if state == svcs.ServiceState_Run || state == svcs.ServiceState_Init {
+				return httpAPI.srv.Close()
+			}
+			return nil
+		},
+	}
+	controller.Register(RunHTTPAPI)
+
 	// We still have some startup to do from this point, and we do not run
 	// the SQL server until we are fully booted. We also want to stop the
 	// SQL server as the first thing we stop. However, if startup fails
@@ -543,24 +661,25 @@ func ConfigureServices(
 	InitSQLServer := &svcs.AnonService{
 		InitF: func(context.Context) (err error) {
 			v, ok := serverConfig.(servercfg.ValidatingServerConfig)
-			if ok && v.GoldenMysqlConnectionString() != "" {
-				mySQLServer, err = server.NewServerWithHandler(
-					serverConf,
-					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
-					metListener,
-					func(h mysql.Handler) (mysql.Handler, error) {
-						return golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
-					},
-				)
-			} else {
-				mySQLServer, err = server.NewServer(
-					serverConf,
-					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
-					metListener,
-				)
-			}
+			mySQLServer, err = server.NewServerWithHandler(
+				serverConf,
+				sqlEngine.GetUnderlyingEngine(),
+				newSessionBuilder(sqlEngine, serverConfig),
+				metListener,
+				func(h mysql.Handler) (mysql.Handler, error) {
+					if ok && v.GoldenMysqlConnectionString() != "" {
+						var err error
+						h, err = golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
+						if err != nil {
+							return nil, err
+						}
+					}
+					return newConnectionIDHandler(h, connIDs, func(c *mysql.Conn) (*sql.Context, error) {
+						// connections close only once the server is running
+						return mySQLServer.SessionManager().NewContext(c)
+					}), nil
+				},
+			)
 			if errors.Is(err, server.UnixSocketInUseError) {
 				lgr.Warn("unix socket set up failed: file already in use: ", serverConf.Socket)
 				err = nil
@@ -847,6 +966,20 @@ func newSessionBuilder(se *engine.SqlEngine, config servercfg.ServerConfig) serv
 	}
 }
 
+// newListenerSessionBuilder returns a session builder for the Postgres and HTTP listeners, which creates sessions the
+// same way as for MySQL connections to the server listening on |addr|.
+func newListenerSessionBuilder(se *engine.SqlEngine, config servercfg.ServerConfig, addr string) func(ctx context.Context, connID uint32, user, clientAddr string) (sql.Session, error) {
+	sessionBuilder := newSessionBuilder(se, config)
+	return func(ctx context.Context, connID uint32, user, clientAddr string) (sql.Session, error) {
+		conn := &mysql.Conn{
+			ConnectionID: connID,
+			User:         user,
+			UserData:     sql.MysqlConnectionUser{User: user, Host: clientAddr},
+		}
+		return sessionBuilder(ctx, conn, addr)
+	}
+}
+
 // getConfigFromServerConfig processes ServerConfig and returns server.Config for sql-server.
 func getConfigFromServerConfig(serverConfig servercfg.ServerConfig) (server.Config, error) {
 	serverConf, err := handleProtocolAndAddress(serverConfig)
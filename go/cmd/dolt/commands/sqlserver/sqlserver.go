@@ -49,6 +49,8 @@ const (
 	socketFlag                  = "socket"
 	remotesapiPortFlag          = "remotesapi-port"
 	remotesapiReadOnlyFlag      = "remotesapi-readonly"
+	postgresPortFlag            = "postgres-port"
+	httpPortFlag                = "http-port"
 	goldenMysqlConn             = "golden"
 	eventSchedulerStatus        = "event-scheduler"
 )
@@ -112,6 +114,22 @@ SUPPORTED CONFIG FILE FIELDS:
 
 {{.EmphasisLeft}}remotesapi.read_only{{.EmphasisRight}}: Boolean flag which disables the ability to perform pushes against the server.
 
+{{.EmphasisLeft}}remotesapi.protected_branches{{.EmphasisRight}}: A list of rules for pushes to the branches matching each rule's {{.EmphasisLeft}}branch{{.EmphasisRight}} pattern, such as {{.EmphasisLeft}}main{{.EmphasisRight}} or {{.EmphasisLeft}}release/*{{.EmphasisRight}}. Pushes may not delete a protected branch unless {{.EmphasisLeft}}allow_deletion{{.EmphasisRight}} is true, or update it to a commit which does not descend from its head unless {{.EmphasisLeft}}allow_force_push{{.EmphasisRight}} is true. Each of the rule's {{.EmphasisLeft}}checks{{.EmphasisRight}}, given as a {{.EmphasisLeft}}name{{.EmphasisRight}} and a {{.EmphasisLeft}}query{{.EmphasisRight}}, must return no rows at the pushed commit, and if {{.EmphasisLeft}}verify_constraints{{.EmphasisRight}} is true the pushed commit must have no constraint violations. Rejected pushes fail, and {{.EmphasisLeft}}dolt push{{.EmphasisRight}} prints the reasons.
+
+{{.EmphasisLeft}}postgres_listener.host{{.EmphasisRight}}: The host address for the PostgreSQL wire protocol listener. Defaults to {{.EmphasisLeft}}listener.host{{.EmphasisRight}}.
+
+{{.EmphasisLeft}}postgres_listener.port{{.EmphasisRight}}: If set, the server also accepts connections using the PostgreSQL wire protocol on this port. Postgres clients can use the simple and extended query protocols, a subset of Postgres SQL and system catalog queries is translated, and Dolt stored procedures can be called as functions, e.g. {{.EmphasisLeft}}SELECT dolt_commit('-am', 'message'){{.EmphasisRight}}.
+
+{{.EmphasisLeft}}http_api.host{{.EmphasisRight}}: The host address for the HTTP query API. Defaults to {{.EmphasisLeft}}listener.host{{.EmphasisRight}}.
+
+{{.EmphasisLeft}}http_api.port{{.EmphasisRight}}: If set, the server accepts queries as {{.EmphasisLeft}}POST /query{{.EmphasisRight}} requests with a JSON body such as {{.EmphasisLeft}}{"query": "SELECT * FROM t WHERE id = ?", "params": [1], "database": "db"}{{.EmphasisRight}} on this port. Requests authenticate with HTTP basic authentication as a SQL user. The {{.EmphasisLeft}}ref{{.EmphasisRight}} URL parameter runs the query against a branch, tag or commit of the database, and results are returned as a JSON document or, with {{.EmphasisLeft}}format=ndjson{{.EmphasisRight}}, streamed as newline delimited JSON.
+
+{{.EmphasisLeft}}http_api.read_only{{.EmphasisRight}}: Boolean flag which makes the HTTP query API reject queries which write.
+
+{{.EmphasisLeft}}http_api.query_timeout_millis{{.EmphasisRight}}: The number of milliseconds a query of the HTTP query API may run before it is canceled. Requests can ask for a shorter timeout with {{.EmphasisLeft}}timeout_ms{{.EmphasisRight}}. Defaults to no limit.
+
+{{.EmphasisLeft}}http_api.max_rows{{.EmphasisRight}}: The largest number of rows of a result returned as a JSON document by the HTTP query API. Larger results are cut off and marked with {{.EmphasisLeft}}"truncated": true{{.EmphasisRight}}; results streamed with {{.EmphasisLeft}}format=ndjson{{.EmphasisRight}} are not limited. Defaults to 10000.
+
 {{.EmphasisLeft}}system_variables{{.EmphasisRight}}: A map of system variable name to desired value for all system variable values to override.
 
 {{.EmphasisLeft}}user_session_vars{{.EmphasisRight}}: A map of user name to a map of session variables to set on connection for each session.
@@ -173,6 +191,8 @@ func (cmd SqlServerCmd) ArgParserWithName(name string) *argparser.ArgParser {
 	ap.SupportsOptionalString(socketFlag, "", "socket file", "Path for the unix socket file. Defaults to '/tmp/mysql.sock'.")
 	ap.SupportsUint(remotesapiPortFlag, "", "remotesapi port", "Sets the port for a server which can expose the databases in this sql-server over remotesapi, so that clients can clone or pull from this server.")
 	ap.SupportsFlag(remotesapiReadOnlyFlag, "", "Disable writes to the sql-server via the push operations. SQL writes are unaffected by this setting.")
+	ap.SupportsUint(postgresPortFlag, "", "postgres port", "Sets the port for an additional listener which accepts connections using the PostgreSQL wire protocol.")
+	ap.SupportsUint(httpPortFlag, "", "http port", "Sets the port for an HTTP API which accepts queries as JSON requests and returns their results as JSON.")
 	ap.SupportsString(goldenMysqlConn, "", "mysql connection string", "Provides a connection string to a MySQL instance to be used to validate query results")
 	ap.SupportsString(eventSchedulerStatus, "", "status", "Determines whether the Event Scheduler is enabled and running on the server. It has one of the following values: 'ON', 'OFF' or 'DISABLED'.")
 	return ap
@@ -27,6 +27,7 @@ import (
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/masking"
 	"github.com/dolthub/dolt/go/libraries/doltcore/mvdata"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table"
@@ -41,9 +42,11 @@ var exportDocs = cli.CommandDocumentationContent{
 	LongDesc: `{{.EmphasisLeft}}dolt table export{{.EmphasisRight}} will export the contents of {{.LessThan}}table{{.GreaterThan}} to {{.LessThan}}|file{{.GreaterThan}}
 
 See the help for {{.EmphasisLeft}}dolt table import{{.EmphasisRight}} as the options are the same.
+
+If the {{.EmphasisLeft}}--masked{{.EmphasisRight}} flag is provided, the masking rules in the {{.EmphasisLeft}}dolt_masking{{.EmphasisRight}} table are applied to the exported rows.
 `,
 	Synopsis: []string{
-		"[-f] [-pk {{.LessThan}}field{{.GreaterThan}}] [-schema {{.LessThan}}file{{.GreaterThan}}] [-map {{.LessThan}}file{{.GreaterThan}}] [-continue] [-file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
+		"[-f] [-pk {{.LessThan}}field{{.GreaterThan}}] [-schema {{.LessThan}}file{{.GreaterThan}}] [-map {{.LessThan}}file{{.GreaterThan}}] [-continue] [-file-type {{.LessThan}}type{{.GreaterThan}}] [--masked] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 	},
 }
 
@@ -52,6 +55,7 @@ type exportOptions struct {
 	force      bool
 	dest       mvdata.DataLocation
 	srcOptions interface{}
+	masked     bool
 }
 
 func (m exportOptions) checkOverwrite(ctx context.Context, root doltdb.RootValue, fs filesys.ReadableFS) (bool, error) {
@@ -148,6 +152,7 @@ func parseExportArgs(ap *argparser.ArgParser, commandStr string, args []string)
 		tableName: tableName,
 		force:     apr.Contains(forceParam),
 		dest:      fileLoc,
+		masked:    apr.Contains(cli.MaskedFlag),
 	}, nil
 }
 
@@ -174,6 +179,7 @@ func (cmd ExportCmd) ArgParser() *argparser.ArgParser {
 	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"file", "The file being output to."})
 	ap.SupportsFlag(forceParam, "f", "If data already exists in the destination, the force flag will allow the target to be overwritten.")
 	ap.SupportsString(fileTypeParam, "", "file_type", "Explicitly define the type of the file if it can't be inferred from the file extension.")
+	ap.SupportsFlag(cli.MaskedFlag, "", "Apply the masking rules in the `dolt_masking` table to the exported rows.")
 	return ap
 }
 
@@ -197,11 +203,20 @@ func (cmd ExportCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return commands.HandleVErrAndExitCode(verr, usage)
 	}
 
+	var rd table.SqlRowReader
 	rd, err := mvdata.NewSqlEngineReader(ctx, dEnv, exOpts.tableName)
 	if err != nil {
 		return commands.HandleVErrAndExitCode(errhand.BuildDError("Error creating reader for %s.", exOpts.SrcName()).AddCause(err).Build(), usage)
 	}
 
+	if exOpts.masked {
+		rules, err := masking.LoadRules(ctx, root)
+		if err != nil {
+			return commands.HandleVErrAndExitCode(errhand.BuildDError("Error reading masking rules.").AddCause(err).Build(), usage)
+		}
+		rd = masking.NewReader(rd, exOpts.tableName, rules)
+	}
+
 	wr, verr := getTableWriter(ctx, root, dEnv, rd.GetSchema(), exOpts)
 	if verr != nil {
 		return commands.HandleVErrAndExitCode(verr, usage)
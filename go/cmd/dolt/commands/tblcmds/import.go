@@ -35,11 +35,13 @@ import (
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/schcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/mvdata"
 	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
@@ -58,6 +60,8 @@ const (
 	updateParam       = "update-table"
 	replaceParam      = "replace-table"
 	appendParam       = "append-table"
+	syncParam         = "sync-table"
+	commitParam       = "commit"
 	tableParam        = "table"
 	fileParam         = "file"
 	schemaParam       = "schema"
@@ -71,6 +75,14 @@ const (
 	ignoreSkippedRows = "ignore-skipped-rows" // alias for quiet
 	disableFkChecks   = "disable-fk-checks"
 	allTextParam      = "all-text"
+	fromMySQLParam    = "from-mysql"
+	inferDecimalParam = "infer-decimal"
+	inferEnumParam    = "infer-enum"
+	dateFormatParam   = "date-format"
+	sampleRowsParam   = "sample-rows"
+
+	// autoPrimaryKey is the value of --pk which proposes a primary key from the data
+	autoPrimaryKey = "auto"
 )
 
 var jsonInputFileHelp = "The expected JSON input file format is:" + `
@@ -99,6 +111,8 @@ If {{.EmphasisLeft}}--append-table | -a{{.EmphasisRight}} is given the operation
 
 If {{.EmphasisLeft}}--replace-table | -r{{.EmphasisRight}} is given the operation will replace {{.LessThan}}table{{.GreaterThan}} with the contents of the file. The table's existing schema will be used, and field names will be used to match file fields with table fields unless a mapping file is specified.
 
+If {{.EmphasisLeft}}--sync-table{{.EmphasisRight}} is given the operation will make {{.LessThan}}table{{.GreaterThan}} match the contents of the file, which is treated as the full desired state of the table. Rows of the file are inserted, or update the rows with the same primary key, and rows of the table whose primary keys are not in the file are deleted. A summary of the rows added, modified and deleted is printed once the table is synced. The table must have a primary key. If {{.EmphasisLeft}}--commit{{.EmphasisRight}} is also given, the table is committed with a message generated from the summary.
+
 If the schema for the existing table does not match the schema for the new file, the import will be aborted by default. To overwrite both the table and the schema, use {{.EmphasisLeft}}-c -f{{.EmphasisRight}}.
 
 A mapping file can be used to map fields between the file being imported and the table being written to. This can be used when creating a new table, or updating or replacing an existing table.
@@ -109,13 +123,19 @@ During import, if there is an error importing any row, the import will be aborte
 		`
 ` + jsonInputFileHelp +
 		`
+If {{.EmphasisLeft}}--from-mysql{{.EmphasisRight}} is given, the rows of the table of the same name are imported from the database of a MySQL server, or of any server which speaks the MySQL protocol, instead of from a file. The value is a data source name such as {{.EmphasisLeft}}user@tcp(host:3306)/db{{.EmphasisRight}}. If it has no password, the password is read from the {{.EmphasisLeft}}DOLT_IMPORT_PASSWORD{{.EmphasisRight}} environment variable. When creating a table, its schema, including its indexes and check constraints, is read from the server's information_schema. Use {{.EmphasisLeft}}dolt import-db{{.EmphasisRight}} to import all the tables of a database.
+
+When the schema of a new table is inferred, {{.EmphasisLeft}}--infer-decimal{{.EmphasisRight}} infers numbers with a fractional component as DECIMAL columns with the precision and scale of the data, rather than as floats, and {{.EmphasisLeft}}--infer-enum{{.EmphasisRight}} infers string columns with a few repeated values as ENUM columns. {{.EmphasisLeft}}--sample-rows{{.EmphasisRight}} infers the schema from the first rows of the file rather than from rows sampled throughout it. If {{.EmphasisLeft}}--pk auto{{.EmphasisRight}} is given, the first column, or combination of up to three columns, whose values are unique in the rows sampled is used as the primary key. {{.EmphasisLeft}}--date-format{{.EmphasisRight}} gives the format, in the syntax of STR_TO_DATE, of dates and times which are not in a format MySQL recognizes, such as {{.EmphasisLeft}}%d/%m/%Y{{.EmphasisRight}}. It is used both to infer date columns and to import the values of date and time columns.
+
 In create, update, and replace scenarios the file's extension is used to infer the type of the file.  If a file does not have the expected extension then the {{.EmphasisLeft}}--file-type{{.EmphasisRight}} parameter should be used to explicitly define the format of the file in one of the supported formats (csv, psv, json, xlsx).  For files separated by a delimiter other than a ',' (type csv) or a '|' (type psv), the --delim parameter can be used to specify a delimiter`,
 
 	Synopsis: []string{
-		"-c [-f] [--pk {{.LessThan}}field{{.GreaterThan}}] [--all-text] [--schema {{.LessThan}}file{{.GreaterThan}}] [--map {{.LessThan}}file{{.GreaterThan}}] [--continue]  [--quiet] [--disable-fk-checks] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
+		"-c [-f] [--pk {{.LessThan}}field{{.GreaterThan}}|auto] [--infer-decimal] [--infer-enum] [--sample-rows {{.LessThan}}n{{.GreaterThan}}] [--date-format {{.LessThan}}format{{.GreaterThan}}] [--all-text] [--schema {{.LessThan}}file{{.GreaterThan}}] [--map {{.LessThan}}file{{.GreaterThan}}] [--continue]  [--quiet] [--disable-fk-checks] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-u [--map {{.LessThan}}file{{.GreaterThan}}] [--continue] [--quiet] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-a [--map {{.LessThan}}file{{.GreaterThan}}] [--continue] [--quiet] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-r [--map {{.LessThan}}file{{.GreaterThan}}] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
+		"--sync-table [--commit] [--map {{.LessThan}}file{{.GreaterThan}}] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
+		"-c|-u|-a|-r [-f] [--continue] [--quiet] [--disable-fk-checks] --from-mysql {{.LessThan}}dsn{{.GreaterThan}} {{.LessThan}}table{{.GreaterThan}}",
 	},
 }
 
@@ -134,6 +154,8 @@ type importOptions struct {
 	quiet           bool
 	disableFkChecks bool
 	allText         bool
+	createStatement string
+	inferOpts       actions.InferenceOptions
 }
 
 func (m importOptions) IsBatched() bool {
@@ -163,6 +185,10 @@ func (m importOptions) FloatThreshold() float64 {
 	return 0.0
 }
 
+func (m importOptions) InferenceOptions() actions.InferenceOptions {
+	return m.inferOpts
+}
+
 func (m importOptions) checkOverwrite(ctx context.Context, root doltdb.RootValue, fs filesys.ReadableFS) (bool, error) {
 	if !m.force && m.operation == mvdata.CreateOp {
 		return root.HasTable(ctx, doltdb.TableName{Name: m.destTableName})
@@ -190,6 +216,9 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 
 	fType, _ := apr.GetValue(fileTypeParam)
 	srcLoc := mvdata.NewDataLocation(path, fType)
+	if dsn, ok := apr.GetValue(fromMySQLParam); ok {
+		srcLoc = mvdata.MySQLDataLocation{DSN: dsn, Table: tableName}
+	}
 	delim, hasDelim := apr.GetValue(delimParam)
 
 	schemaFile, _ := apr.GetValue(schemaParam)
@@ -203,6 +232,19 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 	pks := funcitr.MapStrings(strings.Split(val, ","), strings.TrimSpace)
 	pks = funcitr.FilterStrings(pks, func(s string) bool { return s != "" })
 
+	inferOpts := actions.InferenceOptions{
+		Decimals:   apr.Contains(inferDecimalParam),
+		DateFormat: apr.GetValueOrDefault(dateFormatParam, ""),
+		SampleRows: apr.GetIntOrDefault(sampleRowsParam, 0),
+	}
+	if apr.Contains(inferEnumParam) {
+		inferOpts.EnumMaxValues = actions.DefaultEnumMaxValues
+	}
+	if len(pks) == 1 && strings.EqualFold(pks[0], autoPrimaryKey) {
+		pks = nil
+		inferOpts.PrimaryKey = true
+	}
+
 	mappingFile := apr.GetValueOrDefault(mappingFileParam, "")
 	colMapper, err := rowconv.NameMapperFromFile(mappingFile, dEnv.FS)
 	if err != nil {
@@ -249,6 +291,8 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 		moveOp = mvdata.ReplaceOp
 	case apr.Contains(appendParam):
 		moveOp = mvdata.AppendOp
+	case apr.Contains(syncParam):
+		moveOp = mvdata.SyncOp
 	default:
 		moveOp = mvdata.UpdateOp
 	}
@@ -280,6 +324,7 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 		quiet:           quiet,
 		disableFkChecks: disableFks,
 		allText:         allText,
+		inferOpts:       inferOpts,
 	}, nil
 
 }
@@ -293,12 +338,21 @@ func validateImportArgs(apr *argparser.ArgParseResults) errhand.VerboseError {
 		return errhand.BuildDError("parameters %s and %s are mutually exclusive", schemaParam, primaryKeyParam).Build()
 	}
 
-	if !apr.ContainsAny(createParam, updateParam, replaceParam, appendParam) {
-		return errhand.BuildDError("Must specify exactly one of -c, -u, -a, or -r.").SetPrintUsage().Build()
+	if !apr.ContainsAny(createParam, updateParam, replaceParam, appendParam, syncParam) {
+		return errhand.BuildDError("Must specify exactly one of -c, -u, -a, -r, or --sync-table.").SetPrintUsage().Build()
+	}
+
+	if len(apr.ContainsMany(createParam, updateParam, replaceParam, appendParam, syncParam)) > 1 {
+		return errhand.BuildDError("Must specify exactly one of -c, -u, -a, -r, or --sync-table.").SetPrintUsage().Build()
+	}
+
+	if apr.Contains(syncParam) && apr.Contains(contOnErrParam) {
+		// a skipped row would be deleted from the table
+		return errhand.BuildDError("parameters %s and %s are mutually exclusive", syncParam, contOnErrParam).Build()
 	}
 
-	if len(apr.ContainsMany(createParam, updateParam, replaceParam, appendParam)) > 1 {
-		return errhand.BuildDError("Must specify exactly one of -c, -u, -a, or -r.").SetPrintUsage().Build()
+	if apr.Contains(commitParam) && !apr.Contains(syncParam) {
+		return errhand.BuildDError("fatal: --%s is only supported for sync operations", commitParam).Build()
 	}
 
 	if apr.Contains(schemaParam) && !apr.Contains(createParam) {
@@ -313,11 +367,42 @@ func validateImportArgs(apr *argparser.ArgParseResults) errhand.VerboseError {
 		return errhand.BuildDError("parameters %s and %s are mutually exclusive", allTextParam, schemaParam).Build()
 	}
 
+	for _, param := range []string{inferDecimalParam, inferEnumParam, sampleRowsParam} {
+		if apr.Contains(param) && !apr.Contains(createParam) {
+			return errhand.BuildDError("fatal: --%s is only supported for create operations", param).Build()
+		}
+		for _, other := range []string{schemaParam, allTextParam} {
+			if apr.ContainsAll(param, other) {
+				return errhand.BuildDError("parameters %s and %s are mutually exclusive", param, other).Build()
+			}
+		}
+	}
+
+	if n, ok := apr.GetInt(sampleRowsParam); apr.Contains(sampleRowsParam) && (!ok || n <= 0) {
+		return errhand.BuildDError("error: --%s must be a positive number of rows", sampleRowsParam).Build()
+	}
+
+	if pk, _ := apr.GetValue(primaryKeyParam); strings.EqualFold(strings.TrimSpace(pk), autoPrimaryKey) && apr.Contains(allTextParam) {
+		return errhand.BuildDError("parameters %s and %s=%s are mutually exclusive", allTextParam, primaryKeyParam, autoPrimaryKey).Build()
+	}
+
 	tableName := apr.Arg(0)
 	if err := schcmds.ValidateTableNameForCreate(tableName); err != nil {
 		return err
 	}
 
+	if apr.Contains(fromMySQLParam) {
+		if apr.NArg() != 1 {
+			return errhand.BuildDError("a file can't be imported with --%s", fromMySQLParam).SetPrintUsage().Build()
+		}
+		for _, param := range []string{schemaParam, primaryKeyParam, fileTypeParam, delimParam, allTextParam, dateFormatParam} {
+			if apr.Contains(param) {
+				return errhand.BuildDError("parameters %s and %s are mutually exclusive", fromMySQLParam, param).Build()
+			}
+		}
+		return nil
+	}
+
 	path := ""
 	if apr.NArg() > 1 {
 		path = apr.Arg(1)
@@ -379,6 +464,8 @@ func (cmd ImportCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(updateParam, "u", "Update an existing table with the imported data.")
 	ap.SupportsFlag(appendParam, "a", "Require that the operation will not modify any rows in the table.")
 	ap.SupportsFlag(replaceParam, "r", "Replace existing table with imported data while preserving the original schema.")
+	ap.SupportsFlag(syncParam, "", "Make an existing table match the imported data, deleting the rows which are not in it.")
+	ap.SupportsFlag(commitParam, "", "Commit the table once it is synced, with a generated message.")
 	ap.SupportsFlag(forceParam, "f", "If a create operation is being executed, data already exists in the destination, the force flag will allow the target to be overwritten.")
 	ap.SupportsFlag(contOnErrParam, "", "Continue importing when row import errors are encountered.")
 	ap.SupportsFlag(quiet, "", "Suppress any warning messages about invalid rows when using the --continue flag.")
@@ -386,10 +473,15 @@ func (cmd ImportCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(disableFkChecks, "", "Disables foreign key checks.")
 	ap.SupportsString(schemaParam, "s", "schema_file", "The schema for the output data.")
 	ap.SupportsString(mappingFileParam, "m", "mapping_file", "A file that lays out how fields should be mapped from input data to output data.")
-	ap.SupportsString(primaryKeyParam, "pk", "primary_key", "Explicitly define the name of the field in the schema which should be used as the primary key.")
+	ap.SupportsString(primaryKeyParam, "pk", "primary_key", "Explicitly define the name of the field in the schema which should be used as the primary key, or 'auto' to use the first unique combination of columns.")
 	ap.SupportsString(fileTypeParam, "", "file_type", "Explicitly define the type of the file if it can't be inferred from the file extension.")
 	ap.SupportsString(delimParam, "", "delimiter", "Specify a delimiter for a csv style file with a non-comma delimiter.")
 	ap.SupportsFlag(allTextParam, "", "Treats all fields as text. Can only be used when creating a table.")
+	ap.SupportsString(fromMySQLParam, "", "dsn", "Imports the table from the database of a MySQL server, such as user:password@tcp(host:3306)/db, instead of from a file.")
+	ap.SupportsFlag(inferDecimalParam, "", "Infer numbers with a fractional component as DECIMAL columns rather than floats. Can only be used when creating a table.")
+	ap.SupportsFlag(inferEnumParam, "", "Infer string columns with a few repeated values as ENUM columns. Can only be used when creating a table.")
+	ap.SupportsInt(sampleRowsParam, "", "rows", "Infer the schema from the first rows of the file rather than from rows sampled throughout it. Can only be used when creating a table.")
+	ap.SupportsString(dateFormatParam, "", "format", "The STR_TO_DATE format of dates and times which aren't in a format MySQL recognizes, such as %d/%m/%Y.")
 	return ap
 }
 
@@ -422,6 +514,12 @@ func (cmd ImportCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return commands.HandleVErrAndExitCode(verr, usage)
 	}
 
+	if apr.Contains(commitParam) {
+		if verr = checkNothingStaged(ctx, dEnv, commitParam); verr != nil {
+			return commands.HandleVErrAndExitCode(verr, usage)
+		}
+	}
+
 	root, err := dEnv.WorkingRoot(ctx)
 	if err != nil {
 		verr = errhand.BuildDError("Unable to get the working root value for this data repository.").AddCause(err).Build()
@@ -434,6 +532,10 @@ func (cmd ImportCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return commands.HandleVErrAndExitCode(verr, usage)
 	}
 
+	if defRd, ok := rd.(mvdata.TableDefinitionReader); ok && mvOpts.operation == mvdata.CreateOp {
+		mvOpts.createStatement = defRd.CreateStatement(mvOpts.destTableName)
+	}
+
 	wr, nDMErr := newImportSqlEngineMover(ctx, dEnv, rd.GetSchema(), mvOpts)
 	if nDMErr != nil {
 		verr = newDataMoverErrToVerr(mvOpts, nDMErr)
@@ -444,7 +546,9 @@ func (cmd ImportCmd) Exec(ctx context.Context, commandStr string, args []string,
 	if err != nil {
 		bdr := errhand.BuildDError("\nAn error occurred while moving data")
 		bdr.AddCause(err)
-		bdr.AddDetails("Errors during import can be ignored using '--continue'")
+		if mvOpts.operation != mvdata.SyncOp {
+			bdr.AddDetails("Errors during import can be ignored using '--continue'")
+		}
 		return commands.HandleVErrAndExitCode(bdr.Build(), usage)
 	}
 
@@ -453,6 +557,17 @@ func (cmd ImportCmd) Exec(ctx context.Context, commandStr string, args []string,
 	if skipped > 0 {
 		cli.PrintErrln(color.YellowString("Lines skipped: %d", skipped))
 	}
+
+	if mvOpts.operation == mvdata.SyncOp {
+		stats := wr.Stats()
+		printSyncSummary(stats)
+		if apr.Contains(commitParam) {
+			if err := commitSync(ctx, dEnv, mvOpts, stats); err != nil {
+				verr = errhand.BuildDError("Unable to commit table %s", mvOpts.destTableName).AddCause(err).Build()
+				return commands.HandleVErrAndExitCode(verr, usage)
+			}
+		}
+	}
 	cli.Println(color.CyanString("Import completed successfully."))
 
 	return 0
@@ -468,6 +583,54 @@ func importStatsCB(stats types.AppliedEditStats) {
 	displayStrLen = cli.DeleteAndPrint(displayStrLen, displayStr)
 }
 
+// syncStatsCB reports the progress of a sync, which counts the rows it rewrote unchanged apart from those it modified.
+func syncStatsCB(stats types.AppliedEditStats) {
+	total := stats.Additions + stats.Modifications + stats.SameVal
+	p := message.NewPrinter(message.MatchLanguage("en")) // adds commas
+	displayStr := p.Sprintf("Rows Processed: %d, Additions: %d, Modifications: %d, Unmodified: %d", total, stats.Additions, stats.Modifications, stats.SameVal)
+	displayStrLen = cli.DeleteAndPrint(displayStrLen, displayStr)
+}
+
+// printSyncSummary prints the number of rows of the table which a sync added, modified, deleted and left unchanged.
+func printSyncSummary(stats types.AppliedEditStats) {
+	p := message.NewPrinter(message.MatchLanguage("en")) // adds commas
+	cli.Println(p.Sprintf("%d %s Added", stats.Additions, rowsNoun(stats.Additions)))
+	cli.Println(p.Sprintf("%d %s Modified", stats.Modifications, rowsNoun(stats.Modifications)))
+	cli.Println(p.Sprintf("%d %s Deleted", stats.Deletions, rowsNoun(stats.Deletions)))
+	cli.Println(p.Sprintf("%d %s Unmodified", stats.SameVal, rowsNoun(stats.SameVal)))
+}
+
+// commitSync commits the table synced, with a message which summarizes its changes.
+func commitSync(ctx context.Context, dEnv *env.DoltEnv, mvOpts *importOptions, stats types.AppliedEditStats) error {
+	if stats.Additions+stats.Modifications+stats.Deletions == 0 {
+		cli.Println("No changes to commit.")
+		return nil
+	}
+	msg := fmt.Sprintf("Sync table %s from %s: %d %s added, %d %s modified, %d %s deleted", mvOpts.destTableName, mvOpts.SrcName(),
+		stats.Additions, strings.ToLower(rowsNoun(stats.Additions)),
+		stats.Modifications, strings.ToLower(rowsNoun(stats.Modifications)),
+		stats.Deletions, strings.ToLower(rowsNoun(stats.Deletions)))
+
+	se, dbName, err := engine.NewSqlEngineForEnv(ctx, dEnv)
+	if err != nil {
+		return err
+	}
+	defer se.Close()
+	sqlCtx, err := se.NewLocalContext(ctx)
+	if err != nil {
+		return err
+	}
+	sqlCtx.SetCurrentDatabase(dbName)
+	return commitImportedTables(sqlCtx, se, []string{mvOpts.destTableName}, msg)
+}
+
+func rowsNoun(n int64) string {
+	if n == 1 {
+		return "Row"
+	}
+	return "Rows"
+}
+
 func newImportDataReader(ctx context.Context, root doltdb.RootValue, dEnv *env.DoltEnv, impOpts *importOptions) (table.SqlRowReader, *mvdata.DataMoverCreationError) {
 	var err error
 
@@ -489,7 +652,7 @@ func newImportDataReader(ctx context.Context, root doltdb.RootValue, dEnv *env.D
 }
 
 func newImportSqlEngineMover(ctx context.Context, dEnv *env.DoltEnv, rdSchema schema.Schema, imOpts *importOptions) (*mvdata.SqlEngineTableWriter, *mvdata.DataMoverCreationError) {
-	moveOps := &mvdata.MoverOptions{Force: imOpts.force, TableToWriteTo: imOpts.destTableName, ContinueOnErr: imOpts.contOnErr, Operation: imOpts.operation, DisableFks: imOpts.disableFkChecks}
+	moveOps := &mvdata.MoverOptions{Force: imOpts.force, TableToWriteTo: imOpts.destTableName, ContinueOnErr: imOpts.contOnErr, Operation: imOpts.operation, DisableFks: imOpts.disableFkChecks, CreateStatement: imOpts.createStatement}
 
 	// Returns the schema of the table to be created or the existing schema
 	tableSchema, dmce := getImportSchema(ctx, dEnv, imOpts)
@@ -537,7 +700,11 @@ func newImportSqlEngineMover(ctx context.Context, dEnv *env.DoltEnv, rdSchema sc
 		}
 	}
 
-	mv, err := mvdata.NewSqlEngineTableWriter(ctx, dEnv, tableSchema, rowOperationSchema, moveOps, importStatsCB)
+	statsCB := importStatsCB
+	if imOpts.operation == mvdata.SyncOp {
+		statsCB = syncStatsCB
+	}
+	mv, err := mvdata.NewSqlEngineTableWriter(ctx, dEnv, tableSchema, rowOperationSchema, moveOps, statsCB)
 	if err != nil {
 		return nil, &mvdata.DataMoverCreationError{ErrType: mvdata.CreateWriterErr, Cause: err}
 	}
@@ -675,6 +842,9 @@ func moveRows(
 			if err != nil {
 				return err
 			}
+			if options.inferOpts.DateFormat != "" {
+				normalizeDates(sqlRow, wr.RowOperationSchema(), options.inferOpts.DateFormat)
+			}
 
 			select {
 			case <-ctx.Done():
@@ -714,6 +884,10 @@ func getImportSchema(ctx context.Context, dEnv *env.DoltEnv, impOpts *importOpti
 		}
 		defer rd.Close(ctx)
 
+		if defRd, ok := rd.(mvdata.TableDefinitionReader); ok {
+			return defRd.TableSchema(), nil
+		}
+
 		if impOpts.allText {
 			outSch, err := generateAllTextSchema(rd, impOpts)
 			if err != nil {
@@ -885,6 +1059,21 @@ func NameAndTypeTransform(row sql.Row, rowOperationSchema sql.PrimaryKeySchema,
 	return row, nil
 }
 
+// normalizeDates converts the values of the date and time columns of |row| which are in the STR_TO_DATE format
+// |format| to a format MySQL recognizes. Values which aren't in the format are left as they are.
+func normalizeDates(row sql.Row, rowOperationSchema sql.PrimaryKeySchema, format string) {
+	for i, col := range rowOperationSchema.Schema {
+		if !gmstypes.IsTime(col.Type) && !gmstypes.IsTimespan(col.Type) {
+			continue
+		}
+		if str, ok := row[i].(string); ok {
+			if normalized, ok := actions.NormalizeDate(str, format); ok {
+				row[i] = normalized
+			}
+		}
+	}
+}
+
 // detectAndConvertToBoolean determines whether a column is potentially a boolean and converts it accordingly.
 func detectAndConvertToBoolean(columnVal interface{}, columnType sql.Type) (bool, bool) {
 	switch columnType.Type() {
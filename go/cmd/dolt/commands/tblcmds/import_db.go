@@ -0,0 +1,330 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tblcmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/fatih/color"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/schcmds"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/mvdata"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/mysql"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	commitPerTableParam = "commit-per-table"
+)
+
+var importDbDocs = cli.CommandDocumentationContent{
+	ShortDesc: `Imports the tables of a MySQL database`,
+	LongDesc: `Imports the tables of the database of a MySQL server, or of any server which speaks the MySQL protocol such as {{.EmphasisLeft}}dolt sql-server{{.EmphasisRight}}, into the current database. {{.LessThan}}dsn{{.GreaterThan}} is a data source name which names the database, such as {{.EmphasisLeft}}user@tcp(host:3306)/db{{.EmphasisRight}}. If it has no password, the password is read from the {{.EmphasisLeft}}DOLT_IMPORT_PASSWORD{{.EmphasisRight}} environment variable, which keeps it off the command line.
+
+The schema of each table, including its indexes, check constraints and foreign keys, is read from the server's information_schema. Its rows are streamed in primary key order into the new table through the bulk import path, without an intermediate dump file. Every table is read through a single connection, in a transaction on a consistent snapshot of the database, so that the imported tables are consistent with each other. Foreign keys are added once all the tables are imported. Views, triggers, events and stored procedures are not imported.
+
+All the base tables of the database are imported, unless tables are named. Tables which already exist are not overwritten unless {{.EmphasisLeft}}--force{{.EmphasisRight}} is given.
+
+Imported tables are left in the working set, unless {{.EmphasisLeft}}--commit-per-table{{.EmphasisRight}} is given, in which case each table is committed once it is imported.
+
+During import, if there is an error importing any row, the import will be aborted by default. Use the {{.EmphasisLeft}}--continue{{.EmphasisRight}} flag to continue importing when an error is encountered.`,
+
+	Synopsis: []string{
+		"[-f] [--commit-per-table] [--continue] [--quiet] {{.LessThan}}dsn{{.GreaterThan}} [{{.LessThan}}table{{.GreaterThan}}...]",
+	},
+}
+
+type ImportDbCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd ImportDbCmd) Name() string {
+	return "import-db"
+}
+
+// Description returns a description of the command
+func (cmd ImportDbCmd) Description() string {
+	return "Imports the tables of a database of a MySQL server."
+}
+
+func (cmd ImportDbCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(importDbDocs, ap)
+}
+
+func (cmd ImportDbCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithVariableArgs(cmd.Name())
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"dsn", "The data source name of the server and database to import from, such as user:password@tcp(host:3306)/db."})
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{tableParam, "The tables to import. Defaults to all the tables of the database."})
+	ap.SupportsFlag(forceParam, "f", "Overwrite tables which already exist.")
+	ap.SupportsFlag(commitPerTableParam, "", "Commit each table once it is imported.")
+	ap.SupportsFlag(contOnErrParam, "", "Continue importing when row import errors are encountered.")
+	ap.SupportsFlag(quiet, "", "Suppress any warning messages about invalid rows when using the --continue flag.")
+	return ap
+}
+
+// EventType returns the type of the event to log
+func (cmd ImportDbCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_TABLE_IMPORT
+}
+
+// Exec executes the command
+func (cmd ImportDbCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, importDbDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() == 0 {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("expected a dsn").SetPrintUsage().Build(), usage)
+	}
+
+	dsn := apr.Arg(0)
+	cfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("invalid dsn").AddCause(err).Build(), usage)
+	}
+	if cfg.DBName == "" {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("the dsn must name the database to import, such as user:password@tcp(host:3306)/db").Build(), usage)
+	}
+
+	dEnv, err = commands.MaybeMigrateEnv(ctx, dEnv)
+	if err != nil {
+		verr := errhand.BuildDError("could not load manifest for gc").AddCause(err).Build()
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	verr := importDb(ctx, dEnv, dsn, apr.Args[1:], apr)
+	return commands.HandleVErrAndExitCode(verr, usage)
+}
+
+func importDb(ctx context.Context, dEnv *env.DoltEnv, dsn string, tables []string, apr *argparser.ArgParseResults) errhand.VerboseError {
+	source := mvdata.RedactDSN(dsn)
+	// Every table is read through one connection, in a transaction on a consistent snapshot of the database, so that
+	// the imported tables are consistent with each other
+	snapshot, err := mysql.OpenSnapshot(ctx, dsn)
+	if err != nil {
+		return errhand.BuildDError("Unable to connect to %s", source).AddCause(err).Build()
+	}
+	defer snapshot.Close()
+
+	if len(tables) == 0 {
+		tables, err = mysql.ListTables(ctx, snapshot)
+		if err != nil {
+			return errhand.BuildDError("Unable to list the tables of %s", source).AddCause(err).Build()
+		}
+		if len(tables) == 0 {
+			return errhand.BuildDError("%s has no tables to import", source).Build()
+		}
+	}
+
+	force := apr.Contains(forceParam)
+	root, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return errhand.BuildDError("Unable to get the working root value for this data repository.").AddCause(err).Build()
+	}
+
+	defs := make([]*mysql.TableDef, len(tables))
+	for i, tableName := range tables {
+		if verr := schcmds.ValidateTableNameForCreate(tableName); verr != nil {
+			return verr
+		}
+		if !force {
+			exists, err := root.HasTable(ctx, doltdb.TableName{Name: tableName})
+			if err != nil {
+				return errhand.VerboseErrorFromError(err)
+			} else if exists {
+				return errhand.BuildDError("table %s already exists. Use -f to overwrite.", tableName).Build()
+			}
+		}
+		defs[i], err = mysql.ReadTableDef(ctx, snapshot, tableName)
+		if err != nil {
+			return errhand.BuildDError("Unable to read the definition of table %s", tableName).AddCause(err).Build()
+		}
+	}
+
+	// Every table is written by one engine through the bulk import path
+	se, sqlCtx, err := mvdata.NewBulkImportEngine(ctx, dEnv)
+	if err != nil {
+		return errhand.BuildDError("Unable to create the import engine").AddCause(err).Build()
+	}
+	defer se.Close()
+
+	commitPerTable := apr.Contains(commitPerTableParam)
+	if commitPerTable {
+		if verr := checkNothingStaged(ctx, dEnv, commitPerTableParam); verr != nil {
+			return verr
+		}
+	}
+	for i, tableName := range tables {
+		cli.Println(color.CyanString("Importing table %s", tableName))
+		mvOpts := &importOptions{
+			operation:       mvdata.CreateOp,
+			destTableName:   tableName,
+			contOnErr:       apr.Contains(contOnErrParam),
+			force:           force,
+			quiet:           apr.Contains(quiet),
+			disableFkChecks: true,
+		}
+		if verr := importTable(ctx, se, sqlCtx, root, snapshot, defs[i], mvOpts); verr != nil {
+			return verr
+		}
+
+		if commitPerTable {
+			msg := fmt.Sprintf("Import table %s from %s", tableName, source)
+			if err := commitImportedTables(sqlCtx, se, []string{tableName}, msg); err != nil {
+				return errhand.BuildDError("Unable to commit table %s", tableName).AddCause(err).Build()
+			}
+		}
+	}
+
+	// Foreign keys are added once every table is loaded, so that tables can be loaded in any order. Their checks,
+	// which the import disabled, are enabled again so that the rows are checked when the keys are added.
+	var fkTables []string
+	fkStmts := []string{"SET FOREIGN_KEY_CHECKS = 1"}
+	for i, def := range defs {
+		if stmts := def.AddForeignKeyStatements(tables[i]); len(stmts) > 0 {
+			fkTables = append(fkTables, tables[i])
+			fkStmts = append(fkStmts, stmts...)
+		}
+	}
+	if len(fkTables) > 0 {
+		if err := runImportStatements(sqlCtx, se, fkStmts); err != nil {
+			return errhand.BuildDError("Unable to add foreign keys").AddCause(err).Build()
+		}
+		if commitPerTable {
+			msg := fmt.Sprintf("Add foreign keys of the tables imported from %s", source)
+			if err := commitImportedTables(sqlCtx, se, fkTables, msg); err != nil {
+				return errhand.BuildDError("Unable to commit foreign keys").AddCause(err).Build()
+			}
+		}
+	}
+
+	cli.Println(color.CyanString("Imported %d tables from %s.", len(tables), source))
+	return nil
+}
+
+// importTable imports the table |def|, whose rows are read through |snapshot|, with the bulk import engine |se|.
+func importTable(ctx context.Context, se *engine.SqlEngine, sqlCtx *sql.Context, root doltdb.RootValue, snapshot *mysql.Snapshot, def *mysql.TableDef, mvOpts *importOptions) errhand.VerboseError {
+	rd, err := mvdata.NewMySQLTableReader(sqlCtx, se, root, snapshot, nil, def, mvOpts.destTableName)
+	if err != nil {
+		return errhand.BuildDError("Unable to read the definition of table %s", mvOpts.destTableName).AddCause(err).Build()
+	}
+	defer rd.Close(ctx)
+
+	moveOps := &mvdata.MoverOptions{
+		Force:           mvOpts.force,
+		TableToWriteTo:  mvOpts.destTableName,
+		ContinueOnErr:   mvOpts.contOnErr,
+		Operation:       mvOpts.operation,
+		DisableFks:      mvOpts.disableFkChecks,
+		CreateStatement: rd.CreateStatement(mvOpts.destTableName),
+	}
+	wr, err := mvdata.NewSqlEngineTableWriterForEngine(se, sqlCtx, rd.TableSchema(), rd.GetSchema(), moveOps, importStatsCB)
+	if err != nil {
+		return newDataMoverErrToVerr(mvOpts, &mvdata.DataMoverCreationError{ErrType: mvdata.CreateWriterErr, Cause: err})
+	}
+
+	skipped, err := move(ctx, rd, wr, mvOpts)
+	cli.PrintErrln()
+	if err != nil {
+		bdr := errhand.BuildDError("An error occurred while importing table %s", mvOpts.destTableName)
+		bdr.AddCause(err)
+		bdr.AddDetails("Errors during import can be ignored using '--continue'")
+		return bdr.Build()
+	}
+	if skipped > 0 {
+		cli.PrintErrln(color.YellowString("Rows skipped: %d", skipped))
+	}
+	return nil
+}
+
+// runImportStatements runs the statements given in a transaction of the import engine |se|.
+func runImportStatements(sqlCtx *sql.Context, se *engine.SqlEngine, stmts []string) error {
+	stmts = append(append([]string{"START TRANSACTION"}, stmts...), "COMMIT")
+	for _, stmt := range stmts {
+		if _, err := commands.GetRowsForSql(se, sqlCtx, stmt); err != nil {
+			return fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// checkNothingStaged returns an error if the database has staged changes, which would be committed along with the
+// tables imported with the commit option |commitFlag|.
+func checkNothingStaged(ctx context.Context, dEnv *env.DoltEnv, commitFlag string) errhand.VerboseError {
+	roots, err := dEnv.Roots(ctx)
+	if err != nil {
+		return errhand.BuildDError("Unable to get the roots of this data repository.").AddCause(err).Build()
+	}
+	headHash, err := roots.Head.HashOf()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	stagedHash, err := roots.Staged.HashOf()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if headHash != stagedHash {
+		return errhand.BuildDError("there are staged changes which --%s would commit along with the imported tables", commitFlag).
+			AddDetails("Commit the staged changes, or unstage them with 'dolt reset', before importing.").Build()
+	}
+	return nil
+}
+
+// commitImportedTables stages and commits the tables given. Changes to other tables which were staged are never
+// committed along with them, so the commit fails if there are any.
+func commitImportedTables(sqlCtx *sql.Context, se *engine.SqlEngine, tables []string, msg string) error {
+	rows, err := commands.GetRowsForSql(se, sqlCtx, "SELECT table_name FROM dolt_status WHERE staged = true")
+	if err != nil {
+		return err
+	}
+	imported := make(map[string]struct{}, len(tables))
+	for _, t := range tables {
+		imported[strings.ToLower(t)] = struct{}{}
+	}
+	for _, row := range rows {
+		name := row[0].(string)
+		if _, ok := imported[strings.ToLower(name)]; !ok {
+			return fmt.Errorf("table %s has staged changes which would be committed along with the imported tables", name)
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tables)), ", ")
+	args := make([]interface{}, len(tables))
+	for i, t := range tables {
+		args[i] = t
+	}
+	add, err := dbr.InterpolateForDialect("CALL DOLT_ADD("+placeholders+")", args, dialect.MySQL)
+	if err != nil {
+		return err
+	}
+	commit, err := dbr.InterpolateForDialect("CALL DOLT_COMMIT('-m', ?)", []interface{}{msg}, dialect.MySQL)
+	if err != nil {
+		return err
+	}
+	return runImportStatements(sqlCtx, se, []string{add, commit})
+}
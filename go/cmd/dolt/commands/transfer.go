@@ -0,0 +1,250 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/libraries/utils/streammux"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const transferReadOnlyFlag = "read-only"
+
+var transferDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Serve a database to a remote client over stdin and stdout",
+	LongDesc: `{{.EmphasisLeft}}dolt transfer{{.EmphasisRight}} is run on the remote host by the {{.EmphasisLeft}}ssh://{{.EmphasisRight}} remote transport, and is not usually run by hand. It serves the remotesapi chunk store protocol for the database at {{.LessThan}}path{{.GreaterThan}} on its stdin and stdout until its stdin is closed.
+
+If {{.LessThan}}path{{.GreaterThan}} is a Dolt repository, its database is served, and pushes to its checked out branch are only accepted when the branch's working set is clean. Otherwise {{.LessThan}}path{{.GreaterThan}} holds the database directly, like a {{.EmphasisLeft}}file://{{.EmphasisRight}} remote, and is created when it is first pushed to.
+
+A remote at {{.EmphasisLeft}}ssh://[user@]host[:port]/path{{.EmphasisRight}} runs {{.EmphasisLeft}}ssh [-p port] [user@]host dolt transfer /path{{.EmphasisRight}}. A path starting with {{.EmphasisLeft}}/~{{.EmphasisRight}} is relative to a home directory on the remote host. The ssh command can be changed with the {{.EmphasisLeft}}DOLT_SSH{{.EmphasisRight}} environment variable, and the dolt executable run on the remote host with {{.EmphasisLeft}}DOLT_SSH_EXEC_PATH{{.EmphasisRight}}.`,
+	Synopsis: []string{
+		"[--read-only] {{.LessThan}}path{{.GreaterThan}}",
+	},
+}
+
+type TransferCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd TransferCmd) Name() string {
+	return dbfactory.TransferCommand
+}
+
+// Description returns a description of the command
+func (cmd TransferCmd) Description() string {
+	return transferDocs.ShortDesc
+}
+
+// RequiresRepo should return false if this interface is implemented, and the command does not have the requirement
+// that it be run from within a data repository directory
+func (cmd TransferCmd) RequiresRepo() bool {
+	return false
+}
+
+// EventType returns the type of the event to log
+func (cmd TransferCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_TYPE_UNSPECIFIED
+}
+
+func (cmd TransferCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(transferDocs, ap)
+}
+
+func (cmd TransferCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 1)
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"path", "The path of the database to serve."})
+	ap.SupportsFlag(transferReadOnlyFlag, "", "Reject pushes to the database.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd TransferCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, transferDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+
+	// Stdout carries the protocol, so anything else which would be printed goes to stderr instead
+	out := cli.OutStream
+	cli.CliOut = cli.CliErr
+	logrus.SetOutput(cli.CliErr)
+	logrus.SetLevel(logrus.WarnLevel)
+
+	err := serveTransfer(ctx, apr.Arg(0), apr.Contains(transferReadOnlyFlag), transferConn{ReadCloser: cli.InStream, out: out})
+	if err != nil {
+		cli.PrintErrln(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// serveTransfer serves the database at |path| on |conn| until it is closed by the client.
+func serveTransfer(ctx context.Context, path string, readOnly bool, conn io.ReadWriteCloser) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	// A repository serves its own database, anything else is a database like those of file:// remotes
+	dbCache := &transferDBCache{path: path}
+	created := false
+	concurrencyControl := remotesapi.PushConcurrencyControl_PUSH_CONCURRENCY_CONTROL_IGNORE_WORKING_SET
+	if info, err := os.Stat(filepath.Join(path, dbfactory.DoltDataDir)); err == nil && info.IsDir() {
+		dbCache.dataDir = filepath.Join(path, dbfactory.DoltDataDir)
+		dbCache.params = map[string]interface{}{dbfactory.ChunkJournalParam: struct{}{}}
+		concurrencyControl = remotesapi.PushConcurrencyControl_PUSH_CONCURRENCY_CONTROL_ASSERT_WORKING_SET
+	} else if readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return errhand.BuildDError("error: no database at '%s'", path).AddCause(err).Build()
+		}
+		dbCache.dataDir = path
+	} else {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			created = true
+		}
+		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+			return err
+		}
+		dbCache.dataDir = path
+	}
+
+	// Table file URLs are relative to the root of the file system, and must include the directory of the store
+	fs, err := filesys.LocalFilesysWithWorkingDir(filepath.Dir(dbCache.dataDir))
+	if err != nil {
+		return err
+	}
+
+	server, err := remotesrv.NewServer(remotesrv.ServerArgs{
+		Logger:             logrus.NewEntry(logrus.StandardLogger()),
+		FS:                 fs,
+		DBCache:            dbCache,
+		ReadOnly:           readOnly,
+		ConcurrencyControl: concurrencyControl,
+	})
+	if err != nil {
+		return err
+	}
+
+	sess := streammux.NewSession(conn, false)
+	go server.Serve(remotesrv.SingleListener(sess))
+	select {
+	case <-sess.Done():
+	case <-ctx.Done():
+		sess.Close()
+	}
+	server.GracefulStop()
+
+	// Fetching from a database which does not exist must not leave an empty one behind
+	if created {
+		return dbCache.removeIfEmpty(ctx)
+	}
+	return nil
+}
+
+// transferDBCache is a remotesrv.DBCache which serves the single database of `dolt transfer`, whatever the path of
+// the request. The database is opened when it is first requested, so that a new database gets the format of the
+// client which pushes to it.
+type transferDBCache struct {
+	path    string
+	dataDir string
+	params  map[string]interface{}
+
+	mu    sync.Mutex
+	store remotesrv.RemoteSrvStore
+}
+
+var _ remotesrv.DBCache = (*transferDBCache)(nil)
+
+func (c *transferDBCache) Get(ctx context.Context, _, nbfVerStr string) (remotesrv.RemoteSrvStore, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store != nil {
+		return c.store, nil
+	}
+
+	nbf, err := types.GetFormatForVersionString(nbfVerStr)
+	if err != nil {
+		return nil, err
+	}
+	db, _, _, err := dbfactory.FileFactory{}.CreateDB(ctx, nbf, c.url(), c.params)
+	if err != nil {
+		return nil, err
+	}
+	store, ok := datas.ChunkStoreFromDatabase(db).(remotesrv.RemoteSrvStore)
+	if !ok {
+		return nil, fmt.Errorf("database at '%s' cannot be served", c.path)
+	}
+	c.store = store
+	return store, nil
+}
+
+// removeIfEmpty removes the database if nothing was pushed to it.
+func (c *transferDBCache) removeIfEmpty(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store != nil {
+		root, err := c.store.Root(ctx)
+		if err != nil || !root.IsEmpty() {
+			return err
+		}
+		if err = c.store.Close(); err != nil {
+			return err
+		}
+		if err = dbfactory.DeleteFromSingletonCache(c.url().Path); err != nil {
+			return err
+		}
+		c.store = nil
+	}
+	return os.RemoveAll(c.dataDir)
+}
+
+func (c *transferDBCache) url() *url.URL {
+	return &url.URL{Scheme: dbfactory.FileScheme, Path: filepath.ToSlash(c.dataDir)}
+}
+
+// transferConn is the connection to the client of `dolt transfer` through stdin and stdout.
+type transferConn struct {
+	io.ReadCloser
+	out io.Writer
+}
+
+func (c transferConn) Write(p []byte) (int, error) {
+	return c.out.Write(p)
+}
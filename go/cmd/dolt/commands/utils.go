@@ -448,7 +448,12 @@ func getTimestampColAsUint64(col interface{}) (uint64, error) {
 func passwordValidate(rawDb *mysql_db.MySQLDb, salt []byte, user string, authResponse []byte) error {
 	// The port is meaningless here. It's going to be stripped in the ValidateHash function
 	addr, _ := net.ResolveTCPAddr("tcp", "localhost:3306")
+	return passwordValidateFromAddr(rawDb, salt, user, authResponse, addr)
+}
 
+// passwordValidateFromAddr validates the password for the given user connecting from |addr|, which selects the host
+// part of the account the user is authenticated as.
+func passwordValidateFromAddr(rawDb *mysql_db.MySQLDb, salt []byte, user string, authResponse []byte, addr net.Addr) error {
 	authenticated, err := rawDb.ValidateHash(salt, user, authResponse, addr)
 	if err != nil {
 		return err
@@ -504,6 +509,19 @@ func ValidatePasswordWithAuthResponse(rawDb *mysql_db.MySQLDb, user, password st
 	return passwordValidate(rawDb, salt, user, authResponse)
 }
 
+// ValidatePasswordFromAddr returns nil if |password| is valid for |user| connecting from |addr|. Unlike
+// ValidatePasswordWithAuthResponse, the account is matched against the host of the client's address rather than
+// localhost, so that it can be used to authenticate remote clients.
+func ValidatePasswordFromAddr(rawDb *mysql_db.MySQLDb, user, password string, addr net.Addr) error {
+	salt, err := rawDb.Salt()
+	if err != nil {
+		return err
+	}
+
+	authResponse := buildAuthResponse(salt, password)
+	return passwordValidateFromAddr(rawDb, salt, user, authResponse, addr)
+}
+
 // GetDoltStatus retrieves the status of the current working set of changes in the working set, and returns two
 // lists of modified tables: staged and unstaged. If both lists are empty, there are no changes in the working set.
 // The list of unstaged tables does not include tables that are ignored, as configured by the dolt_ignore table.
@@ -0,0 +1,43 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"net"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql/mysql_db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePasswordFromAddr(t *testing.T) {
+	db := mysql_db.CreateEmptyMySQLDb()
+	ed := db.Editor()
+	db.AddSuperUser(ed, "local", "localhost", "pass")
+	db.AddSuperUser(ed, "remote", "%", "pass")
+	ed.Close()
+
+	loopback := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5432}
+	remote := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5432}
+
+	require.NoError(t, ValidatePasswordFromAddr(db, "local", "pass", loopback))
+	assert.Error(t, ValidatePasswordFromAddr(db, "local", "pass", remote))
+	assert.Error(t, ValidatePasswordFromAddr(db, "local", "wrong", loopback))
+	require.NoError(t, ValidatePasswordFromAddr(db, "remote", "pass", remote))
+
+	// ValidatePasswordWithAuthResponse always authenticates as a client on localhost
+	require.NoError(t, ValidatePasswordWithAuthResponse(db, "local", "pass"))
+}
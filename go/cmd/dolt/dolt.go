@@ -93,6 +93,7 @@ var doltSubCommands = []cli.Command{
 	commands.FetchCmd{},
 	commands.PullCmd{},
 	commands.PushCmd{},
+	commands.TransferCmd{},
 	commands.ConfigCmd{},
 	commands.RemoteCmd{},
 	commands.BackupCmd{},
@@ -101,6 +102,7 @@ var doltSubCommands = []cli.Command{
 	commands.LsCmd{},
 	schcmds.Commands,
 	tblcmds.Commands,
+	tblcmds.ImportDbCmd{},
 	commands.TagCmd{},
 	commands.BlameCmd{},
 	cvcmds.Commands,
@@ -130,6 +132,7 @@ var commandsWithoutCliCtx = []cli.Command{
 	admin.Commands,
 	sqlserver.SqlServerCmd{VersionStr: doltversion.Version},
 	commands.CloneCmd{},
+	commands.TransferCmd{},
 	commands.BackupCmd{},
 	commands.LoginCmd{},
 	credcmds.Commands,
@@ -154,6 +157,7 @@ var commandsWithoutCliCtx = []cli.Command{
 var commandsWithoutGlobalArgSupport = []cli.Command{
 	commands.InitCmd{},
 	commands.CloneCmd{},
+	commands.TransferCmd{},
 	docscmds.Commands,
 	commands.MigrateCmd{},
 	commands.ReadTablesCmd{},
@@ -166,6 +170,7 @@ var commandsWithoutGlobalArgSupport = []cli.Command{
 
 // commands that do not need write access for the current directory
 var commandsWithoutCurrentDirWrites = []cli.Command{
+	commands.TransferCmd{},
 	commands.VersionCmd{VersionStr: doltversion.Version},
 	commands.ConfigCmd{},
 	commands.ProfileCmd{},
@@ -46,6 +46,22 @@ type ReplicationServiceClient interface {
 	UpdateUsersAndGrants(ctx context.Context, in *UpdateUsersAndGrantsRequest, opts ...grpc.CallOption) (*UpdateUsersAndGrantsResponse, error)
 	UpdateBranchControl(ctx context.Context, in *UpdateBranchControlRequest, opts ...grpc.CallOption) (*UpdateBranchControlResponse, error)
 	DropDatabase(ctx context.Context, in *DropDatabaseRequest, opts ...grpc.CallOption) (*DropDatabaseResponse, error)
+	// When automatic failover is configured, a primary periodically calls this
+	// method on each of its standbys. A standby acknowledges the heartbeat if
+	// the primary's epoch is at least as high as any epoch it has seen or voted
+	// in. A primary keeps its lease, and remains primary, as long as a majority
+	// of the cluster acknowledges its heartbeats.
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// When automatic failover is configured, a standby which has not received a
+	// heartbeat from a primary within the lease duration calls this method on
+	// the other members of the cluster to become primary at a new epoch. It
+	// becomes primary if a majority of the cluster grants it a vote.
+	RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error)
+	// A primary calls this method on a standby after it successfully
+	// replicates a root of a database to the standby, or confirms that the
+	// standby still has its latest root. Standbys use it to serve reads which
+	// wait for a root the primary handed out with dolt_cluster_root().
+	UpdateReplicationProgress(ctx context.Context, in *UpdateReplicationProgressRequest, opts ...grpc.CallOption) (*UpdateReplicationProgressResponse, error)
 }
 
 type replicationServiceClient struct {
@@ -83,6 +99,33 @@ func (c *replicationServiceClient) DropDatabase(ctx context.Context, in *DropDat
 	return out, nil
 }
 
+func (c *replicationServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.replicationapi.v1alpha1.ReplicationService/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicationServiceClient) RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error) {
+	out := new(RequestVoteResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicationServiceClient) UpdateReplicationProgress(ctx context.Context, in *UpdateReplicationProgressRequest, opts ...grpc.CallOption) (*UpdateReplicationProgressResponse, error) {
+	out := new(UpdateReplicationProgressResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.replicationapi.v1alpha1.ReplicationService/UpdateReplicationProgress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReplicationServiceServer is the server API for ReplicationService service.
 // All implementations must embed UnimplementedReplicationServiceServer
 // for forward compatibility
@@ -96,6 +139,22 @@ type ReplicationServiceServer interface {
 	UpdateUsersAndGrants(context.Context, *UpdateUsersAndGrantsRequest) (*UpdateUsersAndGrantsResponse, error)
 	UpdateBranchControl(context.Context, *UpdateBranchControlRequest) (*UpdateBranchControlResponse, error)
 	DropDatabase(context.Context, *DropDatabaseRequest) (*DropDatabaseResponse, error)
+	// When automatic failover is configured, a primary periodically calls this
+	// method on each of its standbys. A standby acknowledges the heartbeat if
+	// the primary's epoch is at least as high as any epoch it has seen or voted
+	// in. A primary keeps its lease, and remains primary, as long as a majority
+	// of the cluster acknowledges its heartbeats.
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// When automatic failover is configured, a standby which has not received a
+	// heartbeat from a primary within the lease duration calls this method on
+	// the other members of the cluster to become primary at a new epoch. It
+	// becomes primary if a majority of the cluster grants it a vote.
+	RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error)
+	// A primary calls this method on a standby after it successfully
+	// replicates a root of a database to the standby, or confirms that the
+	// standby still has its latest root. Standbys use it to serve reads which
+	// wait for a root the primary handed out with dolt_cluster_root().
+	UpdateReplicationProgress(context.Context, *UpdateReplicationProgressRequest) (*UpdateReplicationProgressResponse, error)
 	mustEmbedUnimplementedReplicationServiceServer()
 }
 
@@ -112,6 +171,15 @@ func (UnimplementedReplicationServiceServer) UpdateBranchControl(context.Context
 func (UnimplementedReplicationServiceServer) DropDatabase(context.Context, *DropDatabaseRequest) (*DropDatabaseResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DropDatabase not implemented")
 }
+func (UnimplementedReplicationServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedReplicationServiceServer) RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
+func (UnimplementedReplicationServiceServer) UpdateReplicationProgress(context.Context, *UpdateReplicationProgressRequest) (*UpdateReplicationProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReplicationProgress not implemented")
+}
 func (UnimplementedReplicationServiceServer) mustEmbedUnimplementedReplicationServiceServer() {}
 
 // UnsafeReplicationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -179,6 +247,60 @@ func _ReplicationService_DropDatabase_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ReplicationService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.replicationapi.v1alpha1.ReplicationService/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReplicationService_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).RequestVote(ctx, req.(*RequestVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReplicationService_UpdateReplicationProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReplicationProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).UpdateReplicationProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.replicationapi.v1alpha1.ReplicationService/UpdateReplicationProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).UpdateReplicationProgress(ctx, req.(*UpdateReplicationProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ReplicationService_ServiceDesc is the grpc.ServiceDesc for ReplicationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -198,6 +320,18 @@ var ReplicationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DropDatabase",
 			Handler:    _ReplicationService_DropDatabase_Handler,
 		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _ReplicationService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "RequestVote",
+			Handler:    _ReplicationService_RequestVote_Handler,
+		},
+		{
+			MethodName: "UpdateReplicationProgress",
+			Handler:    _ReplicationService_UpdateReplicationProgress_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "dolt/services/replicationapi/v1alpha1/replication.proto",
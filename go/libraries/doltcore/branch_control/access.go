@@ -19,8 +19,10 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	flatbuffers "github.com/dolthub/flatbuffers/v23/go"
+	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/gen/fb/serial"
 )
@@ -31,9 +33,9 @@ type Permissions uint64
 const (
 	Permissions_Admin Permissions = 1 << iota // Permissions_Admin grants unrestricted control over a branch, including modification of table entries
 	Permissions_Write                         // Permissions_Write allows for all modifying operations on a branch, but does not allow modification of table entries
-	Permissions_Read                          // Permissions_Read allows for reading from a branch, which is equivalent to having no permissions
+	Permissions_Read                          // Permissions_Read allows for reading from a branch, which is only required once any entry grants it on the branch
 
-	Permissions_None Permissions = 0 // Permissions_None represents a lack of permissions, which defaults to allowing reading
+	Permissions_None Permissions = 0 // Permissions_None represents a lack of permissions, which defaults to allowing reading unless the branch is read restricted
 )
 
 // Access contains all of the expressions that comprise the "dolt_branch_control" table, which handles write Access to
@@ -44,6 +46,16 @@ type Access struct {
 	binlog   *Binlog
 	rows     []AccessRow
 	freeRows []uint32
+	// readRestrictions caches the parsed expressions of the entries that grant the read permission. It is cleared
+	// whenever the entries change.
+	readRestrictions atomic.Pointer[readRestrictions]
+}
+
+// readRestrictions are the parsed database and branch expressions of the entries that grant the read permission, with
+// the expressions of each entry at the same index.
+type readRestrictions struct {
+	databaseExprs []MatchExpression
+	branchExprs   []MatchExpression
 }
 
 // AccessRow contains the user-facing values of a particular row, along with the permissions for a row.
@@ -85,6 +97,56 @@ func (tbl *Access) Match(database string, branch string, user string, host strin
 	return len(results) > 0, perms
 }
 
+// IsReadRestricted returns whether any entry that grants the read permission matches the given database and branch,
+// regardless of the entry's user and host. Reading from such a branch requires the read, write, or admin permission.
+// Requires external synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Access) IsReadRestricted(database string, branch string) bool {
+	restrictions := tbl.getReadRestrictions()
+	if len(restrictions.databaseExprs) == 0 {
+		return false
+	}
+
+	databaseMatches := Match(restrictions.databaseExprs, database, sql.Collation_utf8mb4_0900_ai_ci)
+	defer indexPool.Put(databaseMatches)
+	var candidates []MatchExpression
+	for _, index := range databaseMatches {
+		candidates = append(candidates, restrictions.branchExprs[index])
+	}
+	branchMatches := Match(candidates, branch, sql.Collation_utf8mb4_0900_ai_ci)
+	defer indexPool.Put(branchMatches)
+	return len(branchMatches) > 0
+}
+
+// getReadRestrictions returns the parsed expressions of the entries that grant the read permission, parsing them if
+// they aren't already cached. Requires external synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Access) getReadRestrictions() *readRestrictions {
+	if restrictions := tbl.readRestrictions.Load(); restrictions != nil {
+		return restrictions
+	}
+	restrictions := &readRestrictions{}
+	for iter := tbl.Iter(); ; {
+		row, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if row.Permissions&Permissions_Read != Permissions_Read {
+			continue
+		}
+		index := uint32(len(restrictions.databaseExprs))
+		restrictions.databaseExprs = append(restrictions.databaseExprs, MatchExpression{
+			CollectionIndex: index,
+			SortOrders:      ParseExpression(row.Database, sql.Collation_utf8mb4_0900_ai_ci),
+		})
+		restrictions.branchExprs = append(restrictions.branchExprs, MatchExpression{
+			CollectionIndex: index,
+			SortOrders:      ParseExpression(row.Branch, sql.Collation_utf8mb4_0900_ai_ci),
+		})
+	}
+	// Readers may hold the read lock concurrently, in which case each parses the same expressions
+	tbl.readRestrictions.Store(restrictions)
+	return restrictions
+}
+
 // GetBinlog returns the table's binlog.
 func (tbl *Access) GetBinlog() *Binlog {
 	return tbl.binlog
@@ -108,6 +170,7 @@ func (tbl *Access) reinit() {
 	tbl.binlog = NewAccessBinlog(nil)
 	tbl.rows = nil
 	tbl.freeRows = nil
+	tbl.readRestrictions.Store(nil)
 }
 
 // Deserialize populates the table with the data from the flatbuffers representation.
@@ -194,6 +257,7 @@ func (tbl *Access) Insert(database string, branch string, user string, host stri
 			Permissions: perms,
 		})
 	}
+	tbl.readRestrictions.Store(nil)
 	// Add the entry to the root node
 	tbl.Root.Add(database, branch, user, host, MatchNodeData{
 		Permissions: perms,
@@ -231,6 +295,7 @@ func (tbl *Access) Delete(database string, branch string, user string, host stri
 	if removedIndex != math.MaxUint32 {
 		tbl.freeRows = append(tbl.freeRows, removedIndex)
 	}
+	tbl.readRestrictions.Store(nil)
 }
 
 // Iter returns an iterator that goes over all valid rows. The iterator does not acquire a read lock, therefore this
@@ -33,6 +33,8 @@ var (
 	ErrIncorrectPermissions  = errors.NewKind("`%s`@`%s` does not have the correct permissions on branch `%s`")
 	ErrCannotCreateBranch    = errors.NewKind("`%s`@`%s` cannot create a branch named `%s`")
 	ErrCannotDeleteBranch    = errors.NewKind("`%s`@`%s` cannot delete the branch `%s`")
+	ErrCannotReadBranch      = errors.NewKind("`%s`@`%s` cannot read from the branch `%s`")
+	ErrCannotReadCommit      = errors.NewKind("`%s`@`%s` cannot read the commit `%s`")
 	ErrExpressionsTooLong    = errors.NewKind("expressions are too long [%q, %q, %q, %q]")
 	ErrInsertingAccessRow    = errors.NewKind("`%s`@`%s` cannot add the row [%q, %q, %q, %q, %q]")
 	ErrInsertingNamespaceRow = errors.NewKind("`%s`@`%s` cannot add the row [%q, %q, %q, %q]")
@@ -314,6 +316,37 @@ func CanDeleteBranch(ctx context.Context, branchName string) error {
 	return ErrCannotDeleteBranch.New(user, host, branchName)
 }
 
+// CanReadBranch returns whether the given context can read from the given branch of the given database. Once any entry
+// grants the read permission on a branch, the branch is read restricted, and reading from it requires the read, write,
+// or admin permission. All other branches may be read by anyone, as was the case before read permissions were enforced.
+// In general, SQL statements will almost always return a *sql.Context, so any checks from the SQL path will correctly
+// check for read permissions.
+// However, not all CLI commands use *sql.Context, and therefore will not have any user associated with the context. In
+// these cases, CanReadBranch will pass as we want to allow all local commands to read any branch.
+func CanReadBranch(ctx context.Context, database string, branch string) error {
+	branchAwareSession := GetBranchAwareSession(ctx)
+	// A nil session means we're not in the SQL context, so we allow the read
+	if branchAwareSession == nil {
+		return nil
+	}
+	controller := branchAwareSession.GetController()
+	// Any context that has a non-nil session should always have a non-nil controller, so this is an error
+	if controller == nil {
+		return ErrMissingController.New()
+	}
+	controller.Access.RWMutex.RLock()
+	defer controller.Access.RWMutex.RUnlock()
+
+	user := branchAwareSession.GetUser()
+	host := branchAwareSession.GetHost()
+	// Get the permissions for the branch, user, and host combination
+	_, perms := controller.Access.Match(database, branch, user, host)
+	if perms&(Permissions_Admin|Permissions_Write|Permissions_Read) != 0 || !controller.Access.IsReadRestricted(database, branch) {
+		return nil
+	}
+	return ErrCannotReadBranch.New(user, host, branch)
+}
+
 // AddAdminForContext adds an entry in the access table for the user represented by the given context. If the
 // context is missing some functionality that is needed to perform the addition, such as a user or the Controller, then
 // this simply returns.
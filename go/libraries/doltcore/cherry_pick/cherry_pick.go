@@ -212,6 +212,9 @@ func cherryPick(ctx *sql.Context, dSess *dsess.DoltSession, roots doltdb.Roots,
 	if !ok {
 		return nil, "", doltdb.ErrGhostCommitEncountered
 	}
+	if err = dsess.CheckReadAccessForCommitSpec(ctx, dbName, doltDB, cherryCommitSpec, cherryCommit); err != nil {
+		return nil, "", err
+	}
 
 	if len(cherryCommit.DatasParents()) > 1 {
 		return nil, "", fmt.Errorf("cherry-picking a merge commit is not supported")
@@ -0,0 +1,82 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const defaultDiskCacheSize = 1 << 30
+
+var diskCache struct {
+	once sync.Once
+	// cache is set once the cache is opened by the first store on object storage
+	cache atomic.Pointer[nbs.DiskCache]
+}
+
+// objectStoreOptions returns the options of the stores created for databases on object storage, such as aws://, gs://
+// and oci:// remotes. When DOLT_DISK_CACHE_DIR is set, their chunks are read through a local disk cache of at most
+// DOLT_DISK_CACHE_SIZE bytes, which is shared by all of the stores of the process.
+func objectStoreOptions() []nbs.StoreOption {
+	diskCache.once.Do(func() {
+		dir := os.Getenv(dconfig.EnvDiskCacheDir)
+		if dir == "" {
+			return
+		}
+		cache, err := openDiskCache(dir, os.Getenv(dconfig.EnvDiskCacheSize))
+		if err != nil {
+			logrus.Warnf("not using the disk cache at %s: %v", dir, err)
+			return
+		}
+		diskCache.cache.Store(cache)
+	})
+
+	cache := diskCache.cache.Load()
+	if cache == nil {
+		return nil
+	}
+	return []nbs.StoreOption{nbs.WithDiskCache(cache)}
+}
+
+func openDiskCache(dir, size string) (*nbs.DiskCache, error) {
+	maxSize := uint64(defaultDiskCacheSize)
+	if size != "" {
+		var err error
+		maxSize, err = humanize.ParseBytes(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", dconfig.EnvDiskCacheSize, size, err)
+		}
+	}
+	return nbs.NewDiskCache(dir, maxSize)
+}
+
+// DiskCacheStats returns the stats of the disk cache of the stores on object storage, and false if the disk cache has
+// not been opened. It never opens the cache itself, which would create its directory and lock it.
+func DiskCacheStats() (nbs.DiskCacheStats, bool) {
+	cache := diskCache.cache.Load()
+	if cache == nil {
+		return nbs.DiskCacheStats{}, false
+	}
+	return cache.Stats(), true
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+)
+
+func TestOpenDiskCache(t *testing.T) {
+	cache, err := openDiskCache(t.TempDir(), "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(defaultDiskCacheSize), cache.Stats().MaxSize)
+	require.NoError(t, cache.Close())
+
+	cache, err = openDiskCache(t.TempDir(), "10MiB")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10<<20), cache.Stats().MaxSize)
+	require.NoError(t, cache.Close())
+
+	_, err = openDiskCache(t.TempDir(), "lots")
+	assert.Error(t, err)
+}
+
+func TestDiskCacheStatsDoesNotOpenCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	t.Setenv(dconfig.EnvDiskCacheDir, dir)
+
+	_, ok := DiskCacheStats()
+	assert.False(t, ok)
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -53,6 +53,12 @@ const (
 
 	OSSScheme = "oss"
 
+	// SSHScheme
+	SSHScheme = "ssh"
+
+	// S3Scheme
+	S3Scheme = "s3"
+
 	defaultScheme       = HTTPSScheme
 	defaultMemTableSize = 256 * 1024 * 1024
 )
@@ -78,6 +84,8 @@ var DBFactories = map[string]DBFactory{
 	LocalBSScheme: LocalBSFactory{},
 	HTTPScheme:    NewDoltRemoteFactory(true),
 	HTTPSScheme:   NewDoltRemoteFactory(false),
+	SSHScheme:     SSHFactory{},
+	S3Scheme:      S3Factory{},
 }
 
 // CreateDB creates a database based on the supplied urlStr, and creation params.  The DBFactory used for creation is
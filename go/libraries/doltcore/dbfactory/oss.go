@@ -97,7 +97,7 @@ func (fact OSSFactory) newChunkStore(ctx context.Context, nbf *types.NomsBinForm
 	}
 
 	q := nbs.NewUnlimitedMemQuotaProvider()
-	return nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, q)
+	return nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, q, objectStoreOptions()...)
 }
 
 func ossConfigFromParams(params map[string]interface{}) ossCredential {
@@ -0,0 +1,128 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// S3EndpointParam is a creation parameter that can be used to set the endpoint of an S3 compatible store
+	S3EndpointParam = "s3-endpoint"
+
+	// S3ForcePathStyleParam is a creation parameter that can be used to address buckets in the path of requests,
+	// rather than in the host name. Most S3 compatible stores need this.
+	S3ForcePathStyleParam = "s3-force-path-style"
+
+	// defaultS3Region is used when no region is configured. S3 compatible stores generally ignore the region, but
+	// requests cannot be signed without one.
+	defaultS3Region = "us-east-1"
+)
+
+// S3Factory is a DBFactory implementation for creating databases in S3 and S3 compatible stores, such as MinIO and
+// Ceph. Unlike AWSFactory it does not need a DynamoDB table, the manifest is kept in the bucket and updated with
+// conditional writes.
+type S3Factory struct {
+}
+
+// PrepareDB prepares an S3 backed database
+func (fact S3Factory) PrepareDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) error {
+	// nothing to prepare
+	return nil
+}
+
+// CreateDB creates an S3 backed database
+func (fact S3Factory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) (datas.Database, types.ValueReadWriter, tree.NodeStore, error) {
+	cs, err := fact.newChunkStore(ctx, nbf, urlObj, params)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	vrw := types.NewValueStore(cs)
+	ns := tree.NewNodeStore(cs)
+	db := datas.NewTypesDatabase(vrw, ns)
+
+	return db, vrw, ns, nil
+}
+
+func (fact S3Factory) newChunkStore(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) (chunks.ChunkStore, error) {
+	// s3://[bucket]/[path]
+	bucket := urlObj.Hostname()
+	if bucket == "" {
+		return nil, errors.New("s3 url has no bucket")
+	}
+	prefix, err := validatePath(urlObj.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s3ConfigFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = sess.Config.Credentials.Get(); err != nil {
+		return nil, err
+	}
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess = sess.Copy(aws.NewConfig().WithRegion(defaultS3Region))
+	}
+
+	bs := blobstore.NewS3Blobstore(s3.New(sess), bucket, prefix)
+	q := nbs.NewUnlimitedMemQuotaProvider()
+	return nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, q, objectStoreOptions()...)
+}
+
+// s3ConfigFromParams returns the session options for the aws credential and region params, along with the
+// endpoint and the addressing of the store.
+func s3ConfigFromParams(params map[string]interface{}) (session.Options, error) {
+	opts, err := awsConfigFromParams(params)
+	if err != nil {
+		return session.Options{}, err
+	}
+
+	s3Config := aws.NewConfig()
+	if val, ok := params[S3EndpointParam]; ok && val.(string) != "" {
+		s3Config = s3Config.WithEndpoint(val.(string))
+	}
+	if val, ok := params[S3ForcePathStyleParam]; ok {
+		pathStyle, err := strconv.ParseBool(val.(string))
+		if err != nil {
+			return session.Options{}, errors.New("invalid value for " + S3ForcePathStyleParam)
+		}
+		s3Config = s3Config.WithS3ForcePathStyle(pathStyle)
+	}
+	opts.Config.MergeIn(s3Config)
+
+	return opts, nil
+}
@@ -0,0 +1,257 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/libraries/events"
+	"github.com/dolthub/dolt/go/libraries/utils/streammux"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// TransferCommand is the dolt command which serves a database to the ssh transport on the remote host
+	TransferCommand = "transfer"
+
+	defaultSSHCommand  = "ssh"
+	defaultSSHExecPath = "dolt"
+
+	sshExitTimeout = 5 * time.Second
+)
+
+// SSHFactory is a DBFactory implementation for creating databases on hosts reachable over SSH. Like git, it runs
+// `dolt transfer <path>` on the remote host through the ssh command, and speaks the remotesapi chunk store protocol
+// with it over the command's stdin and stdout. The gRPC calls and the table file uploads and downloads are
+// multiplexed over that single stream.
+//
+// The ssh command can be replaced through the DOLT_SSH environment variable, and the dolt executable on the remote
+// host through DOLT_SSH_EXEC_PATH.
+type SSHFactory struct {
+}
+
+// PrepareDB does nothing for ssh remotes, `dolt transfer` creates a database which does not exist when it is first
+// written to.
+func (fact SSHFactory) PrepareDB(ctx context.Context, nbf *types.NomsBinFormat, u *url.URL, params map[string]interface{}) error {
+	return nil
+}
+
+// CreateDB starts `dolt transfer` on the remote host, and returns a database backed by it.
+func (fact SSHFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) (datas.Database, types.ValueReadWriter, tree.NodeStore, error) {
+	if urlObj.Host == "" {
+		return nil, nil, nil, fmt.Errorf("ssh url '%s' has no host", urlObj.String())
+	}
+	if strings.Trim(urlObj.Path, "/") == "" {
+		return nil, nil, nil, fmt.Errorf("ssh url '%s' has no path", urlObj.String())
+	}
+
+	args, err := sshArgs(urlObj)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cmd := exec.Command(sshCommand()[0], args...)
+	stderr := &syncBuffer{}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not run ssh command: %w", err)
+	}
+
+	sess := streammux.NewSession(sshConn{ReadCloser: stdout, in: stdin}, true)
+	closeAll := func() {
+		sess.Close()
+		// The remote side exits once its stdin is closed, but the ssh command may be stuck on something else
+		exited := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			close(exited)
+		}()
+		select {
+		case <-exited:
+		case <-time.After(sshExitTimeout):
+			cmd.Process.Kill()
+			<-exited
+		}
+	}
+
+	dial := func(context.Context, string) (net.Conn, error) {
+		return sess.Open()
+	}
+	conn, err := grpc.Dial(urlObj.Host,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dial),
+		grpc.WithChainUnaryInterceptor(remotestorage.EventsUnaryClientInterceptor(events.GlobalCollector())))
+	if err != nil {
+		closeAll()
+		return nil, nil, nil, err
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx, "")
+		},
+	}
+
+	csClient := remotesapi.NewChunkStoreServiceClient(conn)
+	cs, err := remotestorage.NewDoltChunkStoreFromPath(ctx, nbf, urlObj.Path, urlObj.Host, false, csClient)
+	if err != nil {
+		conn.Close()
+		closeAll()
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, nil, nil, fmt.Errorf("could not access dolt url '%s': %s", urlObj.String(), msg)
+		}
+		return nil, nil, nil, fmt.Errorf("could not access dolt url '%s': %w", urlObj.String(), err)
+	}
+	cs = cs.WithHTTPFetcher(&http.Client{Transport: transport})
+	cs.SetFinalizer(func() error {
+		err := conn.Close()
+		transport.CloseIdleConnections()
+		closeAll()
+		return err
+	})
+
+	if _, ok := params[NoCachingParameter]; ok {
+		cs = cs.WithNoopChunkCache()
+	}
+
+	vrw := types.NewValueStore(cs)
+	ns := tree.NewNodeStore(cs)
+	db := datas.NewTypesDatabase(vrw, ns)
+
+	return db, vrw, ns, nil
+}
+
+func sshCommand() []string {
+	if fields := strings.Fields(os.Getenv(dconfig.EnvSSH)); len(fields) > 0 {
+		return fields
+	}
+	return []string{defaultSSHCommand}
+}
+
+// sshArgs returns the arguments of the ssh command which runs `dolt transfer` for the database at |urlObj|. Hosts and
+// users which would be parsed as options of the ssh command are rejected.
+func sshArgs(urlObj *url.URL) ([]string, error) {
+	args := sshCommand()[1:]
+	if port := urlObj.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	host := urlObj.Hostname()
+	if strings.HasPrefix(host, "-") {
+		return nil, fmt.Errorf("invalid ssh host '%s'", host)
+	}
+	if urlObj.User != nil {
+		user := urlObj.User.Username()
+		if strings.HasPrefix(user, "-") {
+			return nil, fmt.Errorf("invalid ssh user '%s'", user)
+		}
+		host = user + "@" + host
+	}
+
+	path, err := remoteShellPath(urlObj.Path)
+	if err != nil {
+		return nil, err
+	}
+	execPath := os.Getenv(dconfig.EnvSSHExecPath)
+	if execPath == "" {
+		execPath = defaultSSHExecPath
+	}
+	return append(args, "--", host, execPath+" "+TransferCommand+" "+path), nil
+}
+
+// homeDirPrefix matches the ~ or ~user prefix of a path relative to a home directory. A user can't start with -,
+// since the shell expands ~- to the previous working directory.
+var homeDirPrefix = regexp.MustCompile(`^~([A-Za-z0-9._][A-Za-z0-9._-]*)?$`)
+
+// remoteShellPath quotes |path| for the shell of the remote host. As with git, a path starting with /~ is relative to
+// a home directory on the remote host, so its ~ or ~user prefix is left for the shell to expand.
+func remoteShellPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/~") {
+		return shellQuote(path), nil
+	}
+	home, rest, found := strings.Cut(path[1:], "/")
+	if !homeDirPrefix.MatchString(home) {
+		return "", fmt.Errorf("invalid home directory '%s' in ssh path '%s'", home, path)
+	}
+	if !found || rest == "" {
+		return home, nil
+	}
+	return home + "/" + shellQuote(rest), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sshConn is the connection to `dolt transfer` through the stdin and stdout of the ssh command.
+type sshConn struct {
+	io.ReadCloser
+	in io.WriteCloser
+}
+
+func (c sshConn) Write(p []byte) (int, error) {
+	return c.in.Write(p)
+}
+
+func (c sshConn) Close() error {
+	err := c.in.Close()
+	if rerr := c.ReadCloser.Close(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// syncBuffer collects the stderr of the ssh command, which is written from another goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
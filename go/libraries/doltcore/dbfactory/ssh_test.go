@@ -0,0 +1,77 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/utils/earl"
+)
+
+func TestSSHArgs(t *testing.T) {
+	t.Setenv(dconfig.EnvSSH, "")
+	t.Setenv(dconfig.EnvSSHExecPath, "")
+
+	tests := []struct {
+		url      string
+		expected []string
+	}{
+		{"ssh://example.com/srv/db", []string{"--", "example.com", "dolt transfer '/srv/db'"}},
+		{"ssh://me@example.com:2222/srv/db", []string{"-p", "2222", "--", "me@example.com", "dolt transfer '/srv/db'"}},
+		{"ssh://example.com/srv/it's", []string{"--", "example.com", `dolt transfer '/srv/it'\''s'`}},
+		{"ssh://example.com/~/db", []string{"--", "example.com", "dolt transfer ~/'db'"}},
+		{"ssh://example.com/~me/db", []string{"--", "example.com", "dolt transfer ~me/'db'"}},
+		{"ssh://example.com/~me", []string{"--", "example.com", "dolt transfer ~me"}},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := earl.Parse(test.url)
+			require.NoError(t, err)
+			args, err := sshArgs(u)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, args)
+		})
+	}
+
+	for _, url := range []string{
+		"ssh://-oProxyCommand=x/srv/db",
+		"ssh://-oProxyCommand=x@example.com/srv/db",
+		"ssh://example.com/~$(touch%20pwned)/db",
+		"ssh://example.com/~me;rm/db",
+		"ssh://example.com/~-/db",
+	} {
+		t.Run(url, func(t *testing.T) {
+			u, err := earl.Parse(url)
+			require.NoError(t, err)
+			_, err = sshArgs(u)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv(dconfig.EnvSSH, "ssh -i key")
+		t.Setenv(dconfig.EnvSSHExecPath, "/opt/dolt/bin/dolt")
+		u, err := earl.Parse("ssh://example.com/srv/db")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ssh", "-i", "key"}, sshCommand())
+		args, err := sshArgs(u)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"-i", "key", "--", "example.com", "/opt/dolt/bin/dolt transfer '/srv/db'"}, args)
+	})
+}
@@ -21,6 +21,7 @@ const (
 	EnvOpenAiKey                     = "OPENAI_API_KEY"
 	EnvDoltRemotePassword            = "DOLT_REMOTE_PASSWORD"
 	EnvEditor                        = "EDITOR"
+	EnvPager                         = "PAGER"
 	EnvSqlDebugLogVerbose            = "DOLT_SQL_DEBUG_LOG_VERBOSE"
 	EnvSqlDebugLog                   = "DOLT_SQL_DEBUG_LOG"
 	EnvHome                          = "HOME"
@@ -43,4 +44,9 @@ const (
 	EnvDoltAuthorDate                = "DOLT_AUTHOR_DATE"
 	EnvDoltCommitterDate             = "DOLT_COMMITTER_DATE"
 	EnvDbNameReplace                 = "DOLT_DBNAME_REPLACE"
+	EnvSSH                           = "DOLT_SSH"
+	EnvSSHExecPath                   = "DOLT_SSH_EXEC_PATH"
+	EnvDiskCacheDir                  = "DOLT_DISK_CACHE_DIR"
+	EnvDiskCacheSize                 = "DOLT_DISK_CACHE_SIZE"
+	EnvImportPassword                = "DOLT_IMPORT_PASSWORD"
 )
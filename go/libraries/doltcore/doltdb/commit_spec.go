@@ -113,3 +113,20 @@ func NewCommitSpec(cSpecStr string) (*CommitSpec, error) {
 	}
 	return &CommitSpec{name, refCommitSpec, as}, nil
 }
+
+// BranchName returns the name of the local branch that the commit spec would resolve, such as `main` for `main`,
+// `heads/main~2` or `refs/heads/main`, and whether the commit spec may refer to a local branch at all. The branch is not
+// required to exist, and a ref naming a tag or a remote branch is not considered to name a local branch.
+func (c *CommitSpec) BranchName() (string, bool) {
+	if c.csType != refCommitSpec {
+		return "", false
+	}
+	name := strings.TrimPrefix(c.baseSpec, "refs/")
+	if trimmed := strings.TrimPrefix(name, "heads/"); trimmed != name {
+		return trimmed, true
+	}
+	if name != c.baseSpec || strings.HasPrefix(name, "tags/") || strings.HasPrefix(name, "remotes/") {
+		return "", false
+	}
+	return name, true
+}
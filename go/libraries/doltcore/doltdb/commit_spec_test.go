@@ -17,6 +17,9 @@ package doltdb
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/dolthub/dolt/go/libraries/utils/test"
 	"github.com/dolthub/dolt/go/store/hash"
 )
@@ -66,3 +69,29 @@ func TestNewCommitSpec(t *testing.T) {
 		}
 	}
 }
+
+func TestCommitSpecBranchName(t *testing.T) {
+	tests := []struct {
+		inputStr       string
+		expectedBranch string
+		expectedOk     bool
+	}{
+		{"main", "main", true},
+		{"release/1.0~2", "release/1.0", true},
+		{"heads/main", "main", true},
+		{"refs/heads/main^", "main", true},
+		{"tags/v1.0", "", false},
+		{"refs/tags/v1.0", "", false},
+		{"remotes/origin/main", "", false},
+		{"head~1", "", false},
+		{"00000000000000000000000000000000", "", false},
+	}
+
+	for _, test := range tests {
+		cs, err := NewCommitSpec(test.inputStr)
+		require.NoError(t, err)
+		branch, ok := cs.BranchName()
+		assert.Equal(t, test.expectedOk, ok, test.inputStr)
+		assert.Equal(t, test.expectedBranch, branch, test.inputStr)
+	}
+}
@@ -1413,6 +1413,47 @@ type ReplicationStatusController struct {
 	// circuit breakers, etc. and might feed into exposed replication
 	// metrics.
 	NotifyWaitFailed []func()
+
+	// There is an entry here for each function in Wait which names the
+	// replica it is waiting on, or "" if the replica is not named.
+	Replicas []string
+
+	// If non-nil, how the writer should treat the results of waiting on
+	// the functions in Wait. If nil, the writer waits on all of them and
+	// failures are not fatal to the write.
+	Quorum *ReplicationQuorum
+}
+
+// ErrReplicationQuorumNotReached is returned for a write which was made to a
+// database with a strict ReplicationQuorum when not enough replicas
+// acknowledged the write in time.
+var ErrReplicationQuorumNotReached = errors.New("replication quorum not reached")
+
+// ReplicationQuorum describes how many of the replicas behind the Wait
+// functions of a ReplicationStatusController must acknowledge a write.
+type ReplicationQuorum struct {
+	// The number of Wait functions which must return successfully before
+	// the write is acknowledged. 0 means all of them.
+	Acks int
+
+	// If true, failing to reach |Acks| acknowledgements is an error for
+	// the write, instead of a warning.
+	Strict bool
+
+	// If non-nil, called with the outcome of each wait on the quorum.
+	Report func(ReplicationWaitResult)
+}
+
+// ReplicationWaitResult is the outcome of waiting on the replication of a
+// write with a ReplicationQuorum.
+type ReplicationWaitResult struct {
+	// How long the writer waited.
+	Duration time.Duration
+	// The names of the replicas which acknowledged the write while the
+	// writer waited.
+	Acked []string
+	// Whether enough replicas acknowledged the write.
+	QuorumReached bool
 }
 
 // DatabaseUpdateListener allows callbacks on a registered listener when a database is created, dropped, or when
@@ -50,6 +50,16 @@ type NotifyWaitFailedCommitHook interface {
 	NotifyWaitFailed()
 }
 
+// QuorumCommitHook is an optional interface that can be implemented by
+// CommitHooks which replicate to a named replica. The Wait functions of the
+// hooks for a database are waited on according to the hooks'
+// ReplicationQuorum.
+type QuorumCommitHook interface {
+	ReplicaName() string
+	// May return nil, in which case the writer waits on every replica.
+	ReplicationQuorum() *ReplicationQuorum
+}
+
 func (db hooksDatabase) SetCommitHooks(ctx context.Context, postHooks []CommitHook) hooksDatabase {
 	db.postCommitHooks = make([]CommitHook, len(postHooks))
 	copy(db.postCommitHooks, postHooks)
@@ -82,7 +92,9 @@ func (db hooksDatabase) ExecuteCommitHooks(ctx context.Context, ds datas.Dataset
 		ioff = len(rsc.Wait)
 		rsc.Wait = append(rsc.Wait, make([]func(context.Context) error, len(db.postCommitHooks))...)
 		rsc.NotifyWaitFailed = append(rsc.NotifyWaitFailed, make([]func(), len(db.postCommitHooks))...)
+		rsc.Replicas = append(rsc.Replicas, make([]string, ioff-len(rsc.Replicas)+len(db.postCommitHooks))...)
 	}
+	var quorumMu sync.Mutex
 	for il, hook := range db.postCommitHooks {
 		if !onlyWS || hook.ExecuteForWorkingSets() {
 			i := il
@@ -101,6 +113,14 @@ func (db hooksDatabase) ExecuteCommitHooks(ctx context.Context, ds datas.Dataset
 					} else {
 						rsc.NotifyWaitFailed[i+ioff] = func() {}
 					}
+					if qh, ok := hook.(QuorumCommitHook); ok {
+						rsc.Replicas[i+ioff] = qh.ReplicaName()
+						if q := qh.ReplicationQuorum(); q != nil {
+							quorumMu.Lock()
+							rsc.Quorum = q
+							quorumMu.Unlock()
+						}
+					}
 				}
 			}()
 		}
@@ -112,11 +132,13 @@ func (db hooksDatabase) ExecuteCommitHooks(ctx context.Context, ds datas.Dataset
 			if rsc.Wait[i] != nil {
 				rsc.Wait[j] = rsc.Wait[i]
 				rsc.NotifyWaitFailed[j] = rsc.NotifyWaitFailed[i]
+				rsc.Replicas[j] = rsc.Replicas[i]
 				j++
 			}
 		}
 		rsc.Wait = rsc.Wait[:j]
 		rsc.NotifyWaitFailed = rsc.NotifyWaitFailed[:j]
+		rsc.Replicas = rsc.Replicas[:j]
 	}
 }
 
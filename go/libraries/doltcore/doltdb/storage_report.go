@@ -0,0 +1,528 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// PrimaryIndexName is the name StorageReport gives to the row data of a table.
+const PrimaryIndexName = "PRIMARY"
+
+// StorageReport attributes the chunks of a database to its refs and to the indexes of its tables. Sizes are the
+// compressed sizes of chunks, as they are stored in table files.
+type StorageReport struct {
+	// Tables are the storage of the row data and secondary indexes of the tables at the roots of the branches, tags
+	// and working sets of the database, sorted by table and index.
+	Tables []TableStorage `json:"tables"`
+	// Refs are the storage reachable from each ref of the database, including its history, sorted by ref.
+	Refs []RefStorage `json:"refs"`
+
+	// Chunks and Bytes are the chunks reachable from any ref of the database. These are kept by `dolt gc`.
+	Chunks uint64 `json:"chunks"`
+	Bytes  uint64 `json:"bytes"`
+	// StoredBytes is the size of the table files of the database.
+	StoredBytes uint64 `json:"stored_bytes"`
+	// ReclaimableBytes is an upper bound of the storage `dolt gc` would free: the size of the table files which is not
+	// used by reachable chunks. It includes the indexes and footers of table files and the record headers of the
+	// journal, which gc does not free for the reachable chunks.
+	ReclaimableBytes uint64 `json:"reclaimable_bytes"`
+}
+
+// TableStorage is the storage of one index of a table. Its UniqueBytes are referenced by no other index, and its
+// SharedBytes are also referenced by other indexes or tables, such as tables with the same contents.
+type TableStorage struct {
+	Table        string `json:"table"`
+	Index        string `json:"index"`
+	Chunks       uint64 `json:"chunks"`
+	Bytes        uint64 `json:"bytes"`
+	UniqueBytes  uint64 `json:"unique_bytes"`
+	SharedBytes  uint64 `json:"shared_bytes"`
+	AvgChunkSize uint64 `json:"avg_chunk_size"`
+}
+
+// RefStorage is the storage reachable from a ref. Its UniqueBytes are reachable from no other ref, and would be
+// reclaimed by `dolt gc` after deleting the ref.
+type RefStorage struct {
+	Ref          string `json:"ref"`
+	Chunks       uint64 `json:"chunks"`
+	Bytes        uint64 `json:"bytes"`
+	UniqueBytes  uint64 `json:"unique_bytes"`
+	AvgChunkSize uint64 `json:"avg_chunk_size"`
+}
+
+// GetStorageReport walks every chunk reachable from the refs of |ddb| to build its StorageReport. This reads the
+// entire database.
+func (ddb *DoltDB) GetStorageReport(ctx context.Context) (*StorageReport, error) {
+	cs := datas.ChunkStoreFromDatabase(ddb.db)
+	walkAddrs, err := types.WalkAddrsForChunkStore(cs)
+	if err != nil {
+		return nil, err
+	}
+
+	datasets, err := ddb.db.Datasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var refNames []string
+	var refAddrs []hash.Hash
+	err = datasets.IterAll(ctx, func(id string, addr hash.Hash) error {
+		refNames = append(refNames, id)
+		refAddrs = append(refAddrs, addr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageReport{}
+	refLabels := newChunkLabeler(cs, walkAddrs)
+	refRoots := make([]hash.HashSet, len(refAddrs))
+	for i, addr := range refAddrs {
+		refRoots[i] = hash.NewHashSet(addr)
+	}
+	if err = refLabels.walk(ctx, refRoots); err != nil {
+		return nil, err
+	}
+	refStats := refLabels.stats()
+	for i, name := range refNames {
+		s := refStats[i]
+		report.Refs = append(report.Refs, RefStorage{
+			Ref:          name,
+			Chunks:       s.chunks,
+			Bytes:        s.bytes,
+			UniqueBytes:  s.uniqueBytes,
+			AvgChunkSize: s.avgChunkSize(),
+		})
+	}
+	sort.Slice(report.Refs, func(i, j int) bool {
+		return report.Refs[i].Ref < report.Refs[j].Ref
+	})
+	for _, c := range refLabels.chunks {
+		report.Chunks++
+		report.Bytes += uint64(c.size)
+	}
+
+	if tfs, ok := cs.(chunks.TableFileStore); ok {
+		report.StoredBytes, err = tfs.Size(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if report.StoredBytes > report.Bytes {
+			report.ReclaimableBytes = report.StoredBytes - report.Bytes
+		}
+	}
+
+	report.Tables, err = ddb.tableStorage(ctx, cs, walkAddrs, refNames)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// tableStorage returns the storage of the indexes of the tables at the roots of the branches, tags and working sets
+// |refNames|.
+func (ddb *DoltDB) tableStorage(ctx context.Context, cs chunks.ChunkStore, walkAddrs chunkAddrWalker, refNames []string) ([]TableStorage, error) {
+	var roots []RootValue
+	for _, name := range refNames {
+		refRoots, err := ddb.rootsOfRef(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, refRoots...)
+	}
+
+	type tableIndex struct {
+		table, index string
+	}
+	labels := make(map[tableIndex]int)
+	var indexes []tableIndex
+	var addrs []hash.HashSet
+	for _, root := range roots {
+		err := root.IterTables(ctx, func(name TableName, table *Table, sch schema.Schema) (bool, error) {
+			indexAddrs, err := tableIndexAddrs(ctx, table, sch)
+			if err != nil {
+				return true, err
+			}
+			for indexName, addr := range indexAddrs {
+				ti := tableIndex{name.String(), indexName}
+				label, ok := labels[ti]
+				if !ok {
+					label = len(indexes)
+					labels[ti] = label
+					indexes = append(indexes, ti)
+					addrs = append(addrs, hash.NewHashSet())
+				}
+				addrs[label].Insert(addr)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	labeler := newChunkLabeler(cs, walkAddrs)
+	if err := labeler.walk(ctx, addrs); err != nil {
+		return nil, err
+	}
+
+	stats := labeler.stats()
+	tables := make([]TableStorage, len(indexes))
+	for i, ti := range indexes {
+		s := stats[i]
+		tables[i] = TableStorage{
+			Table:        ti.table,
+			Index:        ti.index,
+			Chunks:       s.chunks,
+			Bytes:        s.bytes,
+			UniqueBytes:  s.uniqueBytes,
+			SharedBytes:  s.bytes - s.uniqueBytes,
+			AvgChunkSize: s.avgChunkSize(),
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Table != tables[j].Table {
+			return tables[i].Table < tables[j].Table
+		}
+		return tables[i].Index < tables[j].Index
+	})
+	return tables, nil
+}
+
+// rootsOfRef returns the roots of the branch, tag or working set |name|, and nothing for other refs.
+func (ddb *DoltDB) rootsOfRef(ctx context.Context, name string) ([]RootValue, error) {
+	if ref.IsWorkingSet(name) {
+		ws, err := ddb.ResolveWorkingSet(ctx, ref.NewWorkingSetRef(name))
+		if err != nil {
+			return nil, err
+		}
+		return []RootValue{ws.WorkingRoot(), ws.StagedRoot()}, nil
+	}
+	if !ref.IsRef(name) {
+		return nil, nil
+	}
+	r, err := ref.Parse(name)
+	if err != nil {
+		// not a ref with roots, such as a stash
+		return nil, nil
+	}
+
+	var cm *Commit
+	switch r.GetType() {
+	case ref.BranchRefType:
+		cm, err = ddb.ResolveCommitRef(ctx, r)
+	case ref.TagRefType:
+		var tag *Tag
+		tag, err = ddb.ResolveTag(ctx, r.(ref.TagRef))
+		if tag != nil {
+			cm = tag.Commit
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	root, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []RootValue{root}, nil
+}
+
+// tableIndexAddrs returns the addresses of the row data and secondary indexes of |table|, by index name.
+func tableIndexAddrs(ctx context.Context, table *Table, sch schema.Schema) (map[string]hash.Hash, error) {
+	addrs := make(map[string]hash.Hash)
+	rows, err := table.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addrs[PrimaryIndexName], err = indexAddr(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := table.GetIndexSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range sch.Indexes().AllIndexes() {
+		idx, err := set.GetIndex(ctx, sch, nil, def.Name())
+		if err != nil {
+			return nil, err
+		}
+		addrs[def.Name()], err = indexAddr(idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+func indexAddr(idx durable.Index) (hash.Hash, error) {
+	if idx.Format() == types.Format_DOLT {
+		return durable.ProllyMapFromIndex(idx).HashOf(), nil
+	}
+	return idx.HashOf()
+}
+
+type chunkAddrWalker func(chunks.Chunk, func(h hash.Hash, isleaf bool) error) error
+
+type labeledChunk struct {
+	size uint32
+	// hasChildren is false for chunks which reference no other chunks, which are never read again.
+	hasChildren bool
+	// labels is the offset of the labelSet of the chunk in chunkLabeler.sets.
+	labels uint32
+}
+
+// labelSet is a bit set of labels.
+type labelSet []uint64
+
+func (s labelSet) add(o labelSet) {
+	for i := range s {
+		s[i] |= o[i]
+	}
+}
+
+// missing returns the labels of |o| which are not in |s|, or nil if there are none.
+func (s labelSet) missing(o labelSet) labelSet {
+	var d labelSet
+	for i := range o {
+		if w := o[i] &^ s[i]; w != 0 {
+			if d == nil {
+				d = make(labelSet, len(o))
+			}
+			d[i] = w
+		}
+	}
+	return d
+}
+
+// each calls |cb| with each label of |s|.
+func (s labelSet) each(cb func(label int)) {
+	for i, w := range s {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			cb(i*64 + b)
+			w &^= 1 << b
+		}
+	}
+}
+
+// chunkLabeler labels the chunks reachable from addresses with the labels of the addresses which reach them, and
+// keeps the stats of every label. It walks every label at once, level by level, and does not keep the edges between
+// chunks in memory: a chunk with children is read again if it is reached by new labels after it was first read, to
+// pass them on to its children. Chunks without children, which are most of the chunks of a database, are read once.
+type chunkLabeler struct {
+	cs        chunks.ChunkStore
+	walkAddrs chunkAddrWalker
+	chunks    map[hash.Hash]labeledChunk
+	// sets holds the labelSet of each chunk, setWords words each.
+	sets     []uint64
+	setWords int
+	totals   []labelStats
+}
+
+func newChunkLabeler(cs chunks.ChunkStore, walkAddrs chunkAddrWalker) *chunkLabeler {
+	return &chunkLabeler{
+		cs:        cs,
+		walkAddrs: walkAddrs,
+		chunks:    make(map[hash.Hash]labeledChunk),
+	}
+}
+
+func (l *chunkLabeler) labels(c labeledChunk) labelSet {
+	return labelSet(l.sets[c.labels : int(c.labels)+l.setWords])
+}
+
+// walk visits the chunks reachable from each of |addrs| under the label of its index.
+func (l *chunkLabeler) walk(ctx context.Context, addrs []hash.HashSet) error {
+	l.setWords = (len(addrs) + 63) / 64
+	l.totals = make([]labelStats, len(addrs))
+
+	pending := make(map[hash.Hash]labelSet)
+	for label, set := range addrs {
+		for h := range set {
+			ls, ok := pending[h]
+			if !ok {
+				ls = make(labelSet, l.setWords)
+				pending[h] = ls
+			}
+			ls[label/64] |= 1 << (label % 64)
+		}
+	}
+
+	for len(pending) > 0 {
+		// |added| are the labels each chunk to read passes on to its children
+		added := make(map[hash.Hash]labelSet)
+		toRead := hash.NewHashSet()
+		for h, ls := range pending {
+			c, ok := l.chunks[h]
+			if !ok {
+				added[h] = ls
+				toRead.Insert(h)
+				continue
+			}
+			labels := l.labels(c)
+			d := labels.missing(ls)
+			if d == nil {
+				continue
+			}
+			labels.add(d)
+			l.count(d, c.size)
+			if c.hasChildren {
+				added[h] = d
+				toRead.Insert(h)
+			}
+		}
+
+		next := make(map[hash.Hash]labelSet)
+		var mu sync.Mutex
+		err := l.readChunks(ctx, toRead, func(ch chunks.Chunk, size int) error {
+			var children []hash.Hash
+			err := l.walkAddrs(ch, func(h hash.Hash, _ bool) error {
+				children = append(children, h)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			h := ch.Hash()
+			d := added[h]
+			if _, ok := l.chunks[h]; !ok {
+				c := labeledChunk{size: uint32(size), hasChildren: len(children) > 0, labels: uint32(len(l.sets))}
+				l.sets = append(l.sets, d...)
+				l.chunks[h] = c
+				l.count(d, c.size)
+			}
+			for _, child := range children {
+				ls, ok := next[child]
+				if !ok {
+					ls = make(labelSet, l.setWords)
+					next[child] = ls
+				}
+				ls.add(d)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		pending = next
+	}
+	return nil
+}
+
+func (l *chunkLabeler) count(ls labelSet, size uint32) {
+	ls.each(func(label int) {
+		l.totals[label].chunks++
+		l.totals[label].bytes += uint64(size)
+	})
+}
+
+// readChunks calls |cb| with each chunk of |hashes| in the store and its stored size. Chunks missing from the store,
+// such as those of a shallow clone, are skipped.
+func (l *chunkLabeler) readChunks(ctx context.Context, hashes hash.HashSet, cb func(ch chunks.Chunk, size int) error) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	var cbErr error
+	var mu sync.Mutex
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cbErr == nil {
+			cbErr = err
+		}
+	}
+
+	var err error
+	if ccs, ok := l.cs.(interface {
+		GetManyCompressed(context.Context, hash.HashSet, func(context.Context, nbs.CompressedChunk)) error
+	}); ok {
+		err = ccs.GetManyCompressed(ctx, hashes, func(ctx context.Context, cc nbs.CompressedChunk) {
+			ch, err := cc.ToChunk()
+			if err != nil {
+				setErr(err)
+				return
+			}
+			if err = cb(ch, len(cc.FullCompressedChunk)); err != nil {
+				setErr(err)
+			}
+		})
+	} else {
+		err = l.cs.GetMany(ctx, hashes, func(ctx context.Context, ch *chunks.Chunk) {
+			if err := cb(*ch, len(ch.Data())); err != nil {
+				setErr(err)
+			}
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if cbErr != nil {
+		return fmt.Errorf("error reading chunks: %w", cbErr)
+	}
+	return nil
+}
+
+type labelStats struct {
+	chunks, bytes, uniqueBytes uint64
+}
+
+func (s labelStats) avgChunkSize() uint64 {
+	if s.chunks == 0 {
+		return 0
+	}
+	return s.bytes / s.chunks
+}
+
+// stats returns the stats of each label. The unique bytes of a label are those of the chunks reached by no other label.
+func (l *chunkLabeler) stats() []labelStats {
+	stats := slices.Clone(l.totals)
+	for _, c := range l.chunks {
+		only := -1
+		l.labels(c).each(func(label int) {
+			if only == -1 {
+				only = label
+			} else {
+				only = -2
+			}
+		})
+		if only >= 0 {
+			stats[only].uniqueBytes += uint64(c.size)
+		}
+	}
+	return stats
+}
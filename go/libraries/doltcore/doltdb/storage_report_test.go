@@ -0,0 +1,88 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+)
+
+func TestStorageReport(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	defer dEnv.DoltDB.Close()
+
+	cliCtx, verr := commands.NewArgFreeCliContext(ctx, dEnv)
+	require.NoError(t, verr)
+
+	setup := []testCommand{
+		{commands.SqlCmd{}, []string{"-q", "CREATE TABLE big (pk int PRIMARY KEY, c varchar(100), INDEX idx_c (c));"}},
+		{commands.SqlCmd{}, []string{"-q", "INSERT INTO big WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 5000) SELECT i, concat('value number ', i) FROM n;"}},
+		{commands.SqlCmd{}, []string{"-q", "CREATE TABLE small (pk int PRIMARY KEY);"}},
+		{commands.SqlCmd{}, []string{"-q", "INSERT INTO small VALUES (1), (2);"}},
+		{commands.AddCmd{}, []string{"."}},
+		{commands.CommitCmd{}, []string{"-m", "created tables"}},
+		{commands.CheckoutCmd{}, []string{"-b", "feature"}},
+		{commands.SqlCmd{}, []string{"-q", "INSERT INTO small VALUES (3);"}},
+		{commands.AddCmd{}, []string{"."}},
+		{commands.CommitCmd{}, []string{"-m", "changed small"}},
+	}
+	for _, c := range setup {
+		exitCode := c.cmd.Exec(ctx, c.cmd.Name(), c.args, dEnv, cliCtx)
+		require.Equal(t, 0, exitCode)
+	}
+
+	report, err := dEnv.DoltDB.GetStorageReport(ctx)
+	require.NoError(t, err)
+
+	tables := make(map[string]doltdb.TableStorage)
+	for _, ts := range report.Tables {
+		assert.Equal(t, ts.Bytes, ts.UniqueBytes+ts.SharedBytes)
+		assert.NotZero(t, ts.Chunks)
+		tables[ts.Table+"."+ts.Index] = ts
+	}
+	require.Len(t, tables, 3)
+	big, idx, small := tables["big.PRIMARY"], tables["big.idx_c"], tables["small.PRIMARY"]
+	assert.Greater(t, big.Bytes, small.Bytes)
+	assert.Greater(t, idx.Bytes, small.Bytes)
+	assert.Greater(t, big.Chunks, uint64(1))
+	assert.Equal(t, big.Bytes/big.Chunks, big.AvgChunkSize)
+	assert.Equal(t, big.Bytes, big.UniqueBytes)
+	// both versions of small are counted
+	assert.Equal(t, uint64(2), small.Chunks)
+
+	refs := make(map[string]doltdb.RefStorage)
+	for _, rs := range report.Refs {
+		assert.LessOrEqual(t, rs.UniqueBytes, rs.Bytes)
+		assert.LessOrEqual(t, rs.Bytes, report.Bytes)
+		refs[rs.Ref] = rs
+	}
+	main, feature := refs["refs/heads/main"], refs["refs/heads/feature"]
+	assert.Greater(t, feature.Bytes, main.Bytes)
+	assert.Zero(t, main.UniqueBytes, "the history of main is part of the history of feature")
+	assert.NotZero(t, feature.UniqueBytes)
+	assert.Contains(t, refs, "workingSets/heads/main")
+
+	assert.Greater(t, report.Chunks, big.Chunks+idx.Chunks)
+	assert.Greater(t, report.StoredBytes, uint64(0))
+	assert.Equal(t, report.StoredBytes-min(report.StoredBytes, report.Bytes), report.ReclaimableBytes)
+}
@@ -162,6 +162,8 @@ var writeableSystemTables = []string{
 	SchemasTableName,
 	ProceduresTableName,
 	IgnoreTableName,
+	MaskingTableName,
+	SequencesTableName,
 	RebaseTableName,
 }
 
@@ -171,6 +173,8 @@ var persistedSystemTables = []string{
 	SchemasTableName,
 	ProceduresTableName,
 	IgnoreTableName,
+	MaskingTableName,
+	SequencesTableName,
 }
 
 var generatedSystemTables = []string{
@@ -309,11 +313,20 @@ const (
 	// MergeStatusTableName is the merge status system table name.
 	MergeStatusTableName = "dolt_merge_status"
 
+	// MigrationsTableName is the schema migrations system table name.
+	MigrationsTableName = "dolt_migrations"
+
 	// TagsTableName is the tags table name
 	TagsTableName = "dolt_tags"
 
 	IgnoreTableName = "dolt_ignore"
 
+	// MaskingTableName is the name of the system table that stores column masking rules.
+	MaskingTableName = "dolt_masking"
+
+	// SequencesTableName is the name of the system table that stores sequences.
+	SequencesTableName = "dolt_sequences"
+
 	// RebaseTableName is the rebase system table name.
 	RebaseTableName = "dolt_rebase"
 
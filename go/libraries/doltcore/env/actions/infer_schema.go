@@ -20,10 +20,14 @@ import (
 	"errors"
 	"io"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/planbuilder/dateparse"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
 	"github.com/google/uuid"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
@@ -40,8 +44,19 @@ type typeInfoSet map[typeinfo.TypeInfo]struct{}
 const (
 	maxUint24 = 1<<24 - 1
 	minInt24  = -1 << 23
+
+	// DefaultEnumMaxValues is the most distinct values a string column can have to be inferred as an ENUM.
+	DefaultEnumMaxValues = 16
+
+	// maxTrackedValues is the most distinct values of a column which are tracked to find its cardinality.
+	maxTrackedValues = 1 << 16
+	// maxAutoKeyColumns is the most columns a primary key proposed from the data can have.
+	maxAutoKeyColumns = 3
 )
 
+// ErrNoUniqueKey is returned when a primary key is requested from the data, but no combination of columns is unique.
+var ErrNoUniqueKey = errors.New("no unique combination of columns was found to use as the primary key")
+
 // InferenceArgs are arguments that can be passed to the schema inferrer to modify it's inference behavior.
 type InferenceArgs interface {
 	// ColNameMapper allows columns named X in the schema to be named Y in the inferred schema.
@@ -53,43 +68,125 @@ type InferenceArgs interface {
 	// a fractional component greater than or equal to 0.001 will be treated as a float (1.0 would be an int, 1.0009 would
 	// be an int, 1.001 would be a float, 1.1 would be a float, etc)
 	FloatThreshold() float64
+	// InferenceOptions returns the options of the inference beyond the float threshold.
+	InferenceOptions() InferenceOptions
+}
+
+// InferenceOptions are options of schema inference which are off by default.
+type InferenceOptions struct {
+	// Decimals infers numbers with a fractional component as DECIMAL(p,s), with the precision and scale observed in
+	// the data, rather than as floats.
+	Decimals bool
+	// EnumMaxValues, if positive, infers string columns with at most this many distinct values, which are repeated
+	// at least twice on average, as ENUMs of the values observed.
+	EnumMaxValues int
+	// DateFormat is a STR_TO_DATE format, such as %d/%m/%Y, used to infer dates and times which aren't in a format
+	// MySQL recognizes.
+	DateFormat string
+	// SampleRows, if positive, bounds the inference to the first SampleRows rows, each of which is read. Otherwise,
+	// the types of the values are inferred from rows sampled from the whole file, while the precision and scale of
+	// decimals, the values of enums and the uniqueness of primary keys are taken from every row.
+	SampleRows int
+	// PrimaryKey proposes a primary key of up to three columns whose values are unique in the data.
+	PrimaryKey bool
+}
+
+// InferenceReport describes what was observed of the columns of a file while inferring its schema.
+type InferenceReport struct {
+	RowsSampled int64          `json:"rows_sampled"`
+	Columns     []ColumnReport `json:"columns"`
+	PrimaryKey  []string       `json:"primary_key,omitempty"`
+}
+
+// ColumnReport describes what was observed of a column while inferring its schema.
+type ColumnReport struct {
+	Name string `json:"name"`
+	// Type is the type inferred for the column.
+	Type string `json:"type"`
+	// Candidates are the types of the values of the column.
+	Candidates []string `json:"candidates"`
+	Nulls      int64    `json:"nulls"`
+	// Cardinality is the number of distinct values of the column, which is a lower bound if CardinalityCapped is set.
+	Cardinality       int  `json:"cardinality"`
+	CardinalityCapped bool `json:"cardinality_capped,omitempty"`
+}
+
+// NormalizeDate parses |val| with the STR_TO_DATE format |format|, and returns it in a format MySQL recognizes.
+func NormalizeDate(val, format string) (string, bool) {
+	res, err := dateparse.ParseDateWithFormat(val, format)
+	if err != nil {
+		return "", false
+	}
+	str, ok := res.(string)
+	return str, ok
 }
 
 // InferColumnTypesFromTableReader will infer a data types from a table reader.
 func InferColumnTypesFromTableReader(ctx context.Context, rd table.ReadCloser, args InferenceArgs) (*schema.ColCollection, error) {
-	// for large imports, we want to sample a subset of the rows.
-	// skip through the file in an exponential manner
-	const exp = 1.02
+	cols, _, err := InferColumnTypesAndReportFromTableReader(ctx, rd, args)
+	return cols, err
+}
 
-	var curr, prev row.Row
+// InferColumnTypesAndReportFromTableReader infers data types from a table reader, as InferColumnTypesFromTableReader
+// does, and reports what was observed of each column.
+func InferColumnTypesAndReportFromTableReader(ctx context.Context, rd table.ReadCloser, args InferenceArgs) (*schema.ColCollection, *InferenceReport, error) {
 	i := newInferrer(rd.GetSchema(), args)
-OUTER:
-	for j := 0; true; j++ {
-		var err error
+	if err := i.sampleRows(ctx, rd); err != nil {
+		return nil, nil, err
+	}
+	return i.inferColumnTypes()
+}
 
-		next := int(math.Pow(exp, float64(j)))
-		for n := 0; n < next; n++ {
-			curr, err = rd.ReadRow(ctx)
+// sampleRows processes the rows of |rd|. The statistics of the values of each column are collected from every row,
+// while the types of the values are only inferred from a sample of the rows, unless SampleRows is given, in which
+// case only that many rows are read and every one of them is processed in full.
+func (i *inferrer) sampleRows(ctx context.Context, rd table.ReadCloser) error {
+	if i.opts.SampleRows > 0 {
+		for n := 0; n < i.opts.SampleRows; n++ {
+			r, err := rd.ReadRow(ctx)
 			if err == io.EOF {
-				break OUTER
+				return nil
 			} else if err != nil {
-				return nil, err
+				return err
+			}
+			if err = i.processRow(r, true); err != nil {
+				return err
 			}
-			prev = curr
-		}
-		if err = i.processRow(curr); err != nil {
-			return nil, err
 		}
+		return nil
 	}
 
-	// always process last row
-	if prev != nil {
-		if err := i.processRow(prev); err != nil {
-			return nil, err
+	// for large imports, we want to sample a subset of the rows.
+	// skip through the file in an exponential manner
+	const exp = 1.02
+
+	// Each row is processed once the next one is read, since the last row is always sampled.
+	var prev row.Row
+	var prevSampled bool
+	nextSample, j := 0, 0
+	for n := 0; ; n++ {
+		r, err := rd.ReadRow(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if prev != nil {
+			if err = i.processRow(prev, prevSampled); err != nil {
+				return err
+			}
+		}
+		prev, prevSampled = r, n == nextSample
+		if prevSampled {
+			j++
+			nextSample = n + int(math.Pow(exp, float64(j)))
 		}
 	}
 
-	return i.inferColumnTypes()
+	if prev != nil {
+		return i.processRow(prev, true)
+	}
+	return nil
 }
 
 type inferrer struct {
@@ -98,6 +195,28 @@ type inferrer struct {
 	nullable       *set.Uint64Set
 	mapper         rowconv.NameMapper
 	floatThreshold float64
+	opts           InferenceOptions
+
+	tags  []uint64
+	stats map[uint64]*columnStats
+	// rows are the values of the rows processed, kept to find a primary key
+	rows    [][]*string
+	rowsCnt int64
+	// sampledCnt is the number of rows whose types were inferred
+	sampledCnt int64
+}
+
+// columnStats are the statistics of the values of a column which inference beyond the type of each value needs.
+type columnStats struct {
+	nulls int64
+	// values are the distinct values of the column, until there are more than maxTrackedValues
+	values map[string]struct{}
+	capped bool
+
+	intDigits int
+	scale     int
+	// inexact is set if a number can't be represented exactly as a decimal, such as 1.5e3
+	inexact bool
 }
 
 func newInferrer(readerSch schema.Schema, args InferenceArgs) *inferrer {
@@ -107,21 +226,46 @@ func newInferrer(readerSch schema.Schema, args InferenceArgs) *inferrer {
 		return false, nil
 	})
 
+	var tags []uint64
+	stats := make(map[uint64]*columnStats, len(inferSets))
+	_ = readerSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		tags = append(tags, tag)
+		stats[tag] = &columnStats{values: make(map[string]struct{})}
+		return false, nil
+	})
+
 	return &inferrer{
 		readerSch:      readerSch,
 		inferSets:      inferSets,
 		nullable:       set.NewUint64Set(nil),
 		mapper:         args.ColNameMapper(),
 		floatThreshold: args.FloatThreshold(),
+		opts:           args.InferenceOptions(),
+		tags:           tags,
+		stats:          stats,
 	}
 }
 
-// inferColumnTypes returns TableReader's columns with updated TypeInfo and columns names
-func (inf *inferrer) inferColumnTypes() (*schema.ColCollection, error) {
+// inferColumnTypes returns TableReader's columns with updated TypeInfo and columns names, and a report of what was
+// observed of each column
+func (inf *inferrer) inferColumnTypes() (*schema.ColCollection, *InferenceReport, error) {
+	report := &InferenceReport{RowsSampled: inf.sampledCnt}
 
 	inferredTypes := make(map[uint64]typeinfo.TypeInfo)
-	for tag, ts := range inf.inferSets {
-		inferredTypes[tag] = findCommonType(ts)
+	for _, tag := range inf.tags {
+		ts := inf.inferSets[tag]
+		report.Columns = append(report.Columns, ColumnReport{
+			Candidates:        typeNames(ts),
+			Nulls:             inf.stats[tag].nulls,
+			Cardinality:       len(inf.stats[tag].values),
+			CardinalityCapped: inf.stats[tag].capped,
+		})
+
+		ti, err := inf.refineType(findCommonType(ts), inf.stats[tag])
+		if err != nil {
+			return nil, nil, err
+		}
+		inferredTypes[tag] = ti
 	}
 
 	var cols []schema.Column
@@ -134,26 +278,225 @@ func (inf *inferrer) inferColumnTypes() (*schema.ColCollection, error) {
 		// for large imports, it is possible to miss all the null values, so we cannot accurately add not null constraint
 		col.Constraints = []schema.ColConstraint(nil)
 
+		report.Columns[len(cols)].Name = col.Name
+		report.Columns[len(cols)].Type = col.TypeInfo.ToSqlType().String()
 		cols = append(cols, col)
 		return false, nil
 	})
 
-	return schema.NewColCollection(cols...), nil
+	if inf.opts.PrimaryKey {
+		pk, err := inf.findPrimaryKey(cols)
+		if err != nil {
+			return nil, nil, err
+		}
+		report.PrimaryKey = pk
+	}
+
+	return schema.NewColCollection(cols...), report, nil
+}
+
+// refineType returns the type of a column given the common type of its values and the statistics of its values,
+// which decide whether a float is a DECIMAL and whether a string is an ENUM.
+func (inf *inferrer) refineType(ti typeinfo.TypeInfo, stats *columnStats) (typeinfo.TypeInfo, error) {
+	switch ti {
+	case typeinfo.Float32Type, typeinfo.Float64Type:
+		if !inf.opts.Decimals || stats.inexact {
+			return ti, nil
+		}
+		precision := stats.intDigits + stats.scale
+		if precision == 0 {
+			precision = 1
+		}
+		if precision > gmstypes.DecimalTypeMaxPrecision || stats.scale > gmstypes.DecimalTypeMaxScale {
+			return ti, nil
+		}
+		decType, err := gmstypes.CreateColumnDecimalType(uint8(precision), uint8(stats.scale))
+		if err != nil {
+			return nil, err
+		}
+		return typeinfo.FromSqlType(decType)
+
+	case typeinfo.StringDefaultType:
+		// an enum is only inferred for values which are repeated, on average, at least twice
+		distinct := len(stats.values)
+		if inf.opts.EnumMaxValues <= 0 || stats.capped || distinct == 0 || distinct > inf.opts.EnumMaxValues ||
+			inf.rowsCnt-stats.nulls < 2*int64(distinct) {
+			return ti, nil
+		}
+		values := make([]string, 0, len(stats.values))
+		for v := range stats.values {
+			if v != strings.TrimSpace(v) {
+				// trailing spaces are stripped from enum values
+				return ti, nil
+			}
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		enumType, err := gmstypes.CreateEnumType(values, sql.Collation_Default)
+		if err != nil {
+			return ti, nil
+		}
+		return typeinfo.FromSqlType(enumType)
+	}
+	return ti, nil
+}
+
+// findPrimaryKey returns the first single column, pair of columns, or triple of columns, in column order, whose values
+// are never null and are unique in the rows processed. Floating point, JSON and TEXT columns are not considered.
+func (inf *inferrer) findPrimaryKey(cols []schema.Column) ([]string, error) {
+	var candidates []int
+	for i, col := range cols {
+		switch col.TypeInfo {
+		case typeinfo.Float32Type, typeinfo.Float64Type, typeinfo.JSONType, typeinfo.TextType:
+			continue
+		}
+		if inf.stats[inf.tags[i]].nulls == 0 {
+			candidates = append(candidates, i)
+		}
+	}
+
+	var found []int
+	var search func(start int, key []int) bool
+	search = func(start int, key []int) bool {
+		if len(key) == cap(key) {
+			if inf.isUnique(key) {
+				found = append([]int(nil), key...)
+				return true
+			}
+			return false
+		}
+		for i := start; i < len(candidates); i++ {
+			if search(i+1, append(key, candidates[i])) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for n := 1; n <= maxAutoKeyColumns && n <= len(candidates); n++ {
+		if search(0, make([]int, 0, n)) {
+			names := make([]string, len(found))
+			for i, idx := range found {
+				names[i] = cols[idx].Name
+			}
+			return names, nil
+		}
+	}
+	return nil, ErrNoUniqueKey
+}
+
+// isUnique returns whether the values of the columns at the indexes given are unique in the rows processed.
+func (inf *inferrer) isUnique(cols []int) bool {
+	seen := make(map[string]struct{}, len(inf.rows))
+	var b strings.Builder
+	for _, r := range inf.rows {
+		b.Reset()
+		for _, idx := range cols {
+			b.WriteString(strconv.Itoa(len(*r[idx])))
+			b.WriteByte(':')
+			b.WriteString(*r[idx])
+		}
+		if _, ok := seen[b.String()]; ok {
+			return false
+		}
+		seen[b.String()] = struct{}{}
+	}
+	return true
 }
 
-func (inf *inferrer) processRow(r row.Row) error {
-	_, err := r.IterSchema(inf.readerSch, func(tag uint64, val types.Value) (stop bool, err error) {
-		if val == nil {
+// processRow collects the statistics of the values of |r|, and infers the types of its values if |sampled| is true.
+func (inf *inferrer) processRow(r row.Row, sampled bool) error {
+	inf.rowsCnt++
+	if sampled {
+		inf.sampledCnt++
+	}
+	var rowVals []*string
+	if inf.opts.PrimaryKey {
+		rowVals = make([]*string, len(inf.tags))
+	}
+
+	for i, tag := range inf.tags {
+		val, _ := r.GetColVal(tag)
+		stats := inf.stats[tag]
+		if val == nil || types.IsNull(val) {
 			inf.nullable.Add(tag)
-			return false, nil
+			stats.nulls++
+			continue
 		}
+
 		strVal := string(val.(types.String))
+		if rowVals != nil {
+			rowVals[i] = &strVal
+		}
+
+		if !stats.capped {
+			if _, ok := stats.values[strVal]; !ok {
+				if len(stats.values) < maxTrackedValues {
+					stats.values[strVal] = struct{}{}
+				} else {
+					stats.capped = true
+				}
+			}
+		}
+
+		if inf.opts.Decimals {
+			trimmed := strings.TrimSpace(strVal)
+			stats.observeNumber(trimmed, leastPermissiveNumericType(trimmed, inf.floatThreshold))
+		}
+		if !sampled {
+			continue
+		}
+
 		typeInfo := leastPermissiveType(strVal, inf.floatThreshold)
+		if inf.opts.DateFormat != "" && typeInfo == typeinfo.StringDefaultType {
+			if normalized, ok := NormalizeDate(strVal, inf.opts.DateFormat); ok {
+				if chronoType := leastPermissiveChronoType(normalized); chronoType != typeinfo.UnknownType {
+					typeInfo = chronoType
+				}
+			}
+		}
 		inf.inferSets[tag][typeInfo] = struct{}{}
-		return false, nil
-	})
+	}
+
+	if rowVals != nil {
+		inf.rows = append(inf.rows, rowVals)
+	}
+	return nil
+}
+
+// observeNumber records the digits of a numeric value, from which the precision and scale of a DECIMAL are inferred.
+func (stats *columnStats) observeNumber(strVal string, ti typeinfo.TypeInfo) {
+	switch ti {
+	case typeinfo.Int32Type, typeinfo.Int64Type, typeinfo.Float32Type, typeinfo.Float64Type:
+	default:
+		return
+	}
 
-	return err
+	strVal = strings.TrimLeft(strVal, "+-")
+	if strings.ContainsAny(strVal, "eE") {
+		stats.inexact = true
+		return
+	}
+	intPart, fracPart, _ := strings.Cut(strVal, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart) > stats.intDigits {
+		stats.intDigits = len(intPart)
+	}
+	if len(fracPart) > stats.scale {
+		stats.scale = len(fracPart)
+	}
+}
+
+// typeNames returns the sorted names of the types given, ignoring unknown types.
+func typeNames(ts typeInfoSet) []string {
+	names := make([]string, 0, len(ts))
+	for ti := range ts {
+		if ti != typeinfo.UnknownType {
+			names = append(names, ti.ToSqlType().String())
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
 func leastPermissiveType(strVal string, floatThreshold float64) typeinfo.TypeInfo {
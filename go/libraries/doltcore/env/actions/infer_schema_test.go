@@ -20,6 +20,7 @@ import (
 	"math"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -344,6 +345,7 @@ var identityMapper = make(rowconv.NameMapper)
 type testInferenceArgs struct {
 	ColMapper      rowconv.NameMapper
 	floatThreshold float64
+	opts           InferenceOptions
 }
 
 func (tia testInferenceArgs) ColNameMapper() rowconv.NameMapper {
@@ -354,6 +356,10 @@ func (tia testInferenceArgs) FloatThreshold() float64 {
 	return tia.floatThreshold
 }
 
+func (tia testInferenceArgs) InferenceOptions() InferenceOptions {
+	return tia.opts
+}
+
 func TestInferSchema(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -446,26 +452,9 @@ func TestInferSchema(t *testing.T) {
 		},
 	}
 
-	const importFilePath = "/Users/home/datasets/test/import_file.csv"
-
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			dEnv := dtestutils.CreateTestEnv()
-			defer dEnv.DoltDB.Close()
-
-			wrCl, err := dEnv.FS.OpenForWrite(importFilePath, os.ModePerm)
-			require.NoError(t, err)
-			_, err = wrCl.Write([]byte(test.csvContents))
-			require.NoError(t, err)
-			err = wrCl.Close()
-			require.NoError(t, err)
-
-			rdCl, err := dEnv.FS.OpenForRead(importFilePath)
-			require.NoError(t, err)
-
-			csvRd, err := csv.NewCSVReader(types.Format_Default, rdCl, csv.NewCSVInfo())
-			require.NoError(t, err)
-
+			csvRd := newTestCSVReader(t, test.csvContents)
 			allCols, err := InferColumnTypesFromTableReader(context.Background(), csvRd, test.infArgs)
 			require.NoError(t, err)
 
@@ -491,3 +480,202 @@ func TestInferSchema(t *testing.T) {
 		})
 	}
 }
+
+func newTestCSVReader(t *testing.T, csvContents string) *csv.CSVReader {
+	const importFilePath = "/Users/home/datasets/test/import_file.csv"
+
+	dEnv := dtestutils.CreateTestEnv()
+	t.Cleanup(func() { dEnv.DoltDB.Close() })
+
+	wrCl, err := dEnv.FS.OpenForWrite(importFilePath, os.ModePerm)
+	require.NoError(t, err)
+	_, err = wrCl.Write([]byte(csvContents))
+	require.NoError(t, err)
+	err = wrCl.Close()
+	require.NoError(t, err)
+
+	rdCl, err := dEnv.FS.OpenForRead(importFilePath)
+	require.NoError(t, err)
+
+	csvRd, err := csv.NewCSVReader(types.Format_Default, rdCl, csv.NewCSVInfo())
+	require.NoError(t, err)
+	return csvRd
+}
+
+var decimalsAndEnums = `id,price,rate,status,name,day
+1,10.5,1.5e3,open,alice,31/12/2020
+2,-3.25,2,closed,bob,01/01/2021
+3,1000,3,open,carol,15/06/2021
+4,0.125,4,open,dave,
+5,7,5,closed,erin,02/02/2022`
+
+var compositeKey = `region,year,amount
+east,2020,1
+east,2021,2
+west,2020,1
+west,2021,2`
+
+var noUniqueKey = `a,b
+1,1
+1,1`
+
+func TestInferSchemaWithOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		csvContents string
+		opts        InferenceOptions
+		expTypes    map[string]string
+		expPk       []string
+		expErr      error
+	}{
+		{
+			name:        "defaults",
+			csvContents: decimalsAndEnums,
+			expTypes: map[string]string{
+				"id":     "int",
+				"price":  "float",
+				"rate":   "float",
+				"status": "varchar(1023)",
+				"name":   "varchar(1023)",
+				"day":    "varchar(1023)",
+			},
+		},
+		{
+			name:        "decimals, enums and dates",
+			csvContents: decimalsAndEnums,
+			opts: InferenceOptions{
+				Decimals:      true,
+				EnumMaxValues: DefaultEnumMaxValues,
+				DateFormat:    "%d/%m/%Y",
+			},
+			expTypes: map[string]string{
+				"id":     "int",
+				"price":  "decimal(7,3)",
+				"rate":   "float",
+				"status": "enum('closed','open')",
+				"name":   "varchar(1023)",
+				"day":    "date",
+			},
+		},
+		{
+			name:        "sampled rows",
+			csvContents: decimalsAndEnums,
+			opts: InferenceOptions{
+				Decimals:   true,
+				SampleRows: 2,
+			},
+			expTypes: map[string]string{
+				"id":     "int",
+				"price":  "decimal(4,2)",
+				"rate":   "float",
+				"status": "varchar(1023)",
+				"name":   "varchar(1023)",
+				"day":    "varchar(1023)",
+			},
+		},
+		{
+			name:        "single column primary key",
+			csvContents: decimalsAndEnums,
+			opts:        InferenceOptions{PrimaryKey: true},
+			expPk:       []string{"id"},
+		},
+		{
+			name:        "composite primary key",
+			csvContents: compositeKey,
+			opts:        InferenceOptions{PrimaryKey: true},
+			expPk:       []string{"region", "year"},
+		},
+		{
+			name:        "no unique key",
+			csvContents: noUniqueKey,
+			opts:        InferenceOptions{PrimaryKey: true},
+			expErr:      ErrNoUniqueKey,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			csvRd := newTestCSVReader(t, test.csvContents)
+			args := testInferenceArgs{ColMapper: identityMapper, opts: test.opts}
+			allCols, report, err := InferColumnTypesAndReportFromTableReader(context.Background(), csvRd, args)
+			if test.expErr != nil {
+				require.ErrorIs(t, err, test.expErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for name, expType := range test.expTypes {
+				col, ok := allCols.GetByName(name)
+				require.True(t, ok, "column not found: %s", name)
+				assert.Equal(t, expType, col.TypeInfo.ToSqlType().String(), "column: %s", name)
+			}
+			assert.Equal(t, test.expPk, report.PrimaryKey)
+		})
+	}
+}
+
+func TestInferenceStatsFromEveryRow(t *testing.T) {
+	// Only the first rows of a file, and then rows further and further apart, are sampled. Row 36 isn't sampled.
+	var b strings.Builder
+	b.WriteString("id,price\n")
+	for i := 0; i < 50; i++ {
+		switch i {
+		case 36:
+			b.WriteString("1,1234.567\n")
+		default:
+			fmt.Fprintf(&b, "%d,%d.5\n", i, i)
+		}
+	}
+
+	// the float price column can't be a key, and the ids aren't unique
+	csvRd := newTestCSVReader(t, b.String())
+	args := testInferenceArgs{ColMapper: identityMapper, opts: InferenceOptions{PrimaryKey: true}}
+	_, _, err := InferColumnTypesAndReportFromTableReader(context.Background(), csvRd, args)
+	require.ErrorIs(t, err, ErrNoUniqueKey)
+
+	csvRd = newTestCSVReader(t, b.String())
+	args = testInferenceArgs{ColMapper: identityMapper, opts: InferenceOptions{Decimals: true}}
+	allCols, report, err := InferColumnTypesAndReportFromTableReader(context.Background(), csvRd, args)
+	require.NoError(t, err)
+	assert.Less(t, report.RowsSampled, int64(50))
+	price, ok := allCols.GetByName("price")
+	require.True(t, ok)
+	assert.Equal(t, "decimal(7,3)", price.TypeInfo.ToSqlType().String())
+}
+
+func TestInferenceReport(t *testing.T) {
+	csvRd := newTestCSVReader(t, decimalsAndEnums)
+	args := testInferenceArgs{ColMapper: identityMapper, opts: InferenceOptions{EnumMaxValues: DefaultEnumMaxValues}}
+	_, report, err := InferColumnTypesAndReportFromTableReader(context.Background(), csvRd, args)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), report.RowsSampled)
+	require.Len(t, report.Columns, 6)
+
+	price := report.Columns[1]
+	assert.Equal(t, "price", price.Name)
+	assert.Equal(t, "float", price.Type)
+	assert.Equal(t, []string{"float", "int"}, price.Candidates)
+	assert.Equal(t, 5, price.Cardinality)
+
+	status := report.Columns[3]
+	assert.Equal(t, "enum('closed','open')", status.Type)
+	assert.Equal(t, 2, status.Cardinality)
+
+	day := report.Columns[5]
+	assert.Equal(t, int64(1), day.Nulls)
+	assert.Equal(t, 4, day.Cardinality)
+}
+
+func TestNormalizeDate(t *testing.T) {
+	date, ok := NormalizeDate("31/12/2020", "%d/%m/%Y")
+	assert.True(t, ok)
+	assert.Equal(t, "2020-12-31", date)
+
+	datetime, ok := NormalizeDate("12/31/2020 13:45", "%m/%d/%Y %H:%i")
+	assert.True(t, ok)
+	assert.Equal(t, "2020-12-31 13:45:00", datetime)
+
+	_, ok = NormalizeDate("not a date", "%d/%m/%Y")
+	assert.False(t, ok)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
 	"github.com/dolthub/dolt/go/libraries/events"
 	"github.com/dolthub/dolt/go/libraries/utils/earl"
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
@@ -98,6 +99,7 @@ func Push(ctx context.Context, tempTableDir string, mode ref.UpdateMode, destRef
 // This includes if there is a new remote branch created, upstream is set or push was rejected for a branch.
 func DoPush(ctx context.Context, pushMeta *env.PushOptions, progStarter ProgStarter, progStopper ProgStopper) (returnMsg string, err error) {
 	var successPush, setUpstreamPush, failedPush []string
+	var remoteRejected, nonFastForward bool
 	for _, targets := range pushMeta.Targets {
 		err = push(ctx, pushMeta.Rsr, pushMeta.TmpDir, pushMeta.SrcDb, pushMeta.DestDb, pushMeta.Remote, targets, progStarter, progStopper)
 		if err == nil {
@@ -111,8 +113,19 @@ func DoPush(ctx context.Context, pushMeta *env.PushOptions, progStarter ProgStar
 				successPush = append(successPush, fmt.Sprintf(" * [new branch]          %s -> %s", targets.SrcRef.GetPath(), targets.DestRef.GetPath()))
 			}
 
+		} else if rejections := remotestorage.PushRejections(err); len(rejections) > 0 {
+			refs := fmt.Sprintf("%s -> %s", targets.SrcRef.GetPath(), targets.DestRef.GetPath())
+			if targets.SrcRef == ref.EmptyBranchRef {
+				refs = targets.DestRef.GetPath()
+			}
+			for _, r := range rejections {
+				failedPush = append(failedPush, fmt.Sprintf(" ! [remote rejected]     %s (%s)", refs, r.Reason))
+			}
+			remoteRejected = true
+			continue
 		} else if errors.Is(err, doltdb.ErrIsAhead) || errors.Is(err, ErrCantFF) || errors.Is(err, datas.ErrMergeNeeded) {
 			failedPush = append(failedPush, fmt.Sprintf(" ! [rejected]            %s -> %s (non-fast-forward)", targets.SrcRef.GetPath(), targets.DestRef.GetPath()))
+			nonFastForward = true
 			continue
 		} else if !errors.Is(err, doltdb.ErrUpToDate) {
 			// this will allow getting successful push messages along with the error of current push
@@ -133,6 +146,9 @@ func DoPush(ctx context.Context, pushMeta *env.PushOptions, progStarter ProgStar
 	}
 
 	returnMsg, err = buildReturnMsg(successPush, setUpstreamPush, failedPush, pushMeta.Remote.Url, err)
+	if remoteRejected && !nonFastForward && env.ErrFailedToPush.Is(err) {
+		err = env.ErrRemoteRejectedPush.New(pushMeta.Remote.Url)
+	}
 	return
 }
 
@@ -200,7 +216,7 @@ func deleteRemoteBranch(ctx context.Context, toDelete, remoteRef ref.DoltRef, lo
 	err := DeleteRemoteBranch(ctx, toDelete.(ref.BranchRef), remoteRef.(ref.RemoteRef), localDB, remoteDB, force)
 
 	if err != nil {
-		return fmt.Errorf("%w; '%s' from remote '%s'; %s", ErrFailedToDeleteRemote, toDelete.String(), remote.Name, err)
+		return fmt.Errorf("%w; '%s' from remote '%s'; %w", ErrFailedToDeleteRemote, toDelete.String(), remote.Name, err)
 	}
 
 	return nil
@@ -244,7 +260,7 @@ func PushToRemoteBranch(ctx context.Context, rsr env.RepoStateReader, tempTableD
 	case doltdb.ErrUpToDate, doltdb.ErrIsAhead, ErrCantFF, datas.ErrMergeNeeded, datas.ErrDirtyWorkspace:
 		return err
 	default:
-		return fmt.Errorf("%w; %s", ErrUnknownPushErr, err.Error())
+		return fmt.Errorf("%w; %w", ErrUnknownPushErr, err)
 	}
 }
 
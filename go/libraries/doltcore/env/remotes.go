@@ -66,6 +66,9 @@ var ErrFailedToPush = goerrors.NewKind("error: failed to push some refs to '%s'\
 	"hint: Updates were rejected because the tip of your current branch is behind\n" +
 	"hint: its remote counterpart. Integrate the remote changes (e.g.\n" +
 	"hint: 'dolt pull ...') before pushing again.\n")
+var ErrRemoteRejectedPush = goerrors.NewKind("error: failed to push some refs to '%s'\n" +
+	"hint: Updates were rejected by the remote, such as for breaking the rules\n" +
+	"hint: of a protected branch. See the reasons given for each ref above.\n")
 
 func IsEmptyRemote(r Remote) bool {
 	return len(r.Name) == 0 && len(r.Url) == 0 && r.FetchSpecs == nil && r.Params == nil
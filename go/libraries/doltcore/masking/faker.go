@@ -0,0 +1,143 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package masking
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// faker generates a fake value from a seed. The same seed always produces the same value.
+type faker func(seed uint64) string
+
+var firstNames = []string{
+	"Alice", "Amara", "Ben", "Carlos", "Chen", "Dana", "Elena", "Farid", "Grace", "Hiro",
+	"Ines", "Jamal", "Kira", "Liam", "Maya", "Noah", "Olga", "Priya", "Quinn", "Rosa",
+	"Sam", "Tariq", "Uma", "Victor", "Wen", "Ximena", "Yusuf", "Zoe",
+}
+
+var lastNames = []string{
+	"Abbott", "Bauer", "Castillo", "Dubois", "Eriksen", "Fischer", "Garcia", "Haddad", "Ito", "Jensen",
+	"Kowalski", "Lopez", "Moreau", "Nakamura", "Okafor", "Patel", "Quintero", "Rossi", "Schmidt", "Tanaka",
+	"Ueda", "Varga", "Walsh", "Xu", "Yilmaz", "Zimmerman",
+}
+
+var cities = []string{
+	"Ashford", "Brookside", "Cedar Falls", "Dunmore", "Eastwick", "Fairview", "Glenwood", "Harbor City",
+	"Ironton", "Juniper", "Kingsport", "Lakemont", "Millbrook", "Northgate", "Oakridge", "Pinecrest",
+	"Riverton", "Springdale", "Westfield",
+}
+
+var streets = []string{
+	"Maple", "Oak", "Pine", "Cedar", "Elm", "Birch", "Willow", "Lake", "Hill", "Park", "Sunset", "River",
+}
+
+var streetSuffixes = []string{"St", "Ave", "Rd", "Blvd", "Ln", "Way", "Ct"}
+
+var companyWords = []string{
+	"Acme", "Apex", "Blue Ridge", "Crescent", "Evergreen", "Granite", "Horizon", "Keystone", "Lighthouse",
+	"Meridian", "Northwind", "Pinnacle", "Redwood", "Summit", "Vertex",
+}
+
+var companySuffixes = []string{"Inc", "LLC", "Group", "Partners", "Holdings", "Labs"}
+
+var emailDomains = []string{"example.com", "example.net", "example.org"}
+
+var loremWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod",
+	"tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua",
+}
+
+// pick returns an element of |list| selected by |seed| and the next seed.
+func pick(list []string, seed uint64) (string, uint64) {
+	return list[seed%uint64(len(list))], seed / uint64(len(list))
+}
+
+var fakers = map[string]faker{
+	"first_name": func(seed uint64) string {
+		s, _ := pick(firstNames, seed)
+		return s
+	},
+	"last_name": func(seed uint64) string {
+		s, _ := pick(lastNames, seed)
+		return s
+	},
+	"name": func(seed uint64) string {
+		first, seed := pick(firstNames, seed)
+		last, _ := pick(lastNames, seed)
+		return first + " " + last
+	},
+	"email": func(seed uint64) string {
+		first, seed := pick(firstNames, seed)
+		last, seed := pick(lastNames, seed)
+		domain, seed := pick(emailDomains, seed)
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), seed%1000, domain)
+	},
+	"phone": func(seed uint64) string {
+		return fmt.Sprintf("555-%03d-%04d", seed%1000, (seed/1000)%10000)
+	},
+	"city": func(seed uint64) string {
+		s, _ := pick(cities, seed)
+		return s
+	},
+	"street_address": func(seed uint64) string {
+		street, seed := pick(streets, seed)
+		suffix, seed := pick(streetSuffixes, seed)
+		return fmt.Sprintf("%d %s %s", seed%9999+1, street, suffix)
+	},
+	"company": func(seed uint64) string {
+		word, seed := pick(companyWords, seed)
+		suffix, _ := pick(companySuffixes, seed)
+		return word + " " + suffix
+	},
+	"word": func(seed uint64) string {
+		s, _ := pick(loremWords, seed)
+		return s
+	},
+	"text": func(seed uint64) string {
+		words := make([]string, 6)
+		for i := range words {
+			words[i], seed = pick(loremWords, seed)
+		}
+		return strings.Join(words, " ")
+	},
+}
+
+// defaultFakeKind is the kind of fake value used when a fake rule has no argument.
+const defaultFakeKind = "word"
+
+// fakerForKind returns the faker for the fake value kind |kind|.
+func fakerForKind(kind string) (faker, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		kind = defaultFakeKind
+	}
+	f, ok := fakers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake value kind '%s', valid kinds are %s", kind, strings.Join(FakeKinds(), ", "))
+	}
+	return f, nil
+}
+
+// FakeKinds returns the kinds of fake values supported by the fake transform, in sorted order.
+func FakeKinds() []string {
+	kinds := make([]string, 0, len(fakers))
+	for k := range fakers {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
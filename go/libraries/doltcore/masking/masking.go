@@ -0,0 +1,287 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package masking implements column-level data masking. Masking rules are stored in the dolt_masking system table and
+// map a (table, column) pair to a Transform. Every transform is deterministic, so the same input value is always
+// masked to the same output value, which keeps masked data consistent across tables, commits and exports.
+package masking
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+)
+
+// Transform is the kind of masking applied to a column.
+type Transform string
+
+const (
+	// TransformNull replaces every value with NULL.
+	TransformNull Transform = "null"
+	// TransformHash replaces every value with a salted SHA-256 hash of the value. The argument is the salt, which is
+	// required, since the unsalted hashes of values with few possibilities, such as phone numbers, are easily reversed.
+	TransformHash Transform = "hash"
+	// TransformFixed replaces every value with the rule's argument.
+	TransformFixed Transform = "fixed"
+	// TransformFake replaces every value with a realistic looking fake value. The argument is of the form
+	// "<kind>:<salt>", where kind names the kind of fake value, e.g. "email" or "last_name", and may be empty. The fake
+	// value is chosen deterministically from a salted hash of the original value, and the salt is required for the same
+	// reason as for TransformHash.
+	TransformFake Transform = "fake"
+	// TransformTruncate keeps only the first N characters of a value, where N is the rule's argument.
+	TransformTruncate Transform = "truncate"
+)
+
+// defaultTruncateLength is the number of characters kept by TransformTruncate when no argument is given.
+const defaultTruncateLength = 1
+
+var transforms = map[Transform]struct{}{
+	TransformNull:     {},
+	TransformHash:     {},
+	TransformFixed:    {},
+	TransformFake:     {},
+	TransformTruncate: {},
+}
+
+// ParseTransform returns the Transform named by |s|, ignoring case.
+func ParseTransform(s string) (Transform, error) {
+	t := Transform(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := transforms[t]; !ok {
+		return "", fmt.Errorf("unknown masking transform '%s', valid transforms are null, hash, fixed, fake and truncate", s)
+	}
+	return t, nil
+}
+
+// Rule is a masking rule for a single column.
+type Rule struct {
+	Table     string
+	Column    string
+	Transform Transform
+	// Argument parameterizes the transform. Its meaning depends on the transform, and it may be empty.
+	Argument string
+}
+
+// NewRule returns a new Rule, validating the transform and its argument.
+func NewRule(table, column, transform, argument string) (Rule, error) {
+	t, err := ParseTransform(transform)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	r := Rule{Table: table, Column: column, Transform: t, Argument: argument}
+	switch t {
+	case TransformTruncate:
+		if argument != "" {
+			n, err := strconv.Atoi(argument)
+			if err != nil || n < 0 {
+				return Rule{}, fmt.Errorf("invalid argument '%s' for masking transform truncate on %s.%s: expected a non-negative integer", argument, table, column)
+			}
+		}
+	case TransformHash:
+		if argument == "" {
+			return Rule{}, fmt.Errorf("masking transform hash on %s.%s requires a salt as its argument", table, column)
+		}
+	case TransformFake:
+		kind, salt, ok := strings.Cut(argument, fakeSaltSeparator)
+		if !ok || salt == "" {
+			return Rule{}, fmt.Errorf("masking transform fake on %s.%s requires an argument of the form '<kind>:<salt>'", table, column)
+		}
+		if _, err := fakerForKind(kind); err != nil {
+			return Rule{}, fmt.Errorf("invalid argument for masking transform fake on %s.%s: %w", table, column, err)
+		}
+	}
+
+	return r, nil
+}
+
+// fakeSaltSeparator separates the kind of fake value from the salt in the argument of TransformFake.
+const fakeSaltSeparator = ":"
+
+// minUniqueHashLength is the number of hex digits of a hash, 64 bits worth, which a column must hold for masking it
+// with TransformHash to keep its values unique.
+const minUniqueHashLength = 16
+
+// ValidateSchema returns an error if applying |r| to the rows of a table with the schema |sch| could produce rows the
+// table cannot hold: NULLs in a NOT NULL column, or duplicate values in a primary key or unique index column. Only
+// TransformHash keeps distinct values distinct, and only for text columns which can hold enough of the hash. Rules for
+// columns the schema doesn't have are valid, since they are not applied.
+func (r Rule) ValidateSchema(sch schema.Schema) error {
+	col, ok := sch.GetAllCols().GetByNameCaseInsensitive(r.Column)
+	if !ok {
+		return nil
+	}
+	typ := col.TypeInfo.ToSqlType()
+
+	if r.Transform == TransformNull && !col.IsNullable() {
+		return fmt.Errorf("cannot mask NOT NULL column %s.%s with transform null", r.Table, col.Name)
+	}
+	if !isUniqueColumn(sch, col) {
+		return nil
+	}
+	if r.Transform == TransformHash && types.IsText(typ) {
+		st, ok := typ.(sql.StringType)
+		if !ok || st.MaxCharacterLength() >= minUniqueHashLength {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot mask column %s.%s of type %s with transform %s: the column is part of a primary key or "+
+		"unique index, and the transform can mask different values to the same value; use transform hash on a text "+
+		"column of at least %d characters", r.Table, col.Name, typ.String(), r.Transform, minUniqueHashLength)
+}
+
+// isUniqueColumn returns whether |col| is part of the primary key or of a unique index of |sch|.
+func isUniqueColumn(sch schema.Schema, col schema.Column) bool {
+	if col.IsPartOfPK {
+		return true
+	}
+	for _, idx := range sch.Indexes().AllIndexes() {
+		if !idx.IsUnique() {
+			continue
+		}
+		for _, tag := range idx.IndexedColumnTags() {
+			if tag == col.Tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply masks |v|, a value of the SQL type |typ|. NULL values are never masked, so a masked column keeps the same
+// nullability as the original. The returned value is converted to |typ|.
+func (r Rule) Apply(v interface{}, typ sql.Type) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch r.Transform {
+	case TransformNull:
+		return nil, nil
+	case TransformFixed:
+		return r.convert(r.Argument, typ)
+	case TransformHash:
+		sum := sha256.Sum256([]byte(r.Argument + valueString(v)))
+		if types.IsNumber(typ) {
+			return r.numberFromHash(sum, typ)
+		}
+		return r.truncateToType(hex.EncodeToString(sum[:]), typ)
+	case TransformFake:
+		kind, salt, _ := strings.Cut(r.Argument, fakeSaltSeparator)
+		sum := sha256.Sum256([]byte(salt + valueString(v)))
+		if types.IsNumber(typ) {
+			return r.numberFromHash(sum, typ)
+		}
+		fake, err := fakerForKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		return r.truncateToType(fake(binary.BigEndian.Uint64(sum[:8])), typ)
+	case TransformTruncate:
+		if !types.IsText(typ) {
+			return nil, fmt.Errorf("masking transform truncate is not supported for column %s.%s of type %s", r.Table, r.Column, typ.String())
+		}
+		n := defaultTruncateLength
+		if r.Argument != "" {
+			var err error
+			n, err = strconv.Atoi(r.Argument)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return r.truncate(valueString(v), n, typ)
+	default:
+		return nil, fmt.Errorf("unknown masking transform '%s'", r.Transform)
+	}
+}
+
+// numberFromHash derives a non-negative number that fits in |typ| from |sum|. Smaller numbers are tried until one
+// fits, so narrow types such as TINYINT or DECIMAL(3,1) get a correspondingly small value.
+func (r Rule) numberFromHash(sum [sha256.Size]byte, typ sql.Type) (interface{}, error) {
+	n := binary.BigEndian.Uint64(sum[:8])
+	for _, mod := range []uint64{1e18, 1e9, 1e4, 1e2, 1e1, 1} {
+		converted, inRange, err := typ.Convert(n % mod)
+		if err == nil && inRange == sql.InRange {
+			return converted, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to mask column %s.%s of type %s with transform %s", r.Table, r.Column, typ.String(), r.Transform)
+}
+
+// truncateToType shortens |s| so that it fits in a string column of type |typ| and converts it to |typ|.
+func (r Rule) truncateToType(s string, typ sql.Type) (interface{}, error) {
+	if st, ok := typ.(sql.StringType); ok {
+		return r.truncate(s, int(st.MaxCharacterLength()), typ)
+	}
+	return r.convert(s, typ)
+}
+
+func (r Rule) truncate(s string, n int, typ sql.Type) (interface{}, error) {
+	if runes := []rune(s); len(runes) > n {
+		s = string(runes[:n])
+	}
+	return r.convert(s, typ)
+}
+
+func (r Rule) convert(v interface{}, typ sql.Type) (interface{}, error) {
+	converted, inRange, err := typ.Convert(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to mask column %s.%s with transform %s: %w", r.Table, r.Column, r.Transform, err)
+	}
+	if inRange != sql.InRange {
+		return nil, fmt.Errorf("unable to mask column %s.%s with transform %s: value out of range for type %s", r.Table, r.Column, r.Transform, typ.String())
+	}
+	return converted, nil
+}
+
+// valueString returns a canonical string form of a SQL value, used as the input of the deterministic transforms.
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Rules is the set of masking rules of a database, keyed by lower-cased table name and then lower-cased column name.
+type Rules map[string]map[string]Rule
+
+// Add adds |r| to the rule set, replacing any rule for the same column.
+func (rs Rules) Add(r Rule) {
+	tbl := strings.ToLower(r.Table)
+	if rs[tbl] == nil {
+		rs[tbl] = make(map[string]Rule)
+	}
+	rs[tbl][strings.ToLower(r.Column)] = r
+}
+
+// ForTable returns the rules for the columns of |table|, keyed by lower-cased column name.
+func (rs Rules) ForTable(table string) map[string]Rule {
+	return rs[strings.ToLower(table)]
+}
+
+// Empty returns whether there are no rules.
+func (rs Rules) Empty() bool {
+	return len(rs) == 0
+}
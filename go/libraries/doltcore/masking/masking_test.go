@@ -0,0 +1,244 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package masking
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		argument  string
+		expectErr bool
+	}{
+		{"null", "null", "", false},
+		{"case insensitive", "HASH", "salt", false},
+		{"hash without salt", "hash", "", true},
+		{"fixed", "fixed", "redacted", false},
+		{"fake with kind", "fake", "email:salt", false},
+		{"fake without kind", "fake", ":salt", false},
+		{"fake without salt", "fake", "email", true},
+		{"fake with empty salt", "fake", "email:", true},
+		{"fake unknown kind", "fake", "ssn:salt", true},
+		{"truncate", "truncate", "3", false},
+		{"truncate negative", "truncate", "-1", true},
+		{"truncate not a number", "truncate", "abc", true},
+		{"unknown transform", "shuffle", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewRule("t", "c", test.transform, test.argument)
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	varchar10 := types.MustCreateStringWithDefaults(sqltypes.VarChar, 10)
+
+	tests := []struct {
+		name      string
+		transform string
+		argument  string
+		value     interface{}
+		typ       sql.Type
+		expected  interface{}
+		expectErr bool
+	}{
+		{"null stays null", "fixed", "x", nil, types.Text, nil, false},
+		{"null", "null", "", "secret", types.Text, nil, false},
+		{"fixed", "fixed", "redacted", "secret", types.Text, "redacted", false},
+		{"fixed int", "fixed", "42", int32(7), types.Int32, int32(42), false},
+		{"fixed out of range", "fixed", "300", int8(7), types.Int8, nil, true},
+		{"truncate", "truncate", "3", "secret", types.Text, "sec", false},
+		{"truncate default", "truncate", "", "secret", types.Text, "s", false},
+		{"truncate short value", "truncate", "10", "abc", types.Text, "abc", false},
+		{"truncate int", "truncate", "1", int32(7), types.Int32, nil, true},
+		{"hash fits varchar", "hash", "salt", "secret", varchar10, "bede90386d", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := NewRule("t", "c", test.transform, test.argument)
+			require.NoError(t, err)
+			actual, err := rule.Apply(test.value, test.typ)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestApplyIsDeterministic(t *testing.T) {
+	for transform, argument := range map[string]string{"hash": "salt", "fake": ":salt"} {
+		t.Run(transform, func(t *testing.T) {
+			rule, err := NewRule("t", "c", transform, argument)
+			require.NoError(t, err)
+
+			for _, typ := range []sql.Type{types.Text, types.Int8, types.Int64, types.Uint32} {
+				var v interface{} = "value"
+				if types.IsNumber(typ) {
+					v, _, err = typ.Convert(12)
+					require.NoError(t, err)
+				}
+
+				a, err := rule.Apply(v, typ)
+				require.NoError(t, err)
+				b, err := rule.Apply(v, typ)
+				require.NoError(t, err)
+				assert.Equal(t, a, b)
+				assert.NotEqual(t, v, a)
+
+				_, inRange, err := typ.Convert(a)
+				require.NoError(t, err)
+				assert.Equal(t, sql.InRange, inRange)
+			}
+		})
+	}
+}
+
+func TestHashSalt(t *testing.T) {
+	salted, err := NewRule("t", "c", "hash", "salt")
+	require.NoError(t, err)
+	peppered, err := NewRule("t", "c", "hash", "pepper")
+	require.NoError(t, err)
+
+	a, err := salted.Apply("value", types.Text)
+	require.NoError(t, err)
+	b, err := peppered.Apply("value", types.Text)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+}
+
+func TestFakeSalt(t *testing.T) {
+	salted, err := NewRule("t", "c", "fake", "email:salt")
+	require.NoError(t, err)
+	peppered, err := NewRule("t", "c", "fake", "email:pepper")
+	require.NoError(t, err)
+
+	for _, typ := range []sql.Type{types.Text, types.Int64} {
+		var v interface{} = "value"
+		if types.IsNumber(typ) {
+			v = int64(123456789)
+		}
+		a, err := salted.Apply(v, typ)
+		require.NoError(t, err)
+		b, err := peppered.Apply(v, typ)
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	column := func(name string, tag uint64, typ sql.Type, pk bool, constraints ...schema.ColConstraint) schema.Column {
+		ti, err := typeinfo.FromSqlType(typ)
+		require.NoError(t, err)
+		col, err := schema.NewColumnWithTypeInfo(name, tag, ti, pk, "", false, "", constraints...)
+		require.NoError(t, err)
+		return col
+	}
+	notNull := schema.NotNullConstraint{}
+	sch, err := schema.NewSchema(schema.NewColCollection(
+		column("id", 0, types.Int64, true, notNull),
+		column("key", 1, types.MustCreateStringWithDefaults(sqltypes.VarChar, 64), true, notNull),
+		column("code", 2, types.MustCreateStringWithDefaults(sqltypes.VarChar, 8), false),
+		column("email", 3, types.MustCreateStringWithDefaults(sqltypes.VarChar, 100), false, notNull),
+		column("notes", 4, types.Text, false),
+	), nil, schema.Collation_Default, nil, nil)
+	require.NoError(t, err)
+	_, err = sch.Indexes().AddIndexByColNames("code", []string{"code"}, nil, schema.IndexProperties{IsUnique: true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		column    string
+		transform string
+		argument  string
+		expectErr bool
+	}{
+		{"id", "fixed", "1", true},
+		{"id", "hash", "salt", true},
+		{"key", "hash", "salt", false},
+		{"key", "fake", "email:salt", true},
+		{"key", "truncate", "3", true},
+		{"code", "hash", "salt", true},
+		{"code", "fixed", "x", true},
+		{"email", "null", "", true},
+		{"email", "fixed", "x", false},
+		{"notes", "null", "", false},
+		{"missing", "null", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.column+" "+test.transform, func(t *testing.T) {
+			rule, err := NewRule("t", test.column, test.transform, test.argument)
+			require.NoError(t, err)
+			err = rule.ValidateSchema(sch)
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFakeKinds(t *testing.T) {
+	for _, kind := range FakeKinds() {
+		t.Run(kind, func(t *testing.T) {
+			rule, err := NewRule("t", "c", "fake", kind+":salt")
+			require.NoError(t, err)
+			v, err := rule.Apply("original", types.Text)
+			require.NoError(t, err)
+			assert.NotEmpty(t, v)
+		})
+	}
+
+	rule, err := NewRule("t", "c", "fake", "email:salt")
+	require.NoError(t, err)
+	v, err := rule.Apply("someone@company.com", types.Text)
+	require.NoError(t, err)
+	assert.Contains(t, v, "@example.")
+}
+
+func TestRules(t *testing.T) {
+	rules := make(Rules)
+	assert.True(t, rules.Empty())
+
+	rule, err := NewRule("People", "Email", "null", "")
+	require.NoError(t, err)
+	rules.Add(rule)
+
+	assert.False(t, rules.Empty())
+	assert.Equal(t, rule, rules.ForTable("people")["email"])
+	assert.Nil(t, rules.ForTable("other"))
+}
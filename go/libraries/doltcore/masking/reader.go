@@ -0,0 +1,95 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package masking
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+// maskedColumn is a column of a reader's rows that must be masked.
+type maskedColumn struct {
+	idx  int
+	rule Rule
+	typ  sql.Type
+}
+
+// Reader is a table.SqlRowReader that masks the rows read from another reader.
+type Reader struct {
+	rd     table.SqlRowReader
+	masked []maskedColumn
+}
+
+var _ table.SqlRowReader = (*Reader)(nil)
+
+// NewReader returns a table.SqlRowReader that applies the rules in |rules| for |tableName| to every row read from
+// |rd|. If no rule applies to the table, |rd| is returned unchanged.
+func NewReader(rd table.SqlRowReader, tableName string, rules Rules) table.SqlRowReader {
+	tblRules := rules.ForTable(tableName)
+	if len(tblRules) == 0 {
+		return rd
+	}
+
+	var masked []maskedColumn
+	for i, col := range rd.GetSchema().GetAllCols().GetColumns() {
+		if r, ok := tblRules[strings.ToLower(col.Name)]; ok {
+			masked = append(masked, maskedColumn{idx: i, rule: r, typ: col.TypeInfo.ToSqlType()})
+		}
+	}
+	if len(masked) == 0 {
+		return rd
+	}
+
+	return &Reader{rd: rd, masked: masked}
+}
+
+// GetSchema implements table.Reader
+func (r *Reader) GetSchema() schema.Schema {
+	return r.rd.GetSchema()
+}
+
+// ReadRow implements table.Reader
+func (r *Reader) ReadRow(ctx context.Context) (row.Row, error) {
+	panic("deprecated")
+}
+
+// ReadSqlRow implements table.SqlRowReader
+func (r *Reader) ReadSqlRow(ctx context.Context) (sql.Row, error) {
+	sqlRow, err := r.rd.ReadSqlRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	masked := sqlRow.Copy()
+	for _, mc := range r.masked {
+		masked[mc.idx], err = mc.rule.Apply(sqlRow[mc.idx], mc.typ)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return masked, nil
+}
+
+// Close implements table.Closer
+func (r *Reader) Close(ctx context.Context) error {
+	return r.rd.Close(ctx)
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package masking
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// TableNameCol is the name of the column of the dolt_masking table holding the masked table's name
+	TableNameCol = "table_name"
+	// ColumnNameCol is the name of the column of the dolt_masking table holding the masked column's name
+	ColumnNameCol = "column_name"
+	// TransformCol is the name of the column of the dolt_masking table holding the transform to apply
+	TransformCol = "transform"
+	// ArgumentCol is the name of the column of the dolt_masking table holding the transform's argument
+	ArgumentCol = "argument"
+)
+
+// TableSchema returns the schema of the dolt_masking system table.
+func TableSchema() (schema.Schema, error) {
+	colCollection := schema.NewColCollection(
+		schema.Column{
+			Name:       TableNameCol,
+			Tag:        schema.DoltMaskingTableNameTag,
+			Kind:       types.StringKind,
+			IsPartOfPK: true,
+			TypeInfo:   typeinfo.FromKind(types.StringKind),
+		},
+		schema.Column{
+			Name:       ColumnNameCol,
+			Tag:        schema.DoltMaskingColumnNameTag,
+			Kind:       types.StringKind,
+			IsPartOfPK: true,
+			TypeInfo:   typeinfo.FromKind(types.StringKind),
+		},
+		schema.Column{
+			Name:        TransformCol,
+			Tag:         schema.DoltMaskingTransformTag,
+			Kind:        types.StringKind,
+			TypeInfo:    typeinfo.FromKind(types.StringKind),
+			Constraints: []schema.ColConstraint{schema.NotNullConstraint{}},
+		},
+		schema.Column{
+			Name:     ArgumentCol,
+			Tag:      schema.DoltMaskingArgumentTag,
+			Kind:     types.StringKind,
+			TypeInfo: typeinfo.FromKind(types.StringKind),
+		},
+	)
+
+	return schema.NewSchema(colCollection, nil, schema.Collation_Default, nil, nil)
+}
+
+// LoadRules reads the masking rules stored in the dolt_masking table of |root|. If the table does not exist, an empty
+// set of rules is returned. Like dolt_ignore, dolt_masking is not supported for the legacy storage format.
+func LoadRules(ctx context.Context, root doltdb.RootValue) (Rules, error) {
+	rules := make(Rules)
+
+	tbl, found, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.MaskingTableName})
+	if err != nil {
+		return nil, err
+	}
+	if !found || tbl.Format() == types.Format_LD_1 {
+		return rules, nil
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyDesc, valueDesc := sch.GetMapDescriptors()
+	if keyDesc.Count() != 2 || valueDesc.Count() != 2 {
+		return nil, fmt.Errorf("%s had unexpected schema, this should never happen", doltdb.MaskingTableName)
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := durable.ProllyMapFromIndex(idx).IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		table, ok := keyDesc.GetString(0, k)
+		if !ok {
+			return nil, fmt.Errorf("could not read %s from %s", TableNameCol, doltdb.MaskingTableName)
+		}
+		column, ok := keyDesc.GetString(1, k)
+		if !ok {
+			return nil, fmt.Errorf("could not read %s from %s", ColumnNameCol, doltdb.MaskingTableName)
+		}
+		transform, ok := valueDesc.GetString(0, v)
+		if !ok {
+			return nil, fmt.Errorf("could not read %s from %s", TransformCol, doltdb.MaskingTableName)
+		}
+		argument, _ := valueDesc.GetString(1, v)
+
+		rule, err := NewRule(table, column, transform, argument)
+		if err != nil {
+			return nil, err
+		}
+		rules.Add(rule)
+	}
+
+	if err = rules.ValidateRoot(ctx, root); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateRoot returns an error if any of the rules cannot be applied to the schema of its table in |root|; see
+// Rule.ValidateSchema. Rules for tables |root| doesn't have are valid, since they are not applied.
+func (rs Rules) ValidateRoot(ctx context.Context, root doltdb.RootValue) error {
+	for _, tblRules := range rs {
+		var sch schema.Schema
+		for _, r := range tblRules {
+			if sch == nil {
+				tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: r.Table})
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if sch, err = tbl.GetSchema(ctx); err != nil {
+					return err
+				}
+			}
+			if err := r.ValidateSchema(sch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	goerrors "gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schmigrations"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/set"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -323,6 +324,12 @@ func MergeRoots(
 		return nil, err
 	}
 
+	// Cherry-picks and reverts can also bring in a migration whose sequence number was taken since its commit
+	mergedRoot, err = schmigrations.Merge(ctx, mergedRoot, ourRoot, theirRoot, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	mergedFKColl, conflicts, err := ForeignKeysMerge(ctx, mergedRoot, ourRoot, theirRoot, ancRoot)
 	if err != nil {
 		return nil, err
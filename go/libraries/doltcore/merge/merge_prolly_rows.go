@@ -1890,6 +1890,9 @@ func (m *valueMerger) processColumn(ctx *sql.Context, i int, left, right, base v
 		if generatedColumn {
 			return leftCol, false, nil
 		}
+		if resultColumn.Tag == schema.DoltSequencesReservedTag {
+			return maxColumn(i, leftCol, rightCol, resultType), false, nil
+		}
 
 		// conflicting inserts
 		return nil, true, nil
@@ -1955,6 +1958,10 @@ func (m *valueMerger) processColumn(ctx *sql.Context, i int, left, right, base v
 		if generatedColumn {
 			return leftCol, false, nil
 		}
+		// the number of values reserved from a sequence only grows, so the values reserved on both sides are kept
+		if resultColumn.Tag == schema.DoltSequencesReservedTag {
+			return maxColumn(i, leftCol, rightCol, resultType), false, nil
+		}
 		// concurrent modification
 		// if the result type is JSON, we can attempt to merge the JSON changes.
 		dontMergeJsonVar, err := ctx.Session.GetSessionVariable(ctx, "dolt_dont_merge_json")
@@ -2078,6 +2085,14 @@ func isEqual(i int, left []byte, right []byte, resultType val.Type) bool {
 	return val.DefaultTupleComparator{}.CompareValues(i, left, right, resultType) == 0
 }
 
+// maxColumn returns the larger of the values |left| and |right| of column |i|.
+func maxColumn(i int, left []byte, right []byte, resultType val.Type) []byte {
+	if (val.DefaultTupleComparator{}).CompareValues(i, left, right, resultType) > 0 {
+		return left
+	}
+	return right
+}
+
 func getColumn(tuple *val.Tuple, mapping *val.OrdinalMapping, idx int) (col []byte, colIndex int, exists bool) {
 	colIdx := (*mapping)[idx]
 	if colIdx == -1 {
@@ -35,6 +35,8 @@ import (
 type MergeOpts struct {
 	// IsCherryPick is set for cherry-pick operations.
 	IsCherryPick bool
+	// IsRevert is set for revert operations.
+	IsRevert bool
 	// KeepSchemaConflicts is set when schema conflicts should be stored,
 	// otherwise the merge errors out when schema conflicts are detected.
 	KeepSchemaConflicts bool
@@ -75,7 +75,7 @@ func Revert(ctx *sql.Context, ddb *doltdb.DoltDB, root doltdb.RootValue, commits
 		}
 
 		var result *Result
-		result, err = MergeRoots(ctx, root, theirRoot, baseRoot, parentCM, baseCommit, opts, MergeOpts{IsCherryPick: false})
+		result, err = MergeRoots(ctx, root, theirRoot, baseRoot, parentCM, baseCommit, opts, MergeOpts{IsCherryPick: false, IsRevert: true})
 		if err != nil {
 			return nil, "", err
 		}
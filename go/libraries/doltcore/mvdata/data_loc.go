@@ -57,6 +57,9 @@ const (
 
 	// ParquetFile is the format of a data location that is a .paquet file
 	ParquetFile DataFormat = ".parquet"
+
+	// MySQLServer is the format of a data location that is a table of a MySQL server
+	MySQLServer DataFormat = "mysql"
 )
 
 // ReadableStr returns a human readable string for a DataFormat
@@ -76,6 +79,8 @@ func (df DataFormat) ReadableStr() string {
 		return "sql file"
 	case ParquetFile:
 		return "parquet file"
+	case MySQLServer:
+		return "mysql"
 	default:
 		return "invalid"
 	}
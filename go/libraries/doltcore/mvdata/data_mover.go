@@ -54,6 +54,8 @@ type MoverOptions struct {
 	TableToWriteTo string
 	Operation      TableImportOp
 	DisableFks     bool
+	// CreateStatement, if set, creates the table of a create operation instead of a statement generated from its schema
+	CreateStatement string
 }
 
 type DataMoverOptions interface {
@@ -127,11 +129,15 @@ func SchAndTableNameFromFile(ctx context.Context, path string, dEnv *env.DoltEnv
 func InferSchema(ctx context.Context, root doltdb.RootValue, rd table.ReadCloser, tableName string, pks []string, args actions.InferenceArgs) (schema.Schema, error) {
 	var err error
 
-	infCols, err := actions.InferColumnTypesFromTableReader(ctx, rd, args)
+	infCols, report, err := actions.InferColumnTypesAndReportFromTableReader(ctx, rd, args)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(pks) == 0 && args.InferenceOptions().PrimaryKey {
+		pks = report.PrimaryKey
+	}
+
 	pkSet := set.NewStrSet(pks)
 	newCols := schema.MapColCollection(infCols, func(col schema.Column) schema.Column {
 		col.IsPartOfPK = pkSet.Contains(col.Name)
@@ -178,4 +184,5 @@ const (
 	ReplaceOp TableImportOp = "replace"
 	UpdateOp  TableImportOp = "update"
 	AppendOp  TableImportOp = "append"
+	SyncOp    TableImportOp = "sync"
 )
@@ -57,16 +57,31 @@ type SqlEngineTableWriter struct {
 	statOps int32
 
 	importOption       TableImportOp
+	createStatement    string
+	syncer             *tableSyncer
 	tableSchema        sql.PrimaryKeySchema
 	rowOperationSchema sql.PrimaryKeySchema
 }
 
 func NewSqlEngineTableWriter(ctx context.Context, dEnv *env.DoltEnv, createTableSchema, rowOperationSchema schema.Schema, options *MoverOptions, statsCB noms.StatsCB) (*SqlEngineTableWriter, error) {
+	se, sqlCtx, err := NewBulkImportEngine(ctx, dEnv)
+	if err != nil {
+		return nil, err
+	}
+	defer se.Close()
+
+	return NewSqlEngineTableWriterForEngine(se, sqlCtx, createTableSchema, rowOperationSchema, options, statsCB)
+}
+
+// NewBulkImportEngine returns an engine for the databases of |dEnv| which writes through the bulk import path, and a
+// context for it whose current database is the first database. Several tables can be imported through one engine with
+// NewSqlEngineTableWriterForEngine. Callers must close the engine.
+func NewBulkImportEngine(ctx context.Context, dEnv *env.DoltEnv) (*engine.SqlEngine, *sql.Context, error) {
 	// TODO: Assert that dEnv.DoltDB.AccessMode() != ReadOnly?
 
 	mrEnv, err := env.MultiEnvForDirectory(ctx, dEnv.Config.WriteableConfig(), dEnv.FS, dEnv.Version, dEnv)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Simplest path would have our import path be a layer over load data
@@ -81,22 +96,30 @@ func NewSqlEngineTableWriter(ctx context.Context, dEnv *env.DoltEnv, createTable
 		config,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer se.Close()
 
 	dbName := mrEnv.GetFirstDatabase()
 
 	if se.GetUnderlyingEngine().IsReadOnly() {
+		se.Close()
 		// SqlEngineTableWriter does not respect read only mode
-		return nil, analyzererrors.ErrReadOnlyDatabase.New(dbName)
+		return nil, nil, analyzererrors.ErrReadOnlyDatabase.New(dbName)
 	}
 
 	sqlCtx, err := se.NewLocalContext(ctx)
 	if err != nil {
-		return nil, err
+		se.Close()
+		return nil, nil, err
 	}
 	sqlCtx.SetCurrentDatabase(dbName)
+	return se, sqlCtx, nil
+}
+
+// NewSqlEngineTableWriterForEngine returns a writer which imports a table through the engine and context given, which
+// are returned by NewBulkImportEngine.
+func NewSqlEngineTableWriterForEngine(se *engine.SqlEngine, sqlCtx *sql.Context, createTableSchema, rowOperationSchema schema.Schema, options *MoverOptions, statsCB noms.StatsCB) (*SqlEngineTableWriter, error) {
+	dbName := sqlCtx.GetCurrentDatabase()
 
 	doltCreateTableSchema, err := sqlutil.FromDoltSchema("", options.TableToWriteTo, createTableSchema)
 	if err != nil {
@@ -121,6 +144,7 @@ func NewSqlEngineTableWriter(ctx context.Context, dEnv *env.DoltEnv, createTable
 		statsCB: statsCB,
 
 		importOption:       options.Operation,
+		createStatement:    options.CreateStatement,
 		tableSchema:        doltCreateTableSchema,
 		rowOperationSchema: doltRowOperationSchema,
 	}, nil
@@ -149,25 +173,30 @@ func (s *SqlEngineTableWriter) WriteRows(ctx context.Context, inputChannel chan
 		return err
 	}
 
-	updateStats := func(row sql.Row) {
-		if row == nil {
-			return
+	if s.importOption == SyncOp {
+		s.syncer, err = newTableSyncer(s.sqlCtx, s.database, s.tableName)
+		if err != nil {
+			return err
 		}
+	}
 
-		// If the length of the row does not match the schema then we have an update operation.
-		if len(row) != len(s.tableSchema.Schema) {
-			oldRow := row[:len(row)/2]
-			newRow := row[len(row)/2:]
+	// The insert returns a row which updated an existing row as the old row followed by the new one
+	insertedRows := func(row sql.Row) (oldRow, newRow sql.Row) {
+		if n := len(s.tableSchema.Schema); len(row) == 2*n {
+			return row[:n], row[n:]
+		}
+		return nil, row
+	}
 
-			if ok, err := oldRow.Equals(newRow, s.tableSchema.Schema); err == nil {
-				if ok {
-					s.stats.SameVal++
-				} else {
-					s.stats.Modifications++
-				}
-			}
-		} else {
+	updateStats := func(oldRow, newRow sql.Row) {
+		if oldRow == nil {
 			s.stats.Additions++
+		} else if ok, err := oldRow.Equals(newRow, s.tableSchema.Schema); err == nil {
+			if ok {
+				s.stats.SameVal++
+			} else {
+				s.stats.Modifications++
+			}
 		}
 	}
 
@@ -182,6 +211,9 @@ func (s *SqlEngineTableWriter) WriteRows(ctx context.Context, inputChannel chan
 	}
 
 	defer func() {
+		if iter == nil {
+			return
+		}
 		rerr := iter.Close(s.sqlCtx)
 		if err == nil {
 			err = rerr
@@ -202,8 +234,29 @@ func (s *SqlEngineTableWriter) WriteRows(ctx context.Context, inputChannel chan
 		// All other errors are handled by the errorHandler
 		if err == nil {
 			_ = atomic.AddInt32(&s.statOps, 1)
-			updateStats(row)
+			if row == nil {
+				continue
+			}
+			oldRow, newRow := insertedRows(row)
+			updateStats(oldRow, newRow)
+			if s.syncer != nil {
+				if err := s.syncer.add(s.sqlCtx, newRow); err != nil {
+					return err
+				}
+			}
 		} else if err == io.EOF {
+			if s.syncer != nil {
+				// The insert is completed before the rows it didn't write are deleted from the same table
+				cerr := iter.Close(s.sqlCtx)
+				iter = nil
+				if cerr != nil {
+					return cerr
+				}
+				if err := s.deleteUnwrittenRows(insertOrUpdateOperation); err != nil {
+					return err
+				}
+			}
+
 			atomic.LoadInt32(&s.statOps)
 			atomic.StoreInt32(&s.statOps, 0)
 			if s.statsCB != nil {
@@ -233,6 +286,46 @@ func (s *SqlEngineTableWriter) Commit(ctx context.Context) error {
 	return err
 }
 
+// Stats returns the statistics of the rows written so far.
+func (s *SqlEngineTableWriter) Stats() types.AppliedEditStats {
+	return s.stats
+}
+
+// deleteUnwrittenRows deletes the rows of the table that a sync didn't write, through the destination of |insert|,
+// which enforces the foreign keys of the table as the insert did.
+func (s *SqlEngineTableWriter) deleteUnwrittenRows(insert sql.Node) (err error) {
+	ins, ok := insert.(*plan.InsertInto)
+	if !ok {
+		return fmt.Errorf("sync expected *plan.InsertInto, found %T", insert)
+	}
+	deletable, err := plan.GetDeletable(ins.Destination)
+	if err != nil {
+		return err
+	}
+
+	deleter := deletable.Deleter(s.sqlCtx)
+	defer func() {
+		cerr := deleter.Close(s.sqlCtx)
+		if err == nil {
+			err = cerr
+		}
+	}()
+	deleter.StatementBegin(s.sqlCtx)
+
+	deleted, err := s.syncer.unwrittenRows(s.sqlCtx, func(row sql.Row) error {
+		return deleter.Delete(s.sqlCtx, row)
+	})
+	if err != nil {
+		_ = deleter.DiscardChanges(s.sqlCtx, err)
+		return err
+	}
+	if err = deleter.StatementComplete(s.sqlCtx); err != nil {
+		return err
+	}
+	s.stats.Deletions += deleted
+	return nil
+}
+
 func (s *SqlEngineTableWriter) RowOperationSchema() sql.PrimaryKeySchema {
 	return s.rowOperationSchema
 }
@@ -266,6 +359,15 @@ func (s *SqlEngineTableWriter) createOrEmptyTableIfNeeded() error {
 
 // createTable creates a table.
 func (s *SqlEngineTableWriter) createTable() error {
+	if s.createStatement != "" {
+		_, iter, err := s.se.Query(s.sqlCtx, s.createStatement)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(s.sqlCtx, iter)
+		return err
+	}
+
 	// TODO don't use internal interfaces to do this, we had to have a sql.Schema somewhere
 	// upstream to make the dolt schema
 	sqlCols := make([]string, len(s.tableSchema.Schema))
@@ -294,7 +396,7 @@ func (s *SqlEngineTableWriter) createTable() error {
 // createInsertImportNode creates the relevant/analyzed insert node given the import option. This insert node is wrapped
 // with an error handler.
 func (s *SqlEngineTableWriter) getInsertNode(inputChannel chan sql.Row, replace bool) (sql.Node, error) {
-	update := s.importOption == UpdateOp
+	update := s.importOption == UpdateOp || s.importOption == SyncOp
 	colNames := ""
 	values := ""
 	duplicate := ""
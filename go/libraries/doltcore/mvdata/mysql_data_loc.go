@@ -0,0 +1,162 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gomysql "github.com/go-sql-driver/mysql"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/mysql"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// MySQLDataLocation is a table of a MySQL server, or of any server which speaks its protocol, that can be imported
+// from.
+type MySQLDataLocation struct {
+	// DSN is the data source name of the server and database, such as user:password@tcp(host:3306)/db
+	DSN string
+
+	// Table is the name of the table in the database of the DSN
+	Table string
+}
+
+// TableDefinitionReader is a reader of a table which has a definition of its own, such as a table of a MySQL server.
+type TableDefinitionReader interface {
+	table.SqlRowReader
+
+	// TableSchema returns the schema of the table being read, including any columns which aren't read, such as
+	// generated columns.
+	TableSchema() schema.Schema
+
+	// CreateStatement returns a CREATE TABLE statement for the table being read, with the name given.
+	CreateStatement(name string) string
+}
+
+var _ TableDefinitionReader = (*mysql.TableReader)(nil)
+
+// RedactDSN returns the DSN given without its password, for printing.
+func RedactDSN(dsn string) string {
+	cfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return "<invalid dsn>"
+	}
+	cfg.Passwd = ""
+	return cfg.FormatDSN()
+}
+
+// String returns a string representation of the data location.
+func (dl MySQLDataLocation) String() string {
+	return fmt.Sprintf("%s table %s of %s", MySQLServer.ReadableStr(), dl.Table, RedactDSN(dl.DSN))
+}
+
+// Exists returns true if the DataLocation already exists
+func (dl MySQLDataLocation) Exists(ctx context.Context, root doltdb.RootValue, fs filesys.ReadableFS) (bool, error) {
+	db, err := mysql.Open(ctx, dl.DSN)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	tables, err := mysql.ListTables(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tables {
+		if t == dl.Table {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NewReader creates a TableReadCloser for the DataLocation. The reader is a TableDefinitionReader, and the rows it
+// reads are sorted by primary key.
+func (dl MySQLDataLocation) NewReader(ctx context.Context, dEnv *env.DoltEnv, opts interface{}) (rdCl table.SqlRowReader, sorted bool, err error) {
+	db, err := mysql.Open(ctx, dl.DSN)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
+
+	def, err := mysql.ReadTableDef(ctx, db, dl.Table)
+	if err != nil {
+		return nil, false, err
+	}
+
+	root, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	eng, dbName, err := engine.NewSqlEngineForEnv(ctx, dEnv)
+	if err != nil {
+		return nil, false, err
+	}
+	defer eng.Close()
+	sqlCtx, err := eng.NewDefaultContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	sqlCtx.SetCurrentDatabase(dbName)
+
+	rd, err := NewMySQLTableReader(sqlCtx, eng, root, db, db, def, dl.Table)
+	if err != nil {
+		return nil, false, err
+	}
+	return rd, len(def.PrimaryKey) > 0, nil
+}
+
+// NewMySQLTableReader returns a reader of the rows of the MySQL table |def|, which are queried through |q|. The schema
+// of the table is that of its CREATE TABLE statement with the name |name|, as parsed by |eng| against |root|. If
+// |closer| is not nil, it is closed with the reader.
+func NewMySQLTableReader(sqlCtx *sql.Context, eng *engine.SqlEngine, root doltdb.RootValue, q mysql.Querier, closer io.Closer, def *mysql.TableDef, name string) (*mysql.TableReader, error) {
+	_, tableSch, err := sqlutil.ParseCreateTableStatement(sqlCtx, root, eng.GetUnderlyingEngine(), def.CreateStatement(name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create table %s from its definition: %w", name, err)
+	}
+
+	var storedCols []schema.Column
+	for _, col := range tableSch.GetAllCols().GetColumns() {
+		if col.Generated == "" {
+			storedCols = append(storedCols, col)
+		}
+	}
+	sch, err := schema.SchemaFromCols(schema.NewColCollection(storedCols...))
+	if err != nil {
+		return nil, err
+	}
+	return mysql.NewTableReader(q, closer, def, tableSch, sch), nil
+}
+
+// NewCreatingWriter will create a TableWriteCloser for a DataLocation that will create a new table, or overwrite
+// an existing table.
+func (dl MySQLDataLocation) NewCreatingWriter(ctx context.Context, mvOpts DataMoverOptions, root doltdb.RootValue, outSch schema.Schema, opts editor.Options, wr io.WriteCloser) (table.SqlRowWriter, error) {
+	return nil, errors.New("exporting to a MySQL server is not supported")
+}
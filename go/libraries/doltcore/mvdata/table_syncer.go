@@ -0,0 +1,167 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvdata
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// tableSyncer records the primary keys of the rows written by a sync import, so that the rows of the table which the
+// import didn't write can be found and deleted once every row is written.
+type tableSyncer struct {
+	tableName string
+	before    prolly.Map
+	written   *prolly.MutableMap
+	keyDesc   val.TupleDesc
+	keyBld    *val.TupleBuilder
+	ns        tree.NodeStore
+
+	sch       schema.Schema
+	pkOrdinal []int
+}
+
+// newTableSyncer returns a tableSyncer for the table given, as it is in the working set of the session of |ctx|
+// before the import writes any rows.
+func newTableSyncer(ctx *sql.Context, dbName, tableName string) (*tableSyncer, error) {
+	roots, ok := dsess.DSessFromSess(ctx.Session).GetRoots(ctx, dbName)
+	if !ok {
+		return nil, fmt.Errorf("database %s not found", dbName)
+	}
+	tbl, name, ok, err := doltdb.GetTableInsensitive(ctx, roots.Working, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	if !types.IsFormat_DOLT(tbl.Format()) {
+		return nil, fmt.Errorf("syncing a table is only supported by the %s storage format", types.Format_DOLT.VersionString())
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema.IsKeyless(sch) {
+		return nil, fmt.Errorf("table %s has no primary key, which is required to sync it", name)
+	}
+	pkSch, err := sqlutil.FromDoltSchema("", name, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before := durable.ProllyMapFromIndex(idx)
+	keyDesc, _ := before.Descriptors()
+	written, err := prolly.NewMapFromTuples(ctx, before.NodeStore(), keyDesc, val.NewTupleDescriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableSyncer{
+		tableName: name,
+		before:    before,
+		written:   written.Mutate(),
+		keyDesc:   keyDesc,
+		keyBld:    val.NewTupleBuilder(keyDesc),
+		ns:        before.NodeStore(),
+		sch:       sch,
+		pkOrdinal: pkSch.PkOrdinals,
+	}, nil
+}
+
+// add records the primary key of a row written by the import's insert, which is the new row of an update.
+func (ts *tableSyncer) add(ctx *sql.Context, row sql.Row) error {
+	for i, ord := range ts.pkOrdinal {
+		if err := tree.PutField(ctx, ts.ns, ts.keyBld, i, row[ord]); err != nil {
+			return err
+		}
+	}
+	return ts.written.Put(ctx, ts.keyBld.Build(ts.before.Pool()), val.EmptyTuple)
+}
+
+// unwrittenRows merges the rows of the table from before the import with the keys written by the import, both in key
+// order, and passes |cb| the rows whose keys weren't written. It returns the number of rows passed to |cb|.
+func (ts *tableSyncer) unwrittenRows(ctx *sql.Context, cb func(row sql.Row) error) (int64, error) {
+	written, err := ts.written.Map(ctx)
+	if err != nil {
+		return 0, err
+	}
+	beforeIter, err := ts.before.IterAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	writtenIter, err := written.IterAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	nextWritten := func() (val.Tuple, error) {
+		k, _, err := writtenIter.Next(ctx)
+		if err == io.EOF {
+			return nil, nil
+		}
+		return k, err
+	}
+	writtenKey, err := nextWritten()
+	if err != nil {
+		return 0, err
+	}
+
+	var unwritten int64
+	for {
+		k, v, err := beforeIter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+
+		for writtenKey != nil && ts.keyDesc.Compare(writtenKey, k) < 0 {
+			if writtenKey, err = nextWritten(); err != nil {
+				return 0, err
+			}
+		}
+		if writtenKey != nil && ts.keyDesc.Compare(writtenKey, k) == 0 {
+			continue
+		}
+
+		row, err := index.BuildRow(ctx, k, v, ts.sch, ts.ns)
+		if err != nil {
+			return 0, err
+		}
+		if err := cb(row); err != nil {
+			return 0, err
+		}
+		unwritten++
+	}
+	return unwritten, nil
+}
@@ -54,6 +54,9 @@ type RemoteChunkStore struct {
 	fs      filesys.Filesys
 	lgr     *logrus.Entry
 	sealer  Sealer
+
+	// preReceive, if set, is called with the ref updates of each push before its root is committed
+	preReceive PreReceiveHook
 	remotesapi.UnimplementedChunkStoreServiceServer
 }
 
@@ -501,6 +504,13 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 	currHash := hash.New(req.Current)
 	lastHash := hash.New(req.Last)
 
+	if rs.preReceive != nil {
+		err = rs.runPreReceiveHook(ctx, logger, cs, repoPath, lastHash, currHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var ok bool
 	ok, err = cs.Commit(ctx, currHash, lastHash)
 	if err != nil {
@@ -515,6 +525,28 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 	return &remotesapi.CommitResponse{Success: ok}, nil
 }
 
+func (rs *RemoteChunkStore) runPreReceiveHook(ctx context.Context, logger *logrus.Entry, cs RemoteSrvStore, repoPath string, lastHash, currHash hash.Hash) error {
+	updates, err := refUpdates(ctx, cs, lastHash, currHash)
+	if err != nil {
+		logger.WithError(err).Error("error reading ref updates")
+		return status.Errorf(codes.Internal, "failed to read ref updates: %v", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	err = rs.preReceive(ctx, repoPath, updates)
+	var rejected *remotestorage.PushRejectedError
+	if errors.As(err, &rejected) {
+		logger.WithError(err).Info("push rejected by pre-receive hook")
+		return rejected.GRPCStatus().Err()
+	} else if err != nil {
+		logger.WithError(err).Error("error running pre-receive hook")
+		return status.Errorf(codes.Internal, "pre-receive hook failed: %v", err)
+	}
+	return nil
+}
+
 func (rs *RemoteChunkStore) GetRepoMetadata(ctx context.Context, req *remotesapi.GetRepoMetadataRequest) (*remotesapi.GetRepoMetadataResponse, error) {
 	logger := getReqLogger(rs.lgr, "GetRepoMetadata")
 	if err := ValidateGetRepoMetadataRequest(req); err != nil {
@@ -0,0 +1,89 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// RefUpdate is the change a push makes to a single ref of a database, such as refs/heads/main. Old is empty for a
+// ref the push creates, and New is empty for a ref it deletes.
+type RefUpdate struct {
+	Ref string
+	Old hash.Hash
+	New hash.Hash
+}
+
+// PreReceiveHook is called by Commit with the ref updates of a push, once the table files of the push have been added
+// to the store but before its root is committed, so the new commits can be read from the store. A hook rejects the
+// push by returning a *remotestorage.PushRejectedError, whose rejections are reported to the client. Any other error
+// fails the push with an internal error.
+type PreReceiveHook func(ctx context.Context, repoPath string, updates []RefUpdate) error
+
+// refUpdates returns the refs which differ between the roots |last| and |curr| of |cs|.
+func refUpdates(ctx context.Context, cs RemoteSrvStore, last, curr hash.Hash) ([]RefUpdate, error) {
+	// The database is only used for reading, and is not closed, as that would close |cs|
+	db := datas.NewTypesDatabase(types.NewValueStore(cs), tree.NewNodeStore(cs))
+
+	oldRefs, err := refsAtRoot(ctx, db, last)
+	if err != nil {
+		return nil, err
+	}
+	newRefs, err := refsAtRoot(ctx, db, curr)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []RefUpdate
+	for r, addr := range newRefs {
+		if oldRefs[r] != addr {
+			updates = append(updates, RefUpdate{Ref: r, Old: oldRefs[r], New: addr})
+		}
+	}
+	for r, addr := range oldRefs {
+		if _, ok := newRefs[r]; !ok {
+			updates = append(updates, RefUpdate{Ref: r, Old: addr})
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].Ref < updates[j].Ref
+	})
+	return updates, nil
+}
+
+func refsAtRoot(ctx context.Context, db datas.Database, root hash.Hash) (map[string]hash.Hash, error) {
+	refs := make(map[string]hash.Hash)
+	if root.IsEmpty() {
+		return refs, nil
+	}
+	datasets, err := db.DatasetsByRootHash(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	err = datasets.IterAll(ctx, func(id string, addr hash.Hash) error {
+		refs[id] = addr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestRefUpdates(t *testing.T) {
+	ctx := context.Background()
+	cs, err := nbs.NewLocalStore(ctx, types.Format_Default.VersionString(), t.TempDir(), 1<<20, nbs.NewUnlimitedMemQuotaProvider())
+	require.NoError(t, err)
+	defer cs.Close()
+	db := datas.NewTypesDatabase(types.NewValueStore(cs), tree.NewNodeStore(cs))
+
+	commit := func(ref string, v types.Value) hash.Hash {
+		ds, err := db.GetDataset(ctx, ref)
+		require.NoError(t, err)
+		ds, err = datas.CommitValue(ctx, db, ds, v)
+		require.NoError(t, err)
+		addr, ok := ds.MaybeHeadAddr()
+		require.True(t, ok)
+		return addr
+	}
+	root := func() hash.Hash {
+		h, err := cs.Root(ctx)
+		require.NoError(t, err)
+		return h
+	}
+
+	main1 := commit("refs/heads/main", types.Int(1))
+	feature := commit("refs/heads/feature", types.Int(2))
+	first := root()
+
+	updates, err := refUpdates(ctx, cs, hash.Hash{}, first)
+	require.NoError(t, err)
+	assert.Equal(t, []RefUpdate{
+		{Ref: "refs/heads/feature", New: feature},
+		{Ref: "refs/heads/main", New: main1},
+	}, updates)
+
+	main2 := commit("refs/heads/main", types.Int(3))
+	ds, err := db.GetDataset(ctx, "refs/heads/feature")
+	require.NoError(t, err)
+	_, err = db.Delete(ctx, ds, "")
+	require.NoError(t, err)
+
+	updates, err = refUpdates(ctx, cs, first, root())
+	require.NoError(t, err)
+	assert.Equal(t, []RefUpdate{
+		{Ref: "refs/heads/feature", Old: feature},
+		{Ref: "refs/heads/main", Old: main1, New: main2},
+	}, updates)
+
+	updates, err = refUpdates(ctx, cs, first, first)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestPreReceiveHookRejection(t *testing.T) {
+	ctx := context.Background()
+	cs, err := nbs.NewLocalStore(ctx, types.Format_Default.VersionString(), t.TempDir(), 1<<20, nbs.NewUnlimitedMemQuotaProvider())
+	require.NoError(t, err)
+	defer cs.Close()
+	db := datas.NewTypesDatabase(types.NewValueStore(cs), tree.NewNodeStore(cs))
+	ds, err := db.GetDataset(ctx, "refs/heads/main")
+	require.NoError(t, err)
+	_, err = datas.CommitValue(ctx, db, ds, types.Int(1))
+	require.NoError(t, err)
+	curr, err := cs.Root(ctx)
+	require.NoError(t, err)
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	rs := &RemoteChunkStore{}
+	rs.preReceive = func(ctx context.Context, repoPath string, updates []RefUpdate) error {
+		assert.Equal(t, "org/repo", repoPath)
+		require.Len(t, updates, 1)
+		return &remotestorage.PushRejectedError{Rejections: []remotestorage.PushRejection{
+			{Ref: updates[0].Ref, Reason: "protected branch"},
+		}}
+	}
+	err = rs.runPreReceiveHook(ctx, logger, cs, "org/repo", hash.Hash{}, curr)
+	require.Error(t, err)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	rejections := remotestorage.PushRejections(remotestorage.NewRpcError(err, "Commit", "localhost", nil))
+	assert.Equal(t, []remotestorage.PushRejection{{Ref: "refs/heads/main", Reason: "protected branch"}}, rejections)
+
+	rs.preReceive = func(ctx context.Context, repoPath string, updates []RefUpdate) error {
+		return errors.New("hook broke")
+	}
+	err = rs.runPreReceiveHook(ctx, logger, cs, "org/repo", hash.Hash{}, curr)
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.Internal, st.Code())
+}
@@ -67,6 +67,10 @@ type ServerArgs struct {
 
 	HttpInterceptor func(http.Handler) http.Handler
 
+	// If supplied, PreReceiveHook is called with the ref updates of every
+	// push before its new root is committed, and can reject the push.
+	PreReceiveHook PreReceiveHook
+
 	// If supplied, the listener(s) returned from Listeners() will be TLS
 	// listeners. The scheme used in the URLs returned from the gRPC server
 	// will be https.
@@ -95,7 +99,9 @@ func NewServer(args ServerArgs) (*Server, error) {
 	s.wg.Add(2)
 	s.grpcListenAddr = args.GrpcListenAddr
 	s.grpcSrv = grpc.NewServer(append([]grpc.ServerOption{grpc.MaxRecvMsgSize(128 * 1024 * 1024)}, args.Options...)...)
-	var chnkSt remotesapi.ChunkStoreServiceServer = NewHttpFSBackedChunkStore(args.Logger, args.HttpHost, args.DBCache, args.FS, scheme, args.ConcurrencyControl, sealer)
+	remoteStore := NewHttpFSBackedChunkStore(args.Logger, args.HttpHost, args.DBCache, args.FS, scheme, args.ConcurrencyControl, sealer)
+	remoteStore.preReceive = args.PreReceiveHook
+	var chnkSt remotesapi.ChunkStoreServiceServer = remoteStore
 	if args.ReadOnly {
 		chnkSt = ReadOnlyChunkStore{chnkSt}
 	}
@@ -155,6 +161,13 @@ func (l Listeners) Close() error {
 	return nil
 }
 
+// SingleListener returns Listeners which serve both the HTTP and the gRPC requests of a server from |l|, which need
+// not be backed by a network socket. The server must have been created with the same HttpListenAddr and
+// GrpcListenAddr, so that it multiplexes both on the same connections.
+func SingleListener(l net.Listener) Listeners {
+	return Listeners{http: l}
+}
+
 func (s *Server) Listeners() (Listeners, error) {
 	var httpListener net.Listener
 	var grpcListener net.Listener
@@ -0,0 +1,89 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotestorage
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pushRejectedViolationType is the type of the PreconditionFailure violations with which a remotesapi server
+// describes the ref updates of a push which it rejected.
+const pushRejectedViolationType = "PUSH_REJECTED"
+
+// PushRejection is the reason a remote rejected the update of a ref by a push.
+type PushRejection struct {
+	// Ref is the ref the push tried to update, such as refs/heads/main
+	Ref string
+	// Reason is a human readable explanation of the rejection
+	Reason string
+}
+
+// PushRejectedError is returned by a remotesapi server which rejects a push, such as for updating a protected
+// branch. It is sent to the client as a FailedPrecondition status with the rejections in its details.
+type PushRejectedError struct {
+	Rejections []PushRejection
+}
+
+func (e *PushRejectedError) Error() string {
+	reasons := make([]string, len(e.Rejections))
+	for i, r := range e.Rejections {
+		reasons[i] = r.Ref + ": " + r.Reason
+	}
+	return "push rejected: " + strings.Join(reasons, "; ")
+}
+
+// GRPCStatus returns the status a gRPC server sends for the error.
+func (e *PushRejectedError) GRPCStatus() *status.Status {
+	st := status.New(codes.FailedPrecondition, e.Error())
+	failure := &errdetails.PreconditionFailure{}
+	for _, r := range e.Rejections {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        pushRejectedViolationType,
+			Subject:     r.Ref,
+			Description: r.Reason,
+		})
+	}
+	if withDetails, err := st.WithDetails(failure); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// PushRejections returns the rejections of a push which failed with |err|, or nil if the remote did not reject it.
+func PushRejections(err error) []PushRejection {
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) || rpcErr.status == nil {
+		return nil
+	}
+
+	var rejections []PushRejection
+	for _, detail := range rpcErr.status.Details() {
+		failure, ok := detail.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, v := range failure.Violations {
+			if v.Type == pushRejectedViolationType {
+				rejections = append(rejections, PushRejection{Ref: v.Subject, Reason: v.Description})
+			}
+		}
+	}
+	return rejections
+}
@@ -42,6 +42,8 @@ const (
 	HistoryCommitterTag = iota + SystemTableReservedMin + uint64(1000)
 	HistoryCommitHashTag
 	HistoryCommitDateTag
+	HistoryValidFromTag
+	HistoryValidToTag
 )
 
 // Tags for dolt_diff_ table
@@ -115,3 +117,22 @@ const (
 	DoltIgnorePatternTag = iota + SystemTableReservedMin + uint64(8000)
 	DoltIgnoreIgnoredTag
 )
+
+// Tags for the dolt_masking table
+const (
+	DoltMaskingTableNameTag = iota + SystemTableReservedMin + uint64(9000)
+	DoltMaskingColumnNameTag
+	DoltMaskingTransformTag
+	DoltMaskingArgumentTag
+)
+
+// Tags for the dolt_sequences table
+const (
+	DoltSequencesNameTag = iota + SystemTableReservedMin + uint64(10000)
+	DoltSequencesStartValueTag
+	DoltSequencesIncrementTag
+	DoltSequencesMinValueTag
+	DoltSequencesMaxValueTag
+	DoltSequencesBranchRangeTag
+	DoltSequencesReservedTag
+)
@@ -0,0 +1,94 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schmigrations
+
+import "sort"
+
+// DriftKind describes how a migration differs between two sets of migrations.
+type DriftKind string
+
+const (
+	// DriftAdded is the kind of a migration which only exists in the second set.
+	DriftAdded DriftKind = "added"
+	// DriftRemoved is the kind of a migration which only exists in the first set.
+	DriftRemoved DriftKind = "removed"
+	// DriftRenumbered is the kind of a migration whose sequence number differs.
+	DriftRenumbered DriftKind = "renumbered"
+	// DriftModified is the kind of a migration whose scripts differ.
+	DriftModified DriftKind = "modified"
+	// DriftApplied is the kind of a migration which is only applied in the second set.
+	DriftApplied DriftKind = "applied"
+	// DriftUnapplied is the kind of a migration which is only applied in the first set.
+	DriftUnapplied DriftKind = "unapplied"
+)
+
+// Drift is a difference between the migrations of two branches.
+type Drift struct {
+	Name string
+	Kind DriftKind
+	// From is the migration in the first set, or nil if it was added.
+	From *Migration
+	// To is the migration in the second set, or nil if it was removed.
+	To *Migration
+}
+
+// Diff returns the differences between the migrations |from| and |to|, ordered by the sequence numbers of the
+// migrations. A migration which differs in more than one way is reported once for each way.
+func Diff(from, to []Migration) []Drift {
+	toByName := make(map[string]*Migration, len(to))
+	for i := range to {
+		toByName[to[i].Name] = &to[i]
+	}
+
+	var drift []Drift
+	for i := range from {
+		f := &from[i]
+		t, ok := toByName[f.Name]
+		if !ok {
+			drift = append(drift, Drift{Name: f.Name, Kind: DriftRemoved, From: f})
+			continue
+		}
+		delete(toByName, f.Name)
+
+		if f.Sequence != t.Sequence {
+			drift = append(drift, Drift{Name: f.Name, Kind: DriftRenumbered, From: f, To: t})
+		}
+		if f.Up != t.Up || f.Down != t.Down {
+			drift = append(drift, Drift{Name: f.Name, Kind: DriftModified, From: f, To: t})
+		}
+		if f.Applied && !t.Applied {
+			drift = append(drift, Drift{Name: f.Name, Kind: DriftUnapplied, From: f, To: t})
+		} else if !f.Applied && t.Applied {
+			drift = append(drift, Drift{Name: f.Name, Kind: DriftApplied, From: f, To: t})
+		}
+	}
+	for i := range to {
+		if _, ok := toByName[to[i].Name]; ok {
+			drift = append(drift, Drift{Name: to[i].Name, Kind: DriftAdded, To: &to[i]})
+		}
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		return drift[i].sequence() < drift[j].sequence()
+	})
+	return drift
+}
+
+func (d Drift) sequence() int64 {
+	if d.To != nil {
+		return d.To.Sequence
+	}
+	return d.From.Sequence
+}
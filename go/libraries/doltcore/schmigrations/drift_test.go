@@ -0,0 +1,54 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schmigrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	from := []Migration{
+		{Sequence: 1, Name: "same", Up: "create table a (i int)", Applied: true},
+		{Sequence: 2, Name: "renumbered", Up: "create table b (i int)"},
+		{Sequence: 3, Name: "modified", Up: "create table c (i int)", Down: "drop table c"},
+		{Sequence: 4, Name: "applied", Up: "create table d (i int)"},
+		{Sequence: 5, Name: "removed", Up: "create table e (i int)", Applied: true},
+	}
+	to := []Migration{
+		{Sequence: 1, Name: "same", Up: "create table a (i int)", Applied: true},
+		{Sequence: 3, Name: "modified", Up: "create table c (i int)"},
+		{Sequence: 4, Name: "applied", Up: "create table d (i int)", Applied: true},
+		{Sequence: 6, Name: "renumbered", Up: "create table b (i int)"},
+		{Sequence: 7, Name: "added", Up: "create table f (i int)"},
+	}
+
+	drift := Diff(from, to)
+	var actual [][2]string
+	for _, d := range drift {
+		actual = append(actual, [2]string{d.Name, string(d.Kind)})
+	}
+	assert.Equal(t, [][2]string{
+		{"modified", "modified"},
+		{"applied", "applied"},
+		{"removed", "removed"},
+		{"renumbered", "renumbered"},
+		{"added", "added"},
+	}, actual)
+
+	assert.Empty(t, Diff(from, from))
+	assert.Len(t, Diff(nil, to), len(to))
+}
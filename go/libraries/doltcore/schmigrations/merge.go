@@ -0,0 +1,125 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schmigrations
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+)
+
+// Merge resolves the sequence numbers of migrations added on both sides of a merge. Rows of different migrations merge
+// cleanly, so two branches which each added a migration with the same sequence number would leave the merged root
+// with an ambiguous order. When that happens the migration which has not been applied yet, preferring their side, is
+// renumbered to follow all other migrations of |mergedRoot|. If both migrations were applied the order in which their
+// scripts ran cannot be changed, and the merge fails with ErrSequenceConflict.
+func Merge(ctx context.Context, mergedRoot, ourRoot, theirRoot, ancRoot doltdb.RootValue) (doltdb.RootValue, error) {
+	ancHash, _, err := ancRoot.GetTableHash(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+	if err != nil {
+		return nil, err
+	}
+	ourHash, _, err := ourRoot.GetTableHash(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+	if err != nil {
+		return nil, err
+	}
+	theirHash, _, err := theirRoot.GetTableHash(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+	if err != nil {
+		return nil, err
+	}
+	if ourHash == ancHash || theirHash == ancHash || ourHash == theirHash {
+		return mergedRoot, nil
+	}
+
+	anc, err := Load(ctx, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := Load(ctx, ourRoot)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := Load(ctx, theirRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	theirAdded := added(theirs, anc, ours)
+	ourAdded := make(map[int64]Migration)
+	for _, m := range added(ours, anc, theirs) {
+		ourAdded[m.Sequence] = m
+	}
+	if len(ourAdded) == 0 || len(theirAdded) == 0 {
+		return mergedRoot, nil
+	}
+
+	merged, err := Load(ctx, mergedRoot)
+	if err != nil {
+		return nil, err
+	}
+	mergedByName := make(map[string]Migration, len(merged))
+	for _, m := range merged {
+		mergedByName[m.Name] = m
+	}
+	next := NextSequence(merged)
+
+	var renumbered []Migration
+	for _, t := range theirAdded {
+		o, ok := ourAdded[t.Sequence]
+		if !ok {
+			continue
+		}
+
+		var m Migration
+		switch {
+		case !t.Applied:
+			m = t
+		case !o.Applied:
+			m = o
+		default:
+			return nil, ErrSequenceConflict.New(o.Name, t.Name, t.Sequence)
+		}
+
+		// Take the migration from the merged root, which may also have changes from the other side
+		if mm, ok := mergedByName[m.Name]; ok {
+			m = mm
+		}
+		m.Sequence = next
+		next++
+		renumbered = append(renumbered, m)
+	}
+
+	return Update(ctx, mergedRoot, renumbered...)
+}
+
+// added returns the migrations of |side| which were not in the ancestor |anc| and are not on the |other| side of the
+// merge, in the order of their sequence numbers. Migrations added on both sides under the same name are not
+// renumbered; if their contents differ their rows conflict instead.
+func added(side, anc, other []Migration) []Migration {
+	existing := make(map[string]struct{}, len(anc)+len(other))
+	for _, m := range anc {
+		existing[m.Name] = struct{}{}
+	}
+	for _, m := range other {
+		existing[m.Name] = struct{}{}
+	}
+
+	var res []Migration
+	for _, m := range side {
+		if _, ok := existing[m.Name]; !ok {
+			res = append(res, m)
+		}
+	}
+	return res
+}
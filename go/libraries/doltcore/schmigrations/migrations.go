@@ -0,0 +1,322 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schmigrations implements versioned schema migrations. A migration is a pair of SQL scripts, one which
+// applies a schema change and one which reverts it, and a sequence number which orders it among the other migrations
+// of the database. Migrations are stored as rows of the dolt_schemas table, next to views, triggers and events, so
+// they are versioned, diffed and merged with the schema they create.
+package schmigrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// FragmentType is the value of the type column of the dolt_schemas rows which store migrations.
+const FragmentType = "migration"
+
+var ErrMigrationExists = errors.NewKind("schema migration '%s' already exists")
+var ErrSequenceExists = errors.NewKind("schema migration '%s' already has sequence number %d")
+var ErrSequenceConflict = errors.NewKind("schema migrations '%s' and '%s' both have sequence number %d and were " +
+	"applied on both sides of the merge; roll back one of them and give it a new sequence number before merging")
+
+// Migration is a versioned schema migration.
+type Migration struct {
+	// Sequence orders the migration among the other migrations of the database. Migrations are applied in ascending
+	// and rolled back in descending order of their sequence numbers.
+	Sequence int64
+	// Name is the unique name of the migration.
+	Name string
+	// Up is the script which applies the migration.
+	Up string
+	// Down is the script which reverts the migration. Migrations without one cannot be rolled back.
+	Down string
+	// Applied is true once the Up script of the migration has run against the root the migration is stored in.
+	Applied bool
+	// CreatedAt is the time the migration was added, in seconds since the epoch.
+	CreatedAt int64
+}
+
+// Database is a database which can store migrations.
+type Database interface {
+	sql.Database
+	// AddSchemaMigration adds |m| to the dolt_schemas table of the database, creating the table if it does not exist.
+	AddSchemaMigration(ctx *sql.Context, m Migration) error
+}
+
+// Extra is the document stored in the extra column of the dolt_schemas row of a migration. The up script is stored in
+// the fragment column of the row.
+type Extra struct {
+	CreatedAt int64
+	Sequence  int64
+	Down      string `json:",omitempty"`
+	Applied   bool   `json:",omitempty"`
+}
+
+// NewExtra returns the document to store in the extra column of the dolt_schemas row of |m|.
+func NewExtra(m Migration) Extra {
+	return Extra{
+		CreatedAt: m.CreatedAt,
+		Sequence:  m.Sequence,
+		Down:      m.Down,
+		Applied:   m.Applied,
+	}
+}
+
+// Load returns the migrations stored in the dolt_schemas table of |root|, ordered by sequence number. Like
+// dolt_ignore, migrations are not supported for the legacy storage format.
+func Load(ctx context.Context, root doltdb.RootValue) ([]Migration, error) {
+	st, ok, err := loadSchemasTable(ctx, root)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var migrations []Migration
+	err = st.iter(ctx, func(m Migration, _, _ val.Tuple) error {
+		migrations = append(migrations, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Sort(migrations)
+	return migrations, nil
+}
+
+// Update rewrites the rows of |migrations| in the dolt_schemas table of |root|. The migrations are matched by name,
+// and must already be stored in |root|.
+func Update(ctx context.Context, root doltdb.RootValue, migrations ...Migration) (doltdb.RootValue, error) {
+	if len(migrations) == 0 {
+		return root, nil
+	}
+	st, ok, err := loadSchemasTable(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("cannot update schema migration '%s': %s does not exist", migrations[0].Name, doltdb.SchemasTableName)
+	}
+
+	byName := make(map[string]Migration, len(migrations))
+	for _, m := range migrations {
+		byName[m.Name] = m
+	}
+
+	ns := st.rows.NodeStore()
+	vd := st.rows.ValDesc()
+	mut := st.rows.Mutate()
+	err = st.iter(ctx, func(old Migration, k, v val.Tuple) error {
+		m, ok := byName[old.Name]
+		if !ok {
+			return nil
+		}
+		delete(byName, old.Name)
+
+		extra, err := json.Marshal(NewExtra(m))
+		if err != nil {
+			return err
+		}
+
+		tb := val.NewTupleBuilder(vd)
+		for i := 0; i < vd.Count(); i++ {
+			switch i {
+			case st.fragmentIdx:
+				err = tree.PutField(ctx, ns, tb, i, m.Up)
+			case st.extraIdx:
+				err = tree.PutField(ctx, ns, tb, i, extra)
+			default:
+				tb.PutRaw(i, vd.GetField(i, v))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return mut.Put(ctx, k, tb.Build(ns.Pool()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for name := range byName {
+		return nil, fmt.Errorf("cannot update schema migration '%s': it does not exist", name)
+	}
+
+	rows, err := mut.Map(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, err := st.tbl.UpdateRows(ctx, durable.IndexFromProllyMap(rows))
+	if err != nil {
+		return nil, err
+	}
+	return root.PutTable(ctx, doltdb.TableName{Name: doltdb.SchemasTableName}, tbl)
+}
+
+// Sort orders |migrations| by sequence number, and migrations with the same sequence number by name.
+func Sort(migrations []Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		if migrations[i].Sequence != migrations[j].Sequence {
+			return migrations[i].Sequence < migrations[j].Sequence
+		}
+		return migrations[i].Name < migrations[j].Name
+	})
+}
+
+// NextSequence returns the sequence number following those of |migrations|.
+func NextSequence(migrations []Migration) int64 {
+	var max int64
+	for _, m := range migrations {
+		if m.Sequence > max {
+			max = m.Sequence
+		}
+	}
+	return max + 1
+}
+
+// schemasTable is the dolt_schemas table of a root, with the positions of the columns migrations are read from.
+type schemasTable struct {
+	tbl         *doltdb.Table
+	rows        prolly.Map
+	typeIdx     int
+	nameIdx     int
+	fragmentIdx int
+	extraIdx    int
+}
+
+// loadSchemasTable returns the dolt_schemas table of |root|. It returns false if the table does not exist, or still
+// has one of the schemas which predate the extra column. Those tables cannot hold migrations, since they are
+// migrated to the current schema before any fragment is added to them.
+func loadSchemasTable(ctx context.Context, root doltdb.RootValue) (*schemasTable, bool, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if tbl.Format() == types.Format_LD_1 {
+		return nil, false, nil
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	st := &schemasTable{
+		tbl:         tbl,
+		typeIdx:     sch.GetPKCols().IndexOf(doltdb.SchemasTablesTypeCol),
+		nameIdx:     sch.GetPKCols().IndexOf(doltdb.SchemasTablesNameCol),
+		fragmentIdx: sch.GetNonPKCols().IndexOf(doltdb.SchemasTablesFragmentCol),
+		extraIdx:    sch.GetNonPKCols().IndexOf(doltdb.SchemasTablesExtraCol),
+	}
+	if st.typeIdx < 0 || st.nameIdx < 0 || st.fragmentIdx < 0 || st.extraIdx < 0 {
+		return nil, false, nil
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	st.rows = durable.ProllyMapFromIndex(idx)
+	return st, true, nil
+}
+
+// iter calls |cb| with each migration of the table and the key and value of its row.
+func (st *schemasTable) iter(ctx context.Context, cb func(m Migration, k, v val.Tuple) error) error {
+	ns := st.rows.NodeStore()
+	kd, vd := st.rows.Descriptors()
+	iter, err := st.rows.IterAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fragType, err := tree.GetField(ctx, kd, st.typeIdx, k, ns)
+		if err != nil {
+			return err
+		}
+		if fragType != FragmentType {
+			continue
+		}
+		name, err := tree.GetField(ctx, kd, st.nameIdx, k, ns)
+		if err != nil {
+			return err
+		}
+		up, err := tree.GetField(ctx, vd, st.fragmentIdx, v, ns)
+		if err != nil {
+			return err
+		}
+		doc, err := tree.GetField(ctx, vd, st.extraIdx, v, ns)
+		if err != nil {
+			return err
+		}
+		extra, err := decodeExtra(doc)
+		if err != nil {
+			return fmt.Errorf("invalid schema migration '%v': %w", name, err)
+		}
+
+		m := Migration{
+			Sequence:  extra.Sequence,
+			Name:      name.(string),
+			Down:      extra.Down,
+			Applied:   extra.Applied,
+			CreatedAt: extra.CreatedAt,
+		}
+		if up != nil {
+			m.Up = up.(string)
+		}
+		if err = cb(m, k, v); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeExtra(doc interface{}) (Extra, error) {
+	var extra Extra
+	if doc == nil {
+		return extra, nil
+	}
+	wrapper, ok := doc.(sql.JSONWrapper)
+	if !ok {
+		return extra, fmt.Errorf("unexpected type %T for %s", doc, doltdb.SchemasTablesExtraCol)
+	}
+	val, err := wrapper.ToInterface()
+	if err != nil {
+		return extra, err
+	}
+	buf, err := json.Marshal(val)
+	if err != nil {
+		return extra, err
+	}
+	err = json.Unmarshal(buf, &extra)
+	return extra, err
+}
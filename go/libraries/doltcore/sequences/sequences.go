@@ -0,0 +1,385 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sequences implements sequences, named generators of integers which are shared by the tables of a database.
+// Sequences are stored as rows of the dolt_sequences table, so they are versioned, diffed and merged with the rest of a
+// branch. Each row holds the definition of a sequence and the number of values reserved from it, which only ever
+// grows, so merges take the larger of the numbers on either side instead of conflicting.
+//
+// By default the values reserved on a branch follow those reserved on it before, so two branches reserve the same
+// values and rows which use them can collide when the branches are merged. A sequence with a branch range instead
+// reserves values for each branch in ranges of that many values, allocated to the branches of a server in turn, so
+// different branches never reserve the same value.
+package sequences
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+const (
+	// NameCol is the name of the column of the dolt_sequences table holding the sequence's name
+	NameCol = "name"
+	// StartValueCol is the name of the column of the dolt_sequences table holding the sequence's first value
+	StartValueCol = "start_value"
+	// IncrementCol is the name of the column of the dolt_sequences table holding the difference between the
+	// sequence's values
+	IncrementCol = "increment"
+	// MinValueCol is the name of the column of the dolt_sequences table holding the sequence's smallest value
+	MinValueCol = "min_value"
+	// MaxValueCol is the name of the column of the dolt_sequences table holding the sequence's largest value
+	MaxValueCol = "max_value"
+	// BranchRangeCol is the name of the column of the dolt_sequences table holding the number of values reserved
+	// for a branch at a time
+	BranchRangeCol = "branch_range"
+	// ReservedCol is the name of the column of the dolt_sequences table holding the number of values reserved
+	ReservedCol = "reserved"
+)
+
+var ErrSequenceExists = errors.NewKind("sequence '%s' already exists")
+var ErrSequenceNotFound = errors.NewKind("sequence '%s' does not exist")
+var ErrSequenceExhausted = errors.NewKind("sequence '%s' has run out of values")
+var ErrInvalidSequence = errors.NewKind("invalid sequence '%s': %s")
+
+// Sequence is a sequence of integers.
+type Sequence struct {
+	// Name is the unique, lower case name of the sequence.
+	Name string
+	// Start is the first value of the sequence.
+	Start int64
+	// Increment is the difference between consecutive values of the sequence. It is negative for descending
+	// sequences.
+	Increment int64
+	// MinValue and MaxValue bound the values of the sequence.
+	MinValue int64
+	MaxValue int64
+	// BranchRange is the number of values reserved for a branch at a time, or 0 if the values of a branch follow
+	// those reserved on it before.
+	BranchRange int64
+	// Reserved is the number of values reserved from the sequence.
+	Reserved int64
+}
+
+// New returns the sequence named |name| with the given increment, the default bounds for its direction, and its
+// first value at the bound it starts from.
+func New(name string, increment int64) Sequence {
+	seq := Sequence{
+		Name:      strings.ToLower(name),
+		Increment: increment,
+		MinValue:  1,
+		MaxValue:  math.MaxInt64,
+	}
+	if increment < 0 {
+		seq.MinValue, seq.MaxValue = math.MinInt64, -1
+		seq.Start = seq.MaxValue
+	} else {
+		seq.Start = seq.MinValue
+	}
+	return seq
+}
+
+// Validate returns an error if |seq| is not a valid sequence.
+func (seq Sequence) Validate() error {
+	switch {
+	case seq.Name == "":
+		return ErrInvalidSequence.New(seq.Name, "the name cannot be empty")
+	case seq.Name != strings.ToLower(seq.Name):
+		return ErrInvalidSequence.New(seq.Name, "the name must be lower case")
+	case seq.Increment == 0:
+		return ErrInvalidSequence.New(seq.Name, "the increment cannot be 0")
+	case seq.MinValue > seq.MaxValue:
+		return ErrInvalidSequence.New(seq.Name, fmt.Sprintf("the minimum value %d is greater than the maximum value %d", seq.MinValue, seq.MaxValue))
+	case seq.Start < seq.MinValue || seq.Start > seq.MaxValue:
+		return ErrInvalidSequence.New(seq.Name, fmt.Sprintf("the start value %d is not between %d and %d", seq.Start, seq.MinValue, seq.MaxValue))
+	case seq.BranchRange < 0:
+		return ErrInvalidSequence.New(seq.Name, "the branch range cannot be negative")
+	case seq.Reserved < 0:
+		return ErrInvalidSequence.New(seq.Name, "the number of reserved values cannot be negative")
+	}
+	return nil
+}
+
+// Value returns the value of the sequence at |pos|, the number of values before it. It returns ErrSequenceExhausted
+// if the value is out of the bounds of the sequence.
+func (seq Sequence) Value(pos int64) (int64, error) {
+	if pos < 0 {
+		return 0, fmt.Errorf("invalid position %d of sequence '%s'", pos, seq.Name)
+	}
+	// the distance to the bound and the step are computed as unsigned integers, which can hold the difference of
+	// any two int64 values
+	var span, step uint64
+	if seq.Increment > 0 {
+		span, step = uint64(seq.MaxValue)-uint64(seq.Start), uint64(seq.Increment)
+	} else {
+		span, step = uint64(seq.Start)-uint64(seq.MinValue), uint64(-seq.Increment)
+	}
+	if uint64(pos) > span/step {
+		return 0, ErrSequenceExhausted.New(seq.Name)
+	}
+	if seq.Increment > 0 {
+		return int64(uint64(seq.Start) + uint64(pos)*step), nil
+	}
+	return int64(uint64(seq.Start) - uint64(pos)*step), nil
+}
+
+// TableSchema returns the schema of the dolt_sequences system table.
+func TableSchema() (schema.Schema, error) {
+	intCol := func(name string, tag uint64) schema.Column {
+		return schema.Column{
+			Name:        name,
+			Tag:         tag,
+			Kind:        types.IntKind,
+			TypeInfo:    typeinfo.FromKind(types.IntKind),
+			Constraints: []schema.ColConstraint{schema.NotNullConstraint{}},
+		}
+	}
+	colCollection := schema.NewColCollection(
+		schema.Column{
+			Name:        NameCol,
+			Tag:         schema.DoltSequencesNameTag,
+			Kind:        types.StringKind,
+			IsPartOfPK:  true,
+			TypeInfo:    typeinfo.FromKind(types.StringKind),
+			Constraints: []schema.ColConstraint{schema.NotNullConstraint{}},
+		},
+		intCol(StartValueCol, schema.DoltSequencesStartValueTag),
+		intCol(IncrementCol, schema.DoltSequencesIncrementTag),
+		intCol(MinValueCol, schema.DoltSequencesMinValueTag),
+		intCol(MaxValueCol, schema.DoltSequencesMaxValueTag),
+		intCol(BranchRangeCol, schema.DoltSequencesBranchRangeTag),
+		intCol(ReservedCol, schema.DoltSequencesReservedTag),
+	)
+
+	return schema.NewSchema(colCollection, nil, schema.Collation_Default, nil, nil)
+}
+
+// Load returns the sequences stored in the dolt_sequences table of |root|, ordered by name. Like dolt_ignore,
+// sequences are not supported for the legacy storage format.
+func Load(ctx context.Context, root doltdb.RootValue) ([]Sequence, error) {
+	st, ok, err := loadSequencesTable(ctx, root)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var seqs []Sequence
+	iter, err := st.rows.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq, err := st.sequence(ctx, k, v)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i].Name < seqs[j].Name
+	})
+	return seqs, nil
+}
+
+// Get returns the sequence named |name| in |root|, and whether it exists.
+func Get(ctx context.Context, root doltdb.RootValue, name string) (Sequence, bool, error) {
+	st, ok, err := loadSequencesTable(ctx, root)
+	if err != nil || !ok {
+		return Sequence{}, false, err
+	}
+	key, err := st.key(ctx, name)
+	if err != nil {
+		return Sequence{}, false, err
+	}
+
+	var seq Sequence
+	var found bool
+	err = st.rows.Get(ctx, key, func(k, v val.Tuple) error {
+		if k == nil {
+			return nil
+		}
+		found = true
+		seq, err = st.sequence(ctx, k, v)
+		return err
+	})
+	return seq, found, err
+}
+
+// Put writes |seqs| to the dolt_sequences table of |root|, creating the table if it does not exist, and returns the
+// updated root. Existing sequences with the same names are replaced.
+func Put(ctx context.Context, root doltdb.RootValue, seqs ...Sequence) (doltdb.RootValue, error) {
+	for _, seq := range seqs {
+		if err := seq.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	st, ok, err := loadSequencesTable(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		sch, err := TableSchema()
+		if err != nil {
+			return nil, err
+		}
+		root, err = doltdb.CreateEmptyTable(ctx, root, doltdb.TableName{Name: doltdb.SequencesTableName}, sch)
+		if err != nil {
+			return nil, err
+		}
+		if st, ok, err = loadSequencesTable(ctx, root); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("cannot write to %s", doltdb.SequencesTableName)
+		}
+	}
+
+	ns := st.rows.NodeStore()
+	vd := st.rows.ValDesc()
+	mut := st.rows.Mutate()
+	for _, seq := range seqs {
+		key, err := st.key(ctx, seq.Name)
+		if err != nil {
+			return nil, err
+		}
+		vb := val.NewTupleBuilder(vd)
+		for i, v := range []int64{seq.Start, seq.Increment, seq.MinValue, seq.MaxValue, seq.BranchRange, seq.Reserved} {
+			vb.PutInt64(i, v)
+		}
+		if err = mut.Put(ctx, key, vb.Build(ns.Pool())); err != nil {
+			return nil, err
+		}
+	}
+	return st.update(ctx, root, mut)
+}
+
+// Delete removes the sequence named |name| from |root|, returning the updated root and whether the sequence existed.
+func Delete(ctx context.Context, root doltdb.RootValue, name string) (doltdb.RootValue, bool, error) {
+	st, ok, err := loadSequencesTable(ctx, root)
+	if err != nil || !ok {
+		return root, false, err
+	}
+	key, err := st.key(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok, err = st.rows.Has(ctx, key); err != nil || !ok {
+		return root, false, err
+	}
+	mut := st.rows.Mutate()
+	if err = mut.Delete(ctx, key); err != nil {
+		return nil, false, err
+	}
+	root, err = st.update(ctx, root, mut)
+	return root, err == nil, err
+}
+
+// sequencesTable is the dolt_sequences table of a root.
+type sequencesTable struct {
+	tbl  *doltdb.Table
+	rows prolly.Map
+}
+
+// loadSequencesTable returns the dolt_sequences table of |root|, and false if it does not exist.
+func loadSequencesTable(ctx context.Context, root doltdb.RootValue) (*sequencesTable, bool, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.SequencesTableName})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if tbl.Format() == types.Format_LD_1 {
+		return nil, false, nil
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	expected, err := TableSchema()
+	if err != nil {
+		return nil, false, err
+	}
+	if !schema.SchemasAreEqual(sch, expected) {
+		return nil, false, fmt.Errorf("%s does not have the expected schema", doltdb.SequencesTableName)
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return &sequencesTable{tbl: tbl, rows: durable.ProllyMapFromIndex(idx)}, true, nil
+}
+
+// key returns the key of the row of the sequence named |name|.
+func (st *sequencesTable) key(ctx context.Context, name string) (val.Tuple, error) {
+	ns := st.rows.NodeStore()
+	kb := val.NewTupleBuilder(st.rows.KeyDesc())
+	if err := tree.PutField(ctx, ns, kb, 0, strings.ToLower(name)); err != nil {
+		return nil, err
+	}
+	return kb.Build(ns.Pool()), nil
+}
+
+// sequence returns the sequence stored in the row with key |k| and value |v|.
+func (st *sequencesTable) sequence(ctx context.Context, k, v val.Tuple) (Sequence, error) {
+	ns := st.rows.NodeStore()
+	kd, vd := st.rows.Descriptors()
+	name, err := tree.GetField(ctx, kd, 0, k, ns)
+	if err != nil {
+		return Sequence{}, err
+	}
+
+	seq := Sequence{Name: name.(string)}
+	for i, f := range []*int64{&seq.Start, &seq.Increment, &seq.MinValue, &seq.MaxValue, &seq.BranchRange, &seq.Reserved} {
+		n, ok := vd.GetInt64(i, v)
+		if !ok {
+			return Sequence{}, ErrInvalidSequence.New(seq.Name, "a column is null")
+		}
+		*f = n
+	}
+	return seq, nil
+}
+
+// update returns |root| with the rows of the table replaced by those of |mut|.
+func (st *sequencesTable) update(ctx context.Context, root doltdb.RootValue, mut *prolly.MutableMap) (doltdb.RootValue, error) {
+	rows, err := mut.Map(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, err := st.tbl.UpdateRows(ctx, durable.IndexFromProllyMap(rows))
+	if err != nil {
+		return nil, err
+	}
+	return root.PutTable(ctx, doltdb.TableName{Name: doltdb.SequencesTableName}, tbl)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequences
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		seq      Sequence
+		pos      int64
+		expected int64
+		err      bool
+	}{
+		{name: "first value", seq: New("s", 1), pos: 0, expected: 1},
+		{name: "ascending", seq: Sequence{Name: "s", Start: 10, Increment: 5, MinValue: 1, MaxValue: 100}, pos: 3, expected: 25},
+		{name: "last value", seq: Sequence{Name: "s", Start: 10, Increment: 5, MinValue: 1, MaxValue: 100}, pos: 18, expected: 100},
+		{name: "past the last value", seq: Sequence{Name: "s", Start: 10, Increment: 5, MinValue: 1, MaxValue: 100}, pos: 19, err: true},
+		{name: "descending", seq: New("s", -2), pos: 3, expected: -7},
+		{name: "past the smallest value", seq: Sequence{Name: "s", Start: 3, Increment: -2, MinValue: 0, MaxValue: 3}, pos: 2, err: true},
+		{name: "largest int64", seq: Sequence{Name: "s", Start: math.MinInt64, Increment: math.MaxInt64, MinValue: math.MinInt64, MaxValue: math.MaxInt64}, pos: 2, expected: math.MaxInt64 - 1},
+		{name: "past the largest int64", seq: Sequence{Name: "s", Start: math.MinInt64, Increment: math.MaxInt64, MinValue: math.MinInt64, MaxValue: math.MaxInt64}, pos: 3, err: true},
+		{name: "smallest int64", seq: Sequence{Name: "s", Start: math.MaxInt64, Increment: math.MinInt64, MinValue: math.MinInt64, MaxValue: math.MaxInt64}, pos: 1, expected: -1},
+		{name: "negative position", seq: New("s", 1), pos: -1, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := test.seq.Value(test.pos)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, New("s", 1).Validate())
+	assert.NoError(t, New("s", -1).Validate())
+
+	invalid := []Sequence{
+		{Start: 1, Increment: 1, MinValue: 1, MaxValue: 10},
+		{Name: "S", Start: 1, Increment: 1, MinValue: 1, MaxValue: 10},
+		{Name: "s", Start: 1, Increment: 0, MinValue: 1, MaxValue: 10},
+		{Name: "s", Start: 1, Increment: 1, MinValue: 10, MaxValue: 1},
+		{Name: "s", Start: 11, Increment: 1, MinValue: 1, MaxValue: 10},
+		{Name: "s", Start: 1, Increment: 1, MinValue: 1, MaxValue: 10, BranchRange: -1},
+		{Name: "s", Start: 1, Increment: 1, MinValue: 1, MaxValue: 10, Reserved: -1},
+	}
+	for _, seq := range invalid {
+		assert.Error(t, seq.Validate(), "%+v", seq)
+	}
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	defer dEnv.DoltDB.Close()
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	seqs, err := Load(ctx, root)
+	require.NoError(t, err)
+	assert.Empty(t, seqs)
+	_, ok, err := Get(ctx, root, "b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	b := New("b", 1)
+	a := Sequence{Name: "a", Start: -5, Increment: -5, MinValue: -100, MaxValue: 0, BranchRange: 10, Reserved: 3}
+	root, err = Put(ctx, root, b, a)
+	require.NoError(t, err)
+
+	seqs, err = Load(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, []Sequence{a, b}, seqs)
+
+	b.Reserved = 7
+	root, err = Put(ctx, root, b)
+	require.NoError(t, err)
+	got, ok, err := Get(ctx, root, "B")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, b, got)
+
+	_, err = Put(ctx, root, Sequence{Name: "c"})
+	assert.True(t, ErrInvalidSequence.Is(err))
+
+	root, ok, err = Delete(ctx, root, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	root, ok, err = Delete(ctx, root, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	seqs, err = Load(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, []Sequence{b}, seqs)
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -59,6 +60,11 @@ const (
 	DefaultUnixSocketFilePath      = "/tmp/mysql.sock"
 	DefaultMaxLoggedQueryLen       = 0
 	DefaultEncodeLoggedQuery       = false
+
+	DefaultClusterFailoverHeartbeatIntervalMillis = 1000
+	DefaultClusterFailoverLeaseDurationMillis     = 5000
+
+	DefaultHTTPAPIMaxRows = 10000
 )
 
 const (
@@ -95,6 +101,66 @@ type ClusterConfig interface {
 	BootstrapRole() string
 	BootstrapEpoch() int
 	RemotesAPIConfig() ClusterRemotesAPIConfig
+	// FailoverConfig is the configuration for automatic failover, or nil if failover is manual.
+	FailoverConfig() ClusterFailoverConfig
+	// WriteQuorumConfig is the configuration for acknowledging writes on the primary, or nil if every standby is
+	// waited on and replication failures only result in warnings.
+	WriteQuorumConfig() ClusterWriteQuorumConfig
+}
+
+// ClusterFailoverConfig configures automatic failover among the members of a cluster. Every member of the cluster must
+// configure failover with the same settings, and must list every other member in its standby_remotes.
+type ClusterFailoverConfig interface {
+	// HeartbeatIntervalMillis is how often a primary sends a heartbeat to each of its standbys.
+	HeartbeatIntervalMillis() int
+	// LeaseDurationMillis is how long a primary remains primary without a majority of the cluster acknowledging its
+	// heartbeats, and how long a standby waits without a heartbeat before it stands for election.
+	LeaseDurationMillis() int
+}
+
+// ClusterWriteQuorumConfig configures how many standbys must acknowledge a write on the primary before the write is
+// acknowledged to the client. It only applies when dolt_cluster_ack_writes_timeout_secs is non-zero.
+type ClusterWriteQuorumConfig interface {
+	ClusterWriteQuorum
+	// Databases overrides the quorum settings for individual databases.
+	Databases() []ClusterDatabaseWriteQuorumConfig
+}
+
+// ClusterWriteQuorum are the settings for acknowledging a write on the primary.
+type ClusterWriteQuorum interface {
+	// Acks is the number of standbys which must acknowledge a write. 0 means every standby.
+	Acks() int
+	// Strict makes a write which is not acknowledged by Acks() standbys before the timeout fail with an error, instead
+	// of succeeding with a warning.
+	Strict() bool
+	// ReadOnlyOnFailure makes the primary read only after a strict write fails to reach its quorum, until enough
+	// standbys have caught up.
+	ReadOnlyOnFailure() bool
+}
+
+// ClusterDatabaseWriteQuorumConfig overrides the write quorum settings for a single database.
+type ClusterDatabaseWriteQuorumConfig interface {
+	Name() string
+	ClusterWriteQuorum
+}
+
+// ProtectedBranchConfig restricts how pushes to a sql-server's remotesapi interface may update the branches matching
+// Branch, which is a pattern in the syntax of path.Match.
+type ProtectedBranchConfig interface {
+	Branch() string
+	// AllowForcePush is true if pushes may update the branch to a commit which does not descend from its head.
+	AllowForcePush() bool
+	// AllowDeletion is true if pushes may delete the branch.
+	AllowDeletion() bool
+	// VerifyConstraints is true if pushes are rejected when the new head of the branch has constraint violations.
+	VerifyConstraints() bool
+	// Checks are queries which must return no rows at the new head of the branch.
+	Checks() []ProtectedBranchCheckConfig
+}
+
+type ProtectedBranchCheckConfig interface {
+	Name() string
+	Query() string
 }
 
 type ClusterRemotesAPIConfig interface {
@@ -196,8 +262,28 @@ type ServerConfig interface {
 	RemotesapiPort() *int
 	// RemotesapiReadOnly is true if the remotesapi interface should be read only.
 	RemotesapiReadOnly() *bool
+	// RemotesapiProtectedBranches are the rules that pushes to the remotesapi interface must follow when they update
+	// the matching branches.
+	RemotesapiProtectedBranches() []ProtectedBranchConfig
 	// ClusterConfig is the configuration for clustering in this sql-server.
 	ClusterConfig() ClusterConfig
+	// PostgresListenerHost is the host address for the Postgres wire protocol listener.
+	PostgresListenerHost() string
+	// PostgresListenerPort is the port for the Postgres wire protocol listener. When it is nil, the sql-server only
+	// accepts MySQL protocol connections.
+	PostgresListenerPort() *int
+	// HTTPAPIHost is the host address for the HTTP query API.
+	HTTPAPIHost() string
+	// HTTPAPIPort is the port for the HTTP query API. When it is nil, the API is disabled. The API is served over HTTPS
+	// when a tls_key and tls_cert are configured.
+	HTTPAPIPort() *int
+	// HTTPAPIReadOnly is true if the HTTP query API should reject queries which write.
+	HTTPAPIReadOnly() bool
+	// HTTPAPIQueryTimeoutMillis is the longest time a query of the HTTP query API may run. 0 means no limit.
+	HTTPAPIQueryTimeoutMillis() uint64
+	// HTTPAPIMaxRows is the largest number of rows returned in a JSON document by the HTTP query API. Results with more
+	// rows are truncated. Streamed results are not limited.
+	HTTPAPIMaxRows() uint64
 	// EventSchedulerStatus is the configuration for enabling or disabling the event scheduler in this server.
 	EventSchedulerStatus() string
 	// ValueSet returns whether the value string provided was explicitly set in the config
@@ -269,9 +355,49 @@ func ValidateConfig(config ServerConfig) error {
 	if config.RequireSecureTransport() && config.TLSCert() == "" && config.TLSKey() == "" {
 		return fmt.Errorf("require_secure_transport can only be `true` when a tls_key and tls_cert are provided.")
 	}
+	if port := config.PostgresListenerPort(); port != nil {
+		if *port < 1024 || *port > 65535 {
+			return fmt.Errorf("postgres_listener: port is not in the range between 1024-65535: %v\n", *port)
+		}
+		if *port == config.Port() {
+			return fmt.Errorf("postgres_listener: port must differ from the listener port: %v\n", *port)
+		}
+	}
+	if port := config.HTTPAPIPort(); port != nil {
+		if *port < 1024 || *port > 65535 {
+			return fmt.Errorf("http_api: port is not in the range between 1024-65535: %v\n", *port)
+		}
+		if *port == config.Port() {
+			return fmt.Errorf("http_api: port must differ from the listener port: %v\n", *port)
+		}
+		if pgPort := config.PostgresListenerPort(); pgPort != nil && *pgPort == *port {
+			return fmt.Errorf("http_api: port must differ from the postgres_listener port: %v\n", *port)
+		}
+	}
+	if err := ValidateProtectedBranches(config.RemotesapiProtectedBranches()); err != nil {
+		return err
+	}
 	return ValidateClusterConfig(config.ClusterConfig())
 }
 
+// ValidateProtectedBranches returns an error if any of |branches| has an invalid branch pattern or check.
+func ValidateProtectedBranches(branches []ProtectedBranchConfig) error {
+	for _, b := range branches {
+		if b.Branch() == "" {
+			return fmt.Errorf("remotesapi: protected_branches: branch is required\n")
+		}
+		if _, err := path.Match(b.Branch(), ""); err != nil {
+			return fmt.Errorf("remotesapi: protected_branches: invalid branch pattern %q: %v\n", b.Branch(), err)
+		}
+		for _, c := range b.Checks() {
+			if strings.TrimSpace(c.Query()) == "" {
+				return fmt.Errorf("remotesapi: protected_branches: check %q of branch %q has no query\n", c.Name(), b.Branch())
+			}
+		}
+	}
+	return nil
+}
+
 const (
 	MaxConnectionsKey = "max_connections"
 	ReadTimeoutKey    = "net_read_timeout"
@@ -347,6 +473,35 @@ func ValidateClusterConfig(config ClusterConfig) error {
 	if config.RemotesAPIConfig().TLSKey() != "" && config.RemotesAPIConfig().TLSCert() == "" {
 		return fmt.Errorf("cluster: remotesapi: tls_cert: must supply a tls_cert if you supply a tls_key")
 	}
+	if failover := config.FailoverConfig(); failover != nil {
+		if len(remotes) < 2 {
+			return errors.New("cluster: failover: requires at least two standby_remotes, so that a majority of the cluster can elect a new primary")
+		}
+		if failover.HeartbeatIntervalMillis() <= 0 {
+			return fmt.Errorf("cluster: failover: heartbeat_interval_millis: is %d but must be > 0", failover.HeartbeatIntervalMillis())
+		}
+		if failover.LeaseDurationMillis() < 2*failover.HeartbeatIntervalMillis() {
+			return fmt.Errorf("cluster: failover: lease_duration_millis: is %d but must be at least twice heartbeat_interval_millis", failover.LeaseDurationMillis())
+		}
+	}
+	if quorum := config.WriteQuorumConfig(); quorum != nil {
+		if err := validateClusterWriteQuorum("cluster: write_quorum", quorum, len(remotes)); err != nil {
+			return err
+		}
+		databases := make(map[string]struct{})
+		for i, db := range quorum.Databases() {
+			if db.Name() == "" {
+				return fmt.Errorf("cluster: write_quorum: databases: %d: name: must supply a database name", i)
+			}
+			if _, ok := databases[strings.ToLower(db.Name())]; ok {
+				return fmt.Errorf("cluster: write_quorum: databases: %d: name: duplicate entry for database %s", i, db.Name())
+			}
+			databases[strings.ToLower(db.Name())] = struct{}{}
+			if err := validateClusterWriteQuorum(fmt.Sprintf("cluster: write_quorum: databases: %s", db.Name()), db, len(remotes)); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -418,3 +573,13 @@ func CheckForUnixSocket(config ServerConfig) (string, bool, error) {
 
 	return "", false, nil
 }
+
+func validateClusterWriteQuorum(prefix string, quorum ClusterWriteQuorum, numRemotes int) error {
+	if quorum.Acks() < 0 || quorum.Acks() > numRemotes {
+		return fmt.Errorf("%s: acks: is %d but must be between 0 and the number of standby_remotes, %d", prefix, quorum.Acks(), numRemotes)
+	}
+	if quorum.ReadOnlyOnFailure() && !quorum.Strict() {
+		return fmt.Errorf("%s: read_only_on_failure: requires strict: true", prefix)
+	}
+	return nil
+}
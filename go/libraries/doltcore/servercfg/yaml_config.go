@@ -47,6 +47,13 @@ func nillableIntPtr(n int) *int {
 	return &n
 }
 
+func nillableUint64Ptr(n uint64) *uint64 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
 // BehaviorYAMLConfig contains server configuration regarding how the server should behave
 type BehaviorYAMLConfig struct {
 	ReadOnly   *bool `yaml:"read_only"`
@@ -104,8 +111,9 @@ type MetricsYAMLConfig struct {
 }
 
 type RemotesapiYAMLConfig struct {
-	Port_     *int  `yaml:"port,omitempty"`
-	ReadOnly_ *bool `yaml:"read_only,omitempty" minver:"1.30.5"`
+	Port_              *int                        `yaml:"port,omitempty"`
+	ReadOnly_          *bool                       `yaml:"read_only,omitempty" minver:"1.30.5"`
+	ProtectedBranches_ []ProtectedBranchYAMLConfig `yaml:"protected_branches,omitempty" minver:"TBD"`
 }
 
 func (r RemotesapiYAMLConfig) Port() int {
@@ -116,6 +124,72 @@ func (r RemotesapiYAMLConfig) ReadOnly() bool {
 	return *r.ReadOnly_
 }
 
+// ProtectedBranchYAMLConfig contains the rules pushes to the remotesapi must follow when updating the branches
+// matching Branch_
+type ProtectedBranchYAMLConfig struct {
+	Branch_            string                           `yaml:"branch"`
+	AllowForcePush_    *bool                            `yaml:"allow_force_push,omitempty"`
+	AllowDeletion_     *bool                            `yaml:"allow_deletion,omitempty"`
+	VerifyConstraints_ *bool                            `yaml:"verify_constraints,omitempty"`
+	Checks_            []ProtectedBranchCheckYAMLConfig `yaml:"checks,omitempty"`
+}
+
+var _ ProtectedBranchConfig = ProtectedBranchYAMLConfig{}
+
+func (p ProtectedBranchYAMLConfig) Branch() string {
+	return p.Branch_
+}
+
+func (p ProtectedBranchYAMLConfig) AllowForcePush() bool {
+	return p.AllowForcePush_ != nil && *p.AllowForcePush_
+}
+
+func (p ProtectedBranchYAMLConfig) AllowDeletion() bool {
+	return p.AllowDeletion_ != nil && *p.AllowDeletion_
+}
+
+func (p ProtectedBranchYAMLConfig) VerifyConstraints() bool {
+	return p.VerifyConstraints_ != nil && *p.VerifyConstraints_
+}
+
+func (p ProtectedBranchYAMLConfig) Checks() []ProtectedBranchCheckConfig {
+	checks := make([]ProtectedBranchCheckConfig, len(p.Checks_))
+	for i := range p.Checks_ {
+		checks[i] = p.Checks_[i]
+	}
+	return checks
+}
+
+// ProtectedBranchCheckYAMLConfig is a query which must return no rows at the new head of a protected branch for a
+// push to be accepted
+type ProtectedBranchCheckYAMLConfig struct {
+	Name_  string `yaml:"name"`
+	Query_ string `yaml:"query"`
+}
+
+func (c ProtectedBranchCheckYAMLConfig) Name() string {
+	return c.Name_
+}
+
+func (c ProtectedBranchCheckYAMLConfig) Query() string {
+	return c.Query_
+}
+
+// PostgresListenerYAMLConfig contains the configuration for the optional Postgres wire protocol listener
+type PostgresListenerYAMLConfig struct {
+	HostStr    *string `yaml:"host,omitempty" minver:"TBD"`
+	PortNumber *int    `yaml:"port,omitempty" minver:"TBD"`
+}
+
+// HTTPAPIYAMLConfig contains the configuration for the optional HTTP query API
+type HTTPAPIYAMLConfig struct {
+	HostStr            *string `yaml:"host,omitempty" minver:"TBD"`
+	PortNumber         *int    `yaml:"port,omitempty" minver:"TBD"`
+	ReadOnly_          *bool   `yaml:"read_only,omitempty" minver:"TBD"`
+	QueryTimeoutMillis *uint64 `yaml:"query_timeout_millis,omitempty" minver:"TBD"`
+	MaxRows            *uint64 `yaml:"max_rows,omitempty" minver:"TBD"`
+}
+
 type UserSessionVars struct {
 	Name string            `yaml:"name"`
 	Vars map[string]string `yaml:"vars"`
@@ -135,8 +209,12 @@ type YAMLConfig struct {
 	MetricsConfig     MetricsYAMLConfig     `yaml:"metrics"`
 	RemotesapiConfig  RemotesapiYAMLConfig  `yaml:"remotesapi"`
 	ClusterCfg        *ClusterYAMLConfig    `yaml:"cluster,omitempty"`
-	PrivilegeFile     *string               `yaml:"privilege_file,omitempty"`
-	BranchControlFile *string               `yaml:"branch_control_file,omitempty"`
+	// PostgresListener configures an optional listener for the Postgres wire protocol
+	PostgresListener *PostgresListenerYAMLConfig `yaml:"postgres_listener,omitempty" minver:"TBD"`
+	// HTTPAPI configures an optional HTTP listener for running queries
+	HTTPAPI           *HTTPAPIYAMLConfig `yaml:"http_api,omitempty" minver:"TBD"`
+	PrivilegeFile     *string            `yaml:"privilege_file,omitempty"`
+	BranchControlFile *string            `yaml:"branch_control_file,omitempty"`
 	// TODO: Rename to UserVars_
 	Vars            []UserSessionVars      `yaml:"user_session_vars"`
 	SystemVars_     map[string]interface{} `yaml:"system_variables,omitempty" minver:"1.11.1"`
@@ -214,10 +292,13 @@ func ServerConfigAsYAMLConfig(cfg ServerConfig) *YAMLConfig {
 			Port:   ptr(cfg.MetricsPort()),
 		},
 		RemotesapiConfig: RemotesapiYAMLConfig{
-			Port_:     cfg.RemotesapiPort(),
-			ReadOnly_: cfg.RemotesapiReadOnly(),
+			Port_:              cfg.RemotesapiPort(),
+			ReadOnly_:          cfg.RemotesapiReadOnly(),
+			ProtectedBranches_: protectedBranchesAsYAMLConfig(cfg.RemotesapiProtectedBranches()),
 		},
 		ClusterCfg:        clusterConfigAsYAMLConfig(cfg.ClusterConfig()),
+		PostgresListener:  postgresListenerConfigAsYAMLConfig(cfg),
+		HTTPAPI:           httpAPIConfigAsYAMLConfig(cfg),
 		PrivilegeFile:     ptr(cfg.PrivilegeFilePath()),
 		BranchControlFile: ptr(cfg.BranchControlFilePath()),
 		SystemVars_:       systemVars,
@@ -231,10 +312,37 @@ func clusterConfigAsYAMLConfig(config ClusterConfig) *ClusterYAMLConfig {
 		return nil
 	}
 
+	var failover *ClusterFailoverYAMLConfig
+	if failoverConfig := config.FailoverConfig(); failoverConfig != nil {
+		failover = &ClusterFailoverYAMLConfig{
+			HeartbeatIntervalMillis_: ptr(failoverConfig.HeartbeatIntervalMillis()),
+			LeaseDurationMillis_:     ptr(failoverConfig.LeaseDurationMillis()),
+		}
+	}
+
+	var writeQuorum *ClusterWriteQuorumYAMLConfig
+	if quorumConfig := config.WriteQuorumConfig(); quorumConfig != nil {
+		writeQuorum = &ClusterWriteQuorumYAMLConfig{
+			Acks_:              ptr(quorumConfig.Acks()),
+			Strict_:            ptr(quorumConfig.Strict()),
+			ReadOnlyOnFailure_: ptr(quorumConfig.ReadOnlyOnFailure()),
+		}
+		for _, db := range quorumConfig.Databases() {
+			writeQuorum.Databases_ = append(writeQuorum.Databases_, ClusterDatabaseWriteQuorumYAMLConfig{
+				Name_:              db.Name(),
+				Acks_:              ptr(db.Acks()),
+				Strict_:            ptr(db.Strict()),
+				ReadOnlyOnFailure_: ptr(db.ReadOnlyOnFailure()),
+			})
+		}
+	}
+
 	return &ClusterYAMLConfig{
 		StandbyRemotes_: nil,
 		BootstrapRole_:  config.BootstrapRole(),
 		BootstrapEpoch_: config.BootstrapEpoch(),
+		Failover_:       failover,
+		WriteQuorum_:    writeQuorum,
 		RemotesAPI: ClusterRemotesAPIYAMLConfig{
 			Addr_:      config.RemotesAPIConfig().Address(),
 			Port_:      config.RemotesAPIConfig().Port(),
@@ -247,6 +355,53 @@ func clusterConfigAsYAMLConfig(config ClusterConfig) *ClusterYAMLConfig {
 	}
 }
 
+func protectedBranchesAsYAMLConfig(branches []ProtectedBranchConfig) []ProtectedBranchYAMLConfig {
+	if len(branches) == 0 {
+		return nil
+	}
+
+	yamlBranches := make([]ProtectedBranchYAMLConfig, len(branches))
+	for i, b := range branches {
+		checks := make([]ProtectedBranchCheckYAMLConfig, len(b.Checks()))
+		for j, c := range b.Checks() {
+			checks[j] = ProtectedBranchCheckYAMLConfig{Name_: c.Name(), Query_: c.Query()}
+		}
+		yamlBranches[i] = ProtectedBranchYAMLConfig{
+			Branch_:            b.Branch(),
+			AllowForcePush_:    nillableBoolPtr(b.AllowForcePush()),
+			AllowDeletion_:     nillableBoolPtr(b.AllowDeletion()),
+			VerifyConstraints_: nillableBoolPtr(b.VerifyConstraints()),
+			Checks_:            checks,
+		}
+	}
+	return yamlBranches
+}
+
+func postgresListenerConfigAsYAMLConfig(cfg ServerConfig) *PostgresListenerYAMLConfig {
+	if cfg.PostgresListenerPort() == nil {
+		return nil
+	}
+
+	return &PostgresListenerYAMLConfig{
+		HostStr:    ptr(cfg.PostgresListenerHost()),
+		PortNumber: cfg.PostgresListenerPort(),
+	}
+}
+
+func httpAPIConfigAsYAMLConfig(cfg ServerConfig) *HTTPAPIYAMLConfig {
+	if cfg.HTTPAPIPort() == nil {
+		return nil
+	}
+
+	return &HTTPAPIYAMLConfig{
+		HostStr:            ptr(cfg.HTTPAPIHost()),
+		PortNumber:         cfg.HTTPAPIPort(),
+		ReadOnly_:          nillableBoolPtr(cfg.HTTPAPIReadOnly()),
+		QueryTimeoutMillis: nillableUint64Ptr(cfg.HTTPAPIQueryTimeoutMillis()),
+		MaxRows:            ptr(cfg.HTTPAPIMaxRows()),
+	}
+}
+
 // String returns the YAML representation of the config
 func (cfg YAMLConfig) String() string {
 	data, err := yaml.Marshal(cfg)
@@ -427,6 +582,78 @@ func (cfg YAMLConfig) RemotesapiReadOnly() *bool {
 	return cfg.RemotesapiConfig.ReadOnly_
 }
 
+func (cfg YAMLConfig) RemotesapiProtectedBranches() []ProtectedBranchConfig {
+	branches := make([]ProtectedBranchConfig, len(cfg.RemotesapiConfig.ProtectedBranches_))
+	for i := range cfg.RemotesapiConfig.ProtectedBranches_ {
+		branches[i] = cfg.RemotesapiConfig.ProtectedBranches_[i]
+	}
+	return branches
+}
+
+// PostgresListenerHost returns the host the Postgres wire protocol listener binds to. It defaults to the host of the
+// MySQL listener.
+func (cfg YAMLConfig) PostgresListenerHost() string {
+	if cfg.PostgresListener == nil || cfg.PostgresListener.HostStr == nil {
+		return cfg.Host()
+	}
+
+	return *cfg.PostgresListener.HostStr
+}
+
+// PostgresListenerPort returns the port of the Postgres wire protocol listener, or nil if it is not enabled.
+func (cfg YAMLConfig) PostgresListenerPort() *int {
+	if cfg.PostgresListener == nil {
+		return nil
+	}
+
+	return cfg.PostgresListener.PortNumber
+}
+
+// HTTPAPIHost returns the host the HTTP query API binds to. It defaults to the host of the MySQL listener.
+func (cfg YAMLConfig) HTTPAPIHost() string {
+	if cfg.HTTPAPI == nil || cfg.HTTPAPI.HostStr == nil {
+		return cfg.Host()
+	}
+
+	return *cfg.HTTPAPI.HostStr
+}
+
+// HTTPAPIPort returns the port of the HTTP query API, or nil if it is not enabled.
+func (cfg YAMLConfig) HTTPAPIPort() *int {
+	if cfg.HTTPAPI == nil {
+		return nil
+	}
+
+	return cfg.HTTPAPI.PortNumber
+}
+
+// HTTPAPIReadOnly returns whether the HTTP query API only accepts read only queries.
+func (cfg YAMLConfig) HTTPAPIReadOnly() bool {
+	if cfg.HTTPAPI == nil || cfg.HTTPAPI.ReadOnly_ == nil {
+		return false
+	}
+
+	return *cfg.HTTPAPI.ReadOnly_
+}
+
+// HTTPAPIQueryTimeoutMillis returns the longest time a query of the HTTP query API may run, 0 meaning no limit.
+func (cfg YAMLConfig) HTTPAPIQueryTimeoutMillis() uint64 {
+	if cfg.HTTPAPI == nil || cfg.HTTPAPI.QueryTimeoutMillis == nil {
+		return 0
+	}
+
+	return *cfg.HTTPAPI.QueryTimeoutMillis
+}
+
+// HTTPAPIMaxRows returns the largest number of rows returned in a JSON document by the HTTP query API.
+func (cfg YAMLConfig) HTTPAPIMaxRows() uint64 {
+	if cfg.HTTPAPI == nil || cfg.HTTPAPI.MaxRows == nil {
+		return DefaultHTTPAPIMaxRows
+	}
+
+	return *cfg.HTTPAPI.MaxRows
+}
+
 // PrivilegeFilePath returns the path to the file which contains all needed privilege information in the form of a
 // JSON string.
 func (cfg YAMLConfig) PrivilegeFilePath() string {
@@ -593,10 +820,12 @@ func (cfg YAMLConfig) EventSchedulerStatus() string {
 }
 
 type ClusterYAMLConfig struct {
-	StandbyRemotes_ []StandbyRemoteYAMLConfig   `yaml:"standby_remotes"`
-	BootstrapRole_  string                      `yaml:"bootstrap_role"`
-	BootstrapEpoch_ int                         `yaml:"bootstrap_epoch"`
-	RemotesAPI      ClusterRemotesAPIYAMLConfig `yaml:"remotesapi"`
+	StandbyRemotes_ []StandbyRemoteYAMLConfig     `yaml:"standby_remotes"`
+	BootstrapRole_  string                        `yaml:"bootstrap_role"`
+	BootstrapEpoch_ int                           `yaml:"bootstrap_epoch"`
+	RemotesAPI      ClusterRemotesAPIYAMLConfig   `yaml:"remotesapi"`
+	Failover_       *ClusterFailoverYAMLConfig    `yaml:"failover,omitempty" minver:"TBD"`
+	WriteQuorum_    *ClusterWriteQuorumYAMLConfig `yaml:"write_quorum,omitempty" minver:"TBD"`
 }
 
 type StandbyRemoteYAMLConfig struct {
@@ -632,6 +861,110 @@ func (c *ClusterYAMLConfig) RemotesAPIConfig() ClusterRemotesAPIConfig {
 	return c.RemotesAPI
 }
 
+func (c *ClusterYAMLConfig) FailoverConfig() ClusterFailoverConfig {
+	if c.Failover_ == nil {
+		return nil
+	}
+	return c.Failover_
+}
+
+type ClusterFailoverYAMLConfig struct {
+	HeartbeatIntervalMillis_ *int `yaml:"heartbeat_interval_millis,omitempty"`
+	LeaseDurationMillis_     *int `yaml:"lease_duration_millis,omitempty"`
+}
+
+func (c *ClusterFailoverYAMLConfig) HeartbeatIntervalMillis() int {
+	if c.HeartbeatIntervalMillis_ == nil {
+		return DefaultClusterFailoverHeartbeatIntervalMillis
+	}
+	return *c.HeartbeatIntervalMillis_
+}
+
+func (c *ClusterFailoverYAMLConfig) LeaseDurationMillis() int {
+	if c.LeaseDurationMillis_ == nil {
+		return DefaultClusterFailoverLeaseDurationMillis
+	}
+	return *c.LeaseDurationMillis_
+}
+
+func (c *ClusterYAMLConfig) WriteQuorumConfig() ClusterWriteQuorumConfig {
+	if c.WriteQuorum_ == nil {
+		return nil
+	}
+	return c.WriteQuorum_
+}
+
+type ClusterWriteQuorumYAMLConfig struct {
+	Acks_              *int                                   `yaml:"acks,omitempty"`
+	Strict_            *bool                                  `yaml:"strict,omitempty"`
+	ReadOnlyOnFailure_ *bool                                  `yaml:"read_only_on_failure,omitempty"`
+	Databases_         []ClusterDatabaseWriteQuorumYAMLConfig `yaml:"databases,omitempty"`
+}
+
+func (c *ClusterWriteQuorumYAMLConfig) Acks() int {
+	if c.Acks_ == nil {
+		return 0
+	}
+	return *c.Acks_
+}
+
+func (c *ClusterWriteQuorumYAMLConfig) Strict() bool {
+	if c.Strict_ == nil {
+		return false
+	}
+	return *c.Strict_
+}
+
+func (c *ClusterWriteQuorumYAMLConfig) ReadOnlyOnFailure() bool {
+	if c.ReadOnlyOnFailure_ == nil {
+		return false
+	}
+	return *c.ReadOnlyOnFailure_
+}
+
+// Databases returns the per-database overrides. Settings which a database entry leaves unset are inherited from the
+// top-level write_quorum settings.
+func (c *ClusterWriteQuorumYAMLConfig) Databases() []ClusterDatabaseWriteQuorumConfig {
+	ret := make([]ClusterDatabaseWriteQuorumConfig, len(c.Databases_))
+	for i := range c.Databases_ {
+		db := c.Databases_[i]
+		if db.Acks_ == nil {
+			db.Acks_ = ptr(c.Acks())
+		}
+		if db.Strict_ == nil {
+			db.Strict_ = ptr(c.Strict())
+		}
+		if db.ReadOnlyOnFailure_ == nil {
+			db.ReadOnlyOnFailure_ = ptr(c.ReadOnlyOnFailure())
+		}
+		ret[i] = db
+	}
+	return ret
+}
+
+type ClusterDatabaseWriteQuorumYAMLConfig struct {
+	Name_              string `yaml:"name"`
+	Acks_              *int   `yaml:"acks,omitempty"`
+	Strict_            *bool  `yaml:"strict,omitempty"`
+	ReadOnlyOnFailure_ *bool  `yaml:"read_only_on_failure,omitempty"`
+}
+
+func (c ClusterDatabaseWriteQuorumYAMLConfig) Name() string {
+	return c.Name_
+}
+
+func (c ClusterDatabaseWriteQuorumYAMLConfig) Acks() int {
+	return *c.Acks_
+}
+
+func (c ClusterDatabaseWriteQuorumYAMLConfig) Strict() bool {
+	return *c.Strict_
+}
+
+func (c ClusterDatabaseWriteQuorumYAMLConfig) ReadOnlyOnFailure() bool {
+	return *c.ReadOnlyOnFailure_
+}
+
 type ClusterRemotesAPIYAMLConfig struct {
 	Addr_      string   `yaml:"address"`
 	Port_      int      `yaml:"port"`
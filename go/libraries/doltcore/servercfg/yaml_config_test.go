@@ -158,6 +158,96 @@ remotesapi:
 	require.Equal(t, 8000, *config.RemotesapiPort())
 }
 
+func TestUnmarshallPostgresListener(t *testing.T) {
+	testStr := `
+listener:
+  host: 0.0.0.0
+postgres_listener:
+  port: 5432
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	require.NotNil(t, config.PostgresListenerPort())
+	require.Equal(t, 5432, *config.PostgresListenerPort())
+	require.Equal(t, "0.0.0.0", config.PostgresListenerHost())
+	require.NoError(t, ValidateConfig(config))
+
+	config.PostgresListener.HostStr = ptr("127.0.0.1")
+	require.Equal(t, "127.0.0.1", config.PostgresListenerHost())
+
+	config.PostgresListener.PortNumber = ptr(config.Port())
+	require.Error(t, ValidateConfig(config))
+}
+
+func TestUnmarshallHTTPAPI(t *testing.T) {
+	testStr := `
+listener:
+  host: 0.0.0.0
+http_api:
+  port: 8080
+  read_only: true
+  query_timeout_millis: 5000
+  max_rows: 100
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	require.NotNil(t, config.HTTPAPIPort())
+	require.Equal(t, 8080, *config.HTTPAPIPort())
+	require.Equal(t, "0.0.0.0", config.HTTPAPIHost())
+	require.True(t, config.HTTPAPIReadOnly())
+	require.Equal(t, uint64(5000), config.HTTPAPIQueryTimeoutMillis())
+	require.Equal(t, uint64(100), config.HTTPAPIMaxRows())
+	require.NoError(t, ValidateConfig(config))
+
+	config.PostgresListener = &PostgresListenerYAMLConfig{PortNumber: ptr(8080)}
+	require.Error(t, ValidateConfig(config))
+
+	config.PostgresListener = nil
+	config.HTTPAPI.PortNumber = ptr(config.Port())
+	require.Error(t, ValidateConfig(config))
+}
+
+func TestUnmarshallProtectedBranches(t *testing.T) {
+	testStr := `
+remotesapi:
+  port: 50051
+  protected_branches:
+  - branch: main
+    verify_constraints: true
+    checks:
+    - name: no negative balances
+      query: SELECT * FROM accounts WHERE balance < 0
+  - branch: release/*
+    allow_force_push: true
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	branches := config.RemotesapiProtectedBranches()
+	require.Len(t, branches, 2)
+	require.Equal(t, "main", branches[0].Branch())
+	require.False(t, branches[0].AllowForcePush())
+	require.False(t, branches[0].AllowDeletion())
+	require.True(t, branches[0].VerifyConstraints())
+	require.Len(t, branches[0].Checks(), 1)
+	require.Equal(t, "no negative balances", branches[0].Checks()[0].Name())
+	require.Equal(t, "SELECT * FROM accounts WHERE balance < 0", branches[0].Checks()[0].Query())
+	require.Equal(t, "release/*", branches[1].Branch())
+	require.True(t, branches[1].AllowForcePush())
+	require.Empty(t, branches[1].Checks())
+	require.NoError(t, ValidateConfig(config))
+
+	roundTripped, err := NewYamlConfig([]byte(ServerConfigAsYAMLConfig(config).String()))
+	require.NoError(t, err)
+	require.Equal(t, config.RemotesapiConfig.ProtectedBranches_[0].Checks_, roundTripped.RemotesapiConfig.ProtectedBranches_[0].Checks_)
+	require.True(t, roundTripped.RemotesapiProtectedBranches()[1].AllowForcePush())
+
+	config.RemotesapiConfig.ProtectedBranches_[1].Branch_ = "release/["
+	require.Error(t, ValidateConfig(config))
+	config.RemotesapiConfig.ProtectedBranches_[1].Branch_ = "release/*"
+	config.RemotesapiConfig.ProtectedBranches_[0].Checks_[0].Query_ = " "
+	require.Error(t, ValidateConfig(config))
+}
+
 func TestUnmarshallCluster(t *testing.T) {
 	testStr := `
 cluster:
@@ -179,6 +269,70 @@ cluster:
 	require.Equal(t, 0, config.ClusterConfig().BootstrapEpoch())
 	require.Equal(t, "standby", config.ClusterConfig().StandbyRemotes()[0].Name())
 	require.Equal(t, "http://doltdb-1.doltdb:50051/{database}", config.ClusterConfig().StandbyRemotes()[0].RemoteURLTemplate())
+	require.Nil(t, config.ClusterConfig().FailoverConfig())
+	require.Nil(t, config.ClusterConfig().WriteQuorumConfig())
+}
+
+func TestUnmarshallClusterFailover(t *testing.T) {
+	testStr := `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://doltdb-1.doltdb:50051/{database}
+  - name: standby2
+    remote_url_template: http://doltdb-2.doltdb:50051/{database}
+  remotesapi:
+    port: 50051
+  failover:
+    lease_duration_millis: 10000
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	require.NotNil(t, config.ClusterConfig().FailoverConfig())
+	require.Equal(t, DefaultClusterFailoverHeartbeatIntervalMillis, config.ClusterConfig().FailoverConfig().HeartbeatIntervalMillis())
+	require.Equal(t, 10000, config.ClusterConfig().FailoverConfig().LeaseDurationMillis())
+}
+
+func TestUnmarshallClusterWriteQuorum(t *testing.T) {
+	testStr := `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://doltdb-1.doltdb:50051/{database}
+  - name: standby2
+    remote_url_template: http://doltdb-2.doltdb:50051/{database}
+  - name: standby3
+    remote_url_template: http://doltdb-3.doltdb:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    acks: 2
+    databases:
+    - name: important
+      acks: 3
+      strict: true
+      read_only_on_failure: true
+    - name: scratch
+      acks: 1
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	quorum := config.ClusterConfig().WriteQuorumConfig()
+	require.NotNil(t, quorum)
+	require.Equal(t, 2, quorum.Acks())
+	require.False(t, quorum.Strict())
+	require.False(t, quorum.ReadOnlyOnFailure())
+	dbs := quorum.Databases()
+	require.Len(t, dbs, 2)
+	require.Equal(t, "important", dbs[0].Name())
+	require.Equal(t, 3, dbs[0].Acks())
+	require.True(t, dbs[0].Strict())
+	require.True(t, dbs[0].ReadOnlyOnFailure())
+	require.Equal(t, "scratch", dbs[1].Name())
+	require.Equal(t, 1, dbs[1].Acks())
+	require.False(t, dbs[1].Strict())
+	require.False(t, dbs[1].ReadOnlyOnFailure())
+	require.NoError(t, ValidateClusterConfig(config.ClusterConfig()))
 }
 
 func TestValidateClusterConfig(t *testing.T) {
@@ -259,6 +413,163 @@ cluster:
   bootstrap_epoch: 0
   remotesapi:
     port: 50051
+`,
+			Error: true,
+		},
+		{
+			Name: "failover with defaults",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  - name: standby2
+    remote_url_template: http://localhost:50052/{database}
+  remotesapi:
+    port: 50051
+  failover: {}
+`,
+			Error: false,
+		},
+		{
+			Name: "failover with a single standby remote",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  failover: {}
+`,
+			Error: true,
+		},
+		{
+			Name: "failover lease shorter than two heartbeats",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  - name: standby2
+    remote_url_template: http://localhost:50052/{database}
+  remotesapi:
+    port: 50051
+  failover:
+    heartbeat_interval_millis: 1000
+    lease_duration_millis: 1500
+`,
+			Error: true,
+		},
+		{
+			Name: "failover with zero heartbeat interval",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  - name: standby2
+    remote_url_template: http://localhost:50052/{database}
+  remotesapi:
+    port: 50051
+  failover:
+    heartbeat_interval_millis: 0
+`,
+			Error: true,
+		},
+		{
+			Name: "write quorum",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  - name: standby2
+    remote_url_template: http://localhost:50052/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    acks: 1
+    strict: true
+    read_only_on_failure: true
+`,
+			Error: false,
+		},
+		{
+			Name: "write quorum with more acks than standby remotes",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    acks: 2
+`,
+			Error: true,
+		},
+		{
+			Name: "write quorum read_only_on_failure without strict",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    acks: 1
+    read_only_on_failure: true
+`,
+			Error: true,
+		},
+		{
+			Name: "write quorum database without a name",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    databases:
+    - acks: 1
+`,
+			Error: true,
+		},
+		{
+			Name: "write quorum duplicate database",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    databases:
+    - name: db
+      acks: 1
+    - name: DB
+      acks: 0
+`,
+			Error: true,
+		},
+		{
+			Name: "write quorum database with too many acks",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_quorum:
+    databases:
+    - name: db
+      acks: 2
 `,
 			Error: true,
 		},
@@ -344,6 +655,9 @@ func TestYAMLConfigDefaults(t *testing.T) {
 	assert.Nil(t, cfg.MetricsConfig.Labels)
 	assert.Equal(t, DefaultAllowCleartextPasswords, cfg.AllowCleartextPasswords())
 	assert.Nil(t, cfg.RemotesapiPort())
+	assert.Nil(t, cfg.PostgresListenerPort())
+	assert.Nil(t, cfg.HTTPAPIPort())
+	assert.False(t, cfg.HTTPAPIReadOnly())
 
 	c, err := LoadTLSConfig(cfg)
 	assert.NoError(t, err)
@@ -0,0 +1,125 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// How long dolt_wait_for_root() waits when it is not given a timeout.
+const defaultWaitForRootTimeout = 10 * time.Second
+
+// dolt_cluster_root() returns a token for the current root of the session's
+// current database. On a primary, a client calls it after committing a
+// write. On a standby, it returns the latest root the standby has applied,
+// or NULL if it has not applied one yet.
+func newClusterRootProcedure(controller *Controller) sql.ExternalStoredProcedureDetails {
+	return sql.ExternalStoredProcedureDetails{
+		Name: "dolt_cluster_root",
+		Schema: sql.Schema{
+			&sql.Column{
+				Name:     "root",
+				Type:     types.LongText,
+				Nullable: true,
+			},
+		},
+		Function: func(ctx *sql.Context) (sql.RowIter, error) {
+			name, err := currentBaseDatabase(ctx)
+			if err != nil {
+				return nil, err
+			}
+			token, ok, err := controller.clusterRoot(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return sql.RowsToRowIter(sql.Row{nil}), nil
+			}
+			return sql.RowsToRowIter(sql.Row{token.String()}), nil
+		},
+		ReadOnly: true,
+	}
+}
+
+// dolt_wait_for_root(token) blocks until this server has applied the root
+// of the session's current database identified by |token|, which was
+// returned by dolt_cluster_root() on the primary. It returns 0 once the
+// root is applied and 1 if it timed out waiting. Reads in a new transaction
+// after it returns 0 see every write the primary had made when it handed
+// out |token|.
+func newWaitForRootProcedure(controller *Controller) sql.ExternalStoredProcedureDetails {
+	return sql.ExternalStoredProcedureDetails{
+		Name:   "dolt_wait_for_root",
+		Schema: waitForRootSchema,
+		Function: func(ctx *sql.Context, token string) (sql.RowIter, error) {
+			return waitForRoot(ctx, controller, token, defaultWaitForRootTimeout)
+		},
+		ReadOnly: true,
+	}
+}
+
+// dolt_wait_for_root(token, timeout_secs) is dolt_wait_for_root(token) with
+// a timeout.
+func newWaitForRootWithTimeoutProcedure(controller *Controller) sql.ExternalStoredProcedureDetails {
+	return sql.ExternalStoredProcedureDetails{
+		Name:   "dolt_wait_for_root",
+		Schema: waitForRootSchema,
+		Function: func(ctx *sql.Context, token string, timeoutSecs float64) (sql.RowIter, error) {
+			return waitForRoot(ctx, controller, token, time.Duration(timeoutSecs*float64(time.Second)))
+		},
+		ReadOnly: true,
+	}
+}
+
+var waitForRootSchema = sql.Schema{
+	&sql.Column{
+		Name:     "status",
+		Type:     types.Int64,
+		Nullable: false,
+	},
+}
+
+func waitForRoot(ctx *sql.Context, controller *Controller, tokenStr string, timeout time.Duration) (sql.RowIter, error) {
+	token, err := parseRootToken(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	name, err := currentBaseDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := controller.waitForRoot(ctx, name, token, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return sql.RowsToRowIter(sql.Row{1}), nil
+	}
+	return sql.RowsToRowIter(sql.Row{0}), nil
+}
+
+func currentBaseDatabase(ctx *sql.Context) (string, error) {
+	name := ctx.GetCurrentDatabase()
+	if name == "" {
+		return "", sql.ErrNoDatabaseSelected.New()
+	}
+	name, _ = dsess.SplitRevisionDbName(name)
+	return name, nil
+}
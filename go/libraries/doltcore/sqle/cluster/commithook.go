@@ -33,6 +33,7 @@ import (
 
 var _ doltdb.CommitHook = (*commithook)(nil)
 var _ doltdb.NotifyWaitFailedCommitHook = (*commithook)(nil)
+var _ doltdb.QuorumCommitHook = (*commithook)(nil)
 
 type commithook struct {
 	rootLgr              *logrus.Entry
@@ -65,6 +66,21 @@ type commithook struct {
 	// NotifyWaitFailed(), an optional interface on CommitHook.
 	fastFailReplicationWait bool
 
+	// If non-nil, the quorum with which writes to this database wait on
+	// replication. Shared by all the commithooks of a database.
+	quorum *doltdb.ReplicationQuorum
+
+	// Numbers the roots of this database. Shared by all the commithooks
+	// of a database. |nextHeadSeq| and |lastPushedSeq| are the sequence
+	// numbers of |nextHead| and |lastPushedHead|.
+	sequencer     *rootSequencer
+	nextHeadSeq   int64
+	lastPushedSeq int64
+
+	// If non-nil, called without |mu| held to tell the standby the
+	// sequence number of a root it has.
+	reportProgress func(context.Context, int64) error
+
 	role Role
 
 	// The standby replica to which the new root gets replicated.
@@ -84,7 +100,7 @@ var errDestDBRootHashMoved error = errors.New("cluster/commithook: standby repli
 const logFieldThread = "thread"
 const logFieldRole = "role"
 
-func newCommitHook(lgr *logrus.Logger, remotename, remoteurl, dbname string, role Role, destDBF func(context.Context) (*doltdb.DoltDB, error), srcDB *doltdb.DoltDB, tempDir string) *commithook {
+func newCommitHook(lgr *logrus.Logger, remotename, remoteurl, dbname string, role Role, destDBF func(context.Context) (*doltdb.DoltDB, error), srcDB *doltdb.DoltDB, tempDir string, quorum *doltdb.ReplicationQuorum, sequencer *rootSequencer, reportProgress func(context.Context, int64) error) *commithook {
 	var ret commithook
 	ret.rootLgr = lgr.WithField(logFieldThread, "Standby Replication - "+dbname+" to "+remotename)
 	ret.lgr.Store(ret.rootLgr.WithField(logFieldRole, string(role)))
@@ -95,6 +111,9 @@ func newCommitHook(lgr *logrus.Logger, remotename, remoteurl, dbname string, rol
 	ret.destDBF = destDBF
 	ret.srcDB = srcDB
 	ret.tempDir = tempDir
+	ret.quorum = quorum
+	ret.sequencer = sequencer
+	ret.reportProgress = reportProgress
 	ret.cond = sync.NewCond(&ret.mu)
 	return &ret
 }
@@ -151,6 +170,8 @@ func (h *commithook) replicate(ctx context.Context) {
 				// TODO: if err != nil, something is really wrong; should shutdown or backoff.
 				lgr.Warningf("standby replication thread failed to load database root: %v", err)
 				h.nextHead = hash.Hash{}
+			} else {
+				h.nextHeadSeq = h.sequencer.observe(h.nextHead)
 			}
 
 			// We do not know when this head was written, but we
@@ -241,6 +262,7 @@ func (h *commithook) attemptHeartbeat(ctx context.Context) {
 		return
 	}
 	head := h.lastPushedHead
+	seq := h.lastPushedSeq
 	if head.IsEmpty() {
 		return
 	}
@@ -258,7 +280,10 @@ func (h *commithook) attemptHeartbeat(ctx context.Context) {
 	h.mu.Unlock()
 	datasDB := doltdb.HackDatasDatabaseFromDoltDB(destDB)
 	cs := datas.ChunkStoreFromDatabase(datasDB)
-	cs.Commit(ctx, head, head)
+	ok, err := cs.Commit(ctx, head, head)
+	if err == nil && ok {
+		h.sendProgress(ctx, seq)
+	}
 	h.mu.Lock()
 }
 
@@ -270,6 +295,7 @@ func (h *commithook) attemptHeartbeat(ctx context.Context) {
 func (h *commithook) attemptReplicate(ctx context.Context) {
 	lgr := h.logger()
 	toPush := h.nextHead
+	toPushSeq := h.nextHeadSeq
 	incomingTime := h.nextHeadIncomingTime
 	destDB := h.destDB
 	ctx, h.cancelReplicate = context.WithCancel(ctx)
@@ -321,6 +347,9 @@ func (h *commithook) attemptReplicate(ctx context.Context) {
 				}
 			}
 		}
+		if err == nil {
+			h.sendProgress(ctx, toPushSeq)
+		}
 	}
 
 	h.mu.Lock()
@@ -329,6 +358,7 @@ func (h *commithook) attemptReplicate(ctx context.Context) {
 			h.currentError = nil
 			lgr.Tracef("cluster/commithook: successfully Committed chunks on destDB")
 			h.lastPushedHead = toPush
+			h.lastPushedSeq = toPushSeq
 			h.lastSuccess = incomingTime
 			h.nextPushAttempt = time.Time{}
 			h.progressNotifier.RecordSuccess(attempt)
@@ -345,6 +375,20 @@ func (h *commithook) attemptReplicate(ctx context.Context) {
 	}
 }
 
+// Tells the standby that it has the root with sequence number |seq|, so that
+// it can serve reads which wait for it. Failures are not fatal; the standby
+// just can not serve those reads until a later report succeeds.
+//
+// called without h.mu held.
+func (h *commithook) sendProgress(ctx context.Context, seq int64) {
+	if h.reportProgress == nil {
+		return
+	}
+	if err := h.reportProgress(ctx, seq); err != nil {
+		h.logger().Tracef("cluster/commithook: failed to report replication progress to standby: %v", err)
+	}
+}
+
 func (h *commithook) status() (replicationLag *time.Duration, lastUpdate *time.Time, currentErr *string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -424,6 +468,8 @@ func (h *commithook) setRole(role Role) {
 	h.currentError = nil
 	h.nextHead = hash.Hash{}
 	h.lastPushedHead = hash.Hash{}
+	h.nextHeadSeq = 0
+	h.lastPushedSeq = 0
 	h.lastSuccess = time.Time{}
 	h.nextPushAttempt = time.Time{}
 	h.role = role
@@ -464,6 +510,7 @@ func (h *commithook) Execute(ctx context.Context, ds datas.Dataset, db datas.Dat
 		lgr.Errorf("cluster/commithook: Execute: error retrieving local database root: %v", err)
 		return nil, err
 	}
+	seq := h.sequencer.observe(root)
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	lgr = h.logger()
@@ -475,8 +522,17 @@ func (h *commithook) Execute(ctx context.Context, ds datas.Dataset, db datas.Dat
 		lgr.Tracef("signaling replication thread to push new head: %v", root.String())
 		h.nextHeadIncomingTime = time.Now()
 		h.nextHead = root
+		h.nextHeadSeq = seq
 		h.nextPushAttempt = time.Time{}
 		h.cond.Signal()
+	} else if seq > h.nextHeadSeq {
+		// The root came back to |nextHead| after one we did not see.
+		// The standby learns the new sequence number with our next
+		// push or heartbeat.
+		h.nextHeadSeq = seq
+		if h.lastPushedHead == root {
+			h.lastPushedSeq = seq
+		}
 	}
 	var waitF func(context.Context) error
 	if !h.isCaughtUp() {
@@ -487,10 +543,32 @@ func (h *commithook) Execute(ctx context.Context, ds datas.Dataset, db datas.Dat
 		} else {
 			waitF = h.progressNotifier.Wait()
 		}
+	} else if h.quorum != nil {
+		// A standby which is already caught up acknowledges the
+		// write towards the quorum.
+		waitF = func(context.Context) error {
+			return nil
+		}
 	}
 	return waitF, nil
 }
 
+func (h *commithook) ReplicaName() string {
+	return h.remotename
+}
+
+func (h *commithook) ReplicationQuorum() *doltdb.ReplicationQuorum {
+	return h.quorum
+}
+
+// Returns true if the standby has every write which the primary has
+// attempted to replicate to it.
+func (h *commithook) caughtUp() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.isCaughtUp()
+}
+
 func (h *commithook) NotifyWaitFailed() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -37,7 +37,7 @@ func TestCommitHookStartsNotCaughtUp(t *testing.T) {
 
 	hook := newCommitHook(logrus.StandardLogger(), "origin", "https://localhost:50051/mydb", "mydb", RolePrimary, func(context.Context) (*doltdb.DoltDB, error) {
 		return destEnv.DoltDB, nil
-	}, srcEnv.DoltDB, t.TempDir())
+	}, srcEnv.DoltDB, t.TempDir(), nil, nil, nil)
 
 	require.False(t, hook.isCaughtUp())
 }
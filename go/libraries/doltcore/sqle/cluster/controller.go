@@ -110,6 +110,34 @@ type Controller struct {
 	dropDatabase             func(*sql.Context, string) error
 	outstandingDropDatabases map[string]*databaseDropReplication
 	remoteSrvDBCache         remotesrv.DBCache
+
+	// nil unless automatic failover is configured.
+	failover *failoverManager
+
+	// The outcome of the most recent replication wait on a write quorum,
+	// by database name. Guarded by |writeWaitsMu|, which is never held
+	// while taking another lock.
+	writeWaits   map[string]writeWaitStatus
+	writeWaitsMu sync.Mutex
+
+	// Non-nil while this primary is read only because a strict write
+	// quorum was not reached. Maps database names to the number of
+	// standbys which must catch up before writes are accepted again.
+	// Guarded by |mu|.
+	quorumReadOnly map[string]int
+
+	// On a primary, the sequencers which number the roots of each
+	// database. On a standby, the replication progress its primary has
+	// reported for each database. Both are keyed by lower cased database
+	// name and guarded by |progressMu|, which is never held while taking
+	// another lock.
+	sequencers map[string]*rootSequencer
+	applied    map[string]*appliedProgress
+	progressMu sync.Mutex
+
+	// Guards writes to |persistentCfg|. Taken after |mu| and after the
+	// failoverManager's lock, and never held while taking another lock.
+	persistMu sync.Mutex
 }
 
 type sqlvars interface {
@@ -147,6 +175,9 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 		epoch:         epoch,
 		commithooks:   make([]*commithook, 0),
 		lgr:           lgr,
+		writeWaits:    make(map[string]writeWaitStatus),
+		sequencers:    make(map[string]*rootSequencer),
+		applied:       make(map[string]*appliedProgress),
 	}
 	roleSetter := func(role string, epoch int) {
 		ret.setRoleAndEpoch(role, epoch, roleTransitionOptions{
@@ -204,6 +235,51 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 
 	ret.outstandingDropDatabases = make(map[string]*databaseDropReplication)
 
+	if failoverCfg := cfg.FailoverConfig(); failoverCfg != nil {
+		ret.failover, err = ret.buildFailoverManager(failoverCfg, keyIDStr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func (c *Controller) buildFailoverManager(cfg servercfg.ClusterFailoverConfig, candidateID string) (*failoverManager, error) {
+	persistedVotedEpoch := c.persistentCfg.GetStringOrDefault(failoverVotedEpochKey, "0")
+	votedEpoch, err := strconv.Atoi(persistedVotedEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("persisted failover voted epoch %s.%s = %s must be an integer", PersistentConfigPrefix, failoverVotedEpochKey, persistedVotedEpoch)
+	}
+	peers := make([]*failoverPeer, len(c.replicationClients))
+	for i, client := range c.replicationClients {
+		peers[i] = &failoverPeer{
+			remote: client.remote,
+			client: client.client,
+		}
+	}
+	ret := newFailoverManager(
+		c.lgr.WithFields(logrus.Fields{"component": "failover"}),
+		time.Duration(cfg.HeartbeatIntervalMillis())*time.Millisecond,
+		time.Duration(cfg.LeaseDurationMillis())*time.Millisecond,
+		candidateID,
+		peers,
+		c.role,
+		c.epoch,
+		votedEpoch,
+	)
+	ret.setRoleAndEpoch = func(role Role, epoch int) error {
+		_, err := c.setRoleAndEpoch(string(role), epoch, roleTransitionOptions{
+			graceful: false,
+		})
+		return err
+	}
+	ret.replicationPositions = c.replicationPositions
+	ret.persistVotedEpoch = func(epoch int) error {
+		c.persistMu.Lock()
+		defer c.persistMu.Unlock()
+		return c.persistentCfg.SetStrings(map[string]string{failoverVotedEpochKey: strconv.Itoa(epoch)})
+	}
 	return ret, nil
 }
 
@@ -224,6 +300,13 @@ func (c *Controller) Run() {
 		defer wg.Done()
 		c.bcReplication.Run()
 	}()
+	if c.failover != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.failover.Run()
+		}()
+	}
 	wg.Wait()
 	for _, client := range c.replicationClients {
 		client.closer()
@@ -234,6 +317,9 @@ func (c *Controller) GracefulStop() error {
 	c.jwks.GracefulStop()
 	c.mysqlDbPersister.GracefulStop()
 	c.bcReplication.GracefulStop()
+	if c.failover != nil {
+		c.failover.GracefulStop()
+	}
 	return nil
 }
 
@@ -314,7 +400,7 @@ func (c *Controller) applyCommitHooks(ctx context.Context, name string, bt *sql.
 		}
 		commitHook := newCommitHook(c.lgr, r.Name(), remote.Url, name, c.role, func(ctx context.Context) (*doltdb.DoltDB, error) {
 			return remote.GetRemoteDB(ctx, types.Format_Default, dialprovider)
-		}, denv.DoltDB, ttfdir)
+		}, denv.DoltDB, ttfdir, c.replicationQuorum(name), c.rootSequencer(name), c.replicationProgressReporter(r.Name(), name))
 		denv.DoltDB.PrependCommitHook(ctx, commitHook)
 		if err := commitHook.Run(bt); err != nil {
 			return nil, err
@@ -334,6 +420,9 @@ func (c *Controller) RegisterStoredProcedures(store procedurestore) {
 	}
 	store.Register(newAssumeRoleProcedure(c))
 	store.Register(newTransitionToStandbyProcedure(c))
+	store.Register(newClusterRootProcedure(c))
+	store.Register(newWaitForRootProcedure(c))
+	store.Register(newWaitForRootWithTimeoutProcedure(c))
 }
 
 // Incoming drop database replication requests need a way to drop a database in
@@ -371,6 +460,7 @@ func (c *Controller) dropDatabaseHook(_ *sql.Context, dbname string) {
 		j += 1
 	}
 	c.commithooks = c.commithooks[:j]
+	c.forgetReplicationProgress(dbname)
 
 	if c.role != RolePrimary {
 		return
@@ -483,6 +573,8 @@ func (c *Controller) persistVariables() error {
 	toset := make(map[string]string)
 	toset[dsess.DoltClusterRoleVariable] = string(c.role)
 	toset[dsess.DoltClusterRoleEpochVariable] = strconv.Itoa(c.epoch)
+	c.persistMu.Lock()
+	defer c.persistMu.Unlock()
 	return c.persistentCfg.SetStrings(toset)
 }
 
@@ -598,7 +690,7 @@ func (c *Controller) setRoleAndEpoch(role string, epoch int, opts roleTransition
 					err = fmt.Errorf("error assuming role '%s' at epoch %d: the role configuration changed while we were replicating to our standbys. Please try again", role, epoch)
 				}
 				if err != nil {
-					c.setProviderIsStandby(c.role != RolePrimary)
+					c.setProviderIsStandby(c.role != RolePrimary || c.quorumReadOnly != nil)
 					c.killRunningQueries(saveConnID)
 					return roleTransitionResult{false, nil}, err
 				}
@@ -614,6 +706,14 @@ func (c *Controller) setRoleAndEpoch(role string, epoch int, opts roleTransition
 
 	c.role = Role(role)
 	c.epoch = epoch
+	if c.quorumReadOnly != nil {
+		// The transition set the read only state of the databases
+		// for the new role.
+		c.quorumReadOnly = nil
+		if c.role == RolePrimary {
+			c.setProviderIsStandby(false)
+		}
+	}
 
 	c.refreshSystemVars()
 	c.cinterceptor.setRole(c.role, c.epoch)
@@ -625,6 +725,7 @@ func (c *Controller) setRoleAndEpoch(role string, epoch int, opts roleTransition
 		c.mysqlDbPersister.setRole(c.role)
 		c.bcReplication.setRole(c.role)
 	}
+	c.failover.setRole(c.role, c.epoch)
 	_ = c.persistVariables()
 	return roleTransitionResult{
 		changedRole:               changedrole,
@@ -653,17 +754,40 @@ func (c *Controller) GetClusterStatus() []clusterdb.ReplicaStatus {
 	commithooks := make([]*commithook, len(c.commithooks))
 	copy(commithooks, c.commithooks)
 	c.mu.Unlock()
+	var failoverState *string
+	var failoverLeaseExpires *time.Time
+	if c.failover != nil {
+		state, leaseExpires := c.failover.status()
+		stateStr := string(state)
+		failoverState = &stateStr
+		if !leaseExpires.IsZero() {
+			failoverLeaseExpires = &leaseExpires
+		}
+	}
 	ret := make([]clusterdb.ReplicaStatus, len(commithooks))
-	for i, c := range commithooks {
-		lag, lastUpdate, currentErrorStr := c.status()
+	for i, h := range commithooks {
+		lag, lastUpdate, currentErrorStr := h.status()
+		var lastWriteWait *time.Duration
+		var lastWriteAcked *bool
+		if role == RolePrimary {
+			if ww, ok := c.lastWriteWait(h.dbname); ok {
+				_, acked := ww.acked[h.remotename]
+				lastWriteWait = &ww.duration
+				lastWriteAcked = &acked
+			}
+		}
 		ret[i] = clusterdb.ReplicaStatus{
-			Database:       c.dbname,
-			Remote:         c.remotename,
-			Role:           string(role),
-			Epoch:          epoch,
-			ReplicationLag: lag,
-			LastUpdate:     lastUpdate,
-			CurrentError:   currentErrorStr,
+			Database:             h.dbname,
+			Remote:               h.remotename,
+			Role:                 string(role),
+			Epoch:                epoch,
+			ReplicationLag:       lag,
+			LastUpdate:           lastUpdate,
+			CurrentError:         currentErrorStr,
+			FailoverState:        failoverState,
+			FailoverLeaseExpires: failoverLeaseExpires,
+			LastWriteWait:        lastWriteWait,
+			LastWriteAcked:       lastWriteAcked,
 		}
 	}
 	return ret
@@ -761,6 +885,8 @@ func (c *Controller) RegisterGrpcServices(ctxFactory func(context.Context) (*sql
 		branchControl:        c.branchControlController,
 		branchControlFilesys: c.branchControlFilesys,
 		dropDatabase:         c.dropDatabase,
+		failover:             c.failover,
+		updateProgress:       c.recordReplicationProgress,
 		lgr:                  c.lgr.WithFields(logrus.Fields{}),
 	})
 }
@@ -0,0 +1,580 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+)
+
+type FailoverState string
+
+// A primary which holds its lease.
+const FailoverStateLeader FailoverState = "leader"
+
+// A standby which accepts heartbeats from a primary, or is waiting for the
+// lease of an unreachable primary to expire.
+const FailoverStateFollower FailoverState = "follower"
+
+// A standby which is asking the members of the cluster to vote for it to
+// become primary at a new epoch.
+const FailoverStateCandidate FailoverState = "candidate"
+
+// A server in detected_broken_config, which does not take part in failover
+// until an operator assigns it a role.
+const FailoverStateInactive FailoverState = "inactive"
+
+// The key under which the highest epoch this server has voted in is
+// persisted, so that a restarted server does not vote twice in an epoch.
+const failoverVotedEpochKey = "failover_voted_epoch"
+
+// failoverManager implements automatic failover among the members of a
+// cluster, each of which lists all the other members in its standby_remotes.
+//
+// A primary sends a heartbeat to every standby every |heartbeatInterval|. A
+// standby acknowledges a heartbeat if the epoch of the primary is at least as
+// high as any epoch it has seen or voted in. A primary holds a lease for
+// |leaseDuration| past the time it sent the most recent heartbeat which was
+// acknowledged by a majority of the cluster, counting itself. A primary
+// transitions to standby |leaseMargin| before its lease expires, so that a
+// primary cut off from a majority of the cluster stops accepting writes
+// before the cluster can elect a new primary, even if its heartbeats are
+// slow to fail or the clocks of the members run at slightly different rates.
+//
+// A standby which has not acknowledged a heartbeat for |leaseDuration| plus
+// a random jitter stands for election at the next epoch. It votes for itself
+// and asks every other member for its vote. A member grants at most one vote
+// per epoch, only for an epoch higher than any it has seen or voted in, and
+// only if it does not itself hold, or acknowledge, a live lease. A member
+// also denies its vote if it has applied a later root of any database than
+// the candidate, as reported in the vote request. A candidate which receives
+// votes from a majority of the cluster becomes primary at the new epoch.
+// Since any two majorities overlap, and a voter refuses heartbeats from
+// epochs before the one it voted in, the lease of the old primary can not be
+// renewed once a new primary is elected, and the new primary has every root
+// which a majority of the cluster applied.
+//
+// A returning old primary learns of the new epoch from the heartbeats of the
+// new primary, or from the responses to its own heartbeats, and transitions
+// to standby at the new epoch.
+type failoverManager struct {
+	lgr *logrus.Entry
+
+	heartbeatInterval time.Duration
+	leaseDuration     time.Duration
+	leaseMargin       time.Duration
+
+	// An identifier of this server, sent with its vote requests.
+	candidateID string
+
+	peers []*failoverPeer
+
+	// Transitions this server to the given role and epoch. Called
+	// without |mu| held, since it calls back into setRole.
+	setRoleAndEpoch func(role Role, epoch int) error
+	// Durably records the highest epoch this server has voted in.
+	persistVotedEpoch func(epoch int) error
+	// Returns the latest root of each database this server has applied,
+	// keyed by lower cased database name. Called without |mu| held.
+	replicationPositions func(ctx context.Context) (map[string]rootToken, error)
+
+	mu sync.Mutex
+
+	role       Role
+	epoch      int
+	votedEpoch int
+	state      FailoverState
+
+	// The highest epoch another member stood for election in, or told us
+	// of in response to our vote requests. We stand for election above it,
+	// so that a member which can not win elections, because it is behind
+	// in replication, does not keep us from winning one by standing at
+	// the same epochs.
+	seenEpoch int
+
+	// As a primary, the time at which our lease expires.
+	leaseExpires time.Time
+	// As a standby, the time we last acknowledged a heartbeat.
+	lastHeartbeat time.Time
+	// As a standby, the time at which we will stand for election.
+	electionDeadline time.Time
+
+	// Signaled when |leaseExpires| changes, to wake fenceLease.
+	leaseChanged chan struct{}
+
+	shutdown bool
+	done     chan struct{}
+}
+
+type failoverPeer struct {
+	remote string
+	client replicationapi.ReplicationServiceClient
+
+	// As a primary, the time we sent the most recent heartbeat which this
+	// peer acknowledged. Accessed with the failoverManager's |mu| held.
+	lastAck time.Time
+}
+
+func newFailoverManager(lgr *logrus.Entry, heartbeatInterval, leaseDuration time.Duration, candidateID string, peers []*failoverPeer, role Role, epoch, votedEpoch int) *failoverManager {
+	m := &failoverManager{
+		lgr:               lgr,
+		heartbeatInterval: heartbeatInterval,
+		leaseDuration:     leaseDuration,
+		leaseMargin:       leaseDuration / 10,
+		candidateID:       candidateID,
+		peers:             peers,
+		votedEpoch:        votedEpoch,
+		leaseChanged:      make(chan struct{}, 1),
+		done:              make(chan struct{}),
+	}
+	m.setRole(role, epoch)
+	return m
+}
+
+func (m *failoverManager) Run() {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.fenceLease()
+	}()
+	ticker := time.NewTicker(m.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.tick()
+		}
+	}
+}
+
+// fenceLease transitions a primary to standby |leaseMargin| before its lease
+// expires. It runs apart from heartbeat, which can take up to a heartbeat
+// interval to hear back from unreachable peers, so that the primary steps
+// down on time regardless.
+func (m *failoverManager) fenceLease() {
+	timer := time.NewTimer(m.leaseDuration)
+	defer timer.Stop()
+	for {
+		m.mu.Lock()
+		role, epoch := m.role, m.epoch
+		stepDownAt := m.leaseExpires.Add(-m.leaseMargin)
+		m.mu.Unlock()
+		wait := m.leaseDuration
+		if role == RolePrimary {
+			wait = time.Until(stepDownAt)
+			if wait <= 0 {
+				m.lgr.Warnf("cluster/failover: this server is primary at epoch %d and its lease is about to expire without a majority of the cluster acknowledging its heartbeats. transitioning to standby.", epoch)
+				m.transition(RoleStandby, epoch)
+				// If the transition failed, we try again.
+				wait = m.heartbeatInterval
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-m.done:
+			return
+		case <-m.leaseChanged:
+		case <-timer.C:
+		}
+	}
+}
+
+// Wakes fenceLease to reschedule after |leaseExpires| changed. Called with
+// |mu| held.
+func (m *failoverManager) leaseUpdated() {
+	select {
+	case m.leaseChanged <- struct{}{}:
+	default:
+	}
+}
+
+func (m *failoverManager) GracefulStop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.shutdown {
+		m.shutdown = true
+		close(m.done)
+	}
+}
+
+// setRole is called by the Controller whenever the role or epoch of this
+// server changes, including through dolt_assume_cluster_role.
+func (m *failoverManager) setRole(role Role, epoch int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	changedrole := role != m.role
+	m.role = role
+	m.epoch = epoch
+	now := time.Now()
+	switch role {
+	case RolePrimary:
+		if changedrole {
+			// A new primary was elected by a majority of the
+			// cluster, or was assigned by an operator. It holds
+			// its lease until it has had a chance to heartbeat.
+			m.leaseExpires = now.Add(m.leaseDuration)
+			for _, p := range m.peers {
+				p.lastAck = time.Time{}
+			}
+			m.leaseUpdated()
+		}
+		m.state = FailoverStateLeader
+	case RoleStandby:
+		if changedrole {
+			m.lastHeartbeat = now
+			m.resetElectionDeadline(now)
+		}
+		m.state = FailoverStateFollower
+	default:
+		m.state = FailoverStateInactive
+	}
+}
+
+// status returns the current state of this server, and as a primary, the
+// time at which its lease expires, or as a standby, the time at which the
+// lease of the primary it last heard from expires.
+func (m *failoverManager) status() (FailoverState, time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch m.role {
+	case RolePrimary:
+		return m.state, m.leaseExpires
+	case RoleStandby:
+		return m.state, m.lastHeartbeat.Add(m.leaseDuration)
+	default:
+		return m.state, time.Time{}
+	}
+}
+
+func (m *failoverManager) resetElectionDeadline(now time.Time) {
+	jitter := time.Duration(rand.Int63n(int64(m.leaseDuration)))
+	m.electionDeadline = now.Add(m.leaseDuration + jitter)
+}
+
+// The number of votes or acknowledgements, counting our own, which make up a
+// majority of the cluster.
+func (m *failoverManager) majority() int {
+	return (len(m.peers)+1)/2 + 1
+}
+
+func (m *failoverManager) highestEpoch() int {
+	if m.votedEpoch > m.epoch {
+		return m.votedEpoch
+	}
+	return m.epoch
+}
+
+func (m *failoverManager) tick() {
+	m.mu.Lock()
+	role := m.role
+	standForElection := role == RoleStandby && time.Now().After(m.electionDeadline)
+	m.mu.Unlock()
+	if role == RolePrimary {
+		m.heartbeat()
+	} else if standForElection {
+		m.standForElection()
+	}
+}
+
+// heartbeat sends a heartbeat to every peer and renews our lease if a
+// majority of the cluster acknowledged a recent heartbeat. Transitions to
+// standby if a peer has seen a higher epoch. fenceLease transitions to
+// standby if our lease is not renewed.
+func (m *failoverManager) heartbeat() {
+	m.mu.Lock()
+	epoch := m.epoch
+	m.mu.Unlock()
+
+	sent := time.Now()
+	responses := make([]*replicationapi.HeartbeatResponse, len(m.peers))
+	var wg sync.WaitGroup
+	for i, p := range m.peers {
+		wg.Add(1)
+		go func(i int, p *failoverPeer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), m.heartbeatInterval)
+			defer cancel()
+			resp, err := p.client.Heartbeat(ctx, &replicationapi.HeartbeatRequest{Epoch: int64(epoch)})
+			if err != nil {
+				m.lgr.Tracef("cluster/failover: heartbeat to %s failed: %v", p.remote, err)
+				return
+			}
+			responses[i] = resp
+		}(i, p)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	if m.role != RolePrimary || m.epoch != epoch {
+		// Our role changed while we were sending heartbeats.
+		m.mu.Unlock()
+		return
+	}
+	higherEpoch := epoch
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if resp.Acknowledged {
+			m.peers[i].lastAck = sent
+		} else if int(resp.Epoch) > higherEpoch {
+			higherEpoch = int(resp.Epoch)
+		}
+	}
+	if higherEpoch > epoch {
+		m.mu.Unlock()
+		m.lgr.Warnf("cluster/failover: this server is primary at epoch %d. a member of the cluster has seen epoch %d. transitioning to standby.", epoch, higherEpoch)
+		m.transition(RoleStandby, higherEpoch)
+		return
+	}
+	acks := make([]time.Time, len(m.peers))
+	for i, p := range m.peers {
+		acks[i] = p.lastAck
+	}
+	sort.Slice(acks, func(i, j int) bool {
+		return acks[i].After(acks[j])
+	})
+	// Along with our own, we need acknowledgements from majority-1 of our peers.
+	renewed := sent.Add(m.leaseDuration)
+	if needed := m.majority() - 1; needed > 0 {
+		renewed = acks[needed-1].Add(m.leaseDuration)
+	}
+	if renewed.After(m.leaseExpires) {
+		m.leaseExpires = renewed
+		m.leaseUpdated()
+	}
+	m.mu.Unlock()
+}
+
+// standForElection asks every peer to vote for this server to become the
+// primary at the next epoch, and becomes the primary if a majority of the
+// cluster grants their votes.
+func (m *failoverManager) standForElection() {
+	positions, err := m.replicationPositions(context.Background())
+	if err != nil {
+		m.mu.Lock()
+		m.resetElectionDeadline(time.Now())
+		m.mu.Unlock()
+		m.lgr.Errorf("cluster/failover: could not read replication positions to stand for election: %v", err)
+		return
+	}
+
+	m.mu.Lock()
+	epoch := m.highestEpoch() + 1
+	if m.seenEpoch >= epoch {
+		epoch = m.seenEpoch + 1
+	}
+	if err := m.persistVotedEpoch(epoch); err != nil {
+		m.resetElectionDeadline(time.Now())
+		m.mu.Unlock()
+		m.lgr.Errorf("cluster/failover: could not persist vote for epoch %d: %v", epoch, err)
+		return
+	}
+	m.votedEpoch = epoch
+	m.state = FailoverStateCandidate
+	// If this election fails, we stand again after another timeout.
+	m.resetElectionDeadline(time.Now())
+	m.mu.Unlock()
+	m.lgr.Infof("cluster/failover: no heartbeat from a primary within the lease duration. standing for election at epoch %d.", epoch)
+
+	responses := make([]*replicationapi.RequestVoteResponse, len(m.peers))
+	var wg sync.WaitGroup
+	for i, p := range m.peers {
+		wg.Add(1)
+		go func(i int, p *failoverPeer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), m.heartbeatInterval)
+			defer cancel()
+			resp, err := p.client.RequestVote(ctx, &replicationapi.RequestVoteRequest{
+				Epoch:     int64(epoch),
+				Candidate: m.candidateID,
+				Positions: positionsToProto(positions),
+			})
+			if err != nil {
+				m.lgr.Tracef("cluster/failover: vote request to %s failed: %v", p.remote, err)
+				return
+			}
+			responses[i] = resp
+		}(i, p)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	votes := 1
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if resp.Granted {
+			votes += 1
+		} else if int(resp.Epoch) > m.seenEpoch {
+			m.seenEpoch = int(resp.Epoch)
+		}
+	}
+	won := votes >= m.majority() && m.role == RoleStandby && m.state == FailoverStateCandidate && m.votedEpoch == epoch
+	if !won && m.state == FailoverStateCandidate {
+		m.state = FailoverStateFollower
+	}
+	m.mu.Unlock()
+	if !won {
+		m.lgr.Infof("cluster/failover: lost election at epoch %d with %d of %d votes.", epoch, votes, len(m.peers)+1)
+		return
+	}
+	m.lgr.Infof("cluster/failover: won election at epoch %d with %d of %d votes. transitioning to primary.", epoch, votes, len(m.peers)+1)
+	m.transition(RolePrimary, epoch)
+}
+
+func (m *failoverManager) transition(role Role, epoch int) {
+	if err := m.setRoleAndEpoch(role, epoch); err != nil {
+		m.lgr.Errorf("cluster/failover: could not transition to %s at epoch %d: %v", role, epoch, err)
+	}
+}
+
+// handleHeartbeat handles a heartbeat from a primary at |epoch|.
+func (m *failoverManager) handleHeartbeat(epoch int) *replicationapi.HeartbeatResponse {
+	m.mu.Lock()
+	newEpoch := epoch > m.epoch && epoch >= m.votedEpoch
+	role := m.role
+	m.mu.Unlock()
+	if newEpoch {
+		// The server interceptor will usually have transitioned us
+		// already, when it saw the role and epoch of the caller.
+		if role == RolePrimary {
+			m.lgr.Warnf("cluster/failover: received a heartbeat from a primary at epoch %d. transitioning to standby.", epoch)
+		}
+		m.transition(RoleStandby, epoch)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	highest := m.highestEpoch()
+	if epoch < highest || epoch != m.epoch || m.role != RoleStandby {
+		return &replicationapi.HeartbeatResponse{Acknowledged: false, Epoch: int64(highest)}
+	}
+	now := time.Now()
+	m.lastHeartbeat = now
+	m.resetElectionDeadline(now)
+	m.state = FailoverStateFollower
+	return &replicationapi.HeartbeatResponse{Acknowledged: true, Epoch: int64(highest)}
+}
+
+// handleRequestVote handles a request from |candidate| for our vote to
+// become primary at |epoch|. |positions| are the latest roots the candidate
+// has applied.
+func (m *failoverManager) handleRequestVote(ctx context.Context, epoch int, candidate string, positions map[string]rootToken) *replicationapi.RequestVoteResponse {
+	// Read before taking |mu|, which is taken after the Controller's lock.
+	ours, positionsErr := m.replicationPositions(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	highest := m.highestEpoch()
+	if epoch > m.seenEpoch {
+		m.seenEpoch = epoch
+	}
+	deny := func(reason string) *replicationapi.RequestVoteResponse {
+		m.lgr.Infof("cluster/failover: denied vote to %s at epoch %d: %s", candidate, epoch, reason)
+		return &replicationapi.RequestVoteResponse{Granted: false, Epoch: int64(highest)}
+	}
+	now := time.Now()
+	if epoch <= highest {
+		return deny("already at or voted in a higher epoch")
+	}
+	switch m.role {
+	case RolePrimary:
+		if now.Before(m.leaseExpires) {
+			return deny("this server is primary and holds its lease")
+		}
+	case RoleStandby:
+		if now.Before(m.lastHeartbeat.Add(m.leaseDuration)) {
+			return deny("this server acknowledged a heartbeat within the lease duration")
+		}
+	default:
+		return deny("this server is in " + string(m.role))
+	}
+	if positionsErr != nil {
+		m.lgr.Errorf("cluster/failover: could not read replication positions: %v", positionsErr)
+		return deny("could not read replication positions")
+	}
+	if name, ok := newerPosition(ours, positions); ok {
+		return deny(fmt.Sprintf("this server has applied a later root of %s", name))
+	}
+	if err := m.persistVotedEpoch(epoch); err != nil {
+		m.lgr.Errorf("cluster/failover: could not persist vote for epoch %d: %v", epoch, err)
+		return deny("could not persist vote")
+	}
+	m.votedEpoch = epoch
+	if m.state == FailoverStateCandidate {
+		// We lost this election to |candidate|.
+		m.state = FailoverStateFollower
+	}
+	// Give the candidate a chance to win before we stand ourselves.
+	m.resetElectionDeadline(now)
+	m.lgr.Infof("cluster/failover: granted vote to %s at epoch %d", candidate, epoch)
+	return &replicationapi.RequestVoteResponse{Granted: true, Epoch: int64(epoch)}
+}
+
+// Returns the name of a database for which |ours| has a later root than
+// |theirs|, if there is one. A database missing from |theirs| has not had
+// any root applied.
+func newerPosition(ours, theirs map[string]rootToken) (string, bool) {
+	for name, token := range ours {
+		if t, ok := theirs[name]; !ok || !t.includes(token) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func positionsToProto(positions map[string]rootToken) []*replicationapi.ReplicationPosition {
+	ret := make([]*replicationapi.ReplicationPosition, 0, len(positions))
+	for name, token := range positions {
+		ret = append(ret, &replicationapi.ReplicationPosition{
+			Database: name,
+			Epoch:    int64(token.epoch),
+			Sequence: token.seq,
+		})
+	}
+	return ret
+}
+
+func positionsFromProto(positions []*replicationapi.ReplicationPosition) map[string]rootToken {
+	ret := make(map[string]rootToken, len(positions))
+	for _, p := range positions {
+		ret[strings.ToLower(p.Database)] = rootToken{epoch: int(p.Epoch), seq: p.Sequence}
+	}
+	return ret
+}
@@ -0,0 +1,403 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+)
+
+const testHeartbeatInterval = 10 * time.Millisecond
+const testLeaseDuration = 100 * time.Millisecond
+
+type testJWTCreds struct{}
+
+func (testJWTCreds) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + newJWT()}, nil
+}
+
+func (testJWTCreds) RequireTransportSecurity() bool {
+	return false
+}
+
+// failoverTestMember is an in-process member of a cluster, which stands in
+// for the Controller of a sql-server. It serves the replication service with
+// the cluster interceptors installed, and dials every other member of the
+// cluster through them.
+type failoverTestMember struct {
+	name string
+	addr string
+
+	mu          sync.Mutex
+	role        Role
+	epoch       int
+	votedEpoch  int
+	positions   map[string]rootToken
+	steppedDown time.Time
+	si          *serverinterceptor
+	ci          *clientinterceptor
+	m           *failoverManager
+	srv         *grpc.Server
+	conns       []*grpc.ClientConn
+	wg          sync.WaitGroup
+}
+
+func (tm *failoverTestMember) roleAndEpoch() (Role, int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.role, tm.epoch
+}
+
+func (tm *failoverTestMember) failoverState() FailoverState {
+	tm.mu.Lock()
+	m := tm.m
+	tm.mu.Unlock()
+	state, _ := m.status()
+	return state
+}
+
+func (tm *failoverTestMember) setRoleAndEpoch(role Role, epoch int) error {
+	tm.mu.Lock()
+	if epoch < tm.epoch {
+		tm.mu.Unlock()
+		return fmt.Errorf("error assuming role '%s' at epoch %d; already at epoch %d", role, epoch, tm.epoch)
+	}
+	if tm.role == RolePrimary && role != RolePrimary {
+		tm.steppedDown = time.Now()
+	}
+	tm.role, tm.epoch = role, epoch
+	tm.si.setRole(role, epoch)
+	tm.ci.setRole(role, epoch)
+	m := tm.m
+	tm.mu.Unlock()
+	m.setRole(role, epoch)
+	return nil
+}
+
+func (tm *failoverTestMember) start(t *testing.T, peers []*failoverTestMember) {
+	lis, err := net.Listen("tcp", tm.addr)
+	require.NoError(t, err)
+	tm.addr = lis.Addr().String()
+
+	roleSetter := func(role string, epoch int) {
+		tm.setRoleAndEpoch(Role(role), epoch)
+	}
+	tm.si = &serverinterceptor{lgr: lgr, roleSetter: roleSetter, keyProvider: kp}
+	tm.si.setRole(tm.role, tm.epoch)
+	tm.ci = &clientinterceptor{lgr: lgr, roleSetter: roleSetter}
+	tm.ci.setRole(tm.role, tm.epoch)
+
+	var failoverPeers []*failoverPeer
+	for _, p := range peers {
+		if p == tm {
+			continue
+		}
+		cc, err := grpc.Dial(p.addr, append(tm.ci.Options(),
+			grpc.WithInsecure(),
+			grpc.WithPerRPCCredentials(testJWTCreds{}),
+			grpc.WithConnectParams(grpc.ConnectParams{
+				Backoff:           backoff.Config{BaseDelay: testHeartbeatInterval, Multiplier: 1, MaxDelay: testHeartbeatInterval},
+				MinConnectTimeout: testHeartbeatInterval,
+			}))...)
+		require.NoError(t, err)
+		tm.conns = append(tm.conns, cc)
+		failoverPeers = append(failoverPeers, &failoverPeer{
+			remote: p.name,
+			client: replicationapi.NewReplicationServiceClient(cc),
+		})
+	}
+	tm.m = newFailoverManager(lgr.WithField("member", tm.name), testHeartbeatInterval, testLeaseDuration, tm.name, failoverPeers, tm.role, tm.epoch, tm.votedEpoch)
+	tm.m.setRoleAndEpoch = tm.setRoleAndEpoch
+	tm.m.replicationPositions = func(context.Context) (map[string]rootToken, error) {
+		tm.mu.Lock()
+		defer tm.mu.Unlock()
+		return tm.positions, nil
+	}
+	tm.m.persistVotedEpoch = func(epoch int) error {
+		// Called with the failoverManager's lock held.
+		tm.votedEpoch = epoch
+		return nil
+	}
+
+	tm.srv = grpc.NewServer(tm.si.Options()...)
+	replicationapi.RegisterReplicationServiceServer(tm.srv, &replicationServiceServer{
+		lgr:      lgr,
+		failover: tm.m,
+	})
+	tm.wg.Add(2)
+	go func() {
+		defer tm.wg.Done()
+		tm.srv.Serve(lis)
+	}()
+	go func() {
+		defer tm.wg.Done()
+		tm.m.Run()
+	}()
+}
+
+// stop simulates the member crashing. Its role, epoch and voted epoch
+// remain as they were persisted, for a later restart.
+func (tm *failoverTestMember) stop() {
+	tm.m.GracefulStop()
+	tm.srv.Stop()
+	tm.wg.Wait()
+	for _, cc := range tm.conns {
+		cc.Close()
+	}
+	tm.conns = nil
+}
+
+func newFailoverTestCluster(t *testing.T, n int) []*failoverTestMember {
+	members := make([]*failoverTestMember, n)
+	for i := range members {
+		// Reserve a port for each member, so that members can dial
+		// each other before they are all started.
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		members[i] = &failoverTestMember{
+			name:  fmt.Sprintf("member%d", i),
+			addr:  lis.Addr().String(),
+			role:  RoleStandby,
+			epoch: 1,
+		}
+		require.NoError(t, lis.Close())
+	}
+	members[0].role = RolePrimary
+	for _, tm := range members {
+		tm.start(t, members)
+	}
+	t.Cleanup(func() {
+		for _, tm := range members {
+			if tm.srv != nil {
+				tm.stop()
+			}
+		}
+	})
+	return members
+}
+
+// Returns the members which are primary, and the highest epoch of any member.
+func primaries(members []*failoverTestMember) ([]*failoverTestMember, int) {
+	var ret []*failoverTestMember
+	highest := 0
+	for _, tm := range members {
+		role, epoch := tm.roleAndEpoch()
+		if role == RolePrimary {
+			ret = append(ret, tm)
+		}
+		if epoch > highest {
+			highest = epoch
+		}
+	}
+	return ret, highest
+}
+
+func TestFailover(t *testing.T) {
+	t.Run("StablePrimary", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		time.Sleep(5 * testLeaseDuration)
+		for i, tm := range members {
+			role, epoch := tm.roleAndEpoch()
+			assert.Equal(t, 1, epoch)
+			if i == 0 {
+				assert.Equal(t, RolePrimary, role)
+				assert.Equal(t, FailoverStateLeader, tm.failoverState())
+			} else {
+				assert.Equal(t, RoleStandby, role)
+				assert.Equal(t, FailoverStateFollower, tm.failoverState())
+			}
+		}
+	})
+	t.Run("ElectsNewPrimaryAndDemotesReturningPrimary", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		old := members[0]
+		old.stop()
+
+		var newPrimary *failoverTestMember
+		require.Eventually(t, func() bool {
+			ps, _ := primaries(members[1:])
+			if len(ps) != 1 {
+				return false
+			}
+			newPrimary = ps[0]
+			return true
+		}, 5*time.Second, testHeartbeatInterval)
+		_, newEpoch := newPrimary.roleAndEpoch()
+		assert.Greater(t, newEpoch, 1)
+		assert.Eventually(t, func() bool {
+			for _, tm := range members[1:] {
+				if tm == newPrimary {
+					continue
+				}
+				role, epoch := tm.roleAndEpoch()
+				if role != RoleStandby || epoch != newEpoch || tm.failoverState() != FailoverStateFollower {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, testHeartbeatInterval)
+
+		// The old primary returns, still believing it is the primary
+		// at the old epoch.
+		role, epoch := old.roleAndEpoch()
+		require.Equal(t, RolePrimary, role)
+		require.Equal(t, 1, epoch)
+		old.start(t, members)
+		require.Eventually(t, func() bool {
+			role, epoch := old.roleAndEpoch()
+			return role == RoleStandby && epoch >= newEpoch
+		}, 5*time.Second, testHeartbeatInterval)
+		time.Sleep(5 * testLeaseDuration)
+		ps, _ := primaries(members)
+		require.Len(t, ps, 1)
+		assert.Equal(t, newPrimary, ps[0])
+	})
+	t.Run("PrimaryStepsDownWithoutMajority", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		members[1].stop()
+		members[2].stop()
+		require.Eventually(t, func() bool {
+			role, epoch := members[0].roleAndEpoch()
+			return role == RoleStandby && epoch == 1
+		}, 5*time.Second, testHeartbeatInterval)
+	})
+	t.Run("PrimaryStepsDownBeforeLeaseExpires", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		primary := members[0]
+		time.Sleep(2 * testHeartbeatInterval)
+		members[1].stop()
+		members[2].stop()
+		var leaseExpires time.Time
+		for {
+			primary.mu.Lock()
+			role, steppedDown := primary.role, primary.steppedDown
+			primary.mu.Unlock()
+			if role != RolePrimary {
+				assert.True(t, steppedDown.Before(leaseExpires), "stepped down at %v, lease expired at %v", steppedDown, leaseExpires)
+				break
+			}
+			_, leaseExpires = primary.m.status()
+			time.Sleep(time.Millisecond)
+		}
+	})
+	t.Run("ElectsUpToDateStandby", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		for _, tm := range members {
+			tm.mu.Lock()
+			tm.positions = map[string]rootToken{"db": {epoch: 1, seq: 10}}
+			tm.mu.Unlock()
+		}
+		// members[2] did not apply the latest writes of the old primary.
+		members[2].mu.Lock()
+		members[2].positions = map[string]rootToken{"db": {epoch: 1, seq: 8}}
+		members[2].mu.Unlock()
+		members[0].stop()
+
+		require.Eventually(t, func() bool {
+			ps, _ := primaries(members[1:])
+			return len(ps) == 1
+		}, 5*time.Second, testHeartbeatInterval)
+		ps, _ := primaries(members[1:])
+		assert.Equal(t, members[1], ps[0])
+	})
+	t.Run("MinorityCanNotElect", func(t *testing.T) {
+		members := newFailoverTestCluster(t, 3)
+		members[0].stop()
+		members[1].stop()
+		time.Sleep(5 * testLeaseDuration)
+		role, _ := members[2].roleAndEpoch()
+		assert.Equal(t, RoleStandby, role)
+	})
+}
+
+func TestFailoverVotes(t *testing.T) {
+	ctx := context.Background()
+	var persisted int
+	var positions map[string]rootToken
+	newManager := func(role Role, epoch int) *failoverManager {
+		m := newFailoverManager(lgr, testHeartbeatInterval, testLeaseDuration, "self", nil, role, epoch, 0)
+		m.persistVotedEpoch = func(epoch int) error {
+			persisted = epoch
+			return nil
+		}
+		m.replicationPositions = func(context.Context) (map[string]rootToken, error) {
+			return positions, nil
+		}
+		return m
+	}
+	t.Run("StandbyWithLiveLease", func(t *testing.T) {
+		m := newManager(RoleStandby, 1)
+		resp := m.handleRequestVote(ctx, 2, "candidate", nil)
+		assert.False(t, resp.Granted)
+	})
+	t.Run("OneVotePerEpoch", func(t *testing.T) {
+		m := newManager(RoleStandby, 1)
+		time.Sleep(testLeaseDuration)
+		assert.False(t, m.handleRequestVote(ctx, 1, "candidate", nil).Granted)
+		resp := m.handleRequestVote(ctx, 2, "candidate", nil)
+		assert.True(t, resp.Granted)
+		assert.Equal(t, int64(2), resp.Epoch)
+		assert.Equal(t, 2, persisted)
+		resp = m.handleRequestVote(ctx, 2, "other_candidate", nil)
+		assert.False(t, resp.Granted)
+		assert.Equal(t, int64(2), resp.Epoch)
+	})
+	t.Run("VoterRejectsHeartbeatsFromOldEpoch", func(t *testing.T) {
+		m := newManager(RoleStandby, 1)
+		time.Sleep(testLeaseDuration)
+		require.True(t, m.handleRequestVote(ctx, 2, "candidate", nil).Granted)
+		resp := m.handleHeartbeat(1)
+		assert.False(t, resp.Acknowledged)
+		assert.Equal(t, int64(2), resp.Epoch)
+	})
+	t.Run("PrimaryWithLiveLease", func(t *testing.T) {
+		m := newManager(RolePrimary, 1)
+		assert.False(t, m.handleRequestVote(ctx, 2, "candidate", nil).Granted)
+		time.Sleep(testLeaseDuration)
+		assert.True(t, m.handleRequestVote(ctx, 2, "candidate", nil).Granted)
+	})
+	t.Run("DetectedBrokenConfig", func(t *testing.T) {
+		m := newManager(RoleDetectedBrokenConfig, 1)
+		time.Sleep(testLeaseDuration)
+		assert.False(t, m.handleRequestVote(ctx, 2, "candidate", nil).Granted)
+		state, _ := m.status()
+		assert.Equal(t, FailoverStateInactive, state)
+	})
+	t.Run("CandidateBehindVoter", func(t *testing.T) {
+		positions = map[string]rootToken{"db": {epoch: 1, seq: 10}, "other": {epoch: 1, seq: 3}}
+		defer func() {
+			positions = nil
+		}()
+		m := newManager(RoleStandby, 1)
+		time.Sleep(testLeaseDuration)
+		assert.False(t, m.handleRequestVote(ctx, 2, "candidate", nil).Granted)
+		assert.False(t, m.handleRequestVote(ctx, 2, "candidate", map[string]rootToken{"db": {epoch: 1, seq: 10}}).Granted)
+		assert.False(t, m.handleRequestVote(ctx, 2, "candidate", map[string]rootToken{"db": {epoch: 1, seq: 9}, "other": {epoch: 1, seq: 3}}).Granted)
+		// Denied votes do not count as votes in the epoch.
+		assert.True(t, m.handleRequestVote(ctx, 2, "candidate", map[string]rootToken{"db": {epoch: 1, seq: 10}, "other": {epoch: 1, seq: 3}}).Granted)
+		assert.True(t, m.handleRequestVote(ctx, 3, "candidate", map[string]rootToken{"db": {epoch: 2, seq: 1}, "other": {epoch: 1, seq: 4}}).Granted)
+	})
+}
@@ -77,13 +77,15 @@ func NewInitDatabaseHook(controller *Controller, bt *sql.BackgroundThreads) sqle
 		controller.cancelDropDatabaseReplication(name)
 
 		role, _ := controller.roleAndEpoch()
+		quorum := controller.replicationQuorum(name)
+		sequencer := controller.rootSequencer(name)
 		for i, r := range controller.cfg.StandbyRemotes() {
 			ttfdir, err := denv.TempTableFilesDir()
 			if err != nil {
 				// XXX: An error here means we are not replicating to every standby.
 				return err
 			}
-			commitHook := newCommitHook(controller.lgr, r.Name(), remoteUrls[i], name, role, remoteDBs[i], denv.DoltDB, ttfdir)
+			commitHook := newCommitHook(controller.lgr, r.Name(), remoteUrls[i], name, role, remoteDBs[i], denv.DoltDB, ttfdir, quorum, sequencer, controller.replicationProgressReporter(r.Name(), name))
 			denv.DoltDB.PrependCommitHook(ctx, commitHook)
 			controller.registerCommitHook(commitHook)
 			if err := commitHook.Run(bt); err != nil {
@@ -36,11 +36,20 @@ const clusterRoleEpochHeader = "x-dolt-cluster-role-epoch"
 
 var writeEndpoints map[string]bool
 
+// Automatic failover endpoints are called between members of the cluster
+// regardless of their roles. A standby asks for votes, and a primary answers
+// them, so these are exempt from the role checks of the interceptors.
+var failoverEndpoints map[string]bool
+
 func init() {
 	writeEndpoints = make(map[string]bool)
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/Commit"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/AddTableFiles"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/GetUploadLocations"] = true
+
+	failoverEndpoints = make(map[string]bool)
+	failoverEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/Heartbeat"] = true
+	failoverEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote"] = true
 }
 
 func isLikelyServerResponse(err error) bool {
@@ -65,7 +74,8 @@ func isLikelyServerResponse(err error) bool {
 // outbound request.
 // * fails all outgoing requests immediately with codes.FailedPrecondition if
 // the role == RoleStandby, since this server should not be replicating when it
-// believes it is a standby.
+// believes it is a standby. Requests to the automatic failover endpoints are
+// let through in any role.
 // * watches returned response headers for a situation which causes this server
 // to force downgrade from primary to standby. In particular, when a returned
 // response header asserts that the standby replica is a primary at a higher
@@ -114,10 +124,10 @@ func (ci *clientinterceptor) Unary() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		role, epoch := ci.getRole()
 		ci.lgr.Tracef("cluster: clientinterceptor: processing request to %s, role %s", method, string(role))
-		if role == RoleStandby {
+		if role == RoleStandby && !failoverEndpoints[method] {
 			return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
 		}
-		if role == RoleDetectedBrokenConfig {
+		if role == RoleDetectedBrokenConfig && !failoverEndpoints[method] {
 			return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is in detected_broken_config and is not currently replicating to its standby")
 		}
 		ctx = metadata.AppendToOutgoingContext(ctx, clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))
@@ -178,6 +188,7 @@ func (ci *clientinterceptor) Options() []grpc.DialOption {
 // * for any incoming standby traffic, it will fail incoming requests
 // immediately with codes.FailedPrecondition if the current role !=
 // RoleStandby, since nothing should be replicating to us in that state.
+// Requests to the automatic failover endpoints are let through in any role.
 // * watches incoming request headers for a situation which causes this server
 // to force downgrade from primary to standby. In particular, when an incoming
 // request asserts that the client is the current primary at an epoch higher
@@ -249,6 +260,9 @@ func (si *serverinterceptor) Unary() grpc.UnaryServerInterceptor {
 			if err := grpc.SetHeader(ctx, metadata.Pairs(clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))); err != nil {
 				return nil, err
 			}
+			if failoverEndpoints[info.FullMethod] {
+				return handler(ctx, req)
+			}
 			if role == RolePrimary {
 				// As a primary, we do not accept replication requests.
 				return nil, status.Error(codes.FailedPrecondition, "this server is a primary and is not currently accepting replication")
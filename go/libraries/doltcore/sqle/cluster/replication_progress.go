@@ -0,0 +1,314 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// How long a primary waits for a standby to accept a report of its
+// replication progress.
+const replicationProgressTimeout = 5 * time.Second
+
+// How often a session waiting on a root token rechecks this server's role,
+// even if no replication progress was made.
+const waitForRootRecheckInterval = time.Second
+
+var ErrMalformedRootToken = errors.New("cluster: malformed root token; expected a value returned from dolt_cluster_root()")
+
+// The root hashes of a database are not ordered and a commithook can skip
+// some of the roots a primary writes, so a primary instead assigns each new
+// root it sees an increasing sequence number. Sequence numbers are taken
+// from the wallclock when possible, so that they keep increasing across a
+// restart of the primary within the same epoch.
+type rootSequencer struct {
+	mu   sync.Mutex
+	root hash.Hash
+	seq  int64
+}
+
+// Returns the sequence number of |root|, assigning a new one if it is not
+// the most recently observed root.
+func (s *rootSequencer) observe(root hash.Hash) int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if root != s.root {
+		s.root = root
+		next := time.Now().UnixNano()
+		if next <= s.seq {
+			next = s.seq + 1
+		}
+		s.seq = next
+	}
+	return s.seq
+}
+
+// A rootToken identifies a root of a database which was written by the
+// primary at |epoch|. It is returned to clients formatted as "epoch:seq".
+type rootToken struct {
+	epoch int
+	seq   int64
+}
+
+func (t rootToken) String() string {
+	return strconv.Itoa(t.epoch) + ":" + strconv.FormatInt(t.seq, 10)
+}
+
+// Returns true if a server which has applied |t| has also applied |other|.
+// A root from a later epoch includes every root of an earlier epoch which
+// survived the failover.
+func (t rootToken) includes(other rootToken) bool {
+	if t.epoch != other.epoch {
+		return t.epoch > other.epoch
+	}
+	return t.seq >= other.seq
+}
+
+func parseRootToken(s string) (rootToken, error) {
+	epochStr, seqStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return rootToken{}, ErrMalformedRootToken
+	}
+	epoch, err := strconv.Atoi(epochStr)
+	if err != nil || epoch < 0 {
+		return rootToken{}, ErrMalformedRootToken
+	}
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil || seq < 0 {
+		return rootToken{}, ErrMalformedRootToken
+	}
+	return rootToken{epoch: epoch, seq: seq}, nil
+}
+
+// The replication progress a standby has applied for a database. |ch| is
+// closed and replaced every time |applied| changes.
+type appliedProgress struct {
+	applied rootToken
+	ch      chan struct{}
+}
+
+// Returns the rootSequencer for the database |name|, which is shared by all
+// of its commithooks.
+func (c *Controller) rootSequencer(name string) *rootSequencer {
+	name = strings.ToLower(name)
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	s, ok := c.sequencers[name]
+	if !ok {
+		s = &rootSequencer{}
+		c.sequencers[name] = s
+	}
+	return s
+}
+
+// Returns the function a commithook replicating |name| to |remote| uses to
+// tell the standby how far it has caught up, or nil if there is no
+// replication service client for |remote|.
+func (c *Controller) replicationProgressReporter(remote, name string) func(context.Context, int64) error {
+	for _, client := range c.replicationClients {
+		if client.remote == remote {
+			client := client
+			return func(ctx context.Context, seq int64) error {
+				ctx, cancel := context.WithTimeout(ctx, replicationProgressTimeout)
+				defer cancel()
+				_, err := client.client.UpdateReplicationProgress(ctx, &replicationapi.UpdateReplicationProgressRequest{
+					Database: name,
+					Sequence: seq,
+				})
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Called on a standby when the primary at |epoch| reports that it has
+// replicated the root with sequence number |seq| of the database |name|.
+func (c *Controller) recordReplicationProgress(name string, epoch int, seq int64) {
+	name = strings.ToLower(name)
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	token := rootToken{epoch: epoch, seq: seq}
+	p, ok := c.applied[name]
+	if !ok {
+		p = &appliedProgress{ch: make(chan struct{})}
+		c.applied[name] = p
+	} else if p.applied.includes(token) {
+		return
+	}
+	p.applied = token
+	close(p.ch)
+	p.ch = make(chan struct{})
+}
+
+// Returns the applied progress of |name| on this standby and a channel
+// which is closed when it changes.
+func (c *Controller) appliedProgress(name string) (rootToken, bool, chan struct{}) {
+	name = strings.ToLower(name)
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	p, ok := c.applied[name]
+	if !ok {
+		p = &appliedProgress{ch: make(chan struct{})}
+		c.applied[name] = p
+	}
+	return p.applied, ok, p.ch
+}
+
+func (c *Controller) forgetReplicationProgress(name string) {
+	name = strings.ToLower(name)
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	delete(c.sequencers, name)
+	if p, ok := c.applied[name]; ok {
+		close(p.ch)
+		delete(c.applied, name)
+	}
+}
+
+// Returns a token for the current root of the database |name|. On a primary
+// this is its latest root. On a standby it is the latest root it has
+// applied, and false is returned if it has not yet applied one.
+func (c *Controller) clusterRoot(ctx context.Context, name string) (rootToken, bool, error) {
+	role, epoch := c.roleAndEpoch()
+	if role != RolePrimary {
+		applied, ok, _ := c.appliedProgress(name)
+		return applied, ok, nil
+	}
+	srcDB := c.sourceDoltDB(name)
+	if srcDB == nil {
+		return rootToken{}, false, fmt.Errorf("database %s is not replicated by this cluster", name)
+	}
+	cs := datas.ChunkStoreFromDatabase(doltdb.HackDatasDatabaseFromDoltDB(srcDB))
+	root, err := cs.Root(ctx)
+	if err != nil {
+		return rootToken{}, false, err
+	}
+	return rootToken{epoch: epoch, seq: c.rootSequencer(name).observe(root)}, true, nil
+}
+
+// Returns a token for the current root of every database replicated by this
+// cluster, keyed by lower cased database name. Databases for which this
+// standby has not yet applied a root are omitted.
+func (c *Controller) replicationPositions(ctx context.Context) (map[string]rootToken, error) {
+	c.mu.Lock()
+	names := make(map[string]struct{})
+	for _, h := range c.commithooks {
+		names[strings.ToLower(h.dbname)] = struct{}{}
+	}
+	c.mu.Unlock()
+	ret := make(map[string]rootToken, len(names))
+	for name := range names {
+		token, ok, err := c.clusterRoot(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ret[name] = token
+		}
+	}
+	return ret, nil
+}
+
+func (c *Controller) sourceDoltDB(name string) *doltdb.DoltDB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, h := range c.commithooks {
+		if strings.EqualFold(h.dbname, name) {
+			return h.srcDB
+		}
+	}
+	return nil
+}
+
+// Blocks until this server has applied the root identified by |token| for
+// the database |name|, returning true, or until |timeout| elapses, returning
+// false. A primary has applied every root handed out at its epoch or
+// earlier.
+func (c *Controller) waitForRoot(ctx context.Context, name string, token rootToken, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		role, epoch := c.roleAndEpoch()
+		var ch chan struct{}
+		if role == RolePrimary {
+			if token.epoch <= epoch {
+				return true, nil
+			}
+		} else {
+			var applied rootToken
+			var ok bool
+			applied, ok, ch = c.appliedProgress(name)
+			if ok && applied.includes(token) {
+				return true, nil
+			}
+		}
+		select {
+		case <-ch:
+		case <-time.After(waitForRootRecheckInterval):
+		case <-timer.C:
+			return false, nil
+		case <-ctx.Done():
+			return false, context.Cause(ctx)
+		}
+	}
+}
+
+// Returns how long it has been since this standby last heard from its
+// primary about the database |name|, either through a replicated write or
+// a heartbeat. Returns false if this server is not a standby or does not
+// replicate |name|.
+func (c *Controller) ReplicaLag(name string) (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	role := c.role
+	commithooks := make([]*commithook, len(c.commithooks))
+	copy(commithooks, c.commithooks)
+	c.mu.Unlock()
+	if role != RoleStandby {
+		return 0, false
+	}
+	var found bool
+	var lastUpdate time.Time
+	for _, h := range commithooks {
+		if strings.EqualFold(h.dbname, name) {
+			found = true
+			_, update, _ := h.status()
+			if update != nil && update.After(lastUpdate) {
+				lastUpdate = *update
+			}
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	return time.Since(lastUpdate), true
+}
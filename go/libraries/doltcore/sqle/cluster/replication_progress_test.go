@@ -0,0 +1,121 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestRootSequencer(t *testing.T) {
+	var s rootSequencer
+	a := hash.Of([]byte("a"))
+	b := hash.Of([]byte("b"))
+	seqA := s.observe(a)
+	assert.Equal(t, seqA, s.observe(a))
+	seqB := s.observe(b)
+	assert.Greater(t, seqB, seqA)
+	// Coming back to a root we have seen before still moves forward.
+	assert.Greater(t, s.observe(a), seqB)
+
+	var nilS *rootSequencer
+	assert.Equal(t, int64(0), nilS.observe(a))
+}
+
+func TestRootToken(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		token, err := parseRootToken(rootToken{epoch: 3, seq: 12345}.String())
+		require.NoError(t, err)
+		assert.Equal(t, rootToken{epoch: 3, seq: 12345}, token)
+	})
+	t.Run("Malformed", func(t *testing.T) {
+		for _, s := range []string{"", "3", "3:", ":12", "a:12", "3:b", "-1:12", "3:-12", "3:12:5"} {
+			_, err := parseRootToken(s)
+			assert.ErrorIs(t, err, ErrMalformedRootToken, s)
+		}
+	})
+	t.Run("Includes", func(t *testing.T) {
+		assert.True(t, rootToken{epoch: 3, seq: 10}.includes(rootToken{epoch: 3, seq: 10}))
+		assert.True(t, rootToken{epoch: 3, seq: 11}.includes(rootToken{epoch: 3, seq: 10}))
+		assert.False(t, rootToken{epoch: 3, seq: 9}.includes(rootToken{epoch: 3, seq: 10}))
+		assert.True(t, rootToken{epoch: 4, seq: 1}.includes(rootToken{epoch: 3, seq: 10}))
+		assert.False(t, rootToken{epoch: 2, seq: 100}.includes(rootToken{epoch: 3, seq: 10}))
+	})
+}
+
+func newProgressTestController(role Role, epoch int) *Controller {
+	return &Controller{
+		role:       role,
+		epoch:      epoch,
+		sequencers: make(map[string]*rootSequencer),
+		applied:    make(map[string]*appliedProgress),
+	}
+}
+
+func TestWaitForRoot(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Primary", func(t *testing.T) {
+		c := newProgressTestController(RolePrimary, 3)
+		applied, err := c.waitForRoot(ctx, "mydb", rootToken{epoch: 3, seq: 10}, time.Second)
+		require.NoError(t, err)
+		assert.True(t, applied)
+		applied, err = c.waitForRoot(ctx, "mydb", rootToken{epoch: 4, seq: 10}, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, applied)
+	})
+	t.Run("StandbyTimesOut", func(t *testing.T) {
+		c := newProgressTestController(RoleStandby, 3)
+		c.recordReplicationProgress("mydb", 3, 9)
+		applied, err := c.waitForRoot(ctx, "mydb", rootToken{epoch: 3, seq: 10}, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, applied)
+	})
+	t.Run("StandbyWakesOnProgress", func(t *testing.T) {
+		c := newProgressTestController(RoleStandby, 3)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			c.recordReplicationProgress("MyDB", 3, 5)
+			time.Sleep(10 * time.Millisecond)
+			c.recordReplicationProgress("mydb", 3, 10)
+		}()
+		start := time.Now()
+		applied, err := c.waitForRoot(ctx, "mydb", rootToken{epoch: 3, seq: 10}, 5*time.Second)
+		require.NoError(t, err)
+		assert.True(t, applied)
+		assert.Less(t, time.Since(start), waitForRootRecheckInterval)
+	})
+	t.Run("StaleProgressIsIgnored", func(t *testing.T) {
+		c := newProgressTestController(RoleStandby, 4)
+		c.recordReplicationProgress("mydb", 4, 1)
+		c.recordReplicationProgress("mydb", 3, 100)
+		token, ok, err := c.clusterRoot(ctx, "mydb")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, rootToken{epoch: 4, seq: 1}, token)
+	})
+	t.Run("ContextCanceled", func(t *testing.T) {
+		c := newProgressTestController(RoleStandby, 3)
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := c.waitForRoot(ctx, "mydb", rootToken{epoch: 3, seq: 10}, time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
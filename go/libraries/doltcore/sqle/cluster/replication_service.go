@@ -16,11 +16,13 @@ package cluster
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/mysql_db"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
@@ -44,6 +46,10 @@ type replicationServiceServer struct {
 	branchControlFilesys filesys.Filesys
 
 	dropDatabase func(*sql.Context, string) error
+
+	failover *failoverManager
+
+	updateProgress func(database string, epoch int, seq int64)
 }
 
 func (s *replicationServiceServer) UpdateUsersAndGrants(ctx context.Context, req *replicationapi.UpdateUsersAndGrantsRequest) (*replicationapi.UpdateUsersAndGrantsResponse, error) {
@@ -99,3 +105,38 @@ func (s *replicationServiceServer) DropDatabase(ctx context.Context, req *replic
 	}
 	return &replicationapi.DropDatabaseResponse{}, nil
 }
+
+func (s *replicationServiceServer) Heartbeat(ctx context.Context, req *replicationapi.HeartbeatRequest) (*replicationapi.HeartbeatResponse, error) {
+	if s.failover == nil {
+		return nil, status.Error(codes.FailedPrecondition, "automatic failover is not configured on this server")
+	}
+	return s.failover.handleHeartbeat(int(req.Epoch)), nil
+}
+
+func (s *replicationServiceServer) RequestVote(ctx context.Context, req *replicationapi.RequestVoteRequest) (*replicationapi.RequestVoteResponse, error) {
+	if s.failover == nil {
+		return nil, status.Error(codes.FailedPrecondition, "automatic failover is not configured on this server")
+	}
+	return s.failover.handleRequestVote(ctx, int(req.Epoch), req.Candidate, positionsFromProto(req.Positions)), nil
+}
+
+func (s *replicationServiceServer) UpdateReplicationProgress(ctx context.Context, req *replicationapi.UpdateReplicationProgressRequest) (*replicationapi.UpdateReplicationProgressResponse, error) {
+	if s.updateProgress == nil {
+		return nil, status.Error(codes.Unimplemented, "unimplemented")
+	}
+	// The sequence numbers of a primary are only meaningful within its
+	// epoch, which comes in on the request headers.
+	var epochs []string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		epochs = md.Get(clusterRoleEpochHeader)
+	}
+	if len(epochs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing cluster role epoch")
+	}
+	epoch, err := strconv.Atoi(epochs[0])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid cluster role epoch")
+	}
+	s.updateProgress(req.Database, epoch, req.Sequence)
+	return &replicationapi.UpdateReplicationProgressResponse{}, nil
+}
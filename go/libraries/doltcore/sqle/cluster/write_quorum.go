@@ -0,0 +1,147 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+// How often a primary which is read only because a strict write quorum was
+// not reached checks whether enough of its standbys have caught up.
+const quorumReadOnlyPollInterval = time.Second
+
+// The outcome of the most recent wait on a write quorum for a database.
+type writeWaitStatus struct {
+	duration time.Duration
+	acked    map[string]struct{}
+}
+
+// Returns the ReplicationQuorum for the commithooks of the database |name|,
+// or nil if the cluster config does not have a write_quorum.
+func (c *Controller) replicationQuorum(name string) *doltdb.ReplicationQuorum {
+	cfg := c.cfg.WriteQuorumConfig()
+	if cfg == nil {
+		return nil
+	}
+	var settings servercfg.ClusterWriteQuorum = cfg
+	for _, db := range cfg.Databases() {
+		if strings.EqualFold(db.Name(), name) {
+			settings = db
+			break
+		}
+	}
+	acks, strict, readOnlyOnFailure := settings.Acks(), settings.Strict(), settings.ReadOnlyOnFailure()
+	return &doltdb.ReplicationQuorum{
+		Acks:   acks,
+		Strict: strict,
+		Report: func(res doltdb.ReplicationWaitResult) {
+			c.recordWriteWait(name, res)
+			if !res.QuorumReached {
+				c.lgr.Warnf("cluster/controller: a write to %s was acknowledged by %d standbys, which did not reach its write quorum", name, len(res.Acked))
+				if strict && readOnlyOnFailure {
+					c.enterQuorumReadOnly(name, acks)
+				}
+			}
+		},
+	}
+}
+
+func (c *Controller) recordWriteWait(name string, res doltdb.ReplicationWaitResult) {
+	acked := make(map[string]struct{}, len(res.Acked))
+	for _, r := range res.Acked {
+		acked[r] = struct{}{}
+	}
+	c.writeWaitsMu.Lock()
+	defer c.writeWaitsMu.Unlock()
+	c.writeWaits[name] = writeWaitStatus{
+		duration: res.Duration,
+		acked:    acked,
+	}
+}
+
+// Returns the outcome of the most recent wait on a write quorum for
+// |name|, if there was one.
+func (c *Controller) lastWriteWait(name string) (writeWaitStatus, bool) {
+	c.writeWaitsMu.Lock()
+	defer c.writeWaitsMu.Unlock()
+	ret, ok := c.writeWaits[name]
+	return ret, ok
+}
+
+// Makes this primary read only until |acks| of the standbys of the database
+// |name| have caught up, or every standby if |acks| is 0. A role transition
+// also ends the read only period.
+func (c *Controller) enterQuorumReadOnly(name string, acks int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.role != RolePrimary {
+		return
+	}
+	if c.quorumReadOnly == nil {
+		c.lgr.Warnf("cluster/controller: a strict write quorum was not reached for %s. this server will not accept writes until enough standbys catch up.", name)
+		c.quorumReadOnly = make(map[string]int)
+		c.setProviderIsStandby(true)
+		go c.waitForQuorumToRecover()
+	}
+	c.quorumReadOnly[name] = acks
+}
+
+func (c *Controller) waitForQuorumToRecover() {
+	for {
+		time.Sleep(quorumReadOnlyPollInterval)
+		if c.checkQuorumRecovered() {
+			return
+		}
+	}
+}
+
+// Returns true once this primary is no longer read only because of a write
+// quorum, making it writable again if every database has recovered.
+func (c *Controller) checkQuorumRecovered() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.quorumReadOnly == nil {
+		return true
+	}
+	for name, acks := range c.quorumReadOnly {
+		caughtUp, total := 0, 0
+		for _, h := range c.commithooks {
+			if h.dbname == name {
+				total += 1
+				if h.caughtUp() {
+					caughtUp += 1
+				}
+			}
+		}
+		required := acks
+		if required == 0 {
+			required = total
+		}
+		if total == 0 || caughtUp >= required {
+			delete(c.quorumReadOnly, name)
+		}
+	}
+	if len(c.quorumReadOnly) > 0 {
+		return false
+	}
+	c.lgr.Infof("cluster/controller: enough standbys caught up to reach the write quorum. this server is accepting writes again.")
+	c.quorumReadOnly = nil
+	c.setProviderIsStandby(false)
+	return true
+}
@@ -38,6 +38,23 @@ type ReplicaStatus struct {
 	// A string describing the last encountered error.  NULL when we are a
 	// standby. NULL when our last replication attempt succeeded.
 	CurrentError *string
+	// The state of automatic failover on this server. "leader",
+	// "follower", "candidate" or "inactive". NULL when automatic failover
+	// is not configured.
+	FailoverState *string
+	// As a primary, the time at which our lease expires unless a majority
+	// of the cluster acknowledges our heartbeats. As a standby, the time
+	// at which the lease of the primary we last heard from expires. NULL
+	// when automatic failover is not configured.
+	FailoverLeaseExpires *time.Time
+	// As a primary, how long the most recent write to this database
+	// waited on its write quorum. NULL when no write_quorum is
+	// configured or no write has waited on it yet.
+	LastWriteWait *time.Duration
+	// As a primary, whether this standby acknowledged the most recent
+	// write to this database which waited on its write quorum. NULL when
+	// LastWriteWait is NULL.
+	LastWriteAcked *bool
 }
 
 type ClusterStatusProvider interface {
@@ -93,7 +110,7 @@ func replicaStatusesToRows(rss []ReplicaStatus) []sql.Row {
 }
 
 func replicaStatusToRow(rs ReplicaStatus) sql.Row {
-	ret := make(sql.Row, 7)
+	ret := make(sql.Row, 11)
 	ret[0] = rs.Database
 	ret[1] = rs.Remote
 	ret[2] = rs.Role
@@ -107,6 +124,18 @@ func replicaStatusToRow(rs ReplicaStatus) sql.Row {
 	if rs.CurrentError != nil {
 		ret[6] = *rs.CurrentError
 	}
+	if rs.FailoverState != nil {
+		ret[7] = *rs.FailoverState
+	}
+	if rs.FailoverLeaseExpires != nil {
+		ret[8] = *rs.FailoverLeaseExpires
+	}
+	if rs.LastWriteWait != nil {
+		ret[9] = rs.LastWriteWait.Milliseconds()
+	}
+	if rs.LastWriteAcked != nil {
+		ret[10] = *rs.LastWriteAcked
+	}
 	return ret
 }
 
@@ -119,5 +148,9 @@ func (t ClusterStatusTable) Schema() sql.Schema {
 		{Name: "replication_lag_millis", Type: types.Int64, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 		{Name: "last_update", Type: types.Datetime, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 		{Name: "current_error", Type: types.Text, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "failover_state", Type: types.Text, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "failover_lease_expires", Type: types.Datetime, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "last_write_wait_millis", Type: types.Int64, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "last_write_acked", Type: types.Boolean, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 	}
 }
@@ -43,6 +43,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/rebase"
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schmigrations"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
@@ -459,6 +460,8 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		dt, found = dtables.NewStatusTable(ctx, db.ddb, ws, adapter), true
 	case doltdb.MergeStatusTableName:
 		dt, found = dtables.NewMergeStatusTable(db.RevisionQualifiedName()), true
+	case doltdb.MigrationsTableName:
+		dt, found = dtables.NewMigrationsTable(db.RevisionQualifiedName()), true
 	case doltdb.TagsTableName:
 		dt, found = dtables.NewTagsTable(ctx, db.ddb), true
 	case dtables.AccessTableName:
@@ -486,6 +489,17 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 			versionableTable := backingTable.(dtables.VersionableTable)
 			dt, found = dtables.NewIgnoreTable(ctx, versionableTable), true
 		}
+	case doltdb.MaskingTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.MaskingTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyMaskingTable(ctx), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewMaskingTable(ctx, versionableTable), true
+		}
 	case doltdb.DocTableName:
 		backingTable, _, err := db.getTable(ctx, root, doltdb.DocTableName)
 		if err != nil {
@@ -624,6 +638,9 @@ func resolveAsOfCommitRef(ctx *sql.Context, db Database, head ref.DoltRef, commi
 	if !ok {
 		return nil, nil, doltdb.ErrGhostCommitEncountered
 	}
+	if err = dsess.CheckReadAccessForCommitSpec(ctx, db.Name(), ddb, cs, cm); err != nil {
+		return nil, nil, err
+	}
 
 	root, err := cm.GetRootValue(ctx)
 	if err != nil {
@@ -1795,7 +1812,34 @@ func (db Database) DropStoredProcedure(ctx *sql.Context, name string) error {
 	return DoltProceduresDropProcedure(ctx, db, name)
 }
 
+// AddSchemaMigration implements schmigrations.Database.
+func (db Database) AddSchemaMigration(ctx *sql.Context, m schmigrations.Migration) error {
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return err
+	}
+	migrations, err := schmigrations.Load(ctx, root)
+	if err != nil {
+		return err
+	}
+	for _, existing := range migrations {
+		if existing.Sequence == m.Sequence && !strings.EqualFold(existing.Name, m.Name) {
+			return schmigrations.ErrSequenceExists.New(existing.Name, existing.Sequence)
+		}
+	}
+
+	return db.insertFragIntoSchemasTable(ctx, schmigrations.FragmentType, m.Name, m.Up, schmigrations.NewExtra(m), schmigrations.ErrMigrationExists.New(m.Name))
+}
+
 func (db Database) addFragToSchemasTable(ctx *sql.Context, fragType, name, definition string, created time.Time, existingErr error) (err error) {
+	// Encode createdAt time to JSON
+	extra := Extra{
+		CreatedAt: created.Unix(),
+	}
+	return db.insertFragIntoSchemasTable(ctx, fragType, name, definition, extra, existingErr)
+}
+
+func (db Database) insertFragIntoSchemasTable(ctx *sql.Context, fragType, name, definition string, extra interface{}, existingErr error) (err error) {
 	if err := dsess.CheckAccessForDb(ctx, db, branch_control.Permissions_Write); err != nil {
 		return err
 	}
@@ -1820,10 +1864,6 @@ func (db Database) addFragToSchemasTable(ctx *sql.Context, fragType, name, defin
 			err = cErr
 		}
 	}()
-	// Encode createdAt time to JSON
-	extra := Extra{
-		CreatedAt: created.Unix(),
-	}
 	extraJSON, err := json.Marshal(extra)
 	if err != nil {
 		return err
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 
@@ -65,8 +66,13 @@ type DoltDatabaseProvider struct {
 
 	dbFactoryUrl string
 	isStandby    *bool
+	replicaLag   *ReplicaLagFunc
 }
 
+// ReplicaLagFunc returns how far behind its primary this server's copy of the database |name| is. It returns false if
+// this server is not replicating |name| from a primary.
+type ReplicaLagFunc func(name string) (time.Duration, bool)
+
 var _ sql.DatabaseProvider = (*DoltDatabaseProvider)(nil)
 var _ sql.FunctionProvider = (*DoltDatabaseProvider)(nil)
 var _ sql.MutableDatabaseProvider = (*DoltDatabaseProvider)(nil)
@@ -149,6 +155,7 @@ func NewDoltDatabaseProviderWithDatabases(defaultBranch string, fs filesys.Files
 		dbFactoryUrl:           dbFactoryUrl,
 		InitDatabaseHooks:      []InitDatabaseHook{ConfigureReplicationDatabaseHook},
 		isStandby:              new(bool),
+		replicaLag:             new(ReplicaLagFunc),
 		droppedDatabaseManager: newDroppedDatabaseManager(fs),
 	}, nil
 }
@@ -204,6 +211,36 @@ func (p *DoltDatabaseProvider) SetIsStandby(standby bool) {
 	*p.isStandby = standby
 }
 
+// SetReplicaLagFunc sets the function used to find how far behind its primary a standby database is. When this
+// provider is set to standby, reads fail for databases which are further behind than the session's
+// dolt_max_replica_lag_ms.
+func (p *DoltDatabaseProvider) SetReplicaLagFunc(f ReplicaLagFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	*p.replicaLag = f
+}
+
+// checkReplicaLag returns an error if this standby's copy of the database |name| is further behind its primary than
+// the session's dolt_max_replica_lag_ms allows.
+func checkReplicaLag(ctx *sql.Context, name string, replicaLag ReplicaLagFunc) error {
+	if replicaLag == nil {
+		return nil
+	}
+	maxLagMs, err := ctx.GetSessionVariable(ctx, dsess.DoltMaxReplicaLagMs)
+	if err != nil {
+		return err
+	}
+	maxLag := time.Duration(maxLagMs.(int64)) * time.Millisecond
+	if maxLag == 0 {
+		return nil
+	}
+	lag, ok := replicaLag(name)
+	if ok && lag > maxLag {
+		return fmt.Errorf("database %s on this standby is %dms behind its primary, which exceeds %s of %dms", name, lag.Milliseconds(), dsess.DoltMaxReplicaLagMs, maxLag.Milliseconds())
+	}
+	return nil
+}
+
 // FileSystemForDatabase returns a filesystem, with the working directory set to the root directory
 // of the requested database. If the requested database isn't found, a database not found error
 // is returned.
@@ -1221,6 +1258,7 @@ func (p *DoltDatabaseProvider) SessionDatabase(ctx *sql.Context, name string) (d
 	p.mu.RLock()
 	db, ok := p.databases[strings.ToLower(baseName)]
 	standby := *p.isStandby
+	replicaLag := *p.replicaLag
 	p.mu.RUnlock()
 
 	// If the database doesn't exist and this is a read replica, attempt to clone it from the remote
@@ -1242,6 +1280,12 @@ func (p *DoltDatabaseProvider) SessionDatabase(ctx *sql.Context, name string) (d
 		return wrapForStandby(db, standby), true, nil
 	}
 
+	if standby {
+		if err := checkReplicaLag(ctx, db.Name(), replicaLag); err != nil {
+			return nil, false, err
+		}
+	}
+
 	// Convert to a revision database before returning. If we got a non-qualified name, convert it to a qualified name
 	// using the session's current head
 	revisionQualifiedName := name
@@ -1331,6 +1375,12 @@ func (p *DoltDatabaseProvider) TableFunction(_ *sql.Context, name string) (sql.T
 		return &ReflogTableFunction{}, nil
 	case "dolt_query_diff":
 		return &QueryDiffTableFunction{}, nil
+	case "dolt_preview_merge_conflicts_summary":
+		return &PreviewMergeConflictsSummaryTableFunction{}, nil
+	case "dolt_preview_merge_conflicts":
+		return &PreviewMergeConflictsTableFunction{}, nil
+	case "dolt_system_time":
+		return &SystemTimeTableFunction{}, nil
 	}
 
 	if fun, ok := p.tableFunctions[name]; ok {
@@ -26,6 +26,8 @@ var DoltFunctions = []sql.Function{
 	sql.Function2{Name: HasAncestorFuncName, Fn: NewHasAncestor},
 	sql.Function1{Name: HashOfTableFuncName, Fn: NewHashOfTable},
 	sql.FunctionN{Name: HashOfDatabaseFuncName, Fn: NewHashOfDatabase},
+	sql.FunctionN{Name: MaskFuncName, Fn: NewMask},
+	sql.Function1{Name: NextvalFuncName, Fn: NewNextval},
 }
 
 // DolthubApiFunctions are the DoltFunctions that get exposed to Dolthub Api.
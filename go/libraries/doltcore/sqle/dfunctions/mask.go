@@ -0,0 +1,131 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/masking"
+)
+
+const MaskFuncName = "dolt_mask"
+
+// Mask is the dolt_mask(value, transform[, argument]) function, which applies a dolt_masking transform to a value.
+type Mask struct {
+	children []sql.Expression
+}
+
+var _ sql.FunctionExpression = (*Mask)(nil)
+
+// NewMask creates a new Mask expression.
+func NewMask(args ...sql.Expression) (sql.Expression, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, sql.ErrInvalidArgumentNumber.New(MaskFuncName, "2 or 3", len(args))
+	}
+
+	return &Mask{children: args}, nil
+}
+
+// FunctionName implements the FunctionExpression interface.
+func (m *Mask) FunctionName() string {
+	return MaskFuncName
+}
+
+// Description implements the FunctionExpression interface.
+func (m *Mask) Description() string {
+	return "returns the value masked with the given dolt_masking transform and argument"
+}
+
+// Children implements the Expression interface.
+func (m *Mask) Children() []sql.Expression {
+	return m.children
+}
+
+// Eval implements the Expression interface.
+func (m *Mask) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	val, err := m.children[0].Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, len(m.children)-1)
+	for i, child := range m.children[1:] {
+		if !types.IsText(child.Type()) {
+			return nil, sql.ErrInvalidArgumentDetails.New(MaskFuncName, child.String())
+		}
+		v, err := child.Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		s, _, err := types.Text.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = s.(string)
+	}
+
+	var argument string
+	if len(args) > 1 {
+		argument = args[1]
+	}
+
+	rule, err := masking.NewRule("", m.children[0].String(), args[0], argument)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.Apply(val, m.Type())
+}
+
+// String implements the Stringer interface.
+func (m *Mask) String() string {
+	args := make([]string, len(m.children))
+	for i, child := range m.children {
+		args[i] = child.String()
+	}
+	return fmt.Sprintf("%s(%s)", MaskFuncName, strings.Join(args, ", "))
+}
+
+// IsNullable implements the Expression interface.
+func (m *Mask) IsNullable() bool {
+	return true
+}
+
+// Resolved implements the Expression interface.
+func (m *Mask) Resolved() bool {
+	for _, child := range m.children {
+		if !child.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// WithChildren implements the Expression interface.
+func (m *Mask) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return NewMask(children...)
+}
+
+// Type implements the Expression interface. A masked value has the same type as the value being masked.
+func (m *Mask) Type() sql.Type {
+	return m.children[0].Type()
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sequences"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+const NextvalFuncName = "nextval"
+
+// Nextval is the nextval(sequence) function, which reserves the next value of a sequence of the dolt_sequences table
+// on the current branch and returns it. The name of the sequence may be qualified with the name of its database.
+type Nextval struct {
+	expression.UnaryExpression
+}
+
+var _ sql.FunctionExpression = (*Nextval)(nil)
+var _ sql.NonDeterministicExpression = (*Nextval)(nil)
+
+// NewNextval creates a new Nextval expression.
+func NewNextval(e sql.Expression) sql.Expression {
+	return &Nextval{expression.UnaryExpression{Child: e}}
+}
+
+// Eval implements the Expression interface.
+func (n *Nextval) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	val, err := n.Child.Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		return nil, nil
+	}
+	val, _, err = types.Text.Convert(val)
+	if err != nil {
+		return nil, err
+	}
+
+	name := val.(string)
+	dbName := ctx.GetCurrentDatabase()
+	if db, seqName, ok := strings.Cut(name, "."); ok {
+		dbName, name = db, seqName
+	}
+	if dbName == "" {
+		return nil, sql.ErrNoDatabaseSelected.New()
+	}
+
+	return nextSequenceValue(ctx, dbName, name)
+}
+
+// nextSequenceValue reserves the next value of the sequence named |name| of the database named |dbName|. When it
+// reserves new values on the branch, the reservation is recorded in the session and written to the working set when
+// the transaction commits.
+func nextSequenceValue(ctx *sql.Context, dbName, name string) (int64, error) {
+	dSess := dsess.DSessFromSess(ctx.Session)
+	tracker, branch, err := dSess.SequenceTracker(ctx, dbName)
+	if err != nil {
+		return 0, err
+	}
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 0, sql.ErrDatabaseNotFound.New(dbName)
+	}
+
+	seq, ok, err := sequences.Get(ctx, roots.Working, name)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, sequences.ErrSequenceNotFound.New(strings.ToLower(name))
+	}
+
+	pos, reserved := tracker.Next(branch, seq)
+	v, err := seq.Value(pos)
+	if err != nil {
+		return 0, err
+	}
+
+	if reserved != seq.Reserved {
+		if err = dSess.ReserveSequenceValues(ctx, dbName, seq.Name, reserved); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+// String implements the Stringer interface.
+func (n *Nextval) String() string {
+	return fmt.Sprintf("%s(%s)", NextvalFuncName, n.Child.String())
+}
+
+// FunctionName implements the FunctionExpression interface
+func (n *Nextval) FunctionName() string {
+	return NextvalFuncName
+}
+
+// Description implements the FunctionExpression interface
+func (n *Nextval) Description() string {
+	return "reserves the next value of a sequence on the current branch and returns it"
+}
+
+// IsNonDeterministic implements the NonDeterministicExpression interface. Every call returns a new value.
+func (n *Nextval) IsNonDeterministic() bool {
+	return true
+}
+
+// IsNullable implements the Expression interface.
+func (n *Nextval) IsNullable() bool {
+	return n.Child.IsNullable()
+}
+
+// Type implements the Expression interface.
+func (n *Nextval) Type() sql.Type {
+	return types.Int64
+}
+
+// WithChildren implements the Expression interface.
+func (n *Nextval) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(n, len(children), 1)
+	}
+	return NewNextval(children[0]), nil
+}
@@ -259,12 +259,12 @@ func resolveCommitStrings(ctx *sql.Context, fromRef, toRef, dotRef interface{},
 				return "", "", err
 			}
 
-			rightCm, err := resolveCommit(ctx, db.DbData().Ddb, headRef, refs[0])
+			rightCm, err := resolveCommit(ctx, db.Name(), db.DbData().Ddb, headRef, refs[0])
 			if err != nil {
 				return "", "", err
 			}
 
-			leftCm, err := resolveCommit(ctx, db.DbData().Ddb, headRef, refs[1])
+			leftCm, err := resolveCommit(ctx, db.Name(), db.DbData().Ddb, headRef, refs[1])
 			if err != nil {
 				return "", "", err
 			}
@@ -327,7 +327,7 @@ func resolveRoot(ctx *sql.Context, sess *dsess.DoltSession, dbName, hashStr stri
 	return &refDetails{root, hashStr, commitTime}, nil
 }
 
-func resolveCommit(ctx *sql.Context, ddb *doltdb.DoltDB, headRef ref.DoltRef, cSpecStr string) (*doltdb.Commit, error) {
+func resolveCommit(ctx *sql.Context, dbName string, ddb *doltdb.DoltDB, headRef ref.DoltRef, cSpecStr string) (*doltdb.Commit, error) {
 	cs, err := doltdb.NewCommitSpec(cSpecStr)
 	if err != nil {
 		return nil, err
@@ -341,6 +341,9 @@ func resolveCommit(ctx *sql.Context, ddb *doltdb.DoltDB, headRef ref.DoltRef, cS
 	if !ok {
 		return nil, doltdb.ErrGhostCommitEncountered
 	}
+	if err = dsess.CheckReadAccessForCommitSpec(ctx, dbName, ddb, cs, cm); err != nil {
+		return nil, err
+	}
 
 	return cm, nil
 }
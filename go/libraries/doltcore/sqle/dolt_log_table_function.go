@@ -410,7 +410,7 @@ func (ltf *LogTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter
 		return commit.NumParents() >= ltf.minParents, nil
 	}
 
-	cHashToRefs, err := getCommitHashToRefs(ctx, sqledb.DbData().Ddb, ltf.decoration)
+	cHashToRefs, err := getCommitHashToRefs(ctx, sqledb.Name(), sqledb.DbData().Ddb, ltf.decoration)
 	if err != nil {
 		return nil, err
 	}
@@ -442,9 +442,12 @@ func (ltf *LogTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter
 			return nil, err
 		}
 		commit, ok = optCmt.ToCommit()
-		if err != nil {
+		if !ok {
 			return nil, doltdb.ErrGhostCommitEncountered
 		}
+		if err = dsess.CheckReadAccessForCommitSpec(ctx, dbName, sqledb.DbData().Ddb, cs, commit); err != nil {
+			return nil, err
+		}
 
 		commits = append(commits, commit)
 	}
@@ -464,6 +467,9 @@ func (ltf *LogTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter
 		if !ok {
 			return nil, doltdb.ErrGhostCommitEncountered
 		}
+		if err = dsess.CheckReadAccessForCommitSpec(ctx, dbName, sqledb.DbData().Ddb, cs, notCommit); err != nil {
+			return nil, err
+		}
 
 		notCommits = append(notCommits, notCommit)
 	}
@@ -539,7 +545,7 @@ func (ltf *LogTableFunction) evaluateArguments() (revisionValStrs []string, notR
 	return revisionValStrs, notRevisionValStrs, false, nil
 }
 
-func getCommitHashToRefs(ctx *sql.Context, ddb *doltdb.DoltDB, decoration string) (map[hash.Hash][]string, error) {
+func getCommitHashToRefs(ctx *sql.Context, dbName string, ddb *doltdb.DoltDB, decoration string) (map[hash.Hash][]string, error) {
 	cHashToRefs := map[hash.Hash][]string{}
 
 	// Get all branches
@@ -548,6 +554,12 @@ func getCommitHashToRefs(ctx *sql.Context, ddb *doltdb.DoltDB, decoration string
 		return nil, err
 	}
 	for _, b := range branches {
+		// Branches the current user may not read from aren't shown
+		if ok, err := dsess.CanReadBranch(ctx, dbName, b.Ref.GetPath()); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
 		refName := b.Ref.String()
 		if decoration != "full" {
 			refName = b.Ref.GetPath() // trim out "refs/heads/"
@@ -0,0 +1,372 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+const previewMergeConflictsSummaryDefaultRowCount = 10
+
+var _ sql.TableFunction = (*PreviewMergeConflictsSummaryTableFunction)(nil)
+var _ sql.ExecSourceRel = (*PreviewMergeConflictsSummaryTableFunction)(nil)
+
+// PreviewMergeConflictsSummaryTableFunction implements the dolt_preview_merge_conflicts_summary table function. It
+// performs an in-memory merge of a branch into a base branch and reports, for each table, the number of data
+// conflicts, schema conflicts and constraint violations that the merge would produce. Nothing is written to the
+// working set of either branch.
+type PreviewMergeConflictsSummaryTableFunction struct {
+	ctx *sql.Context
+
+	baseBranchExpr  sql.Expression
+	mergeBranchExpr sql.Expression
+	database        sql.Database
+}
+
+var previewMergeConflictsSummarySchema = sql.Schema{
+	&sql.Column{Name: "table", Type: types.LongText, Nullable: false},
+	&sql.Column{Name: "num_data_conflicts", Type: types.Uint64, Nullable: false},
+	&sql.Column{Name: "num_schema_conflicts", Type: types.Uint64, Nullable: false},
+	&sql.Column{Name: "num_constraint_violations", Type: types.Uint64, Nullable: false},
+}
+
+// NewInstance creates a new instance of TableFunction interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) NewInstance(ctx *sql.Context, db sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &PreviewMergeConflictsSummaryTableFunction{
+		ctx:      ctx,
+		database: db,
+	}
+
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func (pm *PreviewMergeConflictsSummaryTableFunction) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(pm.Schema())
+	numRows, _, err := pm.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (pm *PreviewMergeConflictsSummaryTableFunction) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return previewMergeConflictsSummaryDefaultRowCount, false, nil
+}
+
+// Database implements the sql.Databaser interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) Database() sql.Database {
+	return pm.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	npm := *pm
+	npm.database = database
+	return &npm, nil
+}
+
+// Name implements the sql.TableFunction interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) Name() string {
+	return "dolt_preview_merge_conflicts_summary"
+}
+
+// Resolved implements the sql.Resolvable interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) Resolved() bool {
+	return pm.baseBranchExpr.Resolved() && pm.mergeBranchExpr.Resolved()
+}
+
+func (pm *PreviewMergeConflictsSummaryTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// String implements the Stringer interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) String() string {
+	return fmt.Sprintf("DOLT_PREVIEW_MERGE_CONFLICTS_SUMMARY(%s, %s)", pm.baseBranchExpr.String(), pm.mergeBranchExpr.String())
+}
+
+// Schema implements the sql.Node interface.
+func (pm *PreviewMergeConflictsSummaryTableFunction) Schema() sql.Schema {
+	return previewMergeConflictsSummarySchema
+}
+
+// Children implements the sql.Node interface.
+func (pm *PreviewMergeConflictsSummaryTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (pm *PreviewMergeConflictsSummaryTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return pm, nil
+}
+
+// CheckPrivileges implements the interface sql.Node.
+func (pm *PreviewMergeConflictsSummaryTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	tblNames, err := pm.database.GetTableNames(ctx)
+	if err != nil {
+		return false
+	}
+
+	var operations []sql.PrivilegedOperation
+	for _, tblName := range tblNames {
+		subject := sql.PrivilegeCheckSubject{Database: pm.database.Name(), Table: tblName}
+		operations = append(operations, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+	}
+
+	return opChecker.UserHasPrivileges(ctx, operations...)
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (pm *PreviewMergeConflictsSummaryTableFunction) Expressions() []sql.Expression {
+	return []sql.Expression{pm.baseBranchExpr, pm.mergeBranchExpr}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (pm *PreviewMergeConflictsSummaryTableFunction) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 2 {
+		return nil, sql.ErrInvalidArgumentNumber.New(pm.Name(), 2, len(exprs))
+	}
+
+	if err := validateMergePreviewArguments(pm.Name(), exprs); err != nil {
+		return nil, err
+	}
+
+	npm := *pm
+	npm.baseBranchExpr = exprs[0]
+	npm.mergeBranchExpr = exprs[1]
+
+	return &npm, nil
+}
+
+// RowIter implements the sql.Node interface
+func (pm *PreviewMergeConflictsSummaryTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	baseBranch, mergeBranch, err := evaluateMergePreviewBranches(ctx, pm.Name(), pm.baseBranchExpr, pm.mergeBranchExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	sqledb, ok := pm.database.(dsess.SqlDatabase)
+	if !ok {
+		return nil, fmt.Errorf("unexpected database type: %T", pm.database)
+	}
+
+	preview, err := previewMerge(ctx, sqledb, baseBranch, mergeBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []previewMergeConflictsSummary
+	if preview.result != nil {
+		for tblName, stats := range preview.result.Stats {
+			if !stats.HasArtifacts() {
+				continue
+			}
+			summaries = append(summaries, previewMergeConflictsSummary{
+				tableName:            tblName,
+				dataConflicts:        uint64(stats.DataConflicts),
+				schemaConflicts:      uint64(stats.SchemaConflicts),
+				constraintViolations: uint64(stats.ConstraintViolations),
+			})
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].tableName < summaries[j].tableName
+	})
+
+	return &previewMergeConflictsSummaryRowIter{summaries: summaries}, nil
+}
+
+// validateMergePreviewArguments returns an error unless each of |exprs| is a literal text argument. The arguments of
+// dolt_preview_merge_conflicts determine its schema, which is needed before bind variables are bound, so neither merge
+// preview table function supports bind variables.
+func validateMergePreviewArguments(name string, exprs []sql.Expression) error {
+	for _, expr := range exprs {
+		if !expr.Resolved() || expression.IsBindVar(expr) {
+			return ErrInvalidNonLiteralArgument.New(name, expr.String())
+		}
+		// prepared statements resolve functions beforehand, so above check fails
+		if _, ok := expr.(sql.FunctionExpression); ok {
+			return ErrInvalidNonLiteralArgument.New(name, expr.String())
+		}
+		if !types.IsText(expr.Type()) {
+			return sql.ErrInvalidArgumentDetails.New(name, expr.String())
+		}
+	}
+	return nil
+}
+
+// evaluateMergePreviewBranches evaluates the base and merge branch arguments of the merge preview table functions.
+func evaluateMergePreviewBranches(ctx *sql.Context, name string, baseBranchExpr, mergeBranchExpr sql.Expression) (string, string, error) {
+	baseBranchVal, err := baseBranchExpr.Eval(ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	baseBranch, ok := baseBranchVal.(string)
+	if !ok {
+		return "", "", sql.ErrInvalidArgumentDetails.New(name, baseBranchExpr.String())
+	}
+
+	mergeBranchVal, err := mergeBranchExpr.Eval(ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	mergeBranch, ok := mergeBranchVal.(string)
+	if !ok {
+		return "", "", sql.ErrInvalidArgumentDetails.New(name, mergeBranchExpr.String())
+	}
+
+	return baseBranch, mergeBranch, nil
+}
+
+// mergePreview holds the outcome of merging one branch into another in memory.
+type mergePreview struct {
+	// ourRoot is the root value of the base branch, the side being merged into.
+	ourRoot doltdb.RootValue
+	// result is the merge result, or nil if the merge is a no-op or a fast-forward and so cannot conflict.
+	result *merge.Result
+}
+
+// previewMerge merges the head of |mergeBranch| into the head of |baseBranch| in memory. The resulting root value
+// is never persisted, so neither branch nor any working set is modified.
+func previewMerge(ctx *sql.Context, db dsess.SqlDatabase, baseBranch, mergeBranch string) (*mergePreview, error) {
+	sess := dsess.DSessFromSess(ctx.Session)
+	headRef, err := sess.CWBHeadRef(ctx, db.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	ddb := db.DbData().Ddb
+	ourCm, err := resolveCommit(ctx, db.Name(), ddb, headRef, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	theirCm, err := resolveCommit(ctx, db.Name(), ddb, headRef, mergeBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	ourRoot, err := ourCm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	optCmt, err := doltdb.GetCommitAncestor(ctx, ourCm, theirCm)
+	if err != nil {
+		return nil, err
+	}
+	ancCm, ok := optCmt.ToCommit()
+	if !ok {
+		return nil, doltdb.ErrGhostCommitEncountered
+	}
+
+	ancHash, err := ancCm.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	ourHash, err := ourCm.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	theirHash, err := theirCm.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	if ancHash == ourHash || ancHash == theirHash {
+		// up to date or fast-forward, neither of which can produce conflicts
+		return &mergePreview{ourRoot: ourRoot}, nil
+	}
+
+	theirRoot, err := theirCm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ancRoot, err := ancCm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts editor.Options
+	if eo, ok := db.(interface{ EditOptions() editor.Options }); ok {
+		opts = eo.EditOptions()
+	}
+
+	mo := merge.MergeOpts{
+		IsCherryPick:        false,
+		KeepSchemaConflicts: true,
+	}
+	result, err := merge.MergeRoots(ctx, ourRoot, theirRoot, ancRoot, theirCm, ancCm, opts, mo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mergePreview{ourRoot: ourRoot, result: result}, nil
+}
+
+//--------------------------------------------
+// previewMergeConflictsSummaryRowIter
+//--------------------------------------------
+
+type previewMergeConflictsSummary struct {
+	tableName            string
+	dataConflicts        uint64
+	schemaConflicts      uint64
+	constraintViolations uint64
+}
+
+var _ sql.RowIter = &previewMergeConflictsSummaryRowIter{}
+
+type previewMergeConflictsSummaryRowIter struct {
+	summaries []previewMergeConflictsSummary
+	idx       int
+}
+
+func (itr *previewMergeConflictsSummaryRowIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if itr.idx >= len(itr.summaries) {
+		return nil, io.EOF
+	}
+
+	s := itr.summaries[itr.idx]
+	itr.idx++
+	return sql.Row{
+		s.tableName,            // table
+		s.dataConflicts,        // num_data_conflicts
+		s.schemaConflicts,      // num_schema_conflicts
+		s.constraintViolations, // num_constraint_violations
+	}, nil
+}
+
+func (itr *previewMergeConflictsSummaryRowIter) Close(context *sql.Context) error {
+	return nil
+}
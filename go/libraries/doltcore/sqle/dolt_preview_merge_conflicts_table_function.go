@@ -0,0 +1,299 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+const previewMergeConflictsDefaultRowCount = 100
+
+var _ sql.TableFunction = (*PreviewMergeConflictsTableFunction)(nil)
+var _ sql.ExecSourceRel = (*PreviewMergeConflictsTableFunction)(nil)
+
+// PreviewMergeConflictsTableFunction implements the dolt_preview_merge_conflicts table function. It performs an
+// in-memory merge of a branch into a base branch and returns the data conflicts the merge would produce for a single
+// table, with the same base, ours and theirs columns as the dolt_conflicts_$tablename system table and an additional
+// conflict_type column. Nothing is written to the working set of either branch.
+type PreviewMergeConflictsTableFunction struct {
+	ctx *sql.Context
+
+	baseBranchExpr  sql.Expression
+	mergeBranchExpr sql.Expression
+	tableNameExpr   sql.Expression
+	database        sql.Database
+
+	sqlSch       sql.Schema
+	conflictsTbl sql.Table
+	// ourDiffIdx and theirDiffIdx are the positions of the our_diff_type and their_diff_type columns in rows
+	// returned by conflictsTbl
+	ourDiffIdx, theirDiffIdx int
+}
+
+// NewInstance creates a new instance of TableFunction interface
+func (pm *PreviewMergeConflictsTableFunction) NewInstance(ctx *sql.Context, db sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &PreviewMergeConflictsTableFunction{
+		ctx:      ctx,
+		database: db,
+	}
+
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func (pm *PreviewMergeConflictsTableFunction) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(pm.Schema())
+	numRows, _, err := pm.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (pm *PreviewMergeConflictsTableFunction) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return previewMergeConflictsDefaultRowCount, false, nil
+}
+
+// Database implements the sql.Databaser interface
+func (pm *PreviewMergeConflictsTableFunction) Database() sql.Database {
+	return pm.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (pm *PreviewMergeConflictsTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	npm := *pm
+	npm.database = database
+	return &npm, nil
+}
+
+// Name implements the sql.TableFunction interface
+func (pm *PreviewMergeConflictsTableFunction) Name() string {
+	return "dolt_preview_merge_conflicts"
+}
+
+// Resolved implements the sql.Resolvable interface
+func (pm *PreviewMergeConflictsTableFunction) Resolved() bool {
+	return pm.baseBranchExpr.Resolved() && pm.mergeBranchExpr.Resolved() && pm.tableNameExpr.Resolved()
+}
+
+func (pm *PreviewMergeConflictsTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// String implements the Stringer interface
+func (pm *PreviewMergeConflictsTableFunction) String() string {
+	return fmt.Sprintf("DOLT_PREVIEW_MERGE_CONFLICTS(%s, %s, %s)",
+		pm.baseBranchExpr.String(),
+		pm.mergeBranchExpr.String(),
+		pm.tableNameExpr.String())
+}
+
+// Schema implements the sql.Node interface.
+func (pm *PreviewMergeConflictsTableFunction) Schema() sql.Schema {
+	if !pm.Resolved() {
+		return nil
+	}
+
+	if pm.sqlSch == nil {
+		panic("schema hasn't been generated yet")
+	}
+
+	return pm.sqlSch
+}
+
+// Children implements the sql.Node interface.
+func (pm *PreviewMergeConflictsTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (pm *PreviewMergeConflictsTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return pm, nil
+}
+
+// CheckPrivileges implements the interface sql.Node.
+func (pm *PreviewMergeConflictsTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	tableNameVal, err := pm.tableNameExpr.Eval(pm.ctx, nil)
+	if err != nil {
+		return false
+	}
+	tableName, ok := tableNameVal.(string)
+	if !ok {
+		return false
+	}
+
+	subject := sql.PrivilegeCheckSubject{Database: pm.database.Name(), Table: tableName}
+	return opChecker.UserHasPrivileges(ctx, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (pm *PreviewMergeConflictsTableFunction) Expressions() []sql.Expression {
+	return []sql.Expression{pm.baseBranchExpr, pm.mergeBranchExpr, pm.tableNameExpr}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (pm *PreviewMergeConflictsTableFunction) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 3 {
+		return nil, sql.ErrInvalidArgumentNumber.New(pm.Name(), 3, len(exprs))
+	}
+
+	// TODO: Like the DiffTableFunction, we only support literal arguments because the schema of the result depends
+	//       on the table being merged and is needed by the analyzer before arguments could otherwise be resolved.
+	if err := validateMergePreviewArguments(pm.Name(), exprs); err != nil {
+		return nil, err
+	}
+
+	npm := *pm
+	npm.baseBranchExpr = exprs[0]
+	npm.mergeBranchExpr = exprs[1]
+	npm.tableNameExpr = exprs[2]
+
+	err := npm.generateSchema(npm.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &npm, nil
+}
+
+// generateSchema performs the merge preview and caches the conflicts table for the requested table, whose schema
+// determines the schema of this table function.
+func (pm *PreviewMergeConflictsTableFunction) generateSchema(ctx *sql.Context) error {
+	baseBranch, mergeBranch, err := evaluateMergePreviewBranches(ctx, pm.Name(), pm.baseBranchExpr, pm.mergeBranchExpr)
+	if err != nil {
+		return err
+	}
+
+	tableNameVal, err := pm.tableNameExpr.Eval(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tableName, ok := tableNameVal.(string)
+	if !ok {
+		return ErrInvalidTableName.New(pm.tableNameExpr.String())
+	}
+
+	sqledb, ok := pm.database.(dsess.SqlDatabase)
+	if !ok {
+		return fmt.Errorf("unexpected database type: %T", pm.database)
+	}
+
+	preview, err := previewMerge(ctx, sqledb, baseBranch, mergeBranch)
+	if err != nil {
+		return err
+	}
+
+	// Conflicts are read from the merged root. If the merge was a no-op, or the merge removed the table because of a
+	// schema conflict, the table from our root is used instead; it has no conflicts, but still determines the schema.
+	root := preview.ourRoot
+	if preview.result != nil {
+		root = preview.result.Root
+	}
+	tbl, resolvedName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		root = preview.ourRoot
+		tbl, resolvedName, ok, err = doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return sql.ErrTableNotFound.New(tableName)
+		}
+	}
+
+	ct, err := dtables.NewReadOnlyConflictsTable(ctx, resolvedName, tbl, root)
+	if err != nil {
+		return err
+	}
+
+	// TODO: As with the DiffTableFunction, we omit the source table name from the columns because there is no real
+	//       table backing them.
+	sch := make(sql.Schema, 0, len(ct.Schema())+1)
+	for _, col := range ct.Schema() {
+		c := *col
+		c.Source = ""
+		sch = append(sch, &c)
+	}
+	sch = append(sch, &sql.Column{Name: "conflict_type", Type: types.Text, Nullable: false})
+
+	pm.ourDiffIdx = sch.IndexOfColName("our_diff_type")
+	pm.theirDiffIdx = sch.IndexOfColName("their_diff_type")
+	pm.conflictsTbl = ct
+	pm.sqlSch = sch
+
+	return nil
+}
+
+// RowIter implements the sql.Node interface
+func (pm *PreviewMergeConflictsTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	partitions, err := pm.conflictsTbl.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &previewMergeConflictsRowIter{
+		iter:         sql.NewTableRowIter(ctx, pm.conflictsTbl, partitions),
+		ourDiffIdx:   pm.ourDiffIdx,
+		theirDiffIdx: pm.theirDiffIdx,
+	}, nil
+}
+
+//--------------------------------------------
+// previewMergeConflictsRowIter
+//--------------------------------------------
+
+var _ sql.RowIter = &previewMergeConflictsRowIter{}
+
+// previewMergeConflictsRowIter wraps a conflicts table row iterator and appends the conflict_type column, which
+// combines our and their diff types, e.g. "modified/removed".
+type previewMergeConflictsRowIter struct {
+	iter                     sql.RowIter
+	ourDiffIdx, theirDiffIdx int
+}
+
+func (itr *previewMergeConflictsRowIter) Next(ctx *sql.Context) (sql.Row, error) {
+	r, err := itr.iter.Next(ctx)
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	conflictType := fmt.Sprintf("%v/%v", r[itr.ourDiffIdx], r[itr.theirDiffIdx])
+	return append(r, conflictType), nil
+}
+
+func (itr *previewMergeConflictsRowIter) Close(ctx *sql.Context) error {
+	return itr.iter.Close(ctx)
+}
@@ -73,13 +73,13 @@ func doDoltBranch(ctx *sql.Context, args []string) (int, error) {
 
 	switch {
 	case apr.Contains(cli.CopyFlag):
-		err = copyBranch(ctx, dbData, apr, &rsc)
+		err = copyBranch(ctx, dbName, dbData, apr, &rsc)
 	case apr.Contains(cli.MoveFlag):
 		err = renameBranch(ctx, dbData, apr, dSess, dbName, &rsc)
 	case apr.Contains(cli.DeleteFlag), apr.Contains(cli.DeleteForceFlag):
 		err = deleteBranches(ctx, dbData, apr, dSess, dbName, &rsc)
 	default:
-		err = createNewBranch(ctx, dbData, apr, &rsc)
+		err = createNewBranch(ctx, dbName, dbData, apr, &rsc)
 	}
 
 	if err != nil {
@@ -103,7 +103,7 @@ func commitTransaction(ctx *sql.Context, dSess *dsess.DoltSession, rsc *doltdb.R
 	ctx.SetTransaction(newTx)
 
 	if rsc != nil {
-		dsess.WaitForReplicationController(ctx, *rsc)
+		return dsess.WaitForReplicationController(ctx, *rsc)
 	}
 
 	return nil
@@ -348,7 +348,7 @@ func loadConfig(ctx *sql.Context) *env.DoltCliConfig {
 	return dEnv.Config
 }
 
-func createNewBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseResults, rsc *doltdb.ReplicationStatusController) error {
+func createNewBranch(ctx *sql.Context, dbName string, dbData env.DbData, apr *argparser.ArgParseResults, rsc *doltdb.ReplicationStatusController) error {
 	if apr.NArg() == 0 || apr.NArg() > 2 {
 		return InvalidArgErr
 	}
@@ -405,6 +405,10 @@ func createNewBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgPars
 	if err != nil {
 		return err
 	}
+	err = checkReadAccessForCommitSpec(ctx, dbName, dbData, startPt)
+	if err != nil {
+		return err
+	}
 
 	err = actions.CreateBranchWithStartPt(ctx, dbData, branchName, startPt, apr.Contains(cli.ForceFlag), rsc)
 	if err != nil {
@@ -422,7 +426,7 @@ func createNewBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgPars
 	return nil
 }
 
-func copyBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseResults, rsc *doltdb.ReplicationStatusController) error {
+func copyBranch(ctx *sql.Context, dbName string, dbData env.DbData, apr *argparser.ArgParseResults, rsc *doltdb.ReplicationStatusController) error {
 	if apr.NArg() != 2 {
 		return InvalidArgErr
 	}
@@ -437,6 +441,10 @@ func copyBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseResu
 		return EmptyBranchNameErr
 	}
 
+	if err := checkReadAccessForCommitSpec(ctx, dbName, dbData, srcBr); err != nil {
+		return err
+	}
+
 	force := apr.Contains(cli.ForceFlag)
 	return copyABranch(ctx, dbData, srcBr, destBr, force, rsc)
 }
@@ -199,7 +199,9 @@ func doDoltCheckout(ctx *sql.Context, args []string) (statusCode int, successMes
 		successMessage = generateSuccessMessage(branchName, upstream)
 	}
 
-	dsess.WaitForReplicationController(ctx, rsc)
+	if err := dsess.WaitForReplicationController(ctx, rsc); err != nil {
+		return 1, "", err
+	}
 
 	return 0, successMessage, nil
 }
@@ -388,6 +390,10 @@ func checkoutNewBranch(ctx *sql.Context, dbName string, dbData env.DbData, apr *
 		newBranchName = optionBBranch
 	}
 
+	err = checkReadAccessForCommitSpec(ctx, dbName, dbData, startPt)
+	if err != nil {
+		return "", "", err
+	}
 	err = actions.CreateBranchWithStartPt(ctx, dbData, newBranchName, startPt, createBranchForcibly, rsc)
 	if err != nil {
 		return "", "", err
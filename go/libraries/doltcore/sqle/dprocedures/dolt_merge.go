@@ -478,7 +478,7 @@ func createMergeSpec(ctx *sql.Context, sess *dsess.DoltSession, dbName string, a
 	if apr.Contains(cli.NoCommitFlag) && apr.Contains(cli.CommitFlag) {
 		return nil, errors.New("cannot define both 'commit' and 'no-commit' flags at the same time")
 	}
-	return merge.NewMergeSpec(
+	spec, err := merge.NewMergeSpec(
 		ctx,
 		dbData.Rsr,
 		ddb,
@@ -493,6 +493,13 @@ func createMergeSpec(ctx *sql.Context, sess *dsess.DoltSession, dbName string, a
 		merge.WithNoCommit(apr.Contains(cli.NoCommitFlag)),
 		merge.WithNoEdit(apr.Contains(cli.NoEditFlag)),
 	)
+	if err != nil || spec == nil {
+		return spec, err
+	}
+	if err = checkReadAccessForCommit(ctx, dbName, ddb, commitSpecStr, spec.MergeC); err != nil {
+		return nil, err
+	}
+	return spec, nil
 }
 
 func getNameAndEmail(ctx *sql.Context, apr *argparser.ArgParseResults) (string, string, error) {
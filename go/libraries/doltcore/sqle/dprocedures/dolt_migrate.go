@@ -0,0 +1,256 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gms "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schmigrations"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// doltMigrateAdd is the stored procedure which adds a schema migration to the dolt_schemas table of the current
+// database.
+func doltMigrateAdd(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltMigrateAdd(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+func doDoltMigrateAdd(ctx *sql.Context, args []string) (int, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return 1, fmt.Errorf("Empty database name.")
+	}
+
+	apr, err := cli.CreateMigrateAddArgParser().Parse(args)
+	if err != nil {
+		return 1, err
+	}
+	if apr.NArg() < 2 {
+		return 1, fmt.Errorf("error: a schema migration requires a name and an up script")
+	}
+	m := schmigrations.Migration{
+		Name:      strings.TrimSpace(apr.Arg(0)),
+		Up:        apr.Arg(1),
+		CreatedAt: time.Now().Unix(),
+	}
+	if apr.NArg() > 2 {
+		m.Down = apr.Arg(2)
+	}
+	if len(m.Name) == 0 {
+		return 1, fmt.Errorf("error: schema migration name cannot be empty")
+	}
+	if len(strings.TrimSpace(m.Up)) == 0 {
+		return 1, fmt.Errorf("error: schema migration '%s' has an empty up script", m.Name)
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	if seq, ok := apr.GetInt(cli.SequenceParam); ok {
+		if seq <= 0 {
+			return 1, fmt.Errorf("error: schema migration sequence numbers must be positive")
+		}
+		m.Sequence = int64(seq)
+	} else {
+		roots, ok := dSess.GetRoots(ctx, dbName)
+		if !ok {
+			return 1, fmt.Errorf("Could not load database %s", dbName)
+		}
+		migrations, err := schmigrations.Load(ctx, roots.Working)
+		if err != nil {
+			return 1, err
+		}
+		m.Sequence = schmigrations.NextSequence(migrations)
+	}
+
+	db, err := dSess.Provider().Database(ctx, dbName)
+	if err != nil {
+		return 1, err
+	}
+	mdb, ok := db.(schmigrations.Database)
+	if !ok {
+		return 1, fmt.Errorf("database %s does not support schema migrations", dbName)
+	}
+	if err = mdb.AddSchemaMigration(ctx, m); err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// doltMigrateApply is the stored procedure which applies the pending schema migrations of the current database.
+func doltMigrateApply(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltMigrateApply(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+// doDoltMigrateApply runs the up scripts of the pending migrations in the order of their sequence numbers, up to the
+// sequence number given with --to. Either all of them are applied or, if a script fails, none of them are.
+func doDoltMigrateApply(ctx *sql.Context, args []string) (int, error) {
+	apr, err := cli.CreateMigrateArgParser("migrate_apply").Parse(args)
+	if err != nil {
+		return 0, err
+	}
+	to, hasTo := apr.GetInt(cli.ToParam)
+
+	return runMigrations(ctx, func(migrations []schmigrations.Migration) ([]schmigrations.Migration, error) {
+		var pending []schmigrations.Migration
+		for _, m := range migrations {
+			if !m.Applied && (!hasTo || m.Sequence <= int64(to)) {
+				pending = append(pending, m)
+			}
+		}
+		return pending, nil
+	}, true)
+}
+
+// doltMigrateRollback is the stored procedure which rolls back applied schema migrations of the current database.
+func doltMigrateRollback(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltMigrateRollback(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+// doDoltMigrateRollback runs the down scripts of the applied migrations with sequence numbers greater than the one
+// given with --to, in the reverse order of their sequence numbers. Without --to, only the last applied migration is
+// rolled back.
+func doDoltMigrateRollback(ctx *sql.Context, args []string) (int, error) {
+	apr, err := cli.CreateMigrateArgParser("migrate_rollback").Parse(args)
+	if err != nil {
+		return 0, err
+	}
+	to, hasTo := apr.GetInt(cli.ToParam)
+
+	return runMigrations(ctx, func(migrations []schmigrations.Migration) ([]schmigrations.Migration, error) {
+		var applied []schmigrations.Migration
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if !m.Applied || (hasTo && m.Sequence <= int64(to)) {
+				continue
+			}
+			if len(strings.TrimSpace(m.Down)) == 0 {
+				return nil, fmt.Errorf("error: schema migration '%s' cannot be rolled back, it has no down script", m.Name)
+			}
+			applied = append(applied, m)
+			if !hasTo {
+				break
+			}
+		}
+		return applied, nil
+	}, false)
+}
+
+// runMigrations runs the up or down scripts of the migrations chosen by |choose| from the migrations of the current
+// database, and marks them as applied or not applied. The scripts run in the transaction of the calling statement. If
+// any of them fails, the working set is restored to its state before the first one ran.
+func runMigrations(ctx *sql.Context, choose func([]schmigrations.Migration) ([]schmigrations.Migration, error), up bool) (int, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return 0, fmt.Errorf("Empty database name.")
+	}
+	dSess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 0, fmt.Errorf("Could not load database %s", dbName)
+	}
+
+	migrations, err := schmigrations.Load(ctx, roots.Working)
+	if err != nil {
+		return 0, err
+	}
+	chosen, err := choose(migrations)
+	if err != nil || len(chosen) == 0 {
+		return 0, err
+	}
+
+	// The statements of the scripts must not commit the transaction of the calling statement, so that a failure leaves
+	// nothing behind.
+	ignoreAutoCommit := ctx.GetIgnoreAutoCommit()
+	ctx.SetIgnoreAutoCommit(true)
+	defer ctx.SetIgnoreAutoCommit(ignoreAutoCommit)
+
+	// This engine doesn't check privileges or honor read-only mode, which is why the procedures which run migrations
+	// are AdminOnly and not ReadOnly.
+	engine := gms.NewDefault(dSess.Provider())
+	for _, m := range chosen {
+		script := m.Up
+		if !up {
+			script = m.Down
+		}
+		err = runScript(ctx, engine, script)
+		ctx.SetCurrentDatabase(dbName)
+		if err == nil {
+			m.Applied = up
+			err = setMigration(ctx, dSess, dbName, m)
+		}
+		if err != nil {
+			if rErr := dSess.SetWorkingRoot(ctx, dbName, roots.Working); rErr != nil {
+				return 0, rErr
+			}
+			return 0, fmt.Errorf("schema migration %d '%s' failed: %w", m.Sequence, m.Name, err)
+		}
+	}
+
+	return len(chosen), nil
+}
+
+// runScript runs each statement of |script| with |engine|.
+func runScript(ctx *sql.Context, engine *gms.Engine, script string) error {
+	for remainder := script; len(strings.TrimSpace(remainder)) > 0; {
+		parsed, query, rest, err := engine.Parser.Parse(ctx, remainder, true)
+		if errors.Is(err, sqlparser.ErrEmpty) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		remainder = rest
+
+		_, iter, err := engine.QueryWithBindings(ctx, query, parsed, nil)
+		if err != nil {
+			return err
+		}
+		if _, err = sql.RowIterToRows(ctx, iter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMigration records the state of |m| in the working set of |dbName|.
+func setMigration(ctx *sql.Context, dSess *dsess.DoltSession, dbName string, m schmigrations.Migration) error {
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return fmt.Errorf("Could not load database %s", dbName)
+	}
+	root, err := schmigrations.Update(ctx, roots.Working, m)
+	if err != nil {
+		return err
+	}
+	return dSess.SetWorkingRoot(ctx, dbName, root)
+}
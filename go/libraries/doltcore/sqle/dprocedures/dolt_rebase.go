@@ -201,6 +201,9 @@ func startRebase(ctx *sql.Context, upstreamPoint string) error {
 	if !ok {
 		return doltdb.ErrGhostCommitEncountered
 	}
+	if err = dsess.CheckReadAccessForCommitSpec(ctx, ctx.GetCurrentDatabase(), dbData.Ddb, commitSpec, upstreamCommit); err != nil {
+		return err
+	}
 
 	// rebaseWorkingBranch is the name of the temporary branch used when performing a rebase. In Git, a rebase
 	// happens with a detatched HEAD, but Dolt doesn't support that, we use a temporary branch.
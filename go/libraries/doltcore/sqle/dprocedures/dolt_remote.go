@@ -79,7 +79,9 @@ func doDoltRemote(ctx *sql.Context, args []string) (int, error) {
 		return 1, err
 	}
 
-	dsess.WaitForReplicationController(ctx, rsc)
+	if err := dsess.WaitForReplicationController(ctx, rsc); err != nil {
+		return 1, err
+	}
 
 	return 0, nil
 }
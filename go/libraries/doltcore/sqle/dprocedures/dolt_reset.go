@@ -88,6 +88,10 @@ func doDoltReset(ctx *sql.Context, args []string) (int, error) {
 			arg = apr.Arg(0)
 		}
 
+		if err = checkReadAccessForCommitSpec(ctx, dbName, dbData, arg); err != nil {
+			return 1, err
+		}
+
 		var newHead *doltdb.Commit
 		newHead, roots, err = actions.ResetHardTables(ctx, dbData, arg, roots)
 		if err != nil {
@@ -124,6 +128,9 @@ func doDoltReset(ctx *sql.Context, args []string) (int, error) {
 		}
 
 		if arg != "" {
+			if err = checkReadAccessForCommitSpec(ctx, dbName, dbData, arg); err != nil {
+				return 1, err
+			}
 			roots, err = actions.ResetSoftToRef(ctx, dbData, arg)
 			if err != nil {
 				return 1, err
@@ -162,6 +169,9 @@ func doDoltReset(ctx *sql.Context, args []string) (int, error) {
 					return 1, err
 				}
 			} else {
+				if err = checkReadAccessForCommitSpec(ctx, dbName, dbData, apr.Arg(0)); err != nil {
+					return 1, err
+				}
 				roots, err = actions.ResetSoftToRef(ctx, dbData, apr.Arg(0))
 				if err != nil {
 					return 1, err
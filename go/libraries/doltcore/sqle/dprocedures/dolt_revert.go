@@ -106,6 +106,9 @@ func doDoltRevert(ctx *sql.Context, args []string) (int, error) {
 		if !ok {
 			return 1, doltdb.ErrGhostCommitEncountered
 		}
+		if err = dsess.CheckReadAccessForCommitSpec(ctx, dbName, ddb, commitSpec, commit); err != nil {
+			return 1, err
+		}
 
 		commits[i] = commit
 	}
@@ -0,0 +1,166 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sequences"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+// doltCreateSequence is the stored procedure which adds a sequence to the dolt_sequences table of the current
+// database.
+func doltCreateSequence(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltCreateSequence(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+func doDoltCreateSequence(ctx *sql.Context, args []string) (int, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return 1, fmt.Errorf("Empty database name.")
+	}
+
+	apr, err := cli.CreateSequenceArgParser().Parse(args)
+	if err != nil {
+		return 1, err
+	}
+	if apr.NArg() != 1 {
+		return 1, fmt.Errorf("error: a sequence requires a name")
+	}
+
+	increment, _, err := sequenceParam(apr, cli.IncrementParam, 1)
+	if err != nil {
+		return 1, err
+	}
+	seq := sequences.New(strings.TrimSpace(apr.Arg(0)), increment)
+	if seq.MinValue, _, err = sequenceParam(apr, cli.MinValueParam, seq.MinValue); err != nil {
+		return 1, err
+	}
+	if seq.MaxValue, _, err = sequenceParam(apr, cli.MaxValueParam, seq.MaxValue); err != nil {
+		return 1, err
+	}
+	var hasStart bool
+	if seq.Start, hasStart, err = sequenceParam(apr, cli.StartParam, 0); err != nil {
+		return 1, err
+	} else if !hasStart {
+		seq.Start = seq.MinValue
+		if seq.Increment < 0 {
+			seq.Start = seq.MaxValue
+		}
+	}
+	if seq.BranchRange, _, err = sequenceParam(apr, cli.BranchRangeParam, 0); err != nil {
+		return 1, err
+	}
+	if err = seq.Validate(); err != nil {
+		return 1, err
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 1, fmt.Errorf("Could not load database %s", dbName)
+	}
+	if _, exists, err := sequences.Get(ctx, roots.Working, seq.Name); err != nil {
+		return 1, err
+	} else if exists {
+		if apr.Contains(cli.IfNotExistsFlag) {
+			return 0, nil
+		}
+		return 1, sequences.ErrSequenceExists.New(seq.Name)
+	}
+
+	root, err := sequences.Put(ctx, roots.Working, seq)
+	if err != nil {
+		return 1, err
+	}
+	return 0, setSequenceRoot(ctx, dSess, dbName, seq.Name, root)
+}
+
+// doltDropSequence is the stored procedure which removes a sequence from the dolt_sequences table of the current
+// database.
+func doltDropSequence(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltDropSequence(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+func doDoltDropSequence(ctx *sql.Context, args []string) (int, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return 1, fmt.Errorf("Empty database name.")
+	}
+
+	apr, err := cli.CreateDropSequenceArgParser().Parse(args)
+	if err != nil {
+		return 1, err
+	}
+	if apr.NArg() != 1 {
+		return 1, fmt.Errorf("error: the name of the sequence to drop is required")
+	}
+	name := strings.ToLower(strings.TrimSpace(apr.Arg(0)))
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 1, fmt.Errorf("Could not load database %s", dbName)
+	}
+	root, existed, err := sequences.Delete(ctx, roots.Working, name)
+	if err != nil {
+		return 1, err
+	}
+	if !existed {
+		if apr.Contains(cli.IfExistsFlag) {
+			return 0, nil
+		}
+		return 1, sequences.ErrSequenceNotFound.New(name)
+	}
+	return 0, setSequenceRoot(ctx, dSess, dbName, name, root)
+}
+
+// setSequenceRoot sets the working root of |dbName| after the sequence named |name| was created or dropped, and
+// forgets the values the session's branch reserved from any earlier sequence with the same name.
+func setSequenceRoot(ctx *sql.Context, dSess *dsess.DoltSession, dbName, name string, root doltdb.RootValue) error {
+	if err := dSess.SetWorkingRoot(ctx, dbName, root); err != nil {
+		return err
+	}
+	return dSess.ResetSequence(ctx, dbName, name)
+}
+
+// sequenceParam returns the value of the integer option |name| of |apr|, or |def| if it was not given.
+func sequenceParam(apr *argparser.ArgParseResults, name string, def int64) (int64, bool, error) {
+	s, ok := apr.GetValue(name)
+	if !ok {
+		return def, false, nil
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("error: invalid value '%s' for --%s, it must be an integer", s, name)
+	}
+	return v, true, nil
+}
@@ -46,11 +46,13 @@ func doDoltConstraintsVerify(ctx *sql.Context, args []string) (int, error) {
 
 	dbName := ctx.GetCurrentDatabase()
 	dSess := dsess.DSessFromSess(ctx.Session)
-	workingSet, err := dSess.WorkingSet(ctx, dbName)
-	if err != nil {
-		return 1, err
+	// Read only revision databases, such as those for a commit hash, have no working set, but can still be verified
+	// with --output-only
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 1, sql.ErrDatabaseNotFound.New(dbName)
 	}
-	workingRoot := workingSet.WorkingRoot()
+	workingRoot := roots.Working
 	headCommit, err := dSess.GetHeadCommit(ctx, dbName)
 	if err != nil {
 		return 1, err
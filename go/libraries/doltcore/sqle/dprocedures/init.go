@@ -40,6 +40,11 @@ var DoltProcedures = []sql.ExternalStoredProcedureDetails{
 	{Name: "dolt_gc", Schema: int64Schema("status"), Function: doltGC, ReadOnly: true, AdminOnly: true},
 
 	{Name: "dolt_merge", Schema: doltMergeSchema, Function: doltMerge},
+	{Name: "dolt_migrate_add", Schema: int64Schema("status"), Function: doltMigrateAdd},
+	{Name: "dolt_migrate_apply", Schema: int64Schema("applied"), Function: doltMigrateApply, AdminOnly: true},
+	{Name: "dolt_migrate_rollback", Schema: int64Schema("rolled_back"), Function: doltMigrateRollback, AdminOnly: true},
+	{Name: "dolt_create_sequence", Schema: int64Schema("status"), Function: doltCreateSequence},
+	{Name: "dolt_drop_sequence", Schema: int64Schema("status"), Function: doltDropSequence},
 	{Name: "dolt_pull", Schema: doltPullSchema, Function: doltPull, AdminOnly: true},
 	{Name: "dolt_push", Schema: doltPushSchema, Function: doltPush, AdminOnly: true},
 	{Name: "dolt_remote", Schema: int64Schema("status"), Function: doltRemote, AdminOnly: true},
@@ -0,0 +1,56 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// checkReadAccessForCommit checks whether the current user may read the commit |cm| that |commitSpecStr| resolved to.
+// Procedures which copy the data of a commit into a branch, such as merge or cherry-pick, must check this so that a
+// user who may write to one branch can't use them to read another.
+func checkReadAccessForCommit(ctx *sql.Context, dbName string, ddb *doltdb.DoltDB, commitSpecStr string, cm *doltdb.Commit) error {
+	cs, err := doltdb.NewCommitSpec(commitSpecStr)
+	if err != nil {
+		return err
+	}
+	return dsess.CheckReadAccessForCommitSpec(ctx, dbName, ddb, cs, cm)
+}
+
+// checkReadAccessForCommitSpec resolves |commitSpecStr| and checks whether the current user may read the commit it
+// names. A commit spec that can't be resolved is left for the caller to report.
+func checkReadAccessForCommitSpec(ctx *sql.Context, dbName string, dbData env.DbData, commitSpecStr string) error {
+	cs, err := doltdb.NewCommitSpec(commitSpecStr)
+	if err != nil {
+		return nil
+	}
+	headRef, err := dbData.Rsr.CWBHeadRef()
+	if err != nil {
+		headRef = nil
+	}
+	optCmt, err := dbData.Ddb.Resolve(ctx, cs, headRef)
+	if err != nil {
+		return nil
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return nil
+	}
+	return dsess.CheckReadAccessForCommitSpec(ctx, dbName, dbData.Ddb, cs, cm)
+}
@@ -18,6 +18,9 @@ import (
 	"context"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/hash"
 )
 
 // CheckAccessForDb checks whether the current user has the given permissions for the given database.
@@ -57,3 +60,195 @@ func CheckAccessForDb(ctx context.Context, db SqlDatabase, flags branch_control.
 	}
 	return branch_control.ErrIncorrectPermissions.New(user, host, branch)
 }
+
+// checkReadAccess checks whether the current user may read from the branch or commit of the given branch state. States
+// of tags and other revisions may always be read.
+func checkReadAccess(ctx context.Context, baseName string, state *branchState) error {
+	if state == nil {
+		return nil
+	}
+	switch state.revisionType {
+	case RevisionTypeBranch:
+		return branch_control.CanReadBranch(ctx, baseName, state.head)
+	case RevisionTypeCommit:
+		if state.headCommit == nil {
+			return nil
+		}
+		return CheckReadAccessForCommit(ctx, baseName, state.dbData.Ddb, state.headCommit)
+	default:
+		return nil
+	}
+}
+
+// CheckReadAccessForCommitSpec checks whether the current user may read the commit |cm| that the given commit spec
+// resolved to, such as the commit spec of an AS OF clause or of the arguments of a table function. A commit spec that
+// names an existing branch, or one of its ancestors, may be read when the branch may be read. Any other commit, such as
+// one given by its hash or as an ancestor of HEAD, is checked with CheckReadAccessForCommit.
+func CheckReadAccessForCommitSpec(ctx context.Context, dbName string, ddb *doltdb.DoltDB, cs *doltdb.CommitSpec, cm *doltdb.Commit) error {
+	if branch_control.GetBranchAwareSession(ctx) == nil {
+		return nil
+	}
+	if branch, ok := cs.BranchName(); ok {
+		branch, ok, err := ddb.HasBranch(ctx, branch)
+		if err != nil {
+			return err
+		} else if ok {
+			baseName, _ := SplitRevisionDbName(dbName)
+			return branch_control.CanReadBranch(ctx, baseName, branch)
+		}
+	}
+	return CheckReadAccessForCommit(ctx, dbName, ddb, cm)
+}
+
+// CheckReadAccessForCommit checks whether the current user may read the given commit. Once any branch of the database
+// may not be read by the user, the commit must be reachable from a branch they may read, a tag, or a remote branch.
+func CheckReadAccessForCommit(ctx context.Context, dbName string, ddb *doltdb.DoltDB, cm *doltdb.Commit) error {
+	branchAwareSession := branch_control.GetBranchAwareSession(ctx)
+	if branchAwareSession == nil {
+		return nil
+	}
+	heads, restricted, err := readableHeads(ctx, dbName, ddb)
+	if err != nil || !restricted {
+		return err
+	}
+	ok, err := isReachable(ctx, ddb, heads, cm)
+	if err != nil || ok {
+		return err
+	}
+	h, err := cm.HashOf()
+	if err != nil {
+		return err
+	}
+	return branch_control.ErrCannotReadCommit.New(branchAwareSession.GetUser(), branchAwareSession.GetHost(), h.String())
+}
+
+// CanReadBranch returns whether the current user may read from the given branch of the database.
+func CanReadBranch(ctx context.Context, dbName string, branch string) (bool, error) {
+	baseName, _ := SplitRevisionDbName(dbName)
+	err := branch_control.CanReadBranch(ctx, baseName, branch)
+	if branch_control.ErrCannotReadBranch.Is(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ReadableBranches returns the branches given that the current user may read from.
+func ReadableBranches(ctx context.Context, dbName string, branches []ref.DoltRef) ([]ref.DoltRef, error) {
+	readable := make([]ref.DoltRef, 0, len(branches))
+	for _, branch := range branches {
+		if ok, err := CanReadBranch(ctx, dbName, branch.GetPath()); err != nil {
+			return nil, err
+		} else if ok {
+			readable = append(readable, branch)
+		}
+	}
+	return readable, nil
+}
+
+// CommitItrForReadableBranches returns a CommitItr over the commits of the branches of the database that the current
+// user may read from.
+func CommitItrForReadableBranches(ctx context.Context, dbName string, ddb *doltdb.DoltDB) (doltdb.CommitItr, error) {
+	branches, err := ddb.GetBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	branches, err = ReadableBranches(ctx, dbName, branches)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]*doltdb.Commit, len(branches))
+	for i, branch := range branches {
+		if commits[i], err = ddb.ResolveCommitRef(ctx, branch); err != nil {
+			return nil, err
+		}
+	}
+	return doltdb.CommitItrForRoots(ddb, commits...), nil
+}
+
+// readableHeads returns the commits of the branches of the database that the current user may read from, along with
+// the commits of its tags and remote branches, and whether any branch may not be read by the user. The commits are
+// only returned when a branch may not be read.
+func readableHeads(ctx context.Context, dbName string, ddb *doltdb.DoltDB) ([]hash.Hash, bool, error) {
+	branches, err := ddb.GetBranchesWithHashes(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var heads []hash.Hash
+	restricted := false
+	for _, branch := range branches {
+		if ok, err := CanReadBranch(ctx, dbName, branch.Ref.GetPath()); err != nil {
+			return nil, false, err
+		} else if ok {
+			heads = append(heads, branch.Hash)
+		} else {
+			restricted = true
+		}
+	}
+	if !restricted {
+		return nil, false, nil
+	}
+
+	tags, err := ddb.GetTagsWithHashes(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, tag := range tags {
+		heads = append(heads, tag.Hash)
+	}
+	remotes, err := ddb.GetRemotesWithHashes(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, remote := range remotes {
+		heads = append(heads, remote.Hash)
+	}
+	return heads, true, nil
+}
+
+// isReachable returns whether |cm| is one of the commits |heads| or one of their ancestors. A commit can only be an
+// ancestor of commits that are higher than it, so the walk doesn't continue below the height of |cm|.
+func isReachable(ctx context.Context, ddb *doltdb.DoltDB, heads []hash.Hash, cm *doltdb.Commit) (bool, error) {
+	target, err := cm.HashOf()
+	if err != nil {
+		return false, err
+	}
+	height, err := cm.Height()
+	if err != nil {
+		return false, err
+	}
+
+	pending := append([]hash.Hash(nil), heads...)
+	visited := hash.NewHashSet()
+	for len(pending) > 0 {
+		h := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if h == target {
+			return true, nil
+		}
+		if visited.Has(h) {
+			continue
+		}
+		visited.Insert(h)
+
+		optCmt, err := ddb.ReadCommit(ctx, h)
+		if err != nil {
+			return false, err
+		}
+		c, ok := optCmt.ToCommit()
+		if !ok {
+			// The ancestors of a ghost commit of a shallow clone aren't available to walk
+			continue
+		}
+		if ch, err := c.Height(); err != nil {
+			return false, err
+		} else if ch <= height {
+			continue
+		}
+		parents, err := c.ParentHashes(ctx)
+		if err != nil {
+			return false, err
+		}
+		pending = append(pending, parents...)
+	}
+	return false, nil
+}
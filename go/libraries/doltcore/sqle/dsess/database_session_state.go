@@ -124,6 +124,9 @@ type branchState struct {
 	readOnly bool
 	// dirty is true if this branch state has uncommitted changes
 	dirty bool
+	// sequenceReservations are the numbers of values of each sequence reserved by this transaction, which are written
+	// to the working set when it commits
+	sequenceReservations map[string]int64
 }
 
 // NewEmptyBranchState creates a new branch state for the given head name with the head provided, adds it to the db
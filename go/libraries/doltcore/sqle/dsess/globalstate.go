@@ -76,15 +76,22 @@ func NewGlobalStateStoreForDb(ctx context.Context, dbName string, db *doltdb.Dol
 		return GlobalStateImpl{}, err
 	}
 
+	seqTracker, err := NewSequenceTracker(ctx, roots...)
+	if err != nil {
+		return GlobalStateImpl{}, err
+	}
+
 	return GlobalStateImpl{
-		aiTracker: tracker,
-		mu:        &sync.Mutex{},
+		aiTracker:  tracker,
+		seqTracker: seqTracker,
+		mu:         &sync.Mutex{},
 	}, nil
 }
 
 type GlobalStateImpl struct {
-	aiTracker globalstate.AutoIncrementTracker
-	mu        *sync.Mutex
+	aiTracker  globalstate.AutoIncrementTracker
+	seqTracker globalstate.SequenceTracker
+	mu         *sync.Mutex
 }
 
 var _ globalstate.GlobalState = GlobalStateImpl{}
@@ -92,3 +99,7 @@ var _ globalstate.GlobalState = GlobalStateImpl{}
 func (g GlobalStateImpl) AutoIncrementTracker(ctx *sql.Context) (globalstate.AutoIncrementTracker, error) {
 	return g.aiTracker, nil
 }
+
+func (g GlobalStateImpl) SequenceTracker(ctx *sql.Context) (globalstate.SequenceTracker, error) {
+	return g.seqTracker, nil
+}
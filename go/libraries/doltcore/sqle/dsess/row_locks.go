@@ -0,0 +1,478 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	sqltypes "github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/mysql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/pool"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// erLockNowait is ER_LOCK_NOWAIT, which isn't defined by vitess.
+const erLockNowait = 3572
+
+// ErrLockWaitTimeout is returned when a locking read waits longer than @@innodb_lock_wait_timeout for a row lock.
+var ErrLockWaitTimeout = mysql.NewSQLError(mysql.ERLockWaitTimeout, mysql.SSUnknownSQLState, "Lock wait timeout exceeded; try restarting transaction")
+
+// ErrLockNowait is returned when a NOWAIT locking read finds a row locked by another transaction.
+var ErrLockNowait = mysql.NewSQLError(erLockNowait, mysql.SSUnknownSQLState, "Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set.")
+
+// ErrRowLockDeadlock is returned, wrapped in sql.ErrLockDeadlock, when waiting for a row lock would deadlock.
+var ErrRowLockDeadlock = errors.New("deadlock found when trying to get a row lock")
+
+// ErrLockedRowChanged is returned, wrapped in sql.ErrLockDeadlock, when a locking read locks a row which a concurrent
+// transaction changed and committed after this transaction started. Dolt transactions read a snapshot, so the
+// transaction can't see the row it locked and must be restarted.
+var ErrLockedRowChanged = errors.New("a row locked by this transaction was changed by a concurrently committed transaction")
+
+var pkTuplePool = pool.NewBuffPool()
+
+// RowLockWait is how a locking read behaves when a row it reads is locked by another transaction.
+type RowLockWait int
+
+const (
+	// RowLockWaitTimeout waits for the lock for up to @@innodb_lock_wait_timeout seconds.
+	RowLockWaitTimeout RowLockWait = iota
+	// RowLockNoWait fails the statement immediately.
+	RowLockNoWait
+	// RowLockSkipLocked skips the row.
+	RowLockSkipLocked
+)
+
+// LockingRead describes the row locks taken by a SELECT ... FOR UPDATE or FOR SHARE statement.
+type LockingRead struct {
+	// Exclusive is true for FOR UPDATE, and false for FOR SHARE.
+	Exclusive bool
+	Wait      RowLockWait
+}
+
+// rowLocks holds the row locks of every session. Like commits, which are serialized by |txLock|, row locks are shared
+// by every database of the process.
+var rowLocks = newRowLockManager()
+
+// releaseRowLocks releases all the row locks held by the session with the id given, when its transaction ends or the
+// session is closed.
+func releaseRowLocks(sessionID uint32) {
+	rowLocks.releaseAll(sessionID)
+}
+
+// rowLockKey identifies a row of a table on a branch by its primary key.
+type rowLockKey struct {
+	ddb        *doltdb.DoltDB
+	workingSet string
+	tableName  string
+	key        string
+}
+
+type rowLock struct {
+	// the sessions holding the lock, and how each holds it
+	holders map[uint32]rowLockHold
+	// closed and replaced whenever a holder releases the lock, to wake waiters
+	released chan struct{}
+}
+
+// rowLockHold is how a session holds a row lock.
+type rowLockHold struct {
+	exclusive bool
+	// whether the lock was taken by a locking read, rather than only by writes of the row
+	read bool
+}
+
+// rowLockRequest is a request for a row lock, by a locking read or by a write of the row.
+type rowLockRequest struct {
+	key       rowLockKey
+	exclusive bool
+	write     bool
+}
+
+// rowLockManager grants shared and exclusive row locks to sessions. Waits for locks are tracked so that a wait which
+// would complete a cycle of sessions waiting on each other is refused as a deadlock.
+//
+// Writes of a row take its lock exclusively, so they wait for the locking reads of the row and locking reads wait for
+// them. Writes don't wait for each other: concurrent writes of a row are merged, or reported as conflicts, when the
+// transactions commit, as they are without row locks.
+type rowLockManager struct {
+	mu         sync.Mutex
+	locks      map[rowLockKey]*rowLock
+	held       map[uint32]map[rowLockKey]struct{}
+	waitingFor map[uint32]rowLockRequest
+}
+
+func newRowLockManager() *rowLockManager {
+	return &rowLockManager{
+		locks:      make(map[rowLockKey]*rowLock),
+		held:       make(map[uint32]map[rowLockKey]struct{}),
+		waitingFor: make(map[uint32]rowLockRequest),
+	}
+}
+
+// acquire takes the lock on |key| for |owner|, for a write of the row if |write| is true and for a locking read
+// otherwise. It returns false without an error if the row is locked by another session and |wait| is
+// RowLockSkipLocked.
+func (m *rowLockManager) acquire(ctx context.Context, owner uint32, key rowLockKey, exclusive, write bool, wait RowLockWait, timeout time.Duration) (bool, error) {
+	req := rowLockRequest{key: key, exclusive: exclusive || write, write: write}
+	var deadline <-chan time.Time
+	for {
+		m.mu.Lock()
+		l, ok := m.locks[key]
+		if !ok {
+			l = &rowLock{holders: make(map[uint32]rowLockHold), released: make(chan struct{})}
+			m.locks[key] = l
+		}
+		if l.grantable(owner, req) {
+			hold := l.holders[owner]
+			l.holders[owner] = rowLockHold{exclusive: hold.exclusive || req.exclusive, read: hold.read || !write}
+			if m.held[owner] == nil {
+				m.held[owner] = make(map[rowLockKey]struct{})
+			}
+			m.held[owner][key] = struct{}{}
+			m.mu.Unlock()
+			return true, nil
+		}
+
+		switch wait {
+		case RowLockSkipLocked:
+			m.mu.Unlock()
+			return false, nil
+		case RowLockNoWait:
+			m.mu.Unlock()
+			return false, ErrLockNowait
+		}
+		if m.waitWouldDeadlock(owner, l, req) {
+			m.mu.Unlock()
+			return false, sql.ErrLockDeadlock.New(ErrRowLockDeadlock.Error())
+		}
+		m.waitingFor[owner] = req
+		released := l.released
+		m.mu.Unlock()
+
+		if deadline == nil {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		var err error
+		select {
+		case <-released:
+		case <-deadline:
+			err = ErrLockWaitTimeout
+		case <-ctx.Done():
+			err = context.Cause(ctx)
+		}
+
+		m.mu.Lock()
+		delete(m.waitingFor, owner)
+		m.mu.Unlock()
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// grantable returns whether |owner| can take the lock as |req| requests, given its current holders.
+func (l *rowLock) grantable(owner uint32, req rowLockRequest) bool {
+	for holder := range l.holders {
+		if l.blocks(holder, owner, req) {
+			return false
+		}
+	}
+	return true
+}
+
+// blocks returns whether |holder| holding |l| keeps |owner| from taking it as |req| requests.
+func (l *rowLock) blocks(holder, owner uint32, req rowLockRequest) bool {
+	hold, ok := l.holders[holder]
+	if !ok || holder == owner {
+		return false
+	}
+	if req.write && !hold.read {
+		return false
+	}
+	return req.exclusive || hold.exclusive
+}
+
+// waitWouldDeadlock returns whether |owner| waiting on |l| as |req| requests would complete a cycle of waits.
+//
+// called with m.mu held
+func (m *rowLockManager) waitWouldDeadlock(owner uint32, l *rowLock, req rowLockRequest) bool {
+	visited := make(map[uint32]bool)
+	// waitsOnOwner returns whether |session| is, transitively, waiting on a lock held by |owner|
+	var waitsOnOwner func(session uint32) bool
+	waitsOnOwner = func(session uint32) bool {
+		if session == owner {
+			return true
+		}
+		if visited[session] {
+			return false
+		}
+		visited[session] = true
+		waiting, ok := m.waitingFor[session]
+		if !ok {
+			return false
+		}
+		next, ok := m.locks[waiting.key]
+		if !ok {
+			return false
+		}
+		for holder := range next.holders {
+			if next.blocks(holder, session, waiting) && waitsOnOwner(holder) {
+				return true
+			}
+		}
+		return false
+	}
+	for holder := range l.holders {
+		if l.blocks(holder, owner, req) && waitsOnOwner(holder) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseAll releases every lock held by |owner| and wakes the sessions waiting on them.
+func (m *rowLockManager) releaseAll(owner uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.held[owner] {
+		l := m.locks[key]
+		delete(l.holders, owner)
+		close(l.released)
+		l.released = make(chan struct{})
+		if len(l.holders) == 0 {
+			delete(m.locks, key)
+		}
+	}
+	delete(m.held, owner)
+}
+
+// RowLocker takes the row locks of a locking read of one table.
+type RowLocker struct {
+	owner   uint32
+	read    LockingRead
+	timeout time.Duration
+
+	ddb        *doltdb.DoltDB
+	wsRef      ref.WorkingSetRef
+	tableName  string
+	startRows  prolly.Map
+	keyBuilder *val.TupleBuilder
+
+	// whether the locks are taken for writes, which don't check for concurrent changes to the rows
+	write bool
+
+	// the latest committed rows of the table, and the root they were read at
+	latestRoot hash.Hash
+	latestRows *prolly.Map
+}
+
+// NewRowLocker returns a RowLocker for a locking read of the table named in the database named, or nil if the read
+// doesn't need to lock rows, as for reads of read-only revisions or of tables created by the current transaction.
+func NewRowLocker(ctx *sql.Context, dbName, tableName string, read LockingRead) (*RowLocker, error) {
+	tx, ok := ctx.GetTransaction().(*DoltTransaction)
+	if !ok {
+		return nil, nil
+	}
+	sess := DSessFromSess(ctx.Session)
+	branchState, ok, err := sess.lookupDbState(ctx, dbName)
+	if err != nil || !ok {
+		return nil, err
+	}
+	ws := branchState.WorkingSet()
+	if ws == nil {
+		return nil, nil
+	}
+	startPoint, ok := tx.dbStartPoints[strings.ToLower(branchState.dbState.dbName)]
+	if !ok {
+		return nil, nil
+	}
+
+	startWs, err := startPoint.db.ResolveWorkingSetAtRoot(ctx, ws.Ref(), startPoint.rootHash)
+	if err == doltdb.ErrWorkingSetNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	startRows, ok, err := primaryRows(ctx, startWs.WorkingRoot(), tableName)
+	if err != nil || !ok {
+		return nil, err
+	}
+	kd, _ := startRows.Descriptors()
+
+	timeout, err := ctx.GetSessionVariable(ctx, InnodbLockWaitTimeout)
+	if err != nil {
+		return nil, err
+	}
+	timeoutSecs, _, err := sqltypes.Int64.Convert(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RowLocker{
+		owner:      ctx.Session.ID(),
+		read:       read,
+		timeout:    time.Duration(timeoutSecs.(int64)) * time.Second,
+		ddb:        startPoint.db,
+		wsRef:      ws.Ref(),
+		tableName:  strings.ToLower(tableName),
+		startRows:  startRows,
+		keyBuilder: val.NewTupleBuilder(kd),
+	}, nil
+}
+
+// NewRowWriteLocker returns a RowLocker which takes the exclusive locks of the rows written by an UPDATE, DELETE or
+// REPLACE of the table named in the database named, or nil if the write doesn't need to lock rows. Unlike locking
+// reads, writes don't fail when a locked row was changed by a concurrently committed transaction: such changes are
+// merged, or reported as conflicts, when the transaction commits.
+func NewRowWriteLocker(ctx *sql.Context, dbName, tableName string) (*RowLocker, error) {
+	l, err := NewRowLocker(ctx, dbName, tableName, LockingRead{Exclusive: true, Wait: RowLockWaitTimeout})
+	if l != nil {
+		l.write = true
+	}
+	return l, err
+}
+
+// Lock locks the row with the primary key given, whose values are in the order of the table's primary key columns.
+// It returns false if the row was skipped because it is locked by another transaction.
+func (l *RowLocker) Lock(ctx *sql.Context, pk sql.Row) (bool, error) {
+	for i, v := range pk {
+		if err := tree.PutField(ctx, l.startRows.NodeStore(), l.keyBuilder, i, v); err != nil {
+			return false, err
+		}
+	}
+	key := l.keyBuilder.Build(pkTuplePool)
+
+	lockKey := rowLockKey{ddb: l.ddb, workingSet: l.wsRef.String(), tableName: l.tableName, key: string(key)}
+	ok, err := rowLocks.acquire(ctx, l.owner, lockKey, l.read.Exclusive, l.write, l.read.Wait, l.timeout)
+	if err != nil || !ok {
+		if sql.ErrLockDeadlock.Is(err) {
+			err = rollbackForLockError(ctx, err)
+		}
+		return false, err
+	}
+	if l.write {
+		return true, nil
+	}
+
+	changed, err := l.changedSinceStart(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if changed {
+		return false, rollbackForLockError(ctx, sql.ErrLockDeadlock.New(ErrLockedRowChanged.Error()))
+	}
+	return true, nil
+}
+
+// changedSinceStart returns whether the row with |key| differs between the start of the transaction and the latest
+// commit to the branch.
+func (l *RowLocker) changedSinceStart(ctx *sql.Context, key val.Tuple) (bool, error) {
+	nomsRoot, err := l.ddb.NomsRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	if l.latestRows == nil || nomsRoot != l.latestRoot {
+		ws, err := l.ddb.ResolveWorkingSet(ctx, l.wsRef)
+		if err != nil {
+			return false, err
+		}
+		rows, ok, err := primaryRows(ctx, ws.WorkingRoot(), l.tableName)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return true, nil
+		}
+		startKd, _ := l.startRows.Descriptors()
+		kd, _ := rows.Descriptors()
+		if !kd.Equals(startKd) {
+			return true, nil
+		}
+		l.latestRoot, l.latestRows = nomsRoot, &rows
+	}
+
+	startVal, err := getRow(ctx, l.startRows, key)
+	if err != nil {
+		return false, err
+	}
+	latestVal, err := getRow(ctx, *l.latestRows, key)
+	if err != nil {
+		return false, err
+	}
+	return (startVal == nil) != (latestVal == nil) || !bytes.Equal(startVal, latestVal), nil
+}
+
+func getRow(ctx context.Context, m prolly.Map, key val.Tuple) (val.Tuple, error) {
+	var row val.Tuple
+	err := m.Get(ctx, key, func(k, v val.Tuple) error {
+		if k != nil {
+			row = v
+		}
+		return nil
+	})
+	return row, err
+}
+
+// primaryRows returns the primary row data of the table named in |root|, and whether the table exists and has a
+// primary key.
+func primaryRows(ctx context.Context, root doltdb.RootValue, tableName string) (prolly.Map, bool, error) {
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil || !ok {
+		return prolly.Map{}, false, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return prolly.Map{}, false, err
+	}
+	if sch.GetPKCols().Size() == 0 {
+		return prolly.Map{}, false, nil
+	}
+	rows, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return prolly.Map{}, false, err
+	}
+	if !types.IsFormat_DOLT(rows.Format()) {
+		return prolly.Map{}, false, nil
+	}
+	return durable.ProllyMapFromIndex(rows), true, nil
+}
+
+// rollbackForLockError rolls back the transaction of |ctx| and returns |err|, or the error rolling back.
+func rollbackForLockError(ctx *sql.Context, err error) error {
+	tx, ok := ctx.GetTransaction().(*DoltTransaction)
+	if !ok {
+		return err
+	}
+	if rollbackErr := tx.rollback(ctx); rollbackErr != nil {
+		return rollbackErr
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowLockManager(t *testing.T) {
+	ctx := context.Background()
+	row1 := rowLockKey{workingSet: "workingSets/heads/main", tableName: "t", key: "1"}
+	row2 := rowLockKey{workingSet: "workingSets/heads/main", tableName: "t", key: "2"}
+
+	t.Run("shared locks are compatible", func(t *testing.T) {
+		m := newRowLockManager()
+		ok, err := m.acquire(ctx, 1, row1, false, false, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = m.acquire(ctx, 2, row1, false, false, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = m.acquire(ctx, 2, row1, true, false, RowLockNoWait, time.Second)
+		assert.Equal(t, ErrLockNowait, err)
+		ok, err = m.acquire(ctx, 1, row1, true, false, RowLockSkipLocked, time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// the sole holder of a shared lock can upgrade it
+		m.releaseAll(2)
+		ok, err = m.acquire(ctx, 1, row1, true, false, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		m.releaseAll(1)
+		assert.Empty(t, m.locks)
+		assert.Empty(t, m.held)
+	})
+
+	t.Run("writes wait for locking reads but not for each other", func(t *testing.T) {
+		m := newRowLockManager()
+		_, err := m.acquire(ctx, 1, row1, false, false, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		_, err = m.acquire(ctx, 2, row1, true, true, RowLockNoWait, time.Second)
+		assert.Equal(t, ErrLockNowait, err)
+
+		m.releaseAll(1)
+		ok, err := m.acquire(ctx, 2, row1, true, true, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = m.acquire(ctx, 3, row1, true, true, RowLockNoWait, time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		_, err = m.acquire(ctx, 1, row1, false, false, RowLockNoWait, time.Second)
+		assert.Equal(t, ErrLockNowait, err)
+	})
+
+	t.Run("waits until release", func(t *testing.T) {
+		m := newRowLockManager()
+		_, err := m.acquire(ctx, 1, row1, true, false, RowLockWaitTimeout, time.Second)
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			_, err := m.acquire(ctx, 2, row1, false, false, RowLockWaitTimeout, time.Minute)
+			acquired <- err
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("lock acquired while held exclusively")
+		case <-time.After(50 * time.Millisecond):
+		}
+		m.releaseAll(1)
+		require.NoError(t, <-acquired)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		m := newRowLockManager()
+		_, err := m.acquire(ctx, 1, row1, false, false, RowLockWaitTimeout, time.Second)
+		require.NoError(t, err)
+		_, err = m.acquire(ctx, 2, row1, true, false, RowLockWaitTimeout, 10*time.Millisecond)
+		assert.Equal(t, ErrLockWaitTimeout, err)
+		assert.Empty(t, m.waitingFor)
+	})
+
+	t.Run("detects deadlocks", func(t *testing.T) {
+		m := newRowLockManager()
+		_, err := m.acquire(ctx, 1, row1, true, false, RowLockWaitTimeout, time.Second)
+		require.NoError(t, err)
+		_, err = m.acquire(ctx, 2, row2, true, false, RowLockWaitTimeout, time.Second)
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			_, err := m.acquire(ctx, 1, row2, true, false, RowLockWaitTimeout, time.Minute)
+			acquired <- err
+		}()
+		require.Eventually(t, func() bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			_, ok := m.waitingFor[1]
+			return ok
+		}, time.Second, time.Millisecond)
+
+		_, err = m.acquire(ctx, 2, row1, true, false, RowLockWaitTimeout, time.Minute)
+		assert.True(t, sql.ErrLockDeadlock.Is(err))
+		m.releaseAll(2)
+		require.NoError(t, <-acquired)
+	})
+
+	t.Run("honours context cancellation", func(t *testing.T) {
+		m := newRowLockManager()
+		_, err := m.acquire(ctx, 1, row1, true, false, RowLockWaitTimeout, time.Second)
+		require.NoError(t, err)
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = m.acquire(cancelCtx, 2, row1, true, false, RowLockWaitTimeout, time.Minute)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestDoltSessionCloseReleasesRowLocks(t *testing.T) {
+	sess := DefaultSession(emptyDatabaseProvider(), nil)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sess))
+	row := rowLockKey{workingSet: "workingSets/heads/main", tableName: "closed", key: "1"}
+	_, err := rowLocks.acquire(ctx, sess.ID(), row, true, false, RowLockNoWait, time.Second)
+	require.NoError(t, err)
+
+	// a client which disconnects mid transaction never commits or rolls back
+	require.NoError(t, sess.Close(ctx))
+	ok, err := rowLocks.acquire(ctx, sess.ID()+1, row, true, false, RowLockNoWait, time.Second)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	rowLocks.releaseAll(sess.ID() + 1)
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sequences"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/globalstate"
+)
+
+// SequenceTracker tracks the values of the sequences of a database reserved by each branch, so that transactions on
+// a branch never reserve the same value, even before they commit. Like the AutoIncrementTracker, it also tracks the
+// largest number of values reserved from each sequence on any branch, from which sequences with a branch range
+// allocate the ranges of values of each branch.
+type SequenceTracker struct {
+	mu *sync.Mutex
+	// the largest number of values reserved from each sequence with a branch range on any branch
+	reserved map[string]int64
+	// the positions reserved for each branch in each sequence
+	branches map[sequenceBranch]*reservedRange
+}
+
+var _ globalstate.SequenceTracker = SequenceTracker{}
+
+// sequenceBranch identifies a sequence on a branch
+type sequenceBranch struct {
+	name   string
+	branch string
+}
+
+// reservedRange is the range of positions of a sequence reserved for a branch. The next value of the sequence on the
+// branch is at position next, and the positions before end are reserved for it.
+type reservedRange struct {
+	next, end int64
+}
+
+// NewSequenceTracker returns a new sequence tracker for the roots given. All roots must be considered because the
+// ranges of values of sequences with a branch range are allocated across all branches. Roots provided should be the
+// working sets when available, or the branches when they are not.
+func NewSequenceTracker(ctx context.Context, roots ...doltdb.Rootish) (SequenceTracker, error) {
+	st := SequenceTracker{
+		mu:       &sync.Mutex{},
+		reserved: make(map[string]int64),
+		branches: make(map[sequenceBranch]*reservedRange),
+	}
+
+	for _, root := range roots {
+		root, err := root.ResolveRootValue(ctx)
+		if err != nil {
+			return SequenceTracker{}, err
+		}
+		seqs, err := sequences.Load(ctx, root)
+		if err != nil {
+			return SequenceTracker{}, err
+		}
+		for _, seq := range seqs {
+			if seq.BranchRange > 0 && seq.Reserved > st.reserved[seq.Name] {
+				st.reserved[seq.Name] = seq.Reserved
+			}
+		}
+	}
+
+	return st, nil
+}
+
+// Next implements globalstate.SequenceTracker.
+func (st SequenceTracker) Next(branch string, seq sequences.Sequence) (int64, int64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	key := sequenceBranch{name: seq.Name, branch: strings.ToLower(branch)}
+	r, ok := st.branches[key]
+	if !ok {
+		r = &reservedRange{}
+		st.branches[key] = r
+	}
+
+	if seq.BranchRange == 0 {
+		// the values of the branch follow those reserved on it by this and by committed transactions
+		r.next = max(r.next, seq.Reserved)
+		pos := r.next
+		r.next++
+		r.end = r.next
+		return pos, r.end
+	}
+
+	if r.next >= r.end {
+		// the range of the branch is used up, so it gets the next range of the sequence
+		start := max(st.reserved[seq.Name], seq.Reserved)
+		r.next, r.end = start, start+min(seq.BranchRange, math.MaxInt64-start)
+		st.reserved[seq.Name] = r.end
+	}
+	pos := r.next
+	r.next++
+	return pos, max(r.end, seq.Reserved)
+}
+
+// Reset implements globalstate.SequenceTracker.
+func (st SequenceTracker) Reset(branch, name string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.branches, sequenceBranch{name: strings.ToLower(name), branch: strings.ToLower(branch)})
+}
+
+// SequenceTracker returns the sequence tracker of the database named |dbName|, and the name of the branch of the
+// database the session is working on. Sequences can only be used on branches, since their reservations are written to
+// the working set.
+func (d *DoltSession) SequenceTracker(ctx *sql.Context, dbName string) (globalstate.SequenceTracker, string, error) {
+	branchState, ok, err := d.lookupDbState(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", sql.ErrDatabaseNotFound.New(dbName)
+	}
+	if branchState.WorkingSet() == nil || branchState.dbState.globalState == nil {
+		return nil, "", doltdb.ErrOperationNotSupportedInDetachedHead
+	}
+
+	tracker, err := branchState.dbState.globalState.SequenceTracker(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return tracker, branchState.head, nil
+}
+
+// ReserveSequenceValues records that the current transaction reserved the first |reserved| positions of the sequence
+// named |name| of the database named |dbName|. Reservations are kept in the session rather than written to the working
+// set right away, so that reserving values in the middle of a statement doesn't disturb the statement's table writers.
+// They are written to the working set when the transaction commits.
+func (d *DoltSession) ReserveSequenceValues(ctx *sql.Context, dbName, name string, reserved int64) error {
+	branchState, ok, err := d.lookupDbState(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return sql.ErrDatabaseNotFound.New(dbName)
+	}
+	if branchState.WorkingSet() == nil {
+		return doltdb.ErrOperationNotSupportedInDetachedHead
+	}
+	if branchState.readOnly {
+		return fmt.Errorf("cannot set root on read-only session")
+	}
+
+	if branchState.sequenceReservations == nil {
+		branchState.sequenceReservations = make(map[string]int64)
+	}
+	name = strings.ToLower(name)
+	branchState.sequenceReservations[name] = max(branchState.sequenceReservations[name], reserved)
+	branchState.dirty = true
+	return nil
+}
+
+// ResetSequence forgets the values of the sequence named |name| of the database named |dbName| reserved by the
+// session's branch, after the sequence was created or dropped.
+func (d *DoltSession) ResetSequence(ctx *sql.Context, dbName, name string) error {
+	tracker, branch, err := d.SequenceTracker(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	branchState, _, err := d.lookupDbState(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	delete(branchState.sequenceReservations, strings.ToLower(name))
+	tracker.Reset(branch, name)
+	return nil
+}
+
+// withSequenceReservations returns |ws| with the values of sequences reserved by the transaction recorded in its
+// working root. Sequences which no longer exist, or which already record as many reserved values, are left alone.
+func (bs *branchState) withSequenceReservations(ctx context.Context, ws *doltdb.WorkingSet) (*doltdb.WorkingSet, error) {
+	if len(bs.sequenceReservations) == 0 {
+		return ws, nil
+	}
+
+	root := ws.WorkingRoot()
+	for name, reserved := range bs.sequenceReservations {
+		seq, ok, err := sequences.Get(ctx, root, name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || seq.Reserved >= reserved {
+			continue
+		}
+		seq.Reserved = reserved
+		root, err = sequences.Put(ctx, root, seq)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ws.WithWorkingRoot(root), nil
+}
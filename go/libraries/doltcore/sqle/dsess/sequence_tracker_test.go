@@ -0,0 +1,83 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sequences"
+)
+
+func TestSequenceTrackerBranchValues(t *testing.T) {
+	st, err := NewSequenceTracker(context.Background())
+	require.NoError(t, err)
+	seq := sequences.New("s", 1)
+
+	next := func(branch string, seq sequences.Sequence) []int64 {
+		pos, reserved := st.Next(branch, seq)
+		return []int64{pos, reserved}
+	}
+
+	// transactions which haven't committed their reservations yet still get different values
+	assert.Equal(t, []int64{0, 1}, next("main", seq))
+	assert.Equal(t, []int64{1, 2}, next("main", seq))
+	// each branch has its own values
+	assert.Equal(t, []int64{0, 1}, next("other", seq))
+	// values follow those reserved on the branch by committed transactions
+	seq.Reserved = 10
+	assert.Equal(t, []int64{10, 11}, next("main", seq))
+	// and aren't reserved again if the branch's reservations are undone
+	seq.Reserved = 0
+	assert.Equal(t, []int64{11, 12}, next("main", seq))
+
+	st.Reset("MAIN", "s")
+	assert.Equal(t, []int64{0, 1}, next("main", seq))
+}
+
+func TestSequenceTrackerBranchRanges(t *testing.T) {
+	st, err := NewSequenceTracker(context.Background())
+	require.NoError(t, err)
+	seq := sequences.New("s", 1)
+	seq.BranchRange = 3
+
+	next := func(branch string, seq sequences.Sequence) []int64 {
+		pos, reserved := st.Next(branch, seq)
+		return []int64{pos, reserved}
+	}
+
+	assert.Equal(t, []int64{0, 3}, next("main", seq))
+	assert.Equal(t, []int64{3, 6}, next("other", seq))
+	assert.Equal(t, []int64{1, 3}, next("main", seq))
+	assert.Equal(t, []int64{2, 3}, next("main", seq))
+	// the range of main is used up, so it gets the next one
+	assert.Equal(t, []int64{6, 9}, next("main", seq))
+	assert.Equal(t, []int64{4, 6}, next("other", seq))
+
+	// a branch whose row records more reserved values, e.g. after a merge, keeps using its range
+	seq.Reserved = 20
+	assert.Equal(t, []int64{7, 20}, next("main", seq))
+	// and ranges allocated afterward follow all values reserved on it
+	assert.Equal(t, []int64{5, 20}, next("other", seq))
+	assert.Equal(t, []int64{20, 23}, next("other", seq))
+
+	// ranges aren't allocated again after a branch forgets its range
+	st.Reset("main", "s")
+	seq.Reserved = 0
+	assert.Equal(t, []int64{23, 26}, next("main", seq))
+}
@@ -0,0 +1,313 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// ErrSerializationFailure is returned when a SERIALIZABLE transaction read rows which a concurrent transaction wrote
+// and committed first.
+var ErrSerializationFailure = errors.New("this transaction read rows which were written by a concurrently committed transaction")
+
+// isolationLevelSerializable is the value of @@transaction_isolation which makes transactions track their reads and
+// check them for concurrent writes when they commit.
+const isolationLevelSerializable = "SERIALIZABLE"
+
+// maxTrackedRanges is the number of key ranges tracked for each index read by a transaction. Past this, reads of the
+// index are tracked as a read of the whole index.
+const maxTrackedRanges = 1024
+
+// errReadConflict stops a diff once a write into a read range is found.
+var errReadConflict = errors.New("read conflict")
+
+// primaryIndexName is the name under which reads of a table's primary row data are tracked.
+const primaryIndexName = "PRIMARY"
+
+// readSet is the set of key ranges a SERIALIZABLE transaction has read, by table and index. Reads can happen in
+// parallel, so it is guarded by a mutex.
+type readSet struct {
+	mu    sync.Mutex
+	reads map[indexReadKey]*indexReads
+}
+
+type indexReadKey struct {
+	// The revision qualified database name, lower cased.
+	dbName    string
+	tableName string
+	indexName string
+}
+
+type indexReads struct {
+	// True if the whole index was read, in which case |ranges| is ignored.
+	all    bool
+	ranges []prolly.Range
+}
+
+func newReadSet() *readSet {
+	return &readSet{reads: make(map[indexReadKey]*indexReads)}
+}
+
+func (rs *readSet) add(key indexReadKey, rng *prolly.Range) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	reads, ok := rs.reads[key]
+	if !ok {
+		reads = &indexReads{}
+		rs.reads[key] = reads
+	}
+	if reads.all {
+		return
+	}
+	if rng == nil || len(reads.ranges) >= maxTrackedRanges {
+		reads.all = true
+		reads.ranges = nil
+		return
+	}
+	reads.ranges = append(reads.ranges, *rng)
+}
+
+func (rs *readSet) snapshot() map[indexReadKey]indexReads {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	res := make(map[indexReadKey]indexReads, len(rs.reads))
+	for k, v := range rs.reads {
+		res[k] = *v
+	}
+	return res
+}
+
+// serializableTransaction returns the transaction of |ctx| if it is a SERIALIZABLE dolt transaction.
+func serializableTransaction(ctx *sql.Context) (*DoltTransaction, bool) {
+	tx, ok := ctx.GetTransaction().(*DoltTransaction)
+	if !ok || tx.reads == nil {
+		return nil, false
+	}
+	return tx, true
+}
+
+// RecordTableRead records that the current transaction read all the rows of the table named in the database named, if
+// the transaction is SERIALIZABLE.
+func RecordTableRead(ctx *sql.Context, dbName, tableName string) {
+	if tx, ok := serializableTransaction(ctx); ok {
+		tx.reads.add(indexReadKey{dbName: strings.ToLower(dbName), tableName: tableName, indexName: primaryIndexName}, nil)
+	}
+}
+
+// RecordIndexRead records that the current transaction read the rows in |rng| of the index named, if the transaction
+// is SERIALIZABLE. A nil |rng| records a read of the whole index.
+func RecordIndexRead(ctx *sql.Context, dbName, tableName, indexName string, rng *prolly.Range) {
+	if tx, ok := serializableTransaction(ctx); ok {
+		tx.reads.add(indexReadKey{dbName: strings.ToLower(dbName), tableName: tableName, indexName: indexName}, rng)
+	}
+}
+
+// isSerializable returns whether the session of |ctx| uses the SERIALIZABLE isolation level.
+func isSerializable(ctx *sql.Context) bool {
+	val, err := ctx.GetSessionVariable(ctx, "transaction_isolation")
+	if err != nil {
+		return false
+	}
+	level, ok := val.(string)
+	return ok && strings.EqualFold(level, isolationLevelSerializable)
+}
+
+// validateReads checks, for a SERIALIZABLE transaction, that no transaction which committed after this one started
+// wrote into any of the ranges this one read. If one did, this transaction is rolled back and an error is returned.
+// Without this check, two transactions which each read what the other writes can both commit, which no serial order of
+// the transactions allows.
+//
+// called with txLock held
+func (tx *DoltTransaction) validateReads(ctx *sql.Context) error {
+	if tx.reads == nil {
+		return nil
+	}
+
+	sess := DSessFromSess(ctx.Session)
+	roots := make(map[string]*[2]doltdb.RootValue)
+	for key, reads := range tx.reads.snapshot() {
+		branchRoots, ok := roots[key.dbName]
+		if !ok {
+			var err error
+			branchRoots, err = tx.concurrentRoots(ctx, sess, key.dbName)
+			if err != nil {
+				return err
+			}
+			roots[key.dbName] = branchRoots
+		}
+		if branchRoots == nil {
+			continue
+		}
+
+		conflict, err := readConflicts(ctx, branchRoots[0], branchRoots[1], key, reads)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			if err := tx.rollback(ctx); err != nil {
+				return err
+			}
+			return sql.ErrLockDeadlock.New(ErrSerializationFailure.Error())
+		}
+	}
+	return nil
+}
+
+// validateReadOnly validates the reads of a SERIALIZABLE transaction which wrote nothing. Such a transaction can
+// still be part of a cycle no serial order allows: it can see the writes of one concurrent transaction but not those
+// of another which that one read past. Rather than track which transactions saw which, it fails whenever a concurrent
+// transaction wrote into the ranges it read, as a writing transaction does.
+func (tx *DoltTransaction) validateReadOnly(ctx *sql.Context) error {
+	if tx.reads == nil {
+		return nil
+	}
+	txLock.Lock()
+	defer txLock.Unlock()
+	return tx.validateReads(ctx)
+}
+
+// concurrentRoots returns the working root of the branch of |dbName| when this transaction started, and its working
+// root now. It returns nil if no other transaction can have written to the branch since this one started.
+func (tx *DoltTransaction) concurrentRoots(ctx *sql.Context, sess *DoltSession, dbName string) (*[2]doltdb.RootValue, error) {
+	branchState, ok, err := sess.lookupDbState(ctx, dbName)
+	if err != nil || !ok {
+		return nil, err
+	}
+	ws := branchState.WorkingSet()
+	if ws == nil {
+		// read only revisions can't be written to
+		return nil, nil
+	}
+	startPoint, ok := tx.dbStartPoints[strings.ToLower(branchState.dbState.dbName)]
+	if !ok {
+		return nil, nil
+	}
+
+	startWs, err := startPoint.db.ResolveWorkingSetAtRoot(ctx, ws.Ref(), startPoint.rootHash)
+	if err == doltdb.ErrWorkingSetNotFound {
+		// the branch was created after this transaction started
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	currWs, err := startPoint.db.ResolveWorkingSet(ctx, ws.Ref())
+	if err == doltdb.ErrWorkingSetNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	startHash, err := startWs.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	currHash, err := currWs.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	if startHash == currHash {
+		return nil, nil
+	}
+	return &[2]doltdb.RootValue{startWs.WorkingRoot(), currWs.WorkingRoot()}, nil
+}
+
+// readConflicts returns whether the rows of the index read by |key| changed between |from| and |to| within |reads|.
+func readConflicts(ctx context.Context, from, to doltdb.RootValue, key indexReadKey, reads indexReads) (bool, error) {
+	fromIdx, fromOk, err := readIndexData(ctx, from, key)
+	if err != nil {
+		return false, err
+	}
+	toIdx, toOk, err := readIndexData(ctx, to, key)
+	if err != nil {
+		return false, err
+	}
+	if !fromOk || !toOk {
+		// the table or index was created or dropped concurrently
+		return fromOk != toOk, nil
+	}
+
+	fromHash, err := fromIdx.HashOf()
+	if err != nil {
+		return false, err
+	}
+	toHash, err := toIdx.HashOf()
+	if err != nil {
+		return false, err
+	}
+	if fromHash == toHash {
+		return false, nil
+	}
+	if reads.all || !types.IsFormat_DOLT(fromIdx.Format()) {
+		return true, nil
+	}
+
+	fromMap, toMap := durable.ProllyMapFromIndex(fromIdx), durable.ProllyMapFromIndex(toIdx)
+	fromKd, _ := fromMap.Descriptors()
+	toKd, _ := toMap.Descriptors()
+	if !fromKd.Equals(toKd) {
+		// the index changed shape, so the ranges no longer apply to it
+		return true, nil
+	}
+
+	for _, rng := range reads.ranges {
+		err = prolly.RangeDiffMaps(ctx, fromMap, toMap, rng, func(ctx context.Context, diff tree.Diff) error {
+			if rng.Matches(val.Tuple(diff.Key)) {
+				return errReadConflict
+			}
+			return nil
+		})
+		if err == errReadConflict {
+			return true, nil
+		} else if err != nil && err != io.EOF {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// readIndexData returns the row data of the index read by |key| in |root|, and whether it exists.
+func readIndexData(ctx context.Context, root doltdb.RootValue, key indexReadKey) (durable.Index, bool, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: key.tableName})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if key.indexName == primaryIndexName {
+		idx, err := tbl.GetRowData(ctx)
+		return idx, err == nil, err
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if !sch.Indexes().Contains(key.indexName) {
+		return nil, false, nil
+	}
+	idx, err := tbl.GetIndexRowData(ctx, key.indexName)
+	return idx, err == nil, err
+}
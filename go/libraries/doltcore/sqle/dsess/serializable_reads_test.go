@@ -0,0 +1,50 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dolthub/dolt/go/store/prolly"
+)
+
+func TestReadSet(t *testing.T) {
+	key := indexReadKey{dbName: "mydb", tableName: "t", indexName: primaryIndexName}
+	other := indexReadKey{dbName: "mydb", tableName: "t", indexName: "idx"}
+
+	rs := newReadSet()
+	rng := prolly.Range{}
+	rs.add(key, &rng)
+	rs.add(key, &rng)
+	rs.add(other, nil)
+	reads := rs.snapshot()
+	assert.False(t, reads[key].all)
+	assert.Len(t, reads[key].ranges, 2)
+	assert.True(t, reads[other].all)
+
+	// a range read after a full read doesn't narrow it
+	rs.add(other, &rng)
+	assert.True(t, rs.snapshot()[other].all)
+
+	// too many ranges are tracked as a read of the whole index
+	for i := 0; i < maxTrackedRanges; i++ {
+		rs.add(key, &rng)
+	}
+	reads = rs.snapshot()
+	assert.True(t, reads[key].all)
+	assert.Empty(t, reads[key].ranges)
+}
@@ -163,6 +163,9 @@ func (d *DoltSession) lookupDbState(ctx *sql.Context, dbName string) (*branchSta
 			if dbState.Err != nil {
 				return nil, false, dbState.Err
 			}
+			if err := checkReadAccess(ctx, baseName, branchState); err != nil {
+				return nil, false, err
+			}
 
 			return branchState, ok, nil
 		}
@@ -197,7 +200,12 @@ func (d *DoltSession) lookupDbState(ctx *sql.Context, dbName string) (*branchSta
 		return nil, false, sql.ErrDatabaseNotFound.New(dbName)
 	}
 
-	return dbState.heads[strings.ToLower(database.Revision())], true, nil
+	branchState := dbState.heads[strings.ToLower(database.Revision())]
+	if err := checkReadAccess(ctx, baseName, branchState); err != nil {
+		return nil, false, err
+	}
+
+	return branchState, true, nil
 }
 
 // RevisionDbName returns the name of the revision db for the base name and revision string given
@@ -314,6 +322,17 @@ func (d *DoltSession) SetValidateErr(err error) {
 	d.validateErr = err
 }
 
+// UseDatabase implements sql.Session. Using the revision database of a branch requires permission to read from it.
+func (d *DoltSession) UseDatabase(ctx *sql.Context, db sql.Database) error {
+	if sqlDb, ok := db.(SqlDatabase); ok && sqlDb.RevisionType() == RevisionTypeBranch {
+		baseName, branch := SplitRevisionDbName(sqlDb.RevisionQualifiedName())
+		if err := branch_control.CanReadBranch(ctx, baseName, branch); err != nil {
+			return err
+		}
+	}
+	return d.Session.UseDatabase(ctx, db)
+}
+
 // ValidateSession validates a working set if there are a valid sessionState with non-nil working set.
 // If there is no sessionState or its current working set not defined, then no need for validation,
 // so no error is returned.
@@ -331,6 +350,7 @@ func (d *DoltSession) StartTransaction(ctx *sql.Context, tCharacteristic sql.Tra
 
 	// New transaction, clear all session state
 	d.clear()
+	releaseRowLocks(d.ID())
 
 	// Take a snapshot of the current noms root for every database under management
 	doltDatabases := d.provider.DoltDatabases()
@@ -431,8 +451,9 @@ func (d *DoltSession) newWorkingSetForHead(ctx *sql.Context, wsRef ref.WorkingSe
 }
 
 // CommitTransaction commits the in-progress transaction. Depending on session settings, this may write only a new
-// working set, or may additionally create a new dolt commit for the current HEAD. If more than one branch head has
-// changes, the transaction is rejected.
+// working set, or may additionally create a new dolt commit for the current HEAD. If more than one database has
+// changes, they are committed atomically, see commitDirtyWorkingSets. If more than one branch head of the same database
+// has changes, the transaction is rejected.
 func (d *DoltSession) CommitTransaction(ctx *sql.Context, tx sql.Transaction) (err error) {
 	// Any non-error path must set the ctx's transaction to nil even if no work was done, because the engine only clears
 	// out transaction state in some cases. Changes to only branch heads (creating a new branch, reset, etc.) have no
@@ -441,6 +462,7 @@ func (d *DoltSession) CommitTransaction(ctx *sql.Context, tx sql.Transaction) (e
 	defer func() {
 		if err == nil {
 			ctx.SetTransaction(nil)
+			releaseRowLocks(d.ID())
 		}
 	}()
 
@@ -450,11 +472,16 @@ func (d *DoltSession) CommitTransaction(ctx *sql.Context, tx sql.Transaction) (e
 
 	dirties := d.dirtyWorkingSets()
 	if len(dirties) == 0 {
+		if dtx, ok := tx.(*DoltTransaction); ok {
+			return dtx.validateReadOnly(ctx)
+		}
 		return nil
 	}
 
-	if len(dirties) > 1 {
-		return ErrDirtyWorkingSets
+	for _, dirty := range dirties {
+		if err = d.flushSequenceReservations(ctx, dirty); err != nil {
+			return err
+		}
 	}
 
 	performDoltCommitVar, err := d.Session.GetSessionVariable(ctx, DoltCommitOnTransactionCommit)
@@ -467,6 +494,10 @@ func (d *DoltSession) CommitTransaction(ctx *sql.Context, tx sql.Transaction) (e
 		return fmt.Errorf(fmt.Sprintf("Unexpected type for var %s: %T", DoltCommitOnTransactionCommit, performDoltCommitVar))
 	}
 
+	if len(dirties) > 1 {
+		return d.commitDirtyWorkingSets(ctx, dirties, tx, peformDoltCommitInt == 1)
+	}
+
 	dirtyBranchState := dirties[0]
 	if peformDoltCommitInt == 1 {
 		// if the dirty working set doesn't belong to the currently checked out branch, that's an error
@@ -475,31 +506,14 @@ func (d *DoltSession) CommitTransaction(ctx *sql.Context, tx sql.Transaction) (e
 			return err
 		}
 
-		message := "Transaction commit"
-		doltCommitMessageVar, err := d.Session.GetSessionVariable(ctx, DoltCommitOnTransactionCommitMessage)
+		var props actions.CommitStagedProps
+		props, err = d.transactionCommitProps(ctx)
 		if err != nil {
 			return err
 		}
 
-		doltCommitMessageString, ok := doltCommitMessageVar.(string)
-		if !ok && doltCommitMessageVar != nil {
-			return fmt.Errorf(fmt.Sprintf("Unexpected type for var %s: %T", DoltCommitOnTransactionCommitMessage, doltCommitMessageVar))
-		}
-
-		trimmedString := strings.TrimSpace(doltCommitMessageString)
-		if strings.TrimSpace(doltCommitMessageString) != "" {
-			message = trimmedString
-		}
-
 		var pendingCommit *doltdb.PendingCommit
-		pendingCommit, err = d.PendingCommitAllStaged(ctx, dirtyBranchState, actions.CommitStagedProps{
-			Message:    message,
-			Date:       ctx.QueryTime(),
-			AllowEmpty: false,
-			Force:      false,
-			Name:       d.Username(),
-			Email:      d.Email(),
-		})
+		pendingCommit, err = d.PendingCommitAllStaged(ctx, dirtyBranchState, props)
 		if err != nil {
 			return err
 		}
@@ -547,7 +561,94 @@ func (d *DoltSession) validateDoltCommit(ctx *sql.Context, dirtyBranchState *bra
 	return nil
 }
 
-var ErrDirtyWorkingSets = errors.New("Cannot commit changes on more than one branch / database")
+// transactionCommitProps returns the properties of the dolt commit created when @@dolt_transaction_commit is set.
+func (d *DoltSession) transactionCommitProps(ctx *sql.Context) (actions.CommitStagedProps, error) {
+	message := "Transaction commit"
+	doltCommitMessageVar, err := d.Session.GetSessionVariable(ctx, DoltCommitOnTransactionCommitMessage)
+	if err != nil {
+		return actions.CommitStagedProps{}, err
+	}
+
+	doltCommitMessageString, ok := doltCommitMessageVar.(string)
+	if !ok && doltCommitMessageVar != nil {
+		return actions.CommitStagedProps{}, fmt.Errorf(fmt.Sprintf("Unexpected type for var %s: %T", DoltCommitOnTransactionCommitMessage, doltCommitMessageVar))
+	}
+
+	trimmedString := strings.TrimSpace(doltCommitMessageString)
+	if strings.TrimSpace(doltCommitMessageString) != "" {
+		message = trimmedString
+	}
+
+	return actions.CommitStagedProps{
+		Message:    message,
+		Date:       ctx.QueryTime(),
+		AllowEmpty: false,
+		Force:      false,
+		Name:       d.Username(),
+		Email:      d.Email(),
+	}, nil
+}
+
+// commitDirtyWorkingSets commits the dirty working sets of a transaction which wrote to more than one database, so that
+// either all of them are committed or none are. Only one branch of each database may be dirty. If |doltCommit| is true,
+// each dirty working set which has changes to stage also gets a new dolt commit.
+func (d *DoltSession) commitDirtyWorkingSets(ctx *sql.Context, dirties []*branchState, tx sql.Transaction, doltCommit bool) error {
+	dtx, ok := tx.(*DoltTransaction)
+	if !ok {
+		return fmt.Errorf("expected a DoltTransaction")
+	}
+
+	var props actions.CommitStagedProps
+	if doltCommit {
+		var err error
+		props, err = d.transactionCommitProps(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	currDbBaseName, currRev := SplitRevisionDbName(ctx.GetCurrentDatabase())
+	seen := make(map[string]bool)
+	writes := make([]multiDbWrite, len(dirties))
+	for i, branchState := range dirties {
+		dbName := strings.ToLower(branchState.dbState.dbName)
+		if seen[dbName] {
+			return ErrDirtyWorkingSets
+		}
+		seen[dbName] = true
+
+		writes[i] = multiDbWrite{
+			dbName:     branchState.RevisionDbName(),
+			workingSet: branchState.WorkingSet(),
+		}
+		if !doltCommit {
+			continue
+		}
+
+		// if the dirty working set doesn't belong to the branch checked out for its database, that's an error
+		rev := branchState.dbState.checkedOutRevSpec
+		if strings.EqualFold(currDbBaseName, dbName) && currRev != "" {
+			rev = currRev
+		}
+		if !strings.EqualFold(rev, branchState.head) {
+			return fmt.Errorf("no changes to dolt_commit on branch %s of database %s", rev, branchState.dbState.dbName)
+		}
+
+		pendingCommit, err := d.PendingCommitAllStaged(ctx, branchState, props)
+		if err != nil {
+			return err
+		}
+		// Nothing to stage, so only the working set is committed
+		if pendingCommit != nil {
+			writes[i].workingSet = writes[i].workingSet.WithWorkingRoot(pendingCommit.Roots.Working).WithStagedRoot(pendingCommit.Roots.Staged)
+			writes[i].commit = pendingCommit
+		}
+	}
+
+	return dtx.commitAll(ctx, d.fs, writes)
+}
+
+var ErrDirtyWorkingSets = errors.New("Cannot commit changes on more than one branch of a database")
 
 // dirtyWorkingSets returns all dirty working sets for this session
 func (d *DoltSession) dirtyWorkingSets() []*branchState {
@@ -594,10 +695,23 @@ func (d *DoltSession) DoltCommit(
 	tx sql.Transaction,
 	commit *doltdb.PendingCommit,
 ) (*doltdb.Commit, error) {
+	branchState, ok, err := d.lookupDbState(ctx, dbName)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+
 	commitFunc := func(ctx *sql.Context, dtx *DoltTransaction, workingSet *doltdb.WorkingSet) (*doltdb.WorkingSet, *doltdb.Commit, error) {
+		// the working root of the pending commit doesn't include the sequence reservations of the transaction
+		workingSet, err := branchState.withSequenceReservations(ctx, workingSet.WithWorkingRoot(commit.Roots.Working).WithStagedRoot(commit.Roots.Staged))
+		if err != nil {
+			return nil, nil, err
+		}
+
 		ws, commit, err := dtx.DoltCommit(
 			ctx,
-			workingSet.WithWorkingRoot(commit.Roots.Working).WithStagedRoot(commit.Roots.Staged),
+			workingSet,
 			commit,
 			dbName)
 		if err != nil {
@@ -607,7 +721,24 @@ func (d *DoltSession) DoltCommit(
 		return ws, commit, err
 	}
 
-	return d.commitCurrentHead(ctx, dbName, tx, commitFunc)
+	return d.commitBranchState(ctx, branchState, tx, commitFunc)
+}
+
+// flushSequenceReservations writes the sequence reservations of the transaction to the working set of |branchState|,
+// so that they are staged along with the rest of the working set when the transaction creates a dolt commit.
+func (d *DoltSession) flushSequenceReservations(ctx *sql.Context, branchState *branchState) error {
+	if len(branchState.sequenceReservations) == 0 {
+		return nil
+	}
+	ws, err := branchState.withSequenceReservations(ctx, branchState.WorkingSet())
+	if err != nil {
+		return err
+	}
+	if err = d.SetWorkingSet(ctx, branchState.RevisionDbName(), ws); err != nil {
+		return err
+	}
+	branchState.sequenceReservations = nil
+	return nil
 }
 
 // doCommitFunc is a function to write to the database, which involves updating the working set and potentially
@@ -626,10 +757,16 @@ func (d *DoltSession) commitBranchState(
 		return nil, fmt.Errorf("expected a DoltTransaction")
 	}
 
-	_, newCommit, err := commitFunc(ctx, dtx, branchState.WorkingSet())
+	workingSet, err := branchState.withSequenceReservations(ctx, branchState.WorkingSet())
+	if err != nil {
+		return nil, err
+	}
+
+	_, newCommit, err := commitFunc(ctx, dtx, workingSet)
 	if err != nil {
 		return nil, err
 	}
+	branchState.sequenceReservations = nil
 
 	// Anything that commits a transaction needs its current transaction state cleared so that the next statement starts
 	// a new transaction. This should in principle be done by the engine, but it currently only understands explicit
@@ -742,9 +879,17 @@ func (d *DoltSession) newPendingCommit(ctx *sql.Context, branchState *branchStat
 func (d *DoltSession) Rollback(ctx *sql.Context, tx sql.Transaction) error {
 	// Nothing to do here, we just throw away all our work and let a new transaction begin next statement
 	d.clear()
+	releaseRowLocks(d.ID())
 	return nil
 }
 
+// Close ends this session for a client which disconnected. A transaction in progress will never commit, so it is rolled
+// back, which releases the row locks it holds. Every server frontend calls Close as it tears down a session.
+func (d *DoltSession) Close(ctx *sql.Context) error {
+	defer ctx.SetTransaction(nil)
+	return d.Rollback(ctx, ctx.GetTransaction())
+}
+
 // CreateSavepoint creates a new savepoint for this transaction with the name given. A previously created savepoint
 // with the same name will be overwritten.
 func (d *DoltSession) CreateSavepoint(ctx *sql.Context, tx sql.Transaction, savepointName string) error {
@@ -887,7 +1032,6 @@ func (d *DoltSession) ResolveRootForRef(ctx *sql.Context, dbName, refStr string)
 	if !ok {
 		return nil, nil, "", sql.ErrDatabaseNotFound.New(dbName)
 	}
-
 	headRef, err := d.CWBHeadRef(ctx, dbName)
 	if err == doltdb.ErrOperationNotSupportedInDetachedHead {
 		// leave head ref nil, we may not need it (commit hash)
@@ -903,6 +1047,9 @@ func (d *DoltSession) ResolveRootForRef(ctx *sql.Context, dbName, refStr string)
 	if !ok {
 		return nil, nil, "", doltdb.ErrGhostCommitRuntimeFailure
 	}
+	if err = CheckReadAccessForCommitSpec(ctx, dbName, dbData.Ddb, cs, cm); err != nil {
+		return nil, nil, "", err
+	}
 
 	root, err = cm.GetRootValue(ctx)
 	if err != nil {
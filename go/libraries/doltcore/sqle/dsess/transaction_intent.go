@@ -0,0 +1,376 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// transactionIntentFile is the file in the data directory which records the writes of a transaction which is being
+// committed to more than one database. Commits of such transactions are serialized by |txLock|, so there is at most
+// one intent outstanding at a time.
+const transactionIntentFile = ".dolt_transaction_intent.json"
+
+var errNoIntentFilesys = errors.New("cannot commit a transaction to more than one database without a data directory")
+
+// A transactionIntent records the writes a transaction is about to make to each of the databases it commits to, along
+// with the root of each database before the writes. It is written before any of the writes are made and removed after
+// all of them land. If the server stops with an intent outstanding, the working sets and branch heads it names are
+// restored to their prior values when the server next starts, so that either every write lands or none do.
+type transactionIntent struct {
+	Writes []intentWrite `json:"writes"`
+}
+
+type intentWrite struct {
+	// The base name of the database.
+	Database string `json:"database"`
+	// The working set ref which is written, e.g. workingSets/heads/main.
+	WorkingSet string `json:"working_set"`
+	// True if the write also creates a dolt commit on the branch of the working set.
+	DoltCommit bool `json:"dolt_commit"`
+	// The noms root of the database before the write.
+	PrevRoot string `json:"prev_root"`
+	// The hashes of the working set and, if the write made a dolt commit, the branch head written. They are recorded
+	// once the write lands, and are empty if the write was never made or the server stopped before recording them.
+	WrittenWorkingSet string `json:"written_working_set,omitempty"`
+	WrittenHead       string `json:"written_head,omitempty"`
+}
+
+// writeTransactionIntent records |intent| in |fs|, syncing it to disk when the filesystem supports it. The intent is
+// rewritten as each write lands, so it's written to a temporary file and moved into place, leaving either the old or
+// the new intent if the server stops part way through.
+func writeTransactionIntent(fs filesys.Filesys, intent transactionIntent) error {
+	data, err := json.Marshal(intent)
+	if err != nil {
+		return err
+	}
+	tmpFile := transactionIntentFile + ".tmp"
+	wr, err := fs.OpenForWrite(tmpFile, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	_, err = wr.Write(data)
+	if err == nil {
+		if syncer, ok := wr.(interface{ Sync() error }); ok {
+			err = syncer.Sync()
+		}
+	}
+	if closeErr := wr.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return fs.MoveFile(tmpFile, transactionIntentFile)
+}
+
+func removeTransactionIntent(fs filesys.Filesys) error {
+	return fs.DeleteFile(transactionIntentFile)
+}
+
+// recordWritten records in |w| the working set, and the commit |cm| if there is one, which the transaction wrote to
+// |ddb|. It's called right after the write with |txLock| held, so the working set stored is the one written.
+func (w *intentWrite) recordWritten(ctx context.Context, ddb *doltdb.DoltDB, cm *doltdb.Commit) error {
+	ws, err := ddb.ResolveWorkingSet(ctx, ref.NewWorkingSetRef(w.WorkingSet))
+	if err != nil {
+		return err
+	}
+	wsHash, err := ws.HashOf()
+	if err != nil {
+		return err
+	}
+	w.WrittenWorkingSet = wsHash.String()
+	if cm != nil {
+		headHash, err := cm.HashOf()
+		if err != nil {
+			return err
+		}
+		w.WrittenHead = headHash.String()
+	}
+	return nil
+}
+
+// stillWritten returns whether |curr| is the value which the transaction wrote, as recorded in |written|. When the
+// write wasn't recorded, the server stopped between making the write and recording it, and nothing else can have
+// written since, so the current value is the one written if it changed at all.
+func stillWritten(written string, curr hash.Hash) bool {
+	return written == "" || written == curr.String()
+}
+
+// restore sets the working set named by |w|, and its branch head if |w| made a dolt commit, in |ddb| back to their
+// values at |w.PrevRoot|. A value is only restored while it is still the one the transaction wrote, so that the writes
+// of transactions which committed after it are kept. Values which were not changed are left alone, so restore can be
+// called more than once.
+func (w intentWrite) restore(ctx context.Context, ddb *doltdb.DoltDB) error {
+	prevRoot, ok := hash.MaybeParse(w.PrevRoot)
+	if !ok {
+		return fmt.Errorf("invalid root hash %q for database %s in transaction intent", w.PrevRoot, w.Database)
+	}
+	wsRef := ref.NewWorkingSetRef(w.WorkingSet)
+
+	if w.DoltCommit {
+		headRef, err := wsRef.ToHeadRef()
+		if err != nil {
+			return err
+		}
+		prevHead, err := ddb.GetHashForRefStrByNomsRoot(ctx, headRef.String(), prevRoot)
+		if err != nil {
+			return err
+		}
+		currHead, err := ddb.GetHashForRefStr(ctx, headRef.String())
+		if err != nil {
+			return err
+		}
+		if *prevHead != *currHead && stillWritten(w.WrittenHead, *currHead) {
+			if err := ddb.SetHead(ctx, headRef, *prevHead); err != nil {
+				return err
+			}
+		}
+	}
+
+	prevWs, err := ddb.ResolveWorkingSetAtRoot(ctx, wsRef, prevRoot)
+	if err != nil && err != doltdb.ErrWorkingSetNotFound {
+		return err
+	}
+	currWs, err := ddb.ResolveWorkingSet(ctx, wsRef)
+	if err != nil && err != doltdb.ErrWorkingSetNotFound {
+		return err
+	}
+	// The working set may have been deleted since |w.PrevRoot|, in which case we recreate it.
+	var currHash hash.Hash
+	if currWs != nil {
+		currHash, err = currWs.HashOf()
+		if err != nil {
+			return err
+		}
+	}
+	if currWs != nil && !stillWritten(w.WrittenWorkingSet, currHash) {
+		return nil
+	}
+	if prevWs == nil {
+		if currWs == nil {
+			return nil
+		}
+		return ddb.DeleteWorkingSet(ctx, wsRef)
+	}
+	prevHash, err := prevWs.HashOf()
+	if err != nil {
+		return err
+	}
+	if prevHash == currHash {
+		return nil
+	}
+	meta := &datas.WorkingSetMeta{
+		Timestamp:   uint64(time.Now().Unix()),
+		Description: "rolled back partially committed transaction",
+	}
+	return ddb.UpdateWorkingSet(ctx, wsRef, prevWs, currHash, meta, nil)
+}
+
+// RecoverTransactionIntents rolls back the writes of a transaction which was being committed to more than one database
+// when the server last stopped, if there was one. It should be called when the server starts, before it serves any
+// queries. |dbs| are the databases of the server.
+func RecoverTransactionIntents(ctx context.Context, fs filesys.Filesys, dbs []SqlDatabase) error {
+	if fs == nil {
+		return nil
+	}
+	if exists, _ := fs.Exists(transactionIntentFile); !exists {
+		return nil
+	}
+	data, err := fs.ReadFile(transactionIntentFile)
+	if err != nil {
+		return err
+	}
+	var intent transactionIntent
+	if err := json.Unmarshal(data, &intent); err != nil {
+		return fmt.Errorf("could not read transaction intent %s: %w", transactionIntentFile, err)
+	}
+
+	ddbs := make(map[string]*doltdb.DoltDB)
+	for _, db := range dbs {
+		if ddb := db.DbData().Ddb; ddb != nil {
+			ddbs[strings.ToLower(db.Name())] = ddb
+		}
+	}
+	for _, w := range intent.Writes {
+		ddb, ok := ddbs[strings.ToLower(w.Database)]
+		if !ok {
+			logrus.Warnf("could not roll back interrupted transaction commit for database %s, which no longer exists", w.Database)
+			continue
+		}
+		if err := w.restore(ctx, ddb); err != nil {
+			return fmt.Errorf("could not roll back interrupted transaction commit for database %s: %w", w.Database, err)
+		}
+	}
+	logrus.Warnf("rolled back a transaction which was interrupted while committing to databases %s", intent.databaseNames())
+	return removeTransactionIntent(fs)
+}
+
+func (intent transactionIntent) databaseNames() string {
+	names := make([]string, len(intent.Writes))
+	for i, w := range intent.Writes {
+		names[i] = w.Database
+	}
+	return strings.Join(names, ", ")
+}
+
+// multiDbWrite is the working set, and optional dolt commit, which a transaction writes to one of the databases it
+// commits to.
+type multiDbWrite struct {
+	// The revision qualified name of the database.
+	dbName     string
+	workingSet *doltdb.WorkingSet
+	commit     *doltdb.PendingCommit
+}
+
+// commitAll commits this transaction to every database in |writes| with a two-phase commit. In the first phase, each
+// working set is merged with the one currently stored and validated, without writing anything. In the second, an
+// intent naming every write is recorded in |fs|, the writes are made, recording what each wrote in the intent as it
+// lands, and the intent is removed. If a write fails, the writes already made are rolled back. A server which stops during the second phase rolls the writes back when it
+// next starts, see RecoverTransactionIntents.
+func (tx *DoltTransaction) commitAll(ctx *sql.Context, fs filesys.Filesys, writes []multiDbWrite) error {
+	if fs == nil {
+		return errNoIntentFilesys
+	}
+	type target struct {
+		multiDbWrite
+		startPoint dbRoot
+		startState *doltdb.WorkingSet
+		mergeOpts  editor.Options
+	}
+	targets := make([]target, len(writes))
+	for i, w := range writes {
+		startPoint, startState, mergeOpts, err := tx.commitStartState(ctx, w.workingSet, w.dbName)
+		if err != nil {
+			return err
+		}
+		targets[i] = target{w, startPoint, startState, mergeOpts}
+	}
+	// Write in a consistent order, so that the intent and any rollback are deterministic.
+	sort.Slice(targets, func(i, j int) bool {
+		return strings.ToLower(targets[i].startPoint.dbName) < strings.ToLower(targets[j].startPoint.dbName)
+	})
+
+	for i := 0; i < maxTxCommitRetries; i++ {
+		done, rscs, err := func() (bool, []doltdb.ReplicationStatusController, error) {
+			// Serialize commits, since only one can possibly succeed at a time anyway
+			txLock.Lock()
+			defer txLock.Unlock()
+
+			if err := tx.validateReads(ctx); err != nil {
+				return false, nil, err
+			}
+
+			// Phase one: merge and validate every working set.
+			prepared := make([]preparedCommit, len(targets))
+			intent := transactionIntent{Writes: make([]intentWrite, len(targets))}
+			for i, t := range targets {
+				var err error
+				prepared[i], err = tx.prepareCommit(ctx, t.startPoint, t.startState, t.workingSet, t.mergeOpts)
+				if err != nil {
+					return false, nil, err
+				}
+				prevRoot, err := t.startPoint.db.NomsRoot(ctx)
+				if err != nil {
+					return false, nil, err
+				}
+				intent.Writes[i] = intentWrite{
+					Database:   t.startPoint.dbName,
+					WorkingSet: t.workingSet.Ref().String(),
+					DoltCommit: t.commit != nil,
+					PrevRoot:   prevRoot.String(),
+				}
+			}
+
+			// Phase two: record the intent, make every write, then remove the intent.
+			if err := writeTransactionIntent(fs, intent); err != nil {
+				return false, nil, err
+			}
+			rollback := func(n int, cause error) error {
+				for j := 0; j < n; j++ {
+					if err := intent.Writes[j].restore(ctx, targets[j].startPoint.db); err != nil {
+						// Leave the intent in place, so that the rollback is completed when the server restarts.
+						return fmt.Errorf("%w; additionally, rolling back the writes to the other databases of this transaction failed: %v", cause, err)
+					}
+				}
+				if err := removeTransactionIntent(fs); err != nil {
+					return fmt.Errorf("%w; additionally, removing the transaction intent failed: %v", cause, err)
+				}
+				return cause
+			}
+
+			rscs := make([]doltdb.ReplicationStatusController, len(targets))
+			for i, t := range targets {
+				writeFn := txCommit
+				if t.commit != nil {
+					writeFn = doltCommit
+				}
+				_, cm, err := writeFn(ctx, tx, t.startPoint.db, t.startState, t.commit, prepared[i].workingSet, prepared[i].existingWSHash, t.mergeOpts, &rscs[i])
+				if err != nil {
+					// The failed write changed nothing, so only the writes before it are rolled back. Restoring its
+					// database could undo the write which made it fail.
+					err = rollback(i, err)
+					if err == datas.ErrOptimisticLockFailed {
+						// this is effectively a `continue` in the loop
+						return false, nil, nil
+					}
+					return false, nil, err
+				}
+				if err := intent.Writes[i].recordWritten(ctx, t.startPoint.db, cm); err != nil {
+					return false, nil, rollback(i+1, err)
+				}
+				if err := writeTransactionIntent(fs, intent); err != nil {
+					return false, nil, rollback(i+1, err)
+				}
+			}
+			if err := removeTransactionIntent(fs); err != nil {
+				return false, nil, rollback(len(targets), err)
+			}
+			return true, rscs, nil
+		}()
+
+		if err != nil {
+			return err
+		} else if done {
+			var waitErr error
+			for _, rsc := range rscs {
+				if err := WaitForReplicationController(ctx, rsc); waitErr == nil {
+					waitErr = err
+				}
+			}
+			return waitErr
+		}
+	}
+
+	// TODO: different error type for retries exhausted
+	return datas.ErrOptimisticLockFailed
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+)
+
+func TestTransactionIntentRestore(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	t.Cleanup(func() {
+		dEnv.DoltDB.Close()
+	})
+	ddb := dEnv.DoltDB
+
+	headRef := ref.NewBranchRef("main")
+	wsRef, err := ref.WorkingSetRefForHead(headRef)
+	require.NoError(t, err)
+	prevRoot, err := ddb.NomsRoot(ctx)
+	require.NoError(t, err)
+	prevHead, err := ddb.GetHashForRefStr(ctx, headRef.String())
+	require.NoError(t, err)
+	ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+	require.NoError(t, err)
+	prevWsHash, err := ws.HashOf()
+	require.NoError(t, err)
+
+	fs := filesys.NewInMemFS(nil, nil, "/")
+	intent := transactionIntent{Writes: []intentWrite{{
+		Database:   "mydb",
+		WorkingSet: wsRef.String(),
+		DoltCommit: true,
+		PrevRoot:   prevRoot.String(),
+	}}}
+	require.NoError(t, writeTransactionIntent(fs, intent))
+
+	// Make the write the intent describes: a dolt commit on main and a new working set.
+	newRoot, err := ws.WorkingRoot().SetCollation(ctx, schema.Collation_utf8mb4_0900_ai_ci)
+	require.NoError(t, err)
+	newRoot, valHash, err := ddb.WriteRootValue(ctx, newRoot)
+	require.NoError(t, err)
+	cm, err := datas.NewCommitMeta("test", "test@example.com", "partially committed")
+	require.NoError(t, err)
+	_, err = ddb.Commit(ctx, valHash, headRef, cm)
+	require.NoError(t, err)
+	wsMeta := &datas.WorkingSetMeta{Timestamp: uint64(time.Now().Unix())}
+	require.NoError(t, ddb.UpdateWorkingSet(ctx, wsRef, ws.WithWorkingRoot(newRoot).WithStagedRoot(newRoot), prevWsHash, wsMeta, nil))
+
+	data, err := fs.ReadFile(transactionIntentFile)
+	require.NoError(t, err)
+	var read transactionIntent
+	require.NoError(t, json.Unmarshal(data, &read))
+	require.Equal(t, intent, read)
+
+	// Restoring is idempotent, since recovery may itself be interrupted.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, read.Writes[0].restore(ctx, ddb))
+
+		head, err := ddb.GetHashForRefStr(ctx, headRef.String())
+		require.NoError(t, err)
+		assert.Equal(t, *prevHead, *head)
+		ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+		require.NoError(t, err)
+		wsRoot, err := ws.WorkingRoot().HashOf()
+		require.NoError(t, err)
+		prevWsRoot, err := ddb.ResolveWorkingSetAtRoot(ctx, wsRef, prevRoot)
+		require.NoError(t, err)
+		prevWsRootHash, err := prevWsRoot.WorkingRoot().HashOf()
+		require.NoError(t, err)
+		assert.Equal(t, prevWsRootHash, wsRoot)
+	}
+
+	require.NoError(t, removeTransactionIntent(fs))
+	exists, _ := fs.Exists(transactionIntentFile)
+	assert.False(t, exists)
+
+	assert.NoError(t, RecoverTransactionIntents(ctx, fs, nil), "no intent to recover")
+}
+
+func TestTransactionIntentRestoreDeletedWorkingSet(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	t.Cleanup(func() {
+		dEnv.DoltDB.Close()
+	})
+	ddb := dEnv.DoltDB
+
+	wsRef, err := ref.WorkingSetRefForHead(ref.NewBranchRef("main"))
+	require.NoError(t, err)
+	prevRoot, err := ddb.NomsRoot(ctx)
+	require.NoError(t, err)
+	// The working set was deleted after the intent was written.
+	require.NoError(t, ddb.DeleteWorkingSet(ctx, wsRef))
+	_, err = ddb.ResolveWorkingSet(ctx, wsRef)
+	require.ErrorIs(t, err, doltdb.ErrWorkingSetNotFound)
+
+	w := intentWrite{
+		Database:   "mydb",
+		WorkingSet: wsRef.String(),
+		PrevRoot:   prevRoot.String(),
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, w.restore(ctx, ddb))
+
+		ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+		require.NoError(t, err)
+		prevWorking, err := ddb.ResolveWorkingSetAtRoot(ctx, wsRef, prevRoot)
+		require.NoError(t, err)
+		prevWorkingHash, err := prevWorking.WorkingRoot().HashOf()
+		require.NoError(t, err)
+		workingHash, err := ws.WorkingRoot().HashOf()
+		require.NoError(t, err)
+		assert.Equal(t, prevWorkingHash, workingHash)
+	}
+}
+
+func TestTransactionIntentRestoreKeepsLaterWrites(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	t.Cleanup(func() {
+		dEnv.DoltDB.Close()
+	})
+	ddb := dEnv.DoltDB
+
+	headRef := ref.NewBranchRef("main")
+	wsRef, err := ref.WorkingSetRefForHead(headRef)
+	require.NoError(t, err)
+	prevRoot, err := ddb.NomsRoot(ctx)
+	require.NoError(t, err)
+	w := intentWrite{
+		Database:   "mydb",
+		WorkingSet: wsRef.String(),
+		DoltCommit: true,
+		PrevRoot:   prevRoot.String(),
+	}
+
+	// commit makes a dolt commit on main and a new working set with the collation given.
+	commit := func(collation schema.Collation) *doltdb.Commit {
+		ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+		require.NoError(t, err)
+		wsHash, err := ws.HashOf()
+		require.NoError(t, err)
+		newRoot, err := ws.WorkingRoot().SetCollation(ctx, collation)
+		require.NoError(t, err)
+		newRoot, valHash, err := ddb.WriteRootValue(ctx, newRoot)
+		require.NoError(t, err)
+		cm, err := datas.NewCommitMeta("test", "test@example.com", "commit")
+		require.NoError(t, err)
+		dcm, err := ddb.Commit(ctx, valHash, headRef, cm)
+		require.NoError(t, err)
+		wsMeta := &datas.WorkingSetMeta{Timestamp: uint64(time.Now().Unix())}
+		require.NoError(t, ddb.UpdateWorkingSet(ctx, wsRef, ws.WithWorkingRoot(newRoot).WithStagedRoot(newRoot), wsHash, wsMeta, nil))
+		return dcm
+	}
+
+	require.NoError(t, w.recordWritten(ctx, ddb, commit(schema.Collation_utf8mb4_0900_ai_ci)))
+	assert.NotEmpty(t, w.WrittenWorkingSet)
+	assert.NotEmpty(t, w.WrittenHead)
+
+	// Another transaction commits after the write, so restoring must not undo its write.
+	commit(schema.Collation_utf8mb4_bin)
+	laterHead, err := ddb.GetHashForRefStr(ctx, headRef.String())
+	require.NoError(t, err)
+	laterWs, err := ddb.ResolveWorkingSet(ctx, wsRef)
+	require.NoError(t, err)
+	laterWsHash, err := laterWs.HashOf()
+	require.NoError(t, err)
+
+	require.NoError(t, w.restore(ctx, ddb))
+	head, err := ddb.GetHashForRefStr(ctx, headRef.String())
+	require.NoError(t, err)
+	assert.Equal(t, *laterHead, *head)
+	ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+	require.NoError(t, err)
+	wsHash, err := ws.HashOf()
+	require.NoError(t, err)
+	assert.Equal(t, laterWsHash, wsHash)
+}
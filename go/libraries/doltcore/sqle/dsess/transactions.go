@@ -83,6 +83,8 @@ type DoltTransaction struct {
 	dbStartPoints   map[string]dbRoot
 	savepoints      []savepoint
 	tCharacteristic sql.TransactionCharacteristic
+	// reads is the set of rows read by this transaction, tracked only for SERIALIZABLE transactions
+	reads *readSet
 }
 
 type dbRoot struct {
@@ -118,10 +120,14 @@ func NewDoltTransaction(
 		}
 	}
 
-	return &DoltTransaction{
+	tx := &DoltTransaction{
 		dbStartPoints:   startPoints,
 		tCharacteristic: tCharacteristic,
-	}, nil
+	}
+	if isSerializable(ctx) {
+		tx.reads = newReadSet()
+	}
+	return tx, nil
 }
 
 // AddDb adds the database named to the transaction. Only necessary in the case when new databases are added to an
@@ -182,6 +188,7 @@ type transactionWrite func(ctx *sql.Context,
 	workingSet *doltdb.WorkingSet, // must be provided
 	hash hash.Hash, // hash of the current working set to be written
 	mergeOps editor.Options, // editor options for merges
+	rsc *doltdb.ReplicationStatusController, // filled in with the replicas of the write
 ) (*doltdb.WorkingSet, *doltdb.Commit, error)
 
 // doltCommit is a transactionWrite function that updates the working set and commits a pending commit atomically
@@ -193,6 +200,7 @@ func doltCommit(ctx *sql.Context,
 	workingSet *doltdb.WorkingSet, // must be provided
 	currHash hash.Hash, // hash of the current working set to be written
 	mergeOpts editor.Options, // editor options for merges
+	rsc *doltdb.ReplicationStatusController, // filled in with the replicas of the write
 ) (*doltdb.WorkingSet, *doltdb.Commit, error) {
 	pending := *commit
 
@@ -263,9 +271,7 @@ func doltCommit(ctx *sql.Context,
 
 	workingSet = workingSet.ClearMerge()
 
-	var rsc doltdb.ReplicationStatusController
-	newCommit, err := doltDb.CommitWithWorkingSet(ctx, headRef, workingSet.Ref(), &pending, workingSet, currHash, tx.WorkingSetMeta(ctx), &rsc)
-	WaitForReplicationController(ctx, rsc)
+	newCommit, err := doltDb.CommitWithWorkingSet(ctx, headRef, workingSet.Ref(), &pending, workingSet, currHash, tx.WorkingSetMeta(ctx), rsc)
 	return workingSet, newCommit, err
 }
 
@@ -278,10 +284,9 @@ func txCommit(ctx *sql.Context,
 	workingSet *doltdb.WorkingSet, // must be provided
 	hash hash.Hash, // hash of the current working set to be written
 	_ editor.Options, // editor options for merges
+	rsc *doltdb.ReplicationStatusController, // filled in with the replicas of the write
 ) (*doltdb.WorkingSet, *doltdb.Commit, error) {
-	var rsc doltdb.ReplicationStatusController
-	err := doltDb.UpdateWorkingSet(ctx, workingSet.Ref(), workingSet, hash, tx.WorkingSetMeta(ctx), &rsc)
-	WaitForReplicationController(ctx, rsc)
+	err := doltDb.UpdateWorkingSet(ctx, workingSet.Ref(), workingSet, hash, tx.WorkingSetMeta(ctx), rsc)
 	return workingSet, nil, err
 }
 
@@ -295,22 +300,34 @@ func (tx *DoltTransaction) DoltCommit(
 	return tx.doCommit(ctx, workingSet, commit, doltCommit, dbName)
 }
 
-func WaitForReplicationController(ctx *sql.Context, rsc doltdb.ReplicationStatusController) {
+// WaitForReplicationController waits for the replication of a write to the
+// replicas behind |rsc|, for up to dolt_cluster_ack_writes_timeout_secs. By
+// default it waits for every replica and turns failures into warnings. If
+// |rsc| has a Quorum, it returns as soon as enough replicas acknowledge the
+// write, and a strict quorum which is not reached is returned as an error.
+func WaitForReplicationController(ctx *sql.Context, rsc doltdb.ReplicationStatusController) error {
 	if len(rsc.Wait) == 0 {
-		return
+		return nil
 	}
 	_, timeout, ok := sql.SystemVariables.GetGlobal(DoltClusterAckWritesTimeoutSecs)
 	if !ok {
-		return
+		return nil
 	}
 	timeoutI := timeout.(int64)
 	if timeoutI == 0 {
-		return
+		return nil
+	}
+
+	required := len(rsc.Wait)
+	if rsc.Quorum != nil && rsc.Quorum.Acks > 0 {
+		required = rsc.Quorum.Acks
 	}
 
+	start := time.Now()
 	cCtx, cancel := context.WithCancelCause(ctx)
 	var wg sync.WaitGroup
 	wg.Add(len(rsc.Wait))
+	acks := make(chan bool, len(rsc.Wait))
 	for i, f := range rsc.Wait {
 		f := f
 		i := i
@@ -320,46 +337,85 @@ func WaitForReplicationController(ctx *sql.Context, rsc doltdb.ReplicationStatus
 			if err == nil {
 				rsc.Wait[i] = nil
 			}
+			acks <- err == nil
 		}()
 	}
 
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	timer := time.NewTimer(time.Duration(timeoutI) * time.Second)
+	defer timer.Stop()
 
+	numAcked, numFinished := 0, 0
 	waitFailed := false
-	select {
-	case <-time.After(time.Duration(timeoutI) * time.Second):
-		// We timed out before all the waiters were done.
-		// First we make certain to finalize everything.
+	for numAcked < required && numFinished < len(rsc.Wait) && !waitFailed {
+		select {
+		case <-timer.C:
+			// We timed out before all the waiters were done.
+			waitFailed = true
+		case acked := <-acks:
+			numFinished += 1
+			if acked {
+				numAcked += 1
+			}
+		}
+	}
+	waited := time.Since(start)
+
+	// Make certain to finalize everything. Waiters which are still
+	// running after we reached our quorum did not fail; they are just no
+	// longer being waited on.
+	if waitFailed {
 		cancel(doltdb.ErrReplicationWaitFailed)
-		<-done
-		waitFailed = true
-	case <-done:
+	} else {
 		cancel(context.Canceled)
 	}
+	wg.Wait()
+	quorumReached := numAcked >= required
 
-	// Just because our waiters all completed does not mean they all
-	// returned nil errors. Any non-nil entries in rsc.Wait returned an
-	// error. We turn those into warnings here.
+	// Any non-nil entries in rsc.Wait did not complete successfully.
 	numFailed := 0
+	var acked []string
 	for i, f := range rsc.Wait {
 		if f != nil {
 			numFailed += 1
 			if waitFailed {
 				rsc.NotifyWaitFailed[i]()
 			}
+		} else if i < len(rsc.Replicas) {
+			acked = append(acked, rsc.Replicas[i])
+		}
+	}
+
+	if rsc.Quorum == nil {
+		// Failures are turned into warnings here.
+		if numFailed > 0 {
+			warnReplicationTimeout(ctx, numFailed, len(rsc.Wait))
 		}
+		return nil
 	}
-	if numFailed > 0 {
-		ctx.Session.Warn(&sql.Warning{
-			Level:   "Warning",
-			Code:    mysql.ERQueryTimeout,
-			Message: fmt.Sprintf("Timed out replication of commit to %d out of %d replicas.", numFailed, len(rsc.Wait)),
+
+	if rsc.Quorum.Report != nil {
+		rsc.Quorum.Report(doltdb.ReplicationWaitResult{
+			Duration:      waited,
+			Acked:         acked,
+			QuorumReached: quorumReached,
 		})
 	}
+	if quorumReached {
+		return nil
+	}
+	if rsc.Quorum.Strict {
+		return fmt.Errorf("%w: %d of the %d required replicas acknowledged the commit within %d seconds. the commit was applied on this server, but may not be durable", doltdb.ErrReplicationQuorumNotReached, numAcked, required, timeoutI)
+	}
+	warnReplicationTimeout(ctx, numFailed, len(rsc.Wait))
+	return nil
+}
+
+func warnReplicationTimeout(ctx *sql.Context, numFailed, numReplicas int) {
+	ctx.Session.Warn(&sql.Warning{
+		Level:   "Warning",
+		Code:    mysql.ERQueryTimeout,
+		Message: fmt.Sprintf("Timed out replication of commit to %d out of %d replicas.", numFailed, numReplicas),
+	})
 }
 
 // doCommit commits this transaction with the write function provided. It takes the same params as DoltCommit
@@ -370,88 +426,33 @@ func (tx *DoltTransaction) doCommit(
 	writeFn transactionWrite,
 	dbName string,
 ) (*doltdb.WorkingSet, *doltdb.Commit, error) {
-	sess := DSessFromSess(ctx.Session)
-	branchState, ok, err := sess.lookupDbState(ctx, dbName)
-	if err != nil {
-		return nil, nil, err
-	}
-	if !ok {
-		return nil, nil, fmt.Errorf("database %s unknown to transaction, this is a bug", dbName)
-	}
-
-	// Load the start state for this working set from the noms root at tx start
-	// Get the base DB name from the db state, not the branch state
-	startPoint, ok := tx.dbStartPoints[strings.ToLower(branchState.dbState.dbName)]
-	if !ok {
-		return nil, nil, fmt.Errorf("database %s unknown to transaction, this is a bug", dbName)
-	}
-
-	startState, err := startPoint.db.ResolveWorkingSetAtRoot(ctx, workingSet.Ref(), startPoint.rootHash)
+	startPoint, startState, mergeOpts, err := tx.commitStartState(ctx, workingSet, dbName)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// TODO: no-op if the working set hasn't changed since the transaction started
 
-	mergeOpts := branchState.EditOpts()
-
 	for i := 0; i < maxTxCommitRetries; i++ {
 		updatedWs, newCommit, err := func() (*doltdb.WorkingSet, *doltdb.Commit, error) {
 			// Serialize commits, since only one can possibly succeed at a time anyway
 			txLock.Lock()
 			defer txLock.Unlock()
 
-			newWorkingSet := false
-
-			existingWs, err := startPoint.db.ResolveWorkingSet(ctx, workingSet.Ref())
-			if err == doltdb.ErrWorkingSetNotFound {
-				// This is to handle the case where an existing DB pre working sets is committing to this HEAD for the
-				// first time. Can be removed and called an error post 1.0
-				existingWs = doltdb.EmptyWorkingSet(workingSet.Ref())
-				newWorkingSet = true
-			} else if err != nil {
-				return nil, nil, err
-			}
-
-			existingWSHash, err := existingWs.HashOf()
-			if err != nil {
+			if err := tx.validateReads(ctx); err != nil {
 				return nil, nil, err
 			}
 
-			if newWorkingSet || workingAndStagedEqual(existingWs, startState) {
-				// ff merge
-				err = tx.validateWorkingSetForCommit(ctx, workingSet, isFfMerge)
-				if err != nil {
-					return nil, nil, err
-				}
-
-				var newCommit *doltdb.Commit
-				workingSet, newCommit, err = writeFn(ctx, tx, startPoint.db, startState, commit, workingSet, existingWSHash, mergeOpts)
-				if err == datas.ErrOptimisticLockFailed {
-					// this is effectively a `continue` in the loop
-					return nil, nil, nil
-				} else if err != nil {
-					return nil, nil, err
-				}
-
-				return workingSet, newCommit, nil
-			}
-
-			// otherwise (not a ff), merge the working sets together
-			start := time.Now()
-			mergedWorkingSet, err := tx.mergeRoots(ctx, startState, existingWs, workingSet, mergeOpts)
+			prepared, err := tx.prepareCommit(ctx, startPoint, startState, workingSet, mergeOpts)
 			if err != nil {
 				return nil, nil, err
 			}
-			logrus.Tracef("working set merge took %s", time.Since(start))
 
-			err = tx.validateWorkingSetForCommit(ctx, mergedWorkingSet, notFfMerge)
-			if err != nil {
-				return nil, nil, err
+			var rsc doltdb.ReplicationStatusController
+			updatedWs, newCommit, err := writeFn(ctx, tx, startPoint.db, startState, commit, prepared.workingSet, prepared.existingWSHash, mergeOpts, &rsc)
+			if waitErr := WaitForReplicationController(ctx, rsc); err == nil {
+				err = waitErr
 			}
-
-			var newCommit *doltdb.Commit
-			mergedWorkingSet, newCommit, err = writeFn(ctx, tx, startPoint.db, startState, commit, mergedWorkingSet, existingWSHash, mergeOpts)
 			if err == datas.ErrOptimisticLockFailed {
 				// this is effectively a `continue` in the loop
 				return nil, nil, nil
@@ -459,7 +460,7 @@ func (tx *DoltTransaction) doCommit(
 				return nil, nil, err
 			}
 
-			return mergedWorkingSet, newCommit, nil
+			return updatedWs, newCommit, nil
 		}()
 
 		if err != nil {
@@ -473,6 +474,99 @@ func (tx *DoltTransaction) doCommit(
 	return nil, nil, datas.ErrOptimisticLockFailed
 }
 
+// commitStartState returns the start point of this transaction for the database |dbName|, the working set |workingSet|
+// had at that start point, and the editor options for merges into it.
+func (tx *DoltTransaction) commitStartState(
+	ctx *sql.Context,
+	workingSet *doltdb.WorkingSet,
+	dbName string,
+) (dbRoot, *doltdb.WorkingSet, editor.Options, error) {
+	sess := DSessFromSess(ctx.Session)
+	branchState, ok, err := sess.lookupDbState(ctx, dbName)
+	if err != nil {
+		return dbRoot{}, nil, editor.Options{}, err
+	}
+	if !ok {
+		return dbRoot{}, nil, editor.Options{}, fmt.Errorf("database %s unknown to transaction, this is a bug", dbName)
+	}
+
+	// Load the start state for this working set from the noms root at tx start
+	// Get the base DB name from the db state, not the branch state
+	startPoint, ok := tx.dbStartPoints[strings.ToLower(branchState.dbState.dbName)]
+	if !ok {
+		return dbRoot{}, nil, editor.Options{}, fmt.Errorf("database %s unknown to transaction, this is a bug", dbName)
+	}
+
+	startState, err := startPoint.db.ResolveWorkingSetAtRoot(ctx, workingSet.Ref(), startPoint.rootHash)
+	if err != nil {
+		return dbRoot{}, nil, editor.Options{}, err
+	}
+
+	return startPoint, startState, branchState.EditOpts(), nil
+}
+
+// preparedCommit is a working set which has been merged with the working set currently stored in its database, and
+// validated, so that it is ready to be written.
+type preparedCommit struct {
+	// The working set to write.
+	workingSet *doltdb.WorkingSet
+	// The hash of the working set currently stored in the database, which the write replaces.
+	existingWSHash hash.Hash
+}
+
+// prepareCommit merges |workingSet| into the working set currently stored in the database of |startPoint|, fast
+// forwarding if that working set has not changed since |startState|, and validates the result for commit.
+//
+// called with txLock held
+func (tx *DoltTransaction) prepareCommit(
+	ctx *sql.Context,
+	startPoint dbRoot,
+	startState *doltdb.WorkingSet,
+	workingSet *doltdb.WorkingSet,
+	mergeOpts editor.Options,
+) (preparedCommit, error) {
+	newWorkingSet := false
+
+	existingWs, err := startPoint.db.ResolveWorkingSet(ctx, workingSet.Ref())
+	if err == doltdb.ErrWorkingSetNotFound {
+		// This is to handle the case where an existing DB pre working sets is committing to this HEAD for the
+		// first time. Can be removed and called an error post 1.0
+		existingWs = doltdb.EmptyWorkingSet(workingSet.Ref())
+		newWorkingSet = true
+	} else if err != nil {
+		return preparedCommit{}, err
+	}
+
+	existingWSHash, err := existingWs.HashOf()
+	if err != nil {
+		return preparedCommit{}, err
+	}
+
+	if newWorkingSet || workingAndStagedEqual(existingWs, startState) {
+		// ff merge
+		err = tx.validateWorkingSetForCommit(ctx, workingSet, isFfMerge)
+		if err != nil {
+			return preparedCommit{}, err
+		}
+		return preparedCommit{workingSet: workingSet, existingWSHash: existingWSHash}, nil
+	}
+
+	// otherwise (not a ff), merge the working sets together
+	start := time.Now()
+	mergedWorkingSet, err := tx.mergeRoots(ctx, startState, existingWs, workingSet, mergeOpts)
+	if err != nil {
+		return preparedCommit{}, err
+	}
+	logrus.Tracef("working set merge took %s", time.Since(start))
+
+	err = tx.validateWorkingSetForCommit(ctx, mergedWorkingSet, notFfMerge)
+	if err != nil {
+		return preparedCommit{}, err
+	}
+
+	return preparedCommit{workingSet: mergedWorkingSet, existingWSHash: existingWSHash}, nil
+}
+
 // mergeRoots merges the roots in the existing working set with the one being committed and returns the resulting
 // working set. Conflicts are automatically resolved with "accept ours" if the session settings dictate it.
 // Currently merges working and staged roots as necessary. HEAD root is only handled by the DoltCommit function.
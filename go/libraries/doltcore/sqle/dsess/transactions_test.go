@@ -0,0 +1,145 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+)
+
+func TestWaitForReplicationController(t *testing.T) {
+	if _, _, ok := sql.SystemVariables.GetGlobal(DoltClusterAckWritesTimeoutSecs); !ok {
+		sql.SystemVariables.AddSystemVariables([]sql.SystemVariable{
+			&sql.MysqlSystemVariable{
+				Name:    DoltClusterAckWritesTimeoutSecs,
+				Dynamic: true,
+				Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Global),
+				Type:    types.NewSystemIntType(DoltClusterAckWritesTimeoutSecs, 0, 60, false),
+				Default: int64(0),
+			},
+		})
+	}
+	require.NoError(t, sql.SystemVariables.SetGlobal(DoltClusterAckWritesTimeoutSecs, int64(1)))
+	t.Cleanup(func() {
+		sql.SystemVariables.SetGlobal(DoltClusterAckWritesTimeoutSecs, int64(0))
+	})
+
+	acks := func(ctx context.Context) error {
+		return nil
+	}
+	fails := func(ctx context.Context) error {
+		return errors.New("replication failed")
+	}
+	blocks := func(ctx context.Context) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}
+	newRsc := func(waits map[string]func(context.Context) error, notified map[string]bool) doltdb.ReplicationStatusController {
+		var rsc doltdb.ReplicationStatusController
+		for name, wait := range waits {
+			name := name
+			rsc.Wait = append(rsc.Wait, wait)
+			rsc.Replicas = append(rsc.Replicas, name)
+			rsc.NotifyWaitFailed = append(rsc.NotifyWaitFailed, func() {
+				notified[name] = true
+			})
+		}
+		return rsc
+	}
+	newCtx := func() *sql.Context {
+		return sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+	}
+
+	t.Run("WithoutQuorumWarnsOnFailure", func(t *testing.T) {
+		ctx := newCtx()
+		notified := make(map[string]bool)
+		rsc := newRsc(map[string]func(context.Context) error{"a": acks, "b": fails}, notified)
+		require.NoError(t, WaitForReplicationController(ctx, rsc))
+		assert.Len(t, ctx.Session.Warnings(), 1)
+		assert.Empty(t, notified)
+	})
+	t.Run("QuorumReachedDoesNotWaitForOthers", func(t *testing.T) {
+		ctx := newCtx()
+		notified := make(map[string]bool)
+		rsc := newRsc(map[string]func(context.Context) error{"a": acks, "b": blocks}, notified)
+		var result doltdb.ReplicationWaitResult
+		rsc.Quorum = &doltdb.ReplicationQuorum{
+			Acks:   1,
+			Strict: true,
+			Report: func(res doltdb.ReplicationWaitResult) {
+				result = res
+			},
+		}
+		start := time.Now()
+		require.NoError(t, WaitForReplicationController(ctx, rsc))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Empty(t, ctx.Session.Warnings())
+		assert.Empty(t, notified)
+		assert.True(t, result.QuorumReached)
+		assert.Equal(t, []string{"a"}, result.Acked)
+	})
+	t.Run("StrictQuorumNotReached", func(t *testing.T) {
+		ctx := newCtx()
+		notified := make(map[string]bool)
+		rsc := newRsc(map[string]func(context.Context) error{"a": acks, "b": blocks, "c": fails}, notified)
+		var result doltdb.ReplicationWaitResult
+		rsc.Quorum = &doltdb.ReplicationQuorum{
+			Acks:   2,
+			Strict: true,
+			Report: func(res doltdb.ReplicationWaitResult) {
+				result = res
+			},
+		}
+		err := WaitForReplicationController(ctx, rsc)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, doltdb.ErrReplicationQuorumNotReached))
+		assert.False(t, result.QuorumReached)
+		assert.Equal(t, []string{"a"}, result.Acked)
+		assert.GreaterOrEqual(t, result.Duration, time.Second)
+		assert.Equal(t, map[string]bool{"b": true, "c": true}, notified)
+	})
+	t.Run("StrictQuorumNotReachedWithoutTimeout", func(t *testing.T) {
+		ctx := newCtx()
+		notified := make(map[string]bool)
+		rsc := newRsc(map[string]func(context.Context) error{"a": acks, "b": fails}, notified)
+		rsc.Quorum = &doltdb.ReplicationQuorum{
+			Strict: true,
+		}
+		start := time.Now()
+		err := WaitForReplicationController(ctx, rsc)
+		assert.True(t, errors.Is(err, doltdb.ErrReplicationQuorumNotReached))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Empty(t, notified)
+	})
+	t.Run("QuorumNotReachedWarns", func(t *testing.T) {
+		ctx := newCtx()
+		notified := make(map[string]bool)
+		rsc := newRsc(map[string]func(context.Context) error{"a": fails, "b": fails}, notified)
+		rsc.Quorum = &doltdb.ReplicationQuorum{
+			Acks: 1,
+		}
+		require.NoError(t, WaitForReplicationController(ctx, rsc))
+		assert.Len(t, ctx.Session.Warnings(), 1)
+	})
+}
@@ -54,10 +54,12 @@ const (
 	ShowBranchDatabases                  = "dolt_show_branch_databases"
 	DoltLogLevel                         = "dolt_log_level"
 	ShowSystemTables                     = "dolt_show_system_tables"
+	InnodbLockWaitTimeout                = "innodb_lock_wait_timeout"
 
 	DoltClusterRoleVariable         = "dolt_cluster_role"
 	DoltClusterRoleEpochVariable    = "dolt_cluster_role_epoch"
 	DoltClusterAckWritesTimeoutSecs = "dolt_cluster_ack_writes_timeout_secs"
+	DoltMaxReplicaLagMs             = "dolt_max_replica_lag_ms"
 
 	DoltStatsAutoRefreshEnabled   = "dolt_stats_auto_refresh_enabled"
 	DoltStatsBootstrapEnabled     = "dolt_stats_bootstrap_enabled"
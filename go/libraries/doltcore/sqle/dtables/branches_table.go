@@ -153,6 +153,10 @@ func NewBranchItr(ctx *sql.Context, table *BranchesTable) (*BranchItr, error) {
 		if err != nil {
 			return nil, err
 		}
+		branchRefs, err = dsess.ReadableBranches(ctx, db.Name(), branchRefs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	branchNames := make([]string, len(branchRefs))
@@ -141,6 +141,10 @@ func (dt *ColumnDiffTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLo
 			return nil, fmt.Errorf("failed to parse commit lookup ranges: %s", sql.DebugString(lookup.Ranges))
 		}
 		hashes, commits, metas := index.HashesToCommits(ctx, dt.ddb, hs, dt.head, false)
+		hashes, commits, metas, err := filterReadableCommits(ctx, dt.dbName, dt.ddb, hashes, commits, metas)
+		if err != nil {
+			return nil, err
+		}
 		if len(hashes) == 0 {
 			return sql.PartitionsToPartitionIter(), nil
 		}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 )
 
@@ -95,7 +96,7 @@ func (dt *CommitAncestorsTable) PartitionRows(ctx *sql.Context, p sql.Partition)
 			ddb: dt.ddb,
 		}, nil
 	default:
-		return NewCommitAncestorsRowItr(ctx, dt.ddb)
+		return NewCommitAncestorsRowItr(ctx, dt.dbName, dt.ddb)
 	}
 }
 
@@ -123,6 +124,10 @@ func (dt *CommitAncestorsTable) LookupPartitions(ctx *sql.Context, lookup sql.In
 		}
 
 		hashes, commits, metas := index.HashesToCommits(ctx, dt.ddb, hs, nil, false)
+		hashes, commits, metas, err := filterReadableCommits(ctx, dt.dbName, dt.ddb, hashes, commits, metas)
+		if err != nil {
+			return nil, err
+		}
 		if len(hashes) == 0 {
 			return sql.PartitionsToPartitionIter(), nil
 		}
@@ -141,9 +146,10 @@ type CommitAncestorsRowItr struct {
 	cache []sql.Row
 }
 
-// NewCommitAncestorsRowItr creates a CommitAncestorsRowItr from the current environment.
-func NewCommitAncestorsRowItr(sqlCtx *sql.Context, ddb *doltdb.DoltDB) (*CommitAncestorsRowItr, error) {
-	itr, err := doltdb.CommitItrForAllBranches(sqlCtx, ddb)
+// NewCommitAncestorsRowItr creates a CommitAncestorsRowItr over the commits of the branches the current user may read
+// from.
+func NewCommitAncestorsRowItr(sqlCtx *sql.Context, dbName string, ddb *doltdb.DoltDB) (*CommitAncestorsRowItr, error) {
+	itr, err := dsess.CommitItrForReadableBranches(sqlCtx, dbName, ddb)
 	if err != nil {
 		return nil, err
 	}
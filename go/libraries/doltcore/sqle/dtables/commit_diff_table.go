@@ -26,6 +26,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/store/types"
@@ -276,6 +277,9 @@ func (dt *CommitDiffTable) rootValForHash(ctx *sql.Context, hashStr string) (dol
 		if !ok {
 			return nil, "", nil, doltdb.ErrGhostCommitEncountered
 		}
+		if err = dsess.CheckReadAccessForCommitSpec(ctx, dt.dbName, dt.ddb, cs, cm); err != nil {
+			return nil, "", nil, err
+		}
 
 		root, err = cm.GetRootValue(ctx)
 
@@ -0,0 +1,46 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// filterReadableCommits returns the commits of a commit hash lookup that the current user may read. Lookups by hash
+// don't walk from the branches of the database, so commits of branches the user may not read must be removed.
+func filterReadableCommits(ctx *sql.Context, dbName string, ddb *doltdb.DoltDB, hashes []hash.Hash, commits []*doltdb.Commit, metas []*datas.CommitMeta) ([]hash.Hash, []*doltdb.Commit, []*datas.CommitMeta, error) {
+	var readableHashes []hash.Hash
+	var readableCommits []*doltdb.Commit
+	var readableMetas []*datas.CommitMeta
+	for i, cm := range commits {
+		if cm != nil {
+			if err := dsess.CheckReadAccessForCommit(ctx, dbName, ddb, cm); branch_control.ErrCannotReadCommit.Is(err) {
+				continue
+			} else if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		readableHashes = append(readableHashes, hashes[i])
+		readableCommits = append(readableCommits, cm)
+		readableMetas = append(readableMetas, metas[i])
+	}
+	return readableHashes, readableCommits, readableMetas, nil
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/store/datas"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -97,7 +98,7 @@ func (dt *CommitsTable) PartitionRows(ctx *sql.Context, p sql.Partition) (sql.Ro
 	case *doltdb.CommitPart:
 		return sql.RowsToRowIter(formatCommitTableRow(p.Hash(), p.Meta())), nil
 	default:
-		return NewCommitsRowItr(ctx, dt.ddb)
+		return NewCommitsRowItr(ctx, dt.dbName, dt.ddb)
 	}
 }
 
@@ -123,6 +124,10 @@ func (dt *CommitsTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLooku
 			return nil, fmt.Errorf("failed to parse commit lookup ranges: %s", sql.DebugString(lookup.Ranges))
 		}
 		hashes, commits, metas := index.HashesToCommits(ctx, dt.ddb, hashStrs, nil, false)
+		hashes, commits, metas, err := filterReadableCommits(ctx, dt.dbName, dt.ddb, hashes, commits, metas)
+		if err != nil {
+			return nil, err
+		}
 		if len(hashes) == 0 {
 			return sql.PartitionsToPartitionIter(), nil
 		}
@@ -138,9 +143,9 @@ type CommitsRowItr struct {
 	itr doltdb.CommitItr
 }
 
-// NewCommitsRowItr creates a CommitsRowItr from the current environment.
-func NewCommitsRowItr(ctx *sql.Context, ddb *doltdb.DoltDB) (CommitsRowItr, error) {
-	itr, err := doltdb.CommitItrForAllBranches(ctx, ddb)
+// NewCommitsRowItr creates a CommitsRowItr over the commits of the branches the current user may read from.
+func NewCommitsRowItr(ctx *sql.Context, dbName string, ddb *doltdb.DoltDB) (CommitsRowItr, error) {
+	itr, err := dsess.CommitItrForReadableBranches(ctx, dbName, ddb)
 	if err != nil {
 		return CommitsRowItr{}, err
 	}
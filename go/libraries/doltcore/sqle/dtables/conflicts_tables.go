@@ -47,6 +47,16 @@ func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root
 	return newNomsConflictsTable(ctx, tbl, tblName, root, rs)
 }
 
+// NewReadOnlyConflictsTable returns a sql.Table over the conflicts recorded in |tbl|'s artifacts. Unlike
+// NewConflictsTable, the returned table has no backing source table or RootSetter, so it can be used to inspect
+// conflicts in a merged root that is never written to a working set. Only the __DOLT__ format is supported.
+func NewReadOnlyConflictsTable(ctx *sql.Context, tblName string, tbl *doltdb.Table, root doltdb.RootValue) (sql.Table, error) {
+	if !types.IsFormat_DOLT(tbl.Format()) {
+		return nil, fmt.Errorf("reading conflicts of table %s is not supported for format %s", tblName, tbl.Format().VersionString())
+	}
+	return newProllyConflictsTable(ctx, tbl, nil, tblName, root, nil)
+}
+
 func newNomsConflictsTable(ctx *sql.Context, tbl *doltdb.Table, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	rd, err := merge.NewConflictReader(ctx, tbl, tblName)
 	if err != nil {
@@ -113,14 +113,67 @@ func (ct ProllyConflictsTable) PartitionRows(ctx *sql.Context, part sql.Partitio
 }
 
 func (ct ProllyConflictsTable) Updater(ctx *sql.Context) sql.RowUpdater {
+	if ct.readOnly() {
+		return readOnlyConflictsWriter{tblName: ct.tblName}
+	}
 	ourUpdater := ct.sqlTable.Updater(ctx)
 	return newProllyConflictOurTableUpdater(ourUpdater, ct.versionMappings, ct.baseSch, ct.ourSch, ct.theirSch)
 }
 
 func (ct ProllyConflictsTable) Deleter(ctx *sql.Context) sql.RowDeleter {
+	if ct.readOnly() {
+		return readOnlyConflictsWriter{tblName: ct.tblName}
+	}
 	return newProllyConflictDeleter(ct)
 }
 
+// readOnly returns whether this table was created by NewReadOnlyConflictsTable, and so has no source table to update
+// or RootSetter to persist deletes with.
+func (ct ProllyConflictsTable) readOnly() bool {
+	return ct.sqlTable == nil || ct.rs == nil
+}
+
+// readOnlyConflictsWriter is the RowUpdater and RowDeleter of a read-only conflicts table, which returns an error for
+// every edit.
+type readOnlyConflictsWriter struct {
+	tblName string
+}
+
+var _ sql.RowUpdater = readOnlyConflictsWriter{}
+var _ sql.RowDeleter = readOnlyConflictsWriter{}
+
+func (w readOnlyConflictsWriter) err() error {
+	return fmt.Errorf("the conflicts of table %s are read-only", w.tblName)
+}
+
+// Update implements sql.RowUpdater.
+func (w readOnlyConflictsWriter) Update(*sql.Context, sql.Row, sql.Row) error {
+	return w.err()
+}
+
+// Delete implements sql.RowDeleter.
+func (w readOnlyConflictsWriter) Delete(*sql.Context, sql.Row) error {
+	return w.err()
+}
+
+// StatementBegin implements sql.TableEditor.
+func (w readOnlyConflictsWriter) StatementBegin(*sql.Context) {}
+
+// DiscardChanges implements sql.TableEditor.
+func (w readOnlyConflictsWriter) DiscardChanges(*sql.Context, error) error {
+	return nil
+}
+
+// StatementComplete implements sql.TableEditor.
+func (w readOnlyConflictsWriter) StatementComplete(*sql.Context) error {
+	return nil
+}
+
+// Close implements sql.Closer.
+func (w readOnlyConflictsWriter) Close(*sql.Context) error {
+	return nil
+}
+
 type prollyConflictRowIter struct {
 	itr     prolly.ConflictArtifactIter
 	tblName string
@@ -0,0 +1,307 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	sqlTypes "github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/masking"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+var _ sql.Table = (*MaskingTable)(nil)
+var _ sql.UpdatableTable = (*MaskingTable)(nil)
+var _ sql.DeletableTable = (*MaskingTable)(nil)
+var _ sql.InsertableTable = (*MaskingTable)(nil)
+var _ sql.ReplaceableTable = (*MaskingTable)(nil)
+var _ sql.IndexAddressableTable = (*MaskingTable)(nil)
+
+// MaskingTable is the dolt_masking system table. Each row is a masking rule, which names a column of a table and the
+// transform that masked dumps, exports and clones apply to its values. Rules are validated against the schema of the
+// working root when they are written.
+type MaskingTable struct {
+	backingTable VersionableTable
+}
+
+func (i *MaskingTable) Name() string {
+	return doltdb.MaskingTableName
+}
+
+func (i *MaskingTable) String() string {
+	return doltdb.MaskingTableName
+}
+
+// Schema implements sql.Table. A rule is keyed by the table and column it masks; the argument is only used by
+// some transforms.
+func (i *MaskingTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: masking.TableNameCol, Type: sqlTypes.Text, Source: doltdb.MaskingTableName, PrimaryKey: true},
+		{Name: masking.ColumnNameCol, Type: sqlTypes.Text, Source: doltdb.MaskingTableName, PrimaryKey: true},
+		{Name: masking.TransformCol, Type: sqlTypes.Text, Source: doltdb.MaskingTableName, PrimaryKey: false, Nullable: false},
+		{Name: masking.ArgumentCol, Type: sqlTypes.Text, Source: doltdb.MaskingTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+func (i *MaskingTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions implements sql.Table. Until the first rule is written there is no backing table, and no rules.
+func (i *MaskingTable) Partitions(context *sql.Context) (sql.PartitionIter, error) {
+	if i.backingTable == nil {
+		// no backing table; return an empty iter.
+		return index.SinglePartitionIterFromNomsMap(nil), nil
+	}
+	return i.backingTable.Partitions(context)
+}
+
+func (i *MaskingTable) PartitionRows(context *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	if i.backingTable == nil {
+		// no backing table; return an empty iter.
+		return sql.RowsToRowIter(), nil
+	}
+
+	return i.backingTable.PartitionRows(context, partition)
+}
+
+// NewMaskingTable returns the dolt_masking table backed by |backingTable|.
+func NewMaskingTable(_ *sql.Context, backingTable VersionableTable) sql.Table {
+	return &MaskingTable{backingTable: backingTable}
+}
+
+// NewEmptyMaskingTable returns the dolt_masking table of a root without one. Its backing table is created by the
+// first write.
+func NewEmptyMaskingTable(_ *sql.Context) sql.Table {
+	return &MaskingTable{}
+}
+
+// Replacer implements sql.ReplaceableTable. Replaced rules are validated like inserted ones.
+func (it *MaskingTable) Replacer(ctx *sql.Context) sql.RowReplacer {
+	return newMaskingWriter(it)
+}
+
+// Updater implements sql.UpdatableTable. The new values of updated rules are validated.
+func (it *MaskingTable) Updater(ctx *sql.Context) sql.RowUpdater {
+	return newMaskingWriter(it)
+}
+
+// Inserter implements sql.InsertableTable. Inserted rules are validated.
+func (it *MaskingTable) Inserter(*sql.Context) sql.RowInserter {
+	return newMaskingWriter(it)
+}
+
+// Deleter implements sql.DeletableTable. Deleted rules are not validated, so that rules made invalid by a later
+// schema change can be removed.
+func (it *MaskingTable) Deleter(*sql.Context) sql.RowDeleter {
+	return newMaskingWriter(it)
+}
+
+func (it *MaskingTable) LockedToRoot(ctx *sql.Context, root doltdb.RootValue) (sql.IndexAddressableTable, error) {
+	if it.backingTable == nil {
+		return it, nil
+	}
+	return it.backingTable.LockedToRoot(ctx, root)
+}
+
+// IndexedAccess implements sql.IndexAddressableTable. MaskingTable has no indexes, so it is never called.
+func (it *MaskingTable) IndexedAccess(lookup sql.IndexLookup) sql.IndexedTable {
+	panic("Unreachable")
+}
+
+// GetIndexes implements sql.IndexAddressableTable. MaskingTable has no indexes.
+func (it *MaskingTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
+	return nil, nil
+}
+
+func (i *MaskingTable) PreciseMatch() bool {
+	return true
+}
+
+var _ sql.RowReplacer = (*maskingWriter)(nil)
+var _ sql.RowUpdater = (*maskingWriter)(nil)
+var _ sql.RowInserter = (*maskingWriter)(nil)
+var _ sql.RowDeleter = (*maskingWriter)(nil)
+
+// maskingWriter writes the rules of dolt_masking, validating each rule written. The backing table is created by
+// StatementBegin if the working root doesn't have one yet.
+type maskingWriter struct {
+	it                      *MaskingTable
+	errDuringStatementBegin error
+	prevHash                *hash.Hash
+	tableWriter             dsess.TableWriter
+	// root is the working root the statement began with, which rules are validated against
+	root doltdb.RootValue
+}
+
+func newMaskingWriter(it *MaskingTable) *maskingWriter {
+	return &maskingWriter{it: it}
+}
+
+// Insert implements sql.RowInserter. It returns an error if |r| is not a valid rule.
+func (iw *maskingWriter) Insert(ctx *sql.Context, r sql.Row) error {
+	if err := iw.errDuringStatementBegin; err != nil {
+		return err
+	}
+	if err := validateMaskingRow(ctx, iw.root, r); err != nil {
+		return err
+	}
+	return iw.tableWriter.Insert(ctx, r)
+}
+
+// Update implements sql.RowUpdater. It returns an error if |new| is not a valid rule.
+func (iw *maskingWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := iw.errDuringStatementBegin; err != nil {
+		return err
+	}
+	if err := validateMaskingRow(ctx, iw.root, new); err != nil {
+		return err
+	}
+	return iw.tableWriter.Update(ctx, old, new)
+}
+
+// validateMaskingRow returns an error if |r| is not a valid masking rule for the schema of its table in |root|, so
+// that invalid rules are rejected when they are written rather than when they are applied.
+func validateMaskingRow(ctx *sql.Context, root doltdb.RootValue, r sql.Row) error {
+	var fields [4]string
+	for i := range fields {
+		if r[i] != nil {
+			fields[i] = r[i].(string)
+		}
+	}
+	rule, err := masking.NewRule(fields[0], fields[1], fields[2], fields[3])
+	if err != nil || root == nil {
+		return err
+	}
+	rules := make(masking.Rules)
+	rules.Add(rule)
+	return rules.ValidateRoot(ctx, root)
+}
+
+// Delete implements sql.RowDeleter.
+func (iw *maskingWriter) Delete(ctx *sql.Context, r sql.Row) error {
+	if err := iw.errDuringStatementBegin; err != nil {
+		return err
+	}
+	return iw.tableWriter.Delete(ctx, r)
+}
+
+// StatementBegin implements sql.TableEditor. It records the working root that rules are validated against, and
+// creates the backing table in the session's working set if it doesn't exist yet.
+func (iw *maskingWriter) StatementBegin(ctx *sql.Context) {
+	dbName := ctx.GetCurrentDatabase()
+	dSess := dsess.DSessFromSess(ctx.Session)
+
+	// TODO: this needs to use a revision qualified name
+	roots, _ := dSess.GetRoots(ctx, dbName)
+	dbState, ok, err := dSess.LookupDbState(ctx, dbName)
+	if err != nil {
+		iw.errDuringStatementBegin = err
+		return
+	}
+	if !ok {
+		iw.errDuringStatementBegin = fmt.Errorf("no root value found in session")
+		return
+	}
+
+	prevHash, err := roots.Working.HashOf()
+	if err != nil {
+		iw.errDuringStatementBegin = err
+		return
+	}
+
+	iw.prevHash = &prevHash
+	iw.root = roots.Working
+
+	found, err := roots.Working.HasTable(ctx, doltdb.TableName{Name: doltdb.MaskingTableName})
+
+	if err != nil {
+		iw.errDuringStatementBegin = err
+		return
+	}
+
+	if !found {
+		newSchema, err := masking.TableSchema()
+		if err != nil {
+			iw.errDuringStatementBegin = err
+			return
+		}
+
+		// underlying table doesn't exist. Record this, then create the table.
+		newRootValue, err := doltdb.CreateEmptyTable(ctx, roots.Working, doltdb.TableName{Name: doltdb.MaskingTableName}, newSchema)
+
+		if err != nil {
+			iw.errDuringStatementBegin = err
+			return
+		}
+
+		if dbState.WorkingSet() == nil {
+			iw.errDuringStatementBegin = doltdb.ErrOperationNotSupportedInDetachedHead
+			return
+		}
+
+		// We use WriteSession.SetWorkingSet instead of DoltSession.SetWorkingRoot because we want to avoid modifying the root
+		// until the end of the transaction, but we still want the WriteSession to be able to find the newly
+		// created table.
+		if ws := dbState.WriteSession(); ws != nil {
+			err = ws.SetWorkingSet(ctx, dbState.WorkingSet().WithWorkingRoot(newRootValue))
+			if err != nil {
+				iw.errDuringStatementBegin = err
+				return
+			}
+		}
+
+		dSess.SetWorkingRoot(ctx, dbName, newRootValue)
+	}
+
+	if ws := dbState.WriteSession(); ws != nil {
+		tableWriter, err := ws.GetTableWriter(ctx, doltdb.TableName{Name: doltdb.MaskingTableName}, dbName, dSess.SetWorkingRoot)
+		if err != nil {
+			iw.errDuringStatementBegin = err
+			return
+		}
+		iw.tableWriter = tableWriter
+		tableWriter.StatementBegin(ctx)
+	}
+}
+
+// DiscardChanges implements sql.TableEditor.
+func (iw *maskingWriter) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	if iw.tableWriter != nil {
+		return iw.tableWriter.DiscardChanges(ctx, errorEncountered)
+	}
+	return nil
+}
+
+// StatementComplete implements sql.TableEditor.
+func (iw *maskingWriter) StatementComplete(ctx *sql.Context) error {
+	if iw.tableWriter != nil {
+		return iw.tableWriter.StatementComplete(ctx)
+	}
+	return nil
+}
+
+// Close implements sql.Closer. It flushes the rules written to the session's working set.
+func (iw maskingWriter) Close(ctx *sql.Context) error {
+	if iw.tableWriter != nil {
+		return iw.tableWriter.Close(ctx)
+	}
+	return nil
+}
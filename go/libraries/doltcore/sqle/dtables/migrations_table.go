@@ -0,0 +1,229 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schmigrations"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	migrationStatusApplied = "applied"
+	migrationStatusPending = "pending"
+)
+
+// migrationCommitsCacheSize is the number of commits whose migration commits are cached.
+const migrationCommitsCacheSize = 256
+
+// migrationCommitsCache holds the result of appliedMigrationCommits by the hash of the commit, so that reading
+// dolt_migrations only walks the commits made since it was last read.
+var migrationCommitsCache = func() *lru.Cache[hash.Hash, map[string]appliedCommit] {
+	cache, err := lru.New[hash.Hash, map[string]appliedCommit](migrationCommitsCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}()
+
+// appliedCommit is the commit which applied a migration.
+type appliedCommit struct {
+	hash hash.Hash
+	time time.Time
+}
+
+// MigrationsTable is a sql.Table implementation that implements a system table which shows the schema migrations
+// stored in the working set, whether they have been applied, and the commit which applied them.
+type MigrationsTable struct {
+	dbName string
+}
+
+var _ sql.Table = (*MigrationsTable)(nil)
+
+// NewMigrationsTable creates a MigrationsTable
+func NewMigrationsTable(dbName string) sql.Table {
+	return &MigrationsTable{dbName: dbName}
+}
+
+func (mt *MigrationsTable) Name() string {
+	return doltdb.MigrationsTableName
+}
+
+func (mt *MigrationsTable) String() string {
+	return doltdb.MigrationsTableName
+}
+
+func (mt *MigrationsTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "sequence", Type: types.Int64, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: false, DatabaseSource: mt.dbName},
+		{Name: "name", Type: types.Text, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: false, DatabaseSource: mt.dbName},
+		{Name: "status", Type: types.Text, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: false, DatabaseSource: mt.dbName},
+		{Name: "applied_commit", Type: types.Text, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: true, DatabaseSource: mt.dbName},
+		{Name: "applied_at", Type: types.Datetime, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: true, DatabaseSource: mt.dbName},
+		{Name: "up", Type: types.LongText, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: false, DatabaseSource: mt.dbName},
+		{Name: "down", Type: types.LongText, Source: doltdb.MigrationsTableName, PrimaryKey: false, Nullable: true, DatabaseSource: mt.dbName},
+	}
+}
+
+func (mt *MigrationsTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (mt *MigrationsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+func (mt *MigrationsTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	sess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := sess.GetRoots(ctx, mt.dbName)
+	if !ok {
+		return nil, fmt.Errorf("unable to get roots for database %s", mt.dbName)
+	}
+	migrations, err := schmigrations.Load(ctx, roots.Working)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := sess.GetHeadCommit(ctx, mt.dbName)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := appliedMigrationCommits(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, len(migrations))
+	for i, m := range migrations {
+		row := sql.Row{m.Sequence, m.Name, migrationStatusPending, nil, nil, m.Up, nil}
+		if m.Applied {
+			row[2] = migrationStatusApplied
+		}
+		if cm, ok := commits[m.Name]; ok && m.Applied {
+			row[3] = cm.hash.String()
+			row[4] = cm.time
+		}
+		if len(m.Down) > 0 {
+			row[6] = m.Down
+		}
+		rows[i] = row
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// appliedMigrationCommits returns the commits which applied the migrations applied at |head|, by name. The commit
+// which applied a migration is the oldest commit of the first-parent history of |head| from which on the migration has
+// been applied, so a migration applied on another branch is attributed to the merge commit which brought it into this
+// one. Migrations which were only applied in the working set have no commit.
+//
+// The history is walked back until every migration is resolved or a commit whose result is cached is reached, whose
+// result then resolves the remaining migrations, which are applied at every commit walked.
+func appliedMigrationCommits(ctx context.Context, head *doltdb.Commit) (map[string]appliedCommit, error) {
+	headHash, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	if commits, ok := migrationCommitsCache.Get(headHash); ok {
+		return commits, nil
+	}
+
+	commits := make(map[string]appliedCommit)
+	var unresolved map[string]struct{}
+	var prevHash hash.Hash
+	var applied map[string]bool
+	for cm := head; cm != nil; {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		if cached, ok := migrationCommitsCache.Get(h); ok && unresolved != nil {
+			for name := range unresolved {
+				if c, ok := cached[name]; ok {
+					commits[name] = c
+				}
+			}
+			break
+		}
+
+		root, err := cm.GetRootValue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Most commits do not change dolt_schemas, and only need to be loaded once
+		tblHash, _, err := root.GetTableHash(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+		if err != nil {
+			return nil, err
+		}
+		if applied == nil || tblHash != prevHash {
+			ms, err := schmigrations.Load(ctx, root)
+			if err != nil {
+				return nil, err
+			}
+			applied = make(map[string]bool, len(ms))
+			for _, m := range ms {
+				applied[m.Name] = m.Applied
+			}
+			prevHash = tblHash
+		}
+		if unresolved == nil {
+			unresolved = make(map[string]struct{})
+			for name, ok := range applied {
+				if ok {
+					unresolved[name] = struct{}{}
+				}
+			}
+		}
+
+		for name := range unresolved {
+			if !applied[name] {
+				delete(unresolved, name)
+			}
+		}
+		if len(unresolved) == 0 {
+			break
+		}
+		meta, err := cm.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for name := range unresolved {
+			commits[name] = appliedCommit{hash: h, time: meta.Time()}
+		}
+
+		if cm.NumParents() == 0 {
+			break
+		}
+		parent, err := cm.GetParent(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		// The history of shallow clones ends at ghost commits
+		cm, _ = parent.ToCommit()
+	}
+
+	migrationCommitsCache.Add(headHash, commits)
+	return commits, nil
+}
@@ -162,6 +162,10 @@ func (dt *UnscopedDiffTable) LookupPartitions(ctx *sql.Context, lookup sql.Index
 			return nil, fmt.Errorf("failed to parse commit lookup ranges: %s", sql.DebugString(lookup.Ranges))
 		}
 		hashes, commits, metas := index.HashesToCommits(ctx, dt.ddb, hs, dt.head, false)
+		hashes, commits, metas, err := filterReadableCommits(ctx, dt.dbName, dt.ddb, hashes, commits, metas)
+		if err != nil {
+			return nil, err
+		}
 		if len(hashes) == 0 {
 			return sql.PartitionsToPartitionIter(), nil
 		}
@@ -571,6 +575,9 @@ func getCommitFromHash(ctx *sql.Context, ddb *doltdb.DoltDB, val string) *doltdb
 	if !ok {
 		return nil
 	}
+	if err = dsess.CheckReadAccessForCommitSpec(ctx, ctx.GetCurrentDatabase(), ddb, cmSpec, cm); err != nil {
+		return nil
+	}
 
 	return cm
 }
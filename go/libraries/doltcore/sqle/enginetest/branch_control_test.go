@@ -35,6 +35,8 @@ type BranchControlTest struct {
 	Name        string
 	SetUpScript []string
 	Assertions  []BranchControlTestAssertion
+	// UseLocalFileSystem runs the test against a database on disk, which has a chunk journal and so a reflog.
+	UseLocalFileSystem bool
 }
 
 // BranchControlTestAssertion is within a BranchControlTest to assert functionality.
@@ -599,6 +601,258 @@ var BranchControlOtherDbBlockTests = []BranchControlBlockTest{
 }
 
 var BranchControlTests = []BranchControlTest{
+	{
+		Name: "Read entries block reads",
+		SetUpScript: []string{
+			"DELETE FROM dolt_branch_control WHERE user = '%';",
+			"INSERT INTO dolt_branch_control VALUES ('%', '%', 'root', 'localhost', 'admin');",
+			"INSERT INTO dolt_branch_control VALUES ('%', 'main', '%', '%', 'write');",
+			"CREATE USER testuser@localhost;",
+			"GRANT ALL ON *.* TO testuser@localhost;",
+			"CREATE USER otheruser@localhost;",
+			"GRANT ALL ON *.* TO otheruser@localhost;",
+			"CREATE TABLE test (pk BIGINT PRIMARY KEY);",
+			"INSERT INTO test VALUES (1);",
+			"CALL DOLT_COMMIT('-Am', 'setup commit');",
+			"CALL DOLT_BRANCH('release1');",
+			"CALL DOLT_CHECKOUT('release1');",
+			"INSERT INTO test VALUES (2);",
+			"CALL DOLT_COMMIT('-Am', 'embargoed commit');",
+			"CALL DOLT_CHECKOUT('main');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{ // Without an entry granting read on a branch, the branch may be read by anyone
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT * FROM `mydb/release1`.test;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "INSERT INTO dolt_branch_control VALUES ('%', 'release%', 'testuser', 'localhost', 'read');",
+				Expected: []sql.Row{
+					{types.NewOkResult(1)},
+				},
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "SELECT * FROM `mydb/release1`.test;",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "SELECT * FROM test AS OF 'release1';",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "SELECT * FROM dolt_diff('main', 'release1', 'test');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "SELECT message FROM `mydb/release1`.dolt_log;",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "SELECT count(*) FROM dolt_commit_diff_test WHERE to_commit = 'release1' AND from_commit = 'main';",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "USE `mydb/release1`;",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{ // Branches without an entry granting read are unaffected
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT * FROM test;",
+				Expected: []sql.Row{{1}},
+			},
+			{ // Restricted branches and their commits are left out of the system tables
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT name FROM dolt_branches;",
+				Expected: []sql.Row{{"main"}},
+			},
+			{
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_commits WHERE message = 'embargoed commit';",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_commits WHERE commit_hash = (SELECT hashof('release1'));",
+				Expected: []sql.Row{{0}},
+			},
+			{ // Admins may always read
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT * FROM `mydb/release1`.test;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT * FROM `mydb/release1`.test;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT * FROM test AS OF 'release1';",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT to_pk, diff_type FROM dolt_diff('main', 'release1', 'test');",
+				Expected: []sql.Row{{2, "added"}},
+			},
+			{ // Reading does not allow writing
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "INSERT INTO `mydb/release1`.test VALUES (3);",
+				ExpectedErr: branch_control.ErrIncorrectPermissions,
+			},
+		},
+	},
+	{
+		Name: "Read entries block procedures that copy the commits of a branch",
+		SetUpScript: []string{
+			"DELETE FROM dolt_branch_control WHERE user = '%';",
+			"INSERT INTO dolt_branch_control VALUES ('%', '%', 'root', 'localhost', 'admin');",
+			"INSERT INTO dolt_branch_control VALUES ('%', 'main', '%', '%', 'write');",
+			"INSERT INTO dolt_branch_control VALUES ('%', 'release%', 'testuser', 'localhost', 'read');",
+			"CREATE USER testuser@localhost;",
+			"GRANT ALL ON *.* TO testuser@localhost;",
+			"CREATE USER otheruser@localhost;",
+			"GRANT ALL ON *.* TO otheruser@localhost;",
+			"CREATE TABLE test (pk BIGINT PRIMARY KEY);",
+			"INSERT INTO test VALUES (1);",
+			"CALL DOLT_COMMIT('-Am', 'setup commit');",
+			"CALL DOLT_BRANCH('release1');",
+			"CALL DOLT_CHECKOUT('release1');",
+			"INSERT INTO test VALUES (2);",
+			"CALL DOLT_COMMIT('-Am', 'embargoed commit');",
+			"CALL DOLT_CHECKOUT('main');",
+		},
+		UseLocalFileSystem: true,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_MERGE('release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_MERGE('--squash', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_BRANCH('leak', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_BRANCH('-c', 'release1', 'leak');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_CHECKOUT('-b', 'leak', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_CHERRY_PICK('release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_RESET('--hard', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_RESET('--soft', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_RESET('release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_REVERT('release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{
+				User:        "otheruser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_REBASE('-i', 'release1');",
+				ExpectedErr: branch_control.ErrCannotReadBranch,
+			},
+			{ // Nothing was copied from the restricted branch
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT * FROM test;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT name FROM dolt_branches ORDER BY name;",
+				Expected: []sql.Row{{"main"}, {"release1"}},
+			},
+			{ // Restricted branches are left out of the reflog
+				User:     "otheruser",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_reflog() WHERE ref = 'refs/heads/release1';",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT count(*) > 0 FROM dolt_reflog() WHERE ref = 'refs/heads/release1';",
+				Expected: []sql.Row{{true}},
+			},
+			{ // Users who may read the branch may copy it
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH('copy', 'release1');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_MERGE('release1');",
+				Expected: []sql.Row{{doltCommit, 1, 0, "merge successful"}},
+			},
+		},
+	},
 	{
 		Name: "Namespace entries block",
 		SetUpScript: []string{
@@ -1367,6 +1621,9 @@ func TestBranchControl(t *testing.T) {
 	for _, test := range BranchControlTests {
 		harness := newDoltHarness(t)
 		defer harness.Close()
+		if test.UseLocalFileSystem {
+			harness.UseLocalFileSystem()
+		}
 		t.Run(test.Name, func(t *testing.T) {
 			engine, err := harness.NewEngine(t)
 			require.NoError(t, err)
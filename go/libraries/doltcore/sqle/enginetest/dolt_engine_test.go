@@ -345,8 +345,8 @@ func TestSingleScriptPrepared(t *testing.T) {
 			{
 				Query: "select * from dolt_history_xy where commit_hash = (select dolt_log.commit_hash from dolt_log limit 1 offset 1) order by 1",
 				Expected: []sql.Row{
-					sql.Row{0, 1, "itt2nrlkbl7jis4gt9aov2l32ctt08th", "billy bob", time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local)},
-					sql.Row{2, 3, "itt2nrlkbl7jis4gt9aov2l32ctt08th", "billy bob", time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local)},
+					sql.Row{0, 1, "itt2nrlkbl7jis4gt9aov2l32ctt08th", "billy bob", time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local), time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local), nil},
+					sql.Row{2, 3, "itt2nrlkbl7jis4gt9aov2l32ctt08th", "billy bob", time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local), time.Date(1970, time.January, 1, 19, 0, 0, 0, time.Local), nil},
 				},
 			},
 			{
@@ -1169,6 +1169,21 @@ func TestDoltTempTableScripts(t *testing.T) {
 	RunDoltTempTableScripts(t, harness)
 }
 
+func TestDoltMaskingScripts(t *testing.T) {
+	harness := newDoltEnginetestHarness(t)
+	RunDoltMaskingScripts(t, harness)
+}
+
+func TestDoltSchemaMigrationScripts(t *testing.T) {
+	harness := newDoltEnginetestHarness(t)
+	RunDoltSchemaMigrationScripts(t, harness)
+}
+
+func TestDoltSequenceScripts(t *testing.T) {
+	harness := newDoltEnginetestHarness(t)
+	RunDoltSequenceScripts(t, harness)
+}
+
 func TestDoltRevisionDbScripts(t *testing.T) {
 	h := newDoltEnginetestHarness(t)
 	RunDoltRevisionDbScriptsTest(t, h)
@@ -1500,6 +1515,11 @@ func TestDiffSummaryTableFunctionPrepared(t *testing.T) {
 	RunDiffSummaryTableFunctionTestsPrepared(t, harness)
 }
 
+func TestPreviewMergeConflictsFunction(t *testing.T) {
+	harness := newDoltEnginetestHarness(t)
+	RunPreviewMergeConflictsFunctionTests(t, harness)
+}
+
 func TestPatchTableFunction(t *testing.T) {
 	harness := newDoltEnginetestHarness(t)
 	RunDoltPatchTableFunctionTests(t, harness)
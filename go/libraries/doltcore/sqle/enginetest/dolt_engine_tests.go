@@ -593,6 +593,20 @@ func RunTransactionTests(t *testing.T, h DoltEnginetestHarness) {
 			enginetest.TestTransactionScript(t, h, script)
 		}()
 	}
+	for _, script := range DoltSerializableTransactionTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestTransactionScript(t, h, script)
+		}()
+	}
+	for _, script := range DoltLockingReadTransactionTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestTransactionScript(t, h, script)
+		}()
+	}
 	for _, script := range DoltConflictHandlingTests {
 		func() {
 			h := h.NewHarness(t)
@@ -665,6 +679,30 @@ func RunDoltTempTableScripts(t *testing.T, harness DoltEnginetestHarness) {
 	}
 }
 
+func RunDoltMaskingScripts(t *testing.T, harness DoltEnginetestHarness) {
+	for _, script := range DoltMaskingScripts {
+		harness := harness.NewHarness(t)
+		enginetest.TestScript(t, harness, script)
+		harness.Close()
+	}
+}
+
+func RunDoltSchemaMigrationScripts(t *testing.T, harness DoltEnginetestHarness) {
+	for _, script := range DoltSchemaMigrationScripts {
+		harness := harness.NewHarness(t)
+		enginetest.TestScript(t, harness, script)
+		harness.Close()
+	}
+}
+
+func RunDoltSequenceScripts(t *testing.T, harness DoltEnginetestHarness) {
+	for _, script := range DoltSequenceScripts {
+		harness := harness.NewHarness(t)
+		enginetest.TestScript(t, harness, script)
+		harness.Close()
+	}
+}
+
 func RunDoltRevisionDbScriptsTest(t *testing.T, h DoltEnginetestHarness) {
 	for _, script := range DoltRevisionDbScripts {
 		func() {
@@ -1073,6 +1111,13 @@ func RunHistorySystemTableTests(t *testing.T, harness DoltEnginetestHarness) {
 			enginetest.TestScript(t, harness, test)
 		})
 	}
+	for _, test := range SystemTimeScriptTests {
+		harness = harness.NewHarness(t)
+		harness.Setup(setup.MydbData)
+		t.Run(test.Name, func(t *testing.T) {
+			enginetest.TestScript(t, harness, test)
+		})
+	}
 }
 
 func RunHistorySystemTableTestsPrepared(t *testing.T, harness DoltEnginetestHarness) {
@@ -1229,6 +1274,17 @@ func RunDiffSummaryTableFunctionTestsPrepared(t *testing.T, harness DoltEnginete
 	}
 }
 
+func RunPreviewMergeConflictsFunctionTests(t *testing.T, harness DoltEnginetestHarness) {
+	for _, test := range PreviewMergeConflictsFunctionScripts {
+		t.Run(test.Name, func(t *testing.T) {
+			harness = harness.NewHarness(t)
+			defer harness.Close()
+			harness.Setup(setup.MydbData)
+			enginetest.TestScript(t, harness, test)
+		})
+	}
+}
+
 func RunDoltPatchTableFunctionTests(t *testing.T, harness DoltEnginetestHarness) {
 	for _, test := range PatchTableFunctionScriptTests {
 		t.Run(test.Name, func(t *testing.T) {
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	gms "github.com/dolthub/go-mysql-server"
@@ -59,6 +60,8 @@ type DoltHarness struct {
 	configureStats      bool
 	useLocalFilesystem  bool
 	setupTestProcedures bool
+	// the connection id of the last session created, so that each session has its own
+	lastConnID uint32
 }
 
 func (d *DoltHarness) UseLocalFileSystem() {
@@ -252,6 +255,7 @@ func (d *DoltHarness) NewEngine(t *testing.T) (enginetest.QueryEngine, error) {
 			return nil, err
 		}
 		e.Analyzer.ExecBuilder = rowexec.DefaultBuilder
+		e.Parser = sqle.NewDoltParser(e.Parser)
 		d.engine = e
 
 		ctx := enginetest.NewContext(d)
@@ -392,7 +396,8 @@ func (d *DoltHarness) newSessionWithClient(client sql.Client) *dsess.DoltSession
 	localConfig := d.multiRepoEnv.Config()
 	pro := d.session.Provider()
 
-	dSession, err := dsess.NewDoltSession(sql.NewBaseSessionWithClientServer("address", client, 1), pro.(dsess.DoltDatabaseProvider), localConfig, d.branchControl, d.statsPro, writer.NewWriteSession)
+	connID := atomic.AddUint32(&d.lastConnID, 1)
+	dSession, err := dsess.NewDoltSession(sql.NewBaseSessionWithClientServer("address", client, connID), pro.(dsess.DoltDatabaseProvider), localConfig, d.branchControl, d.statsPro, writer.NewWriteSession)
 	dSession.SetCurrentDatabase("mydb")
 	require.NoError(d.t, err)
 	return dSession
@@ -2517,6 +2517,147 @@ WHERE z IN (
 			},
 		},
 	},
+	{
+		Name: "primary key table: row validity",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v varchar(20));",
+			"call dolt_add('.')",
+			"insert into t values (1, 'a'), (2, 'b'), (3, 'c');",
+			"call dolt_commit('-am', 'inserting into t', '--date', '2022-08-06T12:00:01');",
+			"update t set v = 'a2' where pk = 1;",
+			"call dolt_commit('-am', 'updating row 1', '--date', '2022-08-06T12:00:02');",
+			"delete from t where pk = 2;",
+			"call dolt_commit('-am', 'deleting row 2', '--date', '2022-08-06T12:00:03');",
+			"insert into t values (4, 'd');",
+			"update t set v = 'c2' where pk = 3;",
+			"call dolt_commit('-am', 'inserting row 4 and updating row 3', '--date', '2022-08-06T12:00:04');",
+			"create table watched (pk int primary key);",
+			"insert into watched values (1), (3), (5);",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select pk, v, second(valid_from), second(valid_to) from dolt_history_t where commit_date = valid_from order by pk, valid_from;",
+				Expected: []sql.Row{
+					{1, "a", 1, 2},
+					{1, "a2", 2, nil},
+					{2, "b", 1, 3},
+					{3, "c", 1, 4},
+					{3, "c2", 4, nil},
+					{4, "d", 4, nil},
+				},
+			},
+			{
+				Query: "select v, second(commit_date), second(valid_from), second(valid_to) from dolt_history_t where pk = 1 order by commit_date;",
+				Expected: []sql.Row{
+					{"a", 1, 1, 2},
+					{"a2", 2, 2, nil},
+					{"a2", 3, 2, nil},
+					{"a2", 4, 2, nil},
+				},
+			},
+			{
+				// the same rows without a lookup on the primary key
+				Query: "select v, second(commit_date), second(valid_from), second(valid_to) from dolt_history_t where pk + 0 = 1 order by commit_date;",
+				Expected: []sql.Row{
+					{"a", 1, 1, 2},
+					{"a2", 2, 2, nil},
+					{"a2", 3, 2, nil},
+					{"a2", 4, 2, nil},
+				},
+			},
+			{
+				Query: "select pk, v, second(commit_date), commit_hash is not null, committer is not null from dolt_history_t where pk in (2, 5) order by commit_date;",
+				Expected: []sql.Row{
+					{2, "b", 1, true, true},
+					{2, "b", 2, true, true},
+				},
+			},
+			{
+				Query:    "select v from dolt_history_t where pk = 1 and valid_from <= '2022-08-06 12:00:02' and (valid_to is null or valid_to > '2022-08-06 12:00:02') and commit_date = valid_from;",
+				Expected: []sql.Row{{"a2"}},
+			},
+			{
+				Query:    "select count(*) from dolt_history_t where pk = 3 and commit_date = valid_from;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query: "select w.pk, h.v, second(h.valid_from), second(h.valid_to) from watched w join dolt_history_t h on h.pk = w.pk where h.commit_date = h.valid_from order by w.pk, h.valid_from;",
+				Expected: []sql.Row{
+					{1, "a", 1, 2},
+					{1, "a2", 2, nil},
+					{3, "c", 1, 4},
+					{3, "c2", 4, nil},
+				},
+			},
+		},
+	},
+	{
+		Name: "primary key table: row validity across schema changes",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int);",
+			"call dolt_add('.')",
+			"insert into t values (1, 1), (2, 2);",
+			"call dolt_commit('-am', 'inserting into t', '--date', '2022-08-06T12:00:01');",
+			"alter table t add column w int;",
+			"call dolt_commit('-am', 'adding a column', '--date', '2022-08-06T12:00:02');",
+			"update t set w = 10 where pk = 1;",
+			"call dolt_commit('-am', 'updating row 1', '--date', '2022-08-06T12:00:03');",
+			"create table keyless (a int, b int);",
+			"insert into keyless values (1, 1);",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'creating a keyless table', '--date', '2022-08-06T12:00:04');",
+			"create table pk2 (a int, b int, primary key (a, b));",
+			"insert into pk2 values (1, 2), (2, 1);",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'creating a table with a composite key', '--date', '2022-08-06T12:00:05');",
+			"alter table pk2 drop primary key;",
+			"alter table pk2 add primary key (b, a);",
+			"call dolt_commit('-am', 'reordering the key', '--date', '2022-08-06T12:00:06');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				// adding a nullable column doesn't change the stored rows
+				Query: "select pk, v, w, second(valid_from), second(valid_to) from dolt_history_t where commit_date = valid_from order by pk, valid_from;",
+				Expected: []sql.Row{
+					{1, 1, nil, 1, 3},
+					{1, 1, 10, 3, nil},
+					{2, 2, nil, 1, nil},
+				},
+			},
+			{
+				Query: "select second(commit_date), second(valid_from), second(valid_to) from dolt_history_t where pk = 2 order by commit_date;",
+				Expected: []sql.Row{
+					{1, 1, nil},
+					{2, 1, nil},
+					{3, 1, nil},
+					{4, 1, nil},
+					{5, 1, nil},
+					{6, 1, nil},
+				},
+			},
+			{
+				// changing the primary key starts a new version of every row
+				Query: "select a, b, second(commit_date), second(valid_from), second(valid_to) from dolt_history_pk2 where a = 1 and b = 2 order by commit_date;",
+				Expected: []sql.Row{
+					{1, 2, 5, 5, 6},
+					{1, 2, 6, 6, nil},
+				},
+			},
+			{
+				Query: "select a, b, second(valid_from), second(valid_to) from dolt_history_pk2 where commit_date = valid_from order by a, valid_from;",
+				Expected: []sql.Row{
+					{1, 2, 5, 6},
+					{1, 2, 6, nil},
+					{2, 1, 5, 6},
+					{2, 1, 6, nil},
+				},
+			},
+			{
+				Query:    "select distinct a, b, valid_from, valid_to from dolt_history_keyless;",
+				Expected: []sql.Row{{1, 1, nil, nil}},
+			},
+		},
+	},
 	{
 		Name:        "can sort by dolt_log.commit",
 		SetUpScript: []string{},
@@ -2550,6 +2691,93 @@ WHERE z IN (
 	},
 }
 
+// SystemTimeScriptTests contains tests of FOR SYSTEM_TIME clauses. These are rewritten by the engine's parser, which
+// prepared tests don't use, so they only run non-prepared.
+var SystemTimeScriptTests = []queries.ScriptTest{
+	{
+		Name: "FOR SYSTEM_TIME temporal queries",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int);",
+			"call dolt_add('.')",
+			"insert into t values (1, 1), (2, 2);",
+			"call dolt_commit('-am', 'inserting into t', '--date', '2022-08-06T12:00:01');",
+			"update t set v = 10 where pk = 1;",
+			"call dolt_commit('-am', 'updating row 1', '--date', '2022-08-06T12:00:02');",
+			"delete from t where pk = 2;",
+			"insert into t values (3, 3);",
+			"call dolt_commit('-am', 'replacing row 2', '--date', '2022-08-06T12:00:03');",
+			"insert into t values (4, 4);",
+			"call dolt_commit('-am', 'inserting row 4', '--date', '2022-08-06T12:00:04');",
+			"create table keyless (a int, b int);",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'creating a keyless table', '--date', '2022-08-06T12:00:05');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select pk, v, second(valid_from), second(valid_to) from t for system_time all order by pk, valid_from;",
+				Expected: []sql.Row{
+					{1, 1, 1, 2},
+					{1, 10, 2, nil},
+					{2, 2, 1, 3},
+					{3, 3, 3, nil},
+					{4, 4, 4, nil},
+				},
+			},
+			{
+				Query: "select pk, v, second(valid_from), second(valid_to) from t for system_time as of '2022-08-06 12:00:02' order by pk;",
+				Expected: []sql.Row{
+					{1, 10, 2, nil},
+					{2, 2, 1, 3},
+				},
+			},
+			{
+				Query:    "select * from t for system_time as of '2022-08-06 12:00:00';",
+				Expected: []sql.Row{},
+			},
+			{
+				Query: "select pk, v from t for system_time between '2022-08-06 12:00:02' and '2022-08-06 12:00:03' order by pk;",
+				Expected: []sql.Row{
+					{1, 10},
+					{2, 2},
+					{3, 3},
+				},
+			},
+			{
+				Query: "select pk, v from t for system_time from '2022-08-06 12:00:02' to '2022-08-06 12:00:03' order by pk;",
+				Expected: []sql.Row{
+					{1, 10},
+					{2, 2},
+				},
+			},
+			{
+				Query:    "select pk from t for system_time all where valid_to is null order by pk;",
+				Expected: []sql.Row{{1}, {3}, {4}},
+			},
+			{
+				Query:    "select x.pk, x.v from t for system_time as of '2022-08-06 12:00:01' as x where x.pk > 1;",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:    "select h.pk, h.v, t.v from t for system_time all h join t on h.pk = t.pk where h.valid_to is not null;",
+				Expected: []sql.Row{{1, 1, 10}},
+			},
+			{
+				// the AS OF clause still reads the table at a single revision
+				Query:    "select * from t as of 'HEAD~2' order by pk;",
+				Expected: []sql.Row{{1, 10}, {3, 3}},
+			},
+			{
+				Query:    "select 'from t for system_time all';",
+				Expected: []sql.Row{{"from t for system_time all"}},
+			},
+			{
+				Query:       "select * from keyless for system_time all;",
+				ExpectedErr: sqle.ErrSystemTimeKeylessTable,
+			},
+		},
+	},
+}
+
 // BrokenHistorySystemTableScriptTests contains tests that work for non-prepared, but don't work
 // for prepared queries.
 var BrokenHistorySystemTableScriptTests = []queries.ScriptTest{
@@ -7146,3 +7374,642 @@ var DoltTempTableScripts = []queries.ScriptTest{
 		},
 	},
 }
+
+var DoltMaskingScripts = []queries.ScriptTest{
+	{
+		Name: "dolt_masking is empty before any rules are added",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_masking",
+				Expected: []sql.Row{},
+			},
+			{
+				Query: "describe dolt_masking",
+				Expected: []sql.Row{
+					{"table_name", "text", "NO", "PRI", nil, ""},
+					{"column_name", "text", "NO", "PRI", nil, ""},
+					{"transform", "text", "NO", "", nil, ""},
+					{"argument", "text", "YES", "", nil, ""},
+				},
+			},
+		},
+	},
+	{
+		Name: "dolt_masking stores rules and is versioned",
+		SetUpScript: []string{
+			"create table people (id int primary key, email varchar(100), ssn varchar(11));",
+			"insert into dolt_masking values ('people', 'email', 'fake', 'email:salt'), ('people', 'ssn', 'fixed', 'XXX-XX-XXXX');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select * from dolt_masking order by column_name",
+				Expected: []sql.Row{
+					{"people", "email", "fake", "email:salt"},
+					{"people", "ssn", "fixed", "XXX-XX-XXXX"},
+				},
+			},
+			{
+				Query:    "select table_name, status from dolt_status where table_name = 'dolt_masking'",
+				Expected: []sql.Row{{"dolt_masking", "new table"}},
+			},
+			{
+				Query:    "update dolt_masking set transform = 'null', argument = null where column_name = 'ssn'",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "select * from dolt_masking where column_name = 'ssn'",
+				Expected: []sql.Row{{"people", "ssn", "null", nil}},
+			},
+			{
+				Query:    "delete from dolt_masking where column_name = 'ssn'",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select count(*) from dolt_masking",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
+	{
+		Name: "dolt_masking rejects invalid rules",
+		SetUpScript: []string{
+			"create table people (id int primary key, code varchar(8) unique, email varchar(100) not null, age int unique, notes text);",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "insert into dolt_masking values ('t', 'c', 'shuffle', null)",
+				ExpectedErrStr: "unknown masking transform 'shuffle', valid transforms are null, hash, fixed, fake and truncate",
+			},
+			{
+				Query:          "insert into dolt_masking values ('t', 'c', 'truncate', 'abc')",
+				ExpectedErrStr: "invalid argument 'abc' for masking transform truncate on t.c: expected a non-negative integer",
+			},
+			{
+				Query: "insert into dolt_masking values ('t', 'c', 'fake', 'ssn:salt')",
+				ExpectedErrStr: "invalid argument for masking transform fake on t.c: unknown fake value kind 'ssn', valid kinds are " +
+					"city, company, email, first_name, last_name, name, phone, street_address, text, word",
+			},
+			{
+				Query:          "insert into dolt_masking values ('t', 'c', 'hash', null)",
+				ExpectedErrStr: "masking transform hash on t.c requires a salt as its argument",
+			},
+			{
+				Query:          "insert into dolt_masking values ('t', 'c', 'fake', 'email')",
+				ExpectedErrStr: "masking transform fake on t.c requires an argument of the form '<kind>:<salt>'",
+			},
+			{
+				Query: "insert into dolt_masking values ('people', 'id', 'fixed', '1')",
+				ExpectedErrStr: "cannot mask column people.id of type int with transform fixed: the column is part of a primary key or " +
+					"unique index, and the transform can mask different values to the same value; use transform hash on a text column of at least 16 characters",
+			},
+			{
+				Query: "insert into dolt_masking values ('people', 'code', 'hash', 'salt')",
+				ExpectedErrStr: "cannot mask column people.code of type varchar(8) with transform hash: the column is part of a primary key or " +
+					"unique index, and the transform can mask different values to the same value; use transform hash on a text column of at least 16 characters",
+			},
+			{
+				Query: "insert into dolt_masking values ('People', 'AGE', 'hash', 'salt')",
+				ExpectedErrStr: "cannot mask column People.age of type int with transform hash: the column is part of a primary key or " +
+					"unique index, and the transform can mask different values to the same value; use transform hash on a text column of at least 16 characters",
+			},
+			{
+				Query:          "insert into dolt_masking values ('people', 'email', 'null', null)",
+				ExpectedErrStr: "cannot mask NOT NULL column people.email with transform null",
+			},
+			{
+				Query:    "select count(*) from dolt_masking",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "insert into dolt_masking values ('people', 'email', 'hash', 'salt'), ('people', 'notes', 'null', null), ('other', 'id', 'fixed', '1')",
+				Expected: []sql.Row{{types.NewOkResult(3)}},
+			},
+		},
+	},
+	{
+		Name: "dolt_mask function",
+		SetUpScript: []string{
+			"create table people (id int primary key, name varchar(10), age tinyint unsigned, email varchar(100));",
+			"insert into people values (1, 'Alice', 34, 'alice@corp.com'), (2, 'Bob', 71, 'bob@corp.com'), (3, null, null, null);",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select id, dolt_mask(name, 'null') from people order by id",
+				Expected: []sql.Row{{1, nil}, {2, nil}, {3, nil}},
+			},
+			{
+				Query:    "select id, dolt_mask(name, 'fixed', 'REDACTED') from people order by id",
+				Expected: []sql.Row{{1, "REDACTED"}, {2, "REDACTED"}, {3, nil}},
+			},
+			{
+				Query:    "select id, dolt_mask(name, 'truncate', '2') from people order by id",
+				Expected: []sql.Row{{1, "Al"}, {2, "Bo"}, {3, nil}},
+			},
+			{
+				Query:    "select dolt_mask('secret', 'hash', 'salt') = dolt_mask('secret', 'hash', 'salt'), dolt_mask('secret', 'hash', 'salt') = dolt_mask('secret', 'hash', 'pepper')",
+				Expected: []sql.Row{{true, false}},
+			},
+			{
+				Query:          "select dolt_mask('secret', 'hash')",
+				ExpectedErrStr: "masking transform hash on .'secret' requires a salt as its argument",
+			},
+			{
+				Query:    "select count(*) from people where dolt_mask(email, 'fake', 'email:salt') like '%@example.%'",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select count(*) from people where dolt_mask(age, 'hash', 'salt') between 0 and 255",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "update people set name = dolt_mask(name, 'fixed', 'X'), email = dolt_mask(email, 'null')",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 2, Info: plan.UpdateInfo{Matched: 3, Updated: 2}}}},
+			},
+			{
+				Query:    "select * from people order by id",
+				Expected: []sql.Row{{1, "X", uint8(34), nil}, {2, "X", uint8(71), nil}, {3, nil, nil, nil}},
+			},
+			{
+				Query:          "select dolt_mask(name, 'shuffle') from people",
+				ExpectedErrStr: "unknown masking transform 'shuffle', valid transforms are null, hash, fixed, fake and truncate",
+			},
+			{
+				Query:       "select dolt_mask(name) from people",
+				ExpectedErr: sql.ErrInvalidArgumentNumber,
+			},
+		},
+	},
+}
+
+var DoltSchemaMigrationScripts = []queries.ScriptTest{
+	{
+		Name: "schema migrations are applied and rolled back in order",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_migrations",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "call dolt_migrate_add('create_t', 'create table t (pk int primary key); insert into t values (1);', 'drop table t;')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "call dolt_migrate_add('add_c', 'alter table t add column c int;', 'alter table t drop column c')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query: "select sequence, name, status, applied_commit, applied_at, down from dolt_migrations",
+				Expected: []sql.Row{
+					{1, "create_t", "pending", nil, nil, "drop table t;"},
+					{2, "add_c", "pending", nil, nil, "alter table t drop column c"},
+				},
+			},
+			{
+				Query:    "call dolt_migrate_apply('--to', '1')",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "call dolt_migrate_apply()",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, nil}},
+			},
+			{
+				Query:    "select sequence, status, applied_commit from dolt_migrations",
+				Expected: []sql.Row{{1, "applied", nil}, {2, "applied", nil}},
+			},
+			{
+				Query:            "call dolt_commit('-Am', 'apply migrations')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select name from dolt_migrations where applied_commit = (select commit_hash from dolt_log limit 1)",
+				Expected: []sql.Row{{"create_t"}, {"add_c"}},
+			},
+			{
+				Query:            "call dolt_commit('--allow-empty', '-m', 'empty')",
+				SkipResultsCheck: true,
+			},
+			{ // the commits of the migrations are found from the result cached for the previous commit
+				Query:    "select name from dolt_migrations where applied_commit = (select commit_hash from dolt_log limit 1, 1)",
+				Expected: []sql.Row{{"create_t"}, {"add_c"}},
+			},
+			{
+				Query:    "call dolt_migrate_apply()",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "call dolt_migrate_rollback()",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select sequence, status from dolt_migrations",
+				Expected: []sql.Row{{1, "applied"}, {2, "pending"}},
+			},
+			{
+				Query:    "call dolt_migrate_rollback('--to', '0')",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select count(*) from information_schema.tables where table_name = 't'",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select sequence, status from dolt_migrations",
+				Expected: []sql.Row{{1, "pending"}, {2, "pending"}},
+			},
+		},
+	},
+	{
+		Name: "failed schema migrations leave the working set unchanged",
+		SetUpScript: []string{
+			"call dolt_migrate_add('create_a', 'create table a (pk int primary key)');",
+			"call dolt_migrate_add('bad', 'create table b (pk int primary key); insert into nope values (1);', 'drop table b');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "call dolt_migrate_apply()",
+				ExpectedErrStr: "schema migration 2 'bad' failed: table not found: nope",
+			},
+			{
+				Query:    "select count(*) from information_schema.tables where table_name in ('a', 'b')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select sequence, status from dolt_migrations",
+				Expected: []sql.Row{{1, "pending"}, {2, "pending"}},
+			},
+			{
+				Query:          "call dolt_migrate_add('--sequence', '1', 'other', 'select 1')",
+				ExpectedErrStr: "schema migration 'create_a' already has sequence number 1",
+			},
+			{
+				Query:          "call dolt_migrate_add('create_a', 'select 1')",
+				ExpectedErrStr: "schema migration 'create_a' already exists",
+			},
+			{
+				Query:          "call dolt_migrate_add('empty', '  ')",
+				ExpectedErrStr: "error: schema migration 'empty' has an empty up script",
+			},
+			{
+				Query:    "call dolt_migrate_apply('--to', '1')",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:          "call dolt_migrate_rollback()",
+				ExpectedErrStr: "error: schema migration 'create_a' cannot be rolled back, it has no down script",
+			},
+		},
+	},
+	{
+		Name: "merge renumbers a pending schema migration with the same sequence number",
+		SetUpScript: []string{
+			"call dolt_migrate_add('first', 'create table first (pk int primary key)');",
+			"call dolt_commit('-Am', 'add first migration');",
+			"call dolt_branch('other');",
+			"call dolt_migrate_add('main_m', 'create table main_t (pk int primary key)');",
+			"call dolt_migrate_apply();",
+			"call dolt_commit('-Am', 'apply migrations on main');",
+			"call dolt_checkout('other');",
+			"call dolt_migrate_add('other_m', 'create table other_t (pk int primary key)');",
+			"call dolt_commit('-Am', 'add migration on other');",
+			"call dolt_checkout('main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select sequence, name, status from `mydb/other`.dolt_migrations",
+				Expected: []sql.Row{{1, "first", "pending"}, {2, "other_m", "pending"}},
+			},
+			{
+				Query:            "call dolt_merge('other')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select sequence, name, status from dolt_migrations",
+				Expected: []sql.Row{{1, "first", "applied"}, {2, "main_m", "applied"}, {3, "other_m", "pending"}},
+			},
+			{
+				Query:    "call dolt_migrate_apply()",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select count(*) from information_schema.tables where table_name in ('first', 'main_t', 'other_t')",
+				Expected: []sql.Row{{3}},
+			},
+		},
+	},
+	{
+		Name: "merge fails when both sides applied a schema migration with the same sequence number",
+		SetUpScript: []string{
+			"call dolt_commit('--allow-empty', '-m', 'empty');",
+			"call dolt_branch('other');",
+			"call dolt_migrate_add('main_m', 'create table main_t (pk int primary key)');",
+			"call dolt_migrate_apply();",
+			"call dolt_commit('-Am', 'apply migration on main');",
+			"call dolt_checkout('other');",
+			"call dolt_migrate_add('other_m', 'create table other_t (pk int primary key)');",
+			"call dolt_migrate_apply();",
+			"call dolt_commit('-Am', 'apply migration on other');",
+			"call dolt_checkout('main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "call dolt_merge('other')",
+				ExpectedErrStr: "schema migrations 'main_m' and 'other_m' both have sequence number 1 and were applied on both sides of the merge; roll back one of them and give it a new sequence number before merging",
+			},
+		},
+	},
+	{
+		Name: "cherry-pick renumbers a pending schema migration with the same sequence number",
+		SetUpScript: []string{
+			"call dolt_migrate_add('first', 'create table first (pk int primary key)');",
+			"call dolt_commit('-Am', 'add first migration');",
+			"call dolt_branch('other');",
+			"call dolt_migrate_add('main_m', 'create table main_t (pk int primary key)');",
+			"call dolt_commit('-Am', 'add migration on main');",
+			"call dolt_checkout('other');",
+			"call dolt_migrate_add('other_m', 'create table other_t (pk int primary key)');",
+			"call dolt_commit('-Am', 'add migration on other');",
+			"call dolt_checkout('main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "call dolt_cherry_pick('other')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select sequence, name from dolt_migrations",
+				Expected: []sql.Row{{1, "first"}, {2, "main_m"}, {3, "other_m"}},
+			},
+			{
+				Query:            "call dolt_revert('HEAD~1')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select sequence, name from dolt_migrations",
+				Expected: []sql.Row{{1, "first"}, {3, "other_m"}},
+			},
+		},
+	},
+}
+
+// DoltSequenceScripts contains tests of sequences. CREATE SEQUENCE and DROP SEQUENCE are rewritten by the engine's
+// parser, which prepared statements bypass in these tests, so they only run unprepared.
+var DoltSequenceScripts = []queries.ScriptTest{
+	{
+		Name: "sequences are created, used and dropped",
+		SetUpScript: []string{
+			"create table t (id bigint primary key, v varchar(10))",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "create sequence s start with 100 increment by 10",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select nextval('s'), nextval('S')",
+				Expected: []sql.Row{{int64(100), int64(110)}},
+			},
+			{
+				Query:    "insert into t values (nextval('s'), 'a'), (nextval('mydb.s'), 'b')",
+				Expected: []sql.Row{{types.NewOkResult(2)}},
+			},
+			{
+				Query:    "select * from t order by id",
+				Expected: []sql.Row{{int64(120), "a"}, {int64(130), "b"}},
+			},
+			{
+				Query:    "select * from dolt_sequences",
+				Expected: []sql.Row{{"s", int64(100), int64(10), int64(1), int64(9223372036854775807), int64(0), int64(4)}},
+			},
+			{
+				Query:          "create sequence s",
+				ExpectedErrStr: "sequence 's' already exists",
+			},
+			{
+				Query:    "create sequence if not exists s",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "drop sequence s",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:          "select nextval('s')",
+				ExpectedErrStr: "sequence 's' does not exist",
+			},
+			{
+				Query:          "drop sequence s",
+				ExpectedErrStr: "sequence 's' does not exist",
+			},
+			{
+				Query:    "drop sequence if exists s",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select count(*) from dolt_sequences",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "sequence bounds",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "create sequence d increment by -5 minvalue -12",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select nextval('d'), nextval('d'), nextval('d')",
+				Expected: []sql.Row{{int64(-1), int64(-6), int64(-11)}},
+			},
+			{
+				Query:          "select nextval('d')",
+				ExpectedErrStr: "sequence 'd' has run out of values",
+			},
+			{
+				Query:          "create sequence z increment by 0",
+				ExpectedErrStr: "invalid sequence 'z': the increment cannot be 0",
+			},
+			{
+				Query:          "create sequence z start with 0",
+				ExpectedErrStr: "invalid sequence 'z': the start value 0 is not between 1 and 9223372036854775807",
+			},
+			{
+				Query:          "call dolt_create_sequence('--start', 'one', 'z')",
+				ExpectedErrStr: "error: invalid value 'one' for --start, it must be an integer",
+			},
+		},
+	},
+	{
+		Name: "a failed statement does not keep rows with sequence values",
+		SetUpScript: []string{
+			"create table t (id bigint primary key default (nextval('s')), v varchar(10))",
+			"create sequence s",
+			"insert into t (v) values ('a')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "insert into t (id, v) values (nextval('s'), 'b'), (1, 'dup')",
+				ExpectedErrStr: "duplicate primary key given: [1]",
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{int64(1), "a"}},
+			},
+			{
+				Query:    "insert into t (v) values ('c')",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from t order by id",
+				Expected: []sql.Row{{int64(1), "a"}, {int64(3), "c"}},
+			},
+		},
+	},
+	{
+		Name: "reservations of sequence values are written when the transaction commits",
+		SetUpScript: []string{
+			"create table t (id bigint primary key, v varchar(10))",
+			"create table u (id bigint primary key, tid bigint)",
+			"create sequence s",
+			"create trigger trg after insert on t for each row insert into u values (nextval('s'), new.id)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into t values (nextval('s'), 'a'), (nextval('s'), 'b')",
+				Expected: []sql.Row{{types.NewOkResult(2)}},
+			},
+			{
+				Query:    "select * from t order by id",
+				Expected: []sql.Row{{int64(1), "a"}, {int64(2), "b"}},
+			},
+			{
+				Query:    "select * from u order by id",
+				Expected: []sql.Row{{int64(3), int64(1)}, {int64(4), int64(2)}},
+			},
+			{
+				Query:    "select reserved from dolt_sequences",
+				Expected: []sql.Row{{int64(4)}},
+			},
+			{
+				Query:    "start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select nextval('s')",
+				Expected: []sql.Row{{int64(5)}},
+			},
+			{
+				Query:    "rollback",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select nextval('s')",
+				Expected: []sql.Row{{int64(6)}},
+			},
+			{
+				Query:    "select reserved from dolt_sequences",
+				Expected: []sql.Row{{int64(6)}},
+			},
+		},
+	},
+	{
+		Name: "sequence values continue on each branch",
+		SetUpScript: []string{
+			"create sequence s",
+			"call dolt_commit('-Am', 'create sequence')",
+			"call dolt_branch('other')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select nextval('s'), nextval('s')",
+				Expected: []sql.Row{{int64(1), int64(2)}},
+			},
+			{
+				Query:    "call dolt_commit('-am', 'use sequence')",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:    "call dolt_checkout('other')",
+				Expected: []sql.Row{{0, "Switched to branch 'other'"}},
+			},
+			{
+				Query:    "select nextval('s')",
+				Expected: []sql.Row{{int64(1)}},
+			},
+			{
+				Query:    "call dolt_commit('-am', 'use sequence on other')",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:    "call dolt_checkout('main')",
+				Expected: []sql.Row{{0, "Switched to branch 'main'"}},
+			},
+			{
+				Query:    "call dolt_merge('other')",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select reserved from dolt_sequences",
+				Expected: []sql.Row{{int64(2)}},
+			},
+			{
+				Query:    "select nextval('s')",
+				Expected: []sql.Row{{int64(3)}},
+			},
+		},
+	},
+	{
+		Name: "sequences with branch ranges never reserve the same value on different branches",
+		SetUpScript: []string{
+			"create sequence s branch range 10",
+			"create table t (id bigint primary key default (nextval('s')), v varchar(10))",
+			"call dolt_commit('-Am', 'create sequence')",
+			"call dolt_branch('other')",
+			"insert into t (v) values ('main1'), ('main2')",
+			"call dolt_commit('-am', 'insert on main')",
+			"call dolt_checkout('other')",
+			"insert into t (v) values ('other1'), ('other2')",
+			"call dolt_commit('-am', 'insert on other')",
+			"call dolt_checkout('main')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other')",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query: "select * from t order by id",
+				Expected: []sql.Row{
+					{int64(1), "main1"},
+					{int64(2), "main2"},
+					{int64(11), "other1"},
+					{int64(12), "other2"},
+				},
+			},
+			{
+				Query:    "select reserved from dolt_sequences",
+				Expected: []sql.Row{{int64(20)}},
+			},
+			{
+				Query:    "insert into t (v) values ('main3')",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from t where v = 'main3'",
+				Expected: []sql.Row{{int64(3)}},
+			},
+		},
+	},
+}
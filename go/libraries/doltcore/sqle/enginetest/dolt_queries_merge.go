@@ -23,9 +23,12 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 )
 
@@ -3758,6 +3761,103 @@ var SchemaConflictScripts = []queries.ScriptTest{
 	},
 }
 
+var PreviewMergeConflictsFunctionScripts = []queries.ScriptTest{
+	{
+		Name: "preview data conflicts without touching the working set",
+		SetUpScript: []string{
+			"create table t (pk int primary key, col1 int);",
+			"create table u (pk int primary key, col1 int);",
+			"insert into t values (3, 300);",
+			"call dolt_commit('-Am', 'create tables');",
+			"call dolt_checkout('-b', 'other');",
+			"insert into t values (1, 100), (2, 200);",
+			"update t set col1 = 301 where pk = 3;",
+			"insert into u values (1, 1);",
+			"call dolt_commit('-Am', 'other commit');",
+			"call dolt_checkout('main');",
+			"insert into t values (1, -100), (2, 200);",
+			"delete from t where pk = 3;",
+			"call dolt_commit('-Am', 'main commit');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_preview_merge_conflicts_summary('main', 'other');",
+				Expected: []sql.Row{{"t", uint64(2), uint64(0), uint64(0)}},
+			},
+			{
+				Query: "select base_pk, base_col1, our_pk, our_col1, our_diff_type, their_pk, their_col1, their_diff_type, conflict_type from dolt_preview_merge_conflicts('main', 'other', 't') order by coalesce(our_pk, their_pk);",
+				Expected: []sql.Row{
+					{nil, nil, 1, -100, "added", 1, 100, "added", "added/added"},
+					{3, 300, nil, nil, "removed", 3, 301, "modified", "removed/modified"},
+				},
+			},
+			{
+				Query:    "select count(*) from dolt_preview_merge_conflicts('main', 'other', 'u');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from dolt_status;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select * from dolt_conflicts;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select * from dolt_preview_merge_conflicts_summary('main', 'main');",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "select * from dolt_preview_merge_conflicts('main', 'other', 'doesnotexist');",
+				ExpectedErrStr: "table not found: doesnotexist",
+			},
+			{
+				Query:       "select * from dolt_preview_merge_conflicts_summary('main');",
+				ExpectedErr: sql.ErrInvalidArgumentNumber,
+			},
+			{
+				Query:       "select * from dolt_preview_merge_conflicts_summary(?, 'other');",
+				Bindings:    map[string]*query.BindVariable{"v1": sqltypes.StringBindVariable("main")},
+				ExpectedErr: sqle.ErrInvalidNonLiteralArgument,
+			},
+			{
+				Query:       "select * from dolt_preview_merge_conflicts(?, 'other', 't');",
+				Bindings:    map[string]*query.BindVariable{"v1": sqltypes.StringBindVariable("main")},
+				ExpectedErr: sqle.ErrInvalidNonLiteralArgument,
+			},
+		},
+	},
+	{
+		Name: "preview schema conflicts and constraint violations",
+		SetUpScript: []string{
+			"create table t (pk int primary key, c0 varchar(20));",
+			"create table v (pk int primary key, col1 int unique);",
+			"call dolt_commit('-Am', 'create tables');",
+			"call dolt_checkout('-b', 'other');",
+			"alter table t modify column c0 int;",
+			"insert into v values (2, 1);",
+			"call dolt_commit('-am', 'altered t on branch other');",
+			"call dolt_checkout('main');",
+			"alter table t modify column c0 datetime(6);",
+			"insert into v values (1, 1);",
+			"call dolt_commit('-am', 'altered t on branch main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select * from dolt_preview_merge_conflicts_summary('main', 'other');",
+				Expected: []sql.Row{
+					{"t", uint64(0), uint64(1), uint64(0)},
+					{"v", uint64(0), uint64(0), uint64(2)},
+				},
+			},
+			{
+				Query:    "select * from dolt_schema_conflicts;",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+}
+
 // OldFormatMergeConflictsAndCVsScripts tests old format merge behavior
 // where violations are appended and merges are aborted if there are existing
 // violations and/or conflicts.
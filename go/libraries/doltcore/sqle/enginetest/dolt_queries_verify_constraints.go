@@ -795,6 +795,25 @@ var DoltVerifyConstraintsTestScripts = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name:        "verify-constraints: --all --output-only on a read only revision database",
+		SetUpScript: append(append([]string{}, verifyConstraintsFkViolationsSetupScript...), "call dolt_tag('v1', 'HEAD');"),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "use `mydb/v1`;",
+				Expected: []sql.Row{},
+			},
+			{
+				// the tagged commit has violations in child3 and child4
+				Query:    "call dolt_verify_constraints('--all', '--output-only');",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:          "call dolt_verify_constraints('--all');",
+				ExpectedErrStr: "this operation is not supported while in a detached head state",
+			},
+		},
+	},
 	// NOTE: We can't check NOT NULL constraint violations, since there isn't a way to disable NOT NULL enforcement
 	//       (like there is for foreign keys), and merging in a NOT NULL schema change to a table that has NULL values
 	//       causes the NULL values to be removed and listed in the dolt_constraint_violation table, so running
@@ -813,6 +813,636 @@ var DoltTransactionTests = []queries.TransactionTest{
 			},
 		},
 	},
+	{
+		Name: "committing to more than one database rolls back every database on conflict",
+		SetUpScript: []string{
+			"create table t1 (a int primary key, b int)",
+			"insert into t1 values (1, 1)",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'new table')",
+			"create database db2",
+			"use db2",
+			"create table t1 (a int primary key, b int)",
+			"insert into t1 values (1, 1)",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'new table')",
+			"use mydb",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ update t1 set b = 2 where a = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client a */ update db2.t1 set b = 2 where a = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client b */ update db2.t1 set b = 3 where a = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "/* client a */ commit",
+				ExpectedErrStr: sql.ErrLockDeadlock.New(dsess.ErrRetryTransaction.Error()).Error(),
+			},
+			{ // client a gets a rollback after failed commit, so neither database has its changes
+				Query:    "/* client a */ select * from mydb.t1 union all select * from db2.t1",
+				Expected: []sql.Row{{1, 1}, {1, 3}},
+			},
+		},
+	},
+}
+
+var DoltSerializableTransactionTests = []queries.TransactionTest{
+	{
+		Name: "serializable transactions abort on write skew",
+		SetUpScript: []string{
+			"create table accounts (id int primary key, balance int)",
+			"insert into accounts values (1, 100), (2, 100)",
+			"call dolt_add('.')",
+			"call dolt_commit('-m', 'create accounts')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ set session transaction isolation level serializable",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client b */ set session transaction isolation level serializable",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select sum(balance) from accounts",
+				Expected: []sql.Row{{float64(200)}},
+			},
+			{
+				Query:    "/* client b */ select sum(balance) from accounts",
+				Expected: []sql.Row{{float64(200)}},
+			},
+			{
+				Query:    "/* client a */ update accounts set balance = balance - 150 where id = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client b */ update accounts set balance = balance - 150 where id = 2",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "/* client b */ commit",
+				ExpectedErrStr: sql.ErrLockDeadlock.New(dsess.ErrSerializationFailure.Error()).Error(),
+			},
+			{ // client b's transaction was rolled back
+				Query:    "/* client b */ select * from accounts order by id",
+				Expected: []sql.Row{{1, -50}, {2, 100}},
+			},
+		},
+	},
+	{
+		Name: "repeatable read transactions allow write skew",
+		SetUpScript: []string{
+			"create table accounts (id int primary key, balance int)",
+			"insert into accounts values (1, 100), (2, 100)",
+			"call dolt_add('.')",
+			"call dolt_commit('-m', 'create accounts')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select sum(balance) from accounts",
+				Expected: []sql.Row{{float64(200)}},
+			},
+			{
+				Query:    "/* client b */ select sum(balance) from accounts",
+				Expected: []sql.Row{{float64(200)}},
+			},
+			{
+				Query:    "/* client a */ update accounts set balance = balance - 150 where id = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client b */ update accounts set balance = balance - 150 where id = 2",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select * from accounts order by id",
+				Expected: []sql.Row{{1, -50}, {2, -50}},
+			},
+		},
+	},
+	{
+		Name: "serializable transactions only conflict on writes into the ranges they read",
+		SetUpScript: []string{
+			"create table ledger (id int primary key, account int, amount int, key (account))",
+			"insert into ledger values (1, 1, 10), (2, 2, 20), (3, 3, 30)",
+			"call dolt_add('.')",
+			"call dolt_commit('-m', 'create ledger')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ set transaction_isolation = 'SERIALIZABLE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select sum(amount) from ledger where id <= 2",
+				Expected: []sql.Row{{float64(30)}},
+			},
+			{
+				Query:    "/* client b */ insert into ledger values (10, 3, 100)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ insert into ledger values (4, 1, -30)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{ // client b wrote outside the primary key range client a read
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select sum(amount) from ledger where account = 1",
+				Expected: []sql.Row{{float64(-20)}},
+			},
+			{
+				Query:    "/* client b */ insert into ledger values (11, 1, 5)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ insert into ledger values (5, 2, 1)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{ // client b inserted a row into the secondary index range client a read
+				Query:          "/* client a */ commit",
+				ExpectedErrStr: sql.ErrLockDeadlock.New(dsess.ErrSerializationFailure.Error()).Error(),
+			},
+			{
+				Query:    "/* client a */ select id from ledger order by id",
+				Expected: []sql.Row{{1}, {2}, {3}, {4}, {10}, {11}},
+			},
+		},
+	},
+	{
+		Name: "serializable read only transactions abort when a concurrent transaction wrote what they read",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2), (3, 3)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ set transaction_isolation = 'SERIALIZABLE'",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk <= 2",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+			{
+				Query:    "/* client b */ update t set v = 30 where pk = 3",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{ // client b wrote outside the range client a read
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk <= 2",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+			{
+				Query:    "/* client b */ update t set v = 10 where pk = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk <= 2",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+			{
+				Query:          "/* client a */ commit",
+				ExpectedErrStr: sql.ErrLockDeadlock.New(dsess.ErrSerializationFailure.Error()).Error(),
+			},
+			{
+				Query:    "/* client a */ select * from t where pk <= 2",
+				Expected: []sql.Row{{1, 10}, {2, 2}},
+			},
+		},
+	},
+}
+
+var DoltLockingReadTransactionTests = []queries.TransactionTest{
+	{
+		Name: "select for update locks rows against other locking reads",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int, k int, key (k))",
+			"insert into t values (1, 1, 10), (2, 2, 20), (3, 3, 30)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for update",
+				Expected: []sql.Row{{1, 1, 10}},
+			},
+			{
+				Query:          "/* client b */ select * from t where pk = 1 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:          "/* client b */ select * from t where k = 10 for share nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client b */ select pk from t for update skip locked",
+				Expected: []sql.Row{{2}, {3}},
+			},
+			{
+				Query:    "/* client b */ select pk from t where k >= 10 for share skip locked",
+				Expected: []sql.Row{{2}, {3}},
+			},
+			{ // reads which don't lock aren't blocked
+				Query:    "/* client b */ select * from t where pk = 1",
+				Expected: []sql.Row{{1, 1, 10}},
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select pk from t where k = 10 for update nowait",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:          "/* client a */ select * from t where pk = 1 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client b */ rollback",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for update nowait",
+				Expected: []sql.Row{{1, 1, 10}},
+			},
+		},
+	},
+	{
+		Name: "select for update of locks only the rows of the tables named",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"create table u (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2)",
+			"insert into u values (1, 10), (2, 20)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select t.pk, x.v from t join u as x on t.pk = x.pk where t.pk = 1 for update of x",
+				Expected: []sql.Row{{1, 10}},
+			},
+			{
+				Query:    "/* client b */ select * from t where pk = 1 for update nowait",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:          "/* client b */ select * from u where pk = 1 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client b */ select t.pk from t join u on t.pk = u.pk for update of t nowait",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select * from u where pk = 1 for update nowait",
+				Expected: []sql.Row{{1, 10}},
+			},
+		},
+	},
+	{
+		Name: "locking reads in stored procedures and through views",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2)",
+			"create view v as select * from t",
+			"create procedure claim() begin declare x int; select pk from t where v = 1 for update; end",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ call claim()",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:          "/* client b */ select * from t where pk = 1 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client a */ select * from v where pk = 2 for share",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:          "/* client b */ select * from t where pk = 2 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:          "/* client b */ select x.pk from v as x join t on x.pk = t.pk for update of x nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select pk from v for update nowait",
+				Expected: []sql.Row{{1}, {2}},
+			},
+		},
+	},
+	{
+		Name: "shared row locks",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for share",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:    "/* client b */ select * from t where pk = 1 lock in share mode",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:          "/* client a */ select * from t where pk = 1 for update nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{ // the sole holder of a shared lock can make it exclusive
+				Query:    "/* client a */ select * from t where pk = 1 for update nowait",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:          "/* client b */ select * from t for share nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+		},
+	},
+	{
+		Name: "locking reads outside of a transaction release their locks when the statement ends",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ select * from t for update",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:    "/* client b */ select * from t for update nowait",
+				Expected: []sql.Row{{1, 1}},
+			},
+		},
+	},
+	{
+		Name: "lock wait timeout fails the statement but not the transaction",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client b */ set innodb_lock_wait_timeout = 1",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ insert into t values (2, 2)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for update",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:          "/* client b */ select * from t where pk = 1 for update",
+				ExpectedErrStr: dsess.ErrLockWaitTimeout.Error(),
+			},
+			{
+				Query:    "/* client b */ select * from t order by pk",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+		},
+	},
+	{
+		Name: "locking read of a row changed since the transaction started",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t order by pk",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+			{
+				Query:    "/* client b */ update t set v = 10 where pk = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{ // rows which weren't changed can be locked
+				Query:    "/* client a */ select * from t where pk = 2 for update",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:          "/* client a */ select * from t where pk = 1 for update",
+				ExpectedErrStr: sql.ErrLockDeadlock.New(dsess.ErrLockedRowChanged.Error()).Error(),
+			},
+			{ // client a's transaction was rolled back, releasing its locks
+				Query:    "/* client b */ select * from t for update nowait",
+				Expected: []sql.Row{{1, 10}, {2, 2}},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for update",
+				Expected: []sql.Row{{1, 10}},
+			},
+		},
+	},
+	{
+		Name: "updates and deletes wait for row locks",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2), (3, 3)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "/* client b */ set innodb_lock_wait_timeout = 1",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t where pk = 1 for update",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:          "/* client b */ update t set v = 10 where pk = 1",
+				ExpectedErrStr: dsess.ErrLockWaitTimeout.Error(),
+			},
+			{
+				Query:          "/* client b */ delete from t where pk = 1",
+				ExpectedErrStr: dsess.ErrLockWaitTimeout.Error(),
+			},
+			{ // rows which aren't locked can be written
+				Query:    "/* client b */ update t set v = 20 where pk = 2",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{ // and writes lock the rows they write
+				Query:          "/* client a */ select * from t where pk = 2 for share nowait",
+				ExpectedErrStr: dsess.ErrLockNowait.Error(),
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ update t set v = 10 where pk = 1",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "/* client b */ delete from t where pk = 3",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "/* client b */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ select * from t order by pk",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+		},
+	},
 }
 
 var DoltConflictHandlingTests = []queries.TransactionTest{
@@ -2951,7 +3581,7 @@ var MultiDbTransactionTests = []queries.ScriptTest{
 			},
 			{
 				Query:          "commit",
-				ExpectedErrStr: "Cannot commit changes on more than one branch / database",
+				ExpectedErrStr: "Cannot commit changes on more than one branch of a database",
 			},
 		},
 	},
@@ -2983,7 +3613,7 @@ var MultiDbTransactionTests = []queries.ScriptTest{
 			},
 			{
 				Query:          "commit",
-				ExpectedErrStr: "Cannot commit changes on more than one branch / database",
+				ExpectedErrStr: "Cannot commit changes on more than one branch of a database",
 			},
 		},
 	},
@@ -3011,8 +3641,71 @@ var MultiDbTransactionTests = []queries.ScriptTest{
 				},
 			},
 			{
-				Query:          "commit",
-				ExpectedErrStr: "Cannot commit changes on more than one branch / database",
+				Query:    "commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select * from mydb.t1 union all select * from db2.t1",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "select count(*) from mydb.dolt_status",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select count(*) from db2.dolt_status",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
+	{
+		Name: "committing to more than one database at a time with dolt_transaction_commit",
+		SetUpScript: []string{
+			"create table t1 (a int)",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'new table')",
+			"create database db2",
+			"use db2",
+			"create table t1 (a int)",
+			"call dolt_add('.')",
+			"call dolt_commit('-am', 'new table')",
+			"use mydb",
+			"set autocommit = 0",
+			"set @@dolt_transaction_commit = 1",
+			"set @@dolt_transaction_commit_message = 'write to two databases'",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "insert into t1 values (1)",
+				Expected: []sql.Row{
+					{types.OkResult{RowsAffected: 1}},
+				},
+			},
+			{
+				Query: "insert into db2.t1 values (2)",
+				Expected: []sql.Row{
+					{types.OkResult{RowsAffected: 1}},
+				},
+			},
+			{
+				Query:    "commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select message from mydb.dolt_log limit 1",
+				Expected: []sql.Row{{"write to two databases"}},
+			},
+			{
+				Query:    "select message from db2.dolt_log limit 1",
+				Expected: []sql.Row{{"write to two databases"}},
+			},
+			{
+				Query:    "select count(*) from mydb.dolt_status",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select count(*) from db2.dolt_status",
+				Expected: []sql.Row{{0}},
 			},
 		},
 	},
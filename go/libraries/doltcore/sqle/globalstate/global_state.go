@@ -16,11 +16,13 @@ package globalstate
 
 import "github.com/dolthub/go-mysql-server/sql"
 
-// GlobalState is just a holding interface for pieces of global state, of which the auto increment tracking info is
-// the only example at the moment.
+// GlobalState is just a holding interface for pieces of global state, of which the auto increment and sequence
+// tracking info are the examples at the moment.
 type GlobalState interface {
 	// AutoIncrementTracker returns the auto increment tracker for this global state.
 	AutoIncrementTracker(ctx *sql.Context) (AutoIncrementTracker, error)
+	// SequenceTracker returns the sequence tracker for this global state.
+	SequenceTracker(ctx *sql.Context) (SequenceTracker, error)
 }
 
 // GlobalStateProvider is an optional interface for databases that provide global state tracking
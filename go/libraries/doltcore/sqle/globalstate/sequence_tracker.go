@@ -0,0 +1,32 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalstate
+
+import (
+	"github.com/dolthub/dolt/go/libraries/doltcore/sequences"
+)
+
+// SequenceTracker knows which values of the sequences of a database have been reserved by each of its branches. It's
+// defined as an interface here because implementations need to reach into session state, requiring a dependency on
+// this package.
+type SequenceTracker interface {
+	// Next reserves the next value of |seq| for the branch named |branch|. It returns the position of the value in the
+	// sequence, and the number of reserved values to record in the branch's row of the sequence, which is at least
+	// the number recorded there already.
+	Next(branch string, seq sequences.Sequence) (pos int64, reserved int64)
+	// Reset forgets the values of the sequence named |name| reserved for the branch named |branch|, because the
+	// sequence was created or dropped on it.
+	Reset(branch, name string)
+}
@@ -0,0 +1,385 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/util/sizecache"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// historyIntervalCacheSize is the approximate number of bytes of history interval indexes kept in memory. Each index
+// is for one table at one head commit, and is evicted when the indexes used more recently fill the cache.
+const historyIntervalCacheSize = 64 * 1024 * 1024
+
+var historyIntervalCache = sizecache.New(historyIntervalCacheSize)
+
+type historyIntervalKey struct {
+	head      hash.Hash
+	tableName string
+}
+
+// historyCommit is one of the commits of a table's history.
+type historyCommit struct {
+	h    hash.Hash
+	meta *datas.CommitMeta
+}
+
+// rowInterval is a run of consecutive commits in the history of a table during which a row had the same value, given
+// as positions in the history. |to| is the position of the first commit after the run, or -1 if the run extends to the
+// newest commit of the history.
+type rowInterval struct {
+	from, to int
+}
+
+// historyIntervalIndex indexes the history of a table by primary key. For each key, it records the intervals of
+// commits during which the row with that key had each of its values, so that the versions of a row can be found
+// without reading the table at every commit.
+//
+// The commits of the history are those reachable from a head commit, ordered from oldest to newest in the topological
+// order of dolt log. Commits of merged branches are interleaved with the commits they were merged into, so for
+// histories with merges a row's value can change back and forth as the history moves between branches.
+type historyIntervalIndex struct {
+	tableName string
+	commits   []historyCommit
+	positions map[hash.Hash]int
+	// the intervals of each primary key, ordered by position, keyed by the bytes of the key tuple
+	intervals map[string][]rowInterval
+	// the descriptor and column tags of the table's primary key at the newest commit it exists at
+	keyDesc val.TupleDesc
+	keyTags []uint64
+	// whether the table has the same primary key at every commit it exists at. If it doesn't, keys from different
+	// commits can't be compared.
+	uniformKeys bool
+	// the rows of the table at the newest commit, from which the index is extended
+	lastRows   prolly.Map
+	lastExists bool
+}
+
+// getHistoryIntervalIndex returns the history interval index of the table named at |head|. Indexes are built lazily
+// and cached by head commit. When |head| is a run of commits with a single parent on top of a cached head, the cached
+// index is extended rather than rebuilt.
+func getHistoryIntervalIndex(ctx context.Context, ddb *doltdb.DoltDB, head *doltdb.Commit, tableName string) (*historyIntervalIndex, error) {
+	tableName = strings.ToLower(tableName)
+	headHash, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	if idx, ok := historyIntervalCache.Get(historyIntervalKey{head: headHash, tableName: tableName}); ok {
+		return idx.(*historyIntervalIndex), nil
+	}
+
+	// look for a cached index for an ancestor of |head| along a run of commits with a single parent
+	var base *historyIntervalIndex
+	var newCommits []*doltdb.Commit
+	for cm := head; ; {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		if idx, ok := historyIntervalCache.Get(historyIntervalKey{head: h, tableName: tableName}); ok {
+			base = idx.(*historyIntervalIndex)
+			break
+		}
+		newCommits = append(newCommits, cm)
+		if cm.NumParents() != 1 {
+			break
+		}
+		optCmt, err := ddb.ResolveParent(ctx, cm, 0)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if cm, ok = optCmt.ToCommit(); !ok {
+			break
+		}
+	}
+
+	if base == nil {
+		base = &historyIntervalIndex{
+			tableName:   tableName,
+			positions:   make(map[hash.Hash]int),
+			intervals:   make(map[string][]rowInterval),
+			uniformKeys: true,
+		}
+		newCommits, err = historyCommits(ctx, ddb, headHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// commits were collected from newest to oldest
+	for i, j := 0, len(newCommits)-1; i < j; i, j = i+1, j-1 {
+		newCommits[i], newCommits[j] = newCommits[j], newCommits[i]
+	}
+
+	idx, err := base.extend(ctx, newCommits)
+	if err != nil {
+		return nil, err
+	}
+	// an index larger than the whole cache isn't cached
+	historyIntervalCache.Add(historyIntervalKey{head: headHash, tableName: tableName}, idx.size(), idx)
+	return idx, nil
+}
+
+// historyCommits returns the commits reachable from |head|, from newest to oldest in topological order.
+func historyCommits(ctx context.Context, ddb *doltdb.DoltDB, head hash.Hash) ([]*doltdb.Commit, error) {
+	itr, err := commitwalk.GetTopologicalOrderIterator(ctx, ddb, []hash.Hash{head}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var commits []*doltdb.Commit
+	for {
+		_, optCmt, err := itr.Next(ctx)
+		if err == io.EOF {
+			return commits, nil
+		} else if err != nil {
+			return nil, err
+		}
+		// ghost commits of shallow clones have no data to index
+		if cm, ok := optCmt.ToCommit(); ok {
+			commits = append(commits, cm)
+		}
+	}
+}
+
+// extend returns a copy of this index with |commits|, ordered from oldest to newest, appended to its history.
+func (idx *historyIntervalIndex) extend(ctx context.Context, commits []*doltdb.Commit) (*historyIntervalIndex, error) {
+	ext := &historyIntervalIndex{
+		tableName:   idx.tableName,
+		commits:     make([]historyCommit, len(idx.commits), len(idx.commits)+len(commits)),
+		positions:   make(map[hash.Hash]int, len(idx.positions)+len(commits)),
+		intervals:   make(map[string][]rowInterval, len(idx.intervals)),
+		keyDesc:     idx.keyDesc,
+		keyTags:     idx.keyTags,
+		uniformKeys: idx.uniformKeys,
+	}
+	copy(ext.commits, idx.commits)
+	for h, pos := range idx.positions {
+		ext.positions[h] = pos
+	}
+	// interval slices are shared with |idx| until they are changed
+	for k, ivs := range idx.intervals {
+		ext.intervals[k] = ivs
+	}
+	copied := make(map[string]bool)
+	update := func(k string) []rowInterval {
+		ivs := ext.intervals[k]
+		if !copied[k] {
+			ivs = append(make([]rowInterval, 0, len(ivs)+1), ivs...)
+			copied[k] = true
+		}
+		return ivs
+	}
+	open := func(k string, pos int) {
+		ext.intervals[k] = append(update(k), rowInterval{from: pos, to: -1})
+	}
+	closeAt := func(k string, pos int) {
+		ivs := update(k)
+		ivs[len(ivs)-1].to = pos
+		ext.intervals[k] = ivs
+	}
+	forEachKey := func(m prolly.Map, cb func(k string)) error {
+		iter, err := m.IterAll(ctx)
+		if err != nil {
+			return err
+		}
+		for {
+			k, _, err := iter.Next(ctx)
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			cb(string(k))
+		}
+	}
+
+	prev, prevExists := idx.lastRows, idx.lastExists
+
+	for _, cm := range commits {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		meta, err := cm.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pos := len(ext.commits)
+		ext.commits = append(ext.commits, historyCommit{h: h, meta: meta})
+		ext.positions[h] = pos
+
+		rows, sch, exists, err := tableRowsAtCommit(ctx, cm, idx.tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		var kd val.TupleDesc
+		var tags []uint64
+		if exists {
+			kd, _ = rows.Descriptors()
+			tags = sch.GetPKCols().Tags
+		}
+		switch {
+		case !prevExists && !exists:
+		case prevExists && exists && ext.hasKey(kd, tags):
+			err = prolly.DiffMaps(ctx, prev, rows, false, func(_ context.Context, d tree.Diff) error {
+				k := string(d.Key)
+				switch d.Type {
+				case tree.AddedDiff:
+					open(k, pos)
+				case tree.ModifiedDiff:
+					closeAt(k, pos)
+					open(k, pos)
+				case tree.RemovedDiff:
+					closeAt(k, pos)
+				}
+				return nil
+			})
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		default:
+			// the table was created or dropped, or its primary key changed, so every row changes
+			if prevExists {
+				if err := forEachKey(prev, func(k string) { closeAt(k, pos) }); err != nil {
+					return nil, err
+				}
+			}
+			if exists {
+				if ext.keyDesc.Count() > 0 && !ext.hasKey(kd, tags) {
+					ext.uniformKeys = false
+				}
+				ext.keyDesc, ext.keyTags = kd, tags
+				if err := forEachKey(rows, func(k string) { open(k, pos) }); err != nil {
+					return nil, err
+				}
+			}
+		}
+		prev, prevExists = rows, exists
+	}
+	ext.lastRows, ext.lastExists = prev, prevExists
+	return ext, nil
+}
+
+// tableRowsAtCommit returns the primary row data and schema of the table named at |cm|, and whether the table exists
+// at |cm| with a primary key.
+func tableRowsAtCommit(ctx context.Context, cm *doltdb.Commit, tableName string) (prolly.Map, schema.Schema, bool, error) {
+	root, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return prolly.Map{}, nil, false, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil || !ok {
+		return prolly.Map{}, nil, false, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return prolly.Map{}, nil, false, err
+	}
+	if schema.IsKeyless(sch) || !types.IsFormat_DOLT(tbl.Format()) {
+		return prolly.Map{}, nil, false, nil
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return prolly.Map{}, nil, false, err
+	}
+	return durable.ProllyMapFromIndex(idx), sch, true, nil
+}
+
+// size returns the approximate number of bytes of memory used by this index. Commit metadata and interval slices
+// shared with the index this one extended are counted by both.
+func (idx *historyIntervalIndex) size() uint64 {
+	const (
+		// the overhead of a map entry, beyond its key and value
+		mapEntrySize = 16
+		// the size of a string or slice header
+		headerSize = 24
+		// the sizes of a datas.CommitMeta, without its strings, and of a rowInterval
+		commitMetaSize  = 64
+		rowIntervalSize = 16
+	)
+	size := uint64(len(idx.tableName))
+	for _, cm := range idx.commits {
+		size += hash.ByteLen + 8 + commitMetaSize
+		size += uint64(len(cm.meta.Name) + len(cm.meta.Email) + len(cm.meta.Description))
+	}
+	size += uint64(len(idx.positions)) * (hash.ByteLen + 8 + mapEntrySize)
+	for k, ivs := range idx.intervals {
+		size += uint64(len(k)) + 2*headerSize + mapEntrySize + uint64(cap(ivs))*rowIntervalSize
+	}
+	return size
+}
+
+// hasKey returns whether keys with the descriptor |kd| of the primary key columns |tags| are comparable to the keys of
+// this index at its newest commit.
+func (idx *historyIntervalIndex) hasKey(kd val.TupleDesc, tags []uint64) bool {
+	if !kd.Equals(idx.keyDesc) || len(tags) != len(idx.keyTags) {
+		return false
+	}
+	for i := range tags {
+		if tags[i] != idx.keyTags[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// intervalAt returns the interval of the row with |key| which contains the commit at |pos|.
+func (idx *historyIntervalIndex) intervalAt(key val.Tuple, pos int) (rowInterval, bool) {
+	ivs := idx.intervals[string(key)]
+	i := sort.Search(len(ivs), func(i int) bool {
+		return ivs[i].from > pos
+	})
+	if i == 0 {
+		return rowInterval{}, false
+	}
+	iv := ivs[i-1]
+	if iv.to != -1 && iv.to <= pos {
+		return rowInterval{}, false
+	}
+	return iv, true
+}
+
+// end returns the position after the last commit of |iv|.
+func (idx *historyIntervalIndex) end(iv rowInterval) int {
+	if iv.to == -1 {
+		return len(idx.commits)
+	}
+	return iv.to
+}
+
+// validity returns the valid_from and valid_to values of |iv|: the dates of its first commit and of the commit which
+// followed its last commit, or nil if the interval extends to the newest commit.
+func (idx *historyIntervalIndex) validity(iv rowInterval) (interface{}, interface{}) {
+	validFrom := idx.commits[iv.from].meta.Time()
+	if iv.to == -1 {
+		return validFrom, nil
+	}
+	return validFrom, idx.commits[iv.to].meta.Time()
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/store/util/sizecache"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+func TestHistoryIntervalIndexIntervalAt(t *testing.T) {
+	key := val.Tuple("k")
+	idx := &historyIntervalIndex{
+		commits: make([]historyCommit, 6),
+		intervals: map[string][]rowInterval{
+			// the row exists at commits 0 and 1 with one value, is deleted at 2, and is reinserted at 4
+			string(key): {{from: 0, to: 2}, {from: 4, to: -1}},
+		},
+	}
+
+	tests := []struct {
+		pos int
+		iv  rowInterval
+		ok  bool
+	}{
+		{pos: 0, iv: rowInterval{from: 0, to: 2}, ok: true},
+		{pos: 1, iv: rowInterval{from: 0, to: 2}, ok: true},
+		{pos: 2},
+		{pos: 3},
+		{pos: 4, iv: rowInterval{from: 4, to: -1}, ok: true},
+		{pos: 5, iv: rowInterval{from: 4, to: -1}, ok: true},
+	}
+	for _, test := range tests {
+		iv, ok := idx.intervalAt(key, test.pos)
+		assert.Equal(t, test.ok, ok, "position %d", test.pos)
+		assert.Equal(t, test.iv, iv, "position %d", test.pos)
+	}
+
+	_, ok := idx.intervalAt(val.Tuple("other"), 0)
+	assert.False(t, ok)
+	assert.Equal(t, 2, idx.end(rowInterval{from: 0, to: 2}))
+	assert.Equal(t, 6, idx.end(rowInterval{from: 4, to: -1}))
+}
+
+func TestHistoryIntervalCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	dEnv := CreateTestEnv()
+	defer dEnv.DoltDB.Close()
+	tmpDir, err := dEnv.TempTableFilesDir()
+	require.NoError(t, err)
+	db, err := NewDatabase(ctx, "dolt", dEnv.DbData(), editor.Options{Deaf: dEnv.DbEaFactory(), Tempdir: tmpDir})
+	require.NoError(t, err)
+	engine, sqlCtx, err := NewTestEngine(dEnv, ctx, db)
+	require.NoError(t, err)
+	for _, query := range []string{
+		"create table a (pk int primary key, v int)",
+		"create table b (pk int primary key, v int)",
+		"insert into a values (1, 1), (2, 2)",
+		"insert into b values (1, 1), (2, 2), (3, 3)",
+		"call dolt_commit('-Am', 'first', '--author', 'test <test@example.com>')",
+		"update a set v = 10 where pk = 1",
+		"call dolt_commit('-am', 'second', '--author', 'test <test@example.com>')",
+	} {
+		_, iter, err := engine.Query(sqlCtx, query)
+		require.NoError(t, err)
+		for err == nil {
+			_, err = iter.Next(sqlCtx)
+		}
+		require.Equal(t, io.EOF, err)
+		require.NoError(t, iter.Close(sqlCtx))
+	}
+	head, err := dEnv.DoltDB.ResolveCommitRef(ctx, ref.NewBranchRef("main"))
+	require.NoError(t, err)
+	headHash, err := head.HashOf()
+	require.NoError(t, err)
+
+	prevCache := historyIntervalCache
+	defer func() {
+		historyIntervalCache = prevCache
+	}()
+
+	// find the sizes of the indexes of both tables
+	historyIntervalCache = sizecache.New(historyIntervalCacheSize)
+	a, err := getHistoryIntervalIndex(ctx, dEnv.DoltDB, head, "a")
+	require.NoError(t, err)
+	b, err := getHistoryIntervalIndex(ctx, dEnv.DoltDB, head, "b")
+	require.NoError(t, err)
+	assert.Len(t, a.commits, 3)
+	assert.Len(t, a.intervals, 2)
+	var changed int
+	for _, ivs := range a.intervals {
+		if len(ivs) == 2 {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed, "one row of a changed")
+	require.Greater(t, a.size(), uint64(0))
+	require.Greater(t, b.size(), a.size())
+
+	// a cache which can't hold both indexes evicts the one used least recently
+	historyIntervalCache = sizecache.New(a.size() + b.size() - 1)
+	_, err = getHistoryIntervalIndex(ctx, dEnv.DoltDB, head, "a")
+	require.NoError(t, err)
+	_, ok := historyIntervalCache.Get(historyIntervalKey{head: headHash, tableName: "a"})
+	assert.True(t, ok)
+	_, err = getHistoryIntervalIndex(ctx, dEnv.DoltDB, head, "b")
+	require.NoError(t, err)
+	_, ok = historyIntervalCache.Get(historyIntervalKey{head: headHash, tableName: "a"})
+	assert.False(t, ok)
+	_, ok = historyIntervalCache.Get(historyIntervalKey{head: headHash, tableName: "b"})
+	assert.True(t, ok)
+
+	// an index larger than the cache isn't cached at all
+	historyIntervalCache = sizecache.New(a.size() - 1)
+	_, err = getHistoryIntervalIndex(ctx, dEnv.DoltDB, head, "a")
+	require.NoError(t, err)
+	_, ok = historyIntervalCache.Get(historyIntervalKey{head: headHash, tableName: "a"})
+	assert.False(t, ok)
+}
@@ -27,10 +27,14 @@ import (
 	"github.com/dolthub/vitess/go/sqltypes"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/store/datas"
 	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	noms "github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
 )
 
 const (
@@ -42,6 +46,13 @@ const (
 
 	// CommitDateCol is the name of the column containing the commit date in the result set
 	CommitDateCol = "commit_date"
+
+	// ValidFromCol is the name of the column containing the date of the first commit at which a row had its value
+	ValidFromCol = "valid_from"
+
+	// ValidToCol is the name of the column containing the date of the first commit at which a row no longer had its
+	// value, or NULL if the row still has the value at the newest commit
+	ValidToCol = "valid_to"
 )
 
 var (
@@ -57,22 +68,32 @@ var _ sql.IndexAddressableTable = (*HistoryTable)(nil)
 var _ sql.IndexedTable = (*HistoryTable)(nil)
 var _ sql.PrimaryKeyTable = (*HistoryTable)(nil)
 
-// HistoryTable is a system table that shows the history of rows over time
+// HistoryTable is a system table that shows the history of rows over time. Each row of the table at each commit is a
+// row of the history table, along with the commit's metadata and the dates between which the row had that value.
+//
+// Since a row appears once for every commit at which it has a value, the filter `commit_date = valid_from` selects
+// one row for each version of a row. Lookups on the full primary key seek directly to the versions of the rows asked
+// for using a historyIntervalIndex, rather than reading the table at every commit.
 type HistoryTable struct {
 	doltTable                  *DoltTable
+	ddb                        *doltdb.DoltDB
+	head                       *doltdb.Commit
 	commitFilters              []sql.Expression
 	cmItr                      doltdb.CommitItr
 	commitCheck                doltdb.CommitFilter
 	indexLookup                sql.IndexLookup
 	projectedCols              []uint64
 	conversionWarningsByColumn map[string]struct{}
+	// whether to return each version of a row only once, at the first commit it has that value, rather than for every
+	// commit it has that value. Rows of tables without a primary key have no versions, and are returned every time.
+	versionsOnly bool
 }
 
 func (ht *HistoryTable) PrimaryKeySchema() sql.PrimaryKeySchema {
 	tableName := ht.Name()
 	basePkSch := ht.doltTable.PrimaryKeySchema()
 	newSch := sql.PrimaryKeySchema{
-		Schema:     make(sql.Schema, len(basePkSch.Schema), len(basePkSch.Schema)+3),
+		Schema:     make(sql.Schema, len(basePkSch.Schema), len(basePkSch.Schema)+len(historyColumnTags)),
 		PkOrdinals: basePkSch.PkOrdinals,
 	}
 
@@ -82,23 +103,9 @@ func (ht *HistoryTable) PrimaryKeySchema() sql.PrimaryKeySchema {
 		newSch.Schema[i] = col
 	}
 
-	newSch.Schema = append(newSch.Schema,
-		&sql.Column{
-			Name:   CommitHashCol,
-			Source: tableName,
-			Type:   CommitHashColType,
-		},
-		&sql.Column{
-			Name:   CommitterCol,
-			Source: tableName,
-			Type:   CommitterColType,
-		},
-		&sql.Column{
-			Name:   CommitDateCol,
-			Source: tableName,
-			Type:   types.Datetime,
-		},
-	)
+	for _, tag := range historyColumnTags {
+		newSch.Schema = append(newSch.Schema, historyColumn(tableName, tag))
+	}
 	return newSch
 }
 
@@ -165,10 +172,83 @@ func (ht *HistoryTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLooku
 		return &commitPartitioner{cmItr: iter}, nil
 
 	}
+	if lookup.Index.ID() == doltdb.PrimaryIndexName && len(ht.commitFilters) == 0 {
+		parts, ok, err := ht.seekPartitions(ctx, lookup)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			return sql.PartitionsToPartitionIter(parts...), nil
+		}
+	}
 	ht.indexLookup = lookup
 	return ht.Partitions(ctx)
 }
 
+// seekPartitions returns a historySeekPartition for each range of |lookup|, if every range is a point lookup on the
+// full primary key which can be found in the history interval index of the table.
+func (ht *HistoryTable) seekPartitions(ctx *sql.Context, lookup sql.IndexLookup) ([]sql.Partition, bool, error) {
+	pkCols := ht.doltTable.sch.GetPKCols()
+	if pkCols.Size() == 0 || !noms.IsFormat_DOLT(ht.ddb.Format()) {
+		return nil, false, nil
+	}
+	for _, col := range pkCols.GetColumns() {
+		// keys are found by their encoding, so they must compare equal only when their encodings are equal
+		if st, ok := col.TypeInfo.ToSqlType().(sql.StringType); ok {
+			coll := st.Collation()
+			if coll != sql.Collation_binary && !(strings.HasSuffix(coll.Name(), "_bin") && coll.PadAttribute() == "NO PAD") {
+				return nil, false, nil
+			}
+		}
+	}
+	for _, rng := range lookup.Ranges {
+		if len(rng) != pkCols.Size() {
+			return nil, false, nil
+		}
+		for _, expr := range rng {
+			lb, ok := expr.LowerBound.(sql.Below)
+			if !ok || lb.Key == nil {
+				return nil, false, nil
+			}
+			ub, ok := expr.UpperBound.(sql.Above)
+			if !ok || ub.Key == nil {
+				return nil, false, nil
+			}
+			if cmp, err := expr.Typ.Compare(lb.Key, ub.Key); err != nil || cmp != 0 {
+				return nil, false, err
+			}
+		}
+	}
+
+	idx, err := getHistoryIntervalIndex(ctx, ht.ddb, ht.head, ht.doltTable.Name())
+	if err != nil {
+		return nil, false, err
+	}
+	if !idx.uniformKeys || !idx.hasKey(ht.doltTable.sch.GetKeyDescriptor(), pkCols.Tags) {
+		return nil, false, nil
+	}
+
+	ns := ht.ddb.NodeStore()
+	tb := val.NewTupleBuilder(idx.keyDesc)
+	parts := make([]sql.Partition, 0, len(lookup.Ranges))
+	for _, rng := range lookup.Ranges {
+		for j, expr := range rng {
+			v, _, err := expr.Typ.Convert(sql.GetRangeCutKey(expr.LowerBound))
+			if err != nil {
+				// the key can't be in the table
+				return nil, false, nil
+			}
+			if err := tree.PutField(ctx, ns, tb, j, v); err != nil {
+				return nil, false, err
+			}
+		}
+		parts = append(parts, &historySeekPartition{
+			key:    tb.Build(ns.Pool()),
+			lookup: sql.IndexLookup{Index: lookup.Index, Ranges: sql.RangeCollection{rng}},
+		})
+	}
+	return parts, true, nil
+}
+
 // NewHistoryTable creates a history table
 func NewHistoryTable(table *DoltTable, ddb *doltdb.DoltDB, head *doltdb.Commit) sql.Table {
 	cmItr := doltdb.CommitItrForRoots(ddb, head)
@@ -181,6 +261,8 @@ func NewHistoryTable(table *DoltTable, ddb *doltdb.DoltDB, head *doltdb.Commit)
 
 	h := &HistoryTable{
 		doltTable:                  table,
+		ddb:                        ddb,
+		head:                       head,
 		cmItr:                      cmItr,
 		conversionWarningsByColumn: make(map[string]struct{}),
 	}
@@ -188,10 +270,10 @@ func NewHistoryTable(table *DoltTable, ddb *doltdb.DoltDB, head *doltdb.Commit)
 }
 
 // History table schema returns the corresponding history table schema for the base table given, which consists of
-// the table's schema with 3 additional columns
+// the table's schema with the additional columns of historyColumnTags
 func historyTableSchema(tableName string, table *DoltTable) sql.Schema {
 	baseSch := table.Schema().Copy()
-	newSch := make(sql.Schema, len(baseSch), len(baseSch)+3)
+	newSch := make(sql.Schema, len(baseSch), len(baseSch)+len(historyColumnTags))
 
 	for i, col := range baseSch {
 		// Returning a schema from a single table with multiple table names can confuse parts of the analyzer
@@ -199,26 +281,39 @@ func historyTableSchema(tableName string, table *DoltTable) sql.Schema {
 		newSch[i] = col
 	}
 
-	newSch = append(newSch,
-		&sql.Column{
-			Name:   CommitHashCol,
-			Source: tableName,
-			Type:   CommitHashColType,
-		},
-		&sql.Column{
-			Name:   CommitterCol,
-			Source: tableName,
-			Type:   CommitterColType,
-		},
-		&sql.Column{
-			Name:   CommitDateCol,
-			Source: tableName,
-			Type:   types.Datetime,
-		},
-	)
+	for _, tag := range historyColumnTags {
+		newSch = append(newSch, historyColumn(tableName, tag))
+	}
 	return newSch
 }
 
+// historyColumnTags are the tags of the columns the history table adds to the columns of the table, in schema order
+var historyColumnTags = []uint64{
+	schema.HistoryCommitHashTag,
+	schema.HistoryCommitterTag,
+	schema.HistoryCommitDateTag,
+	schema.HistoryValidFromTag,
+	schema.HistoryValidToTag,
+}
+
+// historyColumn returns the history table column with the tag given, or nil if |tag| isn't one of historyColumnTags
+func historyColumn(tableName string, tag uint64) *sql.Column {
+	switch tag {
+	case schema.HistoryCommitHashTag:
+		return &sql.Column{Name: CommitHashCol, Source: tableName, Type: CommitHashColType}
+	case schema.HistoryCommitterTag:
+		return &sql.Column{Name: CommitterCol, Source: tableName, Type: CommitterColType}
+	case schema.HistoryCommitDateTag:
+		return &sql.Column{Name: CommitDateCol, Source: tableName, Type: types.Datetime}
+	case schema.HistoryValidFromTag:
+		return &sql.Column{Name: ValidFromCol, Source: tableName, Type: types.Datetime, Nullable: true}
+	case schema.HistoryValidToTag:
+		return &sql.Column{Name: ValidToCol, Source: tableName, Type: types.Datetime, Nullable: true}
+	default:
+		return nil
+	}
+}
+
 func (ht *HistoryTable) filterIter(ctx *sql.Context, iter doltdb.CommitItr) (doltdb.CommitItr, error) {
 	if len(ht.commitFilters) > 0 {
 		r, err := ht.doltTable.db.GetRoot(ctx)
@@ -327,6 +422,10 @@ func (ht *HistoryTable) WithProjections(colNames []string) sql.Table {
 				nt.projectedCols[i] = schema.HistoryCommitterTag
 			case CommitDateCol:
 				nt.projectedCols[i] = schema.HistoryCommitDateTag
+			case ValidFromCol:
+				nt.projectedCols[i] = schema.HistoryValidFromTag
+			case ValidToCol:
+				nt.projectedCols[i] = schema.HistoryValidToTag
 			default:
 			}
 		} else {
@@ -350,16 +449,8 @@ func (ht *HistoryTable) Projections() []string {
 	for i := range ht.projectedCols {
 		if col, ok := cols.TagToCol[ht.projectedCols[i]]; ok {
 			names[i] = col.Name
-		} else {
-			switch ht.projectedCols[i] {
-			case schema.HistoryCommitHashTag:
-				names[i] = CommitHashCol
-			case schema.HistoryCommitterTag:
-				names[i] = CommitterCol
-			case schema.HistoryCommitDateTag:
-				names[i] = CommitDateCol
-			default:
-			}
+		} else if col := historyColumn(ht.Name(), ht.projectedCols[i]); col != nil {
+			names[i] = col.Name
 		}
 	}
 	return names
@@ -370,7 +461,18 @@ func (ht *HistoryTable) ProjectedTags() []uint64 {
 		return ht.projectedCols
 	}
 	// Otherwise (no projection), return the tags for the underlying table with the extra meta tags appended
-	return append(ht.doltTable.ProjectedTags(), schema.HistoryCommitHashTag, schema.HistoryCommitterTag, schema.HistoryCommitDateTag)
+	return append(ht.doltTable.ProjectedTags(), historyColumnTags...)
+}
+
+// projectsValidity returns whether the valid_from or valid_to columns are projected, which requires the history
+// interval index of the table.
+func (ht *HistoryTable) projectsValidity() bool {
+	for _, t := range ht.ProjectedTags() {
+		if t == schema.HistoryValidFromTag || t == schema.HistoryValidToTag {
+			return true
+		}
+	}
+	return false
 }
 
 // Name returns the name of the history table
@@ -396,24 +498,8 @@ func (ht *HistoryTable) Schema() sql.Schema {
 		if col, ok := allCols.TagToCol[t]; ok {
 			idx := sch.IndexOfColName(col.Name)
 			projectedSch[i] = sch[idx]
-		} else if t == schema.HistoryCommitterTag {
-			projectedSch[i] = &sql.Column{
-				Name:   CommitterCol,
-				Source: ht.Name(),
-				Type:   CommitterColType,
-			}
-		} else if t == schema.HistoryCommitHashTag {
-			projectedSch[i] = &sql.Column{
-				Name:   CommitHashCol,
-				Source: ht.Name(),
-				Type:   CommitHashColType,
-			}
-		} else if t == schema.HistoryCommitDateTag {
-			projectedSch[i] = &sql.Column{
-				Name:   CommitDateCol,
-				Source: ht.Name(),
-				Type:   types.Datetime,
-			}
+		} else if col := historyColumn(ht.Name(), t); col != nil {
+			projectedSch[i] = col
 		} else {
 			panic("column not found")
 		}
@@ -437,6 +523,18 @@ func (ht *HistoryTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error)
 
 // PartitionRows takes a partition and returns a row iterator for that partition
 func (ht *HistoryTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	if sp, ok := part.(*historySeekPartition); ok {
+		idx, err := getHistoryIntervalIndex(ctx, ht.ddb, ht.head, ht.doltTable.Name())
+		if err != nil {
+			return nil, err
+		}
+		return &historySeekIter{
+			ht:        ht,
+			idx:       idx,
+			intervals: idx.intervals[string(sp.key)],
+			lookup:    sp.lookup,
+		}, nil
+	}
 	cp := part.(*commitPartition)
 	return ht.newRowItrForTableAtCommit(ctx, ht.doltTable, cp.h, cp.cm, ht.indexLookup, ht.ProjectedTags())
 }
@@ -477,11 +575,154 @@ func (cp commitPartitioner) Close(ctx *sql.Context) error {
 	return nil
 }
 
+// newVersionsIter returns an iterator over the versions of the rows of the table at the commits of its history which
+// |include| accepts. Each version is returned once, at the first commit at which the row had that value. The table
+// must project the valid_from and valid_to columns, and only commits at which some row has a new version are read.
+func (ht *HistoryTable) newVersionsIter(ctx *sql.Context, include func(meta *datas.CommitMeta) bool) (sql.RowIter, error) {
+	idx, err := getHistoryIntervalIndex(ctx, ht.ddb, ht.head, ht.doltTable.Name())
+	if err != nil {
+		return nil, err
+	}
+	starts := make([]bool, len(idx.commits))
+	for _, ivs := range idx.intervals {
+		for _, iv := range ivs {
+			starts[iv.from] = true
+		}
+	}
+	var commits []historyCommit
+	for pos, c := range idx.commits {
+		if starts[pos] && include(c.meta) {
+			commits = append(commits, c)
+		}
+	}
+
+	nt := *ht
+	nt.versionsOnly = true
+	return &historyVersionsIter{ht: &nt, commits: commits}, nil
+}
+
+// historyVersionsIter returns the rows of a HistoryTable in versionsOnly mode for a list of commits
+type historyVersionsIter struct {
+	ht      *HistoryTable
+	commits []historyCommit
+	curr    sql.RowIter
+}
+
+// Next implements sql.RowIter
+func (i *historyVersionsIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		if i.curr == nil {
+			if len(i.commits) == 0 {
+				return nil, io.EOF
+			}
+			c := i.commits[0]
+			i.commits = i.commits[1:]
+			cm, err := doltdb.HashToCommit(ctx, i.ht.ddb.ValueReadWriter(), i.ht.ddb.NodeStore(), c.h)
+			if err != nil {
+				return nil, err
+			}
+			i.curr, err = i.ht.newRowItrForTableAtCommit(ctx, i.ht.doltTable, c.h, cm, sql.IndexLookup{}, i.ht.ProjectedTags())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		r, err := i.curr.Next(ctx)
+		if err == io.EOF {
+			err = i.curr.Close(ctx)
+			i.curr = nil
+			if err != nil {
+				return nil, err
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
+
+// Close implements sql.RowIter
+func (i *historyVersionsIter) Close(ctx *sql.Context) error {
+	if i.curr != nil {
+		return i.curr.Close(ctx)
+	}
+	return nil
+}
+
+// historySeekPartition is a point lookup of a single primary key across the history of a table
+type historySeekPartition struct {
+	key    val.Tuple
+	lookup sql.IndexLookup
+}
+
+// Key returns the key tuple of the row this partition looks up
+func (sp *historySeekPartition) Key() []byte {
+	return sp.key
+}
+
+// historySeekIter returns the rows of a historySeekPartition. The row is read once for each of its intervals in the
+// history interval index, at the first commit of the interval, and returned for every commit of the interval.
+type historySeekIter struct {
+	ht        *HistoryTable
+	idx       *historyIntervalIndex
+	intervals []rowInterval
+	lookup    sql.IndexLookup
+	// the rows of the current interval and the position of the next commit to return them for
+	rows []sql.Row
+	end  int
+	pos  int
+}
+
+// Next implements sql.RowIter
+func (i *historySeekIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for i.pos >= i.end || len(i.rows) == 0 {
+		if len(i.intervals) == 0 {
+			return nil, io.EOF
+		}
+		iv := i.intervals[0]
+		i.intervals = i.intervals[1:]
+		if err := i.readInterval(ctx, iv); err != nil {
+			return nil, err
+		}
+	}
+
+	// the primary key lookup returns at most one row
+	r := i.rows[0].Copy()
+	c := i.idx.commits[i.pos]
+	i.ht.setCommitColumns(r, i.ht.ProjectedTags(), c.h, c.meta)
+	i.pos++
+	return r, nil
+}
+
+func (i *historySeekIter) readInterval(ctx *sql.Context, iv rowInterval) error {
+	c := i.idx.commits[iv.from]
+	cm, err := doltdb.HashToCommit(ctx, i.ht.ddb.ValueReadWriter(), i.ht.ddb.NodeStore(), c.h)
+	if err != nil {
+		return err
+	}
+	iter, err := i.ht.newRowItrForTableAtCommit(ctx, i.ht.doltTable, c.h, cm, i.lookup, i.ht.ProjectedTags())
+	if err != nil {
+		return err
+	}
+	i.rows, err = sql.RowIterToRows(ctx, iter)
+	if err != nil {
+		return err
+	}
+	i.pos, i.end = iv.from, i.idx.end(iv)
+	return nil
+}
+
+// Close implements sql.RowIter
+func (i *historySeekIter) Close(ctx *sql.Context) error {
+	return nil
+}
+
 type historyIter struct {
 	table            sql.Table
 	tablePartitions  sql.PartitionIter
 	currPart         sql.RowIter
-	rowConverter     func(row sql.Row) sql.Row
+	rowConverter     func(row sql.Row) (sql.Row, error)
 	nonExistentTable bool
 }
 
@@ -499,7 +740,7 @@ func (ht *HistoryTable) newRowItrForTableAtCommit(ctx *sql.Context, table *DoltT
 	}
 
 	// TODO: schema
-	_, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: table.Name()})
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: table.Name()})
 	if err != nil {
 		return nil, err
 	}
@@ -512,6 +753,14 @@ func (ht *HistoryTable) newRowItrForTableAtCommit(ctx *sql.Context, table *DoltT
 		return nil, err
 	}
 
+	var validity rowValidityFunc
+	if ht.projectsValidity() {
+		lockedTable, validity, err = ht.rowValidityAtCommit(ctx, lockedTable.(*DoltTable), tbl, h)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var partIter sql.PartitionIter
 	var histTable sql.Table
 	if !lookup.IsEmpty() {
@@ -545,7 +794,7 @@ func (ht *HistoryTable) newRowItrForTableAtCommit(ctx *sql.Context, table *DoltT
 		}
 	}
 
-	converter := ht.rowConverter(ctx, lockedTable.Schema(), targetSchema, h, meta, projections)
+	converter := ht.rowConverter(ctx, lockedTable.Schema(), targetSchema, h, meta, projections, validity)
 	return &historyIter{
 		table:           histTable,
 		tablePartitions: partIter,
@@ -553,6 +802,80 @@ func (ht *HistoryTable) newRowItrForTableAtCommit(ctx *sql.Context, table *DoltT
 	}, nil
 }
 
+// rowValidityFunc returns the valid_from and valid_to values of a row of a table at a commit, and whether the row should
+// be skipped because another commit returns the same version of it.
+type rowValidityFunc func(row sql.Row) (validFrom interface{}, validTo interface{}, skip bool, err error)
+
+// rowValidityAtCommit returns a rowValidityFunc for the rows of |lockedTable|, which is the table |tbl| at the commit
+// with hash |h|. The returned table is |lockedTable| with its primary key columns added to its projections, since they
+// are needed to find the validity of rows. Rows of keyless tables have no validity.
+func (ht *HistoryTable) rowValidityAtCommit(ctx *sql.Context, lockedTable *DoltTable, tbl *doltdb.Table, h hash.Hash) (*DoltTable, rowValidityFunc, error) {
+	sch := lockedTable.sch
+	if schema.IsKeyless(sch) || !noms.IsFormat_DOLT(tbl.Format()) {
+		return lockedTable, nil, nil
+	}
+	idx, err := getHistoryIntervalIndex(ctx, ht.ddb, ht.head, ht.doltTable.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	pos, ok := idx.positions[h]
+	if !ok {
+		// commit hash lookups can ask for commits which aren't in the history of the head commit
+		return lockedTable, nil, nil
+	}
+
+	if projections := lockedTable.Projections(); projections != nil {
+		for _, col := range sch.GetPKCols().GetColumns() {
+			if !containsIgnoreCase(projections, col.Name) {
+				projections = append(projections, col.Name)
+			}
+		}
+		lockedTable = lockedTable.WithProjections(projections).(*DoltTable)
+	}
+
+	rows, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := durable.ProllyMapFromIndex(rows)
+	kd, _ := m.Descriptors()
+	ns := m.NodeStore()
+
+	srcSchema := lockedTable.Schema()
+	pkCols := sch.GetPKCols().GetColumns()
+	pkIdxs := make([]int, len(pkCols))
+	for i, col := range pkCols {
+		pkIdxs[i] = srcSchema.IndexOfColName(col.Name)
+	}
+
+	tb := val.NewTupleBuilder(kd)
+	return lockedTable, func(row sql.Row) (interface{}, interface{}, bool, error) {
+		for i, j := range pkIdxs {
+			if err := tree.PutField(ctx, ns, tb, i, row[j]); err != nil {
+				return nil, nil, false, err
+			}
+		}
+		iv, ok := idx.intervalAt(tb.Build(ns.Pool()), pos)
+		if !ok {
+			return nil, nil, false, nil
+		}
+		if ht.versionsOnly && iv.from != pos {
+			return nil, nil, true, nil
+		}
+		validFrom, validTo := idx.validity(iv)
+		return validFrom, validTo, false, nil
+	}, nil
+}
+
+func containsIgnoreCase(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // Next retrieves the next row. It will return io.EOF if it's the last row. After retrieving the last row, Close
 // will be automatically closed.
 func (i *historyIter) Next(ctx *sql.Context) (sql.Row, error) {
@@ -575,15 +898,24 @@ func (i *historyIter) Next(ctx *sql.Context) (sql.Row, error) {
 		return i.Next(ctx)
 	}
 
-	r, err := i.currPart.Next(ctx)
-	if err == io.EOF {
-		i.currPart = nil
-		return i.Next(ctx)
-	} else if err != nil {
-		return nil, err
-	}
+	for {
+		r, err := i.currPart.Next(ctx)
+		if err == io.EOF {
+			i.currPart = nil
+			return i.Next(ctx)
+		} else if err != nil {
+			return nil, err
+		}
 
-	return i.rowConverter(r), nil
+		r, err = i.rowConverter(r)
+		if err != nil {
+			return nil, err
+		}
+		// the converter returns nil for rows which are skipped
+		if r != nil {
+			return r, nil
+		}
+	}
 }
 
 func (i *historyIter) Close(ctx *sql.Context) error {
@@ -593,42 +925,79 @@ func (i *historyIter) Close(ctx *sql.Context) error {
 // rowConverter returns a function that converts a row to another schema for the dolt_history system tables. |srcSchema|
 // describes the incoming row, |targetSchema| describes the desired row schema, and |projections| controls which fields
 // are including the returned row. The hash |h| and commit metadata |meta| are used to augment the row with custom
-// fields for the dolt_history table to return commit metadata.
-func (ht *HistoryTable) rowConverter(ctx *sql.Context, srcSchema, targetSchema sql.Schema, h hash.Hash, meta *datas.CommitMeta, projections []uint64) func(row sql.Row) sql.Row {
-	srcToTarget := make(map[int]int)
-	for i, col := range targetSchema {
+// fields for the dolt_history table to return commit metadata, and |validity|, if non-nil, gives the valid_from and
+// valid_to fields of each row. The function returns a nil row for rows which |validity| skips.
+func (ht *HistoryTable) rowConverter(ctx *sql.Context, srcSchema, targetSchema sql.Schema, h hash.Hash, meta *datas.CommitMeta, projections []uint64, validity rowValidityFunc) func(row sql.Row) (sql.Row, error) {
+	// the index in the incoming row of each projected column, or -1 for columns which don't exist or can't be converted
+	srcIdxs := make([]int, len(projections))
+	cols := ht.doltTable.sch.GetAllCols()
+	for i, t := range projections {
+		srcIdxs[i] = -1
+		col, ok := cols.TagToCol[t]
+		if !ok {
+			continue
+		}
+		targetIdx := targetSchema.IndexOfColName(col.Name)
 		srcIdx := srcSchema.IndexOfColName(col.Name)
-		if srcIdx >= 0 {
-			// only add a conversion if the type is the same
-			// TODO: we could do a projection to convert between types in some cases
-			if srcSchema[srcIdx].Type.Equals(targetSchema[i].Type) {
-				srcToTarget[srcIdx] = i
-			} else {
-				if _, alreadyWarned := ht.conversionWarningsByColumn[col.Name]; !alreadyWarned {
-					ctx.Warn(1246, "Unable to convert field %s in historical rows because its type (%s) doesn't match "+
-						"current schema's type (%s)", col.Name, col.Type.String(), srcSchema[srcIdx].Type.String())
-					ht.conversionWarningsByColumn[col.Name] = struct{}{}
-				}
+		if srcIdx < 0 || targetIdx < 0 {
+			continue
+		}
+		// only add a conversion if the type is the same
+		// TODO: we could do a projection to convert between types in some cases
+		if srcSchema[srcIdx].Type.Equals(targetSchema[targetIdx].Type) {
+			srcIdxs[i] = srcIdx
+		} else {
+			if _, alreadyWarned := ht.conversionWarningsByColumn[col.Name]; !alreadyWarned {
+				ctx.Warn(1246, "Unable to convert field %s in historical rows because its type (%s) doesn't match "+
+					"current schema's type (%s)", col.Name, targetSchema[targetIdx].Type.String(), srcSchema[srcIdx].Type.String())
+				ht.conversionWarningsByColumn[col.Name] = struct{}{}
 			}
 		}
 	}
 
-	return func(row sql.Row) sql.Row {
+	return func(row sql.Row) (sql.Row, error) {
+		var validFrom, validTo interface{}
+		if validity != nil {
+			var skip bool
+			var err error
+			validFrom, validTo, skip, err = validity(row)
+			if err != nil || skip {
+				return nil, err
+			}
+		}
+
 		r := make(sql.Row, len(projections))
-		for i, t := range projections {
-			switch t {
-			case schema.HistoryCommitterTag:
-				r[i] = meta.Name
-			case schema.HistoryCommitDateTag:
-				r[i] = meta.Time()
-			case schema.HistoryCommitHashTag:
-				r[i] = h.String()
-			default:
-				if j, ok := srcToTarget[i]; ok {
-					r[j] = row[i]
+		for i, j := range srcIdxs {
+			if j >= 0 {
+				r[i] = row[j]
+			}
+		}
+		ht.setCommitColumns(r, projections, h, meta)
+		if validity != nil {
+			for i, t := range projections {
+				switch t {
+				case schema.HistoryValidFromTag:
+					r[i] = validFrom
+				case schema.HistoryValidToTag:
+					r[i] = validTo
 				}
 			}
 		}
-		return r
+		return r, nil
+	}
+}
+
+// setCommitColumns sets the commit metadata fields of |r|, a row with the columns of |projections|, to those of the
+// commit with hash |h| and metadata |meta|.
+func (ht *HistoryTable) setCommitColumns(r sql.Row, projections []uint64, h hash.Hash, meta *datas.CommitMeta) {
+	for i, t := range projections {
+		switch t {
+		case schema.HistoryCommitterTag:
+			r[i] = meta.Name
+		case schema.HistoryCommitDateTag:
+			r[i] = meta.Time()
+		case schema.HistoryCommitHashTag:
+			r[i] = h.String()
+		}
 	}
 }
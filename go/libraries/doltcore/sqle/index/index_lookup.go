@@ -221,6 +221,19 @@ func (rp rangePartition) Key() []byte {
 	return rp.key
 }
 
+// ProllyRangeForPartition returns the range of the index read for the lookup partition |part|, if it is a partition
+// of a prolly index.
+func ProllyRangeForPartition(part sql.Partition) (prolly.Range, bool) {
+	switch p := part.(type) {
+	case pointPartition:
+		return p.r, true
+	case rangePartition:
+		return p.prollyRange, p.nomsRange == nil
+	default:
+		return prolly.Range{}, false
+	}
+}
+
 // LookupBuilder generates secondary lookups for partitions and
 // encapsulates fast path optimizations for certain point lookups.
 type LookupBuilder interface {
@@ -69,6 +69,12 @@ func (idt *IndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Partition)
 		}
 	}
 
+	idt.recordIndexRead(ctx, idt.idx, part)
+	if locker, err := idt.newRowLocker(ctx); err != nil {
+		return nil, err
+	} else if locker != nil {
+		return idt.DoltTable.lockingIndexRows(ctx, key, idt.idx, part, locker)
+	}
 	return idt.lb.NewRowIter(ctx, part)
 }
 
@@ -86,6 +92,12 @@ func (idt *IndexedDoltTable) PartitionRows2(ctx *sql.Context, part sql.Partition
 		}
 	}
 
+	idt.recordIndexRead(ctx, idt.idx, part)
+	if locker, err := idt.newRowLocker(ctx); err != nil {
+		return nil, err
+	} else if locker != nil {
+		return idt.DoltTable.lockingIndexRows(ctx, key, idt.idx, part, locker)
+	}
 	return idt.lb.NewRowIter(ctx, part)
 }
 
@@ -135,6 +147,12 @@ func (t *WritableIndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Part
 		}
 	}
 
+	t.recordIndexRead(ctx, t.idx, part)
+	if locker, err := t.newRowLocker(ctx); err != nil {
+		return nil, err
+	} else if locker != nil {
+		return t.DoltTable.lockingIndexRows(ctx, key, t.idx, part, locker)
+	}
 	return t.lb.NewRowIter(ctx, part)
 }
 
@@ -0,0 +1,837 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// lockingReadClause matches the locking clause of a SELECT statement, e.g. FOR UPDATE, FOR SHARE OF t NOWAIT or
+// LOCK IN SHARE MODE.
+var lockingReadClause = regexp.MustCompile(`(?is)\b(for\s+update|for\s+share|lock\s+in\s+share\s+mode)(\s+of\s+[^;]+?)?(\s+(nowait|skip\s+locked))?\s*(;|$)`)
+
+func init() {
+	// Locking clauses reach the tables they lock through the filters of their query blocks, so the rule which applies
+	// them runs before any other rule rewrites filters.
+	analyzer.OnceBeforeDefault = append([]analyzer.Rule{{Id: applyLockingReadsId, Apply: applyLockingReads}}, analyzer.OnceBeforeDefault...)
+	// The statements of stored procedures are loaded when the procedures are applied to their calls, late in analysis,
+	// so the rule for them runs just after that.
+	for i, rule := range analyzer.OnceAfterAll {
+		if rule.Id == analyzer.AutocommitId {
+			rules := append([]analyzer.Rule{{Id: applyProcedureLockingReadsId, Apply: applyProcedureLockingReads}}, analyzer.OnceAfterAll[i:]...)
+			analyzer.OnceAfterAll = append(analyzer.OnceAfterAll[:i:i], rules...)
+			break
+		}
+	}
+}
+
+// The ids of the analyzer rules for locking reads are outside the range of the engine's rule ids.
+const (
+	applyLockingReadsId analyzer.RuleId = iota + 1000
+	applyProcedureLockingReadsId
+)
+
+// lockingReadMarkerPrefix begins the string literal of a locking read marker. The rest of the literal is the JSON
+// encoding of the lockingClause of the marker.
+const lockingReadMarkerPrefix = "dolt_locking_read:"
+
+// lockingClause is a locking clause of a query block, and the tables of the block it locks the rows of.
+type lockingClause struct {
+	Read   dsess.LockingRead `json:"read"`
+	Tables []lockedTable     `json:"tables"`
+}
+
+// lockedTable is a table named in the FROM clause of a query block, by its alias if it has one. |DB| is empty if the
+// name isn't qualified.
+type lockedTable struct {
+	DB   string `json:"db,omitempty"`
+	Name string `json:"name"`
+}
+
+// locks returns whether the clause locks the rows of the table, view or alias named |name| in the database |db|.
+func (c lockingClause) locks(db, name string) bool {
+	for _, tbl := range c.Tables {
+		if strings.EqualFold(tbl.Name, name) && (tbl.DB == "" || db == "" || strings.EqualFold(tbl.DB, db)) {
+			return true
+		}
+	}
+	return false
+}
+
+// markLockingReads records the locking clauses of the query blocks of |stmt| in the blocks, so that they reach the
+// plan built from it. The engine ignores the Lock of a query block, so each locked block gets a marker in its WHERE
+// clause: an IS NOT NULL test of a string literal holding the clause, which is always true. applyLockingReads removes
+// the markers from the plan and locks the tables below them.
+func markLockingReads(stmt sqlparser.Statement, ansiQuotes bool) error {
+	blocks, clauses, err := lockingClausesForStatement(stmt, ansiQuotes)
+	if err != nil {
+		return err
+	}
+	for i, sel := range blocks {
+		if len(clauses[i].Tables) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(clauses[i])
+		if err != nil {
+			return err
+		}
+		marker := &sqlparser.IsExpr{
+			Operator: sqlparser.IsNotNullStr,
+			Expr:     sqlparser.NewStrVal(append([]byte(lockingReadMarkerPrefix), payload...)),
+		}
+		if sel.Where == nil {
+			sel.Where = sqlparser.NewWhere(sqlparser.WhereStr, marker)
+		} else {
+			sel.Where.Expr = &sqlparser.AndExpr{Left: sel.Where.Expr, Right: marker}
+		}
+	}
+	return nil
+}
+
+// lockingClauseOfMarker returns the locking clause of |e| if it's a locking read marker.
+func lockingClauseOfMarker(e sql.Expression) (lockingClause, bool, error) {
+	not, ok := e.(*expression.Not)
+	if !ok {
+		return lockingClause{}, false, nil
+	}
+	isNull, ok := not.Child.(*expression.IsNull)
+	if !ok {
+		return lockingClause{}, false, nil
+	}
+	lit, ok := isNull.Child.(*expression.Literal)
+	if !ok {
+		return lockingClause{}, false, nil
+	}
+	str, ok := lit.Value().(string)
+	if !ok || !strings.HasPrefix(str, lockingReadMarkerPrefix) {
+		return lockingClause{}, false, nil
+	}
+	var clause lockingClause
+	if err := json.Unmarshal([]byte(str[len(lockingReadMarkerPrefix):]), &clause); err != nil {
+		return lockingClause{}, false, err
+	}
+	return clause, true, nil
+}
+
+// applyLockingReads is an analyzer rule which removes the locking read markers of markLockingReads from the filters
+// of |n|, and sets the row locks of the tables each marker locks. These are the tables of the FROM clause of the
+// marker's query block, and the tables read through the views and common table expressions it names, but not the
+// tables of derived tables and subqueries, which need their own locking clause.
+func applyLockingReads(ctx *sql.Context, a *analyzer.Analyzer, n sql.Node, scope *plan.Scope, sel analyzer.RuleSelector) (sql.Node, transform.TreeIdentity, error) {
+	return transform.NodeWithOpaque(n, func(n sql.Node) (sql.Node, transform.TreeIdentity, error) {
+		filter, ok := n.(*plan.Filter)
+		if !ok {
+			return n, transform.SameTree, nil
+		}
+		var clauses []lockingClause
+		var rest []sql.Expression
+		for _, e := range expression.SplitConjunction(filter.Expression) {
+			clause, ok, err := lockingClauseOfMarker(e)
+			if err != nil {
+				return nil, transform.SameTree, err
+			} else if ok {
+				clauses = append(clauses, clause)
+			} else {
+				rest = append(rest, e)
+			}
+		}
+		if len(clauses) == 0 {
+			return n, transform.SameTree, nil
+		}
+
+		child := filter.Child
+		for _, clause := range clauses {
+			var err error
+			child, err = lockFromTables(child, clause)
+			if err != nil {
+				return nil, transform.SameTree, err
+			}
+		}
+		if len(rest) == 0 {
+			return child, transform.NewTree, nil
+		}
+		return plan.NewFilter(expression.JoinAnd(rest...), child), transform.NewTree, nil
+	})
+}
+
+// lockFromTables sets the row locks of the tables of the FROM clause |n| which |clause| locks.
+func lockFromTables(n sql.Node, clause lockingClause) (sql.Node, error) {
+	return lockNamedTables(n, clause, false)
+}
+
+// lockNamedTables sets the row locks of the tables, aliases and views of |n| which |clause| names. If |subqueries| is
+// true, the tables of subquery expressions are locked as well.
+func lockNamedTables(n sql.Node, clause lockingClause, subqueries bool) (sql.Node, error) {
+	switch n := n.(type) {
+	case *plan.ResolvedTable, *plan.ProcedureResolvedTable, *plan.IndexedTableAccess:
+		var db string
+		if dbn, ok := n.(sql.Databaser); ok && dbn.Database() != nil {
+			db = dbn.Database().Name()
+		}
+		if !clause.locks(db, n.(sql.Nameable).Name()) {
+			return n, nil
+		}
+		return lockTables(n, clause.Read)
+	case *plan.TableAlias, *plan.SubqueryAlias:
+		// derived tables are never named by a clause, since they aren't tables of the FROM clause it was parsed from
+		if !clause.locks("", n.(sql.Nameable).Name()) {
+			return n, nil
+		}
+		return lockTables(n, clause.Read)
+	}
+
+	var err error
+	if children := n.Children(); len(children) > 0 {
+		newChildren := make([]sql.Node, len(children))
+		for i, child := range children {
+			newChildren[i], err = lockNamedTables(child, clause, subqueries)
+			if err != nil {
+				return nil, err
+			}
+		}
+		n, err = n.WithChildren(newChildren...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !subqueries {
+		return n, nil
+	}
+	n, _, err = transform.OneNodeExpressions(n, func(e sql.Expression) (sql.Expression, transform.TreeIdentity, error) {
+		return transform.Expr(e, func(e sql.Expression) (sql.Expression, transform.TreeIdentity, error) {
+			sq, ok := e.(*plan.Subquery)
+			if !ok {
+				return e, transform.SameTree, nil
+			}
+			query, err := lockNamedTables(sq.Query, clause, subqueries)
+			if err != nil {
+				return nil, transform.SameTree, err
+			}
+			return sq.WithQuery(query), transform.NewTree, nil
+		})
+	})
+	return n, err
+}
+
+// lockTables sets the row locks of every Dolt table read by |n| to |read|.
+func lockTables(n sql.Node, read dsess.LockingRead) (sql.Node, error) {
+	n, _, err := transform.NodeWithOpaque(n, func(n sql.Node) (sql.Node, transform.TreeIdentity, error) {
+		switch n := n.(type) {
+		case *plan.ResolvedTable:
+			tbl, ok := n.Table.(lockingReadTable)
+			if !ok {
+				return n, transform.SameTree, nil
+			}
+			newRt, err := n.WithTable(tbl.withLockingRead(read))
+			if err != nil {
+				return nil, transform.SameTree, err
+			}
+			return newRt, transform.NewTree, nil
+		case *plan.ProcedureResolvedTable:
+			// a procedure's tables are read again from their database for each statement, which would lose the locks
+			// of the table locked below. Dolt tables always read the latest root value of their database anyway.
+			if _, ok := n.ResolvedTable.Table.(lockingReadTable); ok {
+				return n.ResolvedTable, transform.NewTree, nil
+			}
+		case *plan.IndexedTableAccess:
+			tbl, ok := n.Table.(lockingReadTable)
+			if !ok {
+				return n, transform.SameTree, nil
+			}
+			newIta, err := n.WithTable(tbl.withLockingRead(read).(sql.IndexedTable))
+			if err != nil {
+				return nil, transform.SameTree, err
+			}
+			return newIta, transform.NewTree, nil
+		}
+		return n, transform.SameTree, nil
+	})
+	return n, err
+}
+
+// applyProcedureLockingReads is an analyzer rule which sets the row locks of the tables read by the locking reads of
+// the stored procedures called by |n|. The engine parses the statements of stored procedures itself, so they carry no
+// locking read markers. Instead, the definition of each procedure called is parsed again to find the locking clauses
+// of its statements, which lock the tables they name in the statements of the procedure's body.
+func applyProcedureLockingReads(ctx *sql.Context, a *analyzer.Analyzer, n sql.Node, scope *plan.Scope, sel analyzer.RuleSelector) (sql.Node, transform.TreeIdentity, error) {
+	options := sql.LoadSqlMode(ctx).ParserOptions()
+	return transform.NodeWithOpaque(n, func(n sql.Node) (sql.Node, transform.TreeIdentity, error) {
+		call, ok := n.(*plan.Call)
+		if !ok || call.Procedure == nil || !lockingReadKeywords.MatchString(call.Procedure.CreateProcedureString) {
+			return n, transform.SameTree, nil
+		}
+		stmt, _, err := lockingReadDefinitionParser.ParseOneWithOptions(call.Procedure.CreateProcedureString, options)
+		if err != nil {
+			return nil, transform.SameTree, err
+		}
+		ddl, ok := stmt.(*sqlparser.DDL)
+		if !ok || ddl.ProcedureSpec == nil {
+			return n, transform.SameTree, nil
+		}
+		body, err := lockProcedureStatement(ddl.ProcedureSpec.Body, call.Procedure.Body, options.AnsiQuotes)
+		if err != nil {
+			return nil, transform.SameTree, err
+		}
+		proc := *call.Procedure
+		proc.Body = body
+		return call.WithProcedure(&proc), transform.NewTree, nil
+	})
+}
+
+// lockProcedureStatement sets the row locks of the tables read by |n|, the plan of the statement |stmt| of a stored
+// procedure. The statements of a BEGIN ... END block are matched with those of its plan, so that each locks only the
+// tables its own locking clauses name.
+func lockProcedureStatement(stmt sqlparser.Statement, n sql.Node, ansiQuotes bool) (sql.Node, error) {
+	if block, ok := stmt.(*sqlparser.BeginEndBlock); ok {
+		if nBlock, ok := n.(*plan.BeginEndBlock); ok && len(nBlock.Children()) == len(block.Statements) {
+			children := nBlock.Children()
+			newChildren := make([]sql.Node, len(children))
+			for i := range children {
+				var err error
+				newChildren[i], err = lockProcedureStatement(block.Statements[i], children[i], ansiQuotes)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return nBlock.WithChildren(newChildren...)
+		}
+	}
+
+	_, clauses, err := lockingClausesForStatement(stmt, ansiQuotes)
+	if err != nil {
+		return nil, err
+	}
+	for _, clause := range clauses {
+		n, err = lockNamedTables(n, clause, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// lockingReadKeywords matches the keywords of locking clauses, to quickly rule out statements without them.
+var lockingReadKeywords = regexp.MustCompile(`(?i)\bfor\s+(update|share)\b|\block\s+in\s+share\b`)
+
+// lockingReadDefinitionParser parses the definitions of stored procedures to find the locking clauses of their
+// statements. It accepts every statement the engine's parser does.
+var lockingReadDefinitionParser = NewDoltParser(sql.NewMysqlParser())
+
+// lockingReadTable is a table whose reads can lock the rows they read.
+type lockingReadTable interface {
+	sql.Table
+	// withLockingRead returns a copy of the table whose reads take the row locks |read|. A table locked by several
+	// clauses takes the strongest of their locks.
+	withLockingRead(read dsess.LockingRead) sql.Table
+}
+
+// lockingClausesForStatement returns the locked query blocks of |stmt| and their locking clauses. A locking clause
+// locks the rows of the tables in the FROM clause of its query block, or only those it names with OF, and not the rows
+// read by subqueries, which need their own locking clause. The locking clause of a set operation applies to each of
+// its query blocks without one.
+func lockingClausesForStatement(stmt sqlparser.Statement, ansiQuotes bool) ([]*sqlparser.Select, []lockingClause, error) {
+	var blocks []*sqlparser.Select
+	var clauses []lockingClause
+	addBlock := func(sel *sqlparser.Select, lock string) error {
+		read, of, ok := parseLockingClause(lock, ansiQuotes)
+		if !ok {
+			return fmt.Errorf("unable to parse locking clause '%s'", strings.TrimSpace(lock))
+		}
+		clause := lockingClause{Read: read}
+		for _, tbl := range fromTables(sel.From) {
+			if of == nil || tbl.namedBy(of) {
+				clause.Tables = append(clause.Tables, tbl.lockedName())
+			}
+		}
+		blocks = append(blocks, sel)
+		clauses = append(clauses, clause)
+		return nil
+	}
+	var inheritLock func(sel sqlparser.SelectStatement, lock string) error
+	inheritLock = func(sel sqlparser.SelectStatement, lock string) error {
+		switch sel := sel.(type) {
+		case *sqlparser.Select:
+			if sel.Lock == "" {
+				return addBlock(sel, lock)
+			}
+		case *sqlparser.SetOp:
+			if sel.Lock == "" {
+				if err := inheritLock(sel.Left, lock); err != nil {
+					return err
+				}
+				return inheritLock(sel.Right, lock)
+			}
+		case *sqlparser.ParenSelect:
+			return inheritLock(sel.Select, lock)
+		}
+		// query blocks with their own locking clause are added as they are walked
+		return nil
+	}
+
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			if node.Lock != "" {
+				return true, addBlock(node, node.Lock)
+			}
+		case *sqlparser.SetOp:
+			if node.Lock != "" {
+				if err := inheritLock(node.Left, node.Lock); err != nil {
+					return false, err
+				}
+				return true, inheritLock(node.Right, node.Lock)
+			}
+		}
+		return true, nil
+	}, stmt)
+	return blocks, clauses, err
+}
+
+// fromTable is a table in the FROM clause of a query block.
+type fromTable struct {
+	table lockedTable
+	alias string
+}
+
+// lockedName returns the name of the table in the locking clauses which lock it.
+func (t fromTable) lockedName() lockedTable {
+	if t.alias != "" {
+		return lockedTable{Name: t.alias}
+	}
+	return t.table
+}
+
+// namedBy returns whether |names|, the tables named by the OF option of a locking clause, include this table. A table
+// with an alias is named by its alias.
+func (t fromTable) namedBy(names []lockedTable) bool {
+	for _, name := range names {
+		switch {
+		case t.alias != "":
+			if name.DB == "" && strings.EqualFold(name.Name, t.alias) {
+				return true
+			}
+		case strings.EqualFold(name.Name, t.table.Name):
+			if name.DB == "" || strings.EqualFold(name.DB, t.table.DB) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fromTables returns the tables of the FROM clause |exprs|, leaving out derived tables and table functions.
+func fromTables(exprs sqlparser.TableExprs) []fromTable {
+	var tables []fromTable
+	for _, expr := range exprs {
+		switch expr := expr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if name, ok := expr.Expr.(sqlparser.TableName); ok {
+				tables = append(tables, fromTable{
+					table: lockedTable{DB: name.DbQualifier.String(), Name: name.Name.String()},
+					alias: expr.As.String(),
+				})
+			}
+		case *sqlparser.JoinTableExpr:
+			tables = append(tables, fromTables(sqlparser.TableExprs{expr.LeftExpr, expr.RightExpr})...)
+		case *sqlparser.ParenTableExpr:
+			tables = append(tables, fromTables(expr.Exprs)...)
+		}
+	}
+	return tables
+}
+
+// parseLockingClause parses the locking clause |lock| of a query block, as the grammar or lockingReadParser records
+// it, e.g. " for update" or " FOR SHARE OF t1, t2 NOWAIT". It returns the row locks the clause takes and the tables
+// named by its OF option, which are nil if it has none.
+func parseLockingClause(lock string, ansiQuotes bool) (dsess.LockingRead, []lockedTable, bool) {
+	toks := tokenizeSql(lock, ';', lexMode{ansiQuotes: ansiQuotes})
+	word := func(i int, words ...string) bool {
+		for j, w := range words {
+			if i+j >= len(toks) || toks[i+j].word != w {
+				return false
+			}
+		}
+		return true
+	}
+
+	var read dsess.LockingRead
+	var i int
+	switch {
+	case word(0, "for", "update"):
+		read.Exclusive = true
+		i = 2
+	case word(0, "for", "share"):
+		i = 2
+	case word(0, "lock", "in", "share", "mode"):
+		i = 4
+	default:
+		return dsess.LockingRead{}, nil, false
+	}
+
+	var of []lockedTable
+	if word(i, "of") {
+		for i++; ; i++ {
+			if i >= len(toks) || !toks[i].isIdent() {
+				return dsess.LockingRead{}, nil, false
+			}
+			tbl := lockedTable{Name: toks[i].ident(lock)}
+			if i+2 < len(toks) && toks[i+1].is('.') && toks[i+2].isIdent() {
+				tbl = lockedTable{DB: tbl.Name, Name: toks[i+2].ident(lock)}
+				i += 2
+			}
+			of = append(of, tbl)
+			if i+1 >= len(toks) || !toks[i+1].is(',') {
+				i++
+				break
+			}
+			i++
+		}
+	}
+
+	switch {
+	case word(i, "nowait"):
+		read.Wait = dsess.RowLockNoWait
+		i++
+	case word(i, "skip", "locked"):
+		read.Wait = dsess.RowLockSkipLocked
+		i += 2
+	}
+	for ; i < len(toks); i++ {
+		if toks[i].kind != sqlTokenEnd {
+			return dsess.LockingRead{}, nil, false
+		}
+	}
+	return read, of, true
+}
+
+// lockingReadParser is a sql.Parser which accepts the locking clauses of SELECT statements which the grammar doesn't
+// support, such as FOR SHARE, NOWAIT and OF. A statement which fails to parse is parsed again without its locking
+// clause, which is then recorded as the Lock of the statement, as the grammar does for the clauses it supports. The
+// engine ignores Lock, so the locking clauses of every statement it parses are also marked for the plan; see
+// markLockingReads.
+type lockingReadParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = lockingReadParser{}
+var _ modeParser = lockingReadParser{}
+
+// NewLockingReadParser returns a parser which parses statements with |inner|, accepting locking clauses |inner|
+// doesn't support.
+func NewLockingReadParser(inner sql.Parser) sql.Parser {
+	return lockingReadParser{Parser: inner}
+}
+
+// stripLockingClause returns |query| without the first locking clause which ends a statement, the offset the clause
+// began at, and its length, which includes any whitespace before the statement delimiter.
+func stripLockingClause(query string) (string, int, int, bool) {
+	loc := lockingReadClause.FindStringSubmatchIndex(query)
+	if loc == nil {
+		return query, 0, 0, false
+	}
+	// keep the statement delimiter, if there is one
+	end := loc[10]
+	return query[:loc[0]] + query[end:], loc[0], end - loc[0], true
+}
+
+// ParseSimple implements sql.Parser.
+func (p lockingReadParser) ParseSimple(query string) (sqlparser.Statement, error) {
+	stmt, err := p.Parser.ParseSimple(query)
+	if err != nil {
+		if stripped, start, n, ok := stripLockingClause(query); ok {
+			if strippedStmt, strippedErr := p.Parser.ParseSimple(stripped); strippedErr == nil {
+				setLockingClause(strippedStmt, query[start:start+n])
+				stmt, err = strippedStmt, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stmt, markLockingReads(stmt, false)
+}
+
+// Parse implements sql.Parser.
+func (p lockingReadParser) Parse(ctx *sql.Context, query string, multi bool) (sqlparser.Statement, string, string, error) {
+	return parseCtx(ctx, p, query, multi)
+}
+
+// ParseWithOptions implements sql.Parser.
+func (p lockingReadParser) ParseWithOptions(query string, delimiter rune, multi bool, options sqlparser.ParserOptions) (sqlparser.Statement, string, string, error) {
+	return p.parseWithMode(query, delimiter, multi, options, lexModeOfOptions(options))
+}
+
+// parseWithMode implements modeParser.
+func (p lockingReadParser) parseWithMode(query string, delimiter rune, multi bool, options sqlparser.ParserOptions, mode lexMode) (sqlparser.Statement, string, string, error) {
+	stmt, parsed, remainder, err := parseWithMode(p.Parser, query, delimiter, multi, options, mode)
+	if err == nil {
+		return stmt, parsed, remainder, markLockingReads(stmt, options.AnsiQuotes)
+	}
+	query = sql.RemoveSpaceAndDelimiter(query, delimiter)
+	stripped, start, n, ok := stripLockingClause(query)
+	if !ok {
+		return stmt, parsed, remainder, err
+	}
+	strippedStmt, _, strippedRemainder, strippedErr := parseWithMode(p.Parser, stripped, delimiter, multi, options, mode)
+	// the clause must have been removed from the statement which was parsed, rather than from the remainder
+	parsedEnd := len(stripped) - len(strippedRemainder)
+	if strippedErr != nil || start > parsedEnd {
+		return stmt, parsed, remainder, err
+	}
+	setLockingClause(strippedStmt, query[start:start+n])
+	// give the original text of the statement, so that the clause is in the query of the context
+	return strippedStmt, sql.RemoveSpaceAndDelimiter(query[:parsedEnd+n], delimiter), strippedRemainder, markLockingReads(strippedStmt, options.AnsiQuotes)
+}
+
+// ParseOneWithOptions implements sql.Parser.
+func (p lockingReadParser) ParseOneWithOptions(query string, options sqlparser.ParserOptions) (sqlparser.Statement, int, error) {
+	stmt, ri, err := p.Parser.ParseOneWithOptions(query, options)
+	if err == nil {
+		return stmt, ri, markLockingReads(stmt, options.AnsiQuotes)
+	}
+	stripped, start, n, ok := stripLockingClause(query)
+	if !ok {
+		return stmt, ri, err
+	}
+	strippedStmt, strippedRi, strippedErr := p.Parser.ParseOneWithOptions(stripped, options)
+	if strippedErr != nil {
+		return stmt, ri, err
+	}
+	if strippedRi != 0 && start <= strippedRi {
+		strippedRi += n
+	}
+	setLockingClause(strippedStmt, query[start:start+n])
+	return strippedStmt, strippedRi, markLockingReads(strippedStmt, options.AnsiQuotes)
+}
+
+// setLockingClause records the locking clause |clause| as the Lock of |stmt|, in the form the grammar uses.
+func setLockingClause(stmt sqlparser.Statement, clause string) {
+	if sel, ok := stmt.(sqlparser.SelectStatement); ok {
+		sel.SetLock(" " + strings.TrimSpace(clause))
+	}
+}
+
+// newRowLocker returns the locker for the rows this table reads, or nil if no locking clause locks the rows of this
+// table.
+func (t *DoltTable) newRowLocker(ctx *sql.Context) (*dsess.RowLocker, error) {
+	if t.lockedToRoot != nil || schema.IsKeyless(t.sch) {
+		// reads of a fixed root can't conflict with writes, and keyless rows have no key to lock
+		return nil, nil
+	}
+	if t.lockingRead == nil {
+		return nil, nil
+	}
+	return dsess.NewRowLocker(ctx, t.db.Name(), t.tableName, *t.lockingRead)
+}
+
+// withLockingRead implements lockingReadTable.
+func (t *DoltTable) withLockingRead(read dsess.LockingRead) sql.Table {
+	nt := *t
+	if t.lockingRead == nil || read.Exclusive && !t.lockingRead.Exclusive {
+		nt.lockingRead = &read
+	}
+	return &nt
+}
+
+// withLockingRead implements lockingReadTable.
+func (t *WritableDoltTable) withLockingRead(read dsess.LockingRead) sql.Table {
+	nt := *t
+	nt.DoltTable = t.DoltTable.withLockingRead(read).(*DoltTable)
+	return &nt
+}
+
+// withLockingRead implements lockingReadTable.
+func (t *AlterableDoltTable) withLockingRead(read dsess.LockingRead) sql.Table {
+	return &AlterableDoltTable{WritableDoltTable: *t.WritableDoltTable.withLockingRead(read).(*WritableDoltTable)}
+}
+
+// withLockingRead implements lockingReadTable.
+func (idt *IndexedDoltTable) withLockingRead(read dsess.LockingRead) sql.Table {
+	return &IndexedDoltTable{
+		DoltTable:    idt.DoltTable.withLockingRead(read).(*DoltTable),
+		idx:          idt.idx,
+		isDoltFormat: idt.isDoltFormat,
+		mu:           &sync.Mutex{},
+	}
+}
+
+// withLockingRead implements lockingReadTable.
+func (t *WritableIndexedDoltTable) withLockingRead(read dsess.LockingRead) sql.Table {
+	return &WritableIndexedDoltTable{
+		WritableDoltTable: t.WritableDoltTable.withLockingRead(read).(*WritableDoltTable),
+		idx:               t.idx,
+		isDoltFormat:      t.isDoltFormat,
+		mu:                &sync.Mutex{},
+	}
+}
+
+// allColumnTags returns the tags of every column of the table's data, in schema order.
+func (t *DoltTable) allColumnTags() []uint64 {
+	return t.sch.GetAllCols().Tags
+}
+
+// primaryKeyIndexes returns the indexes of the primary key columns in the rows of the table's data.
+func (t *DoltTable) primaryKeyIndexes() []int {
+	cols := t.sch.GetAllCols()
+	pkIdxs := make([]int, 0, t.sch.GetPKCols().Size())
+	for _, tag := range t.sch.GetPKCols().Tags {
+		pkIdxs = append(pkIdxs, cols.TagToIdx[tag])
+	}
+	return pkIdxs
+}
+
+// newLockingRowIter returns an iterator which locks each row of |iter|, which returns every column of the table's
+// data. If |project| is true, the rows returned are projected down to the table's projected columns.
+func (t *DoltTable) newLockingRowIter(iter sql.RowIter, locker *dsess.RowLocker, project bool) sql.RowIter {
+	cols := t.sch.GetAllCols()
+	var projection []int
+	if project && t.projectedCols != nil {
+		projection = make([]int, len(t.projectedCols))
+		for i, tag := range t.projectedCols {
+			projection[i] = cols.TagToIdx[tag]
+		}
+	}
+	return &lockingRowIter{child: iter, locker: locker, pkIdxs: t.primaryKeyIndexes(), projection: projection}
+}
+
+// lockingIndexRows returns the rows of the lookup partition |part| of |idx|, locking each one.
+func (t *DoltTable) lockingIndexRows(ctx *sql.Context, key doltdb.DataCacheKey, idx index.DoltIndex, part sql.Partition, locker *dsess.RowLocker) (sql.RowIter, error) {
+	lb, err := index.NewLookupBuilder(ctx, t, idx, key, t.allColumnTags(), t.sqlSch, types.IsFormat_DOLT(t.Format()))
+	if err != nil {
+		return nil, err
+	}
+	iter, err := lb.NewRowIter(ctx, part)
+	if err != nil {
+		return nil, err
+	}
+	return t.newLockingRowIter(iter, locker, true), nil
+}
+
+// lockingRowIter locks the rows returned by its child, skipping those which are locked by another transaction when
+// the read is SKIP LOCKED.
+type lockingRowIter struct {
+	child      sql.RowIter
+	locker     *dsess.RowLocker
+	pkIdxs     []int
+	projection []int
+	pk         sql.Row
+}
+
+var _ sql.RowIter = (*lockingRowIter)(nil)
+
+func (itr *lockingRowIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if itr.pk == nil {
+		itr.pk = make(sql.Row, len(itr.pkIdxs))
+	}
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i, idx := range itr.pkIdxs {
+			itr.pk[i] = row[idx]
+		}
+		locked, err := itr.locker.Lock(ctx, itr.pk)
+		if err != nil {
+			return nil, err
+		} else if !locked {
+			continue
+		}
+		if itr.projection == nil {
+			return row, nil
+		}
+		projected := make(sql.Row, len(itr.projection))
+		for i, idx := range itr.projection {
+			projected[i] = row[idx]
+		}
+		return projected, nil
+	}
+}
+
+func (itr *lockingRowIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
+
+// newRowLockingWriter returns |te|, wrapped to take the exclusive lock of each row it updates or deletes, so that
+// writes wait for the locks of locking reads, and locking reads wait for writes.
+func (t *WritableDoltTable) newRowLockingWriter(ctx *sql.Context, te dsess.TableWriter) (dsess.TableWriter, error) {
+	if schema.IsKeyless(t.sch) {
+		return te, nil
+	}
+	locker, err := dsess.NewRowWriteLocker(ctx, t.db.Name(), t.tableName)
+	if err != nil || locker == nil {
+		return te, err
+	}
+	return &rowLockingWriter{TableWriter: te, locker: locker, pkIdxs: t.primaryKeyIndexes()}, nil
+}
+
+// rowLockingWriter locks each row before updating or deleting it. Rows which are inserted are new to the table, so
+// there's no lock to take for them.
+type rowLockingWriter struct {
+	dsess.TableWriter
+	locker *dsess.RowLocker
+	pkIdxs []int
+}
+
+var _ dsess.TableWriter = (*rowLockingWriter)(nil)
+
+// Update implements sql.RowUpdater.
+func (w *rowLockingWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.lock(ctx, old); err != nil {
+		return err
+	}
+	if err := w.lock(ctx, new); err != nil {
+		return err
+	}
+	return w.TableWriter.Update(ctx, old, new)
+}
+
+// Delete implements sql.RowDeleter.
+func (w *rowLockingWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.lock(ctx, row); err != nil {
+		return err
+	}
+	return w.TableWriter.Delete(ctx, row)
+}
+
+func (w *rowLockingWriter) lock(ctx *sql.Context, row sql.Row) error {
+	pk := make(sql.Row, len(w.pkIdxs))
+	for i, idx := range w.pkIdxs {
+		if row[idx] == nil {
+			// not a row of the table, such as the missing row which an update of a conflicts table inserts
+			return nil
+		}
+		pk[i] = row[idx]
+	}
+	_, err := w.locker.Lock(ctx, pk)
+	return err
+}
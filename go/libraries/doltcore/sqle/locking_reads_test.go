@@ -0,0 +1,129 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+func TestLockingClausesForStatement(t *testing.T) {
+	exclusive := dsess.LockingRead{Exclusive: true}
+	tbl := func(name string) lockedTable {
+		return lockedTable{Name: name}
+	}
+	tests := []struct {
+		query   string
+		clauses []lockingClause
+	}{
+		{query: "select * from t"},
+		{query: "select * from t where v = 'for update'"},
+		{query: "update t set v = 1"},
+		{query: "select * from t for update", clauses: []lockingClause{{Read: exclusive, Tables: []lockedTable{tbl("t")}}}},
+		{query: "SELECT * FROM db.t FOR UPDATE;", clauses: []lockingClause{{Read: exclusive, Tables: []lockedTable{{DB: "db", Name: "t"}}}}},
+		{query: "select * from t for share", clauses: []lockingClause{{Tables: []lockedTable{tbl("t")}}}},
+		{query: "select * from t lock in share mode", clauses: []lockingClause{{Tables: []lockedTable{tbl("t")}}}},
+		{query: "select * from t for update nowait", clauses: []lockingClause{{Read: dsess.LockingRead{Exclusive: true, Wait: dsess.RowLockNoWait}, Tables: []lockedTable{tbl("t")}}}},
+		{query: "select * from t for share\n  skip   locked", clauses: []lockingClause{{Read: dsess.LockingRead{Wait: dsess.RowLockSkipLocked}, Tables: []lockedTable{tbl("t")}}}},
+		{
+			query:   "select * from t, u join v on u.a = v.a for update of t, v nowait",
+			clauses: []lockingClause{{Read: dsess.LockingRead{Exclusive: true, Wait: dsess.RowLockNoWait}, Tables: []lockedTable{tbl("t"), tbl("v")}}},
+		},
+		{
+			query:   "select * from t as x, `u` for share of X",
+			clauses: []lockingClause{{Tables: []lockedTable{tbl("x")}}},
+		},
+		{
+			query:   "select * from t where a in (select a from u) for update",
+			clauses: []lockingClause{{Read: exclusive, Tables: []lockedTable{tbl("t")}}},
+		},
+		{
+			query: "select * from t where a in (select a from u lock in share mode) for update of t",
+			clauses: []lockingClause{
+				{Read: exclusive, Tables: []lockedTable{tbl("t")}},
+				{Tables: []lockedTable{tbl("u")}},
+			},
+		},
+		{
+			query: "select a from t union select a from u for update",
+			clauses: []lockingClause{
+				{Read: exclusive, Tables: []lockedTable{tbl("t")}},
+				{Read: exclusive, Tables: []lockedTable{tbl("u")}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, _, err := NewLockingReadParser(sql.NewMysqlParser()).ParseOneWithOptions(test.query, sqlparser.ParserOptions{})
+			require.NoError(t, err)
+			_, clauses, err := lockingClausesForStatement(stmt, false)
+			require.NoError(t, err)
+			assert.Equal(t, test.clauses, clauses)
+		})
+	}
+}
+
+func TestLockingReadParser(t *testing.T) {
+	p := NewLockingReadParser(sql.NewMysqlParser())
+	opts := sqlparser.ParserOptions{}
+
+	for _, query := range []string{
+		"select * from t for share",
+		"select * from t for update nowait",
+		"select * from t for share of t skip locked",
+	} {
+		t.Run(query, func(t *testing.T) {
+			stmt, err := p.ParseSimple(query)
+			require.NoError(t, err)
+			assert.IsType(t, &sqlparser.Select{}, stmt)
+
+			stmt, parsed, remainder, err := p.ParseWithOptions(query+";  ", ';', false, opts)
+			require.NoError(t, err)
+			assert.IsType(t, &sqlparser.Select{}, stmt)
+			assert.Equal(t, query, parsed)
+			assert.Empty(t, remainder)
+
+			stmt, parsed, remainder, err = p.ParseWithOptions(query+"; select 2", ';', true, opts)
+			require.NoError(t, err)
+			assert.IsType(t, &sqlparser.Select{}, stmt)
+			assert.Equal(t, query, parsed)
+			assert.Equal(t, "select 2", remainder)
+
+			stmt, ri, err := p.ParseOneWithOptions(query+"; select 2", opts)
+			require.NoError(t, err)
+			assert.IsType(t, &sqlparser.Select{}, stmt)
+			assert.Equal(t, len(query)+2, ri)
+		})
+	}
+
+	// the clause is recorded on the statement
+	stmt, _, _, err := p.ParseWithOptions("select * from t  FOR SHARE OF t\nSKIP LOCKED ;", ';', false, opts)
+	require.NoError(t, err)
+	assert.Equal(t, " FOR SHARE OF t\nSKIP LOCKED", stmt.(*sqlparser.Select).Lock)
+	// and marked for the plan
+	assert.Contains(t, sqlparser.String(stmt.(*sqlparser.Select).Where), lockingReadMarkerPrefix)
+
+	// statements which fail to parse for other reasons still fail
+	_, err = p.ParseSimple("select * form t for share")
+	assert.Error(t, err)
+	_, _, _, err = p.ParseWithOptions("select 1; selec * from t for share", ';', true, opts)
+	assert.NoError(t, err)
+}
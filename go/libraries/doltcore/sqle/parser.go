@@ -0,0 +1,68 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// NewDoltParser returns the parser of Dolt's SQL, which parses statements with |inner| and also accepts the sequence
+// statements, FOR SYSTEM_TIME clauses and locking clauses that |inner| doesn't.
+func NewDoltParser(inner sql.Parser) sql.Parser {
+	return NewSequenceParser(NewSystemTimeParser(NewLockingReadParser(inner)))
+}
+
+// lexMode is how the sql_mode changes the way the parsers of this package tokenize statements.
+// sqlparser.ParserOptions only carries ANSI_QUOTES, so the rest of the mode is passed along separately when a
+// statement is parsed with a *sql.Context. Statements parsed without one are tokenized the way the grammar does, with
+// backslash escapes.
+type lexMode struct {
+	ansiQuotes bool
+	// noBackslashEscapes is set by NO_BACKSLASH_ESCAPES, under which a backslash in a string is an ordinary character.
+	noBackslashEscapes bool
+}
+
+// lexModeOf returns the lexMode of |sqlMode|.
+func lexModeOf(sqlMode *sql.SqlMode) lexMode {
+	return lexMode{
+		ansiQuotes:         sqlMode.AnsiQuotes(),
+		noBackslashEscapes: sqlMode.ModeEnabled("NO_BACKSLASH_ESCAPES"),
+	}
+}
+
+// lexModeOfOptions returns the lexMode of statements parsed with |options| alone.
+func lexModeOfOptions(options sqlparser.ParserOptions) lexMode {
+	return lexMode{ansiQuotes: options.AnsiQuotes}
+}
+
+// modeParser is a parser which tokenizes statements according to a lexMode.
+type modeParser interface {
+	parseWithMode(query string, delimiter rune, multi bool, options sqlparser.ParserOptions, mode lexMode) (sqlparser.Statement, string, string, error)
+}
+
+// parseCtx parses |query| with |p| in the sql_mode of |ctx|.
+func parseCtx(ctx *sql.Context, p modeParser, query string, multi bool) (sqlparser.Statement, string, string, error) {
+	sqlMode := sql.LoadSqlMode(ctx)
+	return p.parseWithMode(query, ';', multi, sqlMode.ParserOptions(), lexModeOf(sqlMode))
+}
+
+// parseWithMode parses |query| with |p|, passing |mode| along if |p| is a modeParser.
+func parseWithMode(p sql.Parser, query string, delimiter rune, multi bool, options sqlparser.ParserOptions, mode lexMode) (sqlparser.Statement, string, string, error) {
+	if mp, ok := p.(modeParser); ok {
+		return mp.parseWithMode(query, delimiter, multi, options, mode)
+	}
+	return p.ParseWithOptions(query, delimiter, multi, options)
+}
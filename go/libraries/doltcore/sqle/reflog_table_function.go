@@ -98,6 +98,8 @@ func (rltf *ReflogTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.Row
 	}
 
 	previousCommitsByRef := make(map[string]string)
+	// branches the current user may not read are left out, by ref
+	readableBranches := make(map[string]bool)
 	rows := make([]sql.Row, 0)
 	err := journal.IterateRoots(func(root string, timestamp *time.Time) error {
 		hashof := hash.Parse(root)
@@ -122,6 +124,19 @@ func (rltf *ReflogTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.Row
 			if doltRef.GetType() == ref.InternalRefType {
 				return nil
 			}
+			if doltRef.GetType() == ref.BranchRefType {
+				readable, ok := readableBranches[id]
+				if !ok {
+					readable, err = dsess.CanReadBranch(ctx, sqlDb.Name(), doltRef.GetPath())
+					if err != nil {
+						return err
+					}
+					readableBranches[id] = readable
+				}
+				if !readable {
+					return nil
+				}
+			}
 			// skip workspace refs by default
 			if doltRef.GetType() == ref.WorkspaceRefType {
 				if !showAll {
@@ -0,0 +1,238 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+)
+
+// sequenceParser is a sql.Parser which accepts the CREATE SEQUENCE and DROP SEQUENCE statements the grammar doesn't
+// support, by rewriting them to calls of the dolt_create_sequence and dolt_drop_sequence procedures. The statements
+// rewritten are
+//
+//	CREATE SEQUENCE [IF NOT EXISTS] name
+//	    [START [WITH | =] n] [INCREMENT [BY | =] n]
+//	    [MINVALUE n | NO MINVALUE] [MAXVALUE n | NO MAXVALUE]
+//	    [NO CYCLE | NOCYCLE] [BRANCH RANGE [=] n]
+//	DROP SEQUENCE [IF EXISTS] name
+type sequenceParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = sequenceParser{}
+var _ modeParser = sequenceParser{}
+
+// NewSequenceParser returns a parser which parses statements with |inner|, rewriting sequence statements.
+func NewSequenceParser(inner sql.Parser) sql.Parser {
+	return sequenceParser{Parser: inner}
+}
+
+// ParseSimple implements sql.Parser.
+func (p sequenceParser) ParseSimple(query string) (sqlparser.Statement, error) {
+	stmt, err := p.Parser.ParseSimple(query)
+	if err != nil {
+		if rewritten, _, ok := rewriteSequenceStatement(query, ';', lexMode{}); ok {
+			return p.Parser.ParseSimple(rewritten)
+		}
+	}
+	return stmt, err
+}
+
+// Parse implements sql.Parser.
+func (p sequenceParser) Parse(ctx *sql.Context, query string, multi bool) (sqlparser.Statement, string, string, error) {
+	return parseCtx(ctx, p, query, multi)
+}
+
+// ParseWithOptions implements sql.Parser. The text of the statement returned is the original sequence statement, not
+// its rewrite, since it's what the process list and the query log show.
+func (p sequenceParser) ParseWithOptions(query string, delimiter rune, multi bool, options sqlparser.ParserOptions) (sqlparser.Statement, string, string, error) {
+	return p.parseWithMode(query, delimiter, multi, options, lexModeOfOptions(options))
+}
+
+// parseWithMode implements modeParser.
+func (p sequenceParser) parseWithMode(query string, delimiter rune, multi bool, options sqlparser.ParserOptions, mode lexMode) (sqlparser.Statement, string, string, error) {
+	stmt, parsed, remainder, err := parseWithMode(p.Parser, query, delimiter, multi, options, mode)
+	if err == nil {
+		return stmt, parsed, remainder, nil
+	}
+	rewritten, _, ok := rewriteSequenceStatement(query, delimiter, mode)
+	if !ok {
+		return stmt, parsed, remainder, err
+	}
+	stmt, _, remainder, err = parseWithMode(p.Parser, rewritten, delimiter, multi, options, mode)
+	if err != nil {
+		return nil, "", "", err
+	}
+	// only the first statement is rewritten, so the remainder is the same in |query|
+	parsed = sql.RemoveSpaceAndDelimiter(query, delimiter)
+	parsed = sql.RemoveSpaceAndDelimiter(parsed[:len(parsed)-len(remainder)], delimiter)
+	return stmt, parsed, remainder, nil
+}
+
+// ParseOneWithOptions implements sql.Parser.
+func (p sequenceParser) ParseOneWithOptions(query string, options sqlparser.ParserOptions) (sqlparser.Statement, int, error) {
+	stmt, ri, err := p.Parser.ParseOneWithOptions(query, options)
+	if err == nil {
+		return stmt, ri, nil
+	}
+	rewritten, delta, ok := rewriteSequenceStatement(query, ';', lexModeOfOptions(options))
+	if !ok {
+		return stmt, ri, err
+	}
+	stmt, ri, err = p.Parser.ParseOneWithOptions(rewritten, options)
+	// the index of the next statement is an index into |query|
+	return stmt, ri - delta, err
+}
+
+// rewriteSequenceStatement returns |query| with its first statement rewritten to a procedure call if it is a
+// sequence statement, and how many bytes longer the rewrite made it.
+func rewriteSequenceStatement(query string, delimiter rune, mode lexMode) (string, int, bool) {
+	toks := tokenizeSql(query, delimiter, mode)
+	end := 0
+	for end < len(toks) && toks[end].kind != sqlTokenEnd {
+		end++
+	}
+	toks = toks[:end]
+	if len(toks) < 3 || toks[1].word != "sequence" {
+		return query, 0, false
+	}
+
+	var call string
+	var ok bool
+	switch toks[0].word {
+	case "create":
+		call, ok = createSequenceCall(query, toks[2:])
+	case "drop":
+		call, ok = dropSequenceCall(query, toks[2:])
+	}
+	if !ok {
+		return query, 0, false
+	}
+
+	start, stop := toks[0].start, toks[len(toks)-1].end
+	return query[:start] + call + query[stop:], len(call) - (stop - start), true
+}
+
+// createSequenceCall returns the call of dolt_create_sequence for the CREATE SEQUENCE statement which continues with
+// |toks|.
+func createSequenceCall(query string, toks []sqlToken) (string, bool) {
+	var args []string
+	i := 0
+	if len(toks) > 3 && toks[0].word == "if" && toks[1].word == "not" && toks[2].word == "exists" {
+		args = append(args, "--"+cli.IfNotExistsFlag)
+		i = 3
+	}
+	if i == len(toks) || !toks[i].isIdent() {
+		return "", false
+	}
+	name := toks[i].ident(query)
+	i++
+
+	// option adds the value of the option at token |i| to the arguments as |param|. The value may be preceded by
+	// = or by |words|.
+	option := func(param string, words ...string) bool {
+		i++
+		if i < len(toks) && (toks[i].is('=') || slices.Contains(words, toks[i].word)) {
+			i++
+		}
+		n, end, ok := sequenceNumber(query, toks, i)
+		if !ok {
+			return false
+		}
+		args = append(args, "--"+param, n)
+		i = end
+		return true
+	}
+
+	for i < len(toks) {
+		var ok bool
+		switch {
+		case toks[i].word == "start":
+			ok = option(cli.StartParam, "with")
+		case toks[i].word == "increment":
+			ok = option(cli.IncrementParam, "by")
+		case toks[i].word == "minvalue":
+			ok = option(cli.MinValueParam)
+		case toks[i].word == "maxvalue":
+			ok = option(cli.MaxValueParam)
+		case toks[i].word == "branch" && i+1 < len(toks) && toks[i+1].word == "range":
+			i++
+			ok = option(cli.BranchRangeParam)
+		case toks[i].word == "no" && i+1 < len(toks):
+			// the defaults, which need no options
+			switch toks[i+1].word {
+			case "minvalue", "maxvalue", "cycle":
+				i, ok = i+2, true
+			}
+		case toks[i].word == "nocycle":
+			i, ok = i+1, true
+		}
+		if !ok {
+			return "", false
+		}
+	}
+
+	args = append(args, name)
+	return procedureCall("dolt_create_sequence", args), true
+}
+
+// dropSequenceCall returns the call of dolt_drop_sequence for the DROP SEQUENCE statement which continues with |toks|.
+func dropSequenceCall(query string, toks []sqlToken) (string, bool) {
+	var args []string
+	if len(toks) > 2 && toks[0].word == "if" && toks[1].word == "exists" {
+		args = append(args, "--"+cli.IfExistsFlag)
+		toks = toks[2:]
+	}
+	if len(toks) != 1 || !toks[0].isIdent() {
+		return "", false
+	}
+	args = append(args, toks[0].ident(query))
+	return procedureCall("dolt_drop_sequence", args), true
+}
+
+// sequenceNumber returns the text of the signed integer which starts at token |i|, and the index of the token after it.
+func sequenceNumber(query string, toks []sqlToken, i int) (string, int, bool) {
+	sign := ""
+	if i < len(toks) && (toks[i].is('-') || toks[i].is('+')) {
+		sign = string(toks[i].char)
+		i++
+	}
+	if i == len(toks) || toks[i].kind != sqlTokenValue {
+		return "", 0, false
+	}
+	n := query[toks[i].start:toks[i].end]
+	for j := 0; j < len(n); j++ {
+		if n[j] < '0' || n[j] > '9' {
+			return "", 0, false
+		}
+	}
+	return sign + n, i + 1, true
+}
+
+// procedureCall returns a CALL statement of the procedure |name| with the string arguments |args|.
+func procedureCall(name string, args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteSqlString(arg)
+	}
+	return fmt.Sprintf("CALL %s(%s)", name, strings.Join(quoted, ", "))
+}
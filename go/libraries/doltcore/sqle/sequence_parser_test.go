@@ -0,0 +1,89 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteSequenceStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "create sequence s",
+			expected: "CALL dolt_create_sequence('s')",
+		},
+		{
+			query:    "CREATE SEQUENCE IF NOT EXISTS `my seq` START WITH 10 INCREMENT BY -2 MINVALUE -100 MAXVALUE 10",
+			expected: "CALL dolt_create_sequence('--if-not-exists', '--start', '10', '--increment', '-2', '--min-value', '-100', '--max-value', '10', 'my seq')",
+		},
+		{
+			query:    "create sequence s start = 5 increment 3 no minvalue no maxvalue nocycle branch range 1000;",
+			expected: "CALL dolt_create_sequence('--start', '5', '--increment', '3', '--branch-range', '1000', 's');",
+		},
+		{
+			query:    "drop sequence s",
+			expected: "CALL dolt_drop_sequence('s')",
+		},
+		{
+			query:    "DROP SEQUENCE IF EXISTS s; select 1",
+			expected: "CALL dolt_drop_sequence('--if-exists', 's'); select 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			rewritten, _, ok := rewriteSequenceStatement(test.query, ';', lexMode{})
+			require.True(t, ok)
+			assert.Equal(t, test.expected, rewritten)
+		})
+	}
+
+	for _, query := range []string{
+		"create table sequence (pk int)",
+		"create sequence",
+		"create sequence s start with 'a'",
+		"create sequence s cycle",
+		"create sequence s branch 10",
+		"drop sequence a, b",
+	} {
+		_, _, ok := rewriteSequenceStatement(query, ';', lexMode{})
+		assert.False(t, ok, query)
+	}
+}
+
+func TestSequenceParser(t *testing.T) {
+	p := NewSequenceParser(sql.NewMysqlParser())
+
+	_, err := p.ParseSimple("create sequence s start with 10")
+	require.NoError(t, err)
+
+	query := "create sequence s branch range 10; select 2"
+	_, ri, err := p.ParseOneWithOptions(query, sql.LoadSqlMode(sql.NewEmptyContext()).ParserOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "select 2", query[ri:])
+
+	stmt, parsed, remainder, err := p.ParseWithOptions("  drop sequence if exists s ; select 2", ';', true, sql.LoadSqlMode(sql.NewEmptyContext()).ParserOptions())
+	require.NoError(t, err)
+	assert.IsType(t, &sqlparser.Call{}, stmt)
+	assert.Equal(t, "drop sequence if exists s", parsed)
+	assert.Equal(t, "select 2", remainder)
+}
@@ -0,0 +1,415 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// systemTimeParser is a sql.Parser which gives the SQL:2011 FOR SYSTEM_TIME clause its standard meaning. The grammar
+// parses FOR SYSTEM_TIME AS OF as the AS OF clause, which reads a table as of a single commit, and the engine doesn't
+// support the other periods, so a statement which |inner| parses with a FOR SYSTEM_TIME clause, or fails to parse,
+// has each clause rewritten to a call of the dolt_system_time table function and is parsed again; see
+// SystemTimeTableFunction. Other statements are parsed by |inner| alone. The clauses rewritten are
+//
+//	t FOR SYSTEM_TIME AS OF <time>
+//	t FOR SYSTEM_TIME BETWEEN <time> AND <time>
+//	t FOR SYSTEM_TIME FROM <time> TO <time>
+//	t FOR SYSTEM_TIME ALL
+type systemTimeParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = systemTimeParser{}
+var _ modeParser = systemTimeParser{}
+
+// NewSystemTimeParser returns a parser which parses statements with |inner|, rewriting FOR SYSTEM_TIME clauses.
+func NewSystemTimeParser(inner sql.Parser) sql.Parser {
+	return systemTimeParser{Parser: inner}
+}
+
+// ParseSimple implements sql.Parser.
+func (p systemTimeParser) ParseSimple(query string) (sqlparser.Statement, error) {
+	stmt, err := p.Parser.ParseSimple(query)
+	if needsSystemTimeRewrite(query, stmt, err) {
+		if rewritten, _, ok := rewriteSystemTime(query, ';', lexMode{}); ok {
+			if rewrittenStmt, rewrittenErr := p.Parser.ParseSimple(rewritten); rewrittenErr == nil {
+				return rewrittenStmt, nil
+			}
+		}
+	}
+	return stmt, err
+}
+
+// Parse implements sql.Parser.
+func (p systemTimeParser) Parse(ctx *sql.Context, query string, multi bool) (sqlparser.Statement, string, string, error) {
+	return parseCtx(ctx, p, query, multi)
+}
+
+// ParseWithOptions implements sql.Parser. The text of a rewritten statement is returned rewritten, so that the
+// definitions of views, triggers and procedures which are parsed again by the engine keep the meaning of the clause.
+func (p systemTimeParser) ParseWithOptions(query string, delimiter rune, multi bool, options sqlparser.ParserOptions) (sqlparser.Statement, string, string, error) {
+	return p.parseWithMode(query, delimiter, multi, options, lexModeOfOptions(options))
+}
+
+// parseWithMode implements modeParser.
+func (p systemTimeParser) parseWithMode(query string, delimiter rune, multi bool, options sqlparser.ParserOptions, mode lexMode) (sqlparser.Statement, string, string, error) {
+	stmt, parsed, remainder, err := parseWithMode(p.Parser, query, delimiter, multi, options, mode)
+	if needsSystemTimeRewrite(query, stmt, err) {
+		if rewritten, _, ok := rewriteSystemTime(query, delimiter, mode); ok {
+			if rewrittenStmt, rewrittenParsed, rewrittenRemainder, rewrittenErr := parseWithMode(p.Parser, rewritten, delimiter, multi, options, mode); rewrittenErr == nil {
+				return rewrittenStmt, rewrittenParsed, rewrittenRemainder, nil
+			}
+		}
+	}
+	return stmt, parsed, remainder, err
+}
+
+// ParseOneWithOptions implements sql.Parser.
+func (p systemTimeParser) ParseOneWithOptions(query string, options sqlparser.ParserOptions) (sqlparser.Statement, int, error) {
+	stmt, ri, err := p.Parser.ParseOneWithOptions(query, options)
+	if !needsSystemTimeRewrite(query, stmt, err) {
+		return stmt, ri, err
+	}
+	rewritten, edits, ok := rewriteSystemTime(query, ';', lexModeOfOptions(options))
+	if !ok {
+		return stmt, ri, err
+	}
+	rewrittenStmt, rewrittenRi, rewrittenErr := p.Parser.ParseOneWithOptions(rewritten, options)
+	if rewrittenErr != nil {
+		return stmt, ri, err
+	}
+	// the index of the next statement is an index into |query|
+	for _, e := range edits {
+		if rewrittenRi <= e.start {
+			break
+		}
+		rewrittenRi -= e.delta
+	}
+	return rewrittenStmt, rewrittenRi, nil
+}
+
+// needsSystemTimeRewrite returns whether |query|, which the inner parser parsed as |stmt| or failed to parse with
+// |err|, may have FOR SYSTEM_TIME clauses to rewrite. The grammar parses the clauses as the AS OF clause of a table.
+func needsSystemTimeRewrite(query string, stmt sqlparser.Statement, err error) bool {
+	if !systemTimeKeyword.MatchString(query) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	var asOf bool
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if tbl, ok := node.(*sqlparser.AliasedTableExpr); ok && tbl.AsOf != nil {
+			asOf = true
+		}
+		return !asOf, nil
+	}, stmt)
+	return asOf
+}
+
+// systemTimeEdit is the rewrite of a FOR SYSTEM_TIME clause, which starts at |start| in the rewritten query and made
+// it |delta| bytes longer.
+type systemTimeEdit struct {
+	start, delta int
+}
+
+// systemTimeKeyword matches the keyword of FOR SYSTEM_TIME clauses, to quickly rule out statements without them.
+var systemTimeKeyword = regexp.MustCompile(`(?i)system_time`)
+
+// rewriteSystemTime returns |query| with each table followed by a FOR SYSTEM_TIME clause rewritten to a call of the
+// dolt_system_time table function, aliased to the table's name unless it already has an alias, and the edits made.
+func rewriteSystemTime(query string, delimiter rune, mode lexMode) (string, []systemTimeEdit, bool) {
+	if !systemTimeKeyword.MatchString(query) {
+		return query, nil, false
+	}
+	toks := tokenizeSql(query, delimiter, mode)
+
+	var sb strings.Builder
+	var edits []systemTimeEdit
+	copied := 0
+	for i := 1; i+2 < len(toks); i++ {
+		if toks[i].word != "for" || toks[i+1].word != "system_time" {
+			continue
+		}
+		tableStart := i - 1
+		if !toks[tableStart].isIdent() {
+			continue
+		}
+		tableName := toks[tableStart].ident(query)
+		if tableStart >= 2 && toks[tableStart-1].is('.') && toks[tableStart-2].isIdent() {
+			tableStart -= 2
+			tableName = toks[tableStart].ident(query) + "." + tableName
+		}
+		if toks[tableStart].start < copied || tableStart == 0 || !toks[tableStart-1].beginsTable() {
+			continue
+		}
+
+		period, bounds, end, ok := parseSystemTimePeriod(query, toks, i+2)
+		if !ok {
+			continue
+		}
+		args := []string{quoteSqlString(tableName), quoteSqlString(period)}
+		args = append(args, bounds...)
+		call := fmt.Sprintf("dolt_system_time(%s)", strings.Join(args, ", "))
+		if end == len(toks) || !toks[end].isAlias() {
+			call += " AS " + sqlparser.String(sqlparser.NewTableIdent(toks[i-1].ident(query)))
+		}
+
+		start, stop := toks[tableStart].start, toks[end-1].end
+		sb.WriteString(query[copied:start])
+		edits = append(edits, systemTimeEdit{start: sb.Len(), delta: len(call) - (stop - start)})
+		sb.WriteString(call)
+		copied = stop
+		i = end - 1
+	}
+	if len(edits) == 0 {
+		return query, nil, false
+	}
+	sb.WriteString(query[copied:])
+	return sb.String(), edits, true
+}
+
+// parseSystemTimePeriod parses the period of a FOR SYSTEM_TIME clause which starts at token |i|, returning the type
+// of the period, the text of the expressions of its bounds, and the index of the token after the clause.
+func parseSystemTimePeriod(query string, toks []sqlToken, i int) (string, []string, int, bool) {
+	expr := func(start int, stopWord string) (string, int, bool) {
+		end := scanSqlExpression(toks, start, stopWord)
+		if end == start {
+			return "", 0, false
+		}
+		return query[toks[start].start:toks[end-1].end], end, true
+	}
+
+	switch {
+	case toks[i].word == "all":
+		return SystemTimeAll, nil, i + 1, true
+	case toks[i].word == "as" && i+1 < len(toks) && toks[i+1].word == "of":
+		at, end, ok := expr(i+2, "")
+		return SystemTimeAsOf, []string{at}, end, ok
+	case toks[i].word == "between" || toks[i].word == "from":
+		period, sep := SystemTimeBetween, "and"
+		if toks[i].word == "from" {
+			period, sep = SystemTimeFromTo, "to"
+		}
+		from, end, ok := expr(i+1, sep)
+		if !ok || end == len(toks) || toks[end].word != sep {
+			return "", nil, 0, false
+		}
+		to, end, ok := expr(end+1, "")
+		return period, []string{from, to}, end, ok
+	default:
+		return "", nil, 0, false
+	}
+}
+
+// sqlClauseWords are the keywords which end a table expression in a FROM clause.
+var sqlClauseWords = map[string]bool{
+	"as": true, "where": true, "join": true, "inner": true, "left": true, "right": true, "cross": true,
+	"natural": true, "straight_join": true, "full": true, "on": true, "using": true, "group": true, "order": true,
+	"limit": true, "having": true, "window": true, "union": true, "intersect": true, "except": true, "for": true,
+	"lock": true, "into": true, "use": true, "force": true, "ignore": true,
+}
+
+// sqlOperatorWords are the keywords which can follow an operand within an expression, mapped to whether an operand
+// ends with them.
+var sqlOperatorWords = map[string]bool{
+	"and": false, "or": false, "xor": false, "not": false, "is": false, "in": false, "like": false, "div": false,
+	"mod": false, "collate": false, "case": false, "when": false, "then": false, "else": false, "interval": false,
+	"escape": false, "regexp": false, "rlike": false, "end": true, "null": true, "true": true, "false": true,
+	"microsecond": true, "second": true, "minute": true, "hour": true, "day": true, "week": true, "month": true,
+	"quarter": true, "year": true, "second_microsecond": true, "minute_microsecond": true, "minute_second": true,
+	"hour_microsecond": true, "hour_second": true, "hour_minute": true, "day_microsecond": true, "day_second": true,
+	"day_minute": true, "day_hour": true, "year_month": true,
+}
+
+// scanSqlExpression returns the index of the token after the expression which starts at token |i|. The expression
+// ends at the end of the enclosing clause, at a table alias, or at the keyword |stopWord|.
+func scanSqlExpression(toks []sqlToken, i int, stopWord string) int {
+	depth := 0
+	operand := false
+	for ; i < len(toks); i++ {
+		tok := toks[i]
+		switch {
+		case tok.is('('):
+			depth++
+			operand = false
+		case tok.is(')'):
+			if depth == 0 {
+				return i
+			}
+			depth--
+			operand = true
+		case depth > 0:
+		case tok.kind == sqlTokenEnd || tok.is(','):
+			return i
+		case tok.word != "":
+			if tok.word == stopWord || sqlClauseWords[tok.word] {
+				return i
+			}
+			if ends, ok := sqlOperatorWords[tok.word]; ok {
+				operand = ends
+			} else if operand {
+				// an identifier after an operand is an alias
+				return i
+			} else {
+				operand = true
+			}
+		case tok.kind == sqlTokenQuotedIdent:
+			if operand {
+				return i
+			}
+			operand = true
+		case tok.kind == sqlTokenPunct:
+			operand = false
+		default:
+			operand = true
+		}
+	}
+	return i
+}
+
+// quoteSqlString returns |s| as a SQL string literal.
+func quoteSqlString(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", "''") + "'"
+}
+
+type sqlTokenKind int
+
+const (
+	// sqlTokenWord is an unquoted identifier or keyword
+	sqlTokenWord sqlTokenKind = iota
+	sqlTokenQuotedIdent
+	// sqlTokenValue is a string or number literal or a variable
+	sqlTokenValue
+	// sqlTokenPunct is any other character
+	sqlTokenPunct
+	// sqlTokenEnd is the end of a statement
+	sqlTokenEnd
+)
+
+// sqlToken is a token of a SQL statement, as far as rewriting FOR SYSTEM_TIME clauses needs to know.
+type sqlToken struct {
+	kind       sqlTokenKind
+	start, end int
+	// the lower case text of sqlTokenWord tokens
+	word string
+	// the character of sqlTokenPunct tokens
+	char byte
+}
+
+func (t sqlToken) is(c byte) bool {
+	return t.kind == sqlTokenPunct && t.char == c
+}
+
+func (t sqlToken) isIdent() bool {
+	return (t.kind == sqlTokenWord && !sqlClauseWords[t.word]) || t.kind == sqlTokenQuotedIdent
+}
+
+// beginsTable returns whether a table name can follow this token in a table expression.
+func (t sqlToken) beginsTable() bool {
+	switch t.word {
+	case "from", "join", "straight_join", "update", "into", "table":
+		return true
+	}
+	return t.is(',') || t.is('(')
+}
+
+// isAlias returns whether this token begins the alias of a table expression.
+func (t sqlToken) isAlias() bool {
+	return t.word == "as" || t.isIdent()
+}
+
+// ident returns the identifier this token names in |query|.
+func (t sqlToken) ident(query string) string {
+	if t.kind == sqlTokenQuotedIdent {
+		q := query[t.start : t.start+1]
+		return strings.ReplaceAll(query[t.start+1:t.end-1], q+q, q)
+	}
+	return query[t.start:t.end]
+}
+
+// tokenizeSql splits |query| into tokens, skipping whitespace and comments. Statements end at |delimiter|.
+func tokenizeSql(query string, delimiter rune, mode lexMode) []sqlToken {
+	var toks []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case c == '#' || strings.HasPrefix(query[i:], "--") && (i+2 == len(query) || query[i+2] <= ' '):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		case c == '`' || c == '"' && mode.ansiQuotes:
+			end := scanSqlQuoted(query, i, false)
+			toks = append(toks, sqlToken{kind: sqlTokenQuotedIdent, start: i, end: end})
+			i = end
+		case c == '\'' || c == '"':
+			end := scanSqlQuoted(query, i, !mode.noBackslashEscapes)
+			toks = append(toks, sqlToken{kind: sqlTokenValue, start: i, end: end})
+			i = end
+		case rune(c) == delimiter:
+			toks = append(toks, sqlToken{kind: sqlTokenEnd, start: i, end: i + 1})
+			i++
+		case isSqlWordChar(c) || c == '@':
+			end := i + 1
+			for end < len(query) && (isSqlWordChar(query[end]) || c == '@' && query[end] == '@') {
+				end++
+			}
+			if c == '@' || c >= '0' && c <= '9' {
+				toks = append(toks, sqlToken{kind: sqlTokenValue, start: i, end: end})
+			} else {
+				toks = append(toks, sqlToken{kind: sqlTokenWord, start: i, end: end, word: strings.ToLower(query[i:end])})
+			}
+			i = end
+		default:
+			toks = append(toks, sqlToken{kind: sqlTokenPunct, start: i, end: i + 1, char: c})
+			i++
+		}
+	}
+	return toks
+}
+
+// scanSqlQuoted returns the index after the quoted string or identifier which starts at |i|.
+func scanSqlQuoted(query string, i int, backslashEscapes bool) int {
+	q := query[i]
+	for j := i + 1; j < len(query); j++ {
+		switch {
+		case backslashEscapes && query[j] == '\\':
+			j++
+		case query[j] == q && j+1 < len(query) && query[j+1] == q:
+			j++
+		case query[j] == q:
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+func isSqlWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
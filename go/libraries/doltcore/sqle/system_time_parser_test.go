@@ -0,0 +1,122 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteSystemTime(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "select * from t for system_time all",
+			expected: "select * from dolt_system_time('t', 'ALL') AS t",
+		},
+		{
+			query:    "SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01' WHERE pk = 1",
+			expected: "SELECT * FROM dolt_system_time('t', 'AS OF', '2024-01-01') AS t WHERE pk = 1",
+		},
+		{
+			query:    "select * from mydb.t for system_time as of now() - interval 1 day x where x.pk = 1",
+			expected: "select * from dolt_system_time('mydb.t', 'AS OF', now() - interval 1 day) x where x.pk = 1",
+		},
+		{
+			query:    "select * from `my t` for system_time between timestamp '2024-01-01' and @t as a order by 1",
+			expected: "select * from dolt_system_time('my t', 'BETWEEN', timestamp '2024-01-01', @t) as a order by 1",
+		},
+		{
+			query:    "select * from `my t` for system_time all",
+			expected: "select * from dolt_system_time('my t', 'ALL') AS `my t`",
+		},
+		{
+			query:    "select * from t for system_time from '2024-01-01' to '2024-02-01' join u for system_time all on t.pk = u.pk;",
+			expected: "select * from dolt_system_time('t', 'FROM', '2024-01-01', '2024-02-01') AS t join dolt_system_time('u', 'ALL') AS u on t.pk = u.pk;",
+		},
+		{
+			query:    "select (select count(*) from t for system_time all), 'for system_time all' from dual",
+			expected: "select (select count(*) from dolt_system_time('t', 'ALL') AS t), 'for system_time all' from dual",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			rewritten, _, ok := rewriteSystemTime(test.query, ';', lexMode{})
+			require.True(t, ok)
+			assert.Equal(t, test.expected, rewritten)
+		})
+	}
+
+	for _, query := range []string{
+		"select * from t",
+		"select 'for system_time all' from t",
+		"select * from t as x for system_time all",
+		"select * from t for system_time as of",
+		"select * from t for system_time between 1",
+	} {
+		_, _, ok := rewriteSystemTime(query, ';', lexMode{})
+		assert.False(t, ok, query)
+	}
+}
+
+func TestSystemTimeRewriteNoBackslashEscapes(t *testing.T) {
+	// under NO_BACKSLASH_ESCAPES the string ends at the second quote, rather than at the end of the query
+	query := `select 'C:\' from t for system_time all`
+	_, _, ok := rewriteSystemTime(query, ';', lexMode{})
+	assert.False(t, ok)
+	rewritten, _, ok := rewriteSystemTime(query, ';', lexMode{noBackslashEscapes: true})
+	require.True(t, ok)
+	assert.Equal(t, `select 'C:\' from dolt_system_time('t', 'ALL') AS t`, rewritten)
+}
+
+func TestSystemTimeParser(t *testing.T) {
+	p := NewSystemTimeParser(sql.NewMysqlParser())
+
+	_, err := p.ParseSimple("select * from t for system_time all")
+	require.NoError(t, err)
+
+	query := "select * from t for system_time as of '2024-01-01'; select 2"
+	_, ri, err := p.ParseOneWithOptions(query, sql.LoadSqlMode(sql.NewEmptyContext()).ParserOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "select 2", query[ri:])
+}
+
+func TestSystemTimeParserRewritesOnlyForSystemTime(t *testing.T) {
+	p := NewSystemTimeParser(sql.NewMysqlParser())
+	opts := sql.LoadSqlMode(sql.NewEmptyContext()).ParserOptions()
+
+	// statements without a FOR SYSTEM_TIME clause are parsed as they are
+	for _, query := range []string{
+		"select `system_time` from t as of 'main'",
+		"select 'for system_time all' from t",
+		"select * from t as of 'main'",
+	} {
+		stmt, parsed, _, err := p.ParseWithOptions(query, ';', false, opts)
+		require.NoError(t, err)
+		assert.Equal(t, query, parsed)
+		assert.NotContains(t, sqlparser.String(stmt), "dolt_system_time")
+	}
+
+	stmt, parsed, _, err := p.ParseWithOptions("select * from t for system_time as of '2024-01-01'", ';', false, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "select * from dolt_system_time('t', 'AS OF', '2024-01-01') AS t", parsed)
+	assert.Contains(t, sqlparser.String(stmt), "dolt_system_time")
+}
@@ -0,0 +1,323 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/datas"
+)
+
+const (
+	// SystemTimeAsOf selects the versions of rows which were valid at a time
+	SystemTimeAsOf = "AS OF"
+	// SystemTimeBetween selects the versions of rows which were valid at some time between two times, inclusive
+	SystemTimeBetween = "BETWEEN"
+	// SystemTimeFromTo selects the versions of rows which were valid at some time from one time up to another
+	SystemTimeFromTo = "FROM"
+	// SystemTimeAll selects every version of rows
+	SystemTimeAll = "ALL"
+)
+
+var ErrSystemTimeKeylessTable = errors.NewKind("table %s has no primary key, so it has no versions to query with FOR SYSTEM_TIME")
+
+var _ sql.TableFunction = (*SystemTimeTableFunction)(nil)
+var _ sql.ExecSourceRel = (*SystemTimeTableFunction)(nil)
+
+// SystemTimeTableFunction is the dolt_system_time table function, which returns the versions of the rows of a table
+// over the commit history of the current branch, selected by a period of system time as in the SQL:2011 FOR
+// SYSTEM_TIME clause. The clause is rewritten to this function by the parser; see NewSystemTimeParser.
+//
+// The arguments are the name of the table, optionally qualified by a database name, the type of the period, which is
+// one of SystemTimeAsOf, SystemTimeBetween, SystemTimeFromTo or SystemTimeAll, and the times bounding the period. Each
+// version of a row is returned once, with the columns of the table followed by valid_from and valid_to, the period
+// during which the row had that value. A version is selected if its period overlaps the period queried, as in the
+// standard: periods include their start and exclude their end, except for BETWEEN periods, which include their end.
+type SystemTimeTableFunction struct {
+	ctx      *sql.Context
+	database sql.Database
+	exprs    []sql.Expression
+
+	history *HistoryTable
+	period  string
+	sqlSch  sql.Schema
+}
+
+// NewInstance implements sql.TableFunction
+func (stf *SystemTimeTableFunction) NewInstance(ctx *sql.Context, database sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &SystemTimeTableFunction{
+		ctx:      ctx,
+		database: database,
+	}
+
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// Name implements sql.TableFunction
+func (stf *SystemTimeTableFunction) Name() string {
+	return "dolt_system_time"
+}
+
+// String implements the Stringer interface
+func (stf *SystemTimeTableFunction) String() string {
+	args := make([]string, len(stf.exprs))
+	for i, expr := range stf.exprs {
+		args[i] = expr.String()
+	}
+	return fmt.Sprintf("DOLT_SYSTEM_TIME(%s)", strings.Join(args, ", "))
+}
+
+// Database implements the sql.Databaser interface
+func (stf *SystemTimeTableFunction) Database() sql.Database {
+	return stf.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (stf *SystemTimeTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	nstf := *stf
+	nstf.database = database
+	return &nstf, nil
+}
+
+// Expressions implements the sql.Expressioner interface
+func (stf *SystemTimeTableFunction) Expressions() []sql.Expression {
+	return stf.exprs
+}
+
+// WithExpressions implements the sql.Expressioner interface
+func (stf *SystemTimeTableFunction) WithExpressions(expressions ...sql.Expression) (sql.Node, error) {
+	if len(expressions) < 2 || len(expressions) > 4 {
+		return nil, sql.ErrInvalidArgumentNumber.New(stf.Name(), "2 to 4", len(expressions))
+	}
+
+	nstf := *stf
+	nstf.exprs = expressions
+	if !nstf.Resolved() {
+		return &nstf, nil
+	}
+
+	// the table and the type of period determine the schema, so they must be literals
+	var args [2]string
+	for i := range args {
+		if !types.IsText(expressions[i].Type()) {
+			return nil, sql.ErrInvalidArgumentDetails.New(stf.Name(), expressions[i].String())
+		}
+		v, err := expressions[i].Eval(stf.ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, ErrInvalidNonLiteralArgument.New(stf.Name(), expressions[i].String())
+		}
+		args[i] = s
+	}
+	tableName := args[0]
+
+	nstf.period = strings.ToUpper(args[1])
+	numBounds := 0
+	switch nstf.period {
+	case SystemTimeAsOf:
+		numBounds = 1
+	case SystemTimeBetween, SystemTimeFromTo:
+		numBounds = 2
+	case SystemTimeAll:
+	default:
+		return nil, sql.ErrInvalidArgumentDetails.New(stf.Name(), expressions[1].String())
+	}
+	if len(expressions) != 2+numBounds {
+		return nil, sql.ErrInvalidArgumentNumber.New(fmt.Sprintf("%s with a period of %s", stf.Name(), nstf.period), 2+numBounds, len(expressions))
+	}
+
+	history, err := nstf.loadHistoryTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the versions of rows have the columns of the table and their validity
+	cols := history.doltTable.sch.GetAllCols().GetColumnNames()
+	history = history.WithProjections(append(cols, ValidFromCol, ValidToCol)).(*HistoryTable)
+	nstf.history = history
+	nstf.sqlSch = history.Schema().Copy()
+	// columns of table functions have no source table; see DiffTableFunction
+	for _, col := range nstf.sqlSch {
+		col.Source = ""
+	}
+
+	return &nstf, nil
+}
+
+// loadHistoryTable returns the history table of the table named, which may be qualified by the name of its database.
+func (stf *SystemTimeTableFunction) loadHistoryTable(tableName string) (*HistoryTable, error) {
+	db := stf.database
+	if dbName, name, ok := strings.Cut(tableName, "."); ok {
+		var err error
+		db, err = dsess.DSessFromSess(stf.ctx.Session).Provider().Database(stf.ctx, dbName)
+		if err != nil {
+			return nil, err
+		}
+		tableName = name
+	}
+
+	tbl, ok, err := db.GetTableInsensitive(stf.ctx, doltdb.DoltHistoryTablePrefix+tableName)
+	if err != nil {
+		return nil, err
+	}
+	history, isHistory := tbl.(*HistoryTable)
+	if !ok || !isHistory {
+		return nil, sql.ErrTableNotFound.New(tableName)
+	}
+	if schema.IsKeyless(history.doltTable.sch) {
+		return nil, ErrSystemTimeKeylessTable.New(tableName)
+	}
+	return history, nil
+}
+
+// Children implements the sql.Node interface
+func (stf *SystemTimeTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface
+func (stf *SystemTimeTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return stf, nil
+}
+
+// CheckPrivileges implements the sql.Node interface
+func (stf *SystemTimeTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	if stf.history == nil {
+		return false
+	}
+	subject := sql.PrivilegeCheckSubject{Database: stf.history.doltTable.db.Name(), Table: stf.history.doltTable.Name()}
+	return opChecker.UserHasPrivileges(ctx, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+}
+
+// Schema implements the sql.Node interface
+func (stf *SystemTimeTableFunction) Schema() sql.Schema {
+	if !stf.Resolved() {
+		return nil
+	}
+	if stf.sqlSch == nil {
+		panic("schema hasn't been generated yet")
+	}
+	return stf.sqlSch
+}
+
+// Resolved implements the sql.Resolvable interface
+func (stf *SystemTimeTableFunction) Resolved() bool {
+	for _, expr := range stf.exprs {
+		if !expr.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReadOnly implements the sql.Node interface
+func (stf *SystemTimeTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// RowIter implements the sql.Node interface
+func (stf *SystemTimeTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	bounds := make([]interface{}, len(stf.exprs)-2)
+	for i, expr := range stf.exprs[2:] {
+		v, err := expr.Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			// no version is valid at an unknown time
+			return sql.RowsToRowIter(), nil
+		}
+		bounds[i], _, err = types.DatetimeMaxPrecision.Convert(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a version starting at a commit can only be selected if the commit is no later than the end of the period
+	include := func(meta *datas.CommitMeta) bool {
+		if len(bounds) == 0 {
+			return true
+		}
+		cmp, err := types.DatetimeMaxPrecision.Compare(meta.Time(), bounds[len(bounds)-1])
+		if err != nil {
+			return true
+		}
+		return cmp < 0 || cmp == 0 && stf.period != SystemTimeFromTo
+	}
+	iter, err := stf.history.newVersionsIter(ctx, include)
+	if err != nil {
+		return nil, err
+	}
+
+	var start interface{}
+	if len(bounds) > 0 {
+		start = bounds[0]
+	}
+	return &systemTimeIter{child: iter, start: start}, nil
+}
+
+// systemTimeIter filters the versions of rows whose periods begin in the period queried down to those which are
+// still valid at its start.
+type systemTimeIter struct {
+	child sql.RowIter
+	// the start of the period queried, or nil if every version is selected
+	start interface{}
+}
+
+// Next implements sql.RowIter
+func (i *systemTimeIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		r, err := i.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// valid_to is the last column of the row
+		validTo := r[len(r)-1]
+		if i.start == nil || validTo == nil {
+			return r, nil
+		}
+		cmp, err := types.DatetimeMaxPrecision.Compare(validTo, i.start)
+		if err != nil {
+			return nil, err
+		}
+		if cmp > 0 {
+			return r, nil
+		}
+	}
+}
+
+// Close implements sql.RowIter
+func (i *systemTimeIter) Close(ctx *sql.Context) error {
+	return i.child.Close(ctx)
+}
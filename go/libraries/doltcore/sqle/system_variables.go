@@ -198,6 +198,20 @@ func AddDoltSystemVariables() {
 			Type:    types.NewSystemIntType(dsess.DoltClusterAckWritesTimeoutSecs, 0, 60, false),
 			Default: int64(0),
 		},
+		&sql.MysqlSystemVariable{ // If non-zero, reads on a cluster standby fail when it is further behind its primary than this.
+			Name:    dsess.DoltMaxReplicaLagMs,
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Both),
+			Type:    types.NewSystemIntType(dsess.DoltMaxReplicaLagMs, 0, math.MaxInt64, false),
+			Default: int64(0),
+		},
+		&sql.MysqlSystemVariable{ // Replaces the engine's definition, which is fixed at 1 since it doesn't lock rows.
+			Name:    dsess.InnodbLockWaitTimeout,
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Both),
+			Type:    types.NewSystemIntType(dsess.InnodbLockWaitTimeout, 1, 1073741824, false),
+			Default: int64(50),
+		},
 		&sql.MysqlSystemVariable{
 			Name:    dsess.ShowSystemTables,
 			Dynamic: true,
@@ -94,6 +94,9 @@ type DoltTable struct {
 
 	// overriddenSchema is set when the @@dolt_override_schema system var is in use
 	overriddenSchema schema.Schema
+
+	// lockingRead is set when a locking clause locks the rows this table reads
+	lockingRead *dsess.LockingRead
 }
 
 func NewDoltTable(name string, sch schema.Schema, tbl *doltdb.Table, db dsess.SqlDatabase, opts editor.Options) (*DoltTable, error) {
@@ -242,6 +245,19 @@ func (t *DoltTable) IndexCacheKey(ctx *sql.Context) (doltdb.DataCacheKey, bool,
 	return doltdb.DataCacheKey{Hash: key}, true, nil
 }
 
+// recordIndexRead records the read of the lookup partition |part| of |idx| for SERIALIZABLE transactions.
+func (t *DoltTable) recordIndexRead(ctx *sql.Context, idx index.DoltIndex, part sql.Partition) {
+	if t.lockedToRoot != nil {
+		// reads of a fixed root can't be affected by concurrent writes
+		return
+	}
+	if rng, ok := index.ProllyRangeForPartition(part); ok {
+		dsess.RecordIndexRead(ctx, t.db.Name(), t.tableName, idx.ID(), &rng)
+	} else {
+		dsess.RecordIndexRead(ctx, t.db.Name(), t.tableName, idx.ID(), nil)
+	}
+}
+
 func (t *DoltTable) workingRoot(ctx *sql.Context) (doltdb.RootValue, error) {
 	root := t.lockedToRoot
 	if root == nil {
@@ -504,10 +520,25 @@ func (t *DoltTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sq
 		}
 	}
 
+	// Locking reads need the primary key of every row, so they read every column and project afterward.
+	locker, err := t.newRowLocker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if locker != nil {
+		projCols = t.allColumnTags()
+	}
+
 	originalRowIter, err := partitionRows(ctx, table, projCols, partition)
 	if err != nil {
 		return originalRowIter, err
 	}
+	if t.lockedToRoot == nil {
+		dsess.RecordTableRead(ctx, t.db.Name(), t.tableName)
+	}
+	if locker != nil {
+		originalRowIter = t.newLockingRowIter(originalRowIter, locker, t.overriddenSchema == nil)
+	}
 
 	if t.overriddenSchema != nil {
 		return newMappingRowIter(ctx, t, originalRowIter)
@@ -837,6 +868,10 @@ func (t *WritableDoltTable) Deleter(ctx *sql.Context) sql.RowDeleter {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.newRowLockingWriter(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -849,6 +884,10 @@ func (t *WritableDoltTable) Replacer(ctx *sql.Context) sql.RowReplacer {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.newRowLockingWriter(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -1003,6 +1042,10 @@ func (t *WritableDoltTable) Updater(ctx *sql.Context) sql.RowUpdater {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.newRowLockingWriter(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
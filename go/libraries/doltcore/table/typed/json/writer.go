@@ -71,6 +71,18 @@ func NewJSONSqlWriter(wr io.WriteCloser, sch sql.Schema) (*RowWriter, error) {
 	return w, nil
 }
 
+// NewNDJSONSqlWriter returns a new writer that encodes rows as newline delimited JSON, with one JSON object per line.
+// Unlike |NewJSONSqlWriter|, the output is valid after every row, which makes it suitable for streaming large results.
+func NewNDJSONSqlWriter(wr io.WriteCloser, sch sql.Schema) (*RowWriter, error) {
+	w, err := NewJSONWriterWithHeader(wr, nil, "", "\n", "\n")
+	if err != nil {
+		return nil, err
+	}
+
+	w.sqlSch = sch
+	return w, nil
+}
+
 func NewJSONWriterWithHeader(wr io.WriteCloser, outSch schema.Schema, header, footer, separator string) (*RowWriter, error) {
 	bwr := bufio.NewWriterSize(wr, WriteBufSize)
 	return &RowWriter{
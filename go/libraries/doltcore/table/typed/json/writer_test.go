@@ -0,0 +1,56 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type StringBuilderCloser struct {
+	strings.Builder
+}
+
+func (*StringBuilderCloser) Close() error {
+	return nil
+}
+
+func TestNDJSONSqlWriter(t *testing.T) {
+	ctx := context.Background()
+	sch := sql.Schema{
+		{Name: "id", Type: types.Int64},
+		{Name: "name", Type: types.Text},
+	}
+
+	var sb StringBuilderCloser
+	wr, err := NewNDJSONSqlWriter(&sb, sch)
+	require.NoError(t, err)
+	require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(1), "one"}))
+	require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(2), nil}))
+	require.NoError(t, wr.Close(ctx))
+	assert.Equal(t, "{\"id\":1,\"name\":\"one\"}\n{\"id\":2}\n", sb.String())
+
+	sb.Reset()
+	wr, err = NewNDJSONSqlWriter(&sb, sch)
+	require.NoError(t, err)
+	require.NoError(t, wr.Close(ctx))
+	assert.Empty(t, sb.String())
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"io"
+	"os"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gomysql "github.com/go-sql-driver/mysql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+// Querier runs queries against a MySQL server. It is implemented by both connection pools and single connections.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*gosql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *gosql.Row
+}
+
+// Open opens a connection pool to the MySQL server of the DSN given, which must name the database to read from. If the
+// DSN has no password, the password is read from DOLT_IMPORT_PASSWORD, so that it need not be given on the command
+// line.
+func Open(ctx context.Context, dsn string) (*gosql.DB, error) {
+	cfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Passwd == "" {
+		cfg.Passwd = os.Getenv(dconfig.EnvImportPassword)
+	}
+	connector, err := gomysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db := gosql.OpenDB(connector)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Snapshot is a single connection to a MySQL server with a read only transaction open on a consistent snapshot of its
+// data, so that the tables read through it are consistent with each other. Only one query can be read through it at a
+// time.
+type Snapshot struct {
+	*gosql.Conn
+	db *gosql.DB
+}
+
+var _ Querier = (*Snapshot)(nil)
+
+// OpenSnapshot opens a Snapshot of the database of the DSN given, as Open does.
+func OpenSnapshot(ctx context.Context, dsn string) (*Snapshot, error) {
+	db, err := Open(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s := &Snapshot{Conn: conn, db: db}
+
+	_, err = conn.ExecContext(ctx, "SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY")
+		if err != nil {
+			// Servers which don't support consistent snapshots, such as Dolt, still read one snapshot of the database
+			// in a repeatable read transaction, which is taken by its first read rather than when it starts.
+			_, err = conn.ExecContext(ctx, "START TRANSACTION READ ONLY")
+		}
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close ends the transaction of the snapshot and closes its connection.
+func (s *Snapshot) Close() error {
+	err := s.Conn.Close()
+	if cerr := s.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// TableReader implements table.SqlRowReader. It streams the rows of a table of a MySQL server in primary key order.
+// The rows are queried when the first one is read, so that a reader can be opened just for its schema.
+type TableReader struct {
+	q        Querier
+	closer   io.Closer
+	def      *TableDef
+	sch      schema.Schema
+	tableSch schema.Schema
+	rows     *gosql.Rows
+	binary   []bool
+	vals     []gosql.RawBytes
+	ptrs     []interface{}
+}
+
+var _ table.SqlRowReader = (*TableReader)(nil)
+
+// NewTableReader returns a reader of the table given, which queries its rows through |q|. |tableSch| is the schema of
+// the table, and |sch| the schema of its stored columns, which are the columns of the rows read. If |closer| is not
+// nil, it is closed with the reader.
+func NewTableReader(q Querier, closer io.Closer, def *TableDef, tableSch, sch schema.Schema) *TableReader {
+	cols := def.StoredColumns()
+	rd := &TableReader{
+		q:        q,
+		closer:   closer,
+		def:      def,
+		sch:      sch,
+		tableSch: tableSch,
+		binary:   make([]bool, len(cols)),
+		vals:     make([]gosql.RawBytes, len(cols)),
+		ptrs:     make([]interface{}, len(cols)),
+	}
+	for i, col := range cols {
+		rd.binary[i] = col.isBinary()
+		rd.ptrs[i] = &rd.vals[i]
+	}
+	return rd
+}
+
+// GetSchema returns the schema of the rows read, which excludes generated columns.
+func (rd *TableReader) GetSchema() schema.Schema {
+	return rd.sch
+}
+
+// TableSchema returns the schema of the table being read.
+func (rd *TableReader) TableSchema() schema.Schema {
+	return rd.tableSch
+}
+
+// CreateStatement returns a statement which creates the table being read with the name given.
+func (rd *TableReader) CreateStatement(name string) string {
+	return rd.def.CreateStatement(name)
+}
+
+func (rd *TableReader) ReadRow(ctx context.Context) (row.Row, error) {
+	panic("deprecated")
+}
+
+// ReadSqlRow returns the next row of the table. Values are returned as strings, or as []byte for binary columns, and
+// are converted to the types of their columns when they are written.
+func (rd *TableReader) ReadSqlRow(ctx context.Context) (sql.Row, error) {
+	if rd.rows == nil {
+		rows, err := rd.q.QueryContext(ctx, rd.def.SelectStatement())
+		if err != nil {
+			return nil, err
+		}
+		rd.rows = rows
+	}
+
+	if !rd.rows.Next() {
+		if err := rd.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err := rd.rows.Scan(rd.ptrs...); err != nil {
+		return nil, err
+	}
+
+	r := make(sql.Row, len(rd.vals))
+	for i, val := range rd.vals {
+		switch {
+		case val == nil:
+			r[i] = nil
+		case rd.binary[i]:
+			r[i] = append([]byte{}, val...)
+		default:
+			r[i] = string(val)
+		}
+	}
+	return r, nil
+}
+
+// Close closes the query of the reader, and its closer if it has one.
+func (rd *TableReader) Close(ctx context.Context) error {
+	var err error
+	if rd.rows != nil {
+		err = rd.rows.Close()
+	}
+	if rd.closer != nil {
+		if cerr := rd.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,455 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// TableDef is the definition of a table of a MySQL server, read from its information_schema.
+type TableDef struct {
+	Name        string
+	Collation   string
+	Comment     string
+	Columns     []ColumnDef
+	PrimaryKey  []string
+	Indexes     []IndexDef
+	Checks      []CheckDef
+	ForeignKeys []ForeignKeyDef
+}
+
+// ColumnDef is the definition of a column of a MySQL table.
+type ColumnDef struct {
+	Name      string
+	Type      string
+	Nullable  bool
+	Default   *string
+	Extra     string
+	Comment   string
+	Collation string
+	// Generated is the expression of a generated column, or the empty string for a stored column.
+	Generated string
+}
+
+// IndexDef is the definition of a secondary index of a MySQL table.
+type IndexDef struct {
+	Name     string
+	Unique   bool
+	FullText bool
+	Spatial  bool
+	Columns  []IndexColumn
+}
+
+// IndexColumn is a column of an index, with the length of its prefix if the index is on a prefix of the column.
+type IndexColumn struct {
+	Name   string
+	Length int64
+}
+
+// CheckDef is the definition of a check constraint of a MySQL table.
+type CheckDef struct {
+	Name   string
+	Clause string
+}
+
+// ForeignKeyDef is the definition of a foreign key of a MySQL table.
+type ForeignKeyDef struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// ListTables returns the names of the base tables of the current database of the connection given.
+func ListTables(ctx context.Context, q Querier) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `select table_name from information_schema.tables
+where table_schema = database() and table_type = 'BASE TABLE' order by table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// ReadTableDef reads the definition of the table given from the information_schema of the current database of the
+// connection given.
+func ReadTableDef(ctx context.Context, q Querier, table string) (*TableDef, error) {
+	def := &TableDef{Name: table}
+
+	var collation gosql.NullString
+	err := q.QueryRowContext(ctx, `select table_collation, table_comment from information_schema.tables
+where table_schema = database() and table_name = ? and table_type = 'BASE TABLE'`, table).Scan(&collation, &def.Comment)
+	if err == gosql.ErrNoRows {
+		return nil, fmt.Errorf("table '%s' not found", table)
+	} else if err != nil {
+		return nil, err
+	}
+	def.Collation = collation.String
+
+	if err := def.readColumns(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := def.readIndexes(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := def.readChecks(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := def.readForeignKeys(ctx, q); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (def *TableDef) readColumns(ctx context.Context, q Querier) error {
+	rows, err := q.QueryContext(ctx, `select column_name, column_type, is_nullable, column_default, extra, column_comment,
+collation_name, generation_expression from information_schema.columns
+where table_schema = database() and table_name = ? order by ordinal_position`, def.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col ColumnDef
+		var nullable string
+		var dflt, extra, collation, generated gosql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &nullable, &dflt, &extra, &col.Comment, &collation, &generated); err != nil {
+			return err
+		}
+		col.Nullable = nullable == "YES"
+		if dflt.Valid {
+			col.Default = &dflt.String
+		}
+		col.Extra = extra.String
+		col.Collation = collation.String
+		if strings.Contains(strings.ToUpper(col.Extra), "GENERATED") && !strings.Contains(strings.ToUpper(col.Extra), "DEFAULT_GENERATED") {
+			col.Generated = generated.String
+		}
+		def.Columns = append(def.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(def.Columns) == 0 {
+		return fmt.Errorf("table '%s' has no columns", def.Name)
+	}
+	return nil
+}
+
+func (def *TableDef) readIndexes(ctx context.Context, q Querier) error {
+	rows, err := q.QueryContext(ctx, `select index_name, non_unique, column_name, sub_part, index_type
+from information_schema.statistics
+where table_schema = database() and table_name = ? order by index_name, seq_in_index`, def.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	indexes := make(map[string]*IndexDef)
+	var names []string
+	for rows.Next() {
+		var name, indexType string
+		var nonUnique bool
+		var column gosql.NullString
+		var subPart gosql.NullInt64
+		if err := rows.Scan(&name, &nonUnique, &column, &subPart, &indexType); err != nil {
+			return err
+		}
+		if !column.Valid {
+			return fmt.Errorf("table '%s' has functional index '%s', which can't be imported", def.Name, name)
+		}
+		if name == "PRIMARY" {
+			def.PrimaryKey = append(def.PrimaryKey, column.String)
+			continue
+		}
+
+		idx, ok := indexes[name]
+		if !ok {
+			idx = &IndexDef{
+				Name:     name,
+				Unique:   !nonUnique,
+				FullText: strings.EqualFold(indexType, "FULLTEXT"),
+				Spatial:  strings.EqualFold(indexType, "SPATIAL"),
+			}
+			indexes[name] = idx
+			names = append(names, name)
+		}
+		idx.Columns = append(idx.Columns, IndexColumn{Name: column.String, Length: subPart.Int64})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		def.Indexes = append(def.Indexes, *indexes[name])
+	}
+	return nil
+}
+
+func (def *TableDef) readChecks(ctx context.Context, q Querier) error {
+	rows, err := q.QueryContext(ctx, `select tc.constraint_name, cc.check_clause
+from information_schema.table_constraints tc join information_schema.check_constraints cc
+on tc.constraint_schema = cc.constraint_schema and tc.constraint_name = cc.constraint_name
+where tc.table_schema = database() and tc.table_name = ? and tc.constraint_type = 'CHECK'
+order by tc.constraint_name`, def.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var check CheckDef
+		if err := rows.Scan(&check.Name, &check.Clause); err != nil {
+			return err
+		}
+		def.Checks = append(def.Checks, check)
+	}
+	return rows.Err()
+}
+
+func (def *TableDef) readForeignKeys(ctx context.Context, q Querier) error {
+	rows, err := q.QueryContext(ctx, `select kcu.constraint_name, kcu.column_name, kcu.referenced_table_name,
+kcu.referenced_column_name, rc.update_rule, rc.delete_rule
+from information_schema.key_column_usage kcu join information_schema.referential_constraints rc
+on kcu.constraint_schema = rc.constraint_schema and kcu.constraint_name = rc.constraint_name and kcu.table_name = rc.table_name
+where kcu.table_schema = database() and kcu.table_name = ? and kcu.referenced_table_name is not null
+order by kcu.constraint_name, kcu.ordinal_position`, def.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, column, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+		if n := len(def.ForeignKeys); n == 0 || def.ForeignKeys[n-1].Name != name {
+			def.ForeignKeys = append(def.ForeignKeys, ForeignKeyDef{Name: name, RefTable: refTable, OnUpdate: onUpdate, OnDelete: onDelete})
+		}
+		fk := &def.ForeignKeys[len(def.ForeignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+	return rows.Err()
+}
+
+// CreateStatement returns a CREATE TABLE statement for the table with the name given. Foreign keys are not included,
+// so that tables can be created and loaded in any order, see AddForeignKeyStatements.
+func (def *TableDef) CreateStatement(name string) string {
+	var defs []string
+	for _, col := range def.Columns {
+		defs = append(defs, col.definition())
+	}
+	if len(def.PrimaryKey) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", quoteIdentifiers(def.PrimaryKey)))
+	}
+	for _, idx := range def.Indexes {
+		defs = append(defs, idx.definition())
+	}
+	for _, check := range def.Checks {
+		clause := strings.TrimSpace(check.Clause)
+		if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+			clause = "(" + clause + ")"
+		}
+		defs = append(defs, fmt.Sprintf("CONSTRAINT %s CHECK %s", sql.QuoteIdentifier(check.Name), clause))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n  %s\n)", sql.QuoteIdentifier(name), strings.Join(defs, ",\n  "))
+	if def.Collation != "" {
+		fmt.Fprintf(&b, " COLLATE=%s", def.Collation)
+	}
+	if def.Comment != "" {
+		fmt.Fprintf(&b, " COMMENT=%s", quoteString(def.Comment))
+	}
+	return b.String()
+}
+
+// AddForeignKeyStatements returns ALTER TABLE statements which add the foreign keys of the table to the table with the
+// name given.
+func (def *TableDef) AddForeignKeyStatements(name string) []string {
+	var stmts []string
+	for _, fk := range def.ForeignKeys {
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			sql.QuoteIdentifier(name), sql.QuoteIdentifier(fk.Name), quoteIdentifiers(fk.Columns),
+			sql.QuoteIdentifier(fk.RefTable), quoteIdentifiers(fk.RefColumns))
+		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+			stmt += " ON UPDATE " + fk.OnUpdate
+		}
+		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+			stmt += " ON DELETE " + fk.OnDelete
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// StoredColumns returns the columns whose values are read from the table, which excludes generated columns.
+func (def *TableDef) StoredColumns() []ColumnDef {
+	var cols []ColumnDef
+	for _, col := range def.Columns {
+		if col.Generated == "" {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// SelectStatement returns a query for the values of the stored columns of the table, in primary key order.
+func (def *TableDef) SelectStatement() string {
+	var exprs []string
+	for _, col := range def.StoredColumns() {
+		if col.isBit() {
+			// bit values are returned as binary strings, which don't convert to integers
+			exprs = append(exprs, fmt.Sprintf("%s + 0 AS %s", sql.QuoteIdentifier(col.Name), sql.QuoteIdentifier(col.Name)))
+		} else {
+			exprs = append(exprs, sql.QuoteIdentifier(col.Name))
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), sql.QuoteIdentifier(def.Name))
+	if len(def.PrimaryKey) > 0 {
+		query += " ORDER BY " + quoteIdentifiers(def.PrimaryKey)
+	}
+	return query
+}
+
+func (col ColumnDef) definition() string {
+	var b strings.Builder
+	b.WriteString(sql.QuoteIdentifier(col.Name))
+	b.WriteString(" ")
+	b.WriteString(col.Type)
+	if col.Collation != "" && col.isText() {
+		b.WriteString(" COLLATE " + col.Collation)
+	}
+
+	if col.Generated != "" {
+		kind := "VIRTUAL"
+		if strings.Contains(strings.ToUpper(col.Extra), "STORED") {
+			kind = "STORED"
+		}
+		fmt.Fprintf(&b, " GENERATED ALWAYS AS (%s) %s", col.Generated, kind)
+	}
+
+	if !col.Nullable {
+		b.WriteString(" NOT NULL")
+	}
+
+	extra := strings.ToUpper(col.Extra)
+	if col.Default != nil && col.Generated == "" {
+		switch {
+		case strings.Contains(extra, "DEFAULT_GENERATED"):
+			b.WriteString(" DEFAULT (" + *col.Default + ")")
+		case col.isBit() && strings.HasPrefix(*col.Default, "b'"):
+			b.WriteString(" DEFAULT " + *col.Default)
+		default:
+			b.WriteString(" DEFAULT " + quoteString(*col.Default))
+		}
+	}
+	if strings.Contains(extra, "AUTO_INCREMENT") {
+		b.WriteString(" AUTO_INCREMENT")
+	}
+	if i := strings.Index(extra, "ON UPDATE "); i >= 0 {
+		b.WriteString(" " + col.Extra[i:])
+	}
+	if col.Comment != "" {
+		b.WriteString(" COMMENT " + quoteString(col.Comment))
+	}
+	return b.String()
+}
+
+func (col ColumnDef) baseType() string {
+	typ := strings.ToLower(col.Type)
+	if i := strings.IndexAny(typ, "( "); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ
+}
+
+func (col ColumnDef) isBit() bool {
+	return col.baseType() == "bit"
+}
+
+func (col ColumnDef) isText() bool {
+	switch col.baseType() {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return true
+	}
+	return false
+}
+
+// isBinary returns whether values of the column are binary strings, rather than text.
+func (col ColumnDef) isBinary() bool {
+	switch col.baseType() {
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		return true
+	}
+	return false
+}
+
+func (idx IndexDef) definition() string {
+	cols := make([]string, len(idx.Columns))
+	for i, col := range idx.Columns {
+		cols[i] = sql.QuoteIdentifier(col.Name)
+		if col.Length > 0 {
+			cols[i] += fmt.Sprintf("(%d)", col.Length)
+		}
+	}
+
+	kind := "KEY"
+	switch {
+	case idx.Unique:
+		kind = "UNIQUE KEY"
+	case idx.FullText:
+		kind = "FULLTEXT KEY"
+	case idx.Spatial:
+		kind = "SPATIAL KEY"
+	}
+	return fmt.Sprintf("%s %s (%s)", kind, sql.QuoteIdentifier(idx.Name), strings.Join(cols, ", "))
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = sql.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTableDef() *TableDef {
+	dflt := func(s string) *string { return &s }
+	return &TableDef{
+		Name:      "parent",
+		Collation: "utf8mb4_bin",
+		Comment:   "it's a table",
+		Columns: []ColumnDef{
+			{Name: "id", Type: "int unsigned", Extra: "auto_increment"},
+			{Name: "name", Type: "varchar(20)", Default: dflt("x'y"), Comment: "the name", Collation: "utf8mb4_bin"},
+			{Name: "created", Type: "timestamp", Nullable: true, Default: dflt("CURRENT_TIMESTAMP"), Extra: "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"},
+			{Name: "flags", Type: "bit(4)", Nullable: true, Default: dflt("b'101'")},
+			{Name: "twice", Type: "int", Nullable: true, Extra: "STORED GENERATED", Generated: "(`id` * 2)"},
+		},
+		PrimaryKey: []string{"id"},
+		Indexes: []IndexDef{
+			{Name: "kidx", Columns: []IndexColumn{{Name: "flags"}, {Name: "name", Length: 5}}},
+			{Name: "uname", Unique: true, Columns: []IndexColumn{{Name: "name"}}},
+		},
+		Checks: []CheckDef{{Name: "chk", Clause: "`id` > 0"}},
+		ForeignKeys: []ForeignKeyDef{
+			{Name: "fk1", Columns: []string{"flags"}, RefTable: "other", RefColumns: []string{"f"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		},
+	}
+}
+
+func TestCreateStatement(t *testing.T) {
+	expected := "CREATE TABLE `new_parent` (\n" +
+		"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(20) COLLATE utf8mb4_bin NOT NULL DEFAULT 'x''y' COMMENT 'the name',\n" +
+		"  `created` timestamp DEFAULT (CURRENT_TIMESTAMP) on update CURRENT_TIMESTAMP,\n" +
+		"  `flags` bit(4) DEFAULT b'101',\n" +
+		"  `twice` int GENERATED ALWAYS AS ((`id` * 2)) STORED,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `kidx` (`flags`, `name`(5)),\n" +
+		"  UNIQUE KEY `uname` (`name`),\n" +
+		"  CONSTRAINT `chk` CHECK (`id` > 0)\n" +
+		") COLLATE=utf8mb4_bin COMMENT='it''s a table'"
+	assert.Equal(t, expected, testTableDef().CreateStatement("new_parent"))
+}
+
+func TestSelectStatement(t *testing.T) {
+	def := testTableDef()
+	assert.Equal(t, "SELECT `id`, `name`, `created`, `flags` + 0 AS `flags` FROM `parent` ORDER BY `id`", def.SelectStatement())
+
+	def.PrimaryKey = nil
+	assert.Equal(t, "SELECT `id`, `name`, `created`, `flags` + 0 AS `flags` FROM `parent`", def.SelectStatement())
+}
+
+func TestAddForeignKeyStatements(t *testing.T) {
+	def := testTableDef()
+	assert.Equal(t, []string{
+		"ALTER TABLE `new_parent` ADD CONSTRAINT `fk1` FOREIGN KEY (`flags`) REFERENCES `other` (`f`) ON DELETE CASCADE",
+	}, def.AddForeignKeyStatements("new_parent"))
+
+	def.ForeignKeys = nil
+	assert.Empty(t, def.AddForeignKeyStatements("new_parent"))
+}
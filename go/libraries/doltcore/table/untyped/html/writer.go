@@ -0,0 +1,116 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+const writeBufSize = 256 * 1024
+
+// HTMLWriter writes rows as an HTML table.
+type HTMLWriter struct {
+	wr            *bufio.Writer
+	closer        io.Closer
+	sch           sql.Schema
+	headerWritten bool
+}
+
+var _ table.SqlRowWriter = (*HTMLWriter)(nil)
+
+// NewHTMLSqlWriter returns a writer of the rows of |sch| as an HTML table to |wr|.
+func NewHTMLSqlWriter(wr io.WriteCloser, sch sql.Schema) *HTMLWriter {
+	return &HTMLWriter{
+		wr:     bufio.NewWriterSize(wr, writeBufSize),
+		closer: wr,
+		sch:    sch,
+	}
+}
+
+func (w *HTMLWriter) WriteSqlRow(ctx context.Context, r sql.Row) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<tr>")
+	for i, val := range r {
+		if val == nil {
+			sb.WriteString("<td>NULL</td>")
+			continue
+		}
+		str, err := sqlutil.SqlColToStr(w.sch[i].Type, val)
+		if err != nil {
+			return err
+		}
+		sb.WriteString("<td>")
+		sb.WriteString(html.EscapeString(str))
+		sb.WriteString("</td>")
+	}
+	sb.WriteString("</tr>\n")
+
+	_, err := w.wr.WriteString(sb.String())
+	return err
+}
+
+func (w *HTMLWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead>\n<tr>")
+	for _, col := range w.sch {
+		sb.WriteString("<th>")
+		sb.WriteString(html.EscapeString(col.Name))
+		sb.WriteString("</th>")
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	_, err := w.wr.WriteString(sb.String())
+	return err
+}
+
+// Close writes the end of the table, and closes the underlying writer.
+func (w *HTMLWriter) Close(ctx context.Context) error {
+	if w.closer == nil {
+		return errors.New("already closed")
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if _, err := w.wr.WriteString("</tbody>\n</table>\n"); err != nil {
+		return err
+	}
+
+	errFl := w.wr.Flush()
+	errCl := w.closer.Close()
+	w.closer = nil
+	if errFl != nil {
+		return errFl
+	}
+	return errCl
+}
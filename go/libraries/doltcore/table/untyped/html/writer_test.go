@@ -0,0 +1,64 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type StringBuilderCloser struct {
+	strings.Builder
+}
+
+func (*StringBuilderCloser) Close() error {
+	return nil
+}
+
+func TestHTMLWriter(t *testing.T) {
+	ctx := context.Background()
+	sch := sql.Schema{
+		{Name: "id", Type: types.Int64},
+		{Name: "<name>", Type: types.Text},
+	}
+
+	t.Run("rows", func(t *testing.T) {
+		var sb StringBuilderCloser
+		wr := NewHTMLSqlWriter(&sb, sch)
+		require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(1), "Tom & Jerry"}))
+		require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{nil, "<b>"}))
+		require.NoError(t, wr.Close(ctx))
+
+		expected := "<table>\n<thead>\n<tr><th>id</th><th>&lt;name&gt;</th></tr>\n</thead>\n<tbody>\n" +
+			"<tr><td>1</td><td>Tom &amp; Jerry</td></tr>\n" +
+			"<tr><td>NULL</td><td>&lt;b&gt;</td></tr>\n" +
+			"</tbody>\n</table>\n"
+		assert.Equal(t, expected, sb.String())
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		var sb StringBuilderCloser
+		wr := NewHTMLSqlWriter(&sb, sch)
+		require.NoError(t, wr.Close(ctx))
+		assert.Equal(t, "<table>\n<thead>\n<tr><th>id</th><th>&lt;name&gt;</th></tr>\n</thead>\n<tbody>\n</tbody>\n</table>\n", sb.String())
+		assert.Error(t, wr.Close(ctx))
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markdown
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+)
+
+// MarkdownDiffWriter writes the row diffs of a table as a markdown table, with a first column which marks each row as
+// added (+), removed (-), or the old (<) or new (>) values of a modified row. The changed cells of modified rows are
+// in bold. Rows are written as they are diffed, so the table is in the order of the diff.
+type MarkdownDiffWriter struct {
+	wr            *bufio.Writer
+	closer        io.Closer
+	sch           sql.Schema
+	headerWritten bool
+}
+
+var _ diff.SqlRowDiffWriter = (*MarkdownDiffWriter)(nil)
+
+// NewMarkdownDiffWriter returns a writer of the row diffs of a table with the schema |sch| to |wr|.
+func NewMarkdownDiffWriter(wr io.WriteCloser, sch sql.Schema) *MarkdownDiffWriter {
+	return &MarkdownDiffWriter{
+		wr:     bufio.NewWriterSize(wr, writeBufSize),
+		closer: wr,
+		sch:    sch,
+	}
+}
+
+func (w *MarkdownDiffWriter) WriteRow(ctx context.Context, row sql.Row, rowDiffType diff.ChangeType, colDiffTypes []diff.ChangeType) error {
+	if len(row) != len(colDiffTypes) {
+		return fmt.Errorf("expected the same size for columns and diff types, got %d and %d", len(row), len(colDiffTypes))
+	}
+
+	cells, err := rowStrings(w.sch, row)
+	if err != nil {
+		return err
+	}
+
+	var marker string
+	switch rowDiffType {
+	case diff.Added:
+		marker = "+"
+	case diff.Removed:
+		marker = "-"
+	case diff.ModifiedOld, diff.ModifiedNew:
+		marker = "<"
+		if rowDiffType == diff.ModifiedNew {
+			marker = ">"
+		}
+		for i := range cells {
+			if colDiffTypes[i] != diff.None {
+				cells[i] = "**" + cells[i] + "**"
+			}
+		}
+	default:
+		return nil
+	}
+
+	if !w.headerWritten {
+		w.headerWritten = true
+		if _, err := w.wr.WriteString("#### Rows\n\n"); err != nil {
+			return err
+		}
+		if err := writeHeader(w.wr, append(sql.Schema{{Name: " ", Type: types.Text}}, w.sch...)); err != nil {
+			return err
+		}
+	}
+	return writeRow(w.wr, append([]string{marker}, cells...))
+}
+
+func (w *MarkdownDiffWriter) WriteCombinedRow(ctx context.Context, oldRow, newRow sql.Row, mode diff.Mode) error {
+	return errors.New("markdown format is unable to output diffs for combined rows")
+}
+
+// Close ends the table of rows, if any rows were written, and closes the underlying writer.
+func (w *MarkdownDiffWriter) Close(ctx context.Context) error {
+	if w.closer == nil {
+		return errors.New("already closed")
+	}
+
+	if w.headerWritten {
+		if _, err := w.wr.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+
+	errFl := w.wr.Flush()
+	errCl := w.closer.Close()
+	w.closer = nil
+	if errFl != nil {
+		return errFl
+	}
+	return errCl
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markdown
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+const writeBufSize = 256 * 1024
+
+// MarkdownWriter writes rows as a GitHub flavored markdown table. Numeric columns are right aligned.
+type MarkdownWriter struct {
+	wr            *bufio.Writer
+	closer        io.Closer
+	sch           sql.Schema
+	headerWritten bool
+}
+
+var _ table.SqlRowWriter = (*MarkdownWriter)(nil)
+
+// NewMarkdownSqlWriter returns a writer of the rows of |sch| as a markdown table to |wr|.
+func NewMarkdownSqlWriter(wr io.WriteCloser, sch sql.Schema) *MarkdownWriter {
+	return &MarkdownWriter{
+		wr:     bufio.NewWriterSize(wr, writeBufSize),
+		closer: wr,
+		sch:    sch,
+	}
+}
+
+func (w *MarkdownWriter) WriteSqlRow(ctx context.Context, r sql.Row) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	cells, err := rowStrings(w.sch, r)
+	if err != nil {
+		return err
+	}
+	return writeRow(w.wr, cells)
+}
+
+func (w *MarkdownWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return writeHeader(w.wr, w.sch)
+}
+
+// Close writes the header of the table if no rows were written, and closes the underlying writer.
+func (w *MarkdownWriter) Close(ctx context.Context) error {
+	if w.closer == nil {
+		return errors.New("already closed")
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	errFl := w.wr.Flush()
+	errCl := w.closer.Close()
+	w.closer = nil
+	if errFl != nil {
+		return errFl
+	}
+	return errCl
+}
+
+// writeHeader writes the column names and the delimiter row of a markdown table.
+func writeHeader(wr io.StringWriter, sch sql.Schema) error {
+	names := make([]string, len(sch))
+	delims := make([]string, len(sch))
+	for i, col := range sch {
+		names[i] = escape(col.Name)
+		if types.IsNumber(col.Type) {
+			delims[i] = "---:"
+		} else {
+			delims[i] = "---"
+		}
+	}
+	if err := writeRow(wr, names); err != nil {
+		return err
+	}
+	return writeRow(wr, delims)
+}
+
+func writeRow(wr io.StringWriter, cells []string) error {
+	_, err := wr.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	return err
+}
+
+// rowStrings returns the escaped markdown of each value of |r|.
+func rowStrings(sch sql.Schema, r sql.Row) ([]string, error) {
+	cells := make([]string, len(r))
+	for i, val := range r {
+		if val == nil {
+			cells[i] = "NULL"
+			continue
+		}
+		str, err := sqlutil.SqlColToStr(sch[i].Type, val)
+		if err != nil {
+			return nil, err
+		}
+		cells[i] = escape(str)
+	}
+	return cells, nil
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`|`, `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+// escape returns |s| as the content of a markdown table cell, which cannot contain pipes or line breaks.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
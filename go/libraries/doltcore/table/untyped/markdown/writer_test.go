@@ -0,0 +1,99 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markdown
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+)
+
+type StringBuilderCloser struct {
+	strings.Builder
+}
+
+func (*StringBuilderCloser) Close() error {
+	return nil
+}
+
+var testSch = sql.Schema{
+	{Name: "id", Type: types.Int64},
+	{Name: "name", Type: types.Text},
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rows", func(t *testing.T) {
+		var sb StringBuilderCloser
+		wr := NewMarkdownSqlWriter(&sb, testSch)
+		require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(1), "Michael Scott"}))
+		require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(2), "a|b\nc"}))
+		require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{nil, nil}))
+		require.NoError(t, wr.Close(ctx))
+
+		expected := "| id | name |\n" +
+			"| ---: | --- |\n" +
+			"| 1 | Michael Scott |\n" +
+			"| 2 | a\\|b<br>c |\n" +
+			"| NULL | NULL |\n"
+		assert.Equal(t, expected, sb.String())
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		var sb StringBuilderCloser
+		wr := NewMarkdownSqlWriter(&sb, testSch)
+		require.NoError(t, wr.Close(ctx))
+		assert.Equal(t, "| id | name |\n| ---: | --- |\n", sb.String())
+		assert.Error(t, wr.Close(ctx))
+	})
+}
+
+func TestMarkdownDiffWriter(t *testing.T) {
+	ctx := context.Background()
+	var sb StringBuilderCloser
+	wr := NewMarkdownDiffWriter(&sb, testSch)
+
+	none := []diff.ChangeType{diff.None, diff.None}
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(1), "old"}, diff.ModifiedOld, []diff.ChangeType{diff.None, diff.ModifiedOld}))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(1), "new"}, diff.ModifiedNew, []diff.ChangeType{diff.None, diff.ModifiedNew}))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(2), "added"}, diff.Added, none))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(3), "removed"}, diff.Removed, none))
+	assert.Error(t, wr.WriteRow(ctx, sql.Row{int64(4)}, diff.Added, none))
+	assert.Error(t, wr.WriteCombinedRow(ctx, sql.Row{}, sql.Row{}, diff.ModeRow))
+	require.NoError(t, wr.Close(ctx))
+
+	expected := "#### Rows\n\n" +
+		"|   | id | name |\n" +
+		"| --- | ---: | --- |\n" +
+		"| < | 1 | **old** |\n" +
+		"| > | 1 | **new** |\n" +
+		"| + | 2 | added |\n" +
+		"| - | 3 | removed |\n" +
+		"\n"
+	assert.Equal(t, expected, sb.String())
+
+	sb = StringBuilderCloser{}
+	wr = NewMarkdownDiffWriter(&sb, testSch)
+	require.NoError(t, wr.Close(ctx))
+	assert.Empty(t, sb.String())
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+const writeBufSize = 256 * 1024
+
+const (
+	xmlHeader = "<?xml version=\"1.0\"?>\n\n<resultset xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\">\n"
+	xmlFooter = "</resultset>\n"
+)
+
+// XMLWriter writes rows as an XML row set, in the format of the --xml option of the mysql client. NULL values are
+// written as fields with the attribute xsi:nil="true".
+type XMLWriter struct {
+	wr            *bufio.Writer
+	closer        io.Closer
+	sch           sql.Schema
+	headerWritten bool
+}
+
+var _ table.SqlRowWriter = (*XMLWriter)(nil)
+
+// NewXMLSqlWriter returns a writer of the rows of |sch| as an XML row set to |wr|.
+func NewXMLSqlWriter(wr io.WriteCloser, sch sql.Schema) *XMLWriter {
+	return &XMLWriter{
+		wr:     bufio.NewWriterSize(wr, writeBufSize),
+		closer: wr,
+		sch:    sch,
+	}
+}
+
+func (w *XMLWriter) WriteSqlRow(ctx context.Context, r sql.Row) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  <row>\n")
+	for i, val := range r {
+		sb.WriteString("\t<field name=\"")
+		if err := xml.EscapeText(&sb, []byte(w.sch[i].Name)); err != nil {
+			return err
+		}
+		if val == nil {
+			sb.WriteString("\" xsi:nil=\"true\" />\n")
+			continue
+		}
+		str, err := sqlutil.SqlColToStr(w.sch[i].Type, val)
+		if err != nil {
+			return err
+		}
+		sb.WriteString("\">")
+		if err := xml.EscapeText(&sb, []byte(str)); err != nil {
+			return err
+		}
+		sb.WriteString("</field>\n")
+	}
+	sb.WriteString("  </row>\n")
+
+	_, err := w.wr.WriteString(sb.String())
+	return err
+}
+
+func (w *XMLWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	_, err := w.wr.WriteString(xmlHeader)
+	return err
+}
+
+// Close writes the end of the row set, and closes the underlying writer.
+func (w *XMLWriter) Close(ctx context.Context) error {
+	if w.closer == nil {
+		return errors.New("already closed")
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if _, err := w.wr.WriteString(xmlFooter); err != nil {
+		return err
+	}
+
+	errFl := w.wr.Flush()
+	errCl := w.closer.Close()
+	w.closer = nil
+	if errFl != nil {
+		return errFl
+	}
+	return errCl
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type StringBuilderCloser struct {
+	strings.Builder
+}
+
+func (*StringBuilderCloser) Close() error {
+	return nil
+}
+
+func TestXMLWriter(t *testing.T) {
+	ctx := context.Background()
+	sch := sql.Schema{
+		{Name: "id", Type: types.Int64},
+		{Name: "name", Type: types.Text},
+	}
+
+	var sb StringBuilderCloser
+	wr := NewXMLSqlWriter(&sb, sch)
+	require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(1), "Tom & Jerry"}))
+	require.NoError(t, wr.WriteSqlRow(ctx, sql.Row{int64(2), nil}))
+	require.NoError(t, wr.Close(ctx))
+	assert.Error(t, wr.Close(ctx))
+
+	expected := `<?xml version="1.0"?>
+
+<resultset xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row>
+	<field name="id">1</field>
+	<field name="name">Tom &amp; Jerry</field>
+  </row>
+  <row>
+	<field name="id">2</field>
+	<field name="name" xsi:nil="true" />
+  </row>
+</resultset>
+`
+	assert.Equal(t, expected, sb.String())
+
+	var parsed struct {
+		Rows []struct {
+			Fields []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"field"`
+		} `xml:"row"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(sb.String()), &parsed))
+	require.Len(t, parsed.Rows, 2)
+	assert.Equal(t, "Tom & Jerry", parsed.Rows[0].Fields[1].Value)
+}
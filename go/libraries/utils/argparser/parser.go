@@ -354,6 +354,17 @@ func (ap *ArgParser) parseToken(args []string, index int, positionalArgs []strin
 		return 0, nil, nil, ErrHelp
 	}
 
+	if isLongFormFlag {
+		// a long form flag is matched by its whole name, even if a value option's abbreviation is a prefix of it
+		if opt, ok := ap.nameOrAbbrevToOpt[arg]; ok && opt.OptType == OptionalFlag && opt.Name == arg {
+			if _, exists := namedArgs[opt.Name]; exists {
+				return 0, nil, nil, errors.New("error: multiple values provided for `" + opt.Name + "'")
+			}
+			namedArgs[opt.Name] = ""
+			return index, positionalArgs, namedArgs, nil
+		}
+	}
+
 	modalOpts, rest := ap.matchModalOptions(arg)
 
 	for _, opt := range modalOpts {
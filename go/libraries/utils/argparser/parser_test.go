@@ -93,6 +93,13 @@ func TestArgParser(t *testing.T) {
 			map[string]string{},
 			[]string{},
 		},
+		{
+			NewArgParserWithVariableArgs("test").SupportsString("param", "p", "", "").SupportsFlag("preview", "", ""),
+			[]string{"--preview", "-pvalue"},
+			nil,
+			map[string]string{"param": "value", "preview": ""},
+			[]string{},
+		},
 		{
 			NewArgParserWithMaxArgs("test", 1),
 			[]string{"foo", "bar"},
@@ -19,6 +19,7 @@ var ConfigOptions = map[string]struct{}{
 	UserNameKey:           {},
 	UserCreds:             {},
 	DoltEditor:            {},
+	DoltPager:             {},
 	InitBranchName:        {},
 	RemotesApiHostKey:     {},
 	RemotesApiHostPortKey: {},
@@ -41,6 +42,8 @@ const UserCreds = "user.creds"
 
 const DoltEditor = "core.editor"
 
+const DoltPager = "core.pager"
+
 const InitBranchName = "init.defaultbranch"
 
 const RemotesApiHostKey = "remotes.default_host"
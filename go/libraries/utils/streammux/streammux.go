@@ -0,0 +1,497 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package streammux multiplexes many independent byte streams over a single io.ReadWriteCloser, such as the stdin and
+// stdout of a child process. Each stream is a net.Conn, and a Session is a net.Listener for the streams opened by its
+// peer, so that HTTP and gRPC clients and servers can run over a connection which is not a network socket.
+package streammux
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type frameType uint8
+
+const (
+	frameOpen frameType = iota
+	frameData
+	frameWindow
+	frameClose
+)
+
+const (
+	headerSize = 9
+
+	// maxFrameSize is the largest payload of a data frame.
+	maxFrameSize = 32 * 1024
+
+	// windowSize is the number of bytes a peer may send on a stream before the stream's reader consumes them.
+	windowSize = 256 * 1024
+
+	acceptBacklog = 256
+)
+
+// ErrSessionClosed is returned by operations on a closed Session and its streams.
+var ErrSessionClosed = errors.New("streammux: session closed")
+
+// Session multiplexes streams over a single connection. Both peers of a session can open streams; the peer created
+// with |client| set to true uses odd stream ids and the other uses even ones.
+type Session struct {
+	conn io.ReadWriteCloser
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	streams map[uint32]*Stream
+	nextID  uint32
+	err     error
+
+	accept    chan *Stream
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ net.Listener = (*Session)(nil)
+
+// NewSession starts a Session over |conn|. The session owns |conn| and closes it when the session is closed or when
+// reading from it fails.
+func NewSession(conn io.ReadWriteCloser, client bool) *Session {
+	s := &Session{
+		conn:    conn,
+		streams: make(map[uint32]*Stream),
+		nextID:  2,
+		accept:  make(chan *Stream, acceptBacklog),
+		done:    make(chan struct{}),
+	}
+	if client {
+		s.nextID = 1
+	}
+	go s.readLoop()
+	return s
+}
+
+// Open opens a new stream to the peer.
+func (s *Session) Open() (*Stream, error) {
+	s.mu.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return nil, err
+	}
+	id := s.nextID
+	s.nextID += 2
+	st := newStream(s, id)
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, 0, nil); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Accept waits for and returns the next stream opened by the peer.
+func (s *Session) Accept() (net.Conn, error) {
+	select {
+	case st := <-s.accept:
+		return st, nil
+	case <-s.done:
+		return nil, s.Err()
+	}
+}
+
+// Addr returns the address of the session, which is not a network address.
+func (s *Session) Addr() net.Addr {
+	return addr{}
+}
+
+// Close closes the session, its connection and all of its streams.
+func (s *Session) Close() error {
+	s.shutdown(ErrSessionClosed)
+	return nil
+}
+
+// Done returns a channel which is closed when the session is closed, either by Close or because the connection
+// failed or was closed by the peer.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error which closed the session, or nil if it is still open. A session closed by its peer returns
+// io.EOF.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Session) shutdown(err error) {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		streams := s.streams
+		s.streams = make(map[uint32]*Stream)
+		s.mu.Unlock()
+
+		s.conn.Close()
+		close(s.done)
+		for _, st := range streams {
+			st.mu.Lock()
+			st.notify()
+			st.mu.Unlock()
+		}
+	})
+}
+
+func (s *Session) readLoop() {
+	var hdr [headerSize]byte
+	for {
+		if _, err := io.ReadFull(s.conn, hdr[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			s.shutdown(err)
+			return
+		}
+		typ := frameType(hdr[0])
+		id := binary.BigEndian.Uint32(hdr[1:5])
+		n := binary.BigEndian.Uint32(hdr[5:9])
+
+		switch typ {
+		case frameOpen:
+			st := newStream(s, id)
+			s.mu.Lock()
+			s.streams[id] = st
+			s.mu.Unlock()
+			select {
+			case s.accept <- st:
+			case <-s.done:
+				return
+			}
+
+		case frameData:
+			if n > maxFrameSize {
+				s.shutdown(fmt.Errorf("streammux: data frame of %d bytes exceeds the maximum frame size", n))
+				return
+			}
+			data := make([]byte, n)
+			if _, err := io.ReadFull(s.conn, data); err != nil {
+				s.shutdown(err)
+				return
+			}
+			if st := s.stream(id); st != nil {
+				st.received(data)
+			}
+
+		case frameWindow:
+			if st := s.stream(id); st != nil {
+				st.credited(int(n))
+			}
+
+		case frameClose:
+			if st := s.stream(id); st != nil {
+				st.remoteClose()
+			}
+
+		default:
+			s.shutdown(fmt.Errorf("streammux: unknown frame type %d", typ))
+			return
+		}
+	}
+}
+
+func (s *Session) stream(id uint32) *Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[id]
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, id)
+}
+
+// writeFrame writes a frame with header value |n| and |payload|. For data frames |n| is the length of the payload,
+// for window frames it is the number of bytes credited to the peer.
+func (s *Session) writeFrame(typ frameType, id uint32, n uint32, payload []byte) error {
+	var hdr [headerSize]byte
+	hdr[0] = byte(typ)
+	binary.BigEndian.PutUint32(hdr[1:5], id)
+	binary.BigEndian.PutUint32(hdr[5:9], n)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write(hdr[:]); err != nil {
+		s.shutdown(err)
+		return err
+	}
+	if len(payload) > 0 {
+		if _, err := s.conn.Write(payload); err != nil {
+			s.shutdown(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Stream is a bidirectional byte stream of a Session. Closing a stream closes both of its directions.
+type Stream struct {
+	sess *Session
+	id   uint32
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	changed       chan struct{}
+	buf           []byte
+	credit        int
+	localClosed   bool
+	remoteClosed  bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+var _ net.Conn = (*Stream)(nil)
+
+func newStream(sess *Session, id uint32) *Stream {
+	return &Stream{
+		sess:    sess,
+		id:      id,
+		changed: make(chan struct{}),
+		credit:  windowSize,
+	}
+}
+
+// notify wakes up readers and writers waiting for the state of the stream to change. Must be called with |st.mu| held.
+func (st *Stream) notify() {
+	close(st.changed)
+	st.changed = make(chan struct{})
+}
+
+func (st *Stream) received(data []byte) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.localClosed {
+		return
+	}
+	st.buf = append(st.buf, data...)
+	st.notify()
+}
+
+func (st *Stream) credited(n int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.credit += n
+	st.notify()
+}
+
+func (st *Stream) remoteClose() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.remoteClosed = true
+	if st.localClosed {
+		st.sess.removeStream(st.id)
+	}
+	st.notify()
+}
+
+// wait blocks until the state of the stream changes or |deadline| passes. It must be called with |st.mu| held, and
+// returns with it held.
+func (st *Stream) wait(deadline time.Time) error {
+	changed := st.changed
+	st.mu.Unlock()
+	defer st.mu.Lock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-changed:
+		return nil
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+func expired(deadline time.Time) bool {
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+// Read reads data sent by the peer, and returns io.EOF once the peer closed the stream and all of its data was read.
+func (st *Stream) Read(p []byte) (int, error) {
+	st.mu.Lock()
+	for {
+		if st.localClosed {
+			st.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+		if len(st.buf) > 0 {
+			n := copy(p, st.buf)
+			st.buf = st.buf[n:]
+			if len(st.buf) == 0 {
+				st.buf = nil
+			}
+			st.mu.Unlock()
+			// The window update may fail if the session was closed, but the data was read nonetheless
+			_ = st.sess.writeFrame(frameWindow, st.id, uint32(n), nil)
+			return n, nil
+		}
+		if st.remoteClosed {
+			st.mu.Unlock()
+			return 0, io.EOF
+		}
+		if err := st.sess.Err(); err != nil {
+			st.mu.Unlock()
+			return 0, err
+		}
+		if expired(st.readDeadline) {
+			st.mu.Unlock()
+			return 0, os.ErrDeadlineExceeded
+		}
+		if err := st.wait(st.readDeadline); err != nil {
+			st.mu.Unlock()
+			return 0, err
+		}
+	}
+}
+
+// Write sends |p| to the peer, blocking while the peer has not consumed earlier data.
+func (st *Stream) Write(p []byte) (int, error) {
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+
+	written := 0
+	for written < len(p) {
+		st.mu.Lock()
+		for {
+			var err error
+			switch {
+			case st.localClosed:
+				err = net.ErrClosed
+			case st.remoteClosed:
+				err = io.ErrClosedPipe
+			case st.sess.Err() != nil:
+				err = st.sess.Err()
+			case st.credit > 0:
+			case expired(st.writeDeadline):
+				err = os.ErrDeadlineExceeded
+			default:
+				err = st.wait(st.writeDeadline)
+				if err == nil {
+					continue
+				}
+			}
+			if err != nil {
+				st.mu.Unlock()
+				return written, err
+			}
+			break
+		}
+
+		n := len(p) - written
+		if n > st.credit {
+			n = st.credit
+		}
+		if n > maxFrameSize {
+			n = maxFrameSize
+		}
+		st.credit -= n
+		st.mu.Unlock()
+
+		if err := st.sess.writeFrame(frameData, st.id, uint32(n), p[written:written+n]); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// Close closes the stream. Data sent by the peer which was not read yet is discarded.
+func (st *Stream) Close() error {
+	st.mu.Lock()
+	if st.localClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.localClosed = true
+	st.buf = nil
+	if st.remoteClosed {
+		st.sess.removeStream(st.id)
+	}
+	st.notify()
+	st.mu.Unlock()
+
+	err := st.sess.writeFrame(frameClose, st.id, 0, nil)
+	if errors.Is(err, ErrSessionClosed) {
+		return nil
+	}
+	return err
+}
+
+func (st *Stream) LocalAddr() net.Addr {
+	return addr{}
+}
+
+func (st *Stream) RemoteAddr() net.Addr {
+	return addr{}
+}
+
+func (st *Stream) SetDeadline(t time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.readDeadline = t
+	st.writeDeadline = t
+	st.notify()
+	return nil
+}
+
+func (st *Stream) SetReadDeadline(t time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.readDeadline = t
+	st.notify()
+	return nil
+}
+
+func (st *Stream) SetWriteDeadline(t time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.writeDeadline = t
+	st.notify()
+	return nil
+}
+
+type addr struct{}
+
+func (addr) Network() string {
+	return "streammux"
+}
+
+func (addr) String() string {
+	return "streammux"
+}
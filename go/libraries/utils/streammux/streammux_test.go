@@ -0,0 +1,186 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streammux
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionPair(t *testing.T) (*Session, *Session) {
+	c1, c2 := net.Pipe()
+	client := NewSession(c1, true)
+	server := NewSession(c2, false)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func TestStreams(t *testing.T) {
+	client, server := newSessionPair(t)
+
+	// More than the window, so that writers have to wait for their peer to read
+	const size = 3*windowSize + 17
+
+	// Echo |size| bytes of every stream back to its opener
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.CopyN(conn, conn, size)
+			}()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st, err := client.Open()
+			require.NoError(t, err)
+			defer st.Close()
+
+			data := make([]byte, size)
+			_, err = rand.Read(data)
+			require.NoError(t, err)
+
+			go st.Write(data)
+			echoed, err := io.ReadAll(st)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, echoed))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStreamClose(t *testing.T) {
+	client, server := newSessionPair(t)
+
+	st, err := client.Open()
+	require.NoError(t, err)
+	conn, err := server.Accept()
+	require.NoError(t, err)
+
+	_, err = st.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, st.Close())
+
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = conn.Write([]byte("world"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+	_, err = st.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestDeadlines(t *testing.T) {
+	client, server := newSessionPair(t)
+
+	st, err := client.Open()
+	require.NoError(t, err)
+	_, err = server.Accept()
+	require.NoError(t, err)
+
+	require.NoError(t, st.SetReadDeadline(time.Now().Add(10*time.Millisecond)))
+	_, err = st.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	// A deadline in the past unblocks a pending read, as net/http does to abort background reads
+	require.NoError(t, st.SetReadDeadline(time.Time{}))
+	errCh := make(chan error)
+	go func() {
+		_, err := st.Read(make([]byte, 1))
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, st.SetReadDeadline(time.Unix(1, 0)))
+	assert.ErrorIs(t, <-errCh, os.ErrDeadlineExceeded)
+
+	// Writes block once the peer's window is full
+	require.NoError(t, st.SetWriteDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err = st.Write(make([]byte, windowSize+1))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestSessionClose(t *testing.T) {
+	client, server := newSessionPair(t)
+
+	st, err := client.Open()
+	require.NoError(t, err)
+	_, err = server.Accept()
+	require.NoError(t, err)
+
+	require.NoError(t, server.Close())
+	<-client.Done()
+	assert.ErrorIs(t, client.Err(), io.EOF)
+
+	_, err = st.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = client.Open()
+	assert.Error(t, err)
+	_, err = server.Accept()
+	assert.ErrorIs(t, err, ErrSessionClosed)
+}
+
+func TestHTTP(t *testing.T) {
+	client, server := newSessionPair(t)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.URL.Path + ":"))
+		w.Write(body)
+	})}
+	go srv.Serve(server)
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+			return client.Open()
+		},
+	}}
+	for i := 0; i < 3; i++ {
+		resp, err := httpClient.Post("http://streammux/path", "text/plain", bytes.NewReader([]byte("body")))
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "/path:body", string(data))
+	}
+
+	require.NoError(t, srv.Close())
+	_, err := server.Accept()
+	assert.True(t, errors.Is(err, ErrSessionClosed))
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash/maphash"
+	"io"
 	"log"
 	"math/rand"
 	"os"
@@ -119,6 +120,7 @@ func newBlobStoreTests() []BlobstoreTest {
 	tests = appendLocalTest(tests)
 	tests = appendGCSTest(tests)
 	tests = appendOCITest(tests)
+	tests = appendS3Test(tests)
 
 	return tests
 }
@@ -460,7 +462,7 @@ func testConcatenate(t *testing.T, bs Blobstore, cnt int) {
 		assert.NoError(t, err)
 
 		act := make([]byte, length)
-		n, err := rdr.Read(act)
+		n, err := io.ReadFull(rdr, act)
 		assert.NoError(t, err)
 		assert.Equal(t, int(length), n)
 		assert.Equal(t, blobs[i].data, act)
@@ -0,0 +1,355 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+const (
+	// s3MinPartSize is the smallest part of a multipart upload that S3 accepts, other than the last one
+	s3MinPartSize = 5 * 1024 * 1024
+	// s3MaxCopyPartSize is the largest part which S3 will copy from an existing object
+	s3MaxCopyPartSize = 5 * 1024 * 1024 * 1024
+	// s3UploadPartSize is the size of the parts in which large blobs are uploaded
+	s3UploadPartSize = 16 * 1024 * 1024
+)
+
+// S3Blobstore provides an implementation of the Blobstore interface for Amazon S3 and the many stores which are
+// compatible with it, such as MinIO and Ceph. Unlike the aws:// remotes, it needs nothing besides the bucket.
+// Versions are the ETags of objects, and CheckAndPut is implemented with conditional writes, so the store must
+// support the If-Match and If-None-Match headers on PutObject.
+type S3Blobstore struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+var _ Blobstore = &S3Blobstore{}
+
+// NewS3Blobstore creates a new instance of a S3Blobstore
+func NewS3Blobstore(client s3iface.S3API, bucket, prefix string) *S3Blobstore {
+	return &S3Blobstore{client: client, bucket: bucket, prefix: normalizePrefix(prefix)}
+}
+
+func (bs *S3Blobstore) Path() string {
+	return path.Join(bs.bucket, bs.prefix)
+}
+
+// Exists returns true if a blob exists for the given key, and false if it does not.
+func (bs *S3Blobstore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := bs.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.absKey(key)),
+	})
+	if isS3Status(err, http.StatusNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get retrieves an io.reader for the portion of a blob specified by br along with
+// its version
+func (bs *S3Blobstore) Get(ctx context.Context, key string, br BlobRange) (io.ReadCloser, string, error) {
+	absKey := bs.absKey(key)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(absKey),
+	}
+	if !br.isAllRange() {
+		input.Range = aws.String(s3RangeHeader(br))
+	}
+
+	result, err := bs.client.GetObjectWithContext(ctx, input)
+	if isS3Status(err, http.StatusNotFound) {
+		return nil, "", NotFound{"s3://" + path.Join(bs.bucket, absKey)}
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	rc := result.Body
+	if br.offset < 0 && br.length > 0 {
+		// HTTP ranges from the end of an object cannot be limited, so the rest is dropped here
+		rc = limitReadCloser{Reader: io.LimitReader(rc, br.length), Closer: rc}
+	}
+	return rc, aws.StringValue(result.ETag), nil
+}
+
+// Put sets the blob and the version for a key
+func (bs *S3Blobstore) Put(ctx context.Context, key string, totalSize int64, reader io.Reader) (string, error) {
+	if totalSize > s3UploadPartSize {
+		return bs.putMultipart(ctx, key, reader)
+	}
+	return bs.putObject(ctx, key, reader)
+}
+
+// CheckAndPut will check the current version of a blob against an expectedVersion, and if the
+// versions match it will update the data and version associated with the key
+func (bs *S3Blobstore) CheckAndPut(ctx context.Context, expectedVersion, key string, totalSize int64, reader io.Reader) (string, error) {
+	condition := func(r *request.Request) {
+		if expectedVersion == "" {
+			r.HTTPRequest.Header.Set("If-None-Match", "*")
+		} else {
+			r.HTTPRequest.Header.Set("If-Match", expectedVersion)
+		}
+	}
+
+	ver, err := bs.putObject(ctx, key, reader, condition)
+	// A conflicting concurrent write is reported as 409, and a missing object for If-Match as 404
+	if isS3Status(err, http.StatusPreconditionFailed) || isS3Status(err, http.StatusConflict) ||
+		(expectedVersion != "" && isS3Status(err, http.StatusNotFound)) {
+		return "", CheckAndPutError{key, expectedVersion, "unknown (Not supported in S3 implementation)"}
+	}
+	return ver, err
+}
+
+// Concatenate creates a new blob named |key| by concatenating |sources| with a multipart upload. Sources which are
+// large enough to be parts on their own are copied within the store, smaller ones are downloaded and uploaded
+// again together.
+func (bs *S3Blobstore) Concatenate(ctx context.Context, key string, sources []string) (string, error) {
+	sizes := make([]int64, len(sources))
+	for i, src := range sources {
+		head, err := bs.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bs.bucket),
+			Key:    aws.String(bs.absKey(src)),
+		})
+		if isS3Status(err, http.StatusNotFound) {
+			return "", NotFound{"s3://" + path.Join(bs.bucket, bs.absKey(src))}
+		} else if err != nil {
+			return "", err
+		}
+		sizes[i] = aws.Int64Value(head.ContentLength)
+	}
+
+	return bs.multipartUpload(ctx, key, func(up *s3Upload) error {
+		// at most a part of the sources is held in memory at a time
+		var pending bytes.Buffer
+		for i, src := range sources {
+			// Parts other than the last must be at least s3MinPartSize, so buffered data is never followed by a copy
+			if pending.Len() == 0 && sizes[i] >= s3MinPartSize {
+				if err := up.copyObject(ctx, bs.absKey(src), sizes[i]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := bs.uploadSource(ctx, up, src, &pending); err != nil {
+				return err
+			}
+			if pending.Len() >= s3MinPartSize {
+				if err := up.uploadPart(ctx, pending.Bytes()); err != nil {
+					return err
+				}
+				pending.Reset()
+			}
+		}
+		if pending.Len() > 0 || len(up.parts) == 0 {
+			return up.uploadPart(ctx, pending.Bytes())
+		}
+		return nil
+	})
+}
+
+// uploadSource reads the blob |key| into |pending|, uploading a part each time |pending| fills to s3UploadPartSize.
+func (bs *S3Blobstore) uploadSource(ctx context.Context, up *s3Upload, key string, pending *bytes.Buffer) error {
+	rc, _, err := bs.Get(ctx, key, AllRange)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	for {
+		_, err := io.CopyN(pending, rc, int64(s3UploadPartSize-pending.Len()))
+		if pending.Len() >= s3UploadPartSize {
+			if perr := up.uploadPart(ctx, pending.Bytes()); perr != nil {
+				return perr
+			}
+			pending.Reset()
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (bs *S3Blobstore) absKey(key string) string {
+	return path.Join(bs.prefix, key)
+}
+
+func (bs *S3Blobstore) putObject(ctx context.Context, key string, reader io.Reader, opts ...request.Option) (string, error) {
+	// PutObject needs a body which can be read again to sign and retry the request
+	body, ok := reader.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	result, err := bs.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.absKey(key)),
+		Body:   body,
+	}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(result.ETag), nil
+}
+
+// putMultipart uploads a large blob in parts of s3UploadPartSize, so that it does not have to be held in memory.
+func (bs *S3Blobstore) putMultipart(ctx context.Context, key string, reader io.Reader) (string, error) {
+	return bs.multipartUpload(ctx, key, func(up *s3Upload) error {
+		buf := make([]byte, s3UploadPartSize)
+		for {
+			n, err := io.ReadFull(reader, buf)
+			if n > 0 || len(up.parts) == 0 {
+				if perr := up.uploadPart(ctx, buf[:n]); perr != nil {
+					return perr
+				}
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// multipartUpload creates the blob |key| from the parts added by |addParts|. The upload is aborted if anything fails.
+func (bs *S3Blobstore) multipartUpload(ctx context.Context, key string, addParts func(up *s3Upload) error) (string, error) {
+	absKey := bs.absKey(key)
+	created, err := bs.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(absKey),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	up := &s3Upload{bs: bs, key: absKey, uploadID: aws.StringValue(created.UploadId)}
+	err = addParts(up)
+	var result *s3.CompleteMultipartUploadOutput
+	if err == nil {
+		result, err = bs.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bs.bucket),
+			Key:             aws.String(absKey),
+			UploadId:        aws.String(up.uploadID),
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: up.parts},
+		})
+	}
+	if err != nil {
+		_, _ = bs.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bs.bucket),
+			Key:      aws.String(absKey),
+			UploadId: aws.String(up.uploadID),
+		})
+		return "", err
+	}
+	return aws.StringValue(result.ETag), nil
+}
+
+// s3Upload is a multipart upload in progress.
+type s3Upload struct {
+	bs       *S3Blobstore
+	key      string
+	uploadID string
+	parts    []*s3.CompletedPart
+}
+
+func (up *s3Upload) nextPartNumber() *int64 {
+	return aws.Int64(int64(len(up.parts) + 1))
+}
+
+func (up *s3Upload) uploadPart(ctx context.Context, data []byte) error {
+	partNum := up.nextPartNumber()
+	result, err := up.bs.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(up.bs.bucket),
+		Key:        aws.String(up.key),
+		UploadId:   aws.String(up.uploadID),
+		PartNumber: partNum,
+		Body:       bytes.NewReader(data),
+	})
+	if err != nil {
+		return err
+	}
+	up.parts = append(up.parts, &s3.CompletedPart{ETag: result.ETag, PartNumber: partNum})
+	return nil
+}
+
+// copyObject adds the object |srcKey| of |size| bytes to the upload, in as many parts as S3 requires.
+func (up *s3Upload) copyObject(ctx context.Context, srcKey string, size int64) error {
+	numParts := size / s3MaxCopyPartSize
+	if size%s3MaxCopyPartSize > 0 {
+		numParts++
+	}
+	var start int64
+	for i := int64(0); i < numParts; i++ {
+		// parts of equal size, so that none of them is too small
+		end := size * (i + 1) / numParts
+		partNum := up.nextPartNumber()
+		result, err := up.bs.client.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(up.bs.bucket),
+			Key:             aws.String(up.key),
+			UploadId:        aws.String(up.uploadID),
+			PartNumber:      partNum,
+			CopySource:      aws.String(url.PathEscape(up.bs.bucket + "/" + srcKey)),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+		})
+		if err != nil {
+			return err
+		}
+		up.parts = append(up.parts, &s3.CompletedPart{ETag: result.CopyPartResult.ETag, PartNumber: partNum})
+		start = end
+	}
+	return nil
+}
+
+// s3RangeHeader returns the Range header which requests |br|. Unlike asHttpRangeHeader, a range from an offset to the
+// end of the blob is given as such, since S3 ignores a malformed range and returns the whole blob.
+func s3RangeHeader(br BlobRange) string {
+	if br.offset >= 0 && br.length == 0 {
+		return fmt.Sprintf("bytes=%d-", br.offset)
+	}
+	return br.asHttpRangeHeader()
+}
+
+func isS3Status(err error, status int) bool {
+	var reqErr awserr.RequestFailure
+	return errors.As(err, &reqErr) && reqErr.StatusCode() == status
+}
+
+type limitReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,398 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testS3Bucket = "test-bucket"
+
+var (
+	fakeS3Once   sync.Once
+	fakeS3Server *fakeS3
+	fakeS3Client *s3.S3
+)
+
+// newFakeS3Client returns a client for an in-process S3 fake which is shared by the tests, and the fake itself.
+func newFakeS3Client() (*s3.S3, *fakeS3) {
+	fakeS3Once.Do(func() {
+		fakeS3Server = &fakeS3{objects: map[string]fakeS3Object{}, uploads: map[string]*fakeS3Upload{}}
+		srv := httptest.NewServer(fakeS3Server)
+		sess := session.Must(session.NewSession(&aws.Config{
+			Region:           aws.String("us-east-1"),
+			Endpoint:         aws.String(srv.URL),
+			S3ForcePathStyle: aws.Bool(true),
+			Credentials:      credentials.NewStaticCredentials("access-key", "secret-key", ""),
+		}))
+		fakeS3Client = s3.New(sess)
+	})
+	return fakeS3Client, fakeS3Server
+}
+
+func appendS3Test(tests []BlobstoreTest) []BlobstoreTest {
+	client, _ := newFakeS3Client()
+	s3Test := BlobstoreTest{"s3", NewS3Blobstore(client, testS3Bucket, uuid.New().String()+"/"), 10, 20}
+	return append(tests, s3Test)
+}
+
+func TestS3ConcatenateLargeSources(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newFakeS3Client()
+	bs := NewS3Blobstore(client, testS3Bucket, uuid.New().String())
+
+	// Large sources are copied, small ones are gathered into parts which are big enough
+	sizes := []int{s3MinPartSize + 1, 1024, s3MinPartSize, 2048, 17}
+	var keys []string
+	var expected []byte
+	for i, size := range sizes {
+		data := randBytes(size)
+		keys = append(keys, strconv.Itoa(i))
+		_, err := PutBytes(ctx, bs, keys[i], data)
+		require.NoError(t, err)
+		expected = append(expected, data...)
+	}
+
+	copies := fake.partCopies()
+	ver, err := bs.Concatenate(ctx, "composite", keys)
+	require.NoError(t, err)
+	assert.Equal(t, copies+1, fake.partCopies())
+
+	data, getVer, err := GetBytes(ctx, bs, "composite", AllRange)
+	require.NoError(t, err)
+	assert.Equal(t, ver, getVer)
+	assert.True(t, bytes.Equal(expected, data))
+
+	_, err = bs.Concatenate(ctx, "composite", []string{"0", "missing"})
+	assert.True(t, IsNotFoundError(err))
+}
+
+func TestS3ConcatenateStreamsSources(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeS3Client()
+	bs := NewS3Blobstore(client, testS3Bucket, uuid.New().String())
+
+	// A large source after a small one can't be copied, so it is uploaded again a part at a time
+	small, large := randBytes(1024), randBytes(2*s3UploadPartSize+5)
+	_, err := PutBytes(ctx, bs, "small", small)
+	require.NoError(t, err)
+	_, err = PutBytes(ctx, bs, "large", large)
+	require.NoError(t, err)
+
+	ver, err := bs.Concatenate(ctx, "composite", []string{"small", "large"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(ver, `-3"`))
+
+	data, _, err := GetBytes(ctx, bs, "composite", AllRange)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(append(small, large...), data))
+
+	data, _, err = GetBytes(ctx, bs, "composite", NewBlobRange(int64(len(data)-10), 0))
+	require.NoError(t, err)
+	assert.Equal(t, large[len(large)-10:], data)
+}
+
+func TestS3PutMultipart(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeS3Client()
+	bs := NewS3Blobstore(client, testS3Bucket, uuid.New().String())
+
+	expected := randBytes(2*s3UploadPartSize + 100)
+	ver, err := bs.Put(ctx, key, int64(len(expected)), io.MultiReader(bytes.NewReader(expected)))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(ver, `-3"`))
+
+	data, getVer, err := GetBytes(ctx, bs, key, NewBlobRange(-100, 0))
+	require.NoError(t, err)
+	assert.Equal(t, ver, getVer)
+	assert.Equal(t, expected[len(expected)-100:], data)
+
+	ok, err := bs.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = bs.Exists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// fakeS3 is a minimal in-memory S3 server for path-style requests. It implements the object and multipart upload
+// operations used by S3Blobstore, including conditional writes, but does not check request signatures.
+type fakeS3 struct {
+	mu         sync.Mutex
+	objects    map[string]fakeS3Object
+	uploads    map[string]*fakeS3Upload
+	partCopied int
+}
+
+type fakeS3Object struct {
+	data []byte
+	etag string
+}
+
+type fakeS3Upload struct {
+	key   string
+	parts map[int64]fakeS3Object
+}
+
+func (f *fakeS3) partCopies() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.partCopied
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	// the content of an object is written once the lock is released, so that a client which streams a large object
+	// doesn't block its other requests
+	var content []byte
+	defer func() {
+		if content != nil {
+			w.Write(content)
+		}
+	}()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	_, isUploads := query["uploads"]
+	uploadID := query.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodPost && isUploads:
+		id := uuid.New().String()
+		f.uploads[id] = &fakeS3Upload{key: key, parts: map[int64]fakeS3Object{}}
+		writeS3XML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			UploadId string
+		}{UploadId: id})
+	case r.Method == http.MethodPost && uploadID != "":
+		f.completeUpload(w, key, uploadID, body)
+	case r.Method == http.MethodDelete && uploadID != "":
+		delete(f.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && uploadID != "":
+		f.uploadPart(w, r, uploadID, body)
+	case r.Method == http.MethodPut:
+		obj, exists := f.objects[key]
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			if !exists {
+				writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+				return
+			} else if ifMatch != obj.etag {
+				writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+				return
+			}
+		}
+		obj = newFakeS3Object(body)
+		f.objects[key] = obj
+		w.Header().Set("ETag", obj.etag)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, exists := f.objects[key]
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", obj.etag)
+		start, end := int64(0), int64(len(obj.data))
+		status := http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			var ok bool
+			if start, end, ok = parseFakeS3Range(rng, int64(len(obj.data))); !ok {
+				writeS3Error(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(obj.data)))
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(end-start, 10))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			content = obj.data[start:end]
+		}
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) uploadPart(w http.ResponseWriter, r *http.Request, uploadID string, body []byte) {
+	up, ok := f.uploads[uploadID]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	partNum, err := strconv.ParseInt(r.URL.Query().Get("partNumber"), 10, 64)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+
+	copySource := r.Header.Get("X-Amz-Copy-Source")
+	if copySource == "" {
+		part := newFakeS3Object(body)
+		up.parts[partNum] = part
+		w.Header().Set("ETag", part.etag)
+		return
+	}
+
+	srcPath, err := url.PathUnescape(copySource)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+	src, exists := f.objects[strings.TrimPrefix(srcPath, "/")]
+	if !exists {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	start, end := int64(0), int64(len(src.data))
+	if rng := r.Header.Get("X-Amz-Copy-Source-Range"); rng != "" {
+		if start, end, ok = parseFakeS3Range(rng, int64(len(src.data))); !ok {
+			writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+			return
+		}
+	}
+	part := newFakeS3Object(src.data[start:end])
+	up.parts[partNum] = part
+	f.partCopied++
+	writeS3XML(w, struct {
+		XMLName xml.Name `xml:"CopyPartResult"`
+		ETag    string
+	}{ETag: part.etag})
+}
+
+func (f *fakeS3) completeUpload(w http.ResponseWriter, key, uploadID string, body []byte) {
+	up, ok := f.uploads[uploadID]
+	if !ok || up.key != key {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	var req struct {
+		Parts []struct {
+			PartNumber int64
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Parts) == 0 {
+		writeS3Error(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+
+	var data []byte
+	var etags []byte
+	for i, p := range req.Parts {
+		part, ok := up.parts[p.PartNumber]
+		if !ok || part.etag != p.ETag {
+			writeS3Error(w, http.StatusBadRequest, "InvalidPart")
+			return
+		} else if i < len(req.Parts)-1 && len(part.data) < s3MinPartSize {
+			writeS3Error(w, http.StatusBadRequest, "EntityTooSmall")
+			return
+		}
+		data = append(data, part.data...)
+		etags = append(etags, part.etag...)
+	}
+	sum := md5.Sum(etags)
+	obj := fakeS3Object{data: data, etag: fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(req.Parts))}
+	f.objects[key] = obj
+	delete(f.uploads, uploadID)
+	writeS3XML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		ETag    string
+	}{ETag: obj.etag})
+}
+
+func newFakeS3Object(data []byte) fakeS3Object {
+	sum := md5.Sum(data)
+	return fakeS3Object{data: append([]byte(nil), data...), etag: `"` + hex.EncodeToString(sum[:]) + `"`}
+}
+
+// parseFakeS3Range returns the half-open interval of a single range of a Range header.
+func parseFakeS3Range(rng string, size int64) (int64, int64, bool) {
+	first, last, found := strings.Cut(strings.TrimPrefix(rng, "bytes="), "-")
+	if !found {
+		return 0, 0, false
+	}
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size, true
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	if last == "" {
+		return start, size, true
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end + 1, true
+}
+
+func writeS3XML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: code})
+}
@@ -0,0 +1,391 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dolthub/fslock"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	diskCacheSegmentExt      = ".dcache"
+	diskCacheLockFileName    = "LOCK"
+	diskCacheRecordHeaderLen = hash.ByteLen + 4
+
+	// diskCacheSegmentCount is the number of segments a full cache is split into. Eviction removes the least
+	// recently used segment, so it frees about 1/diskCacheSegmentCount of the cache at a time.
+	diskCacheSegmentCount   = 16
+	minDiskCacheSegmentSize = 64 * 1024
+)
+
+// ErrDiskCacheLocked is returned by NewDiskCache when another process is using the cache directory.
+var ErrDiskCacheLocked = errors.New("disk cache directory is in use by another process")
+
+// DiskCache is a size bounded cache of chunks on local disk. It is used as a read-through cache in front of the
+// table files of stores on object storage, see WithDiskCache, so that reading a chunk again does not need a network
+// round trip. A DiskCache can be shared by several stores, and its contents survive restarts.
+//
+// Chunks are appended to segment files in their compressed table file format, and each chunk read from the cache is
+// checked against its checksum and its hash. A chunk which fails these checks is dropped from the cache and read
+// from the store instead. When the cache grows past its maximum size, the least recently used segments are deleted.
+type DiskCache struct {
+	dir         string
+	maxSize     uint64
+	segmentSize uint64
+	lock        *fslock.Lock
+
+	mu      sync.Mutex
+	entries map[hash.Hash]diskCacheEntry
+	// lru orders the segments of the cache, most recently used first
+	lru    *list.List
+	active *diskCacheSegment
+	nextID uint64
+	size   uint64
+
+	hits              atomic.Uint64
+	misses            atomic.Uint64
+	inserts           atomic.Uint64
+	evictions         atomic.Uint64
+	integrityFailures atomic.Uint64
+}
+
+type diskCacheSegment struct {
+	id    uint64
+	f     *os.File
+	size  uint64
+	addrs []hash.Hash
+	elem  *list.Element
+}
+
+type diskCacheEntry struct {
+	seg    *diskCacheSegment
+	offset uint64
+	length uint32
+}
+
+// DiskCacheStats are counters describing the use of a DiskCache.
+type DiskCacheStats struct {
+	Hits              uint64
+	Misses            uint64
+	Inserts           uint64
+	Evictions         uint64
+	IntegrityFailures uint64
+	Chunks            int
+	Size              uint64
+	MaxSize           uint64
+}
+
+// NewDiskCache opens the disk cache in |dir|, creating the directory if needed. The cache holds at most |maxSize|
+// bytes of chunks. Chunks cached by an earlier process are kept, unless they exceed |maxSize|.
+func NewDiskCache(dir string, maxSize uint64) (*DiskCache, error) {
+	if maxSize == 0 {
+		return nil, errors.New("disk cache size must be greater than 0")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	lock := fslock.New(filepath.Join(dir, diskCacheLockFileName))
+	err := lock.LockWithTimeout(lockFileTimeout)
+	if errors.Is(err, fslock.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrDiskCacheLocked, dir)
+	} else if err != nil {
+		return nil, err
+	}
+
+	segmentSize := maxSize / diskCacheSegmentCount
+	if segmentSize < minDiskCacheSegmentSize {
+		segmentSize = minDiskCacheSegmentSize
+		if segmentSize > maxSize {
+			segmentSize = maxSize
+		}
+	}
+
+	c := &DiskCache{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: segmentSize,
+		lock:        lock,
+		entries:     make(map[hash.Hash]diskCacheEntry),
+		lru:         list.New(),
+	}
+	if err = c.load(); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// load reads the segments left in the cache directory by an earlier process.
+func (c *DiskCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	var ids []uint64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, diskCacheSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, diskCacheSegmentExt), 16, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// segments are loaded oldest first, so the newest segment ends up at the front of |c.lru|
+	for _, id := range ids {
+		seg, err := c.loadSegment(id)
+		if err != nil {
+			return err
+		}
+		seg.elem = c.lru.PushFront(seg)
+		c.size += seg.size
+		c.nextID = id + 1
+	}
+	return nil
+}
+
+func (c *DiskCache) loadSegment(id uint64) (*diskCacheSegment, error) {
+	f, err := os.OpenFile(c.segmentPath(id), os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &diskCacheSegment{id: id, f: f}
+
+	rd := bufio.NewReader(f)
+	var header [diskCacheRecordHeaderLen]byte
+	for {
+		if _, err = io.ReadFull(rd, header[:]); err != nil {
+			break
+		}
+		h := hash.New(header[:hash.ByteLen])
+		length := binary.BigEndian.Uint32(header[hash.ByteLen:])
+		if _, err = rd.Discard(int(length)); err != nil {
+			break
+		}
+		if _, ok := c.entries[h]; !ok {
+			c.entries[h] = diskCacheEntry{seg: seg, offset: seg.size + diskCacheRecordHeaderLen, length: length}
+			seg.addrs = append(seg.addrs, h)
+		}
+		seg.size += diskCacheRecordHeaderLen + uint64(length)
+	}
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		_ = f.Close()
+		return nil, err
+	}
+	// drop a partially written record left by a crash
+	if err = f.Truncate(int64(seg.size)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return seg, nil
+}
+
+func (c *DiskCache) segmentPath(id uint64) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%016x%s", id, diskCacheSegmentExt))
+}
+
+// get returns the chunk |h| if it is in the cache, checking its integrity.
+func (c *DiskCache) get(h hash.Hash) (CompressedChunk, []byte, bool) {
+	c.mu.Lock()
+	e, ok := c.entries[h]
+	if ok {
+		c.lru.MoveToFront(e.seg.elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		c.misses.Add(1)
+		return CompressedChunk{}, nil, false
+	}
+
+	buf := make([]byte, e.length)
+	if _, err := e.seg.f.ReadAt(buf, int64(e.offset)); err != nil {
+		// the segment may have been evicted since we looked it up
+		c.misses.Add(1)
+		return CompressedChunk{}, nil, false
+	}
+	cc, data, err := verifyCachedChunk(h, buf)
+	if err != nil {
+		c.integrityFailures.Add(1)
+		c.misses.Add(1)
+		c.remove(h, e.seg)
+		return CompressedChunk{}, nil, false
+	}
+	c.hits.Add(1)
+	return cc, data, true
+}
+
+// verifyCachedChunk decodes the compressed chunk |buf| and checks that it is the chunk |h|.
+func verifyCachedChunk(h hash.Hash, buf []byte) (CompressedChunk, []byte, error) {
+	if len(buf) < checksumSize {
+		return CompressedChunk{}, nil, errors.New("cached chunk is truncated")
+	}
+	cc, err := NewCompressedChunk(h, buf)
+	if err != nil {
+		return CompressedChunk{}, nil, err
+	}
+	ch, err := cc.ToChunk()
+	if err != nil {
+		return CompressedChunk{}, nil, err
+	}
+	if hash.Of(ch.Data()) != h {
+		return CompressedChunk{}, nil, fmt.Errorf("cached chunk does not match its hash %s", h.String())
+	}
+	return cc, ch.Data(), nil
+}
+
+// put adds the compressed chunk |cc| to the cache. Errors writing the cache are ignored, as the chunk can always be
+// read from the store again.
+func (c *DiskCache) put(cc CompressedChunk) {
+	recordLen := uint64(diskCacheRecordHeaderLen + len(cc.FullCompressedChunk))
+	if recordLen > c.segmentSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[cc.H]; ok {
+		return
+	}
+	if c.active == nil || c.active.size+recordLen > c.segmentSize {
+		if err := c.newActiveSegment(); err != nil {
+			return
+		}
+	}
+
+	record := make([]byte, recordLen)
+	copy(record, cc.H[:])
+	binary.BigEndian.PutUint32(record[hash.ByteLen:], uint32(len(cc.FullCompressedChunk)))
+	copy(record[diskCacheRecordHeaderLen:], cc.FullCompressedChunk)
+	if _, err := c.active.f.WriteAt(record, int64(c.active.size)); err != nil {
+		// start a new segment for the next chunk, rather than leaving a gap in this one
+		c.active = nil
+		return
+	}
+
+	c.entries[cc.H] = diskCacheEntry{
+		seg:    c.active,
+		offset: c.active.size + diskCacheRecordHeaderLen,
+		length: uint32(len(cc.FullCompressedChunk)),
+	}
+	c.active.addrs = append(c.active.addrs, cc.H)
+	c.active.size += recordLen
+	c.size += recordLen
+	c.lru.MoveToFront(c.active.elem)
+	c.inserts.Add(1)
+	c.evict()
+}
+
+func (c *DiskCache) newActiveSegment() error {
+	id := c.nextID
+	f, err := os.OpenFile(c.segmentPath(id), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	c.nextID++
+	c.active = &diskCacheSegment{id: id, f: f}
+	c.active.elem = c.lru.PushFront(c.active)
+	return nil
+}
+
+// evict deletes the least recently used segments until the cache fits in its maximum size. Callers must hold |c.mu|.
+func (c *DiskCache) evict() {
+	for c.size > c.maxSize {
+		back := c.lru.Back()
+		if back == nil {
+			return
+		}
+		seg := back.Value.(*diskCacheSegment)
+		c.lru.Remove(back)
+		if seg == c.active {
+			c.active = nil
+		}
+		for _, h := range seg.addrs {
+			if e, ok := c.entries[h]; ok && e.seg == seg {
+				delete(c.entries, h)
+				c.evictions.Add(1)
+			}
+		}
+		c.size -= seg.size
+		_ = seg.f.Close()
+		_ = os.Remove(c.segmentPath(seg.id))
+	}
+}
+
+// remove drops the chunk |h| of |seg| from the cache. Its bytes are reclaimed when |seg| is evicted.
+func (c *DiskCache) remove(h hash.Hash, seg *diskCacheSegment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[h]; ok && e.seg == seg {
+		delete(c.entries, h)
+	}
+}
+
+// Stats returns the counters of the cache.
+func (c *DiskCache) Stats() DiskCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return DiskCacheStats{
+		Hits:              c.hits.Load(),
+		Misses:            c.misses.Load(),
+		Inserts:           c.inserts.Load(),
+		Evictions:         c.evictions.Load(),
+		IntegrityFailures: c.integrityFailures.Load(),
+		Chunks:            len(c.entries),
+		Size:              c.size,
+		MaxSize:           c.maxSize,
+	}
+}
+
+// Close closes the files of the cache and releases its directory. The cache must not be used afterwards.
+func (c *DiskCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		if cerr := e.Value.(*diskCacheSegment).f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	c.lru.Init()
+	c.entries = make(map[hash.Hash]diskCacheEntry)
+	c.active = nil
+	if uerr := c.lock.Unlock(); err == nil {
+		err = uerr
+	}
+	return err
+}
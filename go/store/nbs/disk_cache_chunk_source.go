@@ -0,0 +1,168 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// StoreOption configures the stores returned by the constructors for stores on object storage, such as NewBSStore
+// and NewAWSStore.
+type StoreOption func(*storeOptions)
+
+type storeOptions struct {
+	diskCache *DiskCache
+}
+
+// WithDiskCache reads the table files of a store through |cache|.
+func WithDiskCache(cache *DiskCache) StoreOption {
+	return func(opts *storeOptions) {
+		opts.diskCache = cache
+	}
+}
+
+// applyStoreOptions returns |p| configured with |opts|.
+func applyStoreOptions(p tableFilePersister, opts []StoreOption) tablePersister {
+	var options storeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.diskCache != nil {
+		return &diskCachePersister{p, options.diskCache}
+	}
+	return p
+}
+
+// diskCachePersister is a tablePersister whose chunk sources read chunks through a DiskCache.
+type diskCachePersister struct {
+	tableFilePersister
+	cache *DiskCache
+}
+
+var _ tableFilePersister = &diskCachePersister{}
+
+func (p *diskCachePersister) Persist(ctx context.Context, mt *memTable, haver chunkReader, stats *Stats) (chunkSource, error) {
+	cs, err := p.tableFilePersister.Persist(ctx, mt, haver, stats)
+	if err != nil {
+		return nil, err
+	}
+	return p.wrap(cs), nil
+}
+
+func (p *diskCachePersister) ConjoinAll(ctx context.Context, sources chunkSources, stats *Stats) (chunkSource, cleanupFunc, error) {
+	cs, cleanup, err := p.tableFilePersister.ConjoinAll(ctx, sources, stats)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.wrap(cs), cleanup, nil
+}
+
+func (p *diskCachePersister) Open(ctx context.Context, name hash.Hash, chunkCount uint32, stats *Stats) (chunkSource, error) {
+	cs, err := p.tableFilePersister.Open(ctx, name, chunkCount, stats)
+	if err != nil {
+		return nil, err
+	}
+	return p.wrap(cs), nil
+}
+
+func (p *diskCachePersister) wrap(cs chunkSource) chunkSource {
+	if _, ok := cs.(emptyChunkSource); ok {
+		return cs
+	}
+	return diskCachedChunkSource{cs, p.cache}
+}
+
+// diskCachedChunkSource is a chunkSource which serves the chunks it has from a DiskCache when they are cached, and
+// adds the chunks it reads from the underlying chunkSource to the cache.
+type diskCachedChunkSource struct {
+	chunkSource
+	cache *DiskCache
+}
+
+func (s diskCachedChunkSource) get(ctx context.Context, h hash.Hash, stats *Stats) ([]byte, error) {
+	if data, ok := s.getCached(h); ok {
+		return data, nil
+	}
+	data, err := s.chunkSource.get(ctx, h, stats)
+	if err != nil || data == nil {
+		return data, err
+	}
+	s.cache.put(ChunkToCompressedChunk(chunks.NewChunkWithHash(h, data)))
+	return data, nil
+}
+
+func (s diskCachedChunkSource) getMany(ctx context.Context, eg *errgroup.Group, reqs []getRecord, found func(context.Context, *chunks.Chunk), stats *Stats) (bool, error) {
+	for i := range reqs {
+		if reqs[i].found {
+			continue
+		}
+		if data, ok := s.getCached(*reqs[i].a); ok {
+			reqs[i].found = true
+			ch := chunks.NewChunkWithHash(*reqs[i].a, data)
+			found(ctx, &ch)
+		}
+	}
+	return s.chunkSource.getMany(ctx, eg, reqs, func(ctx context.Context, ch *chunks.Chunk) {
+		s.cache.put(ChunkToCompressedChunk(*ch))
+		found(ctx, ch)
+	}, stats)
+}
+
+func (s diskCachedChunkSource) getManyCompressed(ctx context.Context, eg *errgroup.Group, reqs []getRecord, found func(context.Context, CompressedChunk), stats *Stats) (bool, error) {
+	for i := range reqs {
+		if reqs[i].found {
+			continue
+		}
+		if cc, ok := s.getCachedCompressed(*reqs[i].a); ok {
+			reqs[i].found = true
+			found(ctx, cc)
+		}
+	}
+	return s.chunkSource.getManyCompressed(ctx, eg, reqs, func(ctx context.Context, cc CompressedChunk) {
+		s.cache.put(cc)
+		found(ctx, cc)
+	}, stats)
+}
+
+// getCached returns the chunk |h| from the cache if it is cached and this chunk source has it. Chunks are cached
+// by hash, so the cache may hold chunks of other chunk sources.
+func (s diskCachedChunkSource) getCached(h hash.Hash) ([]byte, bool) {
+	if ok, err := s.chunkSource.has(h); err != nil || !ok {
+		return nil, false
+	}
+	_, data, ok := s.cache.get(h)
+	return data, ok
+}
+
+func (s diskCachedChunkSource) getCachedCompressed(h hash.Hash) (CompressedChunk, bool) {
+	if ok, err := s.chunkSource.has(h); err != nil || !ok {
+		return CompressedChunk{}, false
+	}
+	cc, _, ok := s.cache.get(h)
+	return cc, ok
+}
+
+func (s diskCachedChunkSource) clone() (chunkSource, error) {
+	cs, err := s.chunkSource.clone()
+	if err != nil {
+		return nil, err
+	}
+	return diskCachedChunkSource{cs, s.cache}, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestDiskCacheGetPut(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch := chunks.NewChunk([]byte("hello disk cache"))
+	_, _, ok := c.get(ch.Hash())
+	assert.False(t, ok)
+
+	c.put(ChunkToCompressedChunk(ch))
+	cc, data, ok := c.get(ch.Hash())
+	require.True(t, ok)
+	assert.Equal(t, ch.Data(), data)
+	assert.Equal(t, ch.Hash(), cc.H)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Inserts)
+	assert.Equal(t, 1, stats.Chunks)
+	assert.Equal(t, uint64(1<<20), stats.MaxSize)
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	// with a minimum sized segment, each segment holds about 64 of these chunks
+	maxSize := uint64(4 * minDiskCacheSegmentSize)
+	c, err := NewDiskCache(t.TempDir(), maxSize)
+	require.NoError(t, err)
+	defer c.Close()
+
+	first := chunks.NewChunk(randBuf(1000))
+	c.put(ChunkToCompressedChunk(first))
+	var cached []chunks.Chunk
+	for i := 0; i < 1000; i++ {
+		ch := chunks.NewChunk(randBuf(1000))
+		c.put(ChunkToCompressedChunk(ch))
+		cached = append(cached, ch)
+		// keep the segment of |first| in use
+		_, _, ok := c.get(first.Hash())
+		require.True(t, ok)
+	}
+
+	stats := c.Stats()
+	assert.LessOrEqual(t, stats.Size, maxSize)
+	assert.Greater(t, stats.Evictions, uint64(0))
+	assert.Equal(t, 1001, stats.Chunks+int(stats.Evictions))
+
+	_, _, ok := c.get(cached[0].Hash())
+	assert.True(t, ok, "chunks in the segment of |first| are kept")
+	_, _, ok = c.get(cached[200].Hash())
+	assert.False(t, ok, "least recently used chunks are evicted")
+	_, _, ok = c.get(cached[len(cached)-1].Hash())
+	assert.True(t, ok)
+
+	segments, err := filepath.Glob(filepath.Join(c.dir, "*"+diskCacheSegmentExt))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(segments), diskCacheSegmentCount)
+}
+
+func TestDiskCacheIntegrity(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch := chunks.NewChunk([]byte("some data which will be corrupted"))
+	c.put(ChunkToCompressedChunk(ch))
+	e := c.entries[ch.Hash()]
+	_, err = e.seg.f.WriteAt([]byte{0xff, 0xff, 0xff}, int64(e.offset))
+	require.NoError(t, err)
+
+	_, _, ok := c.get(ch.Hash())
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), c.Stats().IntegrityFailures)
+	assert.Equal(t, 0, c.Stats().Chunks)
+
+	// a chunk stored under the wrong hash is rejected, even though its checksum is valid
+	other := chunks.NewChunk([]byte("other data"))
+	cc := ChunkToCompressedChunk(other)
+	cc.H = ch.Hash()
+	c.put(cc)
+	_, _, ok = c.get(ch.Hash())
+	assert.False(t, ok)
+	assert.Equal(t, uint64(2), c.Stats().IntegrityFailures)
+}
+
+func TestDiskCacheReopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, 1<<20)
+	require.NoError(t, err)
+
+	_, err = NewDiskCache(dir, 1<<20)
+	assert.ErrorIs(t, err, ErrDiskCacheLocked)
+
+	var chs []chunks.Chunk
+	for i := 0; i < 10; i++ {
+		ch := chunks.NewChunk(randBuf(100))
+		c.put(ChunkToCompressedChunk(ch))
+		chs = append(chs, ch)
+	}
+	seg := c.active
+	require.NoError(t, c.Close())
+
+	// simulate a crash while writing a record
+	f, err := os.OpenFile(c.segmentPath(seg.id), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("partial record"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c, err = NewDiskCache(dir, 1<<20)
+	require.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, 10, c.Stats().Chunks)
+	for _, ch := range chs {
+		_, data, ok := c.get(ch.Hash())
+		require.True(t, ok)
+		assert.Equal(t, ch.Data(), data)
+	}
+
+	ch := chunks.NewChunk(randBuf(100))
+	c.put(ChunkToCompressedChunk(ch))
+	_, data, ok := c.get(ch.Hash())
+	require.True(t, ok)
+	assert.Equal(t, ch.Data(), data)
+}
+
+// countingBlobstore counts the reads of table files from a Blobstore.
+type countingBlobstore struct {
+	blobstore.Blobstore
+	tableReads atomic.Int64
+}
+
+func (bs *countingBlobstore) Get(ctx context.Context, key string, br blobstore.BlobRange) (io.ReadCloser, string, error) {
+	if key != manifestFile {
+		bs.tableReads.Add(1)
+	}
+	return bs.Blobstore.Get(ctx, key, br)
+}
+
+func TestBSStoreWithDiskCache(t *testing.T) {
+	ctx := context.Background()
+	bs := &countingBlobstore{Blobstore: blobstore.NewInMemoryBlobstore("")}
+	cache, err := NewDiskCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	store, err := NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, NewUnlimitedMemQuotaProvider(), WithDiskCache(cache))
+	require.NoError(t, err)
+	var addrs hash.HashSet = hash.NewHashSet()
+	var written []chunks.Chunk
+	for i := 0; i < 100; i++ {
+		ch := chunks.NewChunk(randBuf(100))
+		require.NoError(t, store.Put(ctx, ch, noopGetAddrs))
+		addrs.Insert(ch.Hash())
+		written = append(written, ch)
+	}
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	ok, err := store.Commit(ctx, written[0].Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, store.Close())
+
+	readAll := func(store *NomsBlockStore) {
+		for _, ch := range written[:10] {
+			got, err := store.Get(ctx, ch.Hash())
+			require.NoError(t, err)
+			assert.Equal(t, ch.Data(), got.Data())
+		}
+		found := 0
+		err = store.GetMany(ctx, addrs, func(ctx context.Context, ch *chunks.Chunk) {
+			found++
+		})
+		require.NoError(t, err)
+		assert.Equal(t, len(written), found)
+		found = 0
+		err = store.GetManyCompressed(ctx, addrs, func(ctx context.Context, cc CompressedChunk) {
+			_, err := cc.ToChunk()
+			assert.NoError(t, err)
+			found++
+		})
+		require.NoError(t, err)
+		assert.Equal(t, len(written), found)
+	}
+
+	store, err = NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, NewUnlimitedMemQuotaProvider(), WithDiskCache(cache))
+	require.NoError(t, err)
+	readAll(store)
+	require.NoError(t, store.Close())
+	assert.Greater(t, bs.tableReads.Load(), int64(0))
+	assert.Equal(t, uint64(len(written)), cache.Stats().Inserts)
+
+	// a new store reads the table file index, but all of its chunks come from the cache
+	bs.tableReads.Store(0)
+	store, err = NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, NewUnlimitedMemQuotaProvider(), WithDiskCache(cache))
+	require.NoError(t, err)
+	defer store.Close()
+	indexReads := bs.tableReads.Load()
+	readAll(store)
+	assert.Equal(t, indexReads, bs.tableReads.Load())
+	assert.Greater(t, cache.Stats().Hits, uint64(0))
+}
@@ -167,7 +167,7 @@ func (s journalChunkSource) reader(context.Context) (io.ReadCloser, uint64, erro
 
 func (s journalChunkSource) getRecordRanges(requests []getRecord) (map[hash.Hash]Range, error) {
 	ranges := make(map[hash.Hash]Range, len(requests))
-	for _, req := range requests {
+	for i, req := range requests {
 		if req.found {
 			continue
 		}
@@ -177,7 +177,7 @@ func (s journalChunkSource) getRecordRanges(requests []getRecord) (map[hash.Hash
 		} else if !ok {
 			continue
 		}
-		req.found = true // update |requests|
+		requests[i].found = true // update |requests|
 		ranges[hash.Hash(*req.a)] = rng
 	}
 	return ranges, nil
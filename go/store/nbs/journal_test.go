@@ -110,6 +110,15 @@ func TestReadRecordRanges(t *testing.T) {
 
 	ranges, err := jcs.getRecordRanges(gets)
 	require.NoError(t, err)
+	assert.Len(t, ranges, len(data))
+	for _, g := range gets {
+		assert.True(t, g.found)
+	}
+
+	// chunks found by one source are not returned again, as for a chunk in both the journal and a table file
+	again, err := jcs.getRecordRanges(gets)
+	require.NoError(t, err)
+	assert.Empty(t, again)
 
 	for h, rng := range ranges {
 		b, err := jcs.get(ctx, h, &Stats{})
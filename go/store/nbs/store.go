@@ -516,7 +516,7 @@ func NewAWSStoreWithMMapIndex(ctx context.Context, nbfVerStr string, table, ns,
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, q, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
-func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3iface.S3API, ddb ddbsvc, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3iface.S3API, ddb ddbsvc, memTableSize uint64, q MemoryQuotaProvider, opts ...StoreOption) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	readRateLimiter := make(chan struct{}, 32)
 	p := &awsTablePersister{
@@ -528,19 +528,19 @@ func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string
 		q,
 	}
 	mm := makeManifestManager(newDynamoManifest(table, ns, ddb))
-	return newNomsBlockStore(ctx, nbfVerStr, mm, p, q, inlineConjoiner{defaultMaxTables}, memTableSize)
+	return newNomsBlockStore(ctx, nbfVerStr, mm, applyStoreOptions(p, opts), q, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
 // NewGCSStore returns an nbs implementation backed by a GCSBlobstore
-func NewGCSStore(ctx context.Context, nbfVerStr string, bucketName, path string, gcs *storage.Client, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+func NewGCSStore(ctx context.Context, nbfVerStr string, bucketName, path string, gcs *storage.Client, memTableSize uint64, q MemoryQuotaProvider, opts ...StoreOption) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
 	bs := blobstore.NewGCSBlobstore(gcs, bucketName, path)
-	return NewBSStore(ctx, nbfVerStr, bs, memTableSize, q)
+	return NewBSStore(ctx, nbfVerStr, bs, memTableSize, q, opts...)
 }
 
 // NewGCSStore returns an nbs implementation backed by a GCSBlobstore
-func NewOCISStore(ctx context.Context, nbfVerStr string, bucketName, path string, provider common.ConfigurationProvider, client objectstorage.ObjectStorageClient, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+func NewOCISStore(ctx context.Context, nbfVerStr string, bucketName, path string, provider common.ConfigurationProvider, client objectstorage.ObjectStorageClient, memTableSize uint64, q MemoryQuotaProvider, opts ...StoreOption) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
 	bs, err := blobstore.NewOCIBlobstore(ctx, provider, client, bucketName, path)
@@ -548,27 +548,27 @@ func NewOCISStore(ctx context.Context, nbfVerStr string, bucketName, path string
 		return nil, err
 	}
 
-	return NewNoConjoinBSStore(ctx, nbfVerStr, bs, memTableSize, q)
+	return NewNoConjoinBSStore(ctx, nbfVerStr, bs, memTableSize, q, opts...)
 }
 
 // NewBSStore returns an nbs implementation backed by a Blobstore
-func NewBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+func NewBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, q MemoryQuotaProvider, opts ...StoreOption) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
 	mm := makeManifestManager(blobstoreManifest{bs})
 
 	p := &blobstorePersister{bs, s3BlockSize, q}
-	return newNomsBlockStore(ctx, nbfVerStr, mm, p, q, inlineConjoiner{defaultMaxTables}, memTableSize)
+	return newNomsBlockStore(ctx, nbfVerStr, mm, applyStoreOptions(p, opts), q, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
 // NewNoConjoinBSStore returns a nbs implementation backed by a Blobstore
-func NewNoConjoinBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+func NewNoConjoinBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, q MemoryQuotaProvider, opts ...StoreOption) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
 	mm := makeManifestManager(blobstoreManifest{bs})
 
 	p := &noConjoinBlobstorePersister{bs, s3BlockSize, q}
-	return newNomsBlockStore(ctx, nbfVerStr, mm, p, q, noopConjoiner{}, memTableSize)
+	return newNomsBlockStore(ctx, nbfVerStr, mm, applyStoreOptions(p, opts), q, noopConjoiner{}, memTableSize)
 }
 
 func NewLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
@@ -114,6 +114,88 @@ setup_test_user() {
     [[ $output =~ "does not have the correct permissions" ]] || false
 }
 
+@test "branch-control: test basic branch read permissions" {
+    setup_test_user
+
+    dolt sql -q "create user test2"
+    dolt sql -q "grant all on *.* to test2"
+
+    dolt sql -q "insert into dolt_branch_control values ('dolt-repo-$$', 'release%', 'test', '%', 'read')"
+    dolt branch release1
+
+    start_sql_server
+
+    # Reading requires the read permission once a branch is read restricted
+    dolt -u test sql -q "select * from \`dolt-repo-$$/release1\`.dolt_log"
+
+    run dolt -u test2 sql -q "select * from \`dolt-repo-$$/release1\`.dolt_log"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read from the branch" ]] || false
+
+    run dolt -u test2 sql -q "use \`dolt-repo-$$/release1\`"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read from the branch" ]] || false
+
+    # Branches that are not read restricted may still be read by anyone
+    dolt -u test2 sql -q "select * from dolt_log"
+
+    # Reading does not allow writing
+    run dolt -u test sql -q "call dolt_checkout('release1'); create table t (c1 int)"
+    [ $status -ne 0 ]
+    [[ $output =~ "does not have the correct permissions" ]] || false
+}
+
+@test "branch-control: read restricted commits can't be read through their hash" {
+    dolt sql -q "create table t (pk int primary key)"
+    dolt add .
+    dolt commit -m "create table"
+    dolt checkout -b release1
+    dolt sql -q "insert into t values (1)"
+    dolt commit -am "embargoed commit"
+    embargoed=$(get_head_commit)
+    dolt checkout main
+
+    setup_test_user
+
+    dolt sql -q "create user test2"
+    dolt sql -q "grant all on *.* to test2"
+    dolt sql -q "insert into dolt_branch_control values ('dolt-repo-$$', 'release%', 'test', '%', 'read')"
+
+    start_sql_server
+
+    run dolt -u test2 sql -q "select * from t as of '$embargoed'"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read the commit" ]] || false
+
+    run dolt -u test2 sql -q "select * from dolt_diff('main', '$embargoed', 't')"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read the commit" ]] || false
+
+    run dolt -u test2 sql -q "select * from dolt_log('$embargoed')"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read the commit" ]] || false
+
+    run dolt -u test2 sql -q "select * from \`dolt-repo-$$/$embargoed\`.t"
+    [ $status -ne 0 ]
+    [[ $output =~ "cannot read the commit" ]] || false
+
+    # Restricted branches and their commits are left out of the branch and commit tables
+    run dolt -u test2 sql -r csv -q "select name from dolt_branches"
+    [ $status -eq 0 ]
+    [[ ! $output =~ "release1" ]] || false
+    run dolt -u test2 sql -r csv -q "select count(*) from dolt_commits where commit_hash = '$embargoed'"
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "0" ]
+    run dolt -u test2 sql -r csv -q "select count(*) from dolt_commits where message = 'embargoed commit'"
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "0" ]
+
+    # Commits of restricted branches can be read once they're reachable from a readable ref
+    dolt -u test sql -q "select * from t as of '$embargoed'"
+    dolt tag v1 release1
+    dolt -u test2 sql -q "select * from t as of '$embargoed'"
+}
+
 @test "branch-control: test admin permissions" {
     setup_test_user
 
@@ -916,3 +916,51 @@ DELIM
     [ "$status" -eq 1 ]
     [[ "$output" =~ "parameters all-text and schema are mutually exclusive" ]] || false
 }
+
+@test "import-create-tables: infer decimals, enums, dates and the primary key" {
+    cat <<CSV > orders.csv
+id,price,status,day
+1,10.50,open,31/12/2020
+2,-3.25,closed,01/01/2021
+3,1000,open,15/06/2021
+4,0.125,open,
+5,7,closed,02/02/2022
+CSV
+    run dolt table import -c --pk auto --infer-decimal --infer-enum --date-format '%d/%m/%Y' orders orders.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Import completed successfully." ]] || false
+
+    run dolt schema show orders
+    [[ "$output" =~ "\`price\` decimal(7,3)" ]] || false
+    [[ "$output" =~ "\`status\` enum('closed','open')" ]] || false
+    [[ "$output" =~ "\`day\` date" ]] || false
+    [[ "$output" =~ 'PRIMARY KEY (`id`)' ]] || false
+
+    run dolt sql -r csv -q "select * from orders order by id"
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,10.500,open,2020-12-31" ]
+    [ "${lines[4]}" = "4,0.125,open," ]
+
+    run dolt table import -u --infer-enum orders orders.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "only supported for create operations" ]] || false
+
+    run dolt table import -c --sample-rows 0 orders2 orders.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "must be a positive number of rows" ]] || false
+}
+
+@test "import-create-tables: --date-format converts dates when updating a table" {
+    dolt sql -q "create table events (id int primary key, at datetime)"
+    cat <<CSV > events.csv
+id,at
+1,12/31/2020 13:45
+2,2021-01-01 00:00:00
+CSV
+    run dolt table import -u --date-format '%m/%d/%Y %H:%i' events events.csv
+    [ "$status" -eq 0 ]
+
+    run dolt sql -r csv -q "select * from events order by id"
+    [ "${lines[1]}" = "1,2020-12-31 13:45:00" ]
+    [ "${lines[2]}" = "2,2021-01-01 00:00:00" ]
+}
@@ -0,0 +1,189 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+load $BATS_TEST_DIRNAME/helper/query-server-common.bash
+
+setup() {
+    setup_common
+
+    mkdir srcdb
+    cd srcdb
+    dolt init
+    dolt sql <<SQL
+CREATE TABLE parent (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(20) NOT NULL DEFAULT 'x''y',
+  flags bit(4) DEFAULT b'101',
+  kind enum('a','b') DEFAULT 'a',
+  bin varbinary(8),
+  PRIMARY KEY (id),
+  UNIQUE KEY uname (name),
+  KEY kidx (kind, name(5)),
+  CONSTRAINT chk CHECK (id < 100)
+) COLLATE=utf8mb4_bin;
+CREATE TABLE child (
+  pid int unsigned NOT NULL,
+  seq int NOT NULL,
+  note text,
+  PRIMARY KEY (pid, seq),
+  CONSTRAINT fk_parent FOREIGN KEY (pid) REFERENCES parent (id) ON DELETE CASCADE
+);
+CREATE TABLE nopk (a int, b varchar(3));
+INSERT INTO parent (name, flags, kind, bin) VALUES ('one', b'11', 'b', 0x0102), ('two', b'0', 'a', NULL);
+INSERT INTO child VALUES (2, 1, NULL), (1, 2, ''), (1, 1, 'hello');
+INSERT INTO nopk VALUES (1, 'a'), (1, 'a'), (NULL, NULL);
+SQL
+    start_sql_server srcdb
+    cd ..
+    DSN="dolt@tcp(127.0.0.1:$PORT)/srcdb"
+}
+
+teardown() {
+    stop_sql_server
+    assert_feature_version
+    teardown_common
+}
+
+@test "import-mysql: import-db imports every table" {
+    run dolt import-db "$DSN"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Imported 3 tables" ]] || false
+
+    run dolt sql -r csv -q "select id, name, hex(flags), kind, hex(bin) from parent order by id"
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,one,3,b,0102" ]
+    [ "${lines[2]}" = "2,two,0,a," ]
+
+    run dolt sql -r csv -q "select * from child order by pid, seq"
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,1,hello" ]
+    [ "${lines[2]}" = '1,2,""' ]
+    [ "${lines[3]}" = "2,1," ]
+
+    run dolt sql -r csv -q "select count(*) from nopk"
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "3" ]
+
+    run dolt schema show parent
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "AUTO_INCREMENT" ]] || false
+    [[ "$output" =~ "UNIQUE KEY \`uname\` (\`name\`)" ]] || false
+    [[ "$output" =~ "KEY \`kidx\` (\`kind\`,\`name\`(5))" ]] || false
+    [[ "$output" =~ "CONSTRAINT \`chk\` CHECK" ]] || false
+    [[ "$output" =~ "utf8mb4_bin" ]] || false
+
+    run dolt schema show child
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONSTRAINT \`fk_parent\` FOREIGN KEY (\`pid\`) REFERENCES \`parent\` (\`id\`) ON DELETE CASCADE" ]] || false
+
+    # nothing is committed by default
+    run dolt status
+    [[ "$output" =~ "new table:        parent" ]] || false
+}
+
+@test "import-mysql: import-db commits each table" {
+    run dolt import-db --commit-per-table "$DSN"
+    [ "$status" -eq 0 ]
+
+    run dolt log --oneline
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Import table child from dolt@tcp" ]] || false
+    [[ "$output" =~ "Import table nopk from dolt@tcp" ]] || false
+    [[ "$output" =~ "Import table parent from dolt@tcp" ]] || false
+    [[ "$output" =~ "Add foreign keys of the tables imported from" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "import-mysql: import-db --commit-per-table refuses to commit other staged changes" {
+    dolt sql -q "CREATE TABLE staged (id int PRIMARY KEY)"
+    dolt add staged
+
+    run dolt import-db --commit-per-table "$DSN"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "there are staged changes which --commit-per-table would commit along with the imported tables" ]] || false
+
+    run dolt log --oneline
+    [[ ! "$output" =~ "Import table" ]] || false
+    run dolt status
+    [[ "$output" =~ "new table:        staged" ]] || false
+}
+
+@test "import-mysql: import-db imports the tables named" {
+    run dolt import-db "$DSN" nopk
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Imported 1 tables" ]] || false
+
+    run dolt ls
+    [[ "$output" =~ "nopk" ]] || false
+    [[ ! "$output" =~ "parent" ]] || false
+
+    run dolt import-db "$DSN" missing
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "table 'missing' not found" ]] || false
+}
+
+@test "import-mysql: import-db does not overwrite tables without --force" {
+    dolt sql -q "create table nopk (x int)"
+
+    run dolt import-db "$DSN" nopk
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "table nopk already exists. Use -f to overwrite." ]] || false
+
+    run dolt import-db -f "$DSN" nopk
+    [ "$status" -eq 0 ]
+    run dolt sql -r csv -q "select count(*) from nopk"
+    [ "${lines[1]}" = "3" ]
+}
+
+@test "import-mysql: import-db requires a database" {
+    run dolt import-db "dolt@tcp(127.0.0.1:$PORT)/"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "the dsn must name the database to import" ]] || false
+}
+
+@test "import-mysql: table import --from-mysql creates and updates a table" {
+    run dolt table import -c --from-mysql "$DSN" parent
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Import completed successfully." ]] || false
+
+    run dolt sql -r csv -q "select id, name from parent order by id"
+    [ "${lines[1]}" = "1,one" ]
+    [ "${lines[2]}" = "2,two" ]
+
+    dolt sql -q "delete from parent where id = 2"
+    run dolt table import -u --from-mysql "$DSN" parent
+    [ "$status" -eq 0 ]
+    run dolt sql -r csv -q "select count(*) from parent"
+    [ "${lines[1]}" = "2" ]
+}
+
+@test "import-mysql: table import --from-mysql rejects file options" {
+    run dolt table import -c --from-mysql "$DSN" -s schema.sql parent
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "parameters from-mysql and schema are mutually exclusive" ]] || false
+
+    run dolt table import -c --from-mysql "$DSN" parent file.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "a file can't be imported with --from-mysql" ]] || false
+}
+
+@test "import-mysql: the password is read from DOLT_IMPORT_PASSWORD" {
+    cd srcdb
+    dolt sql -q "CREATE USER importer IDENTIFIED BY 'pw'; GRANT ALL ON *.* TO importer;"
+    cd ..
+
+    run dolt import-db "importer@tcp(127.0.0.1:$PORT)/srcdb" nopk
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Access denied" ]] || false
+
+    DOLT_IMPORT_PASSWORD=pw run dolt import-db "importer@tcp(127.0.0.1:$PORT)/srcdb" nopk
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Imported 1 tables" ]] || false
+}
+
+@test "import-mysql: the password is not printed" {
+    run dolt import-db "dolt:secret@tcp(127.0.0.1:$PORT)/srcdb"
+    [ "$status" -eq 1 ]
+    [[ ! "$output" =~ "secret" ]] || false
+}
@@ -0,0 +1,173 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE vendor (
+  id int PRIMARY KEY,
+  name varchar(20),
+  price decimal(6,2),
+  UNIQUE KEY (name)
+);
+INSERT INTO vendor VALUES (1, 'a', 1.00), (2, 'b', 2.00), (3, 'c', 3.00), (4, 'd', 4.00);
+CREATE TABLE composite (
+  a varchar(5),
+  b date,
+  x int,
+  PRIMARY KEY (a, b)
+);
+INSERT INTO composite VALUES ('x', '2020-01-01', 1), ('x', '2020-01-02', 2), ('y''s', '2020-01-01', 3);
+SQL
+    dolt commit -Am "initial data"
+
+    cat <<DELIM > vendor.csv
+id,name,price
+1,a,1.00
+2,b,2.50
+4,dd,4.00
+5,e,5.00
+DELIM
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "import-sync-tables: sync table inserts, updates and deletes rows" {
+    run dolt table import --sync-table vendor vendor.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1 Row Added" ]] || false
+    [[ "$output" =~ "2 Rows Modified" ]] || false
+    [[ "$output" =~ "1 Row Deleted" ]] || false
+    [[ "$output" =~ "1 Row Unmodified" ]] || false
+    [[ "$output" =~ "Modifications: 2, Unmodified: 1" ]] || false
+    [[ "$output" =~ "Import completed successfully." ]] || false
+
+    run dolt sql -r csv -q "select * from vendor order by id"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 5 ]
+    [ "${lines[1]}" = "1,a,1.00" ]
+    [ "${lines[2]}" = "2,b,2.50" ]
+    [ "${lines[3]}" = "4,dd,4.00" ]
+    [ "${lines[4]}" = "5,e,5.00" ]
+
+    # nothing is committed without --commit
+    run dolt status
+    [[ "$output" =~ "modified:         vendor" ]] || false
+}
+
+@test "import-sync-tables: sync table with a composite primary key" {
+    cat <<DELIM > composite.csv
+a,b,x
+x,2020-01-02,2
+DELIM
+
+    run dolt table import --sync-table composite composite.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2 Rows Deleted" ]] || false
+
+    run dolt sql -r csv -q "select * from composite"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+    [ "${lines[1]}" = "x,2020-01-02,2" ]
+}
+
+@test "import-sync-tables: sync table commits with a generated message" {
+    run dolt table import --sync-table --commit vendor vendor.csv
+    [ "$status" -eq 0 ]
+
+    run dolt log -n 1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Sync table vendor from vendor.csv: 1 row added, 2 rows modified, 1 row deleted" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+
+    # syncing the same file again changes nothing
+    run dolt table import --sync-table --commit vendor vendor.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "4 Rows Unmodified" ]] || false
+    [[ "$output" =~ "No changes to commit." ]] || false
+
+    run dolt log --oneline
+    [ "${#lines[@]}" -eq 3 ]
+}
+
+@test "import-sync-tables: sync table --commit refuses to commit other staged changes" {
+    dolt sql -q "INSERT INTO composite VALUES ('z', '2020-01-01', 4)"
+    dolt add composite
+
+    run dolt table import --sync-table --commit vendor vendor.csv
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "there are staged changes which --commit would commit along with the imported tables" ]] || false
+
+    run dolt log --oneline
+    [ "${#lines[@]}" -eq 2 ]
+    run dolt sql -r csv -q "select count(*) from vendor where id = 5"
+    [ "${lines[1]}" = "0" ]
+
+    # once the staged changes are committed, the sync commits only its own table
+    dolt commit -m "staged changes"
+    run dolt table import --sync-table --commit vendor vendor.csv
+    [ "$status" -eq 0 ]
+    run dolt sql -r csv -q "select table_name from dolt_diff where commit_hash = hashof('HEAD')"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+    [ "${lines[1]}" = "vendor" ]
+}
+
+@test "import-sync-tables: sync table deletes many rows" {
+    dolt sql -q "create table big (id int primary key, v int)"
+    seq 1 5000 | awk 'BEGIN { print "id,v" } { print $1 "," $1 }' > all.csv
+    dolt table import -u big all.csv
+    seq 1 3 5000 | awk 'BEGIN { print "id,v" } { print $1 "," $1 }' > some.csv
+
+    run dolt table import --sync-table big some.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3,333 Rows Deleted" ]] || false
+
+    run dolt sql -r csv -q "select count(*), min(id), max(id) from big"
+    [ "${lines[1]}" = "1667,1,4999" ]
+}
+
+@test "import-sync-tables: sync table is rolled back when a delete violates a foreign key" {
+    dolt sql -q "create table child (id int primary key, vid int, foreign key (vid) references vendor (id))"
+    dolt sql -q "insert into child values (1, 3)"
+
+    run dolt table import --sync-table vendor vendor.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Foreign key violation" ]] || false
+
+    run dolt sql -r csv -q "select count(*) from vendor"
+    [ "${lines[1]}" = "4" ]
+}
+
+@test "import-sync-tables: sync table requires a primary key" {
+    dolt sql -q "create table keyless (a int)"
+    echo "a" > keyless.csv
+
+    run dolt table import --sync-table keyless keyless.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "table keyless has no primary key, which is required to sync it" ]] || false
+}
+
+@test "import-sync-tables: sync table argument errors" {
+    run dolt table import --sync-table missing vendor.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "The following table could not be found: missing" ]] || false
+
+    run dolt table import --sync-table --continue vendor vendor.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "parameters sync-table and continue are mutually exclusive" ]] || false
+
+    run dolt table import -u --commit vendor vendor.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "fatal: --commit is only supported for sync operations" ]] || false
+
+    run dolt table import --sync-table -u vendor vendor.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Must specify exactly one of -c, -u, -a, -r, or --sync-table." ]] || false
+}
@@ -0,0 +1,154 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE people (id int PRIMARY KEY, name varchar(20), email varchar(100), ssn varchar(11));
+INSERT INTO people VALUES
+  (1, 'Alice', 'alice@corp.com', '123-45-6789'),
+  (2, 'Bob', 'bob@corp.com', '987-65-4321');
+INSERT INTO dolt_masking VALUES
+  ('people', 'name', 'hash', 'salt'),
+  ('people', 'email', 'fake', 'email:salt'),
+  ('people', 'ssn', 'fixed', 'XXX-XX-XXXX');
+SQL
+    dolt add -A
+    dolt commit -m "added people"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "masking: dump without --masked is not masked" {
+    dolt dump
+    run grep INSERT doltdump.sql
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "alice@corp.com" ]] || false
+    [[ "$output" =~ "123-45-6789" ]] || false
+}
+
+@test "masking: dump --masked" {
+    run dolt dump --masked
+    [ "$status" -eq 0 ]
+
+    run grep INSERT doltdump.sql
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "alice@corp.com" ]] || false
+    [[ ! "$output" =~ "Alice" ]] || false
+    [[ ! "$output" =~ "123-45-6789" ]] || false
+    [[ "$output" =~ "XXX-XX-XXXX" ]] || false
+    [[ "$output" =~ "@example." ]] || false
+
+    run dolt dump --masked -r csv
+    [ "$status" -eq 0 ]
+    run cat doltdump/people.csv
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "alice@corp.com" ]] || false
+    [[ "$output" =~ "XXX-XX-XXXX" ]] || false
+}
+
+@test "masking: table export --masked" {
+    run dolt table export --masked people people.csv
+    [ "$status" -eq 0 ]
+
+    run cat people.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "id,name,email,ssn" ]] || false
+    [[ ! "$output" =~ "bob@corp.com" ]] || false
+    [[ ! "$output" =~ "987-65-4321" ]] || false
+    [[ "$output" =~ "XXX-XX-XXXX" ]] || false
+}
+
+@test "masking: masked values are deterministic" {
+    dolt table export --masked people first.csv
+    dolt table export --masked people second.csv
+    run diff first.csv second.csv
+    [ "$status" -eq 0 ]
+}
+
+@test "masking: clone --masked rewrites history of all branches and tags" {
+    dolt sql -q "UPDATE people SET email = 'alice@other.com' WHERE id = 1"
+    dolt commit -am "changed email"
+    dolt branch feature HEAD~1
+    dolt tag v1 HEAD~1
+
+    dolt remote add origin file://../remote
+    dolt push origin main
+    dolt push origin feature
+    dolt push origin v1
+
+    cd ..
+    run dolt clone --masked file://./remote masked
+    [ "$status" -eq 0 ]
+    cd masked
+
+    run dolt branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "main" ]] || false
+    [[ "$output" =~ "feature" ]] || false
+
+    run dolt remote -v
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+
+    for rev in main feature v1 HEAD~1; do
+        run dolt sql -r csv -q "SELECT * FROM people AS OF '$rev'"
+        [ "$status" -eq 0 ]
+        [[ ! "$output" =~ "@corp.com" ]] || false
+        [[ ! "$output" =~ "@other.com" ]] || false
+        [[ ! "$output" =~ "123-45-6789" ]] || false
+        [[ "$output" =~ "XXX-XX-XXXX" ]] || false
+
+        # the rules, and the salts that would reverse the masked values, are not part of any commit
+        run dolt sql -r csv -q "SELECT count(*) FROM dolt_masking AS OF '$rev'"
+        [ "$status" -eq 0 ]
+        [ "${lines[1]}" = "0" ]
+    done
+
+    run dolt ls --all
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "dolt_masking" ]] || false
+
+    # the unchanged row is masked to the same value in every commit
+    run dolt sql -r csv -q "SELECT count(distinct email) FROM dolt_history_people WHERE id = 2"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+}
+
+@test "masking: clone --masked without rules warns" {
+    dolt sql -q "DELETE FROM dolt_masking"
+    dolt commit -am "removed rules"
+    dolt remote add origin file://../remote
+    dolt push origin main
+
+    cd ..
+    run dolt clone --masked file://./remote unmasked
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no rules found in dolt_masking" ]] || false
+}
+
+@test "masking: invalid rules are rejected" {
+    run dolt sql -q "INSERT INTO dolt_masking VALUES ('people', 'id', 'shuffle', NULL)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unknown masking transform 'shuffle'" ]] || false
+}
+
+@test "masking: rules which would break the table's constraints are rejected" {
+    run dolt sql -q "INSERT INTO dolt_masking VALUES ('people', 'id', 'fixed', '1')"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot mask column people.id of type int with transform fixed" ]] || false
+
+    run dolt sql -q "INSERT INTO dolt_masking VALUES ('people', 'name', 'hash', NULL)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "masking transform hash on people.name requires a salt" ]] || false
+
+    # rules are also checked when they are applied, since the schema can change after they are added
+    dolt sql -q "ALTER TABLE people ADD UNIQUE INDEX (ssn)"
+    run dolt dump --masked
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot mask column people.ssn of type varchar(11) with transform fixed" ]] || false
+}
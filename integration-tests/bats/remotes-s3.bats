@@ -0,0 +1,69 @@
+# Smoke tests for s3:// remotes, which work with S3 compatible stores such as MinIO.
+
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+}
+
+teardown() {
+    teardown_common
+}
+
+skip_if_no_s3_tests() {
+    if [ -z "$DOLT_BATS_S3_ENDPOINT" -o -z "$DOLT_BATS_S3_BUCKET" ]; then
+      skip "skipping s3 tests; set DOLT_BATS_S3_ENDPOINT and DOLT_BATS_S3_BUCKET, and the AWS credential environment variables, to run"
+    fi
+}
+
+@test "remotes-s3: can add remote with s3 url and params" {
+    dolt remote add --s3-endpoint http://localhost:9000 --s3-force-path-style --aws-region us-west-2 origin s3://bucket/repo_name
+    run dolt remote -v
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "s3://bucket/repo_name" ]] || false
+    [[ "$output" =~ '"s3-endpoint": "http://localhost:9000"' ]] || false
+    [[ "$output" =~ '"s3-force-path-style": "true"' ]] || false
+}
+
+@test "remotes-s3: s3 params are only valid for s3 remotes" {
+    run dolt remote add --s3-endpoint http://localhost:9000 origin 'aws://[dynamo_db_table:s3_bucket]/repo_name'
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "only valid for s3 remotes" ]] || false
+    run dolt remote add --s3-force-path-style origin file:///tmp/repo_name
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "only valid for s3 remotes" ]] || false
+}
+
+# bats test_tags=no_lambda
+@test "remotes-s3: push, clone and pull" {
+    skip_if_no_s3_tests
+    url="s3://$DOLT_BATS_S3_BUCKET/$(uuidgen)"
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY); INSERT INTO test VALUES (1), (2);"
+    dolt commit -Am "create test"
+    dolt remote add --s3-endpoint "$DOLT_BATS_S3_ENDPOINT" --s3-force-path-style origin "$url"
+    dolt push origin main
+
+    cd ..
+    dolt clone --s3-endpoint "$DOLT_BATS_S3_ENDPOINT" --s3-force-path-style "$url" s3-clone
+    cd s3-clone
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    dolt sql -q "INSERT INTO test VALUES (3)"
+    dolt commit -am "add row"
+    dolt push origin main
+
+    cd ../dolt-repo-$$
+    dolt pull origin main
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+}
+
+# bats test_tags=no_lambda
+@test "remotes-s3: fetch from a missing bucket fails" {
+    skip_if_no_s3_tests
+    dolt remote add --s3-endpoint "$DOLT_BATS_S3_ENDPOINT" --s3-force-path-style origin "s3://this-bucket-does-not-exist-b612c34f/repo"
+    run dolt fetch origin
+    [ "$status" -eq 1 ]
+}
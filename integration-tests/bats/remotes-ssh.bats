@@ -0,0 +1,119 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    skiponwindows "tests use a shell script in place of ssh"
+    setup_common
+
+    # Stands in for ssh: drops the options and the host, and runs the remote command locally
+    cat > "$BATS_TMPDIR/fake-ssh-$$" <<'EOF'
+#!/bin/sh
+while [ $# -gt 0 ]; do
+    case "$1" in
+        -p) shift 2 ;;
+        --) shift 2; break ;;
+        -*) shift ;;
+        *) shift; break ;;
+    esac
+done
+exec sh -c "$*"
+EOF
+    chmod +x "$BATS_TMPDIR/fake-ssh-$$"
+    export DOLT_SSH="$BATS_TMPDIR/fake-ssh-$$"
+    export DOLT_SSH_EXEC_PATH="$(which dolt)"
+
+    cd $BATS_TMPDIR
+    mkdir ssh-remotes-$$
+    cd ssh-remotes-$$
+    REMOTES=$(pwd)
+    cd ../dolt-repo-$$
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+    rm -rf "$BATS_TMPDIR/ssh-remotes-$$" "$BATS_TMPDIR/fake-ssh-$$"
+}
+
+@test "remotes-ssh: push, clone, pull and fetch a bare database" {
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY, c1 int); INSERT INTO test VALUES (1, 1), (2, 2);"
+    dolt commit -Am "create test"
+
+    dolt remote add origin "ssh://user@example.com:2222$REMOTES/bare"
+    run dolt push origin main
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "main -> main" ]] || false
+    [ -d "$REMOTES/bare" ]
+
+    cd $REMOTES
+    dolt clone "ssh://example.com$REMOTES/bare" clone
+    cd clone
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt commit -am "add row"
+    dolt push origin main
+
+    cd $BATS_TMPDIR/dolt-repo-$$
+    dolt fetch origin
+    run dolt log origin/main -n 1
+    [[ "$output" =~ "add row" ]] || false
+    dolt pull origin main
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+}
+
+@test "remotes-ssh: push to and clone from a repository" {
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY)"
+    dolt commit -Am "create test"
+
+    cd $REMOTES
+    dolt clone "ssh://example.com$BATS_TMPDIR/dolt-repo-$$" clone
+    cd clone
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO test VALUES (1)"
+    dolt commit -am "add row"
+    dolt push origin feature
+
+    cd $BATS_TMPDIR/dolt-repo-$$
+    run dolt branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "feature" ]] || false
+    run dolt sql -q "SELECT count(*) FROM test AS OF 'feature'" -r csv
+    [[ "$output" =~ "1" ]] || false
+}
+
+@test "remotes-ssh: cloning a missing database fails and creates nothing" {
+    run dolt clone "ssh://example.com$REMOTES/missing" clone
+    [ "$status" -ne 0 ]
+    [ ! -d "$REMOTES/missing" ]
+}
+
+@test "remotes-ssh: a read-only transfer rejects pushes" {
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY)"
+    dolt commit -Am "create test"
+    dolt remote add origin "ssh://example.com$REMOTES/bare"
+    dolt push origin main
+
+    cat > "$REMOTES/read-only-dolt" <<EOF
+#!/bin/sh
+shift
+exec "$(which dolt)" transfer --read-only "\$@"
+EOF
+    chmod +x "$REMOTES/read-only-dolt"
+    export DOLT_SSH_EXEC_PATH="$REMOTES/read-only-dolt"
+
+    dolt clone "ssh://example.com$REMOTES/bare" "$REMOTES/clone"
+    dolt sql -q "INSERT INTO test VALUES (1)"
+    dolt commit -am "add row"
+    run dolt push origin main
+    [ "$status" -ne 0 ]
+}
+
+@test "remotes-ssh: transfer requires a path" {
+    run dolt transfer
+    [ "$status" -ne 0 ]
+}
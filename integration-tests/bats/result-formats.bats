@@ -0,0 +1,117 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 VARCHAR(20),
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1, 'one'), (2, 'a|b'), (3, NULL);
+SQL
+    dolt add .
+    dolt commit -m "created test"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "result-formats: sql markdown" {
+    run dolt sql -r markdown -q "select * from test order by pk"
+    [ "$status" -eq 0 ]
+    [ "${lines[0]}" = "| pk | c1 |" ]
+    [ "${lines[1]}" = "| ---: | --- |" ]
+    [ "${lines[2]}" = "| 1 | one |" ]
+    [ "${lines[3]}" = '| 2 | a\|b |' ]
+    [ "${lines[4]}" = "| 3 | NULL |" ]
+
+    run dolt sql -r markdown -q "select * from test where pk > 10"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+}
+
+@test "result-formats: sql html" {
+    dolt sql -q "insert into test values (4, '<b>&</b>')"
+    run dolt sql -r html -q "select * from test where pk in (1, 4) order by pk"
+    [ "$status" -eq 0 ]
+    [ "${lines[0]}" = "<table>" ]
+    [[ "$output" =~ "<tr><th>pk</th><th>c1</th></tr>" ]] || false
+    [[ "$output" =~ "<tr><td>1</td><td>one</td></tr>" ]] || false
+    [[ "$output" =~ "<tr><td>4</td><td>&lt;b&gt;&amp;&lt;/b&gt;</td></tr>" ]] || false
+    [ "${lines[-1]}" = "</table>" ]
+}
+
+@test "result-formats: sql xml" {
+    run dolt sql -r xml -q "select * from test where pk in (1, 3) order by pk"
+    [ "$status" -eq 0 ]
+    [ "${lines[0]}" = '<?xml version="1.0"?>' ]
+    [[ "$output" =~ '<field name="c1">one</field>' ]] || false
+    [[ "$output" =~ '<field name="c1" xsi:nil="true" />' ]] || false
+    [ "${lines[-1]}" = "</resultset>" ]
+}
+
+@test "result-formats: sql ndjson" {
+    run dolt sql -r ndjson -q "select * from test order by pk"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 3 ]
+    [ "${lines[0]}" = '{"c1":"one","pk":1}' ]
+    [ "${lines[2]}" = '{"pk":3}' ]
+
+    run dolt sql -r ndjson -q "select * from test where pk > 10"
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+}
+
+@test "result-formats: sql invalid format" {
+    run dolt sql -r yaml -q "select * from test"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Invalid argument for --result-format" ]] || false
+    [[ "$output" =~ "markdown" ]] || false
+}
+
+@test "result-formats: diff markdown" {
+    dolt sql -q "update test set c1 = 'uno' where pk = 1; delete from test where pk = 2; insert into test values (4, 'four')"
+    dolt sql -q "alter table test add column c2 int"
+
+    run dolt diff -r markdown
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '## `test`' ]] || false
+    [[ "$output" =~ '```diff' ]] || false
+    [[ "$output" =~ '+  `c2` int,' ]] || false
+    [[ "$output" =~ "#### Rows" ]] || false
+    [[ "$output" =~ "| + | 4 | four | NULL |" ]] || false
+    [[ "$output" =~ '| - | 2 | a\|b | NULL |' ]] || false
+    [[ "$output" =~ "| < | 1 | **one** | NULL |" ]] || false
+    [[ "$output" =~ "| > | 1 | **uno** | NULL |" ]] || false
+
+    run dolt diff -r markdown --stat
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "- 1 Row Added" ]] || false
+    [[ "$output" =~ "- 1 Row Deleted" ]] || false
+    [[ "$output" =~ "- 1 Row Modified" ]] || false
+}
+
+@test "result-formats: log" {
+    dolt commit --allow-empty -m "second commit"
+
+    run dolt log -r markdown
+    [ "$status" -eq 0 ]
+    [ "${lines[0]}" = "| commit_hash | author | email | date | message |" ]
+    [[ "${lines[2]}" =~ "| second commit |" ]] || false
+    [[ "${lines[3]}" =~ "| created test |" ]] || false
+
+    run dolt log -r ndjson -n 1 --parents
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 1 ]
+    [[ "$output" =~ '"message":"second commit"' ]] || false
+    [[ "$output" =~ '"parents":' ]] || false
+
+    run dolt log -r json --oneline
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot be combined" ]] || false
+}
@@ -410,3 +410,98 @@ CSV
     [[ "$output" =~ "name" ]] || false
     [[ "$output" =~ "invalid schema" ]] || false
 }
+
+@test "schema-import: infer decimals, enums and dates" {
+    cat <<CSV > orders.csv
+id,price,status,day
+1,10.50,open,31/12/2020
+2,-3.25,closed,01/01/2021
+3,1000,open,15/06/2021
+4,0.125,open,
+5,7,closed,02/02/2022
+CSV
+    run dolt schema import -c --dry-run -pks=id orders orders.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "\`price\` float" ]] || false
+    [[ "$output" =~ "\`status\` varchar(1023)" ]] || false
+
+    run dolt schema import -c --dry-run --infer-decimal --infer-enum --date-format '%d/%m/%Y' -pks=id orders orders.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "\`price\` decimal(7,3)" ]] || false
+    [[ "$output" =~ "\`status\` enum('closed','open')" ]] || false
+    [[ "$output" =~ "\`day\` date" ]] || false
+
+    run dolt schema import -c --dry-run --infer-decimal --sample-rows 2 -pks=id orders orders.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "\`price\` decimal(4,2)" ]] || false
+
+    run dolt schema import -c --infer-decimal --float-threshold 0.1 -pks=id orders orders.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "mutually exclusive" ]] || false
+}
+
+@test "schema-import: --pks auto" {
+    cat <<CSV > sales.csv
+region,year,amount
+east,2020,1
+east,2021,2
+west,2020,1
+west,2021,2
+CSV
+    run dolt schema import -c --pks auto sales sales.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ 'PRIMARY KEY (`region`,`year`)' ]] || false
+
+    cat <<CSV > dupes.csv
+a,b
+1,1
+1,1
+CSV
+    run dolt schema import -c --pks auto dupes dupes.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "no unique combination of columns" ]] || false
+
+    run dolt schema import -u --pks auto sales sales.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "only supported for create operations" ]] || false
+}
+
+@test "schema-import: --report" {
+    cat <<CSV > sales.csv
+id,region,amount
+1,east,1.5
+2,east,
+3,west,2
+4,west,2
+CSV
+    run dolt schema import -c --report --pks auto sales sales.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "--report is only supported with --dry-run" ]] || false
+
+    run dolt schema import -c --dry-run --report --infer-enum --pks auto sales sales.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '"table": "sales"' ]] || false
+    [[ "$output" =~ '"create_statement": "CREATE TABLE `sales`' ]] || false
+    [[ "$output" =~ '"rows_sampled": 4' ]] || false
+    [[ "$output" =~ '"primary_key": [
+    "id"
+  ]' ]] || false
+    [[ "$output" =~ '"name": "region",
+      "type": "enum('"'"'east'"'"','"'"'west'"'"')",
+      "candidates": [
+        "varchar(1023)"
+      ],
+      "nulls": 0,
+      "cardinality": 2' ]] || false
+    [[ "$output" =~ '"name": "amount",
+      "type": "float",
+      "candidates": [
+        "float",
+        "int"
+      ],
+      "nulls": 1,
+      "cardinality": 2' ]] || false
+
+    run dolt ls
+    [[ ! "$output" =~ "sales" ]] || false
+}
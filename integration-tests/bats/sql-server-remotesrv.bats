@@ -684,3 +684,125 @@ GRANT CLONE_ADMIN ON *.* TO clone_admin_user@'localhost';
     [[ "$output" =~ "main" ]] || false
 }
 
+
+start_sql_server_with_protected_branches() {
+    PORT=$( definePORT )
+    APIPORT=$( definePORT )
+    export DOLT_REMOTE_PASSWORD="rootpass"
+    export SQL_USER="root"
+    cat > ../server.yaml <<YAML
+user:
+  name: $SQL_USER
+  password: $DOLT_REMOTE_PASSWORD
+listener:
+  port: $PORT
+remotesapi:
+  port: $APIPORT
+  protected_branches:
+  - branch: main
+    verify_constraints: true
+    checks:
+    - name: no negative balances
+      query: SELECT * FROM accounts WHERE balance < 0
+  - branch: release/*
+    allow_force_push: true
+YAML
+    dolt sql-server --config ../server.yaml --socket "dolt.$PORT.sock" &
+    SERVER_PID=$!
+    wait_for_connection $PORT 8500
+}
+
+@test "sql-server-remotesrv: protected branches reject pushes which fail checks" {
+    mkdir remote
+    cd remote
+    dolt init
+    dolt sql -q 'create table accounts (id int primary key, balance int);'
+    dolt sql -q 'create table parent (id int primary key);'
+    dolt sql -q 'create table child (id int primary key, pid int, foreign key (pid) references parent(id));'
+    dolt sql -q 'insert into accounts values (1, 10);'
+    dolt commit -Am 'initial accounts.'
+    start_sql_server_with_protected_branches
+
+    cd ../
+    dolt clone http://localhost:$APIPORT/remote cloned_db -u $SQL_USER
+    cd cloned_db
+
+    dolt sql -q 'insert into accounts values (2, 5);'
+    dolt commit -am 'add account 2'
+    run dolt push origin --user $SQL_USER main:main
+    [[ "$status" -eq 0 ]] || false
+
+    dolt sql -q 'update accounts set balance = -1 where id = 2;'
+    dolt commit -am 'overdraw account 2'
+    run dolt push origin --user $SQL_USER main:main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ '! [remote rejected]     main -> main (check "no negative balances" returned 1 rows at commit ' ]] || false
+    [[ "$output" =~ "Updates were rejected by the remote" ]] || false
+
+    # every pushed commit is checked, not just the new head of the branch
+    dolt sql -q 'update accounts set balance = 0 where id = 2;'
+    dolt commit -am 'fix account 2'
+    run dolt push origin --user $SQL_USER main:main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ 'check "no negative balances" returned 1 rows at commit ' ]] || false
+
+    dolt reset --hard HEAD~2
+    dolt sql -q 'set foreign_key_checks = 0; insert into child values (1, 42);'
+    dolt commit -am 'add orphan'
+    run dolt push origin --user $SQL_USER main:main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "main -> main (commit has constraint violations at commit " ]] || false
+
+    cd ../remote
+    run dolt sql -q "select * from accounts as of 'main'" -r csv
+    [[ "$output" =~ "2,5" ]] || false
+    ! [[ "$output" =~ "-1" ]] || false
+    run dolt sql -q "select count(*) from child as of 'main'" -r csv
+    [[ "$output" =~ "0" ]] || false
+}
+
+@test "sql-server-remotesrv: protected branches reject force pushes and deletions" {
+    mkdir remote
+    cd remote
+    dolt init
+    dolt sql -q 'create table accounts (id int primary key, balance int);'
+    dolt sql -q 'insert into accounts values (1, 10);'
+    dolt commit -Am 'initial accounts.'
+    dolt sql -q 'insert into accounts values (2, 20);'
+    dolt commit -am 'add account 2'
+    dolt branch release/1.0
+    start_sql_server_with_protected_branches
+
+    cd ../
+    dolt clone http://localhost:$APIPORT/remote cloned_db -u $SQL_USER
+    cd cloned_db
+
+    dolt reset --hard HEAD~1
+    dolt sql -q 'insert into accounts values (3, 30);'
+    dolt commit -am 'add account 3'
+    run dolt push origin --force --user $SQL_USER main:main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "! [remote rejected]     main -> main (protected branch main cannot be force pushed)" ]] || false
+
+    run dolt push origin --user $SQL_USER :main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "! [remote rejected]     main (protected branch main cannot be deleted)" ]] || false
+
+    # release/* allows force pushes, but not deletions
+    run dolt push origin --force --user $SQL_USER main:release/1.0
+    [[ "$status" -eq 0 ]] || false
+    run dolt push origin --user $SQL_USER :release/1.0
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "protected branch release/1.0 cannot be deleted" ]] || false
+
+    # unprotected branches can be updated freely
+    run dolt push origin --user $SQL_USER main:feature
+    [[ "$status" -eq 0 ]] || false
+    run dolt push origin --user $SQL_USER :feature
+    [[ "$status" -eq 0 ]] || false
+
+    cd ../remote
+    run dolt sql -q "select * from accounts as of 'main'" -r csv
+    [[ "$output" =~ "2,20" ]] || false
+    ! [[ "$output" =~ "3,30" ]] || false
+}
@@ -101,6 +101,37 @@ teardown() {
     $BATS_TEST_DIRNAME/sql-shell-empty-prompt.expect
 }
 
+# bats test_tags=no_lambda
+@test "sql-shell: meta commands" {
+    skiponwindows "Need to install expect and make this script work on windows."
+    if [ "$SQL_ENGINE" = "remote-engine" ]; then
+      skip "Presently sql command will not connect to remote server due to lack of lock file where there are not DBs."
+    fi
+    dolt commit -Am "create test"
+
+    run $BATS_TEST_DIRNAME/sql-shell-meta-commands.expect
+    echo "$output"
+    [ "$status" -eq 0 ]
+
+    run cat shell-output.txt
+    [[ "$output" =~ "insert into test (pk) values (100);" ]] || false
+    [[ "$output" =~ "Query OK, 1 row affected" ]] || false
+    [[ ! "$output" =~ "from the shell" ]] || false
+
+    # the shell's branch is checked out only in its session
+    run dolt branch --show-current
+    [ "$output" = "main" ]
+
+    run dolt log -n 1 other
+    [[ "$output" =~ "from the shell" ]] || false
+
+    db=$(basename "$PWD")
+    run sh -c "cat \"$DOLT_ROOT_PATH\"/.dolt/sql_history/$db-*/$db.history"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '\checkout -b other' ]] || false
+    [[ "$output" =~ "insert into test (pk) values (100);" ]] || false
+}
+
 @test "sql-shell: works with ANSI_QUOTES SQL mode" {
     if [ $SQL_ENGINE = "remote-engine" ]; then
       skip "Presently sql command will not connect to remote server due to lack of lock file where there are not DBs."
@@ -0,0 +1,57 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE t (pk int PRIMARY KEY, c varchar(50), INDEX idx_c (c));
+INSERT INTO t WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 3000) SELECT i, concat('value ', i) FROM n;
+SQL
+    dolt commit -Am "created t"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "storage-report: reports tables, indexes and refs" {
+    dolt branch other
+    run dolt admin storage-report
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| t     | PRIMARY |" ]] || false
+    [[ "$output" =~ "| t     | idx_c   |" ]] || false
+    [[ "$output" =~ "refs/heads/main" ]] || false
+    [[ "$output" =~ "refs/heads/other" ]] || false
+    [[ "$output" =~ "workingSets/heads/main" ]] || false
+    [[ "$output" =~ "reclaimable by dolt gc: at most" ]] || false
+}
+
+@test "storage-report: json output" {
+    run dolt admin storage-report -r json
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '"table": "t"' ]] || false
+    [[ "$output" =~ '"index": "idx_c"' ]] || false
+    [[ "$output" =~ '"ref": "refs/heads/main"' ]] || false
+    [[ "$output" =~ '"reclaimable_bytes":' ]] || false
+}
+
+@test "storage-report: unique bytes of a branch" {
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO t VALUES (5000, 'new')"
+    dolt commit -am "changed t on feature"
+    dolt checkout main
+
+    run dolt admin storage-report -r json
+    [ "$status" -eq 0 ]
+    feature_unique=$(echo "$output" | grep -A4 '"ref": "refs/heads/feature"' | grep unique_bytes)
+    [[ ! "$feature_unique" =~ '"unique_bytes": 0,' ]] || false
+    main_unique=$(echo "$output" | grep -A4 '"ref": "refs/heads/main"' | grep unique_bytes)
+    [[ "$main_unique" =~ '"unique_bytes": 0,' ]] || false
+}
+
+@test "storage-report: invalid result format" {
+    run dolt admin storage-report -r csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "invalid result format: csv" ]] || false
+}